@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/serpent"
+)
+
+// waterfallWidth is the number of characters used for the bars of the build
+// timings waterfall.
+const waterfallWidth = 40
+
+func (r *RootCmd) builds() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "builds",
+		Short: "Inspect workspace builds",
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.buildTimings(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) buildTimings() *serpent.Command {
+	var buildNumber int64
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			timings, ok := data.(codersdk.WorkspaceBuildTimings)
+			if !ok {
+				return nil, xerrors.Errorf("expected type %T, got %T", timings, data)
+			}
+			return renderBuildTimings(timings), nil
+		}),
+		cliui.JSONFormat(),
+	)
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "timings <workspace>",
+		Short: "Show how long each stage of a workspace build took.",
+		Long: "Provisioner timings are shown per resource, followed by the time it took the workspace agents to connect and run their startup scripts.\n\n" + FormatExamples(
+			Example{
+				Description: "Show the timings of the latest build of a workspace",
+				Command:     "coder builds timings my-workspace",
+			},
+			Example{
+				Description: "Show the timings of a specific build as JSON",
+				Command:     "coder builds timings my-workspace --build 3 --output json",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			var build codersdk.WorkspaceBuild
+			if buildNumber == 0 {
+				workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+				if err != nil {
+					return err
+				}
+				build = workspace.LatestBuild
+			} else {
+				owner, workspace, err := splitNamedWorkspace(inv.Args[0])
+				if err != nil {
+					return err
+				}
+				build, err = client.WorkspaceBuildByUsernameAndWorkspaceNameAndBuildNumber(inv.Context(), owner, workspace, strconv.FormatInt(buildNumber, 10))
+				if err != nil {
+					return err
+				}
+			}
+
+			timings, err := client.WorkspaceBuildTimings(inv.Context(), build.ID)
+			if err != nil {
+				return xerrors.Errorf("get build timings: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), timings)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		buildNumberOption(&buildNumber),
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+type buildTimingRow struct {
+	stage     string
+	name      string
+	startedAt time.Time
+	endedAt   time.Time
+}
+
+// renderBuildTimings draws the timings of a build as a waterfall, so that
+// slow resources and gaps between stages stand out.
+func renderBuildTimings(timings codersdk.WorkspaceBuildTimings) string {
+	rows := make([]buildTimingRow, 0, len(timings.ProvisionerTimings)+len(timings.AgentTimings))
+	for _, t := range timings.ProvisionerTimings {
+		name := t.Resource
+		if name == "" {
+			name = t.Source
+		}
+		rows = append(rows, buildTimingRow{
+			stage:     string(t.Stage),
+			name:      name,
+			startedAt: t.StartedAt,
+			endedAt:   t.EndedAt,
+		})
+	}
+	for _, t := range timings.AgentTimings {
+		rows = append(rows, buildTimingRow{
+			stage:     "agent " + string(t.Stage),
+			name:      t.AgentName,
+			startedAt: t.StartedAt,
+			endedAt:   t.EndedAt,
+		})
+	}
+	if len(rows) == 0 {
+		return "No timings were recorded for this build."
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].startedAt.Before(rows[j].startedAt)
+	})
+
+	var (
+		start      = rows[0].startedAt
+		end        = rows[0].endedAt
+		stageWidth = len("STAGE")
+		nameWidth  = len("RESOURCE")
+	)
+	for _, row := range rows {
+		if row.endedAt.After(end) {
+			end = row.endedAt
+		}
+		stageWidth = max(stageWidth, len(row.stage))
+		nameWidth = max(nameWidth, len(row.name))
+	}
+	total := end.Sub(start)
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "%-*s  %-*s  %9s  %s\n", stageWidth, "STAGE", nameWidth, "RESOURCE", "DURATION", "TIMELINE")
+	for _, row := range rows {
+		duration := row.endedAt.Sub(row.startedAt)
+		offset, barEnd := 0, waterfallWidth
+		if total > 0 {
+			offset = int(float64(row.startedAt.Sub(start)) / float64(total) * waterfallWidth)
+			barEnd = int(math.Round(float64(row.endedAt.Sub(start)) / float64(total) * waterfallWidth))
+		}
+		offset = min(offset, waterfallWidth-1)
+		length := max(1, min(barEnd, waterfallWidth)-offset)
+		bar := strings.Repeat(" ", offset) + strings.Repeat("█", length) + strings.Repeat(" ", waterfallWidth-offset-length)
+		_, _ = fmt.Fprintf(&sb, "%-*s  %-*s  %9s  |%s|\n", stageWidth, row.stage, nameWidth, row.name, formatTimingDuration(duration), bar)
+	}
+	_, _ = fmt.Fprintf(&sb, "\nTotal: %s", formatTimingDuration(total))
+	return sb.String()
+}
+
+func formatTimingDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,77 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+func TestBuildTimings(t *testing.T) {
+	t.Parallel()
+
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	member, memberUser := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        memberUser.ID,
+	}).Do()
+
+	start := time.Now().UTC().Truncate(time.Second)
+	_, err := db.InsertProvisionerJobTimings(context.Background(), database.InsertProvisionerJobTimingsParams{
+		JobID:     r.Build.JobID,
+		StartedAt: []time.Time{start, start.Add(2 * time.Second)},
+		EndedAt:   []time.Time{start.Add(time.Second), start.Add(6 * time.Second)},
+		Stage:     []database.ProvisionerJobTimingStage{database.ProvisionerJobTimingStagePlan, database.ProvisionerJobTimingStageApply},
+		Source:    []string{"coder", "docker"},
+		Action:    []string{"read", "create"},
+		Resource:  []string{"data.coder_workspace.me", "docker_container.workspace"},
+	})
+	require.NoError(t, err)
+
+	t.Run("Waterfall", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "builds", "timings", r.Workspace.Name)
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+
+		got := out.String()
+		t.Log(got)
+		assert.Contains(t, got, "data.coder_workspace.me")
+		assert.Contains(t, got, "docker_container.workspace")
+		assert.Contains(t, got, "4s")
+		assert.Contains(t, got, "Total: 6s")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "builds", "timings", r.Workspace.Name, "--build", "1", "--output", "json")
+		clitest.SetupConfig(t, member, root)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.NoError(t, err)
+
+		var timings codersdk.WorkspaceBuildTimings
+		require.NoError(t, json.Unmarshal(out.Bytes(), &timings))
+		require.Len(t, timings.ProvisionerTimings, 2)
+		assert.Equal(t, codersdk.ProvisionerTimingStageApply, timings.ProvisionerTimings[1].Stage)
+		assert.Empty(t, timings.AgentTimings)
+	})
+}
@@ -47,6 +47,12 @@ func (r Root) Organization() File {
 	return File(filepath.Join(string(r), "organization"))
 }
 
+// ProxyRegion caches the lowest latency workspace proxy region.
+func (r Root) ProxyRegion() File {
+	r.mustNotEmpty()
+	return File(filepath.Join(string(r), "proxy_region"))
+}
+
 func (r Root) DotfilesURL() File {
 	r.mustNotEmpty()
 	return File(filepath.Join(string(r), "dotfilesurl"))
@@ -11,6 +11,7 @@ import (
 	"path"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/pkg/browser"
@@ -107,10 +108,23 @@ func (r *RootCmd) loginWithPassword(
 	client *codersdk.Client,
 	email, password string,
 ) error {
-	resp, err := client.LoginWithPassword(inv.Context(), codersdk.LoginWithPasswordRequest{
+	req := codersdk.LoginWithPasswordRequest{
 		Email:    email,
 		Password: password,
-	})
+	}
+	resp, err := client.LoginWithPassword(inv.Context(), req)
+	switch {
+	case codersdk.IsTOTPEnrollmentRequiredError(err):
+		enrollment, enrollErr := client.EnrollTOTPWithPassword(inv.Context(), req)
+		if enrollErr != nil {
+			return xerrors.Errorf("enroll totp: %w", enrollErr)
+		}
+		_, _ = fmt.Fprintln(inv.Stdout, "This deployment requires owners to use multi-factor authentication.")
+		printTOTPEnrollment(inv, enrollment)
+		resp, err = loginWithTOTPCode(inv, client, req)
+	case codersdk.IsTOTPRequiredError(err):
+		resp, err = loginWithTOTPCode(inv, client, req)
+	}
 	if err != nil {
 		return xerrors.Errorf("login with password: %w", err)
 	}
@@ -139,6 +153,65 @@ func (r *RootCmd) loginWithPassword(
 	return nil
 }
 
+// loginWithTOTPCode prompts for a one-time code until a password login with
+// it succeeds.
+func loginWithTOTPCode(inv *serpent.Invocation, client *codersdk.Client, req codersdk.LoginWithPasswordRequest) (codersdk.LoginWithPasswordResponse, error) {
+	var resp codersdk.LoginWithPasswordResponse
+	_, err := cliui.Prompt(inv, cliui.PromptOptions{
+		Text: "Enter the code from your authenticator app:",
+		Validate: func(code string) error {
+			req.TOTPCode = code
+			var err error
+			resp, err = client.LoginWithPassword(inv.Context(), req)
+			return err
+		},
+	})
+	return resp, err
+}
+
+// loginWithDevice starts a device login and waits for the user to approve it
+// in a browser, which does not have to be on this machine.
+func loginWithDevice(inv *serpent.Invocation, client *codersdk.Client) (string, error) {
+	ctx := inv.Context()
+	resp, err := client.StartDeviceLogin(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("start device login: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(inv.Stdout, "Open the following in a browser and confirm the code %s:\n\n\t%s\n\n",
+		pretty.Sprint(cliui.DefaultStyles.Code, resp.UserCode), resp.VerificationURLComplete)
+	_, _ = fmt.Fprintf(inv.Stdout, "Waiting for the login to be approved...\n")
+
+	interval := time.Duration(resp.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		token, err := client.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{
+			DeviceCode: resp.DeviceCode,
+		})
+		if err != nil {
+			return "", xerrors.Errorf("get device login token: %w", err)
+		}
+		switch token.Status {
+		case codersdk.DeviceLoginStatusApproved:
+			return token.SessionToken, nil
+		case codersdk.DeviceLoginStatusDenied:
+			return "", xerrors.New("the login was denied")
+		case codersdk.DeviceLoginStatusExpired:
+			return "", xerrors.New("the login code expired, run the command again to get a new code")
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
 func (r *RootCmd) login() *serpent.Command {
 	const firstUserTrialEnv = "CODER_FIRST_USER_TRIAL"
 
@@ -149,6 +222,7 @@ func (r *RootCmd) login() *serpent.Command {
 		password           string
 		trial              bool
 		useTokenForSession bool
+		useDevice          bool
 	)
 	cmd := &serpent.Command{
 		Use:        "login [<url>]",
@@ -297,7 +371,12 @@ func (r *RootCmd) login() *serpent.Command {
 			}
 
 			sessionToken, _ := inv.ParsedFlags().GetString(varToken)
-			if sessionToken == "" {
+			if sessionToken == "" && useDevice {
+				sessionToken, err = loginWithDevice(inv, client)
+				if err != nil {
+					return err
+				}
+			} else if sessionToken == "" {
 				authURL := *serverURL
 				// Don't use filepath.Join, we don't want to use the os separator
 				// for a url.
@@ -398,6 +477,11 @@ func (r *RootCmd) login() *serpent.Command {
 			Description: "By default, the CLI will generate a new session token when logging in. This flag will instead use the provided token as the session token.",
 			Value:       serpent.BoolOf(&useTokenForSession),
 		},
+		{
+			Flag:        "device",
+			Description: "Log in without opening a browser on this machine. A code is shown that can be approved in a browser anywhere, such as when connected over SSH.",
+			Value:       serpent.BoolOf(&useDevice),
+		},
 	}
 	return cmd
 }
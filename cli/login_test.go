@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
 	"runtime"
 	"testing"
 
@@ -422,6 +424,42 @@ func TestLogin(t *testing.T) {
 		require.NotEqual(t, client.SessionToken(), sessionFile)
 	})
 
+	t.Run("Device", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		coderdtest.CreateFirstUser(t, client)
+
+		doneChan := make(chan struct{})
+		root, cfg := clitest.New(t, "login", "--force-tty", client.URL.String(), "--device")
+		pty := ptytest.New(t).Attach(root)
+		go func() {
+			defer close(doneChan)
+			err := root.Run()
+			assert.NoError(t, err)
+		}()
+
+		out := pty.ExpectRegexMatch(`/login/device\?user_code=[A-Z]{4}-[A-Z]{4}`)
+		userCode := regexp.MustCompile(`user_code=([A-Z]{4}-[A-Z]{4})`).FindStringSubmatch(out)[1]
+		pty.ExpectMatch("Waiting for the login to be approved")
+
+		// Approve the login the way a browser would.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		q := url.Values{"user_code": {userCode}, "action": {"allow"}}
+		res, err := client.Request(ctx, http.MethodGet, "/login/device?"+q.Encode(), nil, func(req *http.Request) {
+			req.Header.Set("Referer", req.URL.String())
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		pty.ExpectMatch("Welcome to Coder")
+		<-doneChan
+		sessionFile, err := cfg.Session().Read()
+		require.NoError(t, err)
+		require.NotEmpty(t, sessionFile)
+		require.NotEqual(t, client.SessionToken(), sessionFile)
+	})
+
 	t.Run("KeepOrganizationContext", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, nil)
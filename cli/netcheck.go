@@ -4,18 +4,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
 
+	"github.com/coder/coder/v2/cli/cliui"
 	"github.com/coder/coder/v2/coderd/healthcheck/derphealth"
+	"github.com/coder/coder/v2/coderd/util/ptr"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/healthsdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
+	"github.com/coder/coder/v2/tailnet"
 	"github.com/coder/serpent"
 )
 
 func (r *RootCmd) netcheck() *serpent.Command {
+	var (
+		history      bool
+		historySince time.Duration
+	)
 	client := new(codersdk.Client)
 
 	cmd := &serpent.Command{
@@ -28,6 +37,10 @@ func (r *RootCmd) netcheck() *serpent.Command {
 			ctx, cancel := context.WithTimeout(inv.Context(), 30*time.Second)
 			defer cancel()
 
+			if history {
+				return netcheckHistory(ctx, inv, client, historySince)
+			}
+
 			connInfo, err := workspacesdk.New(client).AgentConnectionInfoGeneric(ctx)
 			if err != nil {
 				return err
@@ -45,9 +58,15 @@ func (r *RootCmd) netcheck() *serpent.Command {
 				return xerrors.Errorf("failed to run interfaces report: %w", err)
 			}
 
+			proxyReport, err := r.proxyReport(ctx, inv, client, connInfo.DERPMap)
+			if err != nil {
+				return xerrors.Errorf("failed to run proxy report: %w", err)
+			}
+
 			report := healthsdk.ClientNetcheckReport{
 				DERP:       healthsdk.DERPHealthReport(derpReport),
 				Interfaces: ifReport,
+				Proxy:      proxyReport,
 			}
 
 			raw, err := json.MarshalIndent(report, "", "  ")
@@ -68,6 +87,175 @@ func (r *RootCmd) netcheck() *serpent.Command {
 		},
 	}
 
-	cmd.Options = serpent.OptionSet{}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "history",
+			Description: "Print the DERP health recorded by the deployment over time instead of running a report from this machine. Requires permission to view debug information.",
+			Value:       serpent.BoolOf(&history),
+		},
+		{
+			Flag:        "history-since",
+			Description: "How far back to show DERP health history when --history is set.",
+			Default:     (24 * time.Hour).String(),
+			Value:       serpent.DurationOf(&historySince),
+		},
+	}
 	return cmd
 }
+
+type derpHistoryTableRow struct {
+	Region     string `table:"region,nosort"`
+	Node       string `table:"node"`
+	Replica    string `table:"replica"`
+	Checks     int    `table:"checks"`
+	Healthy    string `table:"healthy"`
+	AvgRTT     string `table:"avg rtt"`
+	MaxRTT     string `table:"max rtt"`
+	PacketLoss string `table:"avg packet loss"`
+	Degraded   string `table:"degraded"`
+}
+
+// netcheckHistory prints a summary of every DERP node's recorded health,
+// including the windows in which it was unhealthy or dropping packets.
+func netcheckHistory(ctx context.Context, inv *serpent.Invocation, client *codersdk.Client, since time.Duration) error {
+	history, err := healthsdk.New(client).DERPHealthHistory(ctx, healthsdk.DERPHealthHistoryRequest{
+		Since: time.Now().Add(-since),
+	})
+	if err != nil {
+		return xerrors.Errorf("get derp health history: %w", err)
+	}
+	if len(history.Nodes) == 0 {
+		_, _ = fmt.Fprintln(inv.Stderr, "No DERP health history has been recorded in this time period.")
+		return nil
+	}
+
+	rows := make([]derpHistoryTableRow, 0, len(history.Nodes))
+	for _, node := range history.Nodes {
+		var (
+			healthy  int
+			totalRTT int
+			maxRTT   int
+			exchange int
+			loss     float64
+		)
+		for _, res := range node.Results {
+			if res.Healthy {
+				healthy++
+			}
+			if res.CanExchangeMessages {
+				exchange++
+				totalRTT += res.RoundTripPingMs
+				maxRTT = max(maxRTT, res.RoundTripPingMs)
+				loss += res.PacketLoss
+			}
+		}
+		row := derpHistoryTableRow{
+			Region:     fmt.Sprintf("%s (%d)", node.RegionCode, node.RegionID),
+			Node:       node.NodeName,
+			Replica:    node.ReplicaID.String()[:8],
+			Checks:     len(node.Results),
+			Healthy:    fmt.Sprintf("%.0f%%", 100*float64(healthy)/float64(max(len(node.Results), 1))),
+			AvgRTT:     "-",
+			MaxRTT:     "-",
+			PacketLoss: "-",
+			Degraded:   strings.Join(derpDegradedWindows(node.Results), ", "),
+		}
+		if exchange > 0 {
+			row.AvgRTT = fmt.Sprintf("%dms", totalRTT/exchange)
+			row.MaxRTT = fmt.Sprintf("%dms", maxRTT)
+			row.PacketLoss = fmt.Sprintf("%.1f%%", 100*loss/float64(exchange))
+		}
+		rows = append(rows, row)
+	}
+
+	out, err := cliui.DisplayTable(rows, "", nil)
+	if err != nil {
+		return xerrors.Errorf("render table: %w", err)
+	}
+	_, _ = fmt.Fprintln(inv.Stdout, out)
+	return nil
+}
+
+// derpDegradedWindows returns the periods in which consecutive results were
+// unhealthy or lost packets, in local time.
+func derpDegradedWindows(results []healthsdk.DERPNodeHealthResult) []string {
+	var (
+		windows    []string
+		start, end time.Time
+	)
+	flush := func() {
+		if start.IsZero() {
+			return
+		}
+		start, end = start.Local(), end.Local()
+		window := start.Format("Jan 2 15:04")
+		switch {
+		case end.Equal(start):
+		case end.YearDay() == start.YearDay() && end.Year() == start.Year():
+			window += "-" + end.Format("15:04")
+		default:
+			window += " - " + end.Format("Jan 2 15:04")
+		}
+		windows = append(windows, window)
+		start = time.Time{}
+	}
+	for _, res := range results {
+		if res.Healthy && res.PacketLoss == 0 {
+			flush()
+			continue
+		}
+		if start.IsZero() {
+			start = res.CreatedAt
+		}
+		end = res.CreatedAt
+	}
+	flush()
+	return windows
+}
+
+// proxyReport probes every region and reports the one whose DERP relay is
+// preferred. A region selected by latency is also cached for later commands.
+func (r *RootCmd) proxyReport(ctx context.Context, inv *serpent.Invocation, client *codersdk.Client, derpMap *tailcfg.DERPMap) (healthsdk.ClientProxyReport, error) {
+	var report healthsdk.ClientProxyReport
+
+	regions, err := client.Regions(ctx)
+	if err != nil {
+		return report, xerrors.Errorf("get regions: %w", err)
+	}
+	latencies := probeRegionLatencies(ctx, client, regions)
+	for _, l := range latencies {
+		regionReport := healthsdk.ClientProxyRegionReport{
+			Name:        l.Region.Name,
+			DisplayName: l.Region.DisplayName,
+			Healthy:     l.Region.Healthy,
+		}
+		if l.Err != nil {
+			regionReport.Error = ptr.Ref(l.Err.Error())
+		} else {
+			regionReport.Latency = l.Latency.String()
+			regionReport.LatencyMS = l.Latency.Milliseconds()
+		}
+		report.Regions = append(report.Regions, regionReport)
+	}
+
+	switch strings.ToLower(r.proxy) {
+	case proxyNone:
+		return report, nil
+	case "", proxyAuto:
+		fastest, ok := fastestRegion(latencies)
+		if !ok {
+			return report, nil
+		}
+		report.Selected = fastest.Region.Name
+		report.Source = "latency"
+		writeCachedProxyRegion(ctx, inv, r.createConfig(), client, report.Selected)
+	default:
+		report.Selected, err = findRegion(regions, r.proxy)
+		if err != nil {
+			return report, err
+		}
+		report.Source = "flag"
+	}
+	report.DERPRegionID, _ = tailnet.DERPRegionIDByName(derpMap, report.Selected)
+	return report, nil
+}
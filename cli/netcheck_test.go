@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/codersdk/healthsdk"
 	"github.com/coder/coder/v2/pty/ptytest"
+	"github.com/coder/coder/v2/testutil"
 )
 
 func TestNetcheck(t *testing.T) {
@@ -35,4 +42,56 @@ func TestNetcheck(t *testing.T) {
 	for _, v := range report.DERP.Regions {
 		require.Len(t, v.NodeReports, len(v.Region.Nodes))
 	}
+
+	// The primary region is the only region without workspace proxies, so it
+	// must be selected and cached.
+	require.Equal(t, "primary", report.Proxy.Selected)
+	require.Equal(t, "latency", report.Proxy.Source)
+	require.NotZero(t, report.Proxy.DERPRegionID)
+	require.Len(t, report.Proxy.Regions, 1)
+	require.Nil(t, report.Proxy.Regions[0].Error)
+	require.True(t, config.ProxyRegion().Exists())
+}
+
+func TestNetcheckUnknownProxy(t *testing.T) {
+	t.Parallel()
+
+	pty := ptytest.New(t)
+	config := login(t, pty)
+
+	inv, _ := clitest.New(t, "netcheck", "--global-config", string(config), "--proxy", "doesnotexist")
+	err := inv.Run()
+	require.ErrorContains(t, err, `workspace proxy "doesnotexist" not found`)
+}
+
+func TestNetcheckHistory(t *testing.T) {
+	t.Parallel()
+
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	now := dbtime.Now()
+	replicaID := uuid.New()
+	for _, row := range []database.InsertDERPHealthHistoryParams{
+		{CreatedAt: now.Add(-3 * time.Minute), RegionID: 999, RegionCode: "coder", NodeName: "999a", Healthy: true, CanExchangeMessages: true, RoundTripPingMs: 10},
+		{CreatedAt: now.Add(-2 * time.Minute), RegionID: 999, RegionCode: "coder", NodeName: "999a", Healthy: true, CanExchangeMessages: true, RoundTripPingMs: 30, PacketLoss: 0.2},
+	} {
+		row.ReplicaID = replicaID
+		//nolint:gocritic // Inserting history requires system privileges.
+		require.NoError(t, db.InsertDERPHealthHistory(dbauthz.AsSystemRestricted(ctx), row))
+	}
+
+	inv, root := clitest.New(t, "netcheck", "--history")
+	clitest.SetupConfig(t, client, root)
+	var out bytes.Buffer
+	inv.Stdout = &out
+	clitest.StartWithWaiter(t, inv).RequireSuccess()
+
+	t.Log(out.String())
+	require.Contains(t, out.String(), "coder (999)")
+	require.Contains(t, out.String(), "999a")
+	require.Contains(t, out.String(), "30ms") // max rtt
+	require.Contains(t, out.String(), "20ms") // avg rtt
+	require.Contains(t, out.String(), "10.0%")
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"net/netip"
+	"net/url"
 	"os"
 	"os/signal"
 	"strconv"
@@ -28,6 +29,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 	var (
 		tcpForwards      []string // <port>:<port>
 		udpForwards      []string // <port>:<port>
+		appForwards      []string // <slug>[:<port>]
 		disableAutostart bool
 	)
 	client := new(codersdk.Client)
@@ -56,6 +58,10 @@ func (r *RootCmd) portForward() *serpent.Command {
 				Description: "Port forward specifying the local address to bind to",
 				Command:     "coder port-forward <workspace> --tcp 1.2.3.4:8080:8080",
 			},
+			Example{
+				Description: "Port forward the app \"db\" to port 5432 on your local machine",
+				Command:     "coder port-forward <workspace> --app db:5432",
+			},
 		),
 		Middleware: serpent.Chain(
 			serpent.RequireNArgs(1),
@@ -69,7 +75,7 @@ func (r *RootCmd) portForward() *serpent.Command {
 			if err != nil {
 				return xerrors.Errorf("parse port-forward specs: %w", err)
 			}
-			if len(specs) == 0 {
+			if len(specs) == 0 && len(appForwards) == 0 {
 				return xerrors.New("no port-forwards requested")
 			}
 
@@ -77,6 +83,16 @@ func (r *RootCmd) portForward() *serpent.Command {
 			if err != nil {
 				return err
 			}
+			if len(appForwards) > 0 {
+				appSpecs, err := parseAppForwards(workspaceAgent.Apps, appForwards)
+				if err != nil {
+					return xerrors.Errorf("parse app port-forward specs: %w", err)
+				}
+				specs = append(specs, appSpecs...)
+				if err := checkDuplicatePortForwards(specs); err != nil {
+					return err
+				}
+			}
 			if workspace.LatestBuild.Transition != codersdk.WorkspaceTransitionStart {
 				return xerrors.New("workspace must be in start transition to port-forward")
 			}
@@ -109,6 +125,10 @@ func (r *RootCmd) portForward() *serpent.Command {
 			if !r.disableNetworkTelemetry {
 				opts.EnableTelemetry = true
 			}
+			opts.PreferredRegion, err = r.preferredProxyRegion(ctx, inv, client)
+			if err != nil {
+				return err
+			}
 			conn, err := workspacesdk.New(client).DialAgent(ctx, workspaceAgent.ID, opts)
 			if err != nil {
 				return err
@@ -188,6 +208,12 @@ func (r *RootCmd) portForward() *serpent.Command {
 			Description: "Forward UDP port(s) from the workspace to the local machine. The UDP connection has TCP-like semantics to support stateful UDP protocols.",
 			Value:       serpent.StringArrayOf(&udpForwards),
 		},
+		{
+			Flag:        "app",
+			Env:         "CODER_PORT_FORWARD_APP",
+			Description: "Forward workspace app(s) to the local machine, in the format <slug>[:<local port>]. TCP and UDP apps are forwarded to the same port by default. Unix socket apps are forwarded to a local TCP port, which must be specified.",
+			Value:       serpent.StringArrayOf(&appForwards),
+		},
 		sshDisableAutostartOption(serpent.BoolOf(&disableAutostart)),
 	}
 
@@ -294,19 +320,97 @@ func parsePortForwards(tcpSpecs, udpSpecs []string) ([]portForwardSpec, error) {
 		}
 	}
 
-	// Check for duplicate entries.
+	if err := checkDuplicatePortForwards(specs); err != nil {
+		return nil, err
+	}
+
+	return specs, nil
+}
+
+func checkDuplicatePortForwards(specs []portForwardSpec) error {
 	locals := map[string]struct{}{}
 	for _, spec := range specs {
 		localStr := fmt.Sprintf("%v:%v", spec.listenNetwork, spec.listenAddress)
 		if _, ok := locals[localStr]; ok {
-			return nil, xerrors.Errorf("local %v %v is specified twice", spec.listenNetwork, spec.listenAddress)
+			return xerrors.Errorf("local %v %v is specified twice", spec.listenNetwork, spec.listenAddress)
 		}
 		locals[localStr] = struct{}{}
 	}
+	return nil
+}
+
+// parseAppForwards converts <slug>[:<local port>] specs to port-forwards to
+// the apps' URLs.
+func parseAppForwards(apps []codersdk.WorkspaceApp, appSpecs []string) ([]portForwardSpec, error) {
+	specs := []portForwardSpec{}
+	for _, specEntry := range appSpecs {
+		for _, spec := range strings.Split(specEntry, ",") {
+			slug, rawPort, hasPort := strings.Cut(strings.TrimSpace(spec), ":")
+			app, ok := findApp(apps, slug)
+			if !ok {
+				return nil, xerrors.Errorf("app %q not found on workspace agent", slug)
+			}
+			if app.External || app.URL == "" {
+				return nil, xerrors.Errorf("app %q does not have a URL that can be forwarded", slug)
+			}
+			appURL, err := url.Parse(app.URL)
+			if err != nil {
+				return nil, xerrors.Errorf("parse URL of app %q: %w", slug, err)
+			}
 
+			var localPort uint16
+			if hasPort {
+				localPort, err = parsePort(rawPort)
+				if err != nil {
+					return nil, xerrors.Errorf("failed to parse app port-forward specification %q: %w", spec, err)
+				}
+			}
+
+			forward := portForwardSpec{listenNetwork: "tcp"}
+			switch protocol := codersdk.WorkspaceAppProtocolFromURL(appURL); protocol {
+			case codersdk.WorkspaceAppProtocolUnix:
+				if localPort == 0 {
+					return nil, xerrors.Errorf("app %q is a unix socket, specify a local port with %s:<port>", slug, slug)
+				}
+				forward.dialNetwork = "unix"
+				forward.dialAddress = appURL.Path
+			default:
+				remotePort := appURL.Port()
+				if remotePort == "" {
+					remotePort = "80"
+					if protocol == codersdk.WorkspaceAppProtocolHTTPS {
+						remotePort = "443"
+					}
+				}
+				forward.dialNetwork = "tcp"
+				if protocol == codersdk.WorkspaceAppProtocolUDP {
+					forward.listenNetwork = "udp"
+					forward.dialNetwork = "udp"
+				}
+				forward.dialAddress = net.JoinHostPort("127.0.0.1", remotePort)
+				if localPort == 0 {
+					localPort, err = parsePort(remotePort)
+					if err != nil {
+						return nil, xerrors.Errorf("parse port of app %q: %w", slug, err)
+					}
+				}
+			}
+			forward.listenAddress = netip.AddrPortFrom(netip.AddrFrom4([4]byte{127, 0, 0, 1}), localPort).String()
+			specs = append(specs, forward)
+		}
+	}
 	return specs, nil
 }
 
+func findApp(apps []codersdk.WorkspaceApp, slug string) (codersdk.WorkspaceApp, bool) {
+	for _, app := range apps {
+		if app.Slug == slug {
+			return app, true
+		}
+	}
+	return codersdk.WorkspaceApp{}, false
+}
+
 func parsePort(in string) (uint16, error) {
 	port, err := strconv.ParseUint(strings.TrimSpace(in), 10, 16)
 	if err != nil {
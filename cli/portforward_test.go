@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -23,6 +25,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/dbfake"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisionersdk/proto"
 	"github.com/coder/coder/v2/pty/ptytest"
 	"github.com/coder/coder/v2/testutil"
 )
@@ -287,6 +290,100 @@ func TestPortForward(t *testing.T) {
 	})
 }
 
+func TestPortForward_App(t *testing.T) {
+	t.Parallel()
+
+	// Unix socket paths are limited in length, so avoid t.TempDir().
+	tmpDir, err := os.MkdirTemp("", "coder-port-forward")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(tmpDir) })
+	socketPath := filepath.Join(tmpDir, "app.sock")
+
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tcpPort := setupTestListener(t, tcpListener)
+	unixListener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	_ = setupTestListener(t, unixListener)
+
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	admin := coderdtest.CreateFirstUser(t, client)
+	member, memberUser := coderdtest.CreateAnotherUser(t, client, admin.OrganizationID)
+	r := dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: admin.OrganizationID,
+		OwnerID:        memberUser.ID,
+	}).WithAgent(func(agents []*proto.Agent) []*proto.Agent {
+		agents[0].Apps = []*proto.App{
+			{Slug: "tcp", DisplayName: "TCP", Url: "tcp://127.0.0.1:" + tcpPort},
+			{Slug: "sock", DisplayName: "Socket", Url: "unix://" + socketPath},
+			{Slug: "web", DisplayName: "Web", Url: "https://coder.com", External: true},
+		}
+		return agents
+	}).Do()
+	_ = agenttest.New(t, client.URL, r.AgentToken)
+	coderdtest.AwaitWorkspaceAgents(t, client, r.Workspace.ID)
+
+	t.Run("TCPAndUnix", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "port-forward", r.Workspace.Name, "--app", "tcp", "--app", "sock:5678")
+		clitest.SetupConfig(t, member, root)
+		pty := ptytest.New(t).Attach(inv)
+		iNet := newInProcNet()
+		inv.Net = iNet
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		errC := make(chan error)
+		go func() {
+			errC <- inv.WithContext(ctx).Run()
+		}()
+		pty.ExpectMatchContext(ctx, "Ready!")
+
+		dialCtx, dialCtxCancel := context.WithTimeout(ctx, testutil.WaitShort)
+		defer dialCtxCancel()
+		c1, err := iNet.dial(dialCtx, addr{"tcp", "127.0.0.1:" + tcpPort})
+		require.NoError(t, err, "open connection to tcp app")
+		defer c1.Close()
+		c2, err := iNet.dial(dialCtx, addr{"tcp", "127.0.0.1:5678"})
+		require.NoError(t, err, "open connection to unix socket app")
+		defer c2.Close()
+		testDial(t, c2)
+		testDial(t, c1)
+
+		cancel()
+		err = <-errC
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("UnixWithoutPort", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "port-forward", r.Workspace.Name, "--app", "sock")
+		clitest.SetupConfig(t, member, root)
+		err := inv.Run()
+		require.ErrorContains(t, err, "specify a local port")
+	})
+
+	t.Run("External", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "port-forward", r.Workspace.Name, "--app", "web")
+		clitest.SetupConfig(t, member, root)
+		err := inv.Run()
+		require.ErrorContains(t, err, "can be forwarded")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "port-forward", r.Workspace.Name, "--app", "missing")
+		clitest.SetupConfig(t, member, root)
+		err := inv.Run()
+		require.ErrorContains(t, err, "not found")
+	})
+}
+
 // runAgent creates a fake workspace and starts an agent locally for that
 // workspace. The agent will be cleaned up on test completion.
 // nolint:unused
@@ -310,7 +407,7 @@ func runAgent(t *testing.T, client *codersdk.Client, owner uuid.UUID, db databas
 }
 
 // setupTestListener starts accepting connections and echoing a single packet.
-// Returns the listener and the listen port.
+// Returns the listen port, or the socket path for unix listeners.
 func setupTestListener(t *testing.T, l net.Listener) string {
 	t.Helper()
 
@@ -342,6 +439,9 @@ func setupTestListener(t *testing.T, l net.Listener) string {
 	}()
 
 	addr := l.Addr().String()
+	if l.Addr().Network() == "unix" {
+		return addr
+	}
 	_, port, err := net.SplitHostPort(addr)
 	require.NoErrorf(t, err, "split non-Unix listen path %q", addr)
 	addr = port
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/cli/config"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/serpent"
+)
+
+const (
+	// proxyAuto selects the healthy region with the lowest latency.
+	proxyAuto = "auto"
+	// proxyNone disables DERP region preference entirely.
+	proxyNone = "none"
+
+	// proxyRegionCacheTTL is how long a latency-selected region is reused
+	// before the regions are probed again.
+	proxyRegionCacheTTL = time.Hour
+	// proxyProbeSamples is the number of latency checks sent to each region.
+	// The fastest sample is used so that connection setup is not counted.
+	proxyProbeSamples = 3
+	proxyProbeTimeout = 5 * time.Second
+)
+
+// cachedProxyRegion is the on-disk format of config.Root.ProxyRegion.
+type cachedProxyRegion struct {
+	URL       string    `json:"url"`
+	Region    string    `json:"region"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// regionLatency is the result of probing a single region.
+type regionLatency struct {
+	Region  codersdk.Region
+	Latency time.Duration
+	Err     error
+}
+
+// preferredProxyRegion returns the name of the region whose DERP relay should
+// be favored when connecting to workspaces. An empty string means no region is
+// preferred. Only an unknown region passed with --proxy is an error; failing
+// to measure latency falls back to no preference.
+func (r *RootCmd) preferredProxyRegion(ctx context.Context, inv *serpent.Invocation, client *codersdk.Client) (string, error) {
+	switch strings.ToLower(r.proxy) {
+	case proxyNone:
+		return "", nil
+	case "", proxyAuto:
+	default:
+		regions, err := client.Regions(ctx)
+		if err != nil {
+			return "", xerrors.Errorf("get regions: %w", err)
+		}
+		return findRegion(regions, r.proxy)
+	}
+
+	conf := r.createConfig()
+	if name, ok := readCachedProxyRegion(conf, client); ok {
+		return name, nil
+	}
+
+	regions, err := client.Regions(ctx)
+	if err != nil {
+		inv.Logger.Debug(ctx, "failed to get regions, not preferring a DERP region", slog.Error(err))
+		return "", nil
+	}
+	latencies := probeRegionLatencies(ctx, client, regions)
+	fastest, ok := fastestRegion(latencies)
+	if !ok {
+		inv.Logger.Debug(ctx, "no region responded to latency checks, not preferring a DERP region")
+		return "", nil
+	}
+	writeCachedProxyRegion(ctx, inv, conf, client, fastest.Region.Name)
+	return fastest.Region.Name, nil
+}
+
+// findRegion returns the name of the region matching name, ignoring case.
+func findRegion(regions []codersdk.Region, name string) (string, error) {
+	names := make([]string, 0, len(regions))
+	for _, region := range regions {
+		if strings.EqualFold(region.Name, name) {
+			return region.Name, nil
+		}
+		names = append(names, region.Name)
+	}
+	sort.Strings(names)
+	return "", xerrors.Errorf("workspace proxy %q not found, available regions: %s", name, strings.Join(names, ", "))
+}
+
+func readCachedProxyRegion(conf config.Root, client *codersdk.Client) (string, bool) {
+	raw, err := conf.ProxyRegion().Read()
+	if err != nil {
+		return "", false
+	}
+	var cached cachedProxyRegion
+	if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+		return "", false
+	}
+	if cached.URL != client.URL.String() || cached.Region == "" {
+		return "", false
+	}
+	if time.Since(cached.CheckedAt) > proxyRegionCacheTTL {
+		return "", false
+	}
+	return cached.Region, true
+}
+
+func writeCachedProxyRegion(ctx context.Context, inv *serpent.Invocation, conf config.Root, client *codersdk.Client, region string) {
+	raw, err := json.Marshal(cachedProxyRegion{
+		URL:       client.URL.String(),
+		Region:    region,
+		CheckedAt: time.Now(),
+	})
+	if err == nil {
+		err = conf.ProxyRegion().Write(string(raw))
+	}
+	if err != nil {
+		// The cache is an optimization, so don't fail the command.
+		inv.Logger.Debug(ctx, "failed to cache proxy region", slog.Error(err))
+	}
+}
+
+// probeRegionLatencies measures the latency to every healthy region
+// concurrently. Unhealthy regions are returned with an error without being
+// probed.
+func probeRegionLatencies(ctx context.Context, client *codersdk.Client, regions []codersdk.Region) []regionLatency {
+	ctx, cancel := context.WithTimeout(ctx, proxyProbeTimeout)
+	defer cancel()
+
+	results := make([]regionLatency, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		results[i].Region = region
+		if !region.Healthy {
+			results[i].Err = xerrors.New("region is unhealthy")
+			continue
+		}
+		if region.PathAppURL == "" {
+			results[i].Err = xerrors.New("region has no access URL")
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *regionLatency) {
+			defer wg.Done()
+			result.Latency, result.Err = probeRegionLatency(ctx, client.HTTPClient, result.Region.PathAppURL)
+		}(&results[i])
+	}
+	wg.Wait()
+	return results
+}
+
+// probeRegionLatency returns the fastest of several round trips to the
+// latency-check endpoint served by coderd and every workspace proxy.
+func probeRegionLatency(ctx context.Context, httpClient *http.Client, accessURL string) (time.Duration, error) {
+	target := strings.TrimSuffix(accessURL, "/") + "/latency-check"
+	var fastest time.Duration
+	for i := 0; i < proxyProbeSamples; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return 0, xerrors.Errorf("create request: %w", err)
+		}
+		start := time.Now()
+		res, err := httpClient.Do(req)
+		if err != nil {
+			return 0, xerrors.Errorf("request latency check: %w", err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		latency := time.Since(start)
+		if res.StatusCode != http.StatusOK {
+			return 0, xerrors.Errorf("unexpected status code %d from latency check", res.StatusCode)
+		}
+		if fastest == 0 || latency < fastest {
+			fastest = latency
+		}
+	}
+	return fastest, nil
+}
+
+// fastestRegion returns the successfully probed region with the lowest
+// latency.
+func fastestRegion(latencies []regionLatency) (regionLatency, bool) {
+	var (
+		fastest regionLatency
+		found   bool
+	)
+	for _, l := range latencies {
+		if l.Err != nil {
+			continue
+		}
+		if !found || l.Latency < fastest.Latency {
+			fastest = l
+			found = true
+		}
+	}
+	return fastest, found
+}
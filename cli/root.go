@@ -66,6 +66,7 @@ const (
 	varVerbose                 = "verbose"
 	varDisableDirect           = "disable-direct-connections"
 	varDisableNetworkTelemetry = "disable-network-telemetry"
+	varProxy                   = "proxy"
 
 	notLoggedInMessage = "You are not logged in. Try logging in using 'coder login <url>'."
 
@@ -101,6 +102,7 @@ func (r *RootCmd) CoreSubcommands() []*serpent.Command {
 
 		// Workspace Commands
 		r.autoupdate(),
+		r.builds(),
 		r.configSSH(),
 		r.create(),
 		r.deleteWorkspace(),
@@ -439,6 +441,14 @@ func (r *RootCmd) Command(subcommands []*serpent.Command) (*serpent.Command, err
 			Value:       serpent.BoolOf(&r.disableDirect),
 			Group:       globalGroup,
 		},
+		{
+			Flag:        varProxy,
+			Env:         "CODER_PROXY",
+			Description: "Name of the workspace proxy region whose DERP relay is preferred when connecting to workspaces. By default, the healthy region with the lowest latency is selected and cached for an hour. Set to \"none\" to disable the preference.",
+			Default:     proxyAuto,
+			Value:       serpent.StringOf(&r.proxy),
+			Group:       globalGroup,
+		},
 		{
 			Flag:        varDisableNetworkTelemetry,
 			Env:         "CODER_DISABLE_NETWORK_TELEMETRY",
@@ -491,6 +501,7 @@ type RootCmd struct {
 	versionFlag    bool
 	disableDirect  bool
 	debugHTTP      bool
+	proxy          string
 
 	disableNetworkTelemetry bool
 	noVersionCheck          bool
@@ -68,6 +68,7 @@ import (
 	"github.com/coder/coder/v2/cli/config"
 	"github.com/coder/coder/v2/coderd"
 	"github.com/coder/coder/v2/coderd/autobuild"
+	"github.com/coder/coder/v2/coderd/canaryrollout"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/awsiamrds"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
@@ -77,14 +78,17 @@ import (
 	"github.com/coder/coder/v2/coderd/database/migrations"
 	"github.com/coder/coder/v2/coderd/database/pubsub"
 	"github.com/coder/coder/v2/coderd/devtunnel"
+	"github.com/coder/coder/v2/coderd/driftdetector"
 	"github.com/coder/coder/v2/coderd/externalauth"
 	"github.com/coder/coder/v2/coderd/gitsshkey"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/ldapauth"
 	"github.com/coder/coder/v2/coderd/notifications"
 	"github.com/coder/coder/v2/coderd/oauthpki"
 	"github.com/coder/coder/v2/coderd/prometheusmetrics"
 	"github.com/coder/coder/v2/coderd/prometheusmetrics/insights"
 	"github.com/coder/coder/v2/coderd/promoauth"
+	"github.com/coder/coder/v2/coderd/rolegrant"
 	"github.com/coder/coder/v2/coderd/schedule"
 	"github.com/coder/coder/v2/coderd/telemetry"
 	"github.com/coder/coder/v2/coderd/tracing"
@@ -193,6 +197,8 @@ func createOIDCConfig(ctx context.Context, logger slog.Logger, vals *codersdk.De
 		UserRoleField:       vals.OIDC.UserRoleField.String(),
 		UserRoleMapping:     vals.OIDC.UserRoleMapping.Value,
 		UserRolesDefault:    vals.OIDC.UserRolesDefault.GetSlice(),
+		OrganizationField:   vals.OIDC.OrganizationField.String(),
+		OrganizationMapping: vals.OIDC.OrganizationMapping.Value,
 		SignInText:          vals.OIDC.SignInText.String(),
 		SignupsDisabledText: vals.OIDC.SignupsDisabledText.String(),
 		IconURL:             vals.OIDC.IconURL.String(),
@@ -200,6 +206,64 @@ func createOIDCConfig(ctx context.Context, logger slog.Logger, vals *codersdk.De
 	}, nil
 }
 
+func createLDAPConfig(vals *codersdk.DeploymentValues) (*coderd.LDAPConfig, error) {
+	u, err := url.Parse(vals.LDAP.URL.String())
+	if err != nil {
+		return nil, xerrors.Errorf("parse ldap url: %w", err)
+	}
+	switch u.Scheme {
+	case "ldap":
+	case "ldaps":
+		if vals.LDAP.StartTLS {
+			return nil, xerrors.Errorf("'ldap-start-tls' cannot be used with an ldaps:// URL")
+		}
+	default:
+		return nil, xerrors.Errorf("LDAP URL must use the ldap:// or ldaps:// scheme, got %q", u.Scheme)
+	}
+	if !strings.Contains(vals.LDAP.SearchFilter.String(), ldapauth.UsernamePlaceholder) {
+		return nil, xerrors.Errorf("'ldap-search-filter' must contain %q", ldapauth.UsernamePlaceholder)
+	}
+	if vals.LDAP.EmailAttribute == "" {
+		return nil, xerrors.Errorf("'ldap-email-attribute' must be set")
+	}
+
+	//nolint:gosec // Skipping verification is opt-in and documented as insecure.
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: vals.LDAP.InsecureSkipVerify.Value(),
+	}
+	if vals.LDAP.CAFile != "" {
+		data, err := os.ReadFile(vals.LDAP.CAFile.String())
+		if err != nil {
+			return nil, xerrors.Errorf("read %q: %w", vals.LDAP.CAFile.String(), err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, xerrors.Errorf("failed to parse CA certificate in ldap-ca-file")
+		}
+	}
+
+	return &coderd.LDAPConfig{
+		Config: ldapauth.Config{
+			URL:               vals.LDAP.URL.String(),
+			StartTLS:          vals.LDAP.StartTLS.Value(),
+			TLSConfig:         tlsConfig,
+			BindDN:            vals.LDAP.BindDN.String(),
+			BindPassword:      vals.LDAP.BindPassword.String(),
+			SearchBaseDN:      vals.LDAP.SearchBaseDN.String(),
+			SearchFilter:      vals.LDAP.SearchFilter.String(),
+			UsernameAttribute: vals.LDAP.UsernameAttribute.String(),
+			EmailAttribute:    vals.LDAP.EmailAttribute.String(),
+			NameAttribute:     vals.LDAP.NameAttribute.String(),
+			GroupAttribute:    vals.LDAP.GroupAttribute.String(),
+		},
+		AllowSignups:        vals.LDAP.AllowSignups.Value(),
+		GroupMapping:        vals.LDAP.GroupMapping.Value,
+		GroupFilter:         vals.LDAP.GroupRegexFilter.Value(),
+		CreateMissingGroups: vals.LDAP.GroupAutoCreate.Value(),
+	}, nil
+}
+
 func afterCtx(ctx context.Context, fn func()) {
 	go func() {
 		<-ctx.Done()
@@ -588,6 +652,7 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				Telemetry:                   telemetry.NewNoop(),
 				MetricsCacheRefreshInterval: vals.MetricsCacheRefreshInterval.Value(),
 				AgentStatsRefreshInterval:   vals.AgentStatRefreshInterval.Value(),
+				DERPHealthHistoryInterval:   vals.Healthcheck.DERPHistoryInterval.Value(),
 				DeploymentValues:            vals,
 				// Do not pass secret values to DeploymentOptions. All values should be read from
 				// the DeploymentValues instead, this just serves to indicate the source of each
@@ -674,6 +739,20 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				options.OIDCConfig = oc
 			}
 
+			if vals.LDAP.URL != "" {
+				if vals.LDAP.InsecureSkipVerify {
+					logger.Warn(ctx, "coder will not verify the certificate of the LDAP server")
+				}
+				lc, err := createLDAPConfig(vals)
+				if err != nil {
+					return xerrors.Errorf("create ldap config: %w", err)
+				}
+				if u, _ := url.Parse(lc.URL); u.Scheme == "ldap" && !lc.StartTLS {
+					logger.Warn(ctx, "LDAP passwords will be sent to the LDAP server unencrypted, use an ldaps:// URL or enable ldap-start-tls")
+				}
+				options.LDAPConfig = lc
+			}
+
 			experiments := coderd.ReadExperiments(
 				options.Logger, options.DeploymentValues.Experiments.Value(),
 			)
@@ -1090,10 +1169,30 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 
 			hangDetectorTicker := time.NewTicker(vals.JobHangDetectorInterval.Value())
 			defer hangDetectorTicker.Stop()
-			hangDetector := unhanger.New(ctx, options.Database, options.Pubsub, logger, hangDetectorTicker.C)
+			hangDetector := unhanger.New(ctx, options.Database, options.Pubsub, logger, hangDetectorTicker.C).WithAuditor(&coderAPI.Auditor)
 			hangDetector.Start()
 			defer hangDetector.Close()
 
+			driftDetectorTicker := time.NewTicker(vals.AutobuildPollInterval.Value())
+			defer driftDetectorTicker.Stop()
+			driftDetector := driftdetector.New(ctx, options.Database, options.Pubsub, logger, driftDetectorTicker.C)
+			driftDetector.Start()
+			defer driftDetector.Close()
+
+			canaryEvaluatorTicker := time.NewTicker(vals.AutobuildPollInterval.Value())
+			defer canaryEvaluatorTicker.Stop()
+			canaryEvaluator := canaryrollout.New(ctx, options.Database, logger, canaryEvaluatorTicker.C).WithAuditor(&coderAPI.Auditor)
+			canaryEvaluator.Start()
+			defer canaryEvaluator.Close()
+
+			roleGrantExpirerTicker := time.NewTicker(vals.AutobuildPollInterval.Value())
+			defer roleGrantExpirerTicker.Stop()
+			roleGrantExpirer := rolegrant.New(ctx, options.Database, logger, roleGrantExpirerTicker.C).
+				WithAuditor(&coderAPI.Auditor).
+				WithNotificationsEnqueuer(coderAPI.NotificationsEnqueuer)
+			roleGrantExpirer.Start()
+			defer roleGrantExpirer.Close()
+
 			waitForProvisionerJobs := false
 			// Currently there is no way to ask the server to shut
 			// itself down, so any exit signal will result in a non-zero
@@ -1467,11 +1566,17 @@ func newProvisionerDaemon(
 				}
 			}()
 			connector[string(database.ProvisionerTypeEcho)] = sdkproto.NewDRPCProvisionerClient(echoClient)
-		case codersdk.ProvisionerTypeTerraform:
+		case codersdk.ProvisionerTypeTerraform, codersdk.ProvisionerTypeTofu:
+			// Terraform and OpenTofu keep separate caches since providers
+			// are resolved from different registries.
+			engine := terraform.Engine(provisionerType)
 			tfDir := filepath.Join(cacheDir, "tf")
+			if engine == terraform.EngineOpenTofu {
+				tfDir = filepath.Join(cacheDir, "tofu")
+			}
 			err = os.MkdirAll(tfDir, 0o700)
 			if err != nil {
-				return nil, xerrors.Errorf("mkdir terraform dir: %w", err)
+				return nil, xerrors.Errorf("mkdir %s dir: %w", engine.BinaryName(), err)
 			}
 
 			tracer := coderAPI.TracerProvider.Tracer(tracing.TracerName)
@@ -1491,11 +1596,15 @@ func newProvisionerDaemon(
 				err := terraform.Serve(ctx, &terraform.ServeOptions{
 					ServeOptions: &provisionersdk.ServeOptions{
 						Listener:      terraformServer,
-						Logger:        logger.Named("terraform"),
+						Logger:        logger.Named(engine.BinaryName()),
 						WorkDirectory: workDir,
 					},
-					CachePath: tfDir,
-					Tracer:    tracer,
+					Engine:         engine,
+					CachePath:      tfDir,
+					CacheMaxSize:   cfg.Provisioner.CacheMaxSize.Value(),
+					CacheMetrics:   metrics.TerraformCache,
+					ProviderMirror: cfg.Provisioner.ProviderMirror.Value(),
+					Tracer:         tracer,
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -1505,7 +1614,7 @@ func newProvisionerDaemon(
 				}
 			}()
 
-			connector[string(database.ProvisionerTypeTerraform)] = sdkproto.NewDRPCProvisionerClient(terraformClient)
+			connector[string(provisionerType)] = sdkproto.NewDRPCProvisionerClient(terraformClient)
 		default:
 			return nil, xerrors.Errorf("unknown provisioner type %q", provisionerType)
 		}
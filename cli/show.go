@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"fmt"
+	"time"
+
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
+
 	"github.com/coder/coder/v2/cli/cliui"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/serpent"
@@ -26,10 +31,40 @@ func (r *RootCmd) show() *serpent.Command {
 			if err != nil {
 				return xerrors.Errorf("get workspace: %w", err)
 			}
-			return cliui.WorkspaceResources(inv.Stdout, workspace.LatestBuild.Resources, cliui.WorkspaceResourcesOptions{
+			err = cliui.WorkspaceResources(inv.Stdout, workspace.LatestBuild.Resources, cliui.WorkspaceResourcesOptions{
 				WorkspaceName: workspace.Name,
 				ServerVersion: buildInfo.Version,
 			})
+			if err != nil {
+				return err
+			}
+
+			// Drift is only informational, and older servers do not have the
+			// endpoint, so failing to fetch it must not fail the command.
+			drift, err := client.WorkspaceDrift(inv.Context(), workspace.ID)
+			if err != nil {
+				inv.Logger.Debug(inv.Context(), "failed to get workspace drift", slog.Error(err))
+				return nil
+			}
+			if len(drift.Resources) == 0 || drift.CheckedAt == nil {
+				return nil
+			}
+			lines := make([]string, 0, len(drift.Resources))
+			for _, change := range drift.Resources {
+				lines = append(lines, fmt.Sprintf("%s was %s outside of Coder", change.Address, driftVerb(change.Action)))
+			}
+			cliui.Warn(inv.Stdout,
+				fmt.Sprintf("Resources have drifted from the latest build (checked %s ago).", time.Since(*drift.CheckedAt).Truncate(time.Second)),
+				lines...,
+			)
+			return nil
 		},
 	}
 }
+
+func driftVerb(action codersdk.ResourceChangeAction) string {
+	if action == codersdk.ResourceChangeActionDelete {
+		return "deleted"
+	}
+	return "changed"
+}
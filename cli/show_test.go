@@ -2,12 +2,23 @@ package cli_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
 
 	"github.com/coder/coder/v2/cli/clitest"
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/driftdetector"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisioner/echo"
+	"github.com/coder/coder/v2/provisionersdk/proto"
 	"github.com/coder/coder/v2/pty/ptytest"
+	"github.com/coder/coder/v2/testutil"
 )
 
 func TestShow(t *testing.T) {
@@ -52,4 +63,62 @@ func TestShow(t *testing.T) {
 		}
 		<-doneChan
 	})
+	t.Run("Drift", func(t *testing.T) {
+		t.Parallel()
+
+		db, ps := dbtestutil.NewDB(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			Database:                 db,
+			Pubsub:                   ps,
+			IncludeProvisionerDaemon: true,
+		})
+		owner := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ApplyComplete,
+			ProvisionPlan: []*proto.Response{{
+				Type: &proto.Response_Plan{
+					Plan: &proto.PlanComplete{
+						ResourceChanges: []*proto.ResourceChange{
+							{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: "update"},
+							{Address: "docker_volume.home", Type: "docker_volume", Name: "home", Action: "delete"},
+						},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DriftDetectionIntervalMillis: ptr.Ref(time.Hour.Milliseconds()),
+		})
+		require.NoError(t, err)
+
+		tickCh := make(chan time.Time)
+		statsCh := make(chan driftdetector.Stats)
+		detector := driftdetector.New(ctx, db, ps, slogtest.Make(t, nil), tickCh).WithStatsChannel(statsCh)
+		detector.Start()
+		t.Cleanup(detector.Close)
+		tickCh <- time.Now()
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		require.Eventually(t, func() bool {
+			drift, err := member.WorkspaceDrift(ctx, workspace.ID)
+			return assert.NoError(t, err) && drift.CheckedAt != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+
+		inv, root := clitest.New(t, "show", workspace.Name)
+		clitest.SetupConfig(t, member, root)
+		pty := ptytest.New(t).Attach(inv)
+		clitest.Start(t, inv)
+
+		pty.ExpectMatch("Resources have drifted from the latest build")
+		pty.ExpectMatch("docker_container.dev was changed outside of Coder")
+		pty.ExpectMatch("docker_volume.home was deleted outside of Coder")
+	})
 }
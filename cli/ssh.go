@@ -241,11 +241,16 @@ func (r *RootCmd) ssh() *serpent.Command {
 			if r.disableDirect {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
+			preferredRegion, err := r.preferredProxyRegion(ctx, inv, client)
+			if err != nil {
+				return err
+			}
 			conn, err := workspacesdk.New(client).
 				DialAgent(ctx, workspaceAgent.ID, &workspacesdk.DialAgentOptions{
 					Logger:          logger,
 					BlockEndpoints:  r.disableDirect,
 					EnableTelemetry: !r.disableNetworkTelemetry,
+					PreferredRegion: preferredRegion,
 				})
 			if err != nil {
 				return xerrors.Errorf("dial agent: %w", err)
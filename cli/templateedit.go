@@ -11,6 +11,7 @@ import (
 	"github.com/coder/serpent"
 
 	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/coderd/util/ptr"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -29,6 +30,8 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 		failureTTL                     time.Duration
 		dormancyThreshold              time.Duration
 		dormancyAutoDeletion           time.Duration
+		buildTimeout                   time.Duration
+		buildCancelGracePeriod         time.Duration
 		allowUserCancelWorkspaceJobs   bool
 		allowUserAutostart             bool
 		allowUserAutostop              bool
@@ -161,6 +164,14 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				deprecated = &deprecationMessage
 			}
 
+			var buildTimeoutMillis, buildCancelGracePeriodMillis *int64
+			if userSetOption(inv, "build-timeout") {
+				buildTimeoutMillis = ptr.Ref(buildTimeout.Milliseconds())
+			}
+			if userSetOption(inv, "build-cancel-grace-period") {
+				buildCancelGracePeriodMillis = ptr.Ref(buildCancelGracePeriod.Milliseconds())
+			}
+
 			var disableEveryoneGroup bool
 			if userSetOption(inv, "private") {
 				disableEveryoneGroup = disableEveryone
@@ -189,6 +200,8 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 				RequireActiveVersion:           requireActiveVersion,
 				DeprecationMessage:             deprecated,
 				DisableEveryoneGroupAccess:     disableEveryoneGroup,
+				BuildTimeoutMillis:             buildTimeoutMillis,
+				BuildCancelGracePeriodMillis:   buildCancelGracePeriodMillis,
 			}
 
 			_, err = client.UpdateTemplateMeta(inv.Context(), template.ID, req)
@@ -270,6 +283,16 @@ func (r *RootCmd) templateEdit() *serpent.Command {
 			Default:     "0h",
 			Value:       serpent.DurationOf(&dormancyAutoDeletion),
 		},
+		{
+			Flag:        "build-timeout",
+			Description: "Specify the maximum duration of a workspace build, after which it is canceled. It must be at least a minute. Use 0 to remove the limit.",
+			Value:       serpent.DurationOf(&buildTimeout),
+		},
+		{
+			Flag:        "build-cancel-grace-period",
+			Description: "Specify how long a canceled workspace build may take to exit gracefully before it is failed. Use 0 for the provisioner daemon default.",
+			Value:       serpent.DurationOf(&buildCancelGracePeriod),
+		},
 		{
 			Flag:        "allow-user-cancel-workspace-jobs",
 			Description: "Allow users to cancel in-progress workspace jobs.",
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/buildinfo"
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/provisioner/terraform/tfparse"
+	"github.com/coder/pretty"
+	"github.com/coder/serpent"
+)
+
+func (*RootCmd) templateLint() *serpent.Command {
+	var directory string
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			findings, ok := data.([]tfparse.LintFinding)
+			if !ok {
+				return nil, xerrors.Errorf("expected type %T, got %T", findings, data)
+			}
+			return renderLintFindings(findings), nil
+		}),
+		sarifFormat{},
+	)
+	cmd := &serpent.Command{
+		Use:   "lint",
+		Short: "Check a template for problems without pushing it",
+		Long: "The template is checked offline for problems that would fail a template import, such as duplicate parameter names or invalid app slugs, and for violations of best practices.\n\n" + FormatExamples(
+			Example{
+				Description: "Lint the template in the current directory",
+				Command:     "coder templates lint",
+			},
+			Example{
+				Description: "Report problems in SARIF format for code scanning tools",
+				Command:     "coder templates lint --output sarif > results.sarif",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			findings, err := tfparse.Lint(directory)
+			if err != nil {
+				return err
+			}
+
+			out, err := formatter.Format(inv.Context(), findings)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			if err != nil {
+				return err
+			}
+
+			errors := 0
+			for _, finding := range findings {
+				if finding.Severity == tfparse.SeverityError {
+					errors++
+				}
+			}
+			if errors > 0 {
+				return xerrors.Errorf("template has %d error(s)", errors)
+			}
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		{
+			Flag:          "directory",
+			FlagShorthand: "d",
+			Description:   "Specify the directory of the template to lint.",
+			Default:       ".",
+			Value:         serpent.StringOf(&directory),
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func renderLintFindings(findings []tfparse.LintFinding) string {
+	if len(findings) == 0 {
+		return "No problems found."
+	}
+
+	var (
+		sb       strings.Builder
+		errors   int
+		warnings int
+	)
+	for _, finding := range findings {
+		location := "template"
+		if finding.Filename != "" {
+			location = fmt.Sprintf("%s:%d:%d", finding.Filename, finding.Line, finding.Column)
+		}
+		severity := pretty.Sprint(cliui.DefaultStyles.Warn, string(finding.Severity))
+		if finding.Severity == tfparse.SeverityError {
+			severity = pretty.Sprint(cliui.DefaultStyles.Error, string(finding.Severity))
+			errors++
+		} else {
+			warnings++
+		}
+		_, _ = fmt.Fprintf(&sb, "%s: %s: %s %s\n", location, severity, finding.Message,
+			pretty.Sprint(cliui.DefaultStyles.Placeholder, "("+finding.Rule+")"))
+	}
+	_, _ = fmt.Fprintf(&sb, "\n%d error(s), %d warning(s)", errors, warnings)
+	return sb.String()
+}
+
+// sarifFormat outputs lint findings in the Static Analysis Results
+// Interchange Format, which is understood by code scanning tools.
+type sarifFormat struct{}
+
+var _ cliui.OutputFormat = sarifFormat{}
+
+func (sarifFormat) ID() string {
+	return "sarif"
+}
+
+func (sarifFormat) AttachOptions(_ *serpent.OptionSet) {}
+
+func (sarifFormat) Format(_ context.Context, data any) (string, error) {
+	findings, ok := data.([]tfparse.LintFinding)
+	if !ok {
+		return "", xerrors.Errorf("expected type %T, got %T", findings, data)
+	}
+
+	rules := make([]sarifRule, 0, len(tfparse.LintRules))
+	for _, rule := range tfparse.LintRules {
+		rules = append(rules, sarifRule{
+			ID:               rule.ID,
+			ShortDescription: sarifMessage{Text: rule.Description},
+			DefaultConfiguration: sarifConfiguration{
+				Level: string(rule.Severity),
+			},
+		})
+	}
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		result := sarifResult{
+			RuleID:  finding.Rule,
+			Level:   string(finding.Severity),
+			Message: sarifMessage{Text: finding.Message},
+		}
+		if finding.Filename != "" {
+			result.Locations = []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: finding.Filename},
+					Region: sarifRegion{
+						StartLine:   finding.Line,
+						StartColumn: finding.Column,
+					},
+				},
+			}}
+		}
+		results = append(results, result)
+	}
+
+	out, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{
+				Driver: sarifDriver{
+					Name:           "coder templates lint",
+					Version:        buildinfo.Version(),
+					InformationURI: "https://coder.com/docs/reference/cli/templates_lint",
+					Rules:          rules,
+				},
+			},
+			Results: results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		return "", xerrors.Errorf("marshal sarif: %w", err)
+	}
+	return string(out), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
@@ -0,0 +1,123 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/clitest"
+)
+
+func TestTemplateLint(t *testing.T) {
+	t.Parallel()
+
+	writeTemplate := func(t *testing.T, content string) string {
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(content), 0o600)
+		require.NoError(t, err)
+		return dir
+	}
+
+	t.Run("NoProblems", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeTemplate(t, `resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", "--directory", dir)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.Run(t, inv)
+		require.Contains(t, out.String(), "No problems found.")
+	})
+
+	t.Run("Warnings", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeTemplate(t, `resource "coder_agent" "main" {
+  os             = "linux"
+  arch           = "amd64"
+  startup_script = "echo hello"
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", "--directory", dir)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.Run(t, inv)
+		require.Contains(t, out.String(), "main.tf:1:1")
+		require.Contains(t, out.String(), "agent-startup-script-behavior")
+		require.Contains(t, out.String(), "0 error(s), 1 warning(s)")
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeTemplate(t, `resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+
+resource "coder_app" "Invalid_Slug" {
+  agent_id = coder_agent.main.id
+  command  = "htop"
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", "--directory", dir)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		err := inv.Run()
+		require.ErrorContains(t, err, "template has 1 error(s)")
+		require.Contains(t, out.String(), "invalid-app-slug")
+	})
+
+	t.Run("SARIF", func(t *testing.T) {
+		t.Parallel()
+
+		dir := writeTemplate(t, `resource "coder_agent" "main" {
+  os             = "linux"
+  arch           = "amd64"
+  startup_script = "echo hello"
+}
+`)
+		inv, _ := clitest.New(t, "templates", "lint", "--directory", dir, "--output", "sarif")
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.Run(t, inv)
+
+		var log struct {
+			Version string `json:"version"`
+			Runs    []struct {
+				Results []struct {
+					RuleID    string `json:"ruleId"`
+					Level     string `json:"level"`
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI string `json:"uri"`
+							} `json:"artifactLocation"`
+							Region struct {
+								StartLine int `json:"startLine"`
+							} `json:"region"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &log))
+		require.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+		require.Len(t, log.Runs[0].Results, 1)
+		result := log.Runs[0].Results[0]
+		require.Equal(t, "agent-startup-script-behavior", result.RuleID)
+		require.Equal(t, "warning", result.Level)
+		require.Len(t, result.Locations, 1)
+		require.Equal(t, "main.tf", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		require.Equal(t, 1, result.Locations[0].PhysicalLocation.Region.StartLine)
+	})
+}
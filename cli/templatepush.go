@@ -91,6 +91,11 @@ func (r *RootCmd) templatePush() *serpent.Command {
 				}
 			}
 
+			provisionerType, err := uploadFlags.provisioner(provisioner, template)
+			if err != nil {
+				return err
+			}
+
 			resp, err := uploadFlags.upload(inv, client)
 			if err != nil {
 				return err
@@ -123,7 +128,7 @@ func (r *RootCmd) templatePush() *serpent.Command {
 				Message:            message,
 				Client:             client,
 				Organization:       organization,
-				Provisioner:        codersdk.ProvisionerType(provisioner),
+				Provisioner:        provisionerType,
 				FileID:             resp.ID,
 				ProvisionerTags:    tags,
 				UserVariableValues: userVariableValues,
@@ -172,10 +177,14 @@ func (r *RootCmd) templatePush() *serpent.Command {
 	}
 
 	cmd.Options = serpent.OptionSet{
+		{
+			Flag:        "provisioner",
+			Description: "Specify the provisioner that runs the template. Defaults to tofu if the template contains .tofu files, otherwise to the provisioner of the existing template or terraform.",
+			Value:       serpent.EnumOf(&provisioner, string(codersdk.ProvisionerTypeTerraform), string(codersdk.ProvisionerTypeTofu)),
+		},
 		{
 			Flag:        "test.provisioner",
 			Description: "Customize the provisioner backend.",
-			Default:     "terraform",
 			Value:       serpent.StringOf(&provisioner),
 			// This is for testing!
 			Hidden: true,
@@ -309,6 +318,28 @@ func (pf *templateUploadFlags) upload(inv *serpent.Invocation, client *codersdk.
 	return &resp, nil
 }
 
+// provisioner picks the provisioner for a new template version. An explicit
+// choice wins, then OpenTofu-specific files in the template directory, then
+// the provisioner of the existing template.
+func (pf *templateUploadFlags) provisioner(requested string, template codersdk.Template) (codersdk.ProvisionerType, error) {
+	if requested != "" {
+		return codersdk.ProvisionerType(requested), nil
+	}
+	if !pf.stdin() {
+		hasTofu, err := provisionersdk.DirHasTofuFiles(pf.directory)
+		if err != nil {
+			return "", xerrors.Errorf("dir has tofu files: %w", err)
+		}
+		if hasTofu {
+			return codersdk.ProvisionerTypeTofu, nil
+		}
+	}
+	if template.Provisioner != "" {
+		return template.Provisioner, nil
+	}
+	return codersdk.ProvisionerTypeTerraform, nil
+}
+
 func (pf *templateUploadFlags) checkForLockfile(inv *serpent.Invocation) error {
 	if pf.stdin() || pf.ignoreLockfile {
 		// Just assume there's a lockfile if reading from stdin.
@@ -30,6 +30,7 @@ func (r *RootCmd) templates() *serpent.Command {
 			r.templateCreate(),
 			r.templateEdit(),
 			r.templateInit(),
+			r.templateLint(),
 			r.templateList(),
 			r.templatePush(),
 			r.templateVersions(),
@@ -3,12 +3,15 @@ package cli
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/coderd/util/slice"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/serpent"
 )
@@ -48,6 +51,9 @@ func (r *RootCmd) createToken() *serpent.Command {
 	var (
 		tokenLifetime time.Duration
 		name          string
+		scopes        []string
+		resources     []string
+		user          string
 	)
 	client := new(codersdk.Client)
 	cmd := &serpent.Command{
@@ -58,10 +64,29 @@ func (r *RootCmd) createToken() *serpent.Command {
 			r.InitClient(client),
 		),
 		Handler: func(inv *serpent.Invocation) error {
-			res, err := client.CreateToken(inv.Context(), codersdk.Me, codersdk.CreateTokenRequest{
+			req := codersdk.CreateTokenRequest{
 				Lifetime:  tokenLifetime,
 				TokenName: name,
-			})
+			}
+			for _, scope := range scopes {
+				req.Scopes = append(req.Scopes, codersdk.APIKeyScope(scope))
+			}
+			for _, resource := range resources {
+				resourceType, rawID, ok := strings.Cut(resource, "=")
+				if !ok {
+					return xerrors.Errorf("invalid resource %q, must be in the format <type>=<id>", resource)
+				}
+				id, err := uuid.Parse(rawID)
+				if err != nil {
+					return xerrors.Errorf("invalid resource %q: parse id: %w", resource, err)
+				}
+				req.AllowList = append(req.AllowList, codersdk.APIAllowListTarget{
+					Type: codersdk.RBACResource(resourceType),
+					ID:   id,
+				})
+			}
+
+			res, err := client.CreateToken(inv.Context(), user, req)
 			if err != nil {
 				return xerrors.Errorf("create tokens: %w", err)
 			}
@@ -87,6 +112,25 @@ func (r *RootCmd) createToken() *serpent.Command {
 			Description:   "Specify a human-readable name.",
 			Value:         serpent.StringOf(&name),
 		},
+		{
+			Flag:        "scope",
+			Env:         "CODER_TOKEN_SCOPE",
+			Description: "Limit the token to the given scopes. Valid scopes are workspace:read, workspace:create, workspace:start, workspace:stop, workspace:delete, workspace:ssh, template:read, template:update and user:read.",
+			Value:       serpent.StringArrayOf(&scopes),
+		},
+		{
+			Flag:        "resource",
+			Env:         "CODER_TOKEN_RESOURCE",
+			Description: "Limit the token to the given resources, in the format <type>=<id>. The type can be workspace or template. Allowing a workspace also allows its template. Requires --scope.",
+			Value:       serpent.StringArrayOf(&resources),
+		},
+		{
+			Flag:        "user",
+			Env:         "CODER_TOKEN_USER",
+			Description: "Create the token for another user, such as a service account. Requires permission to manage that user's tokens.",
+			Default:     codersdk.Me,
+			Value:       serpent.StringOf(&user),
+		},
 	}
 
 	return cmd
@@ -103,10 +147,20 @@ type tokenListRow struct {
 	LastUsed  time.Time `json:"-" table:"last used"`
 	ExpiresAt time.Time `json:"-" table:"expires at"`
 	CreatedAt time.Time `json:"-" table:"created at"`
+	Scopes    string    `json:"-" table:"scopes"`
+	AllowList string    `json:"-" table:"allow list"`
 	Owner     string    `json:"-" table:"owner"`
 }
 
 func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
+	scopes := string(token.Scope)
+	if len(token.Scopes) > 0 {
+		scopes = strings.Join(slice.ToStrings(token.Scopes), ",")
+	}
+	allowList := make([]string, 0, len(token.AllowList))
+	for _, target := range token.AllowList {
+		allowList = append(allowList, fmt.Sprintf("%s=%s", target.Type, target.ID))
+	}
 	return tokenListRow{
 		APIKey:    token.APIKey,
 		ID:        token.ID,
@@ -114,13 +168,15 @@ func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
 		LastUsed:  token.LastUsed,
 		ExpiresAt: token.ExpiresAt,
 		CreatedAt: token.CreatedAt,
+		Scopes:    scopes,
+		AllowList: strings.Join(allowList, ","),
 		Owner:     token.Username,
 	}
 }
 
 func (r *RootCmd) listTokens() *serpent.Command {
 	// we only display the 'owner' column if the --all argument is passed in
-	defaultCols := []string{"id", "name", "last used", "expires at", "created at"}
+	defaultCols := []string{"id", "name", "last used", "expires at", "created at", "scopes", "allow list"}
 	if slices.Contains(os.Args, "-a") || slices.Contains(os.Args, "--all") {
 		defaultCols = append(defaultCols, "owner")
 	}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/coder/coder/v2/cli/clitest"
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/testutil"
 )
@@ -78,3 +80,46 @@ func TestTokens(t *testing.T) {
 	require.NotEmpty(t, res)
 	require.Contains(t, res, "deleted")
 }
+
+func TestTokensScoped(t *testing.T) {
+	t.Parallel()
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	ws := dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: owner.OrganizationID,
+		OwnerID:        owner.UserID,
+	}).Do().Workspace
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	inv, root := clitest.New(t, "tokens", "create", "--name", "scoped",
+		"--scope", "workspace:read,workspace:start",
+		"--resource", "workspace="+ws.ID.String(),
+	)
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	inv.Stdout = buf
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.NotEmpty(t, buf.String())
+
+	inv, root = clitest.New(t, "tokens", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	res := buf.String()
+	require.Contains(t, res, "SCOPES")
+	require.Contains(t, res, "workspace:read,workspace:start")
+	require.Contains(t, res, "workspace="+ws.ID.String())
+	require.Contains(t, res, "template="+ws.TemplateID.String())
+
+	inv, root = clitest.New(t, "tokens", "create", "--name", "invalid",
+		"--scope", "workspace:read",
+		"--resource", "workspace",
+	)
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.ErrorContains(t, err, "<type>=<id>")
+}
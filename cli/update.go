@@ -2,22 +2,28 @@ package cli
 
 import (
 	"fmt"
+	"io"
 
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/v2/cli/cliui"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/serpent"
 )
 
 func (r *RootCmd) update() *serpent.Command {
-	var parameterFlags workspaceParameterFlags
+	var (
+		parameterFlags workspaceParameterFlags
+		plan           bool
+	)
 
 	client := new(codersdk.Client)
 	cmd := &serpent.Command{
 		Annotations: workspaceCommand,
 		Use:         "update <workspace>",
 		Short:       "Will update and start a given workspace if it is out of date",
-		Long:        "Use --always-prompt to change the parameter values of the workspace.",
+		Long: "Use --always-prompt to change the parameter values of the workspace.\n\n" +
+			"Use --plan to preview the resources the update would create, update, replace or destroy without applying it.",
 		Middleware: serpent.Chain(
 			serpent.RequireNArgs(1),
 			r.InitClient(client),
@@ -32,6 +38,10 @@ func (r *RootCmd) update() *serpent.Command {
 				return nil
 			}
 
+			if plan {
+				return planWorkspaceUpdate(inv, client, workspace, parameterFlags)
+			}
+
 			build, err := startWorkspace(inv, client, workspace, parameterFlags, WorkspaceUpdate)
 			if err != nil {
 				return xerrors.Errorf("start workspace: %w", err)
@@ -54,5 +64,87 @@ func (r *RootCmd) update() *serpent.Command {
 	}
 
 	cmd.Options = append(cmd.Options, parameterFlags.allOptions()...)
+	cmd.Options = append(cmd.Options, serpent.Option{
+		Flag:        "plan",
+		Description: "Show the resource changes the update would make without applying them.",
+		Value:       serpent.BoolOf(&plan),
+	})
 	return cmd
 }
+
+// planWorkspaceUpdate runs a plan-only build for the update and prints the
+// resource changes it would make.
+func planWorkspaceUpdate(inv *serpent.Invocation, client *codersdk.Client, workspace codersdk.Workspace, parameterFlags workspaceParameterFlags) error {
+	req, err := buildWorkspaceStartRequest(inv, client, workspace, parameterFlags, WorkspaceUpdate)
+	if err != nil {
+		return err
+	}
+
+	job, err := client.CreateWorkspacePlan(inv.Context(), workspace.ID, codersdk.CreateWorkspacePlanRequest{
+		TemplateVersionID:   req.TemplateVersionID,
+		Transition:          req.Transition,
+		RichParameterValues: req.RichParameterValues,
+	})
+	if err != nil {
+		return xerrors.Errorf("create workspace plan: %w", err)
+	}
+	_, _ = fmt.Fprintln(inv.Stdout, "Planning workspace update...")
+	err = cliui.ProvisionerJob(inv.Context(), inv.Stdout, cliui.ProvisionerJobOptions{
+		Fetch: func() (codersdk.ProvisionerJob, error) {
+			plan, err := client.WorkspacePlan(inv.Context(), workspace.ID, job.ID)
+			return plan.Job, err
+		},
+		Cancel: func() error {
+			return client.CancelWorkspacePlan(inv.Context(), workspace.ID, job.ID)
+		},
+		Logs: func() (<-chan codersdk.ProvisionerJobLog, io.Closer, error) {
+			return client.WorkspacePlanLogsAfter(inv.Context(), workspace.ID, job.ID, 0)
+		},
+	})
+	if err != nil {
+		return xerrors.Errorf("plan workspace update: %w", err)
+	}
+
+	plan, err := client.WorkspacePlan(inv.Context(), workspace.ID, job.ID)
+	if err != nil {
+		return xerrors.Errorf("get workspace plan: %w", err)
+	}
+
+	_, _ = fmt.Fprintln(inv.Stdout)
+	if len(plan.ResourceChanges) == 0 {
+		_, _ = fmt.Fprintln(inv.Stdout, "No changes. The update would not modify any resources.")
+		return nil
+	}
+	out, err := cliui.DisplayTable(plan.ResourceChanges, "", nil)
+	if err != nil {
+		return xerrors.Errorf("render resource changes: %w", err)
+	}
+	_, _ = fmt.Fprintln(inv.Stdout, out)
+
+	counts := make(map[codersdk.ResourceChangeAction]int)
+	var destructive []string
+	for _, change := range plan.ResourceChanges {
+		counts[change.Action]++
+		if change.Destructive {
+			destructive = append(destructive, fmt.Sprintf("%s will be %s", change.Address, destroyVerb(change.Action)))
+		}
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "\nPlan: %d to create, %d to update, %d to replace, %d to delete.\n",
+		counts[codersdk.ResourceChangeActionCreate],
+		counts[codersdk.ResourceChangeActionUpdate],
+		counts[codersdk.ResourceChangeActionReplace],
+		counts[codersdk.ResourceChangeActionDelete],
+	)
+	if len(destructive) > 0 {
+		_, _ = fmt.Fprintln(inv.Stderr)
+		cliui.Warn(inv.Stderr, "The update destroys resources. Any data stored on them will be lost.", destructive...)
+	}
+	return nil
+}
+
+func destroyVerb(action codersdk.ResourceChangeAction) string {
+	if action == codersdk.ResourceChangeActionReplace {
+		return "destroyed and recreated"
+	}
+	return "destroyed"
+}
@@ -78,6 +78,55 @@ func TestUpdate(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, version2.ID.String(), ws.LatestBuild.TemplateVersionID.String())
 	})
+
+	t.Run("Plan", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		owner := coderdtest.CreateFirstUser(t, client)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		version1 := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version1.ID)
+		template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version1.ID)
+		workspace := coderdtest.CreateWorkspace(t, member, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, member, workspace.LatestBuild.ID)
+
+		version2 := coderdtest.UpdateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ApplyComplete,
+			ProvisionPlan: []*proto.Response{{
+				Type: &proto.Response_Plan{
+					Plan: &proto.PlanComplete{
+						ResourceChanges: []*proto.ResourceChange{
+							{Address: "docker_volume.home", Type: "docker_volume", Name: "home", Action: "replace"},
+							{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: "update"},
+						},
+					},
+				},
+			}},
+		}, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version2.ID)
+		err := client.UpdateActiveTemplateVersion(context.Background(), template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: version2.ID,
+		})
+		require.NoError(t, err)
+
+		inv, root := clitest.New(t, "update", workspace.Name, "--plan")
+		clitest.SetupConfig(t, member, root)
+		pty := ptytest.New(t).Attach(inv)
+		clitest.Start(t, inv)
+
+		pty.ExpectMatch("docker_container.dev")
+		pty.ExpectMatch("docker_volume.home")
+		pty.ExpectMatch("Plan: 0 to create, 1 to update, 1 to replace, 0 to delete.")
+		pty.ExpectMatch("docker_volume.home will be destroyed and recreated")
+
+		// Nothing was applied.
+		ws, err := member.WorkspaceByOwnerAndName(context.Background(), memberUser.Username, workspace.Name, codersdk.WorkspaceOptions{})
+		require.NoError(t, err)
+		require.Equal(t, workspace.LatestBuild.ID, ws.LatestBuild.ID)
+		require.Equal(t, version1.ID, ws.LatestBuild.TemplateVersionID)
+	})
 }
 
 func TestUpdateWithRichParameters(t *testing.T) {
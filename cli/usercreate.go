@@ -18,13 +18,14 @@ import (
 
 func (r *RootCmd) userCreate() *serpent.Command {
 	var (
-		email        string
-		username     string
-		name         string
-		password     string
-		disableLogin bool
-		loginType    string
-		orgContext   = NewOrganizationContext()
+		email          string
+		username       string
+		name           string
+		password       string
+		disableLogin   bool
+		loginType      string
+		serviceAccount bool
+		orgContext     = NewOrganizationContext()
 	)
 	client := new(codersdk.Client)
 	cmd := &serpent.Command{
@@ -80,7 +81,10 @@ func (r *RootCmd) userCreate() *serpent.Command {
 			if disableLogin && loginType != "" {
 				return xerrors.New("You cannot specify both --disable-login and --login-type")
 			}
-			if disableLogin {
+			if serviceAccount && (loginType != "" || password != "" || disableLogin) {
+				return xerrors.New("Service accounts cannot log in, so --service-account cannot be used with --login-type or --password")
+			}
+			if serviceAccount || disableLogin {
 				userLoginType = codersdk.LoginTypeNone
 			} else if loginType != "" {
 				userLoginType = codersdk.LoginType(loginType)
@@ -101,11 +105,20 @@ func (r *RootCmd) userCreate() *serpent.Command {
 				Password:       password,
 				OrganizationID: organization.ID,
 				UserLoginType:  userLoginType,
+				ServiceAccount: serviceAccount,
 			})
 			if err != nil {
 				return err
 			}
 
+			if serviceAccount {
+				_, _ = fmt.Fprintln(inv.Stderr, `A new service account has been created in the `+pretty.Sprint(cliui.DefaultStyles.Field, organization.Name)+` organization!
+Service accounts cannot log in. Create an API token for it with:
+
+`+pretty.Sprint(cliui.DefaultStyles.Code, "coder tokens create --user "+username))
+				return nil
+			}
+
 			authenticationMethod := ""
 			switch codersdk.LoginType(strings.ToLower(string(userLoginType))) {
 			case codersdk.LoginTypePassword:
@@ -116,6 +129,8 @@ func (r *RootCmd) userCreate() *serpent.Command {
 				authenticationMethod = `Login is authenticated through GitHub.`
 			case codersdk.LoginTypeOIDC:
 				authenticationMethod = `Login is authenticated through the configured OIDC provider.`
+			case codersdk.LoginTypeLDAP:
+				authenticationMethod = `Login is authenticated through the configured LDAP directory.`
 			}
 
 			_, _ = fmt.Fprintln(inv.Stderr, `A new user has been created!
@@ -170,11 +185,17 @@ Create a workspace  `+pretty.Sprint(cliui.DefaultStyles.Code, "coder create")+`!
 			Description: fmt.Sprintf("Optionally specify the login type for the user. Valid values are: %s. "+
 				"Using 'none' prevents the user from authenticating and requires an API key/token to be generated by an admin.",
 				strings.Join([]string{
-					string(codersdk.LoginTypePassword), string(codersdk.LoginTypeNone), string(codersdk.LoginTypeGithub), string(codersdk.LoginTypeOIDC),
+					string(codersdk.LoginTypePassword), string(codersdk.LoginTypeNone), string(codersdk.LoginTypeGithub), string(codersdk.LoginTypeOIDC), string(codersdk.LoginTypeLDAP),
 				}, ", ",
 				)),
 			Value: serpent.StringOf(&loginType),
 		},
+		{
+			Flag: "service-account",
+			Description: "Create a service account owned by the selected organization. Service accounts cannot log in " +
+				"and only authenticate with API tokens.",
+			Value: serpent.BoolOf(&serviceAccount),
+		},
 	}
 
 	orgContext.AttachOptions(cmd)
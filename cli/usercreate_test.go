@@ -1,6 +1,8 @@
 package cli_test
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -8,6 +10,7 @@ import (
 
 	"github.com/coder/coder/v2/cli/clitest"
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/pty/ptytest"
 	"github.com/coder/coder/v2/testutil"
 )
@@ -124,4 +127,36 @@ func TestUserCreate(t *testing.T) {
 		assert.Equal(t, args[5], created.Username)
 		assert.Empty(t, created.Name)
 	})
+	t.Run("ServiceAccount", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		coderdtest.CreateFirstUser(t, client)
+		inv, root := clitest.New(t, "users", "create", "-e", "ci@coder.com", "-u", "ci", "--service-account")
+		clitest.SetupConfig(t, client, root)
+		err := inv.Run()
+		require.NoError(t, err)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		created, err := client.User(ctx, "ci")
+		require.NoError(t, err)
+		assert.True(t, created.IsServiceAccount)
+		assert.Equal(t, codersdk.LoginTypeNone, created.LoginType)
+
+		// The service account can only be used through API tokens.
+		inv, root = clitest.New(t, "tokens", "create", "--user", "ci")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		inv.Stdout = buf
+		err = inv.Run()
+		require.NoError(t, err)
+		saClient := codersdk.New(client.URL)
+		saClient.SetSessionToken(strings.TrimSpace(buf.String()))
+		me, err := saClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID, me.ID)
+
+		inv, root = clitest.New(t, "users", "create", "-e", "ci2@coder.com", "-u", "ci2", "--service-account", "-p", "1n5ecureP4ssw0rd!")
+		clitest.SetupConfig(t, client, root)
+		err = inv.Run()
+		require.ErrorContains(t, err, "--service-account cannot be used")
+	})
 }
@@ -15,7 +15,7 @@ import (
 
 func (r *RootCmd) userList() *serpent.Command {
 	formatter := cliui.NewOutputFormatter(
-		cliui.TableFormat([]codersdk.User{}, []string{"username", "email", "created at", "status"}),
+		cliui.TableFormat([]codersdk.User{}, []string{"username", "email", "created at", "status", "service account"}),
 		cliui.JSONFormat(),
 	)
 	client := new(codersdk.Client)
@@ -140,6 +140,7 @@ func (*userShowFormat) Format(_ context.Context, out interface{}) (string, error
 	addRow("Full name", user.Name)
 	addRow("Email", user.Email)
 	addRow("Status", user.Status)
+	addRow("Service account", user.IsServiceAccount)
 	addRow("Created At", user.CreatedAt.Format(time.Stamp))
 
 	addRow("", "")
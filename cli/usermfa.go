@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/pretty"
+
+	"github.com/coder/coder/v2/cli/cliui"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/serpent"
+)
+
+func (r *RootCmd) userMFA() *serpent.Command {
+	cmd := &serpent.Command{
+		Use:   "mfa",
+		Short: "Manage multi-factor authentication for password logins",
+		Handler: func(inv *serpent.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*serpent.Command{
+			r.userMFAEnroll(),
+			r.userMFAReset(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) userMFAEnroll() *serpent.Command {
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "enroll",
+		Short: "Add an authenticator app as a second factor for your password login.",
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+			if err != nil {
+				return xerrors.Errorf("enroll totp: %w", err)
+			}
+			printTOTPEnrollment(inv, enrollment)
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text: "Enter the code from your authenticator app:",
+				Validate: func(code string) error {
+					return client.ConfirmTOTP(ctx, codersdk.Me, codersdk.ConfirmTOTPRequest{Code: code})
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(inv.Stdout, "\nMulti-factor authentication is enabled. You will be asked for a code on your next login.")
+			return nil
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) userMFAReset() *serpent.Command {
+	client := new(codersdk.Client)
+	cmd := &serpent.Command{
+		Use:   "reset <username|user_id>",
+		Short: "Remove the second factor of a user that lost access to their authenticator app.",
+		Long: FormatExamples(
+			Example{
+				Command: "coder users mfa reset example_user",
+			},
+		),
+		Middleware: serpent.Chain(
+			serpent.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *serpent.Invocation) error {
+			ctx := inv.Context()
+			user, err := client.User(ctx, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("fetch user: %w", err)
+			}
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Are you sure you want to reset multi-factor authentication for %s? They will be able to log in with their password alone.", pretty.Sprint(cliui.DefaultStyles.Keyword, user.Username)),
+				IsConfirm: true,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = client.ResetUserTOTP(ctx, user.ID.String())
+			if err != nil {
+				return xerrors.Errorf("reset totp: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Multi-factor authentication for %s has been reset.\n", pretty.Sprint(cliui.DefaultStyles.Keyword, user.Username))
+			return nil
+		},
+	}
+	cmd.Options = serpent.OptionSet{
+		cliui.SkipPromptOption(),
+	}
+	return cmd
+}
+
+// printTOTPEnrollment prints what the user needs to add a TOTP secret to their
+// authenticator app.
+func printTOTPEnrollment(inv *serpent.Invocation, enrollment codersdk.TOTPEnrollment) {
+	_, _ = fmt.Fprintf(inv.Stdout, "Add the following secret to your authenticator app:\n\n\t%s\n\n", pretty.Sprint(cliui.DefaultStyles.Code, enrollment.Secret))
+	_, _ = fmt.Fprintf(inv.Stdout, "Or open this URL on the device with your authenticator app:\n\n\t%s\n\n", enrollment.URL)
+	_, _ = fmt.Fprintf(inv.Stdout, "Store these recovery codes somewhere safe. Each of them can be used once to log in if you lose access to your authenticator app:\n\n\t%s\n\n", strings.Join(enrollment.RecoveryCodes, "\n\t"))
+}
@@ -0,0 +1,40 @@
+package cli_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/cli/clitest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestUserMFAReset(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+	client := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	userAdmin, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID, rbac.RoleUserAdmin())
+	member, user := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+	enrollment, err := member.EnrollTOTP(ctx, codersdk.Me)
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	err = member.ConfirmTOTP(ctx, codersdk.Me, codersdk.ConfirmTOTPRequest{Code: code})
+	require.NoError(t, err)
+
+	inv, root := clitest.New(t, "users", "mfa", "reset", user.Username, "--yes")
+	clitest.SetupConfig(t, userAdmin, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	status, err := member.UserMFAStatus(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.False(t, status.TOTPEnabled)
+}
@@ -491,6 +491,46 @@ const docTemplate = `{
                 }
             }
         },
+        "/debug/health/derp/history": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "Get DERP health history",
+                "operationId": "get-derp-health-history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Earliest result to include, in RFC3339 format. Defaults to 24 hours ago.",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only include results for this DERP region",
+                        "name": "region_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/healthsdk.DERPHealthHistory"
+                        }
+                    }
+                }
+            }
+        },
         "/debug/health/settings": {
             "get": {
                 "security": [
@@ -2078,6 +2118,21 @@ const docTemplate = `{
                         "description": "Token scopes (currently ignored)",
                         "name": "scope",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge, required for public applications",
+                        "name": "code_challenge",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "S256"
+                        ],
+                        "type": "string",
+                        "description": "PKCE code challenge method, required if code_challenge is set",
+                        "name": "code_challenge_method",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2087,6 +2142,84 @@ const docTemplate = `{
                 }
             }
         },
+        "/oauth2/device": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "OAuth2 device authorization request.",
+                "operationId": "oauth2-device-authorization-request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, required unless the application is public",
+                        "name": "client_secret",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token scopes (currently ignored)",
+                        "name": "scope",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/oauth2.DeviceAuthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth2/device/verify": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "OAuth2 device verification page.",
+                "operationId": "oauth2-device-verification-page",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User code shown on the device",
+                        "name": "user_code",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "allow",
+                            "deny"
+                        ],
+                        "type": "string",
+                        "description": "Whether to allow or deny the device",
+                        "name": "action",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/oauth2/tokens": {
             "post": {
                 "produces": [
@@ -2100,13 +2233,13 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Client ID, required if grant_type=authorization_code",
+                        "description": "Client ID, required unless grant_type=refresh_token",
                         "name": "client_id",
                         "in": "formData"
                     },
                     {
                         "type": "string",
-                        "description": "Client secret, required if grant_type=authorization_code",
+                        "description": "Client secret, required unless grant_type=refresh_token or the application is public",
                         "name": "client_secret",
                         "in": "formData"
                     },
@@ -2116,6 +2249,18 @@ const docTemplate = `{
                         "name": "code",
                         "in": "formData"
                     },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier, required if a code challenge was sent during authorization",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Device code, required if grant_type=urn:ietf:params:oauth:grant-type:device_code",
+                        "name": "device_code",
+                        "in": "formData"
+                    },
                     {
                         "type": "string",
                         "description": "Refresh token, required if grant_type=refresh_token",
@@ -2125,7 +2270,9 @@ const docTemplate = `{
                     {
                         "enum": [
                             "authorization_code",
-                            "refresh_token"
+                            "refresh_token",
+                            "client_credentials",
+                            "urn:ietf:params:oauth:grant-type:device_code"
                         ],
                         "type": "string",
                         "description": "Grant type",
@@ -3443,28 +3590,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/scim/v2/Users": {
+        "/role-grants": {
             "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "produces": [
-                    "application/scim+json"
-                ],
-                "tags": [
-                    "Enterprise"
-                ],
-                "summary": "SCIM 2.0: Get users",
-                "operationId": "scim-get-users",
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            },
-            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3474,32 +3601,46 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Members"
                 ],
-                "summary": "SCIM 2.0: Create new user",
-                "operationId": "scim-create-new-user",
+                "summary": "Get role grant requests",
+                "operationId": "get-role-grant-requests",
                 "parameters": [
                     {
-                        "description": "New user",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "denied",
+                            "expired",
+                            "revoked"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.RoleGrantRequest"
+                            }
                         }
                     }
                 }
             }
         },
-        "/scim/v2/Users/{id}": {
+        "/role-grants/{rolegrant}": {
             "get": {
                 "security": [
                     {
@@ -3507,74 +3648,70 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Members"
                 ],
-                "summary": "SCIM 2.0: Get user by ID",
-                "operationId": "scim-get-user-by-id",
+                "summary": "Get role grant request",
+                "operationId": "get-role-grant-request",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Role grant request ID",
+                        "name": "rolegrant",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "404": {
-                        "description": "Not Found"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.RoleGrantRequest"
+                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/role-grants/{rolegrant}/approve": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Members"
                 ],
-                "summary": "SCIM 2.0: Update user account",
-                "operationId": "scim-update-user-status",
+                "summary": "Approve role grant request",
+                "operationId": "approve-role-grant-request",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Role grant request ID",
+                        "name": "rolegrant",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update user request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.RoleGrantRequest"
                         }
                     }
                 }
             }
         },
-        "/templates": {
-            "get": {
+        "/role-grants/{rolegrant}/deny": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3584,53 +3721,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Members"
                 ],
-                "summary": "Get all templates",
-                "operationId": "get-all-templates",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.Template"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "/templates/examples": {
-            "get": {
-                "security": [
+                "summary": "Deny role grant request",
+                "operationId": "deny-role-grant-request",
+                "parameters": [
                     {
-                        "CoderSessionToken": []
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Role grant request ID",
+                        "name": "rolegrant",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Templates"
-                ],
-                "summary": "Get template examples",
-                "operationId": "get-template-examples",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateExample"
-                            }
+                            "$ref": "#/definitions/codersdk.RoleGrantRequest"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}": {
-            "get": {
+        "/role-grants/{rolegrant}/revoke": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3640,16 +3756,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Members"
                 ],
-                "summary": "Get template metadata by ID",
-                "operationId": "get-template-metadata-by-id",
+                "summary": "Revoke role grant request",
+                "operationId": "revoke-role-grant-request",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Role grant request ID",
+                        "name": "rolegrant",
                         "in": "path",
                         "required": true
                     }
@@ -3658,19 +3774,549 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "$ref": "#/definitions/codersdk.RoleGrantRequest"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/scim/v2/Groups": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get groups",
+                "operationId": "scim-get-groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter, e.g. displayName eq \"name\"",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1-based index of the first result",
+                        "name": "startIndex",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of results",
+                        "name": "count",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \"members\" to omit group members",
+                        "name": "excludedAttributes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMListResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Create new group",
+                "operationId": "scim-create-new-group",
+                "parameters": [
+                    {
+                        "description": "New group",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Groups/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get group by ID",
+                "operationId": "scim-get-group-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Set to \"members\" to omit group members",
+                        "name": "excludedAttributes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Replace group",
+                "operationId": "scim-replace-group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Replace group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Delete group",
+                "operationId": "scim-delete-group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Update group",
+                "operationId": "scim-update-group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Patch group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMPatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/ResourceTypes": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get resource types",
+                "operationId": "scim-get-resource-types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Schemas": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get schemas",
+                "operationId": "scim-get-schemas",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/ServiceProviderConfig": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get service provider config",
+                "operationId": "scim-get-service-provider-config",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get users",
+                "operationId": "scim-get-users",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Create new user",
+                "operationId": "scim-create-new-user",
+                "parameters": [
+                    {
+                        "description": "New user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
+                    }
+                }
+            }
+        },
+        "/scim/v2/Users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get user by ID",
+                "operationId": "scim-get-user-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "404": {
+                        "description": "Not Found"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Update user account",
+                "operationId": "scim-update-user-status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get all templates",
+                "operationId": "get-all-templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Template"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/examples": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template examples",
+                "operationId": "get-template-examples",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateExample"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template metadata by ID",
+                "operationId": "get-template-metadata-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Template"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
                     "Templates"
@@ -3695,8 +4341,126 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update template metadata by ID",
+                "operationId": "update-template-metadata-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Template"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/acl": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get template ACLs",
+                "operationId": "get-template-acls",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateUser"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Update template ACL",
+                "operationId": "update-template-acl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/acl/available": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3706,10 +4470,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Update template metadata by ID",
-                "operationId": "update-template-metadata-by-id",
+                "summary": "Get template available acl users/groups",
+                "operationId": "get-template-available-acl-usersgroups",
                 "parameters": [
                     {
                         "type": "string",
@@ -3724,13 +4488,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ACLAvailable"
+                            }
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/acl": {
+        "/templates/{template}/canary": {
             "get": {
                 "security": [
                     {
@@ -3741,10 +4508,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get template ACLs",
-                "operationId": "get-template-acls",
+                "summary": "Get template version canary by template ID",
+                "operationId": "get-template-version-canary-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -3759,15 +4526,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateUser"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersionCanary"
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3780,10 +4544,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Update template ACL",
-                "operationId": "update-template-acl",
+                "summary": "Create template version canary by template ID",
+                "operationId": "create-template-version-canary-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -3794,40 +4558,41 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update template request",
+                        "description": "Canary request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionCanaryRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.TemplateVersionCanary"
                         }
                     }
                 }
-            }
-        },
-        "/templates/{template}/acl/available": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get template available acl users/groups",
-                "operationId": "get-template-available-acl-usersgroups",
+                "summary": "Promote or roll back template version canary by template ID",
+                "operationId": "promote-or-roll-back-template-version-canary-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -3836,16 +4601,22 @@ const docTemplate = `{
                         "name": "template",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Canary update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateVersionCanaryRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ACLAvailable"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersionCanary"
                         }
                     }
                 }
@@ -4707,6 +5478,44 @@ const docTemplate = `{
                 }
             }
         },
+        "/templateversions/{templateversion}/timings": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version timings by ID",
+                "operationId": "get-template-version-timings-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerTiming"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/templateversions/{templateversion}/unarchive": {
             "post": {
                 "security": [
@@ -4864,18 +5673,173 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Users"
+                ],
+                "summary": "Create new user",
+                "operationId": "create-new-user",
+                "parameters": [
+                    {
+                        "description": "Create user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/authmethods": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get authentication methods",
+                "operationId": "get-authentication-methods",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.AuthMethods"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/first": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Check initial user created",
+                "operationId": "check-initial-user-created",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create initial user",
+                "operationId": "create-initial-user",
+                "parameters": [
+                    {
+                        "description": "First user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/ldap/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Log in user with LDAP",
+                "operationId": "log-in-user-with-ldap",
+                "parameters": [
+                    {
+                        "description": "Login request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.LoginWithLDAPRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
                 ],
-                "summary": "Create new user",
-                "operationId": "create-new-user",
+                "summary": "Log in user",
+                "operationId": "log-in-user",
                 "parameters": [
                     {
-                        "description": "Create user request",
+                        "description": "Login request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateUserRequest"
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
                         }
                     }
                 ],
@@ -4883,67 +5847,34 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
                         }
                     }
                 }
             }
         },
-        "/users/authmethods": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
+        "/users/login/device": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Authorization"
                 ],
-                "summary": "Get authentication methods",
-                "operationId": "get-authentication-methods",
+                "summary": "Start device login",
+                "operationId": "start-device-login",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.AuthMethods"
+                            "$ref": "#/definitions/codersdk.DeviceLoginResponse"
                         }
                     }
                 }
             }
         },
-        "/users/first": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Check initial user created",
-                "operationId": "check-initial-user-created",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
-                        }
-                    }
-                }
-            },
+        "/users/login/device/token": {
             "post": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -4951,32 +5882,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Authorization"
                 ],
-                "summary": "Create initial user",
-                "operationId": "create-initial-user",
+                "summary": "Get device login token",
+                "operationId": "get-device-login-token",
                 "parameters": [
                     {
-                        "description": "First user request",
+                        "description": "Device login token request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
+                            "$ref": "#/definitions/codersdk.DeviceLoginTokenRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                            "$ref": "#/definitions/codersdk.DeviceLoginTokenResponse"
                         }
                     }
                 }
             }
         },
-        "/users/login": {
+        "/users/login/mfa/totp": {
             "post": {
                 "consumes": [
                     "application/json"
@@ -4987,8 +5918,8 @@ const docTemplate = `{
                 "tags": [
                     "Authorization"
                 ],
-                "summary": "Log in user",
-                "operationId": "log-in-user",
+                "summary": "Enroll TOTP during login",
+                "operationId": "enroll-totp-during-login",
                 "parameters": [
                     {
                         "description": "Login request",
@@ -5004,7 +5935,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
+                            "$ref": "#/definitions/codersdk.TOTPEnrollment"
                         }
                     }
                 }
@@ -5073,6 +6004,45 @@ const docTemplate = `{
                 }
             }
         },
+        "/users/oidc/sync/dry-run": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Preview OIDC claim sync",
+                "operationId": "preview-oidc-claim-sync",
+                "parameters": [
+                    {
+                        "description": "OIDC claims",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OIDCSyncDryRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OIDCSyncDryRunResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/users/roles": {
             "get": {
                 "security": [
@@ -5478,7 +6448,159 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{user}/keys/tokens/tokenconfig": {
+        "/users/{user}/keys/tokens/tokenconfig": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "General"
+                ],
+                "summary": "Get token config",
+                "operationId": "get-token-config",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TokenConfig"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/keys/tokens/{keyname}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get API key by token name",
+                "operationId": "get-api-key-by-token-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "string",
+                        "description": "Key Name",
+                        "name": "keyname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.APIKey"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/keys/{keyid}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get API key by ID",
+                "operationId": "get-api-key-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Key ID",
+                        "name": "keyid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.APIKey"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete API key",
+                "operationId": "delete-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Key ID",
+                        "name": "keyid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/users/{user}/login-type": {
             "get": {
                 "security": [
                     {
@@ -5489,10 +6611,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Users"
                 ],
-                "summary": "Get token config",
-                "operationId": "get-token-config",
+                "summary": "Get user login type",
+                "operationId": "get-user-login-type",
                 "parameters": [
                     {
                         "type": "string",
@@ -5506,13 +6628,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TokenConfig"
+                            "$ref": "#/definitions/codersdk.UserLoginType"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/tokens/{keyname}": {
+        "/users/{user}/mfa": {
             "get": {
                 "security": [
                     {
@@ -5525,8 +6647,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get API key by token name",
-                "operationId": "get-api-key-by-token-name",
+                "summary": "Get user MFA status",
+                "operationId": "get-user-mfa-status",
                 "parameters": [
                     {
                         "type": "string",
@@ -5534,28 +6656,20 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "string",
-                        "description": "Key Name",
-                        "name": "keyname",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
+                            "$ref": "#/definitions/codersdk.UserMFAStatus"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/{keyid}": {
-            "get": {
+        "/users/{user}/mfa/totp": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5567,8 +6681,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get API key by ID",
-                "operationId": "get-api-key-by-id",
+                "summary": "Enroll user TOTP",
+                "operationId": "enroll-user-totp",
                 "parameters": [
                     {
                         "type": "string",
@@ -5576,21 +6690,13 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
+                            "$ref": "#/definitions/codersdk.TOTPEnrollment"
                         }
                     }
                 }
@@ -5604,8 +6710,8 @@ const docTemplate = `{
                 "tags": [
                     "Users"
                 ],
-                "summary": "Delete API key",
-                "operationId": "delete-api-key",
+                "summary": "Reset user TOTP",
+                "operationId": "reset-user-totp",
                 "parameters": [
                     {
                         "type": "string",
@@ -5613,14 +6719,6 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -5630,21 +6728,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{user}/login-type": {
-            "get": {
+        "/users/{user}/mfa/totp/confirm": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user login type",
-                "operationId": "get-user-login-type",
+                "summary": "Confirm user TOTP enrollment",
+                "operationId": "confirm-user-totp-enrollment",
                 "parameters": [
                     {
                         "type": "string",
@@ -5652,14 +6750,20 @@ const docTemplate = `{
                         "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Confirm request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ConfirmTOTPRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UserLoginType"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -5991,28 +7095,219 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/role-grants": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Members"
+                ],
+                "summary": "Get role grant requests by user",
+                "operationId": "get-role-grant-requests-by-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.RoleGrantRequest"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Members"
+                ],
+                "summary": "Request role for user",
+                "operationId": "request-role-for-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role grant request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateRoleGrantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.RoleGrantRequest"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user roles",
+                "operationId": "get-user-roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Assign role to user",
+                "operationId": "assign-role-to-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update roles request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateRoles"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user login sessions",
+                "operationId": "get-user-login-sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Session"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/roles": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Get user roles",
-                "operationId": "get-user-roles",
+                "summary": "Revoke user login sessions",
+                "operationId": "revoke-user-login-sessions",
                 "parameters": [
                     {
                         "type": "string",
@@ -6023,31 +7318,24 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.User"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/users/{user}/sessions/{session}": {
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Users"
                 ],
-                "summary": "Assign role to user",
-                "operationId": "assign-role-to-user",
+                "summary": "Revoke user login session",
+                "operationId": "revoke-user-login-session",
                 "parameters": [
                     {
                         "type": "string",
@@ -6057,21 +7345,16 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update roles request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateRoles"
-                        }
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.User"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -7237,6 +8520,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspacebuilds/{workspacebuild}/timings": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace build timings by ID",
+                "operationId": "get-workspace-build-timings-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuildTimings"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaceproxies": {
             "get": {
                 "security": [
@@ -7956,6 +9274,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{workspace}/drift": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace drift",
+                "operationId": "get-workspace-drift",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceDrift"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{workspace}/extend": {
             "put": {
                 "security": [
@@ -8019,29 +9372,194 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Workspace ID",
-                        "name": "workspace",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Unfavorite workspace by ID.",
+                "operationId": "unfavorite-workspace-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/plans": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Create workspace plan",
+                "operationId": "create-workspace-plan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Plan request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateWorkspacePlanRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/plans/{jobID}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace plan by job ID",
+                "operationId": "get-workspace-plan-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspacePlan"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaces/{workspace}/plans/{jobID}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Cancel workspace plan by job ID",
+                "operationId": "cancel-workspace-plan-by-job-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/workspaces/{workspace}/plans/{jobID}/logs": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Unfavorite workspace by ID.",
-                "operationId": "unfavorite-workspace-by-id",
+                "summary": "Get workspace plan logs by job ID",
+                "operationId": "get-workspace-plan-logs-by-job-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -8050,11 +9568,43 @@ const docTemplate = `{
                         "name": "workspace",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before Unix timestamp",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After Unix timestamp",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
+                            }
+                        }
                     }
                 }
             }
@@ -8459,6 +10009,106 @@ const docTemplate = `{
                 }
             }
         },
+        "coderd.SCIMGroup": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMGroupMember"
+                    }
+                },
+                "meta": {
+                    "type": "object",
+                    "properties": {
+                        "resourceType": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "coderd.SCIMGroupMember": {
+            "type": "object",
+            "properties": {
+                "display": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the ID of the user, as returned when the user was created\nthrough SCIM.",
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "coderd.SCIMListResponse": {
+            "type": "object",
+            "properties": {
+                "Resources": {
+                    "type": "array",
+                    "items": {}
+                },
+                "itemsPerPage": {
+                    "type": "integer"
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "startIndex": {
+                    "type": "integer"
+                },
+                "totalResults": {
+                    "type": "integer"
+                }
+            }
+        },
+        "coderd.SCIMPatchOperation": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "description": "Op is one of \"add\", \"remove\" or \"replace\". Entra ID capitalizes these,\nso they are matched case insensitively.",
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "coderd.SCIMPatchRequest": {
+            "type": "object",
+            "properties": {
+                "Operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMPatchOperation"
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "coderd.SCIMUser": {
             "type": "object",
             "properties": {
@@ -8549,6 +10199,26 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.APIAllowListTarget": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "type": {
+                    "enum": [
+                        "workspace",
+                        "template"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.RBACResource"
+                        }
+                    ]
+                }
+            }
+        },
         "codersdk.APIKey": {
             "type": "object",
             "required": [
@@ -8564,6 +10234,13 @@ const docTemplate = `{
                 "user_id"
             ],
             "properties": {
+                "allow_list": {
+                    "description": "AllowList contains the resources the key is limited to. If empty, the\nkey can be used with every resource its scopes allow.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.APIAllowListTarget"
+                    }
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -8587,6 +10264,7 @@ const docTemplate = `{
                         "password",
                         "github",
                         "oidc",
+                        "ldap",
                         "token"
                     ],
                     "allOf": [
@@ -8606,6 +10284,24 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "scopes": {
+                    "description": "Scopes are the named scopes the key is limited to. If empty, the key is\nlimited to Scope instead.",
+                    "type": "array",
+                    "items": {
+                        "enum": [
+                            "workspace:read",
+                            "workspace:create",
+                            "workspace:start",
+                            "workspace:stop",
+                            "workspace:delete",
+                            "workspace:ssh",
+                            "template:read",
+                            "template:update",
+                            "user:read"
+                        ],
+                        "$ref": "#/definitions/codersdk.APIKeyScope"
+                    }
+                },
                 "token_name": {
                     "type": "string"
                 },
@@ -8623,11 +10319,29 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "all",
-                "application_connect"
+                "application_connect",
+                "workspace:read",
+                "workspace:create",
+                "workspace:start",
+                "workspace:stop",
+                "workspace:delete",
+                "workspace:ssh",
+                "template:read",
+                "template:update",
+                "user:read"
             ],
             "x-enum-varnames": [
                 "APIKeyScopeAll",
-                "APIKeyScopeApplicationConnect"
+                "APIKeyScopeApplicationConnect",
+                "APIKeyScopeWorkspaceRead",
+                "APIKeyScopeWorkspaceCreate",
+                "APIKeyScopeWorkspaceStart",
+                "APIKeyScopeWorkspaceStop",
+                "APIKeyScopeWorkspaceDelete",
+                "APIKeyScopeWorkspaceSSH",
+                "APIKeyScopeTemplateRead",
+                "APIKeyScopeTemplateUpdate",
+                "APIKeyScopeUserRead"
             ]
         },
         "codersdk.AddLicenseRequest": {
@@ -8654,6 +10368,56 @@ const docTemplate = `{
                 "AgentSubsystemExectrace"
             ]
         },
+        "codersdk.AgentTiming": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "agent_name": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "stage": {
+                    "enum": [
+                        "connect",
+                        "start"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.AgentTimingStage"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status": {
+                    "description": "Status is the current lifecycle state of the agent, e.g. \"ready\" or\n\"start_error\".",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentLifecycle"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.AgentTimingStage": {
+            "type": "string",
+            "enum": [
+                "connect",
+                "start"
+            ],
+            "x-enum-varnames": [
+                "AgentTimingStageConnect",
+                "AgentTimingStageStart"
+            ]
+        },
         "codersdk.AppHostResponse": {
             "type": "object",
             "properties": {
@@ -8754,7 +10518,8 @@ const docTemplate = `{
                 "stop",
                 "login",
                 "logout",
-                "register"
+                "register",
+                "open"
             ],
             "x-enum-varnames": [
                 "AuditActionCreate",
@@ -8764,7 +10529,8 @@ const docTemplate = `{
                 "AuditActionStop",
                 "AuditActionLogin",
                 "AuditActionLogout",
-                "AuditActionRegister"
+                "AuditActionRegister",
+                "AuditActionOpen"
             ]
         },
         "codersdk.AuditDiff": {
@@ -8883,6 +10649,9 @@ const docTemplate = `{
                 "github": {
                     "$ref": "#/definitions/codersdk.AuthMethod"
                 },
+                "ldap": {
+                    "$ref": "#/definitions/codersdk.AuthMethod"
+                },
                 "oidc": {
                     "$ref": "#/definitions/codersdk.OIDCAuthMethod"
                 },
@@ -9043,6 +10812,17 @@ const docTemplate = `{
                 "BuildReasonAutostop"
             ]
         },
+        "codersdk.ConfirmTOTPRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.ConnectionLatency": {
             "type": "object",
             "properties": {
@@ -9192,6 +10972,29 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateRoleGrantRequest": {
+            "type": "object",
+            "required": [
+                "lifetime",
+                "reason",
+                "role_name"
+            ],
+            "properties": {
+                "lifetime": {
+                    "type": "integer"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "role_name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateTemplateRequest": {
             "type": "object",
             "required": [
@@ -9271,16 +11074,42 @@ const docTemplate = `{
                         }
                     ]
                 },
-                "name": {
-                    "description": "Name is the name of the template.",
-                    "type": "string"
+                "name": {
+                    "description": "Name is the name of the template.",
+                    "type": "string"
+                },
+                "require_active_version": {
+                    "description": "RequireActiveVersion mandates that workspaces are built with the active\ntemplate version.",
+                    "type": "boolean"
+                },
+                "template_version_id": {
+                    "description": "VersionID is an in-progress or completed job to use as an initial version\nof the template.\n\nThis is required on creation to enable a user-flow of validating a\ntemplate works. There is no reason the data-model cannot support empty\ntemplates, but it doesn't make sense for users.",
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.CreateTemplateVersionCanaryRequest": {
+            "type": "object",
+            "required": [
+                "min_builds",
+                "template_version_id"
+            ],
+            "properties": {
+                "group_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "min_builds": {
+                    "type": "integer"
                 },
-                "require_active_version": {
-                    "description": "RequireActiveVersion mandates that workspaces are built with the active\ntemplate version.",
-                    "type": "boolean"
+                "percent": {
+                    "type": "integer"
+                },
+                "success_threshold": {
+                    "type": "integer"
                 },
                 "template_version_id": {
-                    "description": "VersionID is an in-progress or completed job to use as an initial version\nof the template.\n\nThis is required on creation to enable a user-flow of validating a\ntemplate works. There is no reason the data-model cannot support empty\ntemplates, but it doesn't make sense for users.",
                     "type": "string",
                     "format": "uuid"
                 }
@@ -9330,7 +11159,8 @@ const docTemplate = `{
                     "type": "string",
                     "enum": [
                         "terraform",
-                        "echo"
+                        "echo",
+                        "tofu"
                     ]
                 },
                 "storage_method": {
@@ -9430,6 +11260,13 @@ const docTemplate = `{
         "codersdk.CreateTokenRequest": {
             "type": "object",
             "properties": {
+                "allow_list": {
+                    "description": "AllowList limits the token to the given resources. It requires Scopes.\nAllowing a workspace also allows its template.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.APIAllowListTarget"
+                    }
+                },
                 "lifetime": {
                     "type": "integer"
                 },
@@ -9444,6 +11281,24 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "scopes": {
+                    "description": "Scopes limits the token to the named scopes. Scope must be empty or\n\"all\" if any are set.",
+                    "type": "array",
+                    "items": {
+                        "enum": [
+                            "workspace:read",
+                            "workspace:create",
+                            "workspace:start",
+                            "workspace:stop",
+                            "workspace:delete",
+                            "workspace:ssh",
+                            "template:read",
+                            "template:update",
+                            "user:read"
+                        ],
+                        "$ref": "#/definitions/codersdk.APIKeyScope"
+                    }
+                },
                 "token_name": {
                     "type": "string"
                 }
@@ -9482,6 +11337,10 @@ const docTemplate = `{
                 "password": {
                     "type": "string"
                 },
+                "service_account": {
+                    "description": "ServiceAccount creates a service account owned by OrganizationID.\nService accounts cannot log in and must use API tokens, so\nUserLoginType must be empty or LoginTypeNone and Password must be\nempty.",
+                    "type": "boolean"
+                },
                 "username": {
                     "type": "string"
                 }
@@ -9543,6 +11402,48 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateWorkspacePlanRequest": {
+            "type": "object",
+            "required": [
+                "transition"
+            ],
+            "properties": {
+                "log_level": {
+                    "description": "Log level changes the default logging verbosity of a provider (\"info\" if empty).",
+                    "enum": [
+                        "debug"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerLogLevel"
+                        }
+                    ]
+                },
+                "rich_parameter_values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                    }
+                },
+                "template_version_id": {
+                    "description": "TemplateVersionID defaults to the version of the latest build.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "transition": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceTransition"
+                        }
+                    ]
+                }
+            }
+        },
         "codersdk.CreateWorkspaceProxyRequest": {
             "type": "object",
             "required": [
@@ -9874,6 +11775,9 @@ const docTemplate = `{
                 "job_hang_detector_interval": {
                     "type": "integer"
                 },
+                "ldap": {
+                    "$ref": "#/definitions/codersdk.LDAPConfig"
+                },
                 "logging": {
                     "$ref": "#/definitions/codersdk.LoggingConfig"
                 },
@@ -9925,6 +11829,9 @@ const docTemplate = `{
                 "redirect_to_access_url": {
                     "type": "boolean"
                 },
+                "require_owner_mfa": {
+                    "type": "boolean"
+                },
                 "scim_api_key": {
                     "type": "string"
                 },
@@ -9987,6 +11894,68 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DeviceLoginResponse": {
+            "type": "object",
+            "properties": {
+                "device_code": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "interval": {
+                    "description": "Interval is the number of seconds to wait between polls.",
+                    "type": "integer"
+                },
+                "user_code": {
+                    "type": "string"
+                },
+                "verification_url": {
+                    "type": "string"
+                },
+                "verification_url_complete": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.DeviceLoginStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "denied",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "DeviceLoginStatusPending",
+                "DeviceLoginStatusApproved",
+                "DeviceLoginStatusDenied",
+                "DeviceLoginStatusExpired"
+            ]
+        },
+        "codersdk.DeviceLoginTokenRequest": {
+            "type": "object",
+            "required": [
+                "device_code"
+            ],
+            "properties": {
+                "device_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.DeviceLoginTokenResponse": {
+            "type": "object",
+            "properties": {
+                "session_token": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/codersdk.DeviceLoginStatus"
+                }
+            }
+        },
         "codersdk.DisplayApp": {
             "type": "string",
             "enum": [
@@ -10359,11 +12328,14 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "user",
-                "oidc"
+                "oidc",
+                "ldap",
+                "scim"
             ],
             "x-enum-varnames": [
                 "GroupSourceUser",
-                "GroupSourceOIDC"
+                "GroupSourceOIDC",
+                "GroupSourceSCIM"
             ]
         },
         "codersdk.Healthcheck": {
@@ -10386,6 +12358,9 @@ const docTemplate = `{
         "codersdk.HealthcheckConfig": {
             "type": "object",
             "properties": {
+                "derp_history_interval": {
+                    "type": "integer"
+                },
                 "refresh": {
                     "type": "integer"
                 },
@@ -10464,6 +12439,59 @@ const docTemplate = `{
                 "RequiredTemplateVariables"
             ]
         },
+        "codersdk.LDAPConfig": {
+            "type": "object",
+            "properties": {
+                "allow_signups": {
+                    "type": "boolean"
+                },
+                "bind_dn": {
+                    "type": "string"
+                },
+                "bind_password": {
+                    "type": "string"
+                },
+                "ca_file": {
+                    "type": "string"
+                },
+                "email_attribute": {
+                    "type": "string"
+                },
+                "group_attribute": {
+                    "type": "string"
+                },
+                "group_auto_create": {
+                    "type": "boolean"
+                },
+                "group_mapping": {
+                    "type": "object"
+                },
+                "group_regex_filter": {
+                    "$ref": "#/definitions/serpent.Regexp"
+                },
+                "insecure_skip_verify": {
+                    "type": "boolean"
+                },
+                "name_attribute": {
+                    "type": "string"
+                },
+                "search_base_dn": {
+                    "type": "string"
+                },
+                "search_filter": {
+                    "type": "string"
+                },
+                "start_tls": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "username_attribute": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.License": {
             "type": "object",
             "properties": {
@@ -10559,6 +12587,7 @@ const docTemplate = `{
                 "password",
                 "github",
                 "oidc",
+                "ldap",
                 "token",
                 "none"
             ],
@@ -10567,10 +12596,26 @@ const docTemplate = `{
                 "LoginTypePassword",
                 "LoginTypeGithub",
                 "LoginTypeOIDC",
+                "LoginTypeLDAP",
                 "LoginTypeToken",
                 "LoginTypeNone"
             ]
         },
+        "codersdk.LoginWithLDAPRequest": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.LoginWithPasswordRequest": {
             "type": "object",
             "required": [
@@ -10584,6 +12629,14 @@ const docTemplate = `{
                 },
                 "password": {
                     "type": "string"
+                },
+                "recovery_code": {
+                    "description": "RecoveryCode can be used instead of TOTPCode if the user has lost\naccess to their authenticator app. Each recovery code can only be used\nonce.",
+                    "type": "string"
+                },
+                "totp_code": {
+                    "description": "TOTPCode is the one-time code from the user's authenticator app. It is\nrequired if the user has enabled TOTP.",
+                    "type": "string"
                 }
             }
         },
@@ -10929,6 +12982,11 @@ const docTemplate = `{
                 "callback_url": {
                     "type": "string"
                 },
+                "client_credentials_user_id": {
+                    "description": "ClientCredentialsUserID is the user that tokens issued through the\nclient_credentials grant act as. The grant is disabled when unset.",
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "endpoints": {
                     "description": "Endpoints are included in the app response for easier discovery. The OAuth2\nspec does not have a defined place to find these (for comparison, OIDC has\na '/.well-known/openid-configuration' endpoint).",
                     "allOf": [
@@ -10946,6 +13004,10 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "public": {
+                    "description": "Public apps cannot keep a client secret confidential, so they must use\nPKCE to exchange authorization codes instead of a secret.",
+                    "type": "boolean"
                 }
             }
         },
@@ -11073,6 +13135,12 @@ const docTemplate = `{
                 "name_field": {
                     "type": "string"
                 },
+                "organization_field": {
+                    "type": "string"
+                },
+                "organization_mapping": {
+                    "type": "object"
+                },
                 "scopes": {
                     "type": "array",
                     "items": {
@@ -11105,6 +13173,81 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.OIDCSyncAction": {
+            "type": "string",
+            "enum": [
+                "add",
+                "keep",
+                "remove"
+            ],
+            "x-enum-varnames": [
+                "OIDCSyncActionAdd",
+                "OIDCSyncActionKeep",
+                "OIDCSyncActionRemove"
+            ]
+        },
+        "codersdk.OIDCSyncDryRunOrganization": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/codersdk.OIDCSyncAction"
+                },
+                "groups": {
+                    "description": "Groups are the groups the user would be a member of in the\norganization. Always empty if the user would be removed.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.OIDCSyncDryRunRequest": {
+            "type": "object",
+            "properties": {
+                "claims": {
+                    "description": "Claims are the merged ID token and user info claims.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "user_id": {
+                    "description": "UserID optionally selects an existing user whose current memberships\nthe result is compared against. If unset, the result is what a new user\nwould get.",
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.OIDCSyncDryRunResponse": {
+            "type": "object",
+            "properties": {
+                "group_sync_enabled": {
+                    "type": "boolean"
+                },
+                "organization_sync_enabled": {
+                    "type": "boolean"
+                },
+                "organizations": {
+                    "description": "Organizations are the organizations the user would be added to, kept in\nor removed from.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.OIDCSyncDryRunOrganization"
+                    }
+                },
+                "unmatched_organizations": {
+                    "description": "UnmatchedOrganizations are claim values that did not match any\norganization, after mapping.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.Organization": {
             "type": "object",
             "required": [
@@ -11114,6 +13257,20 @@ const docTemplate = `{
                 "updated_at"
             ],
             "properties": {
+                "allowed_derp_region_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "allowed_workspace_proxy_ids": {
+                    "description": "AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs restrict which\nworkspace proxies may serve apps for workspaces in this organization, and\nwhich DERP regions their agents may relay through. Empty lists allow all\nproxies and regions.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -11307,11 +13464,18 @@ const docTemplate = `{
                 "callback_url": {
                     "type": "string"
                 },
+                "client_credentials_user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "icon": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
                 }
             }
         },
@@ -11364,6 +13528,9 @@ const docTemplate = `{
         "codersdk.ProvisionerConfig": {
             "type": "object",
             "properties": {
+                "cache_max_size": {
+                    "type": "integer"
+                },
                 "daemon_poll_interval": {
                     "type": "integer"
                 },
@@ -11385,6 +13552,9 @@ const docTemplate = `{
                 },
                 "force_cancel_interval": {
                     "type": "integer"
+                },
+                "provider_mirror": {
+                    "type": "string"
                 }
             }
         },
@@ -11603,6 +13773,60 @@ const docTemplate = `{
                 "ProvisionerStorageMethodFile"
             ]
         },
+        "codersdk.ProvisionerTiming": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "ended_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "job_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "stage": {
+                    "enum": [
+                        "init",
+                        "plan",
+                        "graph",
+                        "apply"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerTimingStage"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.ProvisionerTimingStage": {
+            "type": "string",
+            "enum": [
+                "init",
+                "plan",
+                "graph",
+                "apply"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerTimingStageInit",
+                "ProvisionerTimingStagePlan",
+                "ProvisionerTimingStageGraph",
+                "ProvisionerTimingStageApply"
+            ]
+        },
         "codersdk.ProxyHealthReport": {
             "type": "object",
             "properties": {
@@ -11659,11 +13883,18 @@ const docTemplate = `{
                 "callback_url": {
                     "type": "string"
                 },
+                "client_credentials_user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "icon": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
+                },
+                "public": {
+                    "type": "boolean"
                 }
             }
         },
@@ -11773,6 +14004,13 @@ const docTemplate = `{
                 },
                 "disable_all": {
                     "type": "boolean"
+                },
+                "workspace_app_max_bytes_per_second": {
+                    "type": "integer"
+                },
+                "workspace_app_max_connections_per_user": {
+                    "description": "WorkspaceAppMaxConnectionsPerUser and WorkspaceAppMaxBytesPerSecond\nare enforced by each replica and workspace proxy independently.",
+                    "type": "integer"
                 }
             }
         },
@@ -11928,6 +14166,21 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ResourceChangeAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "replace",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "ResourceChangeActionCreate",
+                "ResourceChangeActionUpdate",
+                "ResourceChangeActionReplace",
+                "ResourceChangeActionDelete"
+            ]
+        },
         "codersdk.ResourceType": {
             "type": "string",
             "enum": [
@@ -11947,7 +14200,9 @@ const docTemplate = `{
                 "organization",
                 "oauth2_provider_app",
                 "oauth2_provider_app_secret",
-                "custom_role"
+                "custom_role",
+                "role_grant_request",
+                "template_version_canary"
             ],
             "x-enum-varnames": [
                 "ResourceTypeTemplate",
@@ -11966,7 +14221,9 @@ const docTemplate = `{
                 "ResourceTypeOrganization",
                 "ResourceTypeOAuth2ProviderApp",
                 "ResourceTypeOAuth2ProviderAppSecret",
-                "ResourceTypeCustomRole"
+                "ResourceTypeCustomRole",
+                "ResourceTypeRoleGrantRequest",
+                "ResourceTypeTemplateVersionCanary"
             ]
         },
         "codersdk.Response": {
@@ -12023,6 +14280,81 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.RoleGrantRequest": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "lifetime_seconds": {
+                    "description": "LifetimeSeconds is how long the role is held once approved.",
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "reviewed_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "role": {
+                    "description": "Role is the requested role. Organization roles include the organization\nID.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.SlimRole"
+                        }
+                    ]
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "denied",
+                        "expired",
+                        "revoked"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.RoleGrantStatus"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.RoleGrantStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "denied",
+                "expired",
+                "revoked"
+            ],
+            "x-enum-varnames": [
+                "RoleGrantStatusPending",
+                "RoleGrantStatusApproved",
+                "RoleGrantStatusDenied",
+                "RoleGrantStatusExpired",
+                "RoleGrantStatusRevoked"
+            ]
+        },
         "codersdk.SSHConfig": {
             "type": "object",
             "properties": {
@@ -12053,6 +14385,55 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.Session": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "expires_at",
+                "id",
+                "last_used",
+                "login_type"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "current": {
+                    "description": "Current is true for the session that made the request.",
+                    "type": "boolean"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_used": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "login_type": {
+                    "enum": [
+                        "password",
+                        "github",
+                        "oidc"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.LoginType"
+                        }
+                    ]
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.SessionCountDeploymentStats": {
             "type": "object",
             "properties": {
@@ -12166,6 +14547,31 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TOTPEnrollment": {
+            "type": "object",
+            "properties": {
+                "qr_code": {
+                    "description": "QRCode is a PNG image of URL.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the otpauth:// URL of the secret.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TelemetryConfig": {
             "type": "object",
             "properties": {
@@ -12204,6 +14610,20 @@ const docTemplate = `{
                 "allow_user_cancel_workspace_jobs": {
                     "type": "boolean"
                 },
+                "allowed_derp_region_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "allowed_workspace_proxy_ids": {
+                    "description": "AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs are enterprise-only.\nThey restrict which workspace proxies may serve apps for workspaces of\nthis template, and which DERP regions their agents may relay through.\nThe primary proxy is identified by the deployment ID. Empty lists allow\nall proxies and regions.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "autostart_requirement": {
                     "$ref": "#/definitions/codersdk.TemplateAutostartRequirement"
                 },
@@ -12215,9 +14635,17 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "build_cancel_grace_period_ms": {
+                    "description": "BuildCancelGracePeriodMillis is how long a canceled workspace build may\ntake to exit gracefully before it is failed. Zero means the provisioner\ndaemon default applies.",
+                    "type": "integer"
+                },
                 "build_time_stats": {
                     "$ref": "#/definitions/codersdk.TemplateBuildTimeStats"
                 },
+                "build_timeout_ms": {
+                    "description": "BuildTimeoutMillis is the maximum duration of a workspace build, after\nwhich it is canceled. Zero means no limit.",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -12244,6 +14672,10 @@ const docTemplate = `{
                 "display_name": {
                     "type": "string"
                 },
+                "drift_detection_interval_ms": {
+                    "description": "DriftDetectionIntervalMillis is how often running workspaces are\nchecked for resources that changed outside of Coder. Zero disables\ndrift detection.",
+                    "type": "integer"
+                },
                 "failure_ttl_ms": {
                     "description": "FailureTTLMillis, TimeTilDormantMillis, and TimeTilDormantAutoDeleteMillis are enterprise-only. Their\nvalues are used if your license is entitled to use the advanced\ntemplate scheduling feature.",
                     "type": "integer"
@@ -12255,6 +14687,13 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "max_app_bytes_per_second": {
+                    "type": "integer"
+                },
+                "max_app_connections_per_user": {
+                    "description": "MaxAppConnectionsPerUser and MaxAppBytesPerSecond limit workspace app\ntraffic on each replica and workspace proxy. The stricter of these and\nthe deployment limits applies. Zero means the deployment limit applies.",
+                    "type": "integer"
+                },
                 "max_port_share_level": {
                     "$ref": "#/definitions/codersdk.WorkspaceAgentPortShareLevel"
                 },
@@ -12278,7 +14717,8 @@ const docTemplate = `{
                 "provisioner": {
                     "type": "string",
                     "enum": [
-                        "terraform"
+                        "terraform",
+                        "tofu"
                     ]
                 },
                 "require_active_version": {
@@ -12656,55 +15096,139 @@ const docTemplate = `{
         "codersdk.TemplateVersion": {
             "type": "object",
             "properties": {
-                "archived": {
-                    "type": "boolean"
+                "archived": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "$ref": "#/definitions/codersdk.MinimalUser"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "job": {
+                    "$ref": "#/definitions/codersdk.ProvisionerJob"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "readme": {
+                    "type": "string"
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "enum": [
+                            "DEPRECATED_PARAMETERS"
+                        ],
+                        "$ref": "#/definitions/codersdk.TemplateVersionWarning"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateVersionCanary": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
                 },
                 "created_by": {
-                    "$ref": "#/definitions/codersdk.MinimalUser"
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "failed_builds": {
+                    "type": "integer"
+                },
+                "group_id": {
+                    "description": "GroupID is a group whose members' workspaces are always part of the\ncohort.",
+                    "type": "string",
+                    "format": "uuid"
                 },
                 "id": {
                     "type": "string",
                     "format": "uuid"
                 },
-                "job": {
-                    "$ref": "#/definitions/codersdk.ProvisionerJob"
+                "min_builds": {
+                    "description": "MinBuilds is the number of finished builds of the canary version needed\nbefore it is promoted or rolled back.",
+                    "type": "integer"
                 },
-                "message": {
-                    "type": "string"
+                "percent": {
+                    "description": "Percent is the percentage of the template's workspaces that are part of\nthe cohort.",
+                    "type": "integer"
                 },
-                "name": {
+                "status": {
+                    "enum": [
+                        "active",
+                        "promoted",
+                        "rolled_back"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateVersionCanaryStatus"
+                        }
+                    ]
+                },
+                "status_reason": {
                     "type": "string"
                 },
-                "organization_id": {
-                    "type": "string",
-                    "format": "uuid"
+                "succeeded_builds": {
+                    "type": "integer"
                 },
-                "readme": {
-                    "type": "string"
+                "success_threshold": {
+                    "description": "SuccessThreshold is the percentage of builds that must succeed for the\ncanary version to be promoted.",
+                    "type": "integer"
                 },
                 "template_id": {
                     "type": "string",
                     "format": "uuid"
                 },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "updated_at": {
                     "type": "string",
                     "format": "date-time"
-                },
-                "warnings": {
-                    "type": "array",
-                    "items": {
-                        "enum": [
-                            "DEPRECATED_PARAMETERS"
-                        ],
-                        "$ref": "#/definitions/codersdk.TemplateVersionWarning"
-                    }
                 }
             }
         },
+        "codersdk.TemplateVersionCanaryStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "promoted",
+                "rolled_back"
+            ],
+            "x-enum-varnames": [
+                "TemplateVersionCanaryStatusActive",
+                "TemplateVersionCanaryStatusPromoted",
+                "TemplateVersionCanaryStatusRolledBack"
+            ]
+        },
         "codersdk.TemplateVersionExternalAuth": {
             "type": "object",
             "properties": {
@@ -12953,6 +15477,20 @@ const docTemplate = `{
         "codersdk.UpdateOrganizationRequest": {
             "type": "object",
             "properties": {
+                "allowed_derp_region_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "allowed_workspace_proxy_ids": {
+                    "description": "AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs are enterprise-only. A\nnil value leaves the current restriction unchanged, and an empty list\nremoves it.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
@@ -13005,6 +15543,25 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateTemplateVersionCanaryRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "promoted",
+                        "rolled_back"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateVersionCanaryStatus"
+                        }
+                    ]
+                }
+            }
+        },
         "codersdk.UpdateUserAppearanceSettingsRequest": {
             "type": "object",
             "required": [
@@ -13191,6 +15748,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "is_service_account": {
+                    "description": "IsServiceAccount is true for non-human users that belong to a single\norganization and can only authenticate with API tokens.",
+                    "type": "boolean"
+                },
                 "last_seen_at": {
                     "type": "string",
                     "format": "date-time"
@@ -13366,6 +15927,22 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UserMFAStatus": {
+            "type": "object",
+            "properties": {
+                "recovery_codes_remaining": {
+                    "description": "RecoveryCodesRemaining is the number of unused recovery codes.",
+                    "type": "integer"
+                },
+                "required": {
+                    "description": "Required is true if the deployment requires the user to log in with a\nsecond factor.",
+                    "type": "boolean"
+                },
+                "totp_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.UserParameter": {
             "type": "object",
             "properties": {
@@ -13995,6 +16572,21 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "protocol": {
+                    "description": "Protocol is derived from the URL scheme. TCP and UDP apps cannot be\nopened in a browser and must be reached with \"coder port-forward\".",
+                    "enum": [
+                        "http",
+                        "https",
+                        "unix",
+                        "tcp",
+                        "udp"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppProtocol"
+                        }
+                    ]
+                },
                 "sharing_level": {
                     "enum": [
                         "owner",
@@ -14040,6 +16632,23 @@ const docTemplate = `{
                 "WorkspaceAppHealthUnhealthy"
             ]
         },
+        "codersdk.WorkspaceAppProtocol": {
+            "type": "string",
+            "enum": [
+                "http",
+                "https",
+                "unix",
+                "tcp",
+                "udp"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAppProtocolHTTP",
+                "WorkspaceAppProtocolHTTPS",
+                "WorkspaceAppProtocolUnix",
+                "WorkspaceAppProtocolTCP",
+                "WorkspaceAppProtocolUDP"
+            ]
+        },
         "codersdk.WorkspaceAppSharingLevel": {
             "type": "string",
             "enum": [
@@ -14178,6 +16787,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceBuildTimings": {
+            "type": "object",
+            "properties": {
+                "agent_timings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.AgentTiming"
+                    }
+                },
+                "provisioner_timings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.ProvisionerTiming"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceConnectionLatencyMS": {
             "type": "object",
             "properties": {
@@ -14218,6 +16844,31 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceDrift": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "description": "CheckedAt is when drift was last checked for the latest build, nil if\nit hasn't been checked yet.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "latest_check": {
+                    "description": "LatestCheck is the most recently scheduled drift check, which may still\nbe pending.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                        }
+                    ]
+                },
+                "resources": {
+                    "description": "Resources are the resources that changed outside of Coder.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceResourceChange"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceHealth": {
             "type": "object",
             "properties": {
@@ -14236,6 +16887,20 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspacePlan": {
+            "type": "object",
+            "properties": {
+                "job": {
+                    "$ref": "#/definitions/codersdk.ProvisionerJob"
+                },
+                "resource_changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceResourceChange"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceProxy": {
             "type": "object",
             "properties": {
@@ -14380,6 +17045,37 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceResourceChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "replace",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ResourceChangeAction"
+                        }
+                    ]
+                },
+                "address": {
+                    "type": "string"
+                },
+                "destructive": {
+                    "description": "Destructive is set for changes that destroy an existing resource, and\nwith it any data that lives on the resource.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspaceResourceMetadata": {
             "type": "object",
             "properties": {
@@ -14592,6 +17288,17 @@ const docTemplate = `{
                 }
             }
         },
+        "healthsdk.DERPHealthHistory": {
+            "type": "object",
+            "properties": {
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/healthsdk.DERPNodeHealthHistory"
+                    }
+                }
+            }
+        },
         "healthsdk.DERPHealthReport": {
             "type": "object",
             "properties": {
@@ -14643,6 +17350,60 @@ const docTemplate = `{
                 }
             }
         },
+        "healthsdk.DERPNodeHealthHistory": {
+            "type": "object",
+            "properties": {
+                "node_name": {
+                    "type": "string"
+                },
+                "region_code": {
+                    "type": "string"
+                },
+                "region_id": {
+                    "type": "integer"
+                },
+                "replica_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/healthsdk.DERPNodeHealthResult"
+                    }
+                }
+            }
+        },
+        "healthsdk.DERPNodeHealthResult": {
+            "type": "object",
+            "properties": {
+                "can_exchange_messages": {
+                    "type": "boolean"
+                },
+                "can_stun": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "packet_loss": {
+                    "type": "number"
+                },
+                "round_trip_ping_ms": {
+                    "type": "integer"
+                },
+                "stun_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
         "healthsdk.DERPNodeReport": {
             "type": "object",
             "properties": {
@@ -14680,6 +17441,9 @@ const docTemplate = `{
                 "node_info": {
                     "$ref": "#/definitions/derp.ServerInfoMessage"
                 },
+                "packet_loss": {
+                    "type": "number"
+                },
                 "round_trip_ping": {
                     "type": "string"
                 },
@@ -15117,6 +17881,35 @@ const docTemplate = `{
                 }
             }
         },
+        "oauth2.DeviceAuthResponse": {
+            "type": "object",
+            "properties": {
+                "device_code": {
+                    "description": "DeviceCode",
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "Expiry is when the device code and user code expire",
+                    "type": "string"
+                },
+                "interval": {
+                    "description": "Interval is the duration in seconds that Poll should wait between requests",
+                    "type": "integer"
+                },
+                "user_code": {
+                    "description": "UserCode is the code the user should enter at the verification uri",
+                    "type": "string"
+                },
+                "verification_uri": {
+                    "description": "VerificationURI is where user should enter the user code",
+                    "type": "string"
+                },
+                "verification_uri_complete": {
+                    "description": "VerificationURIComplete (if populated) includes the user code in the verification URI. This is typically shown to the user in non-textual form, such as a QR code.",
+                    "type": "string"
+                }
+            }
+        },
         "oauth2.Token": {
             "type": "object",
             "properties": {
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -15,11 +17,14 @@ import (
 	"github.com/coder/coder/v2/coderd/apikey"
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/coderd/telemetry"
+	"github.com/coder/coder/v2/coderd/util/slice"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -81,7 +86,24 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cookie, key, err := api.createAPIKey(ctx, apikey.CreateParams{
+	scopes, allowList, ok := api.tokenScopes(ctx, rw, createToken)
+	if !ok {
+		return
+	}
+
+	createCtx := ctx
+	if user.ServiceAccountOrganizationID.Valid {
+		// The admins of the organization that owns a service account can
+		// create its tokens.
+		if !api.Authorize(r, policy.ActionCreate, rbac.ResourceApiKey.InOrg(user.ServiceAccountOrganizationID.UUID).WithOwner(user.ID.String())) {
+			httpapi.Forbidden(rw)
+			return
+		}
+		//nolint:gocritic // The organization was authorized above.
+		createCtx = dbauthz.AsSystemRestricted(ctx)
+	}
+
+	cookie, key, err := api.createAPIKey(createCtx, apikey.CreateParams{
 		UserID:          user.ID,
 		LoginType:       database.LoginTypeToken,
 		DefaultLifetime: api.DeploymentValues.Sessions.DefaultDuration.Value(),
@@ -89,6 +111,8 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 		Scope:           scope,
 		LifetimeSeconds: int64(lifeTime.Seconds()),
 		TokenName:       tokenName,
+		Scopes:          scopes,
+		AllowList:       allowList,
 	})
 	if err != nil {
 		if database.IsUniqueViolation(err, database.UniqueIndexAPIKeyName) {
@@ -125,12 +149,21 @@ func (api *API) postAPIKey(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	user := httpmw.UserParam(r)
 
+	if user.IsServiceAccount {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts cannot create session keys.",
+			Detail:  "Create an API token instead.",
+		})
+		return
+	}
+
 	lifeTime := time.Hour * 24 * 7
 	cookie, _, err := api.createAPIKey(ctx, apikey.CreateParams{
 		UserID:          user.ID,
 		DefaultLifetime: api.DeploymentValues.Sessions.DefaultDuration.Value(),
 		LoginType:       database.LoginTypePassword,
 		RemoteAddr:      r.RemoteAddr,
+		UserAgent:       r.UserAgent(),
 		// All api generated keys will last 1 week. Browser login tokens have
 		// a shorter life.
 		ExpiresAt:       dbtime.Now().Add(lifeTime),
@@ -359,6 +392,107 @@ func (api *API) tokenConfig(rw http.ResponseWriter, r *http.Request) {
 	)
 }
 
+// tokenScopes validates the named scopes and allow list of a create token
+// request, and returns them as they are stored on the API key. Allowing a
+// workspace also allows its template, as the workspace cannot be read or
+// built without it.
+func (api *API) tokenScopes(ctx context.Context, rw http.ResponseWriter, req codersdk.CreateTokenRequest) ([]string, []string, bool) {
+	if len(req.Scopes) == 0 {
+		if len(req.AllowList) > 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "An allow list can only be used with scopes.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "allow_list",
+					Detail: "Set at least one scope to limit the token to resources.",
+				}},
+			})
+			return nil, nil, false
+		}
+		return nil, nil, true
+	}
+	if req.Scope != "" && req.Scope != codersdk.APIKeyScopeAll {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Scope %q cannot be combined with scopes.", req.Scope),
+			Validations: []codersdk.ValidationError{{
+				Field:  "scope",
+				Detail: "Leave this empty when setting scopes.",
+			}},
+		})
+		return nil, nil, false
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, name := range req.Scopes {
+		if !rbac.IsCatalogScope(rbac.ScopeName(name)) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Unknown scope %q.", name),
+				Validations: []codersdk.ValidationError{{
+					Field:  "scopes",
+					Detail: fmt.Sprintf("Valid scopes are %s.", strings.Join(slice.ToStrings(rbac.CatalogScopes()), ", ")),
+				}},
+			})
+			return nil, nil, false
+		}
+		if !slices.Contains(scopes, string(name)) {
+			scopes = append(scopes, string(name))
+		}
+	}
+
+	allowList := make([]string, 0, len(req.AllowList))
+	allow := func(resource codersdk.RBACResource, id uuid.UUID) {
+		entry := fmt.Sprintf("%s:%s", resource, id)
+		if !slices.Contains(allowList, entry) {
+			allowList = append(allowList, entry)
+		}
+	}
+	for _, target := range req.AllowList {
+		var err error
+		switch target.Type {
+		case codersdk.ResourceWorkspace:
+			var workspace database.Workspace
+			workspace, err = api.Database.GetWorkspaceByID(ctx, target.ID)
+			if err == nil {
+				allow(codersdk.ResourceWorkspace, workspace.ID)
+				allow(codersdk.ResourceTemplate, workspace.TemplateID)
+			}
+		case codersdk.ResourceTemplate:
+			var template database.Template
+			template, err = api.Database.GetTemplateByID(ctx, target.ID)
+			if err == nil {
+				allow(codersdk.ResourceTemplate, template.ID)
+			}
+		default:
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Resources of type %q cannot be allow listed.", target.Type),
+				Validations: []codersdk.ValidationError{{
+					Field:  "allow_list",
+					Detail: "Only workspaces and templates can be allow listed.",
+				}},
+			})
+			return nil, nil, false
+		}
+		if httpapi.Is404Error(err) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("The %s %s does not exist.", target.Type, target.ID),
+				Validations: []codersdk.ValidationError{{
+					Field:  "allow_list",
+					Detail: "Every allow listed resource must exist.",
+				}},
+			})
+			return nil, nil, false
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: fmt.Sprintf("Internal error fetching %s.", target.Type),
+				Detail:  err.Error(),
+			})
+			return nil, nil, false
+		}
+	}
+
+	return scopes, allowList, true
+}
+
 func (api *API) validateAPIKeyLifetime(lifetime time.Duration) error {
 	if lifetime <= 0 {
 		return xerrors.New("lifetime must be positive number greater than 0")
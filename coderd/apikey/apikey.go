@@ -12,6 +12,7 @@ import (
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/cryptorand"
 )
 
@@ -28,6 +29,11 @@ type CreateParams struct {
 	Scope           database.APIKeyScope
 	TokenName       string
 	RemoteAddr      string
+	UserAgent       string
+	// Scopes and AllowList limit the key further than Scope. See
+	// database.APIKey.ScopeRBAC.
+	Scopes    []string
+	AllowList []string
 }
 
 // Generate generates an API key, returning the key as a string as well as the
@@ -71,6 +77,25 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 	default:
 		return database.InsertAPIKeyParams{}, "", xerrors.Errorf("invalid API key scope: %q", scope)
 	}
+	if len(params.Scopes) > 0 && scope != database.APIKeyScopeAll {
+		return database.InsertAPIKeyParams{}, "", xerrors.Errorf("API key scope %q cannot be combined with named scopes", scope)
+	}
+	for _, name := range params.Scopes {
+		if !rbac.IsCatalogScope(rbac.ScopeName(name)) {
+			return database.InsertAPIKeyParams{}, "", xerrors.Errorf("invalid API key scope: %q", name)
+		}
+	}
+	if len(params.AllowList) > 0 && len(params.Scopes) == 0 {
+		return database.InsertAPIKeyParams{}, "", xerrors.New("an allow list requires named scopes")
+	}
+	scopes := params.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	allowList := params.AllowList
+	if allowList == nil {
+		allowList = []string{}
+	}
 
 	token := fmt.Sprintf("%s-%s", keyID, keySecret)
 
@@ -94,6 +119,9 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 		LoginType:    params.LoginType,
 		Scope:        scope,
 		TokenName:    params.TokenName,
+		Scopes:       scopes,
+		AllowList:    allowList,
+		UserAgent:    params.UserAgent,
 	}, token, nil
 }
 
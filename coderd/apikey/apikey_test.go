@@ -104,6 +104,63 @@ func TestGenerate(t *testing.T) {
 				Scope:           "",
 			},
 		},
+		{
+			name: "NamedScopes",
+			params: apikey.CreateParams{
+				UserID:          uuid.New(),
+				LoginType:       database.LoginTypeToken,
+				DefaultLifetime: time.Duration(0),
+				ExpiresAt:       time.Now().Add(time.Hour),
+				LifetimeSeconds: int64(time.Hour.Seconds()),
+				TokenName:       "hello",
+				RemoteAddr:      "1.2.3.4",
+				Scopes:          []string{"workspace:read", "workspace:start"},
+				AllowList:       []string{"workspace:" + uuid.NewString()},
+			},
+		},
+		{
+			name: "InvalidNamedScope",
+			params: apikey.CreateParams{
+				UserID:          uuid.New(),
+				LoginType:       database.LoginTypeToken,
+				DefaultLifetime: time.Duration(0),
+				ExpiresAt:       time.Now().Add(time.Hour),
+				LifetimeSeconds: int64(time.Hour.Seconds()),
+				TokenName:       "hello",
+				RemoteAddr:      "1.2.3.4",
+				Scopes:          []string{"workspace:everything"},
+			},
+			fail: true,
+		},
+		{
+			name: "NamedScopesWithScope",
+			params: apikey.CreateParams{
+				UserID:          uuid.New(),
+				LoginType:       database.LoginTypeToken,
+				DefaultLifetime: time.Duration(0),
+				ExpiresAt:       time.Now().Add(time.Hour),
+				LifetimeSeconds: int64(time.Hour.Seconds()),
+				TokenName:       "hello",
+				RemoteAddr:      "1.2.3.4",
+				Scope:           database.APIKeyScopeApplicationConnect,
+				Scopes:          []string{"workspace:read"},
+			},
+			fail: true,
+		},
+		{
+			name: "AllowListWithoutScopes",
+			params: apikey.CreateParams{
+				UserID:          uuid.New(),
+				LoginType:       database.LoginTypeToken,
+				DefaultLifetime: time.Duration(0),
+				ExpiresAt:       time.Now().Add(time.Hour),
+				LifetimeSeconds: int64(time.Hour.Seconds()),
+				TokenName:       "hello",
+				RemoteAddr:      "1.2.3.4",
+				AllowList:       []string{"workspace:" + uuid.NewString()},
+			},
+			fail: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -163,6 +220,17 @@ func TestGenerate(t *testing.T) {
 				assert.Equal(t, database.APIKeyScopeAll, key.Scope)
 			}
 
+			if len(tc.params.Scopes) > 0 {
+				assert.Equal(t, tc.params.Scopes, key.Scopes)
+			} else {
+				assert.Empty(t, key.Scopes)
+			}
+			if len(tc.params.AllowList) > 0 {
+				assert.Equal(t, tc.params.AllowList, key.AllowList)
+			} else {
+				assert.Empty(t, key.AllowList)
+			}
+
 			if tc.params.TokenName != "" {
 				assert.Equal(t, tc.params.TokenName, key.TokenName)
 			}
@@ -0,0 +1,18 @@
+package apikey
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionsRevokedChannel is published to when login sessions of a user are
+// revoked. Subscribers drop anything they derived from the sessions, such as
+// signed workspace app tokens, instead of waiting for it to expire.
+const SessionsRevokedChannel = "api_key_sessions_revoked"
+
+// SessionsRevokedMessage is the payload published to SessionsRevokedChannel.
+type SessionsRevokedMessage struct {
+	UserID    uuid.UUID `json:"user_id"`
+	RevokedAt time.Time `json:"revoked_at"`
+}
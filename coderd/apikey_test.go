@@ -85,6 +85,100 @@ func TestTokenScoped(t *testing.T) {
 	require.Equal(t, keys[0].Scope, codersdk.APIKeyScopeApplicationConnect)
 }
 
+func TestTokenNamedScopes(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	owner := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	allowed := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, allowed.LatestBuild.ID)
+	other := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, other.LatestBuild.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	res, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+		TokenName: "scoped",
+		Scopes:    []codersdk.APIKeyScope{codersdk.APIKeyScopeWorkspaceRead, codersdk.APIKeyScopeWorkspaceStop},
+		AllowList: []codersdk.APIAllowListTarget{{
+			Type: codersdk.ResourceWorkspace,
+			ID:   allowed.ID,
+		}},
+	})
+	require.NoError(t, err)
+
+	key, err := client.APIKeyByName(ctx, codersdk.Me, "scoped")
+	require.NoError(t, err)
+	require.Equal(t, []codersdk.APIKeyScope{codersdk.APIKeyScopeWorkspaceRead, codersdk.APIKeyScopeWorkspaceStop}, key.Scopes)
+	require.ElementsMatch(t, []codersdk.APIAllowListTarget{
+		{Type: codersdk.ResourceWorkspace, ID: allowed.ID},
+		{Type: codersdk.ResourceTemplate, ID: template.ID},
+	}, key.AllowList)
+
+	scoped := codersdk.New(client.URL)
+	scoped.SetSessionToken(res.Key)
+
+	// The key can always read the user that owns it.
+	_, err = scoped.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+
+	_, err = scoped.Workspace(ctx, allowed.ID)
+	require.NoError(t, err)
+
+	// Workspaces outside of the allow list are hidden.
+	_, err = scoped.Workspace(ctx, other.ID)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+	build, err := scoped.CreateWorkspaceBuild(ctx, allowed.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStop,
+	})
+	require.NoError(t, err)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, build.ID)
+
+	// Starting the workspace is not part of the scopes.
+	_, err = scoped.CreateWorkspaceBuild(ctx, allowed.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStart,
+	})
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+	// A scoped key cannot create keys of its own.
+	_, err = scoped.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+	require.Error(t, err)
+
+	t.Run("UnknownScope", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			Scopes: []codersdk.APIKeyScope{"workspace:everything"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("AllowListWithoutScopes", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{
+			AllowList: []codersdk.APIAllowListTarget{{
+				Type: codersdk.ResourceWorkspace,
+				ID:   allowed.ID,
+			}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
 func TestUserSetTokenDuration(t *testing.T) {
 	t.Parallel()
 
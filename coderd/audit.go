@@ -206,6 +206,7 @@ func (api *API) convertAuditLog(ctx context.Context, dblog database.GetAuditLogs
 			QuietHoursSchedule: dblog.UserQuietHoursSchedule.String,
 			ThemePreference:    dblog.UserThemePreference.String,
 			Name:               dblog.UserName.String,
+			IsServiceAccount:   dblog.UserIsServiceAccount.Bool,
 		}, []uuid.UUID{})
 		user = &sdkUser
 	}
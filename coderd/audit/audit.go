@@ -22,6 +22,9 @@ type AdditionalFields struct {
 	BuildReason    database.BuildReason `json:"build_reason"`
 	WorkspaceOwner string               `json:"workspace_owner"`
 	WorkspaceID    uuid.UUID            `json:"workspace_id"`
+	// FailureReason is set when a build failed or was terminated, e.g. because
+	// it exceeded the template's build timeout.
+	FailureReason string `json:"failure_reason,omitempty"`
 }
 
 func NewNop() Auditor {
@@ -26,7 +26,9 @@ type Auditable interface {
 		database.CustomRole |
 		database.AuditableOrganizationMember |
 		database.Organization |
-		database.NotificationTemplate
+		database.NotificationTemplate |
+		database.RoleGrantRequest |
+		database.TemplateVersionCanary
 }
 
 // Map is a map of changed fields in an audited resource. It maps field names to
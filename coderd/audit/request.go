@@ -120,6 +120,10 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return typed.Name
 	case database.NotificationTemplate:
 		return typed.Name
+	case database.RoleGrantRequest:
+		return typed.RoleName
+	case database.TemplateVersionCanary:
+		return "" // no target?
 	default:
 		panic(fmt.Sprintf("unknown resource %T for ResourceTarget", tgt))
 	}
@@ -168,6 +172,10 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 		return typed.ID
 	case database.NotificationTemplate:
 		return typed.ID
+	case database.RoleGrantRequest:
+		return typed.ID
+	case database.TemplateVersionCanary:
+		return typed.ID
 	default:
 		panic(fmt.Sprintf("unknown resource %T for ResourceID", tgt))
 	}
@@ -213,6 +221,10 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeOrganization
 	case database.NotificationTemplate:
 		return database.ResourceTypeNotificationTemplate
+	case database.RoleGrantRequest:
+		return database.ResourceTypeRoleGrantRequest
+	case database.TemplateVersionCanary:
+		return database.ResourceTypeTemplateVersionCanary
 	default:
 		panic(fmt.Sprintf("unknown resource %T for ResourceType", typed))
 	}
@@ -260,6 +272,11 @@ func ResourceRequiresOrgID[T Auditable]() bool {
 		return true
 	case database.NotificationTemplate:
 		return false
+	case database.RoleGrantRequest:
+		// Site wide roles can be requested too.
+		return false
+	case database.TemplateVersionCanary:
+		return true
 	default:
 		panic(fmt.Sprintf("unknown resource %T for ResourceRequiresOrgID", tgt))
 	}
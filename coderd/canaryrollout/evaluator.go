@@ -0,0 +1,338 @@
+package canaryrollout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+)
+
+// acquireLockError is returned when the evaluator fails to acquire a lock and
+// skips a canary.
+type acquireLockError struct{}
+
+// Error implements error.
+func (acquireLockError) Error() string {
+	return "lock is held by another client"
+}
+
+// canaryIneligibleError is returned when a canary is no longer active.
+type canaryIneligibleError struct {
+	Err error
+}
+
+// Error implements error.
+func (e canaryIneligibleError) Error() string {
+	return fmt.Sprintf("canary is no longer active: %s", e.Err)
+}
+
+// Evaluator periodically checks the builds of every active template version
+// canary. Once a canary has seen enough builds it is either promoted to the
+// active version of its template or rolled back, depending on its success
+// rate.
+type Evaluator struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db      database.Store
+	log     slog.Logger
+	tick    <-chan time.Time
+	stats   chan<- Stats
+	auditor *atomic.Pointer[audit.Auditor]
+}
+
+// Stats contains statistics about the last run of the evaluator.
+type Stats struct {
+	// Promoted contains the IDs of the canaries that were promoted.
+	Promoted []uuid.UUID
+	// RolledBack contains the IDs of the canaries that were rolled back.
+	RolledBack []uuid.UUID
+	// Error is the fatal error that occurred during the last run of the
+	// evaluator, if any.
+	Error error
+}
+
+// New returns a new canary evaluator.
+func New(ctx context.Context, db database.Store, log slog.Logger, tick <-chan time.Time) *Evaluator {
+	//nolint:gocritic // Canary evaluator has a limited set of permissions.
+	ctx, cancel := context.WithCancel(dbauthz.AsCanaryEvaluator(ctx))
+	e := &Evaluator{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		db:     db,
+		log:    log,
+		tick:   tick,
+		stats:  nil,
+	}
+	return e
+}
+
+// WithStatsChannel will cause Evaluator to push a Stats to ch after
+// every tick. This push is blocking, so if ch is not read, the evaluator will
+// hang. This should only be used in tests.
+func (e *Evaluator) WithStatsChannel(ch chan<- Stats) *Evaluator {
+	e.stats = ch
+	return e
+}
+
+// WithAuditor will cause Evaluator to write an audit log entry for every
+// template whose active version it changes.
+func (e *Evaluator) WithAuditor(auditor *atomic.Pointer[audit.Auditor]) *Evaluator {
+	e.auditor = auditor
+	return e
+}
+
+// Start will cause the evaluator to evaluate active canaries on every tick
+// from its channel. It will stop when its context is Done, or when its channel
+// is closed.
+//
+// Start should only be called once.
+func (e *Evaluator) Start() {
+	go func() {
+		defer close(e.done)
+		defer e.cancel()
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case t, ok := <-e.tick:
+				if !ok {
+					return
+				}
+				stats := e.run(t)
+				if stats.Error != nil {
+					e.log.Warn(e.ctx, "error running template version canary evaluator once", slog.Error(stats.Error))
+				}
+				if e.stats != nil {
+					select {
+					case <-e.ctx.Done():
+						return
+					case e.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the evaluator is stopped.
+func (e *Evaluator) Wait() {
+	<-e.done
+}
+
+// Close will stop the evaluator.
+func (e *Evaluator) Close() {
+	e.cancel()
+	<-e.done
+}
+
+func (e *Evaluator) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		Promoted:   []uuid.UUID{},
+		RolledBack: []uuid.UUID{},
+		Error:      nil,
+	}
+
+	canaries, err := e.db.GetActiveTemplateVersionCanaries(ctx)
+	if err != nil {
+		stats.Error = xerrors.Errorf("get active template version canaries: %w", err)
+		return stats
+	}
+
+	for _, canary := range canaries {
+		log := e.log.With(
+			slog.F("canary_id", canary.ID),
+			slog.F("template_id", canary.TemplateID),
+			slog.F("template_version_id", canary.TemplateVersionID),
+		)
+
+		result, err := evaluate(ctx, e.db, canary.ID, t)
+		if err != nil {
+			if !(xerrors.As(err, &acquireLockError{}) || xerrors.As(err, &canaryIneligibleError{})) {
+				log.Error(ctx, "error evaluating template version canary", slog.Error(err))
+			}
+			continue
+		}
+		if result == nil {
+			continue
+		}
+
+		switch result.canary.Status {
+		case database.TemplateVersionCanaryStatusPromoted:
+			log.Info(ctx, "promoted template version canary", slog.F("reason", result.canary.StatusReason))
+			stats.Promoted = append(stats.Promoted, canary.ID)
+			e.auditPromotion(ctx, log, *result)
+		case database.TemplateVersionCanaryStatusRolledBack:
+			log.Info(ctx, "rolled back template version canary", slog.F("reason", result.canary.StatusReason))
+			stats.RolledBack = append(stats.RolledBack, canary.ID)
+			e.auditRollback(ctx, log, *result)
+		}
+	}
+
+	return stats
+}
+
+// evaluation is the outcome of a canary that was ended by the evaluator.
+type evaluation struct {
+	oldCanary   database.TemplateVersionCanary
+	canary      database.TemplateVersionCanary
+	oldTemplate database.Template
+	newTemplate database.Template
+}
+
+// evaluate ends the canary if it has seen enough builds. It returns nil if the
+// canary should keep running. Rolling back a canary does not rebuild the
+// workspaces of its cohort, they keep the canary version until they are
+// updated.
+func evaluate(ctx context.Context, db database.Store, canaryID uuid.UUID, t time.Time) (*evaluation, error) {
+	var result *evaluation
+	err := db.InTx(func(db database.Store) error {
+		locked, err := db.TryAcquireLock(ctx, database.GenLockID(fmt.Sprintf("canary-evaluator:%s", canaryID)))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			// This error is ignored.
+			return acquireLockError{}
+		}
+
+		// Refetch the canary while we hold the lock, another replica or an
+		// administrator may have ended it in the meantime.
+		canary, err := db.GetTemplateVersionCanaryByID(ctx, canaryID)
+		if err != nil {
+			return xerrors.Errorf("get template version canary: %w", err)
+		}
+		if canary.Status != database.TemplateVersionCanaryStatusActive {
+			return canaryIneligibleError{Err: xerrors.Errorf("canary is %s", canary.Status)}
+		}
+
+		stats, err := db.GetTemplateVersionCanaryStats(ctx, database.GetTemplateVersionCanaryStatsParams{
+			TemplateVersionID: canary.TemplateVersionID,
+			Since:             canary.CreatedAt,
+		})
+		if err != nil {
+			return xerrors.Errorf("get template version canary stats: %w", err)
+		}
+		total := stats.SucceededBuilds + stats.FailedBuilds
+		if total < int64(canary.MinBuilds) {
+			return nil
+		}
+
+		successRate := stats.SucceededBuilds * 100 / total
+		status := database.TemplateVersionCanaryStatusRolledBack
+		reason := fmt.Sprintf("%d%% of %d builds succeeded, below the %d%% threshold", successRate, total, canary.SuccessThreshold)
+		if successRate >= int64(canary.SuccessThreshold) {
+			status = database.TemplateVersionCanaryStatusPromoted
+			reason = fmt.Sprintf("%d%% of %d builds succeeded", successRate, total)
+		}
+
+		template, err := db.GetTemplateByID(ctx, canary.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		newTemplate := template
+		if status == database.TemplateVersionCanaryStatusPromoted {
+			err = db.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
+				ID:              template.ID,
+				ActiveVersionID: canary.TemplateVersionID,
+				UpdatedAt:       dbtime.Time(t),
+			})
+			if err != nil {
+				return xerrors.Errorf("update active version: %w", err)
+			}
+			newTemplate.ActiveVersionID = canary.TemplateVersionID
+		}
+
+		oldCanary := canary
+		canary, err = db.UpdateTemplateVersionCanaryStatusByID(ctx, database.UpdateTemplateVersionCanaryStatusByIDParams{
+			ID:           canary.ID,
+			Status:       status,
+			StatusReason: reason,
+			UpdatedAt:    dbtime.Time(t),
+		})
+		if err != nil {
+			return xerrors.Errorf("update template version canary status: %w", err)
+		}
+
+		result = &evaluation{
+			oldCanary:   oldCanary,
+			canary:      canary,
+			oldTemplate: template,
+			newTemplate: newTemplate,
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (e *Evaluator) loadAuditor() audit.Auditor {
+	if e.auditor == nil {
+		return nil
+	}
+	auditor := e.auditor.Load()
+	if auditor == nil {
+		return nil
+	}
+	return *auditor
+}
+
+// auditPromotion audits the change of the template's active version.
+func (e *Evaluator) auditPromotion(ctx context.Context, log slog.Logger, result evaluation) {
+	auditor := e.loadAuditor()
+	if auditor == nil {
+		return
+	}
+
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.Template]{
+		Audit:          auditor,
+		Log:            log,
+		UserID:         result.canary.CreatedBy,
+		OrganizationID: result.newTemplate.OrganizationID,
+		RequestID:      result.canary.ID,
+		Action:         database.AuditActionWrite,
+		Old:            result.oldTemplate,
+		New:            result.newTemplate,
+		Status:         http.StatusOK,
+	})
+}
+
+// auditRollback audits the status change of the canary. A rollback leaves the
+// template untouched, so the canary is the only resource that changes.
+func (e *Evaluator) auditRollback(ctx context.Context, log slog.Logger, result evaluation) {
+	auditor := e.loadAuditor()
+	if auditor == nil {
+		return
+	}
+
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.TemplateVersionCanary]{
+		Audit:          auditor,
+		Log:            log,
+		UserID:         result.canary.CreatedBy,
+		OrganizationID: result.newTemplate.OrganizationID,
+		RequestID:      result.canary.ID,
+		Action:         database.AuditActionWrite,
+		Old:            result.oldCanary,
+		New:            result.canary,
+		Status:         http.StatusOK,
+	})
+}
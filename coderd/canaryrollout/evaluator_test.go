@@ -0,0 +1,238 @@
+package canaryrollout_test
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/canaryrollout"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/coderd/database/dbgen"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestEvaluatorNoCanaries(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan canaryrollout.Stats)
+	)
+
+	evaluator := canaryrollout.New(ctx, db, log, tickCh).WithStatsChannel(statsCh)
+	evaluator.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Promoted)
+	require.Empty(t, stats.RolledBack)
+
+	evaluator.Close()
+	evaluator.Wait()
+}
+
+func TestEvaluatorWaitsForMinBuilds(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan canaryrollout.Stats)
+	)
+
+	env := setupCanary(t, db, 3, 90)
+	env.build(t, db, true)
+
+	evaluator := canaryrollout.New(ctx, db, log, tickCh).WithStatsChannel(statsCh)
+	evaluator.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Promoted)
+	require.Empty(t, stats.RolledBack)
+
+	canary, err := db.GetTemplateVersionCanaryByID(ctx, env.canary.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateVersionCanaryStatusActive, canary.Status)
+
+	evaluator.Close()
+	evaluator.Wait()
+}
+
+func TestEvaluatorPromotes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan canaryrollout.Stats)
+	)
+
+	env := setupCanary(t, db, 2, 50)
+	env.build(t, db, true)
+	env.build(t, db, false)
+
+	evaluator := canaryrollout.New(ctx, db, log, tickCh).WithStatsChannel(statsCh)
+	evaluator.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Equal(t, []uuid.UUID{env.canary.ID}, stats.Promoted)
+	require.Empty(t, stats.RolledBack)
+
+	canary, err := db.GetTemplateVersionCanaryByID(ctx, env.canary.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateVersionCanaryStatusPromoted, canary.Status)
+	require.NotEmpty(t, canary.StatusReason)
+	require.True(t, canary.CompletedAt.Valid)
+
+	template, err := db.GetTemplateByID(ctx, env.template.ID)
+	require.NoError(t, err)
+	require.Equal(t, env.canary.TemplateVersionID, template.ActiveVersionID)
+
+	// An ended canary is not evaluated again.
+	tickCh <- time.Now()
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Promoted)
+
+	evaluator.Close()
+	evaluator.Wait()
+}
+
+func TestEvaluatorRollsBack(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan canaryrollout.Stats)
+		auditor = audit.NewMock()
+	)
+
+	env := setupCanary(t, db, 2, 90)
+	env.build(t, db, true)
+	env.build(t, db, false)
+
+	var auditorPtr atomic.Pointer[audit.Auditor]
+	var a audit.Auditor = auditor
+	auditorPtr.Store(&a)
+
+	evaluator := canaryrollout.New(ctx, db, log, tickCh).WithStatsChannel(statsCh).WithAuditor(&auditorPtr)
+	evaluator.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Promoted)
+	require.Equal(t, []uuid.UUID{env.canary.ID}, stats.RolledBack)
+
+	canary, err := db.GetTemplateVersionCanaryByID(ctx, env.canary.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.TemplateVersionCanaryStatusRolledBack, canary.Status)
+
+	// The active version is left untouched.
+	template, err := db.GetTemplateByID(ctx, env.template.ID)
+	require.NoError(t, err)
+	require.Equal(t, env.template.ActiveVersionID, template.ActiveVersionID)
+
+	// The rollback is audited on the canary.
+	require.True(t, auditor.Contains(t, database.AuditLog{
+		ResourceType: database.ResourceTypeTemplateVersionCanary,
+		ResourceID:   env.canary.ID,
+		Action:       database.AuditActionWrite,
+		UserID:       env.owner.ID,
+	}))
+
+	evaluator.Close()
+	evaluator.Wait()
+}
+
+type canaryEnv struct {
+	owner    database.User
+	template database.Template
+	canary   database.TemplateVersionCanary
+}
+
+// setupCanary creates a template with a second version that is rolled out as
+// a canary to every workspace.
+func setupCanary(t *testing.T, db database.Store, minBuilds, successThreshold int32) canaryEnv {
+	t.Helper()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	owner := dbgen.User(t, db, database.User{})
+	active := dbfake.TemplateVersion(t, db).Seed(database.TemplateVersion{
+		OrganizationID: org.ID,
+		CreatedBy:      owner.ID,
+	}).Do()
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		OrganizationID: org.ID,
+		TemplateID:     uuid.NullUUID{UUID: active.Template.ID, Valid: true},
+		CreatedBy:      owner.ID,
+	})
+	canary := dbgen.TemplateVersionCanary(t, db, database.TemplateVersionCanary{
+		TemplateID:        active.Template.ID,
+		TemplateVersionID: version.ID,
+		Percent:           100,
+		MinBuilds:         minBuilds,
+		SuccessThreshold:  successThreshold,
+		CreatedBy:         owner.ID,
+		CreatedAt:         dbtime.Now().Add(-time.Minute),
+	})
+	return canaryEnv{
+		owner:    owner,
+		template: active.Template,
+		canary:   canary,
+	}
+}
+
+// build inserts a finished start build of the canary version.
+func (e canaryEnv) build(t *testing.T, db database.Store, succeeded bool) {
+	t.Helper()
+
+	resp := dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: e.template.OrganizationID,
+		OwnerID:        e.owner.ID,
+		TemplateID:     e.template.ID,
+	}).Seed(database.WorkspaceBuild{
+		TemplateVersionID: e.canary.TemplateVersionID,
+		Transition:        database.WorkspaceTransitionStart,
+	}).Do()
+	if succeeded {
+		return
+	}
+	err := db.UpdateProvisionerJobWithCompleteByID(context.Background(), database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:          resp.Build.JobID,
+		UpdatedAt:   dbtime.Now(),
+		CompletedAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+		Error:       sql.NullString{String: "failed", Valid: true},
+	})
+	require.NoError(t, err)
+}
@@ -62,6 +62,7 @@ import (
 	"github.com/coder/coder/v2/coderd/portsharing"
 	"github.com/coder/coder/v2/coderd/prometheusmetrics"
 	"github.com/coder/coder/v2/coderd/provisionerdserver"
+	"github.com/coder/coder/v2/coderd/proxypinning"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/coderd/rbac/rolestore"
@@ -146,6 +147,7 @@ type Options struct {
 	GoogleTokenValidator           *idtoken.Validator
 	GithubOAuth2Config             *GithubOAuth2Config
 	OIDCConfig                     *OIDCConfig
+	LDAPConfig                     *LDAPConfig
 	PrometheusRegistry             *prometheus.Registry
 	SecureAuthCookie               bool
 	StrictTransportSecurityCfg     httpmw.HSTSConfig
@@ -176,9 +178,12 @@ type Options struct {
 	SwaggerEndpoint                bool
 	SetUserGroups                  func(ctx context.Context, logger slog.Logger, tx database.Store, userID uuid.UUID, orgGroupNames map[uuid.UUID][]string, createMissingGroups bool) error
 	SetUserSiteRoles               func(ctx context.Context, logger slog.Logger, tx database.Store, userID uuid.UUID, roles []string) error
-	TemplateScheduleStore          *atomic.Pointer[schedule.TemplateScheduleStore]
-	UserQuietHoursScheduleStore    *atomic.Pointer[schedule.UserQuietHoursScheduleStore]
-	AccessControlStore             *atomic.Pointer[dbauthz.AccessControlStore]
+	// SetUserOrganizations returns a commitAudit function that must only be
+	// called once tx has been committed.
+	SetUserOrganizations        func(ctx context.Context, logger slog.Logger, tx database.Store, userID uuid.UUID, organizations []string) (commitAudit func(), err error)
+	TemplateScheduleStore       *atomic.Pointer[schedule.TemplateScheduleStore]
+	UserQuietHoursScheduleStore *atomic.Pointer[schedule.UserQuietHoursScheduleStore]
+	AccessControlStore          *atomic.Pointer[dbauthz.AccessControlStore]
 	// AppSecurityKey is the crypto key used to sign and encrypt tokens related to
 	// workspace applications. It consists of both a signing and encryption key.
 	AppSecurityKey workspaceapps.SecurityKey
@@ -190,6 +195,9 @@ type Options struct {
 	HealthcheckTimeout           time.Duration
 	HealthcheckRefresh           time.Duration
 	WorkspaceProxiesFetchUpdater *atomic.Pointer[healthcheck.WorkspaceProxiesFetchUpdater]
+	// DERPHealthHistoryInterval is how often DERP health is recorded for
+	// the history endpoint. Zero disables recording.
+	DERPHealthHistoryInterval time.Duration
 
 	// OAuthSigningKey is the crypto key used to sign and encrypt state strings
 	// related to OAuth. This is a symmetric secret key using hmac to sign payloads.
@@ -375,6 +383,14 @@ func New(options *Options) *API {
 			return nil
 		}
 	}
+	if options.SetUserOrganizations == nil {
+		options.SetUserOrganizations = func(ctx context.Context, logger slog.Logger, _ database.Store, userID uuid.UUID, organizations []string) (func(), error) {
+			logger.Warn(ctx, "attempted to assign OIDC organizations without enterprise license",
+				slog.F("user_id", userID), slog.F("organizations", organizations),
+			)
+			return func() {}, nil
+		}
+	}
 	if options.TemplateScheduleStore == nil {
 		options.TemplateScheduleStore = &atomic.Pointer[schedule.TemplateScheduleStore]{}
 	}
@@ -451,16 +467,6 @@ func New(options *Options) *API {
 			Authorizer: options.Authorizer,
 			Logger:     options.Logger,
 		},
-		WorkspaceAppsProvider: workspaceapps.NewDBTokenProvider(
-			options.Logger.Named("workspaceapps"),
-			options.AccessURL,
-			options.Authorizer,
-			options.Database,
-			options.DeploymentValues,
-			oauthConfigs,
-			options.AgentInactiveDisconnectTimeout,
-			options.AppSecurityKey,
-		),
 		metricsCache:                metricsCache,
 		Auditor:                     atomic.Pointer[audit.Auditor]{},
 		TailnetCoordinator:          atomic.Pointer[tailnet.Coordinator]{},
@@ -481,6 +487,25 @@ func New(options *Options) *API {
 	f := appearance.NewDefaultFetcher(api.DeploymentValues.DocsURL.String())
 	api.AppearanceFetcher.Store(&f)
 	api.PortSharer.Store(&portsharing.DefaultPortSharer)
+	api.WorkspaceProxyPinner.Store(&proxypinning.DefaultPinner)
+	appsTokenProvider := workspaceapps.NewDBTokenProvider(
+		options.Logger.Named("workspaceapps"),
+		options.AccessURL,
+		options.Authorizer,
+		options.Database,
+		options.DeploymentValues,
+		oauthConfigs,
+		options.AgentInactiveDisconnectTimeout,
+		options.AppSecurityKey,
+		&api.Auditor,
+		&api.WorkspaceProxyPinner,
+		api.PrimaryWorkspaceProxyID(),
+	)
+	api.WorkspaceAppsProvider = appsTokenProvider
+	api.cancelSessionsRevoked, err = api.subscribeSessionsRevoked(appsTokenProvider)
+	if err != nil {
+		panic(xerrors.Errorf("subscribe to revoked sessions: %w", err))
+	}
 	buildInfo := codersdk.BuildInfoResponse{
 		ExternalURL:     buildinfo.ExternalURL(),
 		Version:         buildinfo.Version(),
@@ -612,6 +637,13 @@ func New(options *Options) *API {
 		UpdateAgentMetricsFn:  options.UpdateAgentMetrics,
 		AppStatBatchSize:      workspaceapps.DefaultStatsDBReporterBatchSize,
 	})
+	if options.DERPHealthHistoryInterval > 0 {
+		api.derpHealthHistory = derphealth.NewHistoryRecorder(api.ctx, options.Logger.Named("derp_health_history"), options.Database, derphealth.HistoryOptions{
+			ReplicaID: api.ID,
+			Interval:  options.DERPHealthHistoryInterval,
+			DERPMap:   api.DERPMap,
+		})
+	}
 	workspaceAppsLogger := options.Logger.Named("workspaceapps")
 	if options.WorkspaceAppsStatsCollectorOptions.Logger == nil {
 		named := workspaceAppsLogger.Named("stats_collector")
@@ -637,6 +669,7 @@ func New(options *Options) *API {
 		AgentProvider:       api.agentProvider,
 		AppSecurityKey:      options.AppSecurityKey,
 		StatsCollector:      workspaceapps.NewStatsCollector(options.WorkspaceAppsStatsCollectorOptions),
+		Limiter:             workspaceapps.NewLimiter(options.PrometheusRegistry),
 
 		DisablePathApps:  options.DeploymentValues.DisablePathApps.Value(),
 		SecureAuthCookie: options.DeploymentValues.SecureAuthCookie.Value(),
@@ -776,30 +809,51 @@ func New(options *Options) *API {
 	// for an external application to use Coder as an OAuth2 provider, not for
 	// logging into Coder with an external OAuth2 provider.
 	r.Route("/oauth2", func(r chi.Router) {
-		r.Use(
-			api.oAuth2ProviderMiddleware,
-			// Fetch the app as system because in the /tokens route there will be no
-			// authenticated user.
-			httpmw.AsAuthzSystem(httpmw.ExtractOAuth2ProviderApp(options.Database)),
-		)
-		r.Route("/authorize", func(r chi.Router) {
+		r.Use(api.oAuth2ProviderMiddleware)
+		// The device verification page is visited by the user, who does not
+		// know the app's client ID, so the app is looked up from the user code.
+		r.Route("/device/verify", func(r chi.Router) {
 			r.Use(apiKeyMiddlewareRedirect)
-			r.Get("/", api.getOAuth2ProviderAppAuthorize())
+			r.Get("/", api.getOAuth2ProviderDeviceVerify())
 		})
-		r.Route("/tokens", func(r chi.Router) {
-			r.Group(func(r chi.Router) {
-				r.Use(apiKeyMiddleware)
-				// DELETE on /tokens is not part of the OAuth2 spec.  It is our own
-				// route used to revoke permissions from an application.  It is here for
-				// parity with POST on /tokens.
-				r.Delete("/", api.deleteOAuth2ProviderAppTokens())
+		r.Group(func(r chi.Router) {
+			r.Use(
+				// Fetch the app as system because in the /tokens and /device
+				// routes there will be no authenticated user.
+				httpmw.AsAuthzSystem(httpmw.ExtractOAuth2ProviderApp(options.Database)),
+			)
+			r.Route("/authorize", func(r chi.Router) {
+				r.Use(apiKeyMiddlewareRedirect)
+				r.Get("/", api.getOAuth2ProviderAppAuthorize())
 			})
-			// The POST /tokens endpoint will be called from an unauthorized client so
-			// we cannot require an API key.
-			r.Post("/", api.postOAuth2ProviderAppToken())
+			r.Route("/tokens", func(r chi.Router) {
+				r.Group(func(r chi.Router) {
+					r.Use(apiKeyMiddleware)
+					// DELETE on /tokens is not part of the OAuth2 spec.  It is our own
+					// route used to revoke permissions from an application.  It is here for
+					// parity with POST on /tokens.
+					r.Delete("/", api.deleteOAuth2ProviderAppTokens())
+				})
+				// The POST /tokens endpoint will be called from an unauthorized client so
+				// we cannot require an API key.
+				r.Post("/", api.postOAuth2ProviderAppToken())
+			})
+			// The device will not have a browser, so like POST on /tokens it is
+			// called without an API key.
+			r.Post("/device", api.postOAuth2ProviderDeviceAuthorization())
 		})
 	})
 
+	// Devices started with "coder login --device" are approved on this page.
+	r.Route(deviceLoginPath, func(r chi.Router) {
+		r.Use(
+			apiKeyMiddlewareRedirect,
+			// Limit approvals the same way as password logins, keyed by user.
+			httpmw.RateLimit(options.LoginRateLimit, time.Minute),
+		)
+		r.Get("/", api.getDeviceLoginVerify())
+	})
+
 	r.Route("/api/v2", func(r chi.Router) {
 		api.APIHandler = r
 
@@ -935,6 +989,11 @@ func New(options *Options) *API {
 				r.Get("/", api.template)
 				r.Delete("/", api.deleteTemplate)
 				r.Patch("/", api.patchTemplateMeta)
+				r.Route("/canary", func(r chi.Router) {
+					r.Get("/", api.templateVersionCanary)
+					r.Post("/", api.postTemplateVersionCanary)
+					r.Patch("/", api.patchTemplateVersionCanary)
+				})
 				r.Route("/versions", func(r chi.Router) {
 					r.Post("/archive", api.postArchiveTemplateVersions)
 					r.Get("/", api.templateVersionsByTemplate)
@@ -962,6 +1021,7 @@ func New(options *Options) *API {
 			r.Get("/variables", api.templateVersionVariables)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
+			r.Get("/timings", api.templateVersionTimings)
 			r.Route("/dry-run", func(r chi.Router) {
 				r.Post("/", api.postTemplateVersionDryRun)
 				r.Get("/{jobID}", api.templateVersionDryRun)
@@ -983,6 +1043,12 @@ func New(options *Options) *API {
 				// This value is intentionally increased during tests.
 				r.Use(httpmw.RateLimit(options.LoginRateLimit, time.Minute))
 				r.Post("/login", api.postLogin)
+				r.Post("/login/mfa/totp", api.postLoginTOTP)
+				r.Post("/ldap/login", api.postLDAPLogin)
+				r.Route("/login/device", func(r chi.Router) {
+					r.Post("/", api.postDeviceLogin)
+					r.Post("/token", api.postDeviceLoginToken)
+				})
 				r.Route("/oauth2", func(r chi.Router) {
 					r.Route("/github", func(r chi.Router) {
 						r.Use(
@@ -1022,6 +1088,15 @@ func New(options *Options) *API {
 						r.Put("/activate", api.putActivateUserAccount())
 					})
 					r.Put("/appearance", api.putUserAppearanceSettings)
+					r.Route("/mfa", func(r chi.Router) {
+						r.Get("/", api.userMFAStatus)
+						r.Route("/totp", func(r chi.Router) {
+							r.Use(httpmw.RateLimit(options.LoginRateLimit, time.Minute))
+							r.Post("/", api.postUserTOTP)
+							r.Delete("/", api.deleteUserTOTP)
+							r.Post("/confirm", api.postUserTOTPConfirm)
+						})
+					})
 					r.Route("/password", func(r chi.Router) {
 						r.Use(httpmw.RateLimit(options.LoginRateLimit, time.Minute))
 						r.Put("/", api.putUserPassword)
@@ -1029,6 +1104,10 @@ func New(options *Options) *API {
 					// These roles apply to the site wide permissions.
 					r.Put("/roles", api.putUserRoles)
 					r.Get("/roles", api.userRoles)
+					r.Route("/role-grants", func(r chi.Router) {
+						r.Get("/", api.userRoleGrants)
+						r.Post("/", api.postUserRoleGrant)
+					})
 
 					r.Route("/keys", func(r chi.Router) {
 						r.Post("/", api.postAPIKey)
@@ -1046,6 +1125,12 @@ func New(options *Options) *API {
 						})
 					})
 
+					r.Route("/sessions", func(r chi.Router) {
+						r.Get("/", api.userSessions)
+						r.Delete("/", api.deleteUserSessions)
+						r.Delete("/{session}", api.deleteUserSession)
+					})
+
 					r.Route("/organizations", func(r chi.Router) {
 						r.Get("/", api.organizationsByUser)
 						r.Get("/{organizationname}", api.organizationByUserAndName)
@@ -1066,6 +1151,17 @@ func New(options *Options) *API {
 				})
 			})
 		})
+		r.Route("/role-grants", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.roleGrants)
+			r.Route("/{rolegrant}", func(r chi.Router) {
+				r.Use(httpmw.ExtractRoleGrantRequestParam(options.Database))
+				r.Get("/", api.roleGrant)
+				r.Post("/approve", api.postApproveRoleGrant)
+				r.Post("/deny", api.postDenyRoleGrant)
+				r.Post("/revoke", api.postRevokeRoleGrant)
+			})
+		})
 		r.Route("/workspaceagents", func(r chi.Router) {
 			r.Post("/azure-instance-identity", api.postWorkspaceAuthAzureInstanceIdentity)
 			r.Post("/aws-instance-identity", api.postWorkspaceAuthAWSInstanceIdentity)
@@ -1130,6 +1226,13 @@ func New(options *Options) *API {
 					r.Get("/", api.workspaceBuilds)
 					r.Post("/", api.postWorkspaceBuilds)
 				})
+				r.Route("/plans", func(r chi.Router) {
+					r.Post("/", api.postWorkspacePlan)
+					r.Get("/{jobID}", api.workspacePlan)
+					r.Get("/{jobID}/logs", api.workspacePlanLogs)
+					r.Patch("/{jobID}/cancel", api.patchWorkspacePlanCancel)
+				})
+				r.Get("/drift", api.workspaceDrift)
 				r.Route("/autostart", func(r chi.Router) {
 					r.Put("/", api.putWorkspaceAutostart)
 				})
@@ -1163,6 +1266,7 @@ func New(options *Options) *API {
 			r.Get("/parameters", api.workspaceBuildParameters)
 			r.Get("/resources", api.workspaceBuildResourcesDeprecated)
 			r.Get("/state", api.workspaceBuildState)
+			r.Get("/timings", api.workspaceBuildTimings)
 		})
 		r.Route("/authcheck", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
@@ -1214,6 +1318,7 @@ func New(options *Options) *API {
 					r.Get("/", api.deploymentHealthSettings)
 					r.Put("/", api.putDeploymentHealthSettings)
 				})
+				r.Get("/derp/history", api.debugDERPHealthHistory)
 			})
 			r.Get("/ws", (&healthcheck.WebsocketEchoServer{}).ServeHTTP)
 			r.Route("/{user}", func(r chi.Router) {
@@ -1347,6 +1452,9 @@ type API struct {
 	// passed to dbauthz.
 	AccessControlStore *atomic.Pointer[dbauthz.AccessControlStore]
 	PortSharer         atomic.Pointer[portsharing.PortSharer]
+	// WorkspaceProxyPinner restricts the workspace proxies and DERP regions
+	// that may be used to reach a workspace.
+	WorkspaceProxyPinner atomic.Pointer[proxypinning.Pinner]
 
 	HTTPAuth *HTTPAuthorizer
 
@@ -1376,11 +1484,15 @@ type API struct {
 	healthCheckCache atomic.Pointer[healthsdk.HealthcheckReport]
 
 	statsReporter *workspacestats.Reporter
+	// derpHealthHistory is nil if DERP health history recording is disabled.
+	derpHealthHistory io.Closer
 
 	Acquirer *provisionerdserver.Acquirer
 	// dbRolluper rolls up template usage stats from raw agent and app
 	// stats. This is used to provide insights in the WebUI.
 	dbRolluper *dbrollup.Rolluper
+	// cancelSessionsRevoked stops listening for revoked sessions.
+	cancelSessionsRevoked func()
 }
 
 // Close waits for all WebSocket connections to drain before returning.
@@ -1413,6 +1525,7 @@ func (api *API) Close() error {
 		api.Logger.Warn(api.ctx, "websocket shutdown timed out after 10 seconds")
 	}
 
+	api.cancelSessionsRevoked()
 	api.dbRolluper.Close()
 	api.metricsCache.Close()
 	if api.updateChecker != nil {
@@ -1425,6 +1538,9 @@ func (api *API) Close() error {
 	}
 	_ = api.agentProvider.Close()
 	_ = api.statsReporter.Close()
+	if api.derpHealthHistory != nil {
+		_ = api.derpHealthHistory.Close()
+	}
 	_ = api.NetworkTelemetryBatcher.Close()
 	return nil
 }
@@ -0,0 +1,409 @@
+// Package ldaptest provides an in-process LDAP server for tests. It implements
+// just enough of the protocol to authenticate users: simple binds, searches
+// with the common filter types, StartTLS and LDAPS.
+package ldaptest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Entry is a directory entry. Password is the password used to bind as the
+// entry and is never returned by searches.
+type Entry struct {
+	DN         string
+	Password   string
+	Attributes map[string][]string
+}
+
+// FakeLDAP is an in-process LDAP server.
+type FakeLDAP struct {
+	t         testing.TB
+	listener  net.Listener
+	tlsConfig *tls.Config
+	ldaps     bool
+	startTLS  bool
+	anonymous bool
+
+	mu      sync.Mutex
+	entries []Entry
+	binds   []string
+}
+
+type FakeLDAPOpt func(f *FakeLDAP)
+
+// WithLDAPS serves TLS on every connection, as an ldaps:// server does.
+func WithLDAPS() FakeLDAPOpt {
+	return func(f *FakeLDAP) {
+		f.ldaps = true
+	}
+}
+
+// WithStartTLS allows clients to upgrade ldap:// connections to TLS.
+func WithStartTLS() FakeLDAPOpt {
+	return func(f *FakeLDAP) {
+		f.startTLS = true
+	}
+}
+
+// WithAnonymousSearch allows searches without binding first.
+func WithAnonymousSearch() FakeLDAPOpt {
+	return func(f *FakeLDAP) {
+		f.anonymous = true
+	}
+}
+
+// WithEntries adds entries to the directory.
+func WithEntries(entries ...Entry) FakeLDAPOpt {
+	return func(f *FakeLDAP) {
+		f.entries = append(f.entries, entries...)
+	}
+}
+
+// New starts a FakeLDAP that is closed when the test ends.
+func New(t testing.TB, opts ...FakeLDAPOpt) *FakeLDAP {
+	t.Helper()
+
+	f := &FakeLDAP{
+		t: t,
+		tlsConfig: &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{testutil.GenerateTLSCertificate(t, "localhost")},
+		},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f.listener = listener
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if f.ldaps {
+				conn = tls.Server(conn, f.tlsConfig)
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+// URL returns the URL clients should dial.
+func (f *FakeLDAP) URL() string {
+	scheme := "ldap"
+	if f.ldaps {
+		scheme = "ldaps"
+	}
+	return fmt.Sprintf("%s://%s", scheme, f.listener.Addr().String())
+}
+
+// RootCAs returns a pool that trusts the certificate of the server.
+func (f *FakeLDAP) RootCAs() *x509.CertPool {
+	cert, err := x509.ParseCertificate(f.tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(f.t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return pool
+}
+
+// AddEntry adds an entry to the directory, replacing any entry with the same
+// DN.
+func (f *FakeLDAP) AddEntry(entry Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, existing := range f.entries {
+		if dnEqual(existing.DN, entry.DN) {
+			f.entries[i] = entry
+			return
+		}
+	}
+	f.entries = append(f.entries, entry)
+}
+
+// Binds returns the DNs of every successful simple bind, in order. Anonymous
+// binds are not included.
+func (f *FakeLDAP) Binds() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.binds...)
+}
+
+func (f *FakeLDAP) serve(conn net.Conn) {
+	defer conn.Close()
+
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		id, ok := packet.Children[0].Value.(int64)
+		if !ok {
+			return
+		}
+		request := packet.Children[1]
+
+		switch request.Tag {
+		case ldap.ApplicationBindRequest:
+			// A failed bind leaves the connection anonymous.
+			var code uint16
+			code, boundDN = f.bind(request)
+			err = write(conn, id, result(ldap.ApplicationBindResponse, code, ""))
+		case ldap.ApplicationSearchRequest:
+			err = f.search(conn, id, request, boundDN)
+		case ldap.ApplicationExtendedRequest:
+			var upgraded net.Conn
+			upgraded, err = f.extended(conn, id, request)
+			if upgraded != nil {
+				conn = upgraded
+			}
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			err = errors.New("unsupported request")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (f *FakeLDAP) bind(request *ber.Packet) (code uint16, dn string) {
+	if len(request.Children) < 3 || request.Children[2].Tag != 0 {
+		return ldap.LDAPResultAuthMethodNotSupported, ""
+	}
+	dn = request.Children[1].Data.String()
+	password := request.Children[2].Data.String()
+	if dn == "" && password == "" {
+		return ldap.LDAPResultSuccess, ""
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range f.entries {
+		if dnEqual(entry.DN, dn) && entry.Password != "" && entry.Password == password {
+			f.binds = append(f.binds, entry.DN)
+			return ldap.LDAPResultSuccess, entry.DN
+		}
+	}
+	return ldap.LDAPResultInvalidCredentials, ""
+}
+
+func (f *FakeLDAP) search(conn net.Conn, id int64, request *ber.Packet, boundDN string) error {
+	if len(request.Children) < 8 {
+		return errors.New("malformed search request")
+	}
+	if boundDN == "" && !f.anonymous {
+		return write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights, "anonymous search is not allowed"))
+	}
+
+	base, err := ldap.ParseDN(request.Children[0].Data.String())
+	if err != nil {
+		return write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultInvalidDNSyntax, err.Error()))
+	}
+	scope, _ := request.Children[1].Value.(int64)
+	sizeLimit, _ := request.Children[3].Value.(int64)
+	filter := request.Children[6]
+	var attributes []string
+	for _, attribute := range request.Children[7].Children {
+		attributes = append(attributes, attribute.Data.String())
+	}
+
+	f.mu.Lock()
+	var found []Entry
+	for _, entry := range f.entries {
+		dn, err := ldap.ParseDN(entry.DN)
+		if err != nil || !inScope(base, dn, scope) {
+			continue
+		}
+		ok, err := matches(filter, entry)
+		if err != nil {
+			f.mu.Unlock()
+			return write(conn, id, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, err.Error()))
+		}
+		if ok {
+			found = append(found, entry)
+		}
+	}
+	f.mu.Unlock()
+
+	code := uint16(ldap.LDAPResultSuccess)
+	if sizeLimit > 0 && int64(len(found)) > sizeLimit {
+		found = found[:sizeLimit]
+		code = ldap.LDAPResultSizeLimitExceeded
+	}
+	for _, entry := range found {
+		err = write(conn, id, searchEntry(entry, attributes))
+		if err != nil {
+			return err
+		}
+	}
+	return write(conn, id, result(ldap.ApplicationSearchResultDone, code, ""))
+}
+
+func (f *FakeLDAP) extended(conn net.Conn, id int64, request *ber.Packet) (net.Conn, error) {
+	if len(request.Children) < 1 || request.Children[0].Data.String() != startTLSOID {
+		return nil, write(conn, id, result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation"))
+	}
+	if !f.startTLS || f.ldaps {
+		return nil, write(conn, id, result(ldap.ApplicationExtendedResponse, ldap.LDAPResultUnavailable, "StartTLS is not available"))
+	}
+	err := write(conn, id, result(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, ""))
+	if err != nil {
+		return nil, err
+	}
+	upgraded := tls.Server(conn, f.tlsConfig)
+	return upgraded, upgraded.Handshake()
+}
+
+func inScope(base, dn *ldap.DN, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return base.EqualFold(dn)
+	case ldap.ScopeSingleLevel:
+		return base.AncestorOfFold(dn) && len(dn.RDNs) == len(base.RDNs)+1
+	default:
+		return base.EqualFold(dn) || base.AncestorOfFold(dn)
+	}
+}
+
+// matches evaluates a search filter against an entry. Values are compared
+// case-insensitively, like the caseIgnoreMatch rule most attributes use.
+func matches(filter *ber.Packet, entry Entry) (bool, error) {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			ok, err := matches(child, entry)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			ok, err := matches(child, entry)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	case ldap.FilterNot:
+		if len(filter.Children) != 1 {
+			return false, errors.New("malformed not filter")
+		}
+		ok, err := matches(filter.Children[0], entry)
+		return !ok, err
+	case ldap.FilterEqualityMatch:
+		if len(filter.Children) != 2 {
+			return false, errors.New("malformed equality filter")
+		}
+		want := filter.Children[1].Data.String()
+		for _, value := range attributeValues(entry, filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, want) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ldap.FilterPresent:
+		return len(attributeValues(entry, filter.Data.String())) > 0, nil
+	default:
+		return false, fmt.Errorf("unsupported filter %q", ldap.FilterMap[uint64(filter.Tag)])
+	}
+}
+
+func attributeValues(entry Entry, name string) []string {
+	for attribute, values := range entry.Attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+	return nil
+}
+
+func dnEqual(a, b string) bool {
+	adn, err := ldap.ParseDN(a)
+	if err != nil {
+		return false
+	}
+	bdn, err := ldap.ParseDN(b)
+	if err != nil {
+		return false
+	}
+	return adn.EqualFold(bdn)
+}
+
+func searchEntry(entry Entry, attributes []string) *ber.Packet {
+	all := len(attributes) == 0
+	for _, attribute := range attributes {
+		if attribute == "*" {
+			all = true
+		}
+	}
+
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.Attributes {
+		requested := all
+		for _, attribute := range attributes {
+			if strings.EqualFold(attribute, name) {
+				requested = true
+			}
+		}
+		if !requested {
+			continue
+		}
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	packet.AppendChild(list)
+	return packet
+}
+
+func result(tag ber.Tag, code uint16, message string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, ldap.ApplicationMap[uint8(tag)])
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return packet
+}
+
+func write(conn net.Conn, id int64, response *ber.Packet) error {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	packet.AppendChild(response)
+	_, err := conn.Write(packet.Bytes())
+	return err
+}
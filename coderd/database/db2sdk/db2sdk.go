@@ -194,9 +194,10 @@ func ReducedUsers(users []database.User) []codersdk.ReducedUser {
 
 func User(user database.User, organizationIDs []uuid.UUID) codersdk.User {
 	convertedUser := codersdk.User{
-		ReducedUser:     ReducedUser(user),
-		OrganizationIDs: organizationIDs,
-		Roles:           SlimRolesFromNames(user.RBACRoles),
+		ReducedUser:      ReducedUser(user),
+		OrganizationIDs:  organizationIDs,
+		Roles:            SlimRolesFromNames(user.RBACRoles),
+		IsServiceAccount: user.IsServiceAccount,
 	}
 
 	return convertedUser
@@ -302,11 +303,17 @@ func templateVersionParameterOptions(rawOptions json.RawMessage) ([]codersdk.Tem
 }
 
 func OAuth2ProviderApp(accessURL *url.URL, dbApp database.OAuth2ProviderApp) codersdk.OAuth2ProviderApp {
+	var clientCredentialsUserID *uuid.UUID
+	if dbApp.ClientCredentialsUserID.Valid {
+		clientCredentialsUserID = &dbApp.ClientCredentialsUserID.UUID
+	}
 	return codersdk.OAuth2ProviderApp{
-		ID:          dbApp.ID,
-		Name:        dbApp.Name,
-		CallbackURL: dbApp.CallbackURL,
-		Icon:        dbApp.Icon,
+		ID:                      dbApp.ID,
+		Name:                    dbApp.Name,
+		CallbackURL:             dbApp.CallbackURL,
+		Icon:                    dbApp.Icon,
+		Public:                  dbApp.Public,
+		ClientCredentialsUserID: clientCredentialsUserID,
 		Endpoints: codersdk.OAuth2AppEndpoints{
 			Authorization: accessURL.ResolveReference(&url.URL{
 				Path: "/oauth2/authorize",
@@ -314,8 +321,9 @@ func OAuth2ProviderApp(accessURL *url.URL, dbApp database.OAuth2ProviderApp) cod
 			Token: accessURL.ResolveReference(&url.URL{
 				Path: "/oauth2/tokens",
 			}).String(),
-			// We do not currently support DeviceAuth.
-			DeviceAuth: "",
+			DeviceAuth: accessURL.ResolveReference(&url.URL{
+				Path: "/oauth2/device",
+			}).String(),
 		},
 	}
 }
@@ -498,10 +506,17 @@ func Apps(dbApps []database.WorkspaceApp, agent database.WorkspaceAgent, ownerNa
 
 	apps := make([]codersdk.WorkspaceApp, 0)
 	for _, dbApp := range dbApps {
+		var protocol codersdk.WorkspaceAppProtocol
+		if dbApp.Url.Valid && !dbApp.External {
+			if u, err := url.Parse(dbApp.Url.String); err == nil {
+				protocol = codersdk.WorkspaceAppProtocolFromURL(u)
+			}
+		}
 		apps = append(apps, codersdk.WorkspaceApp{
 			ID:            dbApp.ID,
 			URL:           dbApp.Url.String,
 			External:      dbApp.External,
+			Protocol:      protocol,
 			Slug:          dbApp.Slug,
 			DisplayName:   dbApp.DisplayName,
 			Command:       dbApp.Command.String,
@@ -520,6 +535,20 @@ func Apps(dbApps []database.WorkspaceApp, agent database.WorkspaceAgent, ownerNa
 	return apps
 }
 
+func ProvisionerTimings(dbTimings []database.ProvisionerJobTiming) []codersdk.ProvisionerTiming {
+	return List(dbTimings, func(t database.ProvisionerJobTiming) codersdk.ProvisionerTiming {
+		return codersdk.ProvisionerTiming{
+			JobID:     t.JobID,
+			StartedAt: t.StartedAt,
+			EndedAt:   t.EndedAt,
+			Stage:     codersdk.ProvisionerTimingStage(t.Stage),
+			Source:    t.Source,
+			Action:    t.Action,
+			Resource:  t.Resource,
+		}
+	})
+}
+
 func ProvisionerDaemon(dbDaemon database.ProvisionerDaemon) codersdk.ProvisionerDaemon {
 	result := codersdk.ProvisionerDaemon{
 		ID:             dbDaemon.ID,
@@ -571,6 +600,35 @@ func SlimRoleFromName(name string) codersdk.SlimRole {
 	return convertedRole
 }
 
+func RoleGrantRequest(req database.RoleGrantRequest) codersdk.RoleGrantRequest {
+	role := codersdk.SlimRole{Name: req.RoleName}
+	if rbacRole, err := rbac.RoleByName(req.RoleIdentifier()); err == nil {
+		role = SlimRole(rbacRole)
+	} else if req.OrganizationID.Valid {
+		role.OrganizationID = req.OrganizationID.UUID.String()
+	}
+
+	grant := codersdk.RoleGrantRequest{
+		ID:              req.ID,
+		UserID:          req.UserID,
+		Role:            role,
+		Reason:          req.Reason,
+		LifetimeSeconds: req.LifetimeSeconds,
+		Status:          codersdk.RoleGrantStatus(req.Status),
+		CreatedAt:       req.CreatedAt,
+	}
+	if req.ReviewedBy.Valid {
+		grant.ReviewedBy = &req.ReviewedBy.UUID
+	}
+	if req.ReviewedAt.Valid {
+		grant.ReviewedAt = &req.ReviewedAt.Time
+	}
+	if req.ExpiresAt.Valid {
+		grant.ExpiresAt = &req.ExpiresAt.Time
+	}
+	return grant
+}
+
 func RBACRole(role rbac.Role) codersdk.Role {
 	slim := SlimRole(role)
 
@@ -618,6 +676,7 @@ func RBACPermission(permission rbac.Permission) codersdk.Permission {
 }
 
 func Organization(organization database.Organization) codersdk.Organization {
+	proxyIDs, derpRegionIDs := WorkspaceProxyPins(organization.AllowedWorkspaceProxyIDs, organization.AllowedDERPRegionIDs)
 	return codersdk.Organization{
 		MinimalOrganization: codersdk.MinimalOrganization{
 			ID:          organization.ID,
@@ -625,9 +684,18 @@ func Organization(organization database.Organization) codersdk.Organization {
 			DisplayName: organization.DisplayName,
 			Icon:        organization.Icon,
 		},
-		Description: organization.Description,
-		CreatedAt:   organization.CreatedAt,
-		UpdatedAt:   organization.UpdatedAt,
-		IsDefault:   organization.IsDefault,
+		Description:              organization.Description,
+		CreatedAt:                organization.CreatedAt,
+		UpdatedAt:                organization.UpdatedAt,
+		IsDefault:                organization.IsDefault,
+		AllowedWorkspaceProxyIDs: proxyIDs,
+		AllowedDERPRegionIDs:     derpRegionIDs,
 	}
 }
+
+// WorkspaceProxyPins converts the workspace proxy and DERP region allow-lists
+// of a template or organization. The returned lists are never nil.
+func WorkspaceProxyPins(proxyIDs []uuid.UUID, derpRegionIDs []int32) ([]uuid.UUID, []int) {
+	return List(proxyIDs, func(id uuid.UUID) uuid.UUID { return id }),
+		List(derpRegionIDs, func(id int32) int { return int(id) })
+}
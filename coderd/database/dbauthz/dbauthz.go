@@ -217,6 +217,7 @@ var (
 				Identifier:  rbac.RoleIdentifier{Name: "hangdetector"},
 				DisplayName: "Hang Detector Daemon",
 				Site: rbac.Permissions(map[string][]policy.Action{
+					rbac.ResourceAuditLog.Type:  {policy.ActionCreate},
 					rbac.ResourceSystem.Type:    {policy.WildcardSymbol},
 					rbac.ResourceTemplate.Type:  {policy.ActionRead},
 					rbac.ResourceWorkspace.Type: {policy.ActionRead, policy.ActionUpdate},
@@ -228,6 +229,46 @@ var (
 		Scope: rbac.ScopeAll,
 	}.WithCachedASTValue()
 
+	// See driftdetector package.
+	subjectDriftDetector = rbac.Subject{
+		FriendlyName: "Drift Detector",
+		ID:           uuid.Nil.String(),
+		Roles: rbac.Roles([]rbac.Role{
+			{
+				Identifier:  rbac.RoleIdentifier{Name: "driftdetector"},
+				DisplayName: "Drift Detector Daemon",
+				Site: rbac.Permissions(map[string][]policy.Action{
+					rbac.ResourceSystem.Type:    {policy.WildcardSymbol},
+					rbac.ResourceTemplate.Type:  {policy.ActionRead},
+					rbac.ResourceWorkspace.Type: {policy.ActionRead, policy.ActionUpdate},
+				}),
+				Org:  map[string][]rbac.Permission{},
+				User: []rbac.Permission{},
+			},
+		}),
+		Scope: rbac.ScopeAll,
+	}.WithCachedASTValue()
+
+	// See canaryrollout package.
+	subjectCanaryEvaluator = rbac.Subject{
+		FriendlyName: "Canary Evaluator",
+		ID:           uuid.Nil.String(),
+		Roles: rbac.Roles([]rbac.Role{
+			{
+				Identifier:  rbac.RoleIdentifier{Name: "canaryevaluator"},
+				DisplayName: "Canary Evaluator Daemon",
+				Site: rbac.Permissions(map[string][]policy.Action{
+					rbac.ResourceAuditLog.Type: {policy.ActionCreate},
+					rbac.ResourceSystem.Type:   {policy.WildcardSymbol},
+					rbac.ResourceTemplate.Type: {policy.ActionRead, policy.ActionUpdate},
+				}),
+				Org:  map[string][]rbac.Permission{},
+				User: []rbac.Permission{},
+			},
+		}),
+		Scope: rbac.ScopeAll,
+	}.WithCachedASTValue()
+
 	subjectSystemRestricted = rbac.Subject{
 		FriendlyName: "System",
 		ID:           uuid.Nil.String(),
@@ -238,12 +279,12 @@ var (
 				Site: rbac.Permissions(map[string][]policy.Action{
 					rbac.ResourceWildcard.Type:           {policy.ActionRead},
 					rbac.ResourceApiKey.Type:             rbac.ResourceApiKey.AvailableActions(),
-					rbac.ResourceGroup.Type:              {policy.ActionCreate, policy.ActionUpdate},
+					rbac.ResourceGroup.Type:              {policy.ActionCreate, policy.ActionUpdate, policy.ActionDelete},
 					rbac.ResourceAssignRole.Type:         rbac.ResourceAssignRole.AvailableActions(),
 					rbac.ResourceAssignOrgRole.Type:      rbac.ResourceAssignOrgRole.AvailableActions(),
 					rbac.ResourceSystem.Type:             {policy.WildcardSymbol},
 					rbac.ResourceOrganization.Type:       {policy.ActionCreate, policy.ActionRead},
-					rbac.ResourceOrganizationMember.Type: {policy.ActionCreate},
+					rbac.ResourceOrganizationMember.Type: {policy.ActionCreate, policy.ActionDelete},
 					rbac.ResourceProvisionerDaemon.Type:  {policy.ActionCreate, policy.ActionUpdate},
 					rbac.ResourceProvisionerKeys.Type:    {policy.ActionCreate, policy.ActionRead, policy.ActionDelete},
 					rbac.ResourceUser.Type:               rbac.ResourceUser.AvailableActions(),
@@ -277,6 +318,18 @@ func AsHangDetector(ctx context.Context) context.Context {
 	return context.WithValue(ctx, authContextKey{}, subjectHangDetector)
 }
 
+// AsDriftDetector returns a context with an actor that has permissions required
+// for driftdetector.Detector to function.
+func AsDriftDetector(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authContextKey{}, subjectDriftDetector)
+}
+
+// AsCanaryEvaluator returns a context with an actor that has permissions
+// required for canaryrollout.Evaluator to function.
+func AsCanaryEvaluator(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authContextKey{}, subjectCanaryEvaluator)
+}
+
 // AsSystemRestricted returns a context with an actor that has permissions
 // required for various system operations (login, logout, metrics cache).
 func AsSystemRestricted(ctx context.Context) context.Context {
@@ -767,6 +820,36 @@ func authorizedTemplateVersionFromJob(ctx context.Context, q *querier, job datab
 	}
 }
 
+// authorizedWorkspaceFromPlanJob returns the workspace a plan-only build job
+// was created for. Plan jobs have no workspace build row, so the workspace is
+// read from the job input.
+func authorizedWorkspaceFromPlanJob(ctx context.Context, q *querier, job database.ProvisionerJob) (database.Workspace, error) {
+	if job.Type != database.ProvisionerJobTypeWorkspaceBuildPlan {
+		return database.Workspace{}, xerrors.Errorf("unknown job type: %q", job.Type)
+	}
+	// TODO: This is really unfortunate that we need to inspect the json
+	// payload. We should fix this.
+	tmp := struct {
+		WorkspaceID uuid.UUID `json:"workspace_id"`
+	}{}
+	err := json.Unmarshal(job.Input, &tmp)
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("plan unmarshal: %w", err)
+	}
+	// Authorized call to get workspace.
+	return q.GetWorkspaceByID(ctx, tmp.WorkspaceID)
+}
+
+// isCanaryVersion returns true if the workspace is in the cohort of an active
+// canary rollout of the given template version.
+func (q *querier) isCanaryVersion(ctx context.Context, workspaceID, templateVersionID uuid.UUID) bool {
+	canary, err := q.db.GetTemplateVersionCanaryByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return false
+	}
+	return canary.TemplateVersionID == templateVersionID
+}
+
 func (q *querier) authorizeTemplateInsights(ctx context.Context, templateIDs []uuid.UUID) error {
 	// Abort early if can read all template insights, aka admins.
 	// TODO: If we know the org, that would allow org admins to abort early too.
@@ -1052,6 +1135,13 @@ func (q *querier) DeleteCustomRole(ctx context.Context, arg database.DeleteCusto
 	return q.db.DeleteCustomRole(ctx, arg)
 }
 
+func (q *querier) DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, policy.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx)
+}
+
 func (q *querier) DeleteExternalAuthLink(ctx context.Context, arg database.DeleteExternalAuthLinkParams) error {
 	return fetchAndExec(q.log, q.auth, policy.ActionUpdatePersonal, func(ctx context.Context, arg database.DeleteExternalAuthLinkParams) (database.ExternalAuthLink, error) {
 		//nolint:gosimple
@@ -1112,6 +1202,17 @@ func (q *querier) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Context
 	return q.db.DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx, arg)
 }
 
+func (q *querier) DeleteOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) error {
+	code, err := q.db.GetOAuth2ProviderAppDeviceCodeByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionDelete, code); err != nil {
+		return err
+	}
+	return q.db.DeleteOAuth2ProviderAppDeviceCodeByID(ctx, id)
+}
+
 func (q *querier) DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error {
 	if err := q.authorizeContext(ctx, policy.ActionDelete, rbac.ResourceOauth2AppSecret); err != nil {
 		return err
@@ -1127,6 +1228,13 @@ func (q *querier) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Contex
 	return q.db.DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx, arg)
 }
 
+func (q *querier) DeleteOldDERPHealthHistory(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, policy.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldDERPHealthHistory(ctx)
+}
+
 func (q *querier) DeleteOldNotificationMessages(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, policy.ActionDelete, rbac.ResourceSystem); err != nil {
 		return err
@@ -1180,6 +1288,16 @@ func (q *querier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt tim
 	return q.db.DeleteReplicasUpdatedBefore(ctx, updatedAt)
 }
 
+func (q *querier) DeleteSessionAPIKeysByUserID(ctx context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) error {
+	// TODO: This is not 100% correct because it omits apikey IDs.
+	err := q.authorizeContext(ctx, policy.ActionDelete,
+		rbac.ResourceApiKey.WithOwner(arg.UserID.String()))
+	if err != nil {
+		return err
+	}
+	return q.db.DeleteSessionAPIKeysByUserID(ctx, arg)
+}
+
 func (q *querier) DeleteTailnetAgent(ctx context.Context, arg database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceTailnetCoordinator); err != nil {
 		return database.DeleteTailnetAgentRow{}, err
@@ -1215,6 +1333,19 @@ func (q *querier) DeleteTailnetTunnel(ctx context.Context, arg database.DeleteTa
 	return q.db.DeleteTailnetTunnel(ctx, arg)
 }
 
+func (q *querier) DeleteUserTOTPByUserID(ctx context.Context, userID uuid.UUID) error {
+	u, err := q.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	// Removing a second factor is an administrative reset, users cannot
+	// remove their own.
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, u); err != nil {
+		return err
+	}
+	return q.db.DeleteUserTOTPByUserID(ctx, userID)
+}
+
 func (q *querier) DeleteWorkspaceAgentPortShare(ctx context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	w, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -1283,6 +1414,29 @@ func (q *querier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Tim
 	return fetchWithPostFilter(q.auth, policy.ActionRead, q.db.GetAPIKeysLastUsedAfter)(ctx, lastUsed)
 }
 
+func (q *querier) GetActiveServiceAccountCount(ctx context.Context) (int64, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return 0, err
+	}
+	return q.db.GetActiveServiceAccountCount(ctx)
+}
+
+func (q *querier) GetActiveTemplateVersionCanaries(ctx context.Context) ([]database.TemplateVersionCanary, error) {
+	// This is a system-only function.
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetActiveTemplateVersionCanaries(ctx)
+}
+
+func (q *querier) GetActiveTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	// An actor can read the canary if they can read the related template.
+	if _, err := q.GetTemplateByID(ctx, templateID); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return q.db.GetActiveTemplateVersionCanaryByTemplateID(ctx, templateID)
+}
+
 func (q *querier) GetActiveUserCount(ctx context.Context) (int64, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
 		return 0, err
@@ -1380,6 +1534,13 @@ func (q *querier) GetDBCryptKeys(ctx context.Context) ([]database.DBCryptKey, er
 	return q.db.GetDBCryptKeys(ctx)
 }
 
+func (q *querier) GetDERPHealthHistory(ctx context.Context, arg database.GetDERPHealthHistoryParams) ([]database.DERPHealthHistory, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceDebugInfo); err != nil {
+		return nil, err
+	}
+	return q.db.GetDERPHealthHistory(ctx, arg)
+}
+
 func (q *querier) GetDERPMeshKey(ctx context.Context) (string, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
 		return "", err
@@ -1419,6 +1580,13 @@ func (q *querier) GetDeploymentWorkspaceStats(ctx context.Context) (database.Get
 	return q.db.GetDeploymentWorkspaceStats(ctx)
 }
 
+func (q *querier) GetExpiredRoleGrantRequests(ctx context.Context, now time.Time) ([]database.RoleGrantRequest, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetExpiredRoleGrantRequests(ctx, now)
+}
+
 func (q *querier) GetExternalAuthLink(ctx context.Context, arg database.GetExternalAuthLinkParams) (database.ExternalAuthLink, error) {
 	return fetchWithAction(q.log, q.auth, policy.ActionReadPersonal, q.db.GetExternalAuthLink)(ctx, arg)
 }
@@ -1539,6 +1707,14 @@ func (q *querier) GetLastUpdateCheck(ctx context.Context) (string, error) {
 	return q.db.GetLastUpdateCheck(ctx)
 }
 
+func (q *querier) GetLatestTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	// An actor can read the canary if they can read the related template.
+	if _, err := q.GetTemplateByID(ctx, templateID); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return q.db.GetLatestTemplateVersionCanaryByTemplateID(ctx, templateID)
+}
+
 func (q *querier) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceBuild, error) {
 	if _, err := q.GetWorkspaceByID(ctx, workspaceID); err != nil {
 		return database.WorkspaceBuild{}, err
@@ -1626,6 +1802,18 @@ func (q *querier) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPr
 	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppCodeByPrefix)(ctx, secretPrefix)
 }
 
+func (q *querier) GetOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppDeviceCode, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppDeviceCodeByID)(ctx, id)
+}
+
+func (q *querier) GetOAuth2ProviderAppDeviceCodeByPrefix(ctx context.Context, devicePrefix []byte) (database.OAuth2ProviderAppDeviceCode, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppDeviceCodeByPrefix)(ctx, devicePrefix)
+}
+
+func (q *querier) GetOAuth2ProviderAppDeviceCodeByUserCode(ctx context.Context, userCode string) (database.OAuth2ProviderAppDeviceCode, error) {
+	return fetch(q.log, q.auth, q.db.GetOAuth2ProviderAppDeviceCodeByUserCode)(ctx, userCode)
+}
+
 func (q *querier) GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceOauth2AppSecret); err != nil {
 		return database.OAuth2ProviderAppSecret{}, err
@@ -1768,6 +1956,12 @@ func (q *querier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (data
 		if err != nil {
 			return database.ProvisionerJob{}, err
 		}
+	case database.ProvisionerJobTypeWorkspaceBuildPlan:
+		// Authorized call to get the workspace the plan was made for.
+		_, err := authorizedWorkspaceFromPlanJob(ctx, q, job)
+		if err != nil {
+			return database.ProvisionerJob{}, err
+		}
 	case database.ProvisionerJobTypeTemplateVersionDryRun, database.ProvisionerJobTypeTemplateVersionImport:
 		// Authorized call to get template version.
 		_, err := authorizedTemplateVersionFromJob(ctx, q, job)
@@ -1781,6 +1975,24 @@ func (q *querier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (data
 	return job, nil
 }
 
+func (q *querier) GetProvisionerJobResourceChangesByJobID(ctx context.Context, jobID uuid.UUID) ([]database.ProvisionerJobResourceChange, error) {
+	// Authorized read on job lets the actor also read the planned changes.
+	_, err := q.GetProvisionerJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetProvisionerJobResourceChangesByJobID(ctx, jobID)
+}
+
+func (q *querier) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID uuid.UUID) ([]database.ProvisionerJobTiming, error) {
+	// Authorized read on job lets the actor also read the timings.
+	_, err := q.GetProvisionerJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetProvisionerJobTimingsByJobID(ctx, jobID)
+}
+
 // TODO: we need to add a provisioner job resource
 func (q *querier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	// if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
@@ -1853,6 +2065,37 @@ func (q *querier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Ti
 	return q.db.GetReplicasUpdatedAfter(ctx, updatedAt)
 }
 
+func (q *querier) GetRoleGrantRequestByID(ctx context.Context, id uuid.UUID) (database.RoleGrantRequest, error) {
+	req, err := q.db.GetRoleGrantRequestByID(ctx, id)
+	if err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+	// Requesters can always see their own requests, approvers can see any
+	// request for a role they are able to assign.
+	if err := q.authorizeContext(ctx, policy.ActionReadPersonal, rbac.ResourceUserObject(req.UserID)); err == nil {
+		return req, nil
+	}
+	if err := q.authorizeContext(ctx, policy.ActionAssign, req); err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+	return req, nil
+}
+
+func (q *querier) GetRoleGrantRequests(ctx context.Context, arg database.GetRoleGrantRequestsParams) ([]database.RoleGrantRequest, error) {
+	return fetchWithPostFilter(q.auth, policy.ActionAssign, q.db.GetRoleGrantRequests)(ctx, arg)
+}
+
+func (q *querier) GetRoleGrantRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]database.RoleGrantRequest, error) {
+	if err := q.authorizeContext(ctx, policy.ActionReadPersonal, rbac.ResourceUserObject(userID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetRoleGrantRequestsByUserID(ctx, userID)
+}
+
+func (q *querier) GetSessionAPIKeysByUserID(ctx context.Context, arg database.GetSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	return fetchWithPostFilter(q.auth, policy.ActionRead, q.db.GetSessionAPIKeysByUserID)(ctx, arg)
+}
+
 func (q *querier) GetTailnetAgents(ctx context.Context, id uuid.UUID) ([]database.TailnetAgent, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
 		return nil, err
@@ -2014,6 +2257,42 @@ func (q *querier) GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg
 	return tv, nil
 }
 
+func (q *querier) GetTemplateVersionCanaryByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionCanary, error) {
+	canary, err := q.db.GetTemplateVersionCanaryByID(ctx, id)
+	if err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	// An actor can read the canary if they can read the related template.
+	if _, err := q.GetTemplateByID(ctx, canary.TemplateID); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return canary, nil
+}
+
+func (q *querier) GetTemplateVersionCanaryByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.TemplateVersionCanary, error) {
+	// An actor can check the canary of a workspace if they can read the workspace.
+	if _, err := q.GetWorkspaceByID(ctx, workspaceID); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return q.db.GetTemplateVersionCanaryByWorkspaceID(ctx, workspaceID)
+}
+
+func (q *querier) GetTemplateVersionCanaryStats(ctx context.Context, arg database.GetTemplateVersionCanaryStatsParams) (database.GetTemplateVersionCanaryStatsRow, error) {
+	// An actor can read the stats if they can read the related template.
+	tv, err := q.db.GetTemplateVersionByID(ctx, arg.TemplateVersionID)
+	if err != nil {
+		return database.GetTemplateVersionCanaryStatsRow{}, err
+	}
+	if !tv.TemplateID.Valid {
+		if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceTemplate.InOrg(tv.OrganizationID)); err != nil {
+			return database.GetTemplateVersionCanaryStatsRow{}, err
+		}
+	} else if _, err := q.GetTemplateByID(ctx, tv.TemplateID.UUID); err != nil {
+		return database.GetTemplateVersionCanaryStatsRow{}, err
+	}
+	return q.db.GetTemplateVersionCanaryStats(ctx, arg)
+}
+
 func (q *querier) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionParameter, error) {
 	// An actor can read template version parameters if they can read the related template.
 	tv, err := q.db.GetTemplateVersionByID(ctx, templateVersionID)
@@ -2130,6 +2409,13 @@ func (q *querier) GetTemplatesWithFilter(ctx context.Context, arg database.GetTe
 	return q.db.GetAuthorizedTemplates(ctx, arg, prep)
 }
 
+func (q *querier) GetTimedOutProvisionerJobs(ctx context.Context, arg database.GetTimedOutProvisionerJobsParams) ([]database.ProvisionerJob, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTimedOutProvisionerJobs(ctx, arg)
+}
+
 func (q *querier) GetUnexpiredLicenses(ctx context.Context) ([]database.License, error) {
 	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
 		return nil, err
@@ -2224,6 +2510,17 @@ func (q *querier) GetUserNotificationPreferences(ctx context.Context, userID uui
 	return q.db.GetUserNotificationPreferences(ctx, userID)
 }
 
+func (q *querier) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	u, err := q.db.GetUserByID(ctx, userID)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionReadPersonal, u); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return q.db.GetUserTOTPByUserID(ctx, userID)
+}
+
 func (q *querier) GetUserWorkspaceBuildParameters(ctx context.Context, params database.GetUserWorkspaceBuildParametersParams) ([]database.GetUserWorkspaceBuildParametersRow, error) {
 	u, err := q.db.GetUserByID(ctx, params.OwnerID)
 	if err != nil {
@@ -2456,6 +2753,13 @@ func (q *querier) GetWorkspaceBuildParameters(ctx context.Context, workspaceBuil
 	return q.db.GetWorkspaceBuildParameters(ctx, workspaceBuildID)
 }
 
+func (q *querier) GetWorkspaceBuildProvisionerStates(ctx context.Context, arg database.GetWorkspaceBuildProvisionerStatesParams) ([]database.GetWorkspaceBuildProvisionerStatesRow, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceBuildProvisionerStates(ctx, arg)
+}
+
 func (q *querier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	if _, err := q.GetWorkspaceByID(ctx, arg.WorkspaceID); err != nil {
 		return nil, err
@@ -2489,6 +2793,15 @@ func (q *querier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceApp
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByWorkspaceAppID)(ctx, workspaceAppID)
 }
 
+func (q *querier) GetWorkspaceDriftCheckByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceDriftCheck, error) {
+	// Authorized call to get the workspace the check belongs to.
+	_, err := q.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		return database.WorkspaceDriftCheck{}, err
+	}
+	return q.db.GetWorkspaceDriftCheckByWorkspaceID(ctx, workspaceID)
+}
+
 func (q *querier) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	return fetchWithPostFilter(q.auth, policy.ActionRead, func(ctx context.Context, _ interface{}) ([]database.WorkspaceProxy, error) {
 		return q.db.GetWorkspaceProxies(ctx)
@@ -2618,6 +2931,13 @@ func (q *querier) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesP
 	return q.db.GetAuthorizedWorkspaces(ctx, arg, prep)
 }
 
+func (q *querier) GetWorkspacesDueForDriftCheck(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+	if err := q.authorizeContext(ctx, policy.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspacesDueForDriftCheck(ctx, now)
+}
+
 func (q *querier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
 	return q.db.GetWorkspacesEligibleForTransition(ctx, now)
 }
@@ -2672,6 +2992,13 @@ func (q *querier) InsertDBCryptKey(ctx context.Context, arg database.InsertDBCry
 	return q.db.InsertDBCryptKey(ctx, arg)
 }
 
+func (q *querier) InsertDERPHealthHistory(ctx context.Context, arg database.InsertDERPHealthHistoryParams) error {
+	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.InsertDERPHealthHistory(ctx, arg)
+}
+
 func (q *querier) InsertDERPMeshKey(ctx context.Context, value string) error {
 	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
 		return err
@@ -2738,6 +3065,15 @@ func (q *querier) InsertOAuth2ProviderAppCode(ctx context.Context, arg database.
 	return q.db.InsertOAuth2ProviderAppCode(ctx, arg)
 }
 
+func (q *querier) InsertOAuth2ProviderAppDeviceCode(ctx context.Context, arg database.InsertOAuth2ProviderAppDeviceCodeParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	// Device codes are requested by unauthenticated clients and do not have an
+	// owner until a user approves them.
+	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+	return q.db.InsertOAuth2ProviderAppDeviceCode(ctx, arg)
+}
+
 func (q *querier) InsertOAuth2ProviderAppSecret(ctx context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
 	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceOauth2AppSecret); err != nil {
 		return database.OAuth2ProviderAppSecret{}, err
@@ -2793,6 +3129,14 @@ func (q *querier) InsertProvisionerJobLogs(ctx context.Context, arg database.Ins
 	return q.db.InsertProvisionerJobLogs(ctx, arg)
 }
 
+// TODO: We need to create a ProvisionerJob resource type
+func (q *querier) InsertProvisionerJobResourceChanges(ctx context.Context, arg database.InsertProvisionerJobResourceChangesParams) ([]database.ProvisionerJobResourceChange, error) {
+	// if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
+	// return nil, err
+	// }
+	return q.db.InsertProvisionerJobResourceChanges(ctx, arg)
+}
+
 // TODO: We need to create a ProvisionerJob resource type
 func (q *querier) InsertProvisionerJobTimings(ctx context.Context, arg database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
 	// if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
@@ -2812,6 +3156,13 @@ func (q *querier) InsertReplica(ctx context.Context, arg database.InsertReplicaP
 	return q.db.InsertReplica(ctx, arg)
 }
 
+func (q *querier) InsertRoleGrantRequest(ctx context.Context, arg database.InsertRoleGrantRequestParams) (database.RoleGrantRequest, error) {
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, rbac.ResourceUserObject(arg.UserID)); err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+	return q.db.InsertRoleGrantRequest(ctx, arg)
+}
+
 func (q *querier) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) error {
 	obj := rbac.ResourceTemplate.InOrg(arg.OrganizationID)
 	if err := q.authorizeContext(ctx, policy.ActionCreate, obj); err != nil {
@@ -2843,6 +3194,18 @@ func (q *querier) InsertTemplateVersion(ctx context.Context, arg database.Insert
 	return q.db.InsertTemplateVersion(ctx, arg)
 }
 
+func (q *querier) InsertTemplateVersionCanary(ctx context.Context, arg database.InsertTemplateVersionCanaryParams) (database.TemplateVersionCanary, error) {
+	// Starting a canary is the same permission as promoting a version.
+	tpl, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, tpl); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return q.db.InsertTemplateVersionCanary(ctx, arg)
+}
+
 func (q *querier) InsertTemplateVersionParameter(ctx context.Context, arg database.InsertTemplateVersionParameterParams) (database.TemplateVersionParameter, error) {
 	if err := q.authorizeContext(ctx, policy.ActionCreate, rbac.ResourceSystem); err != nil {
 		return database.TemplateVersionParameter{}, err
@@ -2983,8 +3346,10 @@ func (q *querier) InsertWorkspaceBuild(ctx context.Context, arg database.InsertW
 		// If the template requires the active version we need to check if
 		// the user is a template admin. If they aren't and are attempting
 		// to use a non-active version then we must fail the request.
+		// Workspaces in the cohort of an active canary may use the canary
+		// version instead.
 		if accessControl.RequireActiveVersion {
-			if arg.TemplateVersionID != t.ActiveVersionID {
+			if arg.TemplateVersionID != t.ActiveVersionID && !q.isCanaryVersion(ctx, w.ID, arg.TemplateVersionID) {
 				if err = q.authorizeContext(ctx, policy.ActionUpdate, t); err != nil {
 					return xerrors.Errorf("cannot use non-active version: %w", err)
 				}
@@ -3083,6 +3448,17 @@ func (q *querier) RemoveUserFromAllGroups(ctx context.Context, userID uuid.UUID)
 	return q.db.RemoveUserFromAllGroups(ctx, userID)
 }
 
+func (q *querier) RemoveUserTOTPRecoveryCode(ctx context.Context, arg database.RemoveUserTOTPRecoveryCodeParams) (database.UserTOTP, error) {
+	u, err := q.db.GetUserByID(ctx, arg.UserID)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, u); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return q.db.RemoveUserTOTPRecoveryCode(ctx, arg)
+}
+
 func (q *querier) RevokeDBCryptKey(ctx context.Context, activeKeyDigest string) error {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return err
@@ -3227,6 +3603,23 @@ func (q *querier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg database.
 	return q.db.UpdateOAuth2ProviderAppByID(ctx, arg)
 }
 
+func (q *querier) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(ctx context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+	return q.db.UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(ctx, arg)
+}
+
+func (q *querier) UpdateOAuth2ProviderAppDeviceCodeStatus(ctx context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeStatusParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	// Acting on a device code is equivalent to creating an authorization code
+	// for the user, since an approved code can be exchanged for their token.
+	if err := q.authorizeContext(ctx, policy.ActionCreate,
+		rbac.ResourceOauth2AppCodeToken.WithOwner(arg.UserID.UUID.String())); err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+	return q.db.UpdateOAuth2ProviderAppDeviceCodeStatus(ctx, arg)
+}
+
 func (q *querier) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceOauth2AppSecret); err != nil {
 		return database.OAuth2ProviderAppSecret{}, err
@@ -3291,6 +3684,17 @@ func (q *querier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg da
 			}
 		}
 
+		err = q.authorizeContext(ctx, policy.ActionUpdate, workspace)
+		if err != nil {
+			return err
+		}
+	case database.ProvisionerJobTypeWorkspaceBuildPlan:
+		// Plans never apply anything, so canceling one is allowed for anyone
+		// who can update the workspace regardless of the template setting.
+		workspace, err := authorizedWorkspaceFromPlanJob(ctx, q, job)
+		if err != nil {
+			return err
+		}
 		err = q.authorizeContext(ctx, policy.ActionUpdate, workspace)
 		if err != nil {
 			return err
@@ -3338,6 +3742,31 @@ func (q *querier) UpdateReplica(ctx context.Context, arg database.UpdateReplicaP
 	return q.db.UpdateReplica(ctx, arg)
 }
 
+func (q *querier) UpdateRoleGrantRequestByID(ctx context.Context, arg database.UpdateRoleGrantRequestByIDParams) (database.RoleGrantRequest, error) {
+	req, err := q.db.GetRoleGrantRequestByID(ctx, arg.ID)
+	if err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+
+	var orgID *uuid.UUID
+	if req.OrganizationID.Valid {
+		orgID = &req.OrganizationID.UUID
+	}
+	// Reviewing a request requires being able to assign the role, ending a
+	// grant requires being able to remove it.
+	var added, removed []rbac.RoleIdentifier
+	switch arg.Status {
+	case database.RoleGrantStatusExpired, database.RoleGrantStatusRevoked:
+		removed = append(removed, req.RoleIdentifier())
+	default:
+		added = append(added, req.RoleIdentifier())
+	}
+	if err := q.canAssignRoles(ctx, orgID, added, removed); err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+	return q.db.UpdateRoleGrantRequestByID(ctx, arg)
+}
+
 func (q *querier) UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg database.UpdateTailnetPeerStatusByCoordinatorParams) error {
 	if err := q.authorizeContext(ctx, policy.ActionUpdate, rbac.ResourceTailnetCoordinator); err != nil {
 		return err
@@ -3409,6 +3838,22 @@ func (q *querier) UpdateTemplateVersionByID(ctx context.Context, arg database.Up
 	return q.db.UpdateTemplateVersionByID(ctx, arg)
 }
 
+func (q *querier) UpdateTemplateVersionCanaryStatusByID(ctx context.Context, arg database.UpdateTemplateVersionCanaryStatusByIDParams) (database.TemplateVersionCanary, error) {
+	// Ending a canary is the same permission as promoting a version.
+	canary, err := q.db.GetTemplateVersionCanaryByID(ctx, arg.ID)
+	if err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	tpl, err := q.db.GetTemplateByID(ctx, canary.TemplateID)
+	if err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdate, tpl); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	return q.db.UpdateTemplateVersionCanaryStatusByID(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg database.UpdateTemplateVersionDescriptionByJobIDParams) error {
 	// An actor is allowed to update the template version description if they are authorized to update the template.
 	tv, err := q.db.GetTemplateVersionByJobID(ctx, arg.JobID)
@@ -3601,6 +4046,39 @@ func (q *querier) UpdateUserStatus(ctx context.Context, arg database.UpdateUserS
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateUserStatus)(ctx, arg)
 }
 
+func (q *querier) UpdateUserTOTPEnabledAt(ctx context.Context, arg database.UpdateUserTOTPEnabledAtParams) error {
+	u, err := q.db.GetUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, u); err != nil {
+		return err
+	}
+	return q.db.UpdateUserTOTPEnabledAt(ctx, arg)
+}
+
+func (q *querier) UpdateUserTOTPLastUsedCounter(ctx context.Context, arg database.UpdateUserTOTPLastUsedCounterParams) (database.UserTOTP, error) {
+	u, err := q.db.GetUserByID(ctx, arg.UserID)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, u); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return q.db.UpdateUserTOTPLastUsedCounter(ctx, arg)
+}
+
+func (q *querier) UpdateUserTOTPSecret(ctx context.Context, arg database.UpdateUserTOTPSecretParams) error {
+	u, err := q.db.GetUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, u); err != nil {
+		return err
+	}
+	return q.db.UpdateUserTOTPSecret(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspace(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -3762,6 +4240,13 @@ func (q *querier) UpdateWorkspaceDormantDeletingAt(ctx context.Context, arg data
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWorkspaceDormantDeletingAt)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceDriftCheckResult(ctx context.Context, arg database.UpdateWorkspaceDriftCheckResultParams) error {
+	fetch := func(ctx context.Context, arg database.UpdateWorkspaceDriftCheckResultParams) (database.Workspace, error) {
+		return q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	}
+	return update(q.log, q.auth, fetch, q.db.UpdateWorkspaceDriftCheckResult)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -3952,6 +4437,17 @@ func (q *querier) UpsertTemplateUsageStats(ctx context.Context) error {
 	return q.db.UpsertTemplateUsageStats(ctx)
 }
 
+func (q *querier) UpsertUserTOTP(ctx context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	u, err := q.db.GetUserByID(ctx, arg.UserID)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := q.authorizeContext(ctx, policy.ActionUpdatePersonal, u); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return q.db.UpsertUserTOTP(ctx, arg)
+}
+
 func (q *querier) UpsertWorkspaceAgentPortShare(ctx context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -3966,6 +4462,20 @@ func (q *querier) UpsertWorkspaceAgentPortShare(ctx context.Context, arg databas
 	return q.db.UpsertWorkspaceAgentPortShare(ctx, arg)
 }
 
+func (q *querier) UpsertWorkspaceDriftCheck(ctx context.Context, arg database.UpsertWorkspaceDriftCheckParams) (database.WorkspaceDriftCheck, error) {
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return database.WorkspaceDriftCheck{}, err
+	}
+
+	err = q.authorizeContext(ctx, policy.ActionUpdate, workspace)
+	if err != nil {
+		return database.WorkspaceDriftCheck{}, err
+	}
+
+	return q.db.UpsertWorkspaceDriftCheck(ctx, arg)
+}
+
 func (q *querier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, _ rbac.PreparedAuthorized) ([]database.Template, error) {
 	// TODO Delete this function, all GetTemplates should be authorized. For now just call getTemplates on the authz querier.
 	return q.GetTemplatesWithFilter(ctx, arg)
@@ -209,6 +209,19 @@ func (s *MethodTestSuite) TestAPIKey() {
 			Asserts(keyA, policy.ActionRead, keyB, policy.ActionRead).
 			Returns(slice.New(keyA, keyB))
 	}))
+	s.Run("GetSessionAPIKeysByUserID", s.Subtest(func(db database.Store, check *expects) {
+		userID := uuid.New()
+		keyA, _ := dbgen.APIKey(s.T(), db, database.APIKey{UserID: userID, LoginType: database.LoginTypePassword})
+		keyB, _ := dbgen.APIKey(s.T(), db, database.APIKey{UserID: userID, LoginType: database.LoginTypeOIDC, LastUsed: dbtime.Now().Add(-time.Hour)})
+		_, _ = dbgen.APIKey(s.T(), db, database.APIKey{UserID: userID, LoginType: database.LoginTypeToken})
+
+		check.Args(database.GetSessionAPIKeysByUserIDParams{
+			UserID:     userID,
+			LoginTypes: database.SessionLoginTypes,
+		}).
+			Asserts(keyA, policy.ActionRead, keyB, policy.ActionRead).
+			Returns(slice.New(keyA, keyB))
+	}))
 	s.Run("GetAPIKeysLastUsedAfter", s.Subtest(func(db database.Store, check *expects) {
 		a, _ := dbgen.APIKey(s.T(), db, database.APIKey{LastUsed: time.Now().Add(time.Hour)})
 		b, _ := dbgen.APIKey(s.T(), db, database.APIKey{LastUsed: time.Now().Add(time.Hour)})
@@ -237,6 +250,13 @@ func (s *MethodTestSuite) TestAPIKey() {
 		})
 		check.Args(a.UserID).Asserts(rbac.ResourceApiKey.WithOwner(a.UserID.String()), policy.ActionDelete).Returns()
 	}))
+	s.Run("DeleteSessionAPIKeysByUserID", s.Subtest(func(db database.Store, check *expects) {
+		a, _ := dbgen.APIKey(s.T(), db, database.APIKey{})
+		check.Args(database.DeleteSessionAPIKeysByUserIDParams{
+			UserID:     a.UserID,
+			LoginTypes: database.SessionLoginTypes,
+		}).Asserts(rbac.ResourceApiKey.WithOwner(a.UserID.String()), policy.ActionDelete).Returns()
+	}))
 	s.Run("DeleteExternalAuthLink", s.Subtest(func(db database.Store, check *expects) {
 		a := dbgen.ExternalAuthLink(s.T(), db, database.ExternalAuthLink{})
 		check.Args(database.DeleteExternalAuthLinkParams{
@@ -528,6 +548,18 @@ func (s *MethodTestSuite) TestProvisionerJob() {
 		check.Args(database.UpdateProvisionerJobWithCancelByIDParams{ID: j.ID}).
 			Asserts(v.RBACObject(tpl), []policy.Action{policy.ActionRead, policy.ActionUpdate}).Returns()
 	}))
+	s.Run("Plan/UpdateProvisionerJobWithCancelByID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{AllowUserCancelWorkspaceJobs: false})
+		w := dbgen.Workspace(s.T(), db, database.Workspace{TemplateID: tpl.ID})
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuildPlan,
+			Input: must(json.Marshal(struct {
+				WorkspaceID uuid.UUID `json:"workspace_id"`
+			}{WorkspaceID: w.ID})),
+		})
+		check.Args(database.UpdateProvisionerJobWithCancelByIDParams{ID: j.ID}).
+			Asserts(w, []policy.Action{policy.ActionRead, policy.ActionUpdate}).Returns()
+	}))
 	s.Run("GetProvisionerJobsByIDs", s.Subtest(func(db database.Store, check *expects) {
 		a := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
 		b := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
@@ -543,6 +575,24 @@ func (s *MethodTestSuite) TestProvisionerJob() {
 			JobID: j.ID,
 		}).Asserts(w, policy.ActionRead).Returns([]database.ProvisionerJobLog{})
 	}))
+	s.Run("GetProvisionerJobResourceChangesByJobID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Workspace(s.T(), db, database.Workspace{})
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuildPlan,
+			Input: must(json.Marshal(struct {
+				WorkspaceID uuid.UUID `json:"workspace_id"`
+			}{WorkspaceID: w.ID})),
+		})
+		check.Args(j.ID).Asserts(w, policy.ActionRead).Returns([]database.ProvisionerJobResourceChange{})
+	}))
+	s.Run("GetProvisionerJobTimingsByJobID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Workspace(s.T(), db, database.Workspace{})
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{JobID: j.ID, WorkspaceID: w.ID})
+		check.Args(j.ID).Asserts(w, policy.ActionRead).Returns([]database.ProvisionerJobTiming{})
+	}))
 }
 
 func (s *MethodTestSuite) TestLicense() {
@@ -968,6 +1018,108 @@ func (s *MethodTestSuite) TestTemplate() {
 			TemplateID: t1.ID,
 		}).Asserts(t1, policy.ActionUpdate)
 	}))
+	s.Run("GetActiveTemplateVersionCanaryByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		c := dbgen.TemplateVersionCanary(s.T(), db, database.TemplateVersionCanary{
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			CreatedBy:         u.ID,
+		})
+		check.Args(t1.ID).Asserts(t1, policy.ActionRead).Returns(c)
+	}))
+	s.Run("GetLatestTemplateVersionCanaryByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		c := dbgen.TemplateVersionCanary(s.T(), db, database.TemplateVersionCanary{
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			CreatedBy:         u.ID,
+		})
+		check.Args(t1.ID).Asserts(t1, policy.ActionRead).Returns(c)
+	}))
+	s.Run("GetTemplateVersionCanaryByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		c := dbgen.TemplateVersionCanary(s.T(), db, database.TemplateVersionCanary{
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			CreatedBy:         u.ID,
+		})
+		check.Args(c.ID).Asserts(t1, policy.ActionRead).Returns(c)
+	}))
+	s.Run("GetTemplateVersionCanaryByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		t1 := dbgen.Template(s.T(), db, database.Template{OrganizationID: o.ID, CreatedBy: u.ID})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID:     uuid.NullUUID{UUID: t1.ID, Valid: true},
+			OrganizationID: o.ID,
+			CreatedBy:      u.ID,
+		})
+		c := dbgen.TemplateVersionCanary(s.T(), db, database.TemplateVersionCanary{
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			Percent:           100,
+			CreatedBy:         u.ID,
+		})
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{
+			OwnerID:        u.ID,
+			OrganizationID: o.ID,
+			TemplateID:     t1.ID,
+		})
+		check.Args(ws.ID).Asserts(ws, policy.ActionRead).Returns(c)
+	}))
+	s.Run("GetTemplateVersionCanaryStats", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.GetTemplateVersionCanaryStatsParams{
+			TemplateVersionID: tv.ID,
+		}).Asserts(t1, policy.ActionRead).Returns(database.GetTemplateVersionCanaryStatsRow{})
+	}))
+	s.Run("InsertTemplateVersionCanary", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.InsertTemplateVersionCanaryParams{
+			ID:                uuid.New(),
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			Percent:           10,
+			MinBuilds:         1,
+			SuccessThreshold:  90,
+			CreatedBy:         u.ID,
+		}).Asserts(t1, policy.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateVersionCanaryStatusByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		c := dbgen.TemplateVersionCanary(s.T(), db, database.TemplateVersionCanary{
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			CreatedBy:         u.ID,
+		})
+		check.Args(database.UpdateTemplateVersionCanaryStatusByIDParams{
+			ID:     c.ID,
+			Status: database.TemplateVersionCanaryStatusRolledBack,
+		}).Asserts(t1, policy.ActionUpdate)
+	}))
 	s.Run("UpdateTemplateActiveVersionByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{
 			ActiveVersionID: uuid.New(),
@@ -1155,6 +1307,57 @@ func (s *MethodTestSuite) TestUser() {
 			LastSeenAt: u.LastSeenAt,
 		}).Asserts(u, policy.ActionUpdate).Returns(u)
 	}))
+	s.Run("GetUserTOTPByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		totp := dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(u.ID).Asserts(u, policy.ActionReadPersonal).Returns(totp)
+	}))
+	s.Run("UpsertUserTOTP", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.UpsertUserTOTPParams{
+			UserID:              u.ID,
+			Secret:              "secret",
+			HashedRecoveryCodes: []string{},
+		}).Asserts(u, policy.ActionUpdatePersonal)
+	}))
+	s.Run("UpdateUserTOTPEnabledAt", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(database.UpdateUserTOTPEnabledAtParams{
+			UserID: u.ID,
+		}).Asserts(u, policy.ActionUpdatePersonal).Returns()
+	}))
+	s.Run("RemoveUserTOTPRecoveryCode", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		totp := dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID, HashedRecoveryCodes: []string{"a", "b"}})
+		totp.HashedRecoveryCodes = []string{"b"}
+		check.Args(database.RemoveUserTOTPRecoveryCodeParams{
+			UserID:             u.ID,
+			HashedRecoveryCode: "a",
+		}).Asserts(u, policy.ActionUpdatePersonal).Returns(totp)
+	}))
+	s.Run("UpdateUserTOTPLastUsedCounter", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		totp := dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		totp.LastUsedCounter = 1
+		check.Args(database.UpdateUserTOTPLastUsedCounterParams{
+			UserID:          u.ID,
+			LastUsedCounter: 1,
+		}).Asserts(u, policy.ActionUpdatePersonal).Returns(totp)
+	}))
+	s.Run("UpdateUserTOTPSecret", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(database.UpdateUserTOTPSecretParams{
+			UserID: u.ID,
+			Secret: "secret",
+		}).Asserts(u, policy.ActionUpdatePersonal).Returns()
+	}))
+	s.Run("DeleteUserTOTPByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_ = dbgen.UserTOTP(s.T(), db, database.UserTOTP{UserID: u.ID})
+		check.Args(u.ID).Asserts(u, policy.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateUserProfile", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(database.UpdateUserProfileParams{
@@ -1871,6 +2074,52 @@ func (s *MethodTestSuite) TestWorkspace() {
 	}))
 }
 
+func (s *MethodTestSuite) TestWorkspaceDriftChecks() {
+	s.Run("GetWorkspaceDriftCheckByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		job := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{Type: database.ProvisionerJobTypeWorkspaceBuildPlan})
+		dc, err := db.UpsertWorkspaceDriftCheck(context.Background(), database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceID: ws.ID,
+			JobID:       job.ID,
+			ScheduledAt: dbtime.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(ws.ID).Asserts(ws, policy.ActionRead).Returns(dc)
+	}))
+	s.Run("UpsertWorkspaceDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		job := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{Type: database.ProvisionerJobTypeWorkspaceBuildPlan})
+		now := dbtime.Now()
+		check.Args(database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceID: ws.ID,
+			JobID:       job.ID,
+			ScheduledAt: now,
+		}).Asserts(ws, policy.ActionUpdate).Returns(database.WorkspaceDriftCheck{
+			WorkspaceID: ws.ID,
+			JobID:       job.ID,
+			ScheduledAt: now,
+		})
+	}))
+	s.Run("UpdateWorkspaceDriftCheckResult", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		job := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{Type: database.ProvisionerJobTypeWorkspaceBuildPlan})
+		_, err := db.UpsertWorkspaceDriftCheck(context.Background(), database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceID: ws.ID,
+			JobID:       job.ID,
+			ScheduledAt: dbtime.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.UpdateWorkspaceDriftCheckResultParams{
+			WorkspaceID: ws.ID,
+			ResultJobID: uuid.NullUUID{UUID: job.ID, Valid: true},
+			CheckedAt:   sql.NullTime{Time: dbtime.Now(), Valid: true},
+		}).Asserts(ws, policy.ActionUpdate).Returns()
+	}))
+	s.Run("GetWorkspacesDueForDriftCheck", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Time{}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+}
+
 func (s *MethodTestSuite) TestWorkspacePortSharing() {
 	s.Run("UpsertWorkspaceAgentPortShare", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
@@ -1984,6 +2233,66 @@ func (s *MethodTestSuite) TestProvisionerKeys() {
 	}))
 }
 
+func (s *MethodTestSuite) TestRoleGrantRequests() {
+	s.Run("InsertRoleGrantRequest", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertRoleGrantRequestParams{
+			ID:              uuid.New(),
+			UserID:          u.ID,
+			RoleName:        codersdk.RoleTemplateAdmin,
+			Reason:          "deploying a template",
+			LifetimeSeconds: 3600,
+		}).Asserts(rbac.ResourceUserObject(u.ID), policy.ActionUpdatePersonal)
+	}))
+	s.Run("GetRoleGrantRequestByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		req := dbgen.RoleGrantRequest(s.T(), db, database.RoleGrantRequest{UserID: u.ID})
+		check.Args(req.ID).Asserts(rbac.ResourceUserObject(u.ID), policy.ActionReadPersonal).Returns(req)
+	}))
+	s.Run("GetRoleGrantRequestsByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		req := dbgen.RoleGrantRequest(s.T(), db, database.RoleGrantRequest{UserID: u.ID})
+		check.Args(u.ID).Asserts(rbac.ResourceUserObject(u.ID), policy.ActionReadPersonal).Returns([]database.RoleGrantRequest{req})
+	}))
+	s.Run("GetRoleGrantRequests", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		u := dbgen.User(s.T(), db, database.User{})
+		req := dbgen.RoleGrantRequest(s.T(), db, database.RoleGrantRequest{
+			UserID:         u.ID,
+			OrganizationID: uuid.NullUUID{UUID: o.ID, Valid: true},
+		})
+		check.Args(database.GetRoleGrantRequestsParams{OrganizationID: o.ID}).
+			Asserts(req, policy.ActionAssign).
+			Returns([]database.RoleGrantRequest{req})
+	}))
+	s.Run("GetExpiredRoleGrantRequests", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(dbtime.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("Approve/UpdateRoleGrantRequestByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		req := dbgen.RoleGrantRequest(s.T(), db, database.RoleGrantRequest{UserID: u.ID})
+		check.Args(database.UpdateRoleGrantRequestByIDParams{
+			ID:         req.ID,
+			Status:     database.RoleGrantStatusApproved,
+			FromStatus: database.RoleGrantStatusPending,
+		}).Asserts(rbac.ResourceAssignRole, policy.ActionAssign)
+	}))
+	s.Run("Revoke/UpdateRoleGrantRequestByID", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		u := dbgen.User(s.T(), db, database.User{})
+		req := dbgen.RoleGrantRequest(s.T(), db, database.RoleGrantRequest{
+			UserID:         u.ID,
+			OrganizationID: uuid.NullUUID{UUID: o.ID, Valid: true},
+			Status:         database.RoleGrantStatusApproved,
+		})
+		check.Args(database.UpdateRoleGrantRequestByIDParams{
+			ID:         req.ID,
+			Status:     database.RoleGrantStatusRevoked,
+			FromStatus: database.RoleGrantStatusApproved,
+		}).Asserts(rbac.ResourceAssignOrgRole.InOrg(o.ID), policy.ActionDelete)
+	}))
+}
+
 func (s *MethodTestSuite) TestExtraMethods() {
 	s.Run("GetProvisionerDaemons", s.Subtest(func(db database.Store, check *expects) {
 		d, err := db.UpsertProvisionerDaemon(context.Background(), database.UpsertProvisionerDaemonParams{
@@ -2231,6 +2540,9 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetActiveUserCount", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead).Returns(int64(0))
 	}))
+	s.Run("GetActiveServiceAccountCount", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead).Returns(int64(0))
+	}))
 	s.Run("GetUnexpiredLicenses", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
@@ -2238,6 +2550,15 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
+	s.Run("GetDERPHealthHistory", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetDERPHealthHistoryParams{}).Asserts(rbac.ResourceDebugInfo, policy.ActionRead)
+	}))
+	s.Run("InsertDERPHealthHistory", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertDERPHealthHistoryParams{}).Asserts(rbac.ResourceSystem, policy.ActionCreate).Returns()
+	}))
+	s.Run("DeleteOldDERPHealthHistory", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionDelete).Returns()
+	}))
 	s.Run("GetDERPMeshKey", s.Subtest(func(db database.Store, check *expects) {
 		db.InsertDERPMeshKey(context.Background(), "testing")
 		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead)
@@ -2307,6 +2628,10 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
 	}))
+	s.Run("GetWorkspaceBuildProvisionerStates", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{ProvisionerState: []byte("state")})
+		check.Args(database.GetWorkspaceBuildProvisionerStatesParams{LimitOpt: 10}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
 	s.Run("GetWorkspaceAgentsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, policy.ActionRead)
@@ -2467,6 +2792,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		}).Asserts( /*rbac.ResourceSystem, policy.ActionCreate*/ )
 	}))
 	s.Run("InsertProvisionerJobLogs", s.Subtest(func(db database.Store, check *expects) {
@@ -2476,6 +2802,13 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			JobID: j.ID,
 		}).Asserts( /*rbac.ResourceSystem, policy.ActionCreate*/ )
 	}))
+	s.Run("InsertProvisionerJobResourceChanges", s.Subtest(func(db database.Store, check *expects) {
+		// TODO: we need to create a ProvisionerJob resource
+		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
+		check.Args(database.InsertProvisionerJobResourceChangesParams{
+			JobID: j.ID,
+		}).Asserts( /*rbac.ResourceSystem, policy.ActionCreate*/ )
+	}))
 	s.Run("InsertProvisionerJobTimings", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: we need to create a ProvisionerJob resource
 		j := dbgen.ProvisionerJob(s.T(), db, nil, database.ProvisionerJob{})
@@ -2580,6 +2913,12 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("GetHungProvisionerJobs", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(time.Time{}).Asserts()
 	}))
+	s.Run("GetActiveTemplateVersionCanaries", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
+	s.Run("GetTimedOutProvisionerJobs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetTimedOutProvisionerJobsParams{Now: time.Now()}).Asserts(rbac.ResourceSystem, policy.ActionRead)
+	}))
 	s.Run("UpsertOAuthSigningKey", s.Subtest(func(db database.Store, check *expects) {
 		check.Args("foo").Asserts(rbac.ResourceSystem, policy.ActionUpdate)
 	}))
@@ -2794,8 +3133,9 @@ func (s *MethodTestSuite) TestOAuth2ProviderApps() {
 		})
 		for i := 0; i < 5; i++ {
 			_ = dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
-				AppSecretID: secret.ID,
+				AppSecretID: uuid.NullUUID{UUID: secret.ID, Valid: true},
 				APIKeyID:    key.ID,
+				AppID:       app.ID,
 			})
 		}
 		check.Args(user.ID).Asserts(rbac.ResourceOauth2AppCodeToken.WithOwner(user.ID.String()), policy.ActionRead).Returns([]database.GetOAuth2ProviderAppsByUserIDRow{
@@ -2940,6 +3280,74 @@ func (s *MethodTestSuite) TestOAuth2ProviderAppCodes() {
 	}))
 }
 
+func (s *MethodTestSuite) TestOAuth2ProviderAppDeviceCodes() {
+	s.Run("GetOAuth2ProviderAppDeviceCodeByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		check.Args(code.ID).Asserts(code, policy.ActionRead).Returns(code)
+	}))
+	s.Run("GetOAuth2ProviderAppDeviceCodeByPrefix", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		check.Args(code.DevicePrefix).Asserts(code, policy.ActionRead).Returns(code)
+	}))
+	s.Run("GetOAuth2ProviderAppDeviceCodeByUserCode", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		check.Args(code.UserCode).Asserts(code, policy.ActionRead).Returns(code)
+	}))
+	s.Run("InsertOAuth2ProviderAppDeviceCode", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		check.Args(database.InsertOAuth2ProviderAppDeviceCodeParams{
+			AppID:    uuid.NullUUID{UUID: app.ID, Valid: true},
+			UserCode: "BCDFGHJK",
+		}).Asserts(rbac.ResourceSystem, policy.ActionCreate)
+	}))
+	s.Run("UpdateOAuth2ProviderAppDeviceCodeStatus", s.Subtest(func(db database.Store, check *expects) {
+		user := dbgen.User(s.T(), db, database.User{})
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		code.Status = database.OAuth2ProviderDeviceCodeStatusApproved
+		code.UserID = uuid.NullUUID{UUID: user.ID, Valid: true}
+		check.Args(database.UpdateOAuth2ProviderAppDeviceCodeStatusParams{
+			ID:     code.ID,
+			Status: code.Status,
+			UserID: code.UserID,
+		}).Asserts(rbac.ResourceOauth2AppCodeToken.WithOwner(user.ID.String()), policy.ActionCreate).Returns(code)
+	}))
+	s.Run("DeleteOAuth2ProviderAppDeviceCodeByID", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		check.Args(code.ID).Asserts(code, policy.ActionDelete)
+	}))
+	s.Run("UpdateOAuth2ProviderAppDeviceCodeLastPolledAt", s.Subtest(func(db database.Store, check *expects) {
+		app := dbgen.OAuth2ProviderApp(s.T(), db, database.OAuth2ProviderApp{})
+		code := dbgen.OAuth2ProviderAppDeviceCode(s.T(), db, database.OAuth2ProviderAppDeviceCode{
+			AppID: uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		now := dbtime.Now()
+		code.LastPolledAt = sql.NullTime{Time: now, Valid: true}
+		check.Args(database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams{
+			ID:           code.ID,
+			LastPolledAt: code.LastPolledAt,
+			PolledBefore: now,
+		}).Asserts(rbac.ResourceSystem, policy.ActionUpdate).Returns(code)
+	}))
+	s.Run("DeleteExpiredOAuth2ProviderAppDeviceCodes", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, policy.ActionDelete)
+	}))
+}
+
 func (s *MethodTestSuite) TestOAuth2ProviderAppTokens() {
 	s.Run("InsertOAuth2ProviderAppToken", s.Subtest(func(db database.Store, check *expects) {
 		user := dbgen.User(s.T(), db, database.User{})
@@ -2951,8 +3359,9 @@ func (s *MethodTestSuite) TestOAuth2ProviderAppTokens() {
 			AppID: app.ID,
 		})
 		check.Args(database.InsertOAuth2ProviderAppTokenParams{
-			AppSecretID: secret.ID,
+			AppSecretID: uuid.NullUUID{UUID: secret.ID, Valid: true},
 			APIKeyID:    key.ID,
+			AppID:       app.ID,
 		}).Asserts(rbac.ResourceOauth2AppCodeToken.WithOwner(user.ID.String()), policy.ActionCreate)
 	}))
 	s.Run("GetOAuth2ProviderAppTokenByPrefix", s.Subtest(func(db database.Store, check *expects) {
@@ -2965,8 +3374,9 @@ func (s *MethodTestSuite) TestOAuth2ProviderAppTokens() {
 			AppID: app.ID,
 		})
 		token := dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
-			AppSecretID: secret.ID,
+			AppSecretID: uuid.NullUUID{UUID: secret.ID, Valid: true},
 			APIKeyID:    key.ID,
+			AppID:       app.ID,
 		})
 		check.Args(token.HashPrefix).Asserts(rbac.ResourceOauth2AppCodeToken.WithOwner(user.ID.String()), policy.ActionRead)
 	}))
@@ -2981,8 +3391,9 @@ func (s *MethodTestSuite) TestOAuth2ProviderAppTokens() {
 		})
 		for i := 0; i < 5; i++ {
 			_ = dbgen.OAuth2ProviderAppToken(s.T(), db, database.OAuth2ProviderAppToken{
-				AppSecretID: secret.ID,
+				AppSecretID: uuid.NullUUID{UUID: secret.ID, Valid: true},
 				APIKeyID:    key.ID,
+				AppID:       app.ID,
 			})
 		}
 		check.Args(database.DeleteOAuth2ProviderAppTokensByAppAndUserIDParams{
@@ -177,6 +177,7 @@ func (b WorkspaceBuildBuilder) Do() WorkspaceResponse {
 		Input:          payload,
 		Tags:           map[string]string{},
 		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       database.ProvisionerJobPriorityInteractive,
 	})
 	require.NoError(b.t, err, "insert job")
 
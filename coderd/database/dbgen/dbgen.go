@@ -133,6 +133,9 @@ func APIKey(t testing.TB, db database.Store, seed database.APIKey) (key database
 		LoginType:       takeFirst(seed.LoginType, database.LoginTypePassword),
 		Scope:           takeFirst(seed.Scope, database.APIKeyScopeAll),
 		TokenName:       takeFirst(seed.TokenName),
+		Scopes:          seed.Scopes,
+		AllowList:       seed.AllowList,
+		UserAgent:       takeFirst(seed.UserAgent),
 	})
 	require.NoError(t, err, "insert api key")
 	return key, fmt.Sprintf("%s-%s", key.ID, secret)
@@ -226,19 +229,20 @@ func WorkspaceBuild(t testing.TB, db database.Store, orig database.WorkspaceBuil
 	var build database.WorkspaceBuild
 	err := db.InTx(func(db database.Store) error {
 		err := db.InsertWorkspaceBuild(genCtx, database.InsertWorkspaceBuildParams{
-			ID:                buildID,
-			CreatedAt:         takeFirst(orig.CreatedAt, dbtime.Now()),
-			UpdatedAt:         takeFirst(orig.UpdatedAt, dbtime.Now()),
-			WorkspaceID:       takeFirst(orig.WorkspaceID, uuid.New()),
-			TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
-			BuildNumber:       takeFirst(orig.BuildNumber, 1),
-			Transition:        takeFirst(orig.Transition, database.WorkspaceTransitionStart),
-			InitiatorID:       takeFirst(orig.InitiatorID, uuid.New()),
-			JobID:             takeFirst(orig.JobID, uuid.New()),
-			ProvisionerState:  takeFirstSlice(orig.ProvisionerState, []byte{}),
-			Deadline:          takeFirst(orig.Deadline, dbtime.Now().Add(time.Hour)),
-			MaxDeadline:       takeFirst(orig.MaxDeadline, time.Time{}),
-			Reason:            takeFirst(orig.Reason, database.BuildReasonInitiator),
+			ID:                    buildID,
+			CreatedAt:             takeFirst(orig.CreatedAt, dbtime.Now()),
+			UpdatedAt:             takeFirst(orig.UpdatedAt, dbtime.Now()),
+			WorkspaceID:           takeFirst(orig.WorkspaceID, uuid.New()),
+			TemplateVersionID:     takeFirst(orig.TemplateVersionID, uuid.New()),
+			BuildNumber:           takeFirst(orig.BuildNumber, 1),
+			Transition:            takeFirst(orig.Transition, database.WorkspaceTransitionStart),
+			InitiatorID:           takeFirst(orig.InitiatorID, uuid.New()),
+			JobID:                 takeFirst(orig.JobID, uuid.New()),
+			ProvisionerState:      takeFirstSlice(orig.ProvisionerState, []byte{}),
+			Deadline:              takeFirst(orig.Deadline, dbtime.Now().Add(time.Hour)),
+			MaxDeadline:           takeFirst(orig.MaxDeadline, time.Time{}),
+			Reason:                takeFirst(orig.Reason, database.BuildReasonInitiator),
+			ProvisionerStateKeyID: takeFirst(orig.ProvisionerStateKeyID, sql.NullString{}),
 		})
 		if err != nil {
 			return err
@@ -466,6 +470,7 @@ func ProvisionerJob(t testing.TB, db database.Store, ps pubsub.Pubsub, orig data
 		Input:          takeFirstSlice(orig.Input, []byte("{}")),
 		Tags:           orig.Tags,
 		TraceMetadata:  pqtype.NullRawMessage{},
+		Priority:       takeFirst(orig.Priority, database.ProvisionerJobPriorityInteractive),
 	})
 	require.NoError(t, err, "insert job")
 	if ps != nil {
@@ -675,6 +680,27 @@ func UserLink(t testing.TB, db database.Store, orig database.UserLink) database.
 	return link
 }
 
+func UserTOTP(t testing.TB, db database.Store, orig database.UserTOTP) database.UserTOTP {
+	totp, err := db.UpsertUserTOTP(genCtx, database.UpsertUserTOTPParams{
+		UserID:              takeFirst(orig.UserID, uuid.New()),
+		Secret:              takeFirst(orig.Secret, "JBSWY3DPEHPK3PXP"),
+		SecretKeyID:         takeFirst(orig.SecretKeyID, sql.NullString{}),
+		HashedRecoveryCodes: takeFirstSlice(orig.HashedRecoveryCodes, []string{}),
+		CreatedAt:           takeFirst(orig.CreatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "insert user totp")
+
+	if orig.EnabledAt.Valid {
+		err = db.UpdateUserTOTPEnabledAt(genCtx, database.UpdateUserTOTPEnabledAtParams{
+			UserID:    totp.UserID,
+			EnabledAt: orig.EnabledAt,
+		})
+		require.NoError(t, err, "enable user totp")
+		totp.EnabledAt = orig.EnabledAt
+	}
+	return totp
+}
+
 func ExternalAuthLink(t testing.TB, db database.Store, orig database.ExternalAuthLink) database.ExternalAuthLink {
 	msg := takeFirst(&orig.OAuthExtra, &pqtype.NullRawMessage{})
 	link, err := db.InsertExternalAuthLink(genCtx, database.InsertExternalAuthLinkParams{
@@ -725,6 +751,23 @@ func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVers
 	return version
 }
 
+func TemplateVersionCanary(t testing.TB, db database.Store, orig database.TemplateVersionCanary) database.TemplateVersionCanary {
+	canary, err := db.InsertTemplateVersionCanary(genCtx, database.InsertTemplateVersionCanaryParams{
+		ID:                takeFirst(orig.ID, uuid.New()),
+		TemplateID:        takeFirst(orig.TemplateID, uuid.New()),
+		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
+		Percent:           takeFirst(orig.Percent, 10),
+		GroupID:           orig.GroupID,
+		MinBuilds:         takeFirst(orig.MinBuilds, 5),
+		SuccessThreshold:  takeFirst(orig.SuccessThreshold, 90),
+		CreatedBy:         takeFirst(orig.CreatedBy, uuid.New()),
+		CreatedAt:         takeFirst(orig.CreatedAt, dbtime.Now()),
+		UpdatedAt:         takeFirst(orig.UpdatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "insert template version canary")
+	return canary
+}
+
 func TemplateVersionVariable(t testing.TB, db database.Store, orig database.TemplateVersionVariable) database.TemplateVersionVariable {
 	version, err := db.InsertTemplateVersionVariable(genCtx, database.InsertTemplateVersionVariableParams{
 		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
@@ -827,12 +870,14 @@ func WorkspaceAgentStat(t testing.TB, db database.Store, orig database.Workspace
 
 func OAuth2ProviderApp(t testing.TB, db database.Store, seed database.OAuth2ProviderApp) database.OAuth2ProviderApp {
 	app, err := db.InsertOAuth2ProviderApp(genCtx, database.InsertOAuth2ProviderAppParams{
-		ID:          takeFirst(seed.ID, uuid.New()),
-		Name:        takeFirst(seed.Name, testutil.GetRandomName(t)),
-		CreatedAt:   takeFirst(seed.CreatedAt, dbtime.Now()),
-		UpdatedAt:   takeFirst(seed.UpdatedAt, dbtime.Now()),
-		Icon:        takeFirst(seed.Icon, ""),
-		CallbackURL: takeFirst(seed.CallbackURL, "http://localhost"),
+		ID:                      takeFirst(seed.ID, uuid.New()),
+		Name:                    takeFirst(seed.Name, testutil.GetRandomName(t)),
+		CreatedAt:               takeFirst(seed.CreatedAt, dbtime.Now()),
+		UpdatedAt:               takeFirst(seed.UpdatedAt, dbtime.Now()),
+		Icon:                    takeFirst(seed.Icon, ""),
+		CallbackURL:             takeFirst(seed.CallbackURL, "http://localhost"),
+		Public:                  seed.Public,
+		ClientCredentialsUserID: seed.ClientCredentialsUserID,
 	})
 	require.NoError(t, err, "insert oauth2 app")
 	return app
@@ -853,18 +898,34 @@ func OAuth2ProviderAppSecret(t testing.TB, db database.Store, seed database.OAut
 
 func OAuth2ProviderAppCode(t testing.TB, db database.Store, seed database.OAuth2ProviderAppCode) database.OAuth2ProviderAppCode {
 	code, err := db.InsertOAuth2ProviderAppCode(genCtx, database.InsertOAuth2ProviderAppCodeParams{
-		ID:           takeFirst(seed.ID, uuid.New()),
-		CreatedAt:    takeFirst(seed.CreatedAt, dbtime.Now()),
-		ExpiresAt:    takeFirst(seed.CreatedAt, dbtime.Now()),
-		SecretPrefix: takeFirstSlice(seed.SecretPrefix, []byte("prefix")),
-		HashedSecret: takeFirstSlice(seed.HashedSecret, []byte("hashed-secret")),
-		AppID:        takeFirst(seed.AppID, uuid.New()),
-		UserID:       takeFirst(seed.UserID, uuid.New()),
+		ID:                  takeFirst(seed.ID, uuid.New()),
+		CreatedAt:           takeFirst(seed.CreatedAt, dbtime.Now()),
+		ExpiresAt:           takeFirst(seed.CreatedAt, dbtime.Now()),
+		SecretPrefix:        takeFirstSlice(seed.SecretPrefix, []byte("prefix")),
+		HashedSecret:        takeFirstSlice(seed.HashedSecret, []byte("hashed-secret")),
+		AppID:               takeFirst(seed.AppID, uuid.New()),
+		UserID:              takeFirst(seed.UserID, uuid.New()),
+		CodeChallenge:       seed.CodeChallenge,
+		CodeChallengeMethod: seed.CodeChallengeMethod,
 	})
 	require.NoError(t, err, "insert oauth2 app code")
 	return code
 }
 
+func OAuth2ProviderAppDeviceCode(t testing.TB, db database.Store, seed database.OAuth2ProviderAppDeviceCode) database.OAuth2ProviderAppDeviceCode {
+	code, err := db.InsertOAuth2ProviderAppDeviceCode(genCtx, database.InsertOAuth2ProviderAppDeviceCodeParams{
+		ID:               takeFirst(seed.ID, uuid.New()),
+		CreatedAt:        takeFirst(seed.CreatedAt, dbtime.Now()),
+		ExpiresAt:        takeFirst(seed.ExpiresAt, dbtime.Now().Add(time.Minute*10)),
+		DevicePrefix:     takeFirstSlice(seed.DevicePrefix, []byte("prefix")),
+		HashedDeviceCode: takeFirstSlice(seed.HashedDeviceCode, []byte("hashed-device-code")),
+		UserCode:         takeFirst(seed.UserCode, "BCDFGHJK"),
+		AppID:            seed.AppID,
+	})
+	require.NoError(t, err, "insert oauth2 app device code")
+	return code
+}
+
 func OAuth2ProviderAppToken(t testing.TB, db database.Store, seed database.OAuth2ProviderAppToken) database.OAuth2ProviderAppToken {
 	token, err := db.InsertOAuth2ProviderAppToken(genCtx, database.InsertOAuth2ProviderAppTokenParams{
 		ID:          takeFirst(seed.ID, uuid.New()),
@@ -872,8 +933,9 @@ func OAuth2ProviderAppToken(t testing.TB, db database.Store, seed database.OAuth
 		ExpiresAt:   takeFirst(seed.CreatedAt, dbtime.Now()),
 		HashPrefix:  takeFirstSlice(seed.HashPrefix, []byte("prefix")),
 		RefreshHash: takeFirstSlice(seed.RefreshHash, []byte("hashed-secret")),
-		AppSecretID: takeFirst(seed.AppSecretID, uuid.New()),
+		AppSecretID: seed.AppSecretID,
 		APIKeyID:    takeFirst(seed.APIKeyID, uuid.New().String()),
+		AppID:       takeFirst(seed.AppID, uuid.New()),
 	})
 	require.NoError(t, err, "insert oauth2 app token")
 	return token
@@ -892,6 +954,38 @@ func CustomRole(t testing.TB, db database.Store, seed database.CustomRole) datab
 	return role
 }
 
+func RoleGrantRequest(t testing.TB, db database.Store, orig database.RoleGrantRequest) database.RoleGrantRequest {
+	roleName := rbac.RoleTemplateAdmin().Name
+	if orig.OrganizationID.Valid {
+		roleName = rbac.RoleOrgTemplateAdmin()
+	}
+	req, err := db.InsertRoleGrantRequest(genCtx, database.InsertRoleGrantRequestParams{
+		ID:              takeFirst(orig.ID, uuid.New()),
+		UserID:          takeFirst(orig.UserID, uuid.New()),
+		OrganizationID:  orig.OrganizationID,
+		RoleName:        takeFirst(orig.RoleName, roleName),
+		Reason:          takeFirst(orig.Reason, testutil.GetRandomName(t)),
+		LifetimeSeconds: takeFirst(orig.LifetimeSeconds, 3600),
+		CreatedAt:       takeFirst(orig.CreatedAt, dbtime.Now()),
+		UpdatedAt:       takeFirst(orig.UpdatedAt, dbtime.Now()),
+	})
+	require.NoError(t, err, "insert role grant request")
+	if orig.Status == "" || orig.Status == database.RoleGrantStatusPending {
+		return req
+	}
+	req, err = db.UpdateRoleGrantRequestByID(genCtx, database.UpdateRoleGrantRequestByIDParams{
+		ID:         req.ID,
+		Status:     orig.Status,
+		FromStatus: database.RoleGrantStatusPending,
+		ReviewedBy: orig.ReviewedBy,
+		ReviewedAt: orig.ReviewedAt,
+		ExpiresAt:  orig.ExpiresAt,
+		UpdatedAt:  req.UpdatedAt,
+	})
+	require.NoError(t, err, "update role grant request")
+	return req
+}
+
 func must[V any](v V, err error) V {
 	if err != nil {
 		panic(err)
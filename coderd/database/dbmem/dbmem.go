@@ -3,7 +3,9 @@ package dbmem
 import (
 	"bytes"
 	"context"
+	"crypto/md5" //#nosec // Only used to assign workspaces to canary cohorts.
 	"database/sql"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -53,37 +55,39 @@ func New() database.Store {
 	q := &FakeQuerier{
 		mutex: &sync.RWMutex{},
 		data: &data{
-			apiKeys:                   make([]database.APIKey, 0),
-			organizationMembers:       make([]database.OrganizationMember, 0),
-			organizations:             make([]database.Organization, 0),
-			users:                     make([]database.User, 0),
-			dbcryptKeys:               make([]database.DBCryptKey, 0),
-			externalAuthLinks:         make([]database.ExternalAuthLink, 0),
-			groups:                    make([]database.Group, 0),
-			groupMembers:              make([]database.GroupMemberTable, 0),
-			auditLogs:                 make([]database.AuditLog, 0),
-			files:                     make([]database.File, 0),
-			gitSSHKey:                 make([]database.GitSSHKey, 0),
-			notificationMessages:      make([]database.NotificationMessage, 0),
-			notificationPreferences:   make([]database.NotificationPreference, 0),
-			parameterSchemas:          make([]database.ParameterSchema, 0),
-			provisionerDaemons:        make([]database.ProvisionerDaemon, 0),
-			workspaceAgents:           make([]database.WorkspaceAgent, 0),
-			provisionerJobLogs:        make([]database.ProvisionerJobLog, 0),
-			workspaceResources:        make([]database.WorkspaceResource, 0),
-			workspaceResourceMetadata: make([]database.WorkspaceResourceMetadatum, 0),
-			provisionerJobs:           make([]database.ProvisionerJob, 0),
-			templateVersions:          make([]database.TemplateVersionTable, 0),
-			templates:                 make([]database.TemplateTable, 0),
-			workspaceAgentStats:       make([]database.WorkspaceAgentStat, 0),
-			workspaceAgentLogs:        make([]database.WorkspaceAgentLog, 0),
-			workspaceBuilds:           make([]database.WorkspaceBuild, 0),
-			workspaceApps:             make([]database.WorkspaceApp, 0),
-			workspaces:                make([]database.Workspace, 0),
-			licenses:                  make([]database.License, 0),
-			workspaceProxies:          make([]database.WorkspaceProxy, 0),
-			customRoles:               make([]database.CustomRole, 0),
-			locks:                     map[int64]struct{}{},
+			apiKeys:                       make([]database.APIKey, 0),
+			organizationMembers:           make([]database.OrganizationMember, 0),
+			organizations:                 make([]database.Organization, 0),
+			users:                         make([]database.User, 0),
+			dbcryptKeys:                   make([]database.DBCryptKey, 0),
+			externalAuthLinks:             make([]database.ExternalAuthLink, 0),
+			groups:                        make([]database.Group, 0),
+			groupMembers:                  make([]database.GroupMemberTable, 0),
+			auditLogs:                     make([]database.AuditLog, 0),
+			files:                         make([]database.File, 0),
+			gitSSHKey:                     make([]database.GitSSHKey, 0),
+			notificationMessages:          make([]database.NotificationMessage, 0),
+			notificationPreferences:       make([]database.NotificationPreference, 0),
+			parameterSchemas:              make([]database.ParameterSchema, 0),
+			provisionerDaemons:            make([]database.ProvisionerDaemon, 0),
+			workspaceAgents:               make([]database.WorkspaceAgent, 0),
+			provisionerJobLogs:            make([]database.ProvisionerJobLog, 0),
+			provisionerJobResourceChanges: make([]database.ProvisionerJobResourceChange, 0),
+			provisionerJobTimings:         make([]database.ProvisionerJobTiming, 0),
+			workspaceResources:            make([]database.WorkspaceResource, 0),
+			workspaceResourceMetadata:     make([]database.WorkspaceResourceMetadatum, 0),
+			provisionerJobs:               make([]database.ProvisionerJob, 0),
+			templateVersions:              make([]database.TemplateVersionTable, 0),
+			templates:                     make([]database.TemplateTable, 0),
+			workspaceAgentStats:           make([]database.WorkspaceAgentStat, 0),
+			workspaceAgentLogs:            make([]database.WorkspaceAgentLog, 0),
+			workspaceBuilds:               make([]database.WorkspaceBuild, 0),
+			workspaceApps:                 make([]database.WorkspaceApp, 0),
+			workspaces:                    make([]database.Workspace, 0),
+			licenses:                      make([]database.License, 0),
+			workspaceProxies:              make([]database.WorkspaceProxy, 0),
+			customRoles:                   make([]database.CustomRole, 0),
+			locks:                         map[int64]struct{}{},
 		},
 	}
 	// Always start with a default org. Matching migration 198.
@@ -148,11 +152,14 @@ type data struct {
 	organizationMembers []database.OrganizationMember
 	users               []database.User
 	userLinks           []database.UserLink
+	userTOTPs           []database.UserTOTP
 
 	// New tables
 	workspaceAgentStats           []database.WorkspaceAgentStat
 	auditLogs                     []database.AuditLog
 	dbcryptKeys                   []database.DBCryptKey
+	derpHealthHistory             []database.DERPHealthHistory
+	derpHealthHistoryLastInsertID int64
 	files                         []database.File
 	externalAuthLinks             []database.ExternalAuthLink
 	gitSSHKey                     []database.GitSSHKey
@@ -165,14 +172,19 @@ type data struct {
 	oauth2ProviderApps            []database.OAuth2ProviderApp
 	oauth2ProviderAppSecrets      []database.OAuth2ProviderAppSecret
 	oauth2ProviderAppCodes        []database.OAuth2ProviderAppCode
+	oauth2ProviderAppDeviceCodes  []database.OAuth2ProviderAppDeviceCode
 	oauth2ProviderAppTokens       []database.OAuth2ProviderAppToken
 	parameterSchemas              []database.ParameterSchema
 	provisionerDaemons            []database.ProvisionerDaemon
 	provisionerJobLogs            []database.ProvisionerJobLog
+	provisionerJobResourceChanges []database.ProvisionerJobResourceChange
+	provisionerJobTimings         []database.ProvisionerJobTiming
 	provisionerJobs               []database.ProvisionerJob
 	provisionerKeys               []database.ProvisionerKey
 	replicas                      []database.Replica
+	roleGrantRequests             []database.RoleGrantRequest
 	templateVersions              []database.TemplateVersionTable
+	templateVersionCanaries       []database.TemplateVersionCanary
 	templateVersionParameters     []database.TemplateVersionParameter
 	templateVersionVariables      []database.TemplateVersionVariable
 	templateVersionWorkspaceTags  []database.TemplateVersionWorkspaceTag
@@ -189,6 +201,7 @@ type data struct {
 	workspaceAppStats             []database.WorkspaceAppStat
 	workspaceBuilds               []database.WorkspaceBuild
 	workspaceBuildParameters      []database.WorkspaceBuildParameter
+	workspaceDriftChecks          []database.WorkspaceDriftCheck
 	workspaceResourceMetadata     []database.WorkspaceResourceMetadatum
 	workspaceResources            []database.WorkspaceResource
 	workspaces                    []database.Workspace
@@ -335,20 +348,22 @@ func convertUsers(users []database.User, count int64) []database.GetUsersRow {
 	rows := make([]database.GetUsersRow, len(users))
 	for i, u := range users {
 		rows[i] = database.GetUsersRow{
-			ID:             u.ID,
-			Email:          u.Email,
-			Username:       u.Username,
-			Name:           u.Name,
-			HashedPassword: u.HashedPassword,
-			CreatedAt:      u.CreatedAt,
-			UpdatedAt:      u.UpdatedAt,
-			Status:         u.Status,
-			RBACRoles:      u.RBACRoles,
-			LoginType:      u.LoginType,
-			AvatarURL:      u.AvatarURL,
-			Deleted:        u.Deleted,
-			LastSeenAt:     u.LastSeenAt,
-			Count:          count,
+			ID:                           u.ID,
+			Email:                        u.Email,
+			Username:                     u.Username,
+			Name:                         u.Name,
+			HashedPassword:               u.HashedPassword,
+			CreatedAt:                    u.CreatedAt,
+			UpdatedAt:                    u.UpdatedAt,
+			Status:                       u.Status,
+			RBACRoles:                    u.RBACRoles,
+			LoginType:                    u.LoginType,
+			AvatarURL:                    u.AvatarURL,
+			Deleted:                      u.Deleted,
+			LastSeenAt:                   u.LastSeenAt,
+			IsServiceAccount:             u.IsServiceAccount,
+			ServiceAccountOrganizationID: u.ServiceAccountOrganizationID,
+			Count:                        count,
 		}
 	}
 
@@ -801,6 +816,31 @@ func (q *FakeQuerier) isEveryoneGroup(id uuid.UUID) bool {
 	return false
 }
 
+// inCanaryCohortNoLock mirrors the cohort check of
+// GetTemplateVersionCanaryByWorkspaceID.
+func (q *FakeQuerier) inCanaryCohortNoLock(ctx context.Context, canary database.TemplateVersionCanary, workspace database.Workspace) bool {
+	if canary.GroupID.Valid {
+		if q.isEveryoneGroup(canary.GroupID.UUID) {
+			for _, member := range q.getOrganizationMemberNoLock(canary.GroupID.UUID) {
+				if member.UserID == workspace.OwnerID {
+					return true
+				}
+			}
+		} else {
+			for _, member := range q.groupMembers {
+				if member.GroupID != canary.GroupID.UUID || member.UserID != workspace.OwnerID {
+					continue
+				}
+				if _, err := q.getGroupMemberNoLock(ctx, member.UserID, member.GroupID); err == nil {
+					return true
+				}
+			}
+		}
+	}
+	sum := md5.Sum([]byte(workspace.ID.String())) //#nosec // Not used for security.
+	return int32(binary.BigEndian.Uint32(sum[:4])%100) < canary.Percent
+}
+
 func (q *FakeQuerier) GetActiveDBCryptKeys(_ context.Context) ([]database.DBCryptKey, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1049,6 +1089,27 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	// Jobs already running for each initiator, to give every user a fair
+	// share of the provisioners.
+	running := map[uuid.UUID]int{}
+	for _, provisionerJob := range q.provisionerJobs {
+		if provisionerJob.OrganizationID == arg.OrganizationID && provisionerJob.StartedAt.Valid && !provisionerJob.CompletedAt.Valid {
+			running[provisionerJob.InitiatorID]++
+		}
+	}
+	// ORDER BY nested.priority, running_jobs.running, nested.created_at
+	priorities := database.AllProvisionerJobPriorityValues()
+	before := func(a, b database.ProvisionerJob) bool {
+		if pa, pb := slices.Index(priorities, a.Priority), slices.Index(priorities, b.Priority); pa != pb {
+			return pa < pb
+		}
+		if ra, rb := running[a.InitiatorID], running[b.InitiatorID]; ra != rb {
+			return ra < rb
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	selected := -1
 	for index, provisionerJob := range q.provisionerJobs {
 		if provisionerJob.OrganizationID != arg.OrganizationID {
 			continue
@@ -1076,7 +1137,7 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 		}
 
 		// Special case for untagged provisioners: only match untagged jobs.
-		// Ref: coderd/database/queries/provisionerjobs.sql:24-30
+		// Ref: coderd/database/queries/provisionerjobs.sql:38-44
 		// CASE WHEN nested.tags :: jsonb = '{"scope": "organization", "owner": ""}' :: jsonb
 		//      THEN nested.tags :: jsonb = @tags :: jsonb
 		if tagsEqual(provisionerJob.Tags, tagsUntagged) && !tagsEqual(provisionerJob.Tags, tags) {
@@ -1086,11 +1147,17 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 		if !tagsSubset(provisionerJob.Tags, tags) {
 			continue
 		}
+		if selected < 0 || before(provisionerJob, q.provisionerJobs[selected]) {
+			selected = index
+		}
+	}
+	if selected >= 0 {
+		provisionerJob := q.provisionerJobs[selected]
 		provisionerJob.StartedAt = arg.StartedAt
 		provisionerJob.UpdatedAt = arg.StartedAt.Time
 		provisionerJob.WorkerID = arg.WorkerID
 		provisionerJob.JobStatus = provisonerJobStatus(provisionerJob)
-		q.provisionerJobs[index] = provisionerJob
+		q.provisionerJobs[selected] = provisionerJob
 		// clone the Tags before returning, since maps are reference types and
 		// we don't want the caller to be able to mutate the map we have inside
 		// dbmem!
@@ -1461,6 +1528,17 @@ func (q *FakeQuerier) DeleteCustomRole(_ context.Context, arg database.DeleteCus
 	return nil
 }
 
+func (q *FakeQuerier) DeleteExpiredOAuth2ProviderAppDeviceCodes(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := dbtime.Now()
+	q.oauth2ProviderAppDeviceCodes = slices.DeleteFunc(q.oauth2ProviderAppDeviceCodes, func(code database.OAuth2ProviderAppDeviceCode) bool {
+		return code.ExpiresAt.Before(now)
+	})
+	return nil
+}
+
 func (q *FakeQuerier) DeleteExternalAuthLink(_ context.Context, arg database.DeleteExternalAuthLinkParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -1554,20 +1632,18 @@ func (q *FakeQuerier) DeleteOAuth2ProviderAppByID(_ context.Context, id uuid.UUI
 	q.oauth2ProviderApps[index] = q.oauth2ProviderApps[len(q.oauth2ProviderApps)-1]
 	q.oauth2ProviderApps = q.oauth2ProviderApps[:len(q.oauth2ProviderApps)-1]
 
-	// Cascade delete secrets associated with the deleted app.
-	var deletedSecretIDs []uuid.UUID
+	// Cascade delete secrets and device codes associated with the deleted app.
 	q.oauth2ProviderAppSecrets = slices.DeleteFunc(q.oauth2ProviderAppSecrets, func(secret database.OAuth2ProviderAppSecret) bool {
-		matches := secret.AppID == id
-		if matches {
-			deletedSecretIDs = append(deletedSecretIDs, secret.ID)
-		}
-		return matches
+		return secret.AppID == id
+	})
+	q.oauth2ProviderAppDeviceCodes = slices.DeleteFunc(q.oauth2ProviderAppDeviceCodes, func(code database.OAuth2ProviderAppDeviceCode) bool {
+		return code.AppID.Valid && code.AppID.UUID == id
 	})
 
-	// Cascade delete tokens through the deleted secrets.
+	// Cascade delete tokens associated with the deleted app.
 	var keyIDsToDelete []string
 	q.oauth2ProviderAppTokens = slices.DeleteFunc(q.oauth2ProviderAppTokens, func(token database.OAuth2ProviderAppToken) bool {
-		matches := slice.Contains(deletedSecretIDs, token.AppSecretID)
+		matches := token.AppID == id
 		if matches {
 			keyIDsToDelete = append(keyIDsToDelete, token.APIKeyID)
 		}
@@ -1615,6 +1691,20 @@ func (q *FakeQuerier) DeleteOAuth2ProviderAppCodesByAppAndUserID(_ context.Conte
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) DeleteOAuth2ProviderAppDeviceCodeByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, code := range q.oauth2ProviderAppDeviceCodes {
+		if code.ID == id {
+			q.oauth2ProviderAppDeviceCodes[index] = q.oauth2ProviderAppDeviceCodes[len(q.oauth2ProviderAppDeviceCodes)-1]
+			q.oauth2ProviderAppDeviceCodes = q.oauth2ProviderAppDeviceCodes[:len(q.oauth2ProviderAppDeviceCodes)-1]
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) DeleteOAuth2ProviderAppSecretByID(_ context.Context, id uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -1633,7 +1723,7 @@ func (q *FakeQuerier) DeleteOAuth2ProviderAppSecretByID(_ context.Context, id uu
 	// Cascade delete tokens created through the deleted secret.
 	var keyIDsToDelete []string
 	q.oauth2ProviderAppTokens = slices.DeleteFunc(q.oauth2ProviderAppTokens, func(token database.OAuth2ProviderAppToken) bool {
-		matches := token.AppSecretID == id
+		matches := token.AppSecretID.Valid && token.AppSecretID.UUID == id
 		if matches {
 			keyIDsToDelete = append(keyIDsToDelete, token.APIKeyID)
 		}
@@ -1659,15 +1749,11 @@ func (q *FakeQuerier) DeleteOAuth2ProviderAppTokensByAppAndUserID(_ context.Cont
 
 	var keyIDsToDelete []string
 	q.oauth2ProviderAppTokens = slices.DeleteFunc(q.oauth2ProviderAppTokens, func(token database.OAuth2ProviderAppToken) bool {
-		// Join secrets and keys to see if the token matches.
-		secretIdx := slices.IndexFunc(q.oauth2ProviderAppSecrets, func(secret database.OAuth2ProviderAppSecret) bool {
-			return secret.ID == token.AppSecretID
-		})
+		// Join keys to see if the token matches.
 		keyIdx := slices.IndexFunc(q.apiKeys, func(key database.APIKey) bool {
 			return key.ID == token.APIKeyID
 		})
-		matches := secretIdx != -1 &&
-			q.oauth2ProviderAppSecrets[secretIdx].AppID == arg.AppID &&
+		matches := token.AppID == arg.AppID &&
 			keyIdx != -1 && q.apiKeys[keyIdx].UserID == arg.UserID
 		if matches {
 			keyIDsToDelete = append(keyIDsToDelete, token.APIKeyID)
@@ -1683,6 +1769,17 @@ func (q *FakeQuerier) DeleteOAuth2ProviderAppTokensByAppAndUserID(_ context.Cont
 	return nil
 }
 
+func (q *FakeQuerier) DeleteOldDERPHealthHistory(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	weekAgo := dbtime.Now().Add(-7 * 24 * time.Hour)
+	q.derpHealthHistory = slices.DeleteFunc(q.derpHealthHistory, func(h database.DERPHealthHistory) bool {
+		return h.CreatedAt.Before(weekAgo)
+	})
+	return nil
+}
+
 func (*FakeQuerier) DeleteOldNotificationMessages(_ context.Context) error {
 	return nil
 }
@@ -1891,6 +1988,31 @@ func (*FakeQuerier) DeleteTailnetTunnel(_ context.Context, arg database.DeleteTa
 	return database.DeleteTailnetTunnelRow{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) DeleteSessionAPIKeysByUserID(_ context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.apiKeys = slices.DeleteFunc(q.apiKeys, func(key database.APIKey) bool {
+		return key.UserID == arg.UserID && slices.Contains(arg.LoginTypes, key.LoginType) && key.ID != arg.ExceptID
+	})
+	return nil
+}
+
+func (q *FakeQuerier) DeleteUserTOTPByUserID(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.userTOTPs = slices.DeleteFunc(q.userTOTPs, func(t database.UserTOTP) bool {
+		return t.UserID == userID
+	})
+	return nil
+}
+
 func (q *FakeQuerier) DeleteWorkspaceAgentPortShare(_ context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2088,13 +2210,54 @@ func (q *FakeQuerier) GetAPIKeysLastUsedAfter(_ context.Context, after time.Time
 	return apiKeys, nil
 }
 
+func (q *FakeQuerier) GetActiveServiceAccountCount(_ context.Context) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	active := int64(0)
+	for _, u := range q.users {
+		if u.Status == database.UserStatusActive && !u.Deleted && u.IsServiceAccount {
+			active++
+		}
+	}
+	return active, nil
+}
+
+func (q *FakeQuerier) GetActiveTemplateVersionCanaries(_ context.Context) ([]database.TemplateVersionCanary, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	canaries := []database.TemplateVersionCanary{}
+	for _, canary := range q.templateVersionCanaries {
+		if canary.Status == database.TemplateVersionCanaryStatusActive {
+			canaries = append(canaries, canary)
+		}
+	}
+	slices.SortFunc(canaries, func(a, b database.TemplateVersionCanary) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return canaries, nil
+}
+
+func (q *FakeQuerier) GetActiveTemplateVersionCanaryByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, canary := range q.templateVersionCanaries {
+		if canary.TemplateID == templateID && canary.Status == database.TemplateVersionCanaryStatusActive {
+			return canary, nil
+		}
+	}
+	return database.TemplateVersionCanary{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetActiveUserCount(_ context.Context) (int64, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
 	active := int64(0)
 	for _, u := range q.users {
-		if u.Status == database.UserStatusActive && !u.Deleted {
+		if u.Status == database.UserStatusActive && !u.Deleted && !u.IsServiceAccount {
 			active++
 		}
 	}
@@ -2240,6 +2403,34 @@ func (q *FakeQuerier) GetDBCryptKeys(_ context.Context) ([]database.DBCryptKey,
 	return ks, nil
 }
 
+func (q *FakeQuerier) GetDERPHealthHistory(_ context.Context, arg database.GetDERPHealthHistoryParams) ([]database.DERPHealthHistory, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	history := make([]database.DERPHealthHistory, 0)
+	for _, h := range q.derpHealthHistory {
+		if h.CreatedAt.Before(arg.CreatedAfter) {
+			continue
+		}
+		if arg.RegionID != 0 && h.RegionID != arg.RegionID {
+			continue
+		}
+		history = append(history, h)
+	}
+	slices.SortFunc(history, func(a, b database.DERPHealthHistory) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	return history, nil
+}
+
 func (q *FakeQuerier) GetDERPMeshKey(_ context.Context) (string, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2409,6 +2600,22 @@ func (q *FakeQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (database
 	return stat, nil
 }
 
+func (q *FakeQuerier) GetExpiredRoleGrantRequests(_ context.Context, now time.Time) ([]database.RoleGrantRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	reqs := []database.RoleGrantRequest{}
+	for _, req := range q.roleGrantRequests {
+		if req.Status == database.RoleGrantStatusApproved && req.ExpiresAt.Valid && !req.ExpiresAt.Time.After(now) {
+			reqs = append(reqs, req)
+		}
+	}
+	slices.SortFunc(reqs, func(a, b database.RoleGrantRequest) int {
+		return a.ExpiresAt.Time.Compare(b.ExpiresAt.Time)
+	})
+	return reqs, nil
+}
+
 func (q *FakeQuerier) GetExternalAuthLink(_ context.Context, arg database.GetExternalAuthLinkParams) (database.ExternalAuthLink, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.ExternalAuthLink{}, err
@@ -2707,6 +2914,29 @@ func (q *FakeQuerier) GetLastUpdateCheck(_ context.Context) (string, error) {
 	return string(q.lastUpdateCheck), nil
 }
 
+func (q *FakeQuerier) GetLatestTemplateVersionCanaryByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var (
+		latest database.TemplateVersionCanary
+		found  bool
+	)
+	for _, canary := range q.templateVersionCanaries {
+		if canary.TemplateID != templateID {
+			continue
+		}
+		if !found || canary.CreatedAt.After(latest.CreatedAt) {
+			latest = canary
+			found = true
+		}
+	}
+	if !found {
+		return database.TemplateVersionCanary{}, sql.ErrNoRows
+	}
+	return latest, nil
+}
+
 func (q *FakeQuerier) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceBuild, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2878,6 +3108,42 @@ func (q *FakeQuerier) GetOAuth2ProviderAppCodeByPrefix(_ context.Context, secret
 	return database.OAuth2ProviderAppCode{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetOAuth2ProviderAppDeviceCodeByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderAppDeviceCode, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, code := range q.oauth2ProviderAppDeviceCodes {
+		if code.ID == id {
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetOAuth2ProviderAppDeviceCodeByPrefix(_ context.Context, devicePrefix []byte) (database.OAuth2ProviderAppDeviceCode, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, code := range q.oauth2ProviderAppDeviceCodes {
+		if bytes.Equal(code.DevicePrefix, devicePrefix) {
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetOAuth2ProviderAppDeviceCodeByUserCode(_ context.Context, userCode string) (database.OAuth2ProviderAppDeviceCode, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, code := range q.oauth2ProviderAppDeviceCodes {
+		if code.UserCode == userCode {
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetOAuth2ProviderAppSecretByID(_ context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -2959,27 +3225,25 @@ func (q *FakeQuerier) GetOAuth2ProviderAppsByUserID(_ context.Context, userID uu
 	rows := []database.GetOAuth2ProviderAppsByUserIDRow{}
 	for _, app := range q.oauth2ProviderApps {
 		tokens := []database.OAuth2ProviderAppToken{}
-		for _, secret := range q.oauth2ProviderAppSecrets {
-			if secret.AppID == app.ID {
-				for _, token := range q.oauth2ProviderAppTokens {
-					if token.AppSecretID == secret.ID {
-						keyIdx := slices.IndexFunc(q.apiKeys, func(key database.APIKey) bool {
-							return key.ID == token.APIKeyID
-						})
-						if keyIdx != -1 && q.apiKeys[keyIdx].UserID == userID {
-							tokens = append(tokens, token)
-						}
-					}
+		for _, token := range q.oauth2ProviderAppTokens {
+			if token.AppID == app.ID {
+				keyIdx := slices.IndexFunc(q.apiKeys, func(key database.APIKey) bool {
+					return key.ID == token.APIKeyID
+				})
+				if keyIdx != -1 && q.apiKeys[keyIdx].UserID == userID {
+					tokens = append(tokens, token)
 				}
 			}
 		}
 		if len(tokens) > 0 {
 			rows = append(rows, database.GetOAuth2ProviderAppsByUserIDRow{
 				OAuth2ProviderApp: database.OAuth2ProviderApp{
-					CallbackURL: app.CallbackURL,
-					ID:          app.ID,
-					Icon:        app.Icon,
-					Name:        app.Name,
+					CallbackURL:             app.CallbackURL,
+					ID:                      app.ID,
+					Icon:                    app.Icon,
+					Name:                    app.Name,
+					Public:                  app.Public,
+					ClientCredentialsUserID: app.ClientCredentialsUserID,
 				},
 				TokenCount: int64(len(tokens)),
 			})
@@ -3177,6 +3441,38 @@ func (q *FakeQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return q.getProvisionerJobByIDNoLock(ctx, id)
 }
 
+func (q *FakeQuerier) GetProvisionerJobResourceChangesByJobID(_ context.Context, jobID uuid.UUID) ([]database.ProvisionerJobResourceChange, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	changes := make([]database.ProvisionerJobResourceChange, 0)
+	for _, change := range q.provisionerJobResourceChanges {
+		if change.JobID == jobID {
+			changes = append(changes, change)
+		}
+	}
+	slices.SortFunc(changes, func(a, b database.ProvisionerJobResourceChange) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return changes, nil
+}
+
+func (q *FakeQuerier) GetProvisionerJobTimingsByJobID(_ context.Context, jobID uuid.UUID) ([]database.ProvisionerJobTiming, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	timings := make([]database.ProvisionerJobTiming, 0)
+	for _, timing := range q.provisionerJobTimings {
+		if timing.JobID == jobID {
+			timings = append(timings, timing)
+		}
+	}
+	slices.SortFunc(timings, func(a, b database.ProvisionerJobTiming) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return timings, nil
+}
+
 func (q *FakeQuerier) GetProvisionerJobsByIDs(_ context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3432,6 +3728,84 @@ func (*FakeQuerier) GetTailnetTunnelPeerIDs(context.Context, uuid.UUID) ([]datab
 	return nil, ErrUnimplemented
 }
 
+func (q *FakeQuerier) GetRoleGrantRequestByID(_ context.Context, id uuid.UUID) (database.RoleGrantRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, req := range q.roleGrantRequests {
+		if req.ID == id {
+			return req, nil
+		}
+	}
+	return database.RoleGrantRequest{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetRoleGrantRequests(_ context.Context, arg database.GetRoleGrantRequestsParams) ([]database.RoleGrantRequest, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	reqs := []database.RoleGrantRequest{}
+	for _, req := range q.roleGrantRequests {
+		if arg.OrganizationID != uuid.Nil && req.OrganizationID.UUID != arg.OrganizationID {
+			continue
+		}
+		if arg.Status != "" && string(req.Status) != arg.Status {
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+	slices.SortFunc(reqs, func(a, b database.RoleGrantRequest) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return reqs, nil
+}
+
+func (q *FakeQuerier) GetRoleGrantRequestsByUserID(_ context.Context, userID uuid.UUID) ([]database.RoleGrantRequest, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	reqs := []database.RoleGrantRequest{}
+	for _, req := range q.roleGrantRequests {
+		if req.UserID == userID {
+			reqs = append(reqs, req)
+		}
+	}
+	slices.SortFunc(reqs, func(a, b database.RoleGrantRequest) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return reqs, nil
+}
+
+func (q *FakeQuerier) GetSessionAPIKeysByUserID(_ context.Context, arg database.GetSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	now := dbtime.Now()
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.UserID != arg.UserID || !slices.Contains(arg.LoginTypes, key.LoginType) {
+			continue
+		}
+		if key.Scope != database.APIKeyScopeAll || !key.ExpiresAt.After(now) {
+			continue
+		}
+		apiKeys = append(apiKeys, key)
+	}
+	slices.SortFunc(apiKeys, func(a, b database.APIKey) int {
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+	return apiKeys, nil
+}
+
 func (q *FakeQuerier) GetTemplateAppInsights(ctx context.Context, arg database.GetTemplateAppInsightsParams) ([]database.GetTemplateAppInsightsRow, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -4498,43 +4872,126 @@ func (q *FakeQuerier) GetTemplateVersionByTemplateIDAndName(_ context.Context, a
 	return database.TemplateVersion{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetTemplateVersionParameters(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionParameter, error) {
+func (q *FakeQuerier) GetTemplateVersionCanaryByID(_ context.Context, id uuid.UUID) (database.TemplateVersionCanary, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	parameters := make([]database.TemplateVersionParameter, 0)
-	for _, param := range q.templateVersionParameters {
-		if param.TemplateVersionID != templateVersionID {
-			continue
+	for _, canary := range q.templateVersionCanaries {
+		if canary.ID == id {
+			return canary, nil
 		}
-		parameters = append(parameters, param)
 	}
-	sort.Slice(parameters, func(i, j int) bool {
-		if parameters[i].DisplayOrder != parameters[j].DisplayOrder {
-			return parameters[i].DisplayOrder < parameters[j].DisplayOrder
-		}
-		return strings.ToLower(parameters[i].Name) < strings.ToLower(parameters[j].Name)
-	})
-	return parameters, nil
+	return database.TemplateVersionCanary{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
+func (q *FakeQuerier) GetTemplateVersionCanaryByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.TemplateVersionCanary, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	variables := make([]database.TemplateVersionVariable, 0)
-	for _, variable := range q.templateVersionVariables {
-		if variable.TemplateVersionID != templateVersionID {
+	workspace, err := q.getWorkspaceByIDNoLock(ctx, workspaceID)
+	if err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+	for _, canary := range q.templateVersionCanaries {
+		if canary.TemplateID != workspace.TemplateID || canary.Status != database.TemplateVersionCanaryStatusActive {
 			continue
 		}
-		variables = append(variables, variable)
+		if q.inCanaryCohortNoLock(ctx, canary, workspace) {
+			return canary, nil
+		}
 	}
-	return variables, nil
+	return database.TemplateVersionCanary{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetTemplateVersionWorkspaceTags(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionWorkspaceTag, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+func (q *FakeQuerier) GetTemplateVersionCanaryStats(ctx context.Context, arg database.GetTemplateVersionCanaryStatsParams) (database.GetTemplateVersionCanaryStatsRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.GetTemplateVersionCanaryStatsRow{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var row database.GetTemplateVersionCanaryStatsRow
+	for _, build := range q.workspaceBuilds {
+		if build.TemplateVersionID != arg.TemplateVersionID ||
+			build.Transition != database.WorkspaceTransitionStart ||
+			build.CreatedAt.Before(arg.Since) {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			continue
+		}
+		resources, err := q.getWorkspaceResourcesByJobIDNoLock(ctx, job.ID)
+		if err != nil {
+			return database.GetTemplateVersionCanaryStatsRow{}, err
+		}
+		resourceIDs := make([]uuid.UUID, 0, len(resources))
+		for _, resource := range resources {
+			resourceIDs = append(resourceIDs, resource.ID)
+		}
+		agents, err := q.getWorkspaceAgentsByResourceIDsNoLock(ctx, resourceIDs)
+		if err != nil {
+			return database.GetTemplateVersionCanaryStatsRow{}, err
+		}
+		var agentsFailed, agentsPending bool
+		for _, agent := range agents {
+			switch agent.LifecycleState {
+			case database.WorkspaceAgentLifecycleStateStartTimeout, database.WorkspaceAgentLifecycleStateStartError:
+				agentsFailed = true
+			case database.WorkspaceAgentLifecycleStateCreated, database.WorkspaceAgentLifecycleStateStarting:
+				agentsPending = true
+			}
+		}
+		status := provisonerJobStatus(job)
+		if status == database.ProvisionerJobStatusFailed || agentsFailed {
+			row.FailedBuilds++
+		}
+		if status == database.ProvisionerJobStatusSucceeded && !agentsFailed && !agentsPending {
+			row.SucceededBuilds++
+		}
+	}
+	return row, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionParameters(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionParameter, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	parameters := make([]database.TemplateVersionParameter, 0)
+	for _, param := range q.templateVersionParameters {
+		if param.TemplateVersionID != templateVersionID {
+			continue
+		}
+		parameters = append(parameters, param)
+	}
+	sort.Slice(parameters, func(i, j int) bool {
+		if parameters[i].DisplayOrder != parameters[j].DisplayOrder {
+			return parameters[i].DisplayOrder < parameters[j].DisplayOrder
+		}
+		return strings.ToLower(parameters[i].Name) < strings.ToLower(parameters[j].Name)
+	})
+	return parameters, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	variables := make([]database.TemplateVersionVariable, 0)
+	for _, variable := range q.templateVersionVariables {
+		if variable.TemplateVersionID != templateVersionID {
+			continue
+		}
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionWorkspaceTags(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionWorkspaceTag, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
 
 	workspaceTags := make([]database.TemplateVersionWorkspaceTag, 0)
 	for _, workspaceTag := range q.templateVersionWorkspaceTags {
@@ -4675,6 +5132,54 @@ func (q *FakeQuerier) GetTemplatesWithFilter(ctx context.Context, arg database.G
 	return q.GetAuthorizedTemplates(ctx, arg, nil)
 }
 
+func (q *FakeQuerier) GetTimedOutProvisionerJobs(ctx context.Context, arg database.GetTimedOutProvisionerJobsParams) ([]database.ProvisionerJob, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	timedOutJobs := []database.ProvisionerJob{}
+	for _, provisionerJob := range q.provisionerJobs {
+		if !provisionerJob.StartedAt.Valid || provisionerJob.CompletedAt.Valid {
+			continue
+		}
+		var workspaceID uuid.UUID
+		for _, build := range q.workspaceBuilds {
+			if build.JobID == provisionerJob.ID {
+				workspaceID = build.WorkspaceID
+				break
+			}
+		}
+		if workspaceID == uuid.Nil {
+			continue
+		}
+		workspace, err := q.getWorkspaceByIDNoLock(ctx, workspaceID)
+		if err != nil {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+		if err != nil {
+			continue
+		}
+		if template.BuildTimeout <= 0 {
+			continue
+		}
+		gracePeriod := template.BuildCancelGracePeriod
+		if gracePeriod <= 0 {
+			gracePeriod = arg.DefaultCancelGracePeriod
+		}
+		deadline := provisionerJob.StartedAt.Time.Add(time.Duration(template.BuildTimeout + gracePeriod))
+		if deadline.Before(arg.Now) {
+			provisionerJob.Tags = maps.Clone(provisionerJob.Tags)
+			timedOutJobs = append(timedOutJobs, provisionerJob)
+		}
+	}
+	return timedOutJobs, nil
+}
+
 func (q *FakeQuerier) GetUnexpiredLicenses(_ context.Context) ([]database.License, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -5012,6 +5517,18 @@ func (q *FakeQuerier) GetUserNotificationPreferences(_ context.Context, userID u
 	return out, nil
 }
 
+func (q *FakeQuerier) GetUserTOTPByUserID(_ context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, t := range q.userTOTPs {
+		if t.UserID == userID {
+			return t, nil
+		}
+	}
+	return database.UserTOTP{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetUserWorkspaceBuildParameters(_ context.Context, params database.GetUserWorkspaceBuildParametersParams) ([]database.GetUserWorkspaceBuildParametersRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -5699,6 +6216,39 @@ func (q *FakeQuerier) GetWorkspaceBuildParameters(_ context.Context, workspaceBu
 	return params, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceBuildProvisionerStates(_ context.Context, arg database.GetWorkspaceBuildProvisionerStatesParams) ([]database.GetWorkspaceBuildProvisionerStatesRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceBuildProvisionerStatesRow, 0)
+	for _, build := range q.workspaceBuilds {
+		if bytes.Compare(build.ID[:], arg.AfterID[:]) <= 0 {
+			continue
+		}
+		if len(build.ProvisionerState) == 0 {
+			continue
+		}
+		rows = append(rows, database.GetWorkspaceBuildProvisionerStatesRow{
+			ID:                    build.ID,
+			ProvisionerState:      build.ProvisionerState,
+			ProvisionerStateKeyID: build.ProvisionerStateKeyID,
+			UpdatedAt:             build.UpdatedAt,
+		})
+	}
+	slices.SortFunc(rows, func(a, b database.GetWorkspaceBuildProvisionerStatesRow) int {
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	if arg.LimitOpt > 0 && len(rows) > int(arg.LimitOpt) {
+		rows = rows[:arg.LimitOpt]
+	}
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceBuildsByWorkspaceID(_ context.Context,
 	params database.GetWorkspaceBuildsByWorkspaceIDParams,
 ) ([]database.WorkspaceBuild, error) {
@@ -5850,6 +6400,18 @@ func (q *FakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceDriftCheckByWorkspaceID(_ context.Context, workspaceID uuid.UUID) (database.WorkspaceDriftCheck, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, check := range q.workspaceDriftChecks {
+		if check.WorkspaceID == workspaceID {
+			return check, nil
+		}
+	}
+	return database.WorkspaceDriftCheck{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceProxies(_ context.Context) ([]database.WorkspaceProxy, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -6054,6 +6616,60 @@ func (q *FakeQuerier) GetWorkspaces(ctx context.Context, arg database.GetWorkspa
 	return workspaceRows, err
 }
 
+func (q *FakeQuerier) GetWorkspacesDueForDriftCheck(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := []database.Workspace{}
+	for _, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.DormantAt.Valid {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template by ID: %w", err)
+		}
+		if template.Deleted || template.DriftDetectionInterval <= 0 {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if build.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
+		}
+		if job.JobStatus != database.ProvisionerJobStatusSucceeded {
+			continue
+		}
+
+		due := true
+		for _, check := range q.workspaceDriftChecks {
+			if check.WorkspaceID != workspace.ID {
+				continue
+			}
+			checkJob, err := q.getProvisionerJobByIDNoLock(ctx, check.JobID)
+			if err != nil {
+				return nil, xerrors.Errorf("get drift check job by ID: %w", err)
+			}
+			due = checkJob.CompletedAt.Valid &&
+				!check.ScheduledAt.Add(time.Duration(template.DriftDetectionInterval)).After(now)
+		}
+		if due {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+
+	return workspaces, nil
+}
+
 func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -6147,6 +6763,15 @@ func (q *FakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyP
 		LoginType:       arg.LoginType,
 		Scope:           arg.Scope,
 		TokenName:       arg.TokenName,
+		Scopes:          arg.Scopes,
+		AllowList:       arg.AllowList,
+		UserAgent:       arg.UserAgent,
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	if key.AllowList == nil {
+		key.AllowList = []string{}
 	}
 	q.apiKeys = append(q.apiKeys, key)
 	return key, nil
@@ -6237,6 +6862,34 @@ func (q *FakeQuerier) InsertDBCryptKey(_ context.Context, arg database.InsertDBC
 	return nil
 }
 
+func (q *FakeQuerier) InsertDERPHealthHistory(_ context.Context, arg database.InsertDERPHealthHistoryParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.derpHealthHistoryLastInsertID++
+	q.derpHealthHistory = append(q.derpHealthHistory, database.DERPHealthHistory{
+		ID:                  q.derpHealthHistoryLastInsertID,
+		CreatedAt:           arg.CreatedAt,
+		ReplicaID:           arg.ReplicaID,
+		RegionID:            arg.RegionID,
+		RegionCode:          arg.RegionCode,
+		NodeName:            arg.NodeName,
+		Healthy:             arg.Healthy,
+		CanExchangeMessages: arg.CanExchangeMessages,
+		RoundTripPingMs:     arg.RoundTripPingMs,
+		PacketLoss:          arg.PacketLoss,
+		StunEnabled:         arg.StunEnabled,
+		CanStun:             arg.CanStun,
+		Error:               arg.Error,
+	})
+	return nil
+}
+
 func (q *FakeQuerier) InsertDERPMeshKey(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -6450,12 +7103,14 @@ func (q *FakeQuerier) InsertOAuth2ProviderApp(_ context.Context, arg database.In
 
 	//nolint:gosimple // Go wants database.OAuth2ProviderApp(arg), but we cannot be sure the structs will remain identical.
 	app := database.OAuth2ProviderApp{
-		ID:          arg.ID,
-		CreatedAt:   arg.CreatedAt,
-		UpdatedAt:   arg.UpdatedAt,
-		Name:        arg.Name,
-		Icon:        arg.Icon,
-		CallbackURL: arg.CallbackURL,
+		ID:                      arg.ID,
+		CreatedAt:               arg.CreatedAt,
+		UpdatedAt:               arg.UpdatedAt,
+		Name:                    arg.Name,
+		Icon:                    arg.Icon,
+		CallbackURL:             arg.CallbackURL,
+		Public:                  arg.Public,
+		ClientCredentialsUserID: arg.ClientCredentialsUserID,
 	}
 	q.oauth2ProviderApps = append(q.oauth2ProviderApps, app)
 
@@ -6474,13 +7129,15 @@ func (q *FakeQuerier) InsertOAuth2ProviderAppCode(_ context.Context, arg databas
 	for _, app := range q.oauth2ProviderApps {
 		if app.ID == arg.AppID {
 			code := database.OAuth2ProviderAppCode{
-				ID:           arg.ID,
-				CreatedAt:    arg.CreatedAt,
-				ExpiresAt:    arg.ExpiresAt,
-				SecretPrefix: arg.SecretPrefix,
-				HashedSecret: arg.HashedSecret,
-				UserID:       arg.UserID,
-				AppID:        arg.AppID,
+				ID:                  arg.ID,
+				CreatedAt:           arg.CreatedAt,
+				ExpiresAt:           arg.ExpiresAt,
+				SecretPrefix:        arg.SecretPrefix,
+				HashedSecret:        arg.HashedSecret,
+				UserID:              arg.UserID,
+				AppID:               arg.AppID,
+				CodeChallenge:       arg.CodeChallenge,
+				CodeChallengeMethod: arg.CodeChallengeMethod,
 			}
 			q.oauth2ProviderAppCodes = append(q.oauth2ProviderAppCodes, code)
 			return code, nil
@@ -6490,6 +7147,41 @@ func (q *FakeQuerier) InsertOAuth2ProviderAppCode(_ context.Context, arg databas
 	return database.OAuth2ProviderAppCode{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) InsertOAuth2ProviderAppDeviceCode(_ context.Context, arg database.InsertOAuth2ProviderAppDeviceCodeParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, code := range q.oauth2ProviderAppDeviceCodes {
+		if bytes.Equal(code.DevicePrefix, arg.DevicePrefix) || code.UserCode == arg.UserCode {
+			return database.OAuth2ProviderAppDeviceCode{}, errUniqueConstraint
+		}
+	}
+
+	if arg.AppID.Valid && !slices.ContainsFunc(q.oauth2ProviderApps, func(app database.OAuth2ProviderApp) bool {
+		return app.ID == arg.AppID.UUID
+	}) {
+		return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+	}
+
+	code := database.OAuth2ProviderAppDeviceCode{
+		ID:               arg.ID,
+		CreatedAt:        arg.CreatedAt,
+		ExpiresAt:        arg.ExpiresAt,
+		DevicePrefix:     arg.DevicePrefix,
+		HashedDeviceCode: arg.HashedDeviceCode,
+		UserCode:         arg.UserCode,
+		AppID:            arg.AppID,
+		Status:           database.OAuth2ProviderDeviceCodeStatusPending,
+	}
+	q.oauth2ProviderAppDeviceCodes = append(q.oauth2ProviderAppDeviceCodes, code)
+	return code, nil
+}
+
 func (q *FakeQuerier) InsertOAuth2ProviderAppSecret(_ context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -6526,8 +7218,17 @@ func (q *FakeQuerier) InsertOAuth2ProviderAppToken(_ context.Context, arg databa
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for _, secret := range q.oauth2ProviderAppSecrets {
-		if secret.ID == arg.AppSecretID {
+	if arg.AppSecretID.Valid {
+		secretIdx := slices.IndexFunc(q.oauth2ProviderAppSecrets, func(secret database.OAuth2ProviderAppSecret) bool {
+			return secret.ID == arg.AppSecretID.UUID
+		})
+		if secretIdx == -1 {
+			return database.OAuth2ProviderAppToken{}, sql.ErrNoRows
+		}
+	}
+
+	for _, app := range q.oauth2ProviderApps {
+		if app.ID == arg.AppID {
 			//nolint:gosimple // Go wants database.OAuth2ProviderAppToken(arg), but we cannot be sure the structs will remain identical.
 			token := database.OAuth2ProviderAppToken{
 				ID:          arg.ID,
@@ -6537,6 +7238,7 @@ func (q *FakeQuerier) InsertOAuth2ProviderAppToken(_ context.Context, arg databa
 				RefreshHash: arg.RefreshHash,
 				APIKeyID:    arg.APIKeyID,
 				AppSecretID: arg.AppSecretID,
+				AppID:       arg.AppID,
 			}
 			q.oauth2ProviderAppTokens = append(q.oauth2ProviderAppTokens, token)
 			return token, nil
@@ -6563,6 +7265,9 @@ func (q *FakeQuerier) InsertOrganization(_ context.Context, arg database.InsertO
 		CreatedAt:   arg.CreatedAt,
 		UpdatedAt:   arg.UpdatedAt,
 		IsDefault:   len(q.organizations) == 0,
+
+		AllowedWorkspaceProxyIDs: []uuid.UUID{},
+		AllowedDERPRegionIDs:     []int32{},
 	}
 	q.organizations = append(q.organizations, organization)
 	return organization, nil
@@ -6623,6 +7328,7 @@ func (q *FakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 		Input:          arg.Input,
 		Tags:           maps.Clone(arg.Tags),
 		TraceMetadata:  arg.TraceMetadata,
+		Priority:       arg.Priority,
 	}
 	job.JobStatus = provisonerJobStatus(job)
 	q.provisionerJobs = append(q.provisionerJobs, job)
@@ -6658,13 +7364,56 @@ func (q *FakeQuerier) InsertProvisionerJobLogs(_ context.Context, arg database.I
 	return logs, nil
 }
 
-func (*FakeQuerier) InsertProvisionerJobTimings(_ context.Context, arg database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
+func (q *FakeQuerier) InsertProvisionerJobResourceChanges(_ context.Context, arg database.InsertProvisionerJobResourceChangesParams) ([]database.ProvisionerJobResourceChange, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	insertedChanges := make([]database.ProvisionerJobResourceChange, 0, len(arg.Address))
+	for i := range arg.Address {
+		change := database.ProvisionerJobResourceChange{
+			JobID:   arg.JobID,
+			Address: arg.Address[i],
+			Type:    arg.Type[i],
+			Name:    arg.Name[i],
+			Action:  arg.Action[i],
+		}
+		q.provisionerJobResourceChanges = append(q.provisionerJobResourceChanges, change)
+		insertedChanges = append(insertedChanges, change)
+	}
+
+	return insertedChanges, nil
+}
+
+func (q *FakeQuerier) InsertProvisionerJobTimings(_ context.Context, arg database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	insertedTimings := make([]database.ProvisionerJobTiming, 0, len(arg.StartedAt))
+	for i := range arg.StartedAt {
+		timing := database.ProvisionerJobTiming{
+			JobID:     arg.JobID,
+			StartedAt: arg.StartedAt[i],
+			EndedAt:   arg.EndedAt[i],
+			Stage:     arg.Stage[i],
+			Source:    arg.Source[i],
+			Action:    arg.Action[i],
+			Resource:  arg.Resource[i],
+		}
+		q.provisionerJobTimings = append(q.provisionerJobTimings, timing)
+		insertedTimings = append(insertedTimings, timing)
+	}
+
+	return insertedTimings, nil
 }
 
 func (q *FakeQuerier) InsertProvisionerKey(_ context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
@@ -6720,6 +7469,38 @@ func (q *FakeQuerier) InsertReplica(_ context.Context, arg database.InsertReplic
 	return replica, nil
 }
 
+func (q *FakeQuerier) InsertRoleGrantRequest(_ context.Context, arg database.InsertRoleGrantRequestParams) (database.RoleGrantRequest, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, req := range q.roleGrantRequests {
+		if req.UserID != arg.UserID || req.OrganizationID.UUID != arg.OrganizationID.UUID || req.RoleName != arg.RoleName {
+			continue
+		}
+		if req.Status == database.RoleGrantStatusPending || req.Status == database.RoleGrantStatusApproved {
+			return database.RoleGrantRequest{}, newUniqueConstraintError(database.UniqueRoleGrantRequestsOpenIndex)
+		}
+	}
+
+	req := database.RoleGrantRequest{
+		ID:              arg.ID,
+		UserID:          arg.UserID,
+		OrganizationID:  arg.OrganizationID,
+		RoleName:        arg.RoleName,
+		Reason:          arg.Reason,
+		LifetimeSeconds: arg.LifetimeSeconds,
+		Status:          database.RoleGrantStatusPending,
+		CreatedAt:       arg.CreatedAt,
+		UpdatedAt:       arg.UpdatedAt,
+	}
+	q.roleGrantRequests = append(q.roleGrantRequests, req)
+	return req, nil
+}
+
 func (q *FakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTemplateParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -6747,6 +7528,8 @@ func (q *FakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		AllowUserAutostart:           true,
 		AllowUserAutostop:            true,
 		MaxPortSharingLevel:          arg.MaxPortSharingLevel,
+		AllowedWorkspaceProxyIDs:     []uuid.UUID{},
+		AllowedDERPRegionIDs:         []int32{},
 	}
 	q.templates = append(q.templates, template)
 	return nil
@@ -6781,6 +7564,37 @@ func (q *FakeQuerier) InsertTemplateVersion(_ context.Context, arg database.Inse
 	return nil
 }
 
+func (q *FakeQuerier) InsertTemplateVersionCanary(_ context.Context, arg database.InsertTemplateVersionCanaryParams) (database.TemplateVersionCanary, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, canary := range q.templateVersionCanaries {
+		if canary.TemplateID == arg.TemplateID && canary.Status == database.TemplateVersionCanaryStatusActive {
+			return database.TemplateVersionCanary{}, errUniqueConstraint
+		}
+	}
+
+	canary := database.TemplateVersionCanary{
+		ID:                arg.ID,
+		TemplateID:        arg.TemplateID,
+		TemplateVersionID: arg.TemplateVersionID,
+		Percent:           arg.Percent,
+		GroupID:           arg.GroupID,
+		MinBuilds:         arg.MinBuilds,
+		SuccessThreshold:  arg.SuccessThreshold,
+		Status:            database.TemplateVersionCanaryStatusActive,
+		CreatedBy:         arg.CreatedBy,
+		CreatedAt:         arg.CreatedAt,
+		UpdatedAt:         arg.UpdatedAt,
+	}
+	q.templateVersionCanaries = append(q.templateVersionCanaries, canary)
+	return canary, nil
+}
+
 func (q *FakeQuerier) InsertTemplateVersionParameter(_ context.Context, arg database.InsertTemplateVersionParameterParams) (database.TemplateVersionParameter, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.TemplateVersionParameter{}, err
@@ -6885,16 +7699,18 @@ func (q *FakeQuerier) InsertUser(_ context.Context, arg database.InsertUserParam
 	}
 
 	user := database.User{
-		ID:             arg.ID,
-		Email:          arg.Email,
-		HashedPassword: arg.HashedPassword,
-		CreatedAt:      arg.CreatedAt,
-		UpdatedAt:      arg.UpdatedAt,
-		Username:       arg.Username,
-		Name:           arg.Name,
-		Status:         database.UserStatusDormant,
-		RBACRoles:      arg.RBACRoles,
-		LoginType:      arg.LoginType,
+		ID:                           arg.ID,
+		Email:                        arg.Email,
+		HashedPassword:               arg.HashedPassword,
+		CreatedAt:                    arg.CreatedAt,
+		UpdatedAt:                    arg.UpdatedAt,
+		Username:                     arg.Username,
+		Name:                         arg.Name,
+		Status:                       database.UserStatusDormant,
+		RBACRoles:                    arg.RBACRoles,
+		LoginType:                    arg.LoginType,
+		IsServiceAccount:             arg.IsServiceAccount,
+		ServiceAccountOrganizationID: arg.ServiceAccountOrganizationID,
 	}
 	q.users = append(q.users, user)
 	return user, nil
@@ -6906,6 +7722,9 @@ func (q *FakeQuerier) InsertUserGroupsByName(_ context.Context, arg database.Ins
 
 	var groupIDs []uuid.UUID
 	for _, group := range q.groups {
+		if group.OrganizationID != arg.OrganizationID {
+			continue
+		}
 		for _, groupName := range arg.GroupNames {
 			if group.Name == groupName {
 				groupIDs = append(groupIDs, group.ID)
@@ -7251,19 +8070,20 @@ func (q *FakeQuerier) InsertWorkspaceBuild(_ context.Context, arg database.Inser
 	defer q.mutex.Unlock()
 
 	workspaceBuild := database.WorkspaceBuild{
-		ID:                arg.ID,
-		CreatedAt:         arg.CreatedAt,
-		UpdatedAt:         arg.UpdatedAt,
-		WorkspaceID:       arg.WorkspaceID,
-		TemplateVersionID: arg.TemplateVersionID,
-		BuildNumber:       arg.BuildNumber,
-		Transition:        arg.Transition,
-		InitiatorID:       arg.InitiatorID,
-		JobID:             arg.JobID,
-		ProvisionerState:  arg.ProvisionerState,
-		Deadline:          arg.Deadline,
-		MaxDeadline:       arg.MaxDeadline,
-		Reason:            arg.Reason,
+		ID:                    arg.ID,
+		CreatedAt:             arg.CreatedAt,
+		UpdatedAt:             arg.UpdatedAt,
+		WorkspaceID:           arg.WorkspaceID,
+		TemplateVersionID:     arg.TemplateVersionID,
+		BuildNumber:           arg.BuildNumber,
+		Transition:            arg.Transition,
+		InitiatorID:           arg.InitiatorID,
+		JobID:                 arg.JobID,
+		ProvisionerState:      arg.ProvisionerState,
+		Deadline:              arg.Deadline,
+		MaxDeadline:           arg.MaxDeadline,
+		Reason:                arg.Reason,
+		ProvisionerStateKeyID: arg.ProvisionerStateKeyID,
 	}
 	q.workspaceBuilds = append(q.workspaceBuilds, workspaceBuild)
 	return nil
@@ -7491,6 +8311,27 @@ func (q *FakeQuerier) RemoveUserFromAllGroups(_ context.Context, userID uuid.UUI
 	return nil
 }
 
+func (q *FakeQuerier) RemoveUserTOTPRecoveryCode(_ context.Context, arg database.RemoveUserTOTPRecoveryCodeParams) (database.UserTOTP, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserTOTP{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, t := range q.userTOTPs {
+		if t.UserID != arg.UserID || !slices.Contains(t.HashedRecoveryCodes, arg.HashedRecoveryCode) {
+			continue
+		}
+		t.HashedRecoveryCodes = slices.DeleteFunc(slices.Clone(t.HashedRecoveryCodes), func(code string) bool {
+			return code == arg.HashedRecoveryCode
+		})
+		q.userTOTPs[i] = t
+		return t, nil
+	}
+	return database.UserTOTP{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) RevokeDBCryptKey(_ context.Context, activeKeyDigest string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -7590,6 +8431,7 @@ func (q *FakeQuerier) UpdateAPIKeyByID(_ context.Context, arg database.UpdateAPI
 		apiKey.LastUsed = arg.LastUsed
 		apiKey.ExpiresAt = arg.ExpiresAt
 		apiKey.IPAddress = arg.IPAddress
+		apiKey.UserAgent = arg.UserAgent
 		q.apiKeys[index] = apiKey
 		return nil
 	}
@@ -7766,12 +8608,14 @@ func (q *FakeQuerier) UpdateOAuth2ProviderAppByID(_ context.Context, arg databas
 	for index, app := range q.oauth2ProviderApps {
 		if app.ID == arg.ID {
 			newApp := database.OAuth2ProviderApp{
-				ID:          arg.ID,
-				CreatedAt:   app.CreatedAt,
-				UpdatedAt:   arg.UpdatedAt,
-				Name:        arg.Name,
-				Icon:        arg.Icon,
-				CallbackURL: arg.CallbackURL,
+				ID:                      arg.ID,
+				CreatedAt:               app.CreatedAt,
+				UpdatedAt:               arg.UpdatedAt,
+				Name:                    arg.Name,
+				Icon:                    arg.Icon,
+				CallbackURL:             arg.CallbackURL,
+				Public:                  arg.Public,
+				ClientCredentialsUserID: arg.ClientCredentialsUserID,
 			}
 			q.oauth2ProviderApps[index] = newApp
 			return newApp, nil
@@ -7780,6 +8624,49 @@ func (q *FakeQuerier) UpdateOAuth2ProviderAppByID(_ context.Context, arg databas
 	return database.OAuth2ProviderApp{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(_ context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, code := range q.oauth2ProviderAppDeviceCodes {
+		if code.ID != arg.ID {
+			continue
+		}
+		if code.LastPolledAt.Valid && code.LastPolledAt.Time.After(arg.PolledBefore) {
+			return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+		}
+		code.LastPolledAt = arg.LastPolledAt
+		q.oauth2ProviderAppDeviceCodes[index] = code
+		return code, nil
+	}
+	return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) UpdateOAuth2ProviderAppDeviceCodeStatus(_ context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeStatusParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return database.OAuth2ProviderAppDeviceCode{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, code := range q.oauth2ProviderAppDeviceCodes {
+		if code.ID == arg.ID && code.Status == database.OAuth2ProviderDeviceCodeStatusPending {
+			code.Status = arg.Status
+			code.UserID = arg.UserID
+			q.oauth2ProviderAppDeviceCodes[index] = code
+			return code, nil
+		}
+	}
+	return database.OAuth2ProviderAppDeviceCode{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateOAuth2ProviderAppSecretByID(_ context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -7830,6 +8717,8 @@ func (q *FakeQuerier) UpdateOrganization(_ context.Context, arg database.UpdateO
 			org.DisplayName = arg.DisplayName
 			org.Description = arg.Description
 			org.Icon = arg.Icon
+			org.AllowedWorkspaceProxyIDs = arg.AllowedWorkspaceProxyIDs
+			org.AllowedDERPRegionIDs = arg.AllowedDERPRegionIDs
 			q.organizations[i] = org
 			return org, nil
 		}
@@ -7955,6 +8844,29 @@ func (*FakeQuerier) UpdateTailnetPeerStatusByCoordinator(context.Context, databa
 	return ErrUnimplemented
 }
 
+func (q *FakeQuerier) UpdateRoleGrantRequestByID(_ context.Context, arg database.UpdateRoleGrantRequestByIDParams) (database.RoleGrantRequest, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.RoleGrantRequest{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, req := range q.roleGrantRequests {
+		if req.ID != arg.ID || req.Status != arg.FromStatus {
+			continue
+		}
+		req.Status = arg.Status
+		req.ReviewedBy = arg.ReviewedBy
+		req.ReviewedAt = arg.ReviewedAt
+		req.ExpiresAt = arg.ExpiresAt
+		req.UpdatedAt = arg.UpdatedAt
+		q.roleGrantRequests[i] = req
+		return req, nil
+	}
+	return database.RoleGrantRequest{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateTemplateACLByID(_ context.Context, arg database.UpdateTemplateACLByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -8056,6 +8968,13 @@ func (q *FakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.GroupACL = arg.GroupACL
 		tpl.AllowUserCancelWorkspaceJobs = arg.AllowUserCancelWorkspaceJobs
 		tpl.MaxPortSharingLevel = arg.MaxPortSharingLevel
+		tpl.AllowedWorkspaceProxyIDs = arg.AllowedWorkspaceProxyIDs
+		tpl.AllowedDERPRegionIDs = arg.AllowedDERPRegionIDs
+		tpl.MaxAppConnectionsPerUser = arg.MaxAppConnectionsPerUser
+		tpl.MaxAppBytesPerSecond = arg.MaxAppBytesPerSecond
+		tpl.DriftDetectionInterval = arg.DriftDetectionInterval
+		tpl.BuildTimeout = arg.BuildTimeout
+		tpl.BuildCancelGracePeriod = arg.BuildCancelGracePeriod
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -8115,6 +9034,28 @@ func (q *FakeQuerier) UpdateTemplateVersionByID(_ context.Context, arg database.
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateTemplateVersionCanaryStatusByID(_ context.Context, arg database.UpdateTemplateVersionCanaryStatusByIDParams) (database.TemplateVersionCanary, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionCanary{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, canary := range q.templateVersionCanaries {
+		if canary.ID != arg.ID || canary.Status != database.TemplateVersionCanaryStatusActive {
+			continue
+		}
+		canary.Status = arg.Status
+		canary.StatusReason = arg.StatusReason
+		canary.UpdatedAt = arg.UpdatedAt
+		canary.CompletedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		q.templateVersionCanaries[i] = canary
+		return canary, nil
+	}
+	return database.TemplateVersionCanary{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateTemplateVersionDescriptionByJobID(_ context.Context, arg database.UpdateTemplateVersionDescriptionByJobIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -8492,6 +9433,61 @@ func (q *FakeQuerier) UpdateUserStatus(_ context.Context, arg database.UpdateUse
 	return database.User{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateUserTOTPEnabledAt(_ context.Context, arg database.UpdateUserTOTPEnabledAtParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, t := range q.userTOTPs {
+		if t.UserID == arg.UserID {
+			t.EnabledAt = arg.EnabledAt
+			q.userTOTPs[i] = t
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *FakeQuerier) UpdateUserTOTPLastUsedCounter(_ context.Context, arg database.UpdateUserTOTPLastUsedCounterParams) (database.UserTOTP, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserTOTP{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, t := range q.userTOTPs {
+		if t.UserID == arg.UserID && t.LastUsedCounter < arg.LastUsedCounter {
+			t.LastUsedCounter = arg.LastUsedCounter
+			q.userTOTPs[i] = t
+			return t, nil
+		}
+	}
+	return database.UserTOTP{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) UpdateUserTOTPSecret(_ context.Context, arg database.UpdateUserTOTPSecretParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, t := range q.userTOTPs {
+		if t.UserID == arg.UserID {
+			t.Secret = arg.Secret
+			t.SecretKeyID = arg.SecretKeyID
+			q.userTOTPs[i] = t
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateWorkspace(_ context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Workspace{}, err
@@ -8757,6 +9753,7 @@ func (q *FakeQuerier) UpdateWorkspaceBuildProvisionerStateByID(_ context.Context
 			continue
 		}
 		build.ProvisionerState = arg.ProvisionerState
+		build.ProvisionerStateKeyID = arg.ProvisionerStateKeyID
 		build.UpdatedAt = arg.UpdatedAt
 		q.workspaceBuilds[idx] = build
 		return nil
@@ -8823,6 +9820,26 @@ func (q *FakeQuerier) UpdateWorkspaceDormantDeletingAt(_ context.Context, arg da
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceDriftCheckResult(_ context.Context, arg database.UpdateWorkspaceDriftCheckResultParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, check := range q.workspaceDriftChecks {
+		if check.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		check.ResultJobID = arg.ResultJobID
+		check.CheckedAt = arg.CheckedAt
+		q.workspaceDriftChecks[i] = check
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -9719,6 +10736,39 @@ TemplateUsageStatsInsertLoop:
 	return nil
 }
 
+func (q *FakeQuerier) UpsertUserTOTP(_ context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserTOTP{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if _, err := q.getUserByIDNoLock(arg.UserID); err != nil {
+		return database.UserTOTP{}, errForeignKeyConstraint
+	}
+
+	// Like the upsert, this clears enabled_at and last_used_counter.
+	totp := database.UserTOTP{
+		UserID:              arg.UserID,
+		Secret:              arg.Secret,
+		SecretKeyID:         arg.SecretKeyID,
+		HashedRecoveryCodes: arg.HashedRecoveryCodes,
+		CreatedAt:           arg.CreatedAt,
+	}
+	if totp.HashedRecoveryCodes == nil {
+		totp.HashedRecoveryCodes = []string{}
+	}
+	for i, t := range q.userTOTPs {
+		if t.UserID == arg.UserID {
+			q.userTOTPs[i] = totp
+			return totp, nil
+		}
+	}
+	q.userTOTPs = append(q.userTOTPs, totp)
+	return totp, nil
+}
+
 func (q *FakeQuerier) UpsertWorkspaceAgentPortShare(_ context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -9750,6 +10800,33 @@ func (q *FakeQuerier) UpsertWorkspaceAgentPortShare(_ context.Context, arg datab
 	return psl, nil
 }
 
+func (q *FakeQuerier) UpsertWorkspaceDriftCheck(_ context.Context, arg database.UpsertWorkspaceDriftCheckParams) (database.WorkspaceDriftCheck, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceDriftCheck{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, check := range q.workspaceDriftChecks {
+		if check.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		check.JobID = arg.JobID
+		check.ScheduledAt = arg.ScheduledAt
+		q.workspaceDriftChecks[i] = check
+		return check, nil
+	}
+
+	check := database.WorkspaceDriftCheck{
+		WorkspaceID: arg.WorkspaceID,
+		JobID:       arg.JobID,
+		ScheduledAt: arg.ScheduledAt,
+	}
+	q.workspaceDriftChecks = append(q.workspaceDriftChecks, check)
+	return check, nil
+}
+
 func (q *FakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -10350,6 +11427,7 @@ func (q *FakeQuerier) GetAuthorizedAuditLogsOffset(ctx context.Context, arg data
 			UserDeleted:             sql.NullBool{Bool: user.Deleted, Valid: userValid},
 			UserThemePreference:     sql.NullString{String: user.ThemePreference, Valid: userValid},
 			UserQuietHoursSchedule:  sql.NullString{String: user.QuietHoursSchedule, Valid: userValid},
+			UserIsServiceAccount:    sql.NullBool{Bool: user.IsServiceAccount, Valid: userValid},
 			UserStatus:              database.NullUserStatus{UserStatus: user.Status, Valid: userValid},
 			UserRoles:               user.RBACRoles,
 			Count:                   0,
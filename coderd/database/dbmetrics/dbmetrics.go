@@ -221,6 +221,13 @@ func (m metricsStore) DeleteCustomRole(ctx context.Context, arg database.DeleteC
 	return r0
 }
 
+func (m metricsStore) DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx context.Context) error {
+	start := time.Now()
+	r0 := m.s.DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx)
+	m.queryLatencies.WithLabelValues("DeleteExpiredOAuth2ProviderAppDeviceCodes").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteExternalAuthLink(ctx context.Context, arg database.DeleteExternalAuthLinkParams) error {
 	start := time.Now()
 	r0 := m.s.DeleteExternalAuthLink(ctx, arg)
@@ -277,6 +284,13 @@ func (m metricsStore) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Con
 	return r0
 }
 
+func (m metricsStore) DeleteOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteOAuth2ProviderAppDeviceCodeByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteOAuth2ProviderAppDeviceCodeByID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) error {
 	start := time.Now()
 	r0 := m.s.DeleteOAuth2ProviderAppSecretByID(ctx, id)
@@ -291,6 +305,13 @@ func (m metricsStore) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Co
 	return r0
 }
 
+func (m metricsStore) DeleteOldDERPHealthHistory(ctx context.Context) error {
+	start := time.Now()
+	r0 := m.s.DeleteOldDERPHealthHistory(ctx)
+	m.queryLatencies.WithLabelValues("DeleteOldDERPHealthHistory").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteOldNotificationMessages(ctx context.Context) error {
 	start := time.Now()
 	r0 := m.s.DeleteOldNotificationMessages(ctx)
@@ -347,6 +368,13 @@ func (m metricsStore) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt
 	return err
 }
 
+func (m metricsStore) DeleteSessionAPIKeysByUserID(ctx context.Context, arg database.DeleteSessionAPIKeysByUserIDParams) error {
+	start := time.Now()
+	r0 := m.s.DeleteSessionAPIKeysByUserID(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteSessionAPIKeysByUserID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteTailnetAgent(ctx context.Context, arg database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.DeleteTailnetAgent(ctx, arg)
@@ -382,6 +410,13 @@ func (m metricsStore) DeleteTailnetTunnel(ctx context.Context, arg database.Dele
 	return r0, r1
 }
 
+func (m metricsStore) DeleteUserTOTPByUserID(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteUserTOTPByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("DeleteUserTOTPByUserID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteWorkspaceAgentPortShare(ctx context.Context, arg database.DeleteWorkspaceAgentPortShareParams) error {
 	start := time.Now()
 	r0 := m.s.DeleteWorkspaceAgentPortShare(ctx, arg)
@@ -452,6 +487,27 @@ func (m metricsStore) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time
 	return apiKeys, err
 }
 
+func (m metricsStore) GetActiveServiceAccountCount(ctx context.Context) (int64, error) {
+	start := time.Now()
+	count, err := m.s.GetActiveServiceAccountCount(ctx)
+	m.queryLatencies.WithLabelValues("GetActiveServiceAccountCount").Observe(time.Since(start).Seconds())
+	return count, err
+}
+
+func (m metricsStore) GetActiveTemplateVersionCanaries(ctx context.Context) ([]database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canaries, err := m.s.GetActiveTemplateVersionCanaries(ctx)
+	m.queryLatencies.WithLabelValues("GetActiveTemplateVersionCanaries").Observe(time.Since(start).Seconds())
+	return canaries, err
+}
+
+func (m metricsStore) GetActiveTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.GetActiveTemplateVersionCanaryByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetActiveTemplateVersionCanaryByTemplateID").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
 func (m metricsStore) GetActiveUserCount(ctx context.Context) (int64, error) {
 	start := time.Now()
 	count, err := m.s.GetActiveUserCount(ctx)
@@ -543,6 +599,13 @@ func (m metricsStore) GetDBCryptKeys(ctx context.Context) ([]database.DBCryptKey
 	return r0, r1
 }
 
+func (m metricsStore) GetDERPHealthHistory(ctx context.Context, arg database.GetDERPHealthHistoryParams) ([]database.DERPHealthHistory, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetDERPHealthHistory(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetDERPHealthHistory").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetDERPMeshKey(ctx context.Context) (string, error) {
 	start := time.Now()
 	key, err := m.s.GetDERPMeshKey(ctx)
@@ -592,6 +655,13 @@ func (m metricsStore) GetDeploymentWorkspaceStats(ctx context.Context) (database
 	return row, err
 }
 
+func (m metricsStore) GetExpiredRoleGrantRequests(ctx context.Context, now time.Time) ([]database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetExpiredRoleGrantRequests(ctx, now)
+	m.queryLatencies.WithLabelValues("GetExpiredRoleGrantRequests").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetExternalAuthLink(ctx context.Context, arg database.GetExternalAuthLinkParams) (database.ExternalAuthLink, error) {
 	start := time.Now()
 	link, err := m.s.GetExternalAuthLink(ctx, arg)
@@ -704,6 +774,13 @@ func (m metricsStore) GetLastUpdateCheck(ctx context.Context) (string, error) {
 	return version, err
 }
 
+func (m metricsStore) GetLatestTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.GetLatestTemplateVersionCanaryByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetLatestTemplateVersionCanaryByTemplateID").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
 func (m metricsStore) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceBuild, error) {
 	start := time.Now()
 	build, err := m.s.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspaceID)
@@ -795,6 +872,27 @@ func (m metricsStore) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secr
 	return r0, r1
 }
 
+func (m metricsStore) GetOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOAuth2ProviderAppDeviceCodeByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppDeviceCodeByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetOAuth2ProviderAppDeviceCodeByPrefix(ctx context.Context, devicePrefix []byte) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOAuth2ProviderAppDeviceCodeByPrefix(ctx, devicePrefix)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppDeviceCodeByPrefix").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetOAuth2ProviderAppDeviceCodeByUserCode(ctx context.Context, userCode string) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOAuth2ProviderAppDeviceCodeByUserCode(ctx, userCode)
+	m.queryLatencies.WithLabelValues("GetOAuth2ProviderAppDeviceCodeByUserCode").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetOAuth2ProviderAppSecretByID(ctx context.Context, id uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetOAuth2ProviderAppSecretByID(ctx, id)
@@ -914,6 +1012,20 @@ func (m metricsStore) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return job, err
 }
 
+func (m metricsStore) GetProvisionerJobResourceChangesByJobID(ctx context.Context, jobID uuid.UUID) ([]database.ProvisionerJobResourceChange, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetProvisionerJobResourceChangesByJobID(ctx, jobID)
+	m.queryLatencies.WithLabelValues("GetProvisionerJobResourceChangesByJobID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID uuid.UUID) ([]database.ProvisionerJobTiming, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetProvisionerJobTimingsByJobID(ctx, jobID)
+	m.queryLatencies.WithLabelValues("GetProvisionerJobTimingsByJobID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	start := time.Now()
 	jobs, err := m.s.GetProvisionerJobsByIDs(ctx, ids)
@@ -991,6 +1103,34 @@ func (m metricsStore) GetReplicasUpdatedAfter(ctx context.Context, updatedAt tim
 	return replicas, err
 }
 
+func (m metricsStore) GetRoleGrantRequestByID(ctx context.Context, id uuid.UUID) (database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetRoleGrantRequestByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetRoleGrantRequestByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetRoleGrantRequests(ctx context.Context, arg database.GetRoleGrantRequestsParams) ([]database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetRoleGrantRequests(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetRoleGrantRequests").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetRoleGrantRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetRoleGrantRequestsByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetRoleGrantRequestsByUserID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetSessionAPIKeysByUserID(ctx context.Context, arg database.GetSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetSessionAPIKeysByUserID(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetSessionAPIKeysByUserID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetTailnetAgents(ctx context.Context, id uuid.UUID) ([]database.TailnetAgent, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetTailnetAgents(ctx, id)
@@ -1124,6 +1264,27 @@ func (m metricsStore) GetTemplateVersionByTemplateIDAndName(ctx context.Context,
 	return version, err
 }
 
+func (m metricsStore) GetTemplateVersionCanaryByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.GetTemplateVersionCanaryByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionCanaryByID").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
+func (m metricsStore) GetTemplateVersionCanaryByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.GetTemplateVersionCanaryByWorkspaceID(ctx, workspaceID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionCanaryByWorkspaceID").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
+func (m metricsStore) GetTemplateVersionCanaryStats(ctx context.Context, arg database.GetTemplateVersionCanaryStatsParams) (database.GetTemplateVersionCanaryStatsRow, error) {
+	start := time.Now()
+	r0, err := m.s.GetTemplateVersionCanaryStats(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionCanaryStats").Observe(time.Since(start).Seconds())
+	return r0, err
+}
+
 func (m metricsStore) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionParameter, error) {
 	start := time.Now()
 	parameters, err := m.s.GetTemplateVersionParameters(ctx, templateVersionID)
@@ -1180,6 +1341,13 @@ func (m metricsStore) GetTemplatesWithFilter(ctx context.Context, arg database.G
 	return templates, err
 }
 
+func (m metricsStore) GetTimedOutProvisionerJobs(ctx context.Context, arg database.GetTimedOutProvisionerJobsParams) ([]database.ProvisionerJob, error) {
+	start := time.Now()
+	jobs, err := m.s.GetTimedOutProvisionerJobs(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTimedOutProvisionerJobs").Observe(time.Since(start).Seconds())
+	return jobs, err
+}
+
 func (m metricsStore) GetUnexpiredLicenses(ctx context.Context) ([]database.License, error) {
 	start := time.Now()
 	licenses, err := m.s.GetUnexpiredLicenses(ctx)
@@ -1250,6 +1418,13 @@ func (m metricsStore) GetUserNotificationPreferences(ctx context.Context, userID
 	return r0, r1
 }
 
+func (m metricsStore) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetUserTOTPByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserTOTPByUserID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetUserWorkspaceBuildParameters(ctx context.Context, ownerID database.GetUserWorkspaceBuildParametersParams) ([]database.GetUserWorkspaceBuildParametersRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetUserWorkspaceBuildParameters(ctx, ownerID)
@@ -1425,6 +1600,13 @@ func (m metricsStore) GetWorkspaceBuildParameters(ctx context.Context, workspace
 	return params, err
 }
 
+func (m metricsStore) GetWorkspaceBuildProvisionerStates(ctx context.Context, arg database.GetWorkspaceBuildProvisionerStatesParams) ([]database.GetWorkspaceBuildProvisionerStatesRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceBuildProvisionerStates(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBuildProvisionerStates").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	start := time.Now()
 	builds, err := m.s.GetWorkspaceBuildsByWorkspaceID(ctx, arg)
@@ -1467,6 +1649,13 @@ func (m metricsStore) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspac
 	return workspace, err
 }
 
+func (m metricsStore) GetWorkspaceDriftCheckByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceDriftCheck, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceDriftCheckByWorkspaceID(ctx, workspaceID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceDriftCheckByWorkspaceID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	start := time.Now()
 	proxies, err := m.s.GetWorkspaceProxies(ctx)
@@ -1551,6 +1740,13 @@ func (m metricsStore) GetWorkspaces(ctx context.Context, arg database.GetWorkspa
 	return workspaces, err
 }
 
+func (m metricsStore) GetWorkspacesDueForDriftCheck(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspacesDueForDriftCheck(ctx, now)
+	m.queryLatencies.WithLabelValues("GetWorkspacesDueForDriftCheck").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
 	start := time.Now()
 	workspaces, err := m.s.GetWorkspacesEligibleForTransition(ctx, now)
@@ -1593,6 +1789,13 @@ func (m metricsStore) InsertDBCryptKey(ctx context.Context, arg database.InsertD
 	return r0
 }
 
+func (m metricsStore) InsertDERPHealthHistory(ctx context.Context, arg database.InsertDERPHealthHistoryParams) error {
+	start := time.Now()
+	r0 := m.s.InsertDERPHealthHistory(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertDERPHealthHistory").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) InsertDERPMeshKey(ctx context.Context, value string) error {
 	start := time.Now()
 	err := m.s.InsertDERPMeshKey(ctx, value)
@@ -1670,6 +1873,13 @@ func (m metricsStore) InsertOAuth2ProviderAppCode(ctx context.Context, arg datab
 	return r0, r1
 }
 
+func (m metricsStore) InsertOAuth2ProviderAppDeviceCode(ctx context.Context, arg database.InsertOAuth2ProviderAppDeviceCodeParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertOAuth2ProviderAppDeviceCode(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertOAuth2ProviderAppDeviceCode").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertOAuth2ProviderAppSecret(ctx context.Context, arg database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertOAuth2ProviderAppSecret(ctx, arg)
@@ -1712,6 +1922,13 @@ func (m metricsStore) InsertProvisionerJobLogs(ctx context.Context, arg database
 	return logs, err
 }
 
+func (m metricsStore) InsertProvisionerJobResourceChanges(ctx context.Context, arg database.InsertProvisionerJobResourceChangesParams) ([]database.ProvisionerJobResourceChange, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertProvisionerJobResourceChanges(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertProvisionerJobResourceChanges").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertProvisionerJobTimings(ctx context.Context, arg database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertProvisionerJobTimings(ctx, arg)
@@ -1733,6 +1950,13 @@ func (m metricsStore) InsertReplica(ctx context.Context, arg database.InsertRepl
 	return replica, err
 }
 
+func (m metricsStore) InsertRoleGrantRequest(ctx context.Context, arg database.InsertRoleGrantRequestParams) (database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertRoleGrantRequest(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertRoleGrantRequest").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) error {
 	start := time.Now()
 	err := m.s.InsertTemplate(ctx, arg)
@@ -1747,6 +1971,13 @@ func (m metricsStore) InsertTemplateVersion(ctx context.Context, arg database.In
 	return err
 }
 
+func (m metricsStore) InsertTemplateVersionCanary(ctx context.Context, arg database.InsertTemplateVersionCanaryParams) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.InsertTemplateVersionCanary(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionCanary").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
 func (m metricsStore) InsertTemplateVersionParameter(ctx context.Context, arg database.InsertTemplateVersionParameterParams) (database.TemplateVersionParameter, error) {
 	start := time.Now()
 	parameter, err := m.s.InsertTemplateVersionParameter(ctx, arg)
@@ -1929,6 +2160,13 @@ func (m metricsStore) RemoveUserFromAllGroups(ctx context.Context, userID uuid.U
 	return r0
 }
 
+func (m metricsStore) RemoveUserTOTPRecoveryCode(ctx context.Context, arg database.RemoveUserTOTPRecoveryCodeParams) (database.UserTOTP, error) {
+	start := time.Now()
+	r0, r1 := m.s.RemoveUserTOTPRecoveryCode(ctx, arg)
+	m.queryLatencies.WithLabelValues("RemoveUserTOTPRecoveryCode").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) RevokeDBCryptKey(ctx context.Context, activeKeyDigest string) error {
 	start := time.Now()
 	r0 := m.s.RevokeDBCryptKey(ctx, activeKeyDigest)
@@ -2020,6 +2258,20 @@ func (m metricsStore) UpdateOAuth2ProviderAppByID(ctx context.Context, arg datab
 	return r0, r1
 }
 
+func (m metricsStore) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(ctx context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2ProviderAppDeviceCodeLastPolledAt").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpdateOAuth2ProviderAppDeviceCodeStatus(ctx context.Context, arg database.UpdateOAuth2ProviderAppDeviceCodeStatusParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateOAuth2ProviderAppDeviceCodeStatus(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOAuth2ProviderAppDeviceCodeStatus").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpdateOAuth2ProviderAppSecretByID(ctx context.Context, arg database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
 	start := time.Now()
 	r0, r1 := m.s.UpdateOAuth2ProviderAppSecretByID(ctx, arg)
@@ -2069,6 +2321,13 @@ func (m metricsStore) UpdateReplica(ctx context.Context, arg database.UpdateRepl
 	return replica, err
 }
 
+func (m metricsStore) UpdateRoleGrantRequestByID(ctx context.Context, arg database.UpdateRoleGrantRequestByIDParams) (database.RoleGrantRequest, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateRoleGrantRequestByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateRoleGrantRequestByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpdateTailnetPeerStatusByCoordinator(ctx context.Context, arg database.UpdateTailnetPeerStatusByCoordinatorParams) error {
 	start := time.Now()
 	r0 := m.s.UpdateTailnetPeerStatusByCoordinator(ctx, arg)
@@ -2125,6 +2384,13 @@ func (m metricsStore) UpdateTemplateVersionByID(ctx context.Context, arg databas
 	return err
 }
 
+func (m metricsStore) UpdateTemplateVersionCanaryStatusByID(ctx context.Context, arg database.UpdateTemplateVersionCanaryStatusByIDParams) (database.TemplateVersionCanary, error) {
+	start := time.Now()
+	canary, err := m.s.UpdateTemplateVersionCanaryStatusByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateVersionCanaryStatusByID").Observe(time.Since(start).Seconds())
+	return canary, err
+}
+
 func (m metricsStore) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg database.UpdateTemplateVersionDescriptionByJobIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateTemplateVersionDescriptionByJobID(ctx, arg)
@@ -2237,6 +2503,27 @@ func (m metricsStore) UpdateUserStatus(ctx context.Context, arg database.UpdateU
 	return user, err
 }
 
+func (m metricsStore) UpdateUserTOTPEnabledAt(ctx context.Context, arg database.UpdateUserTOTPEnabledAtParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateUserTOTPEnabledAt(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserTOTPEnabledAt").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) UpdateUserTOTPLastUsedCounter(ctx context.Context, arg database.UpdateUserTOTPLastUsedCounterParams) (database.UserTOTP, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateUserTOTPLastUsedCounter(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserTOTPLastUsedCounter").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpdateUserTOTPSecret(ctx context.Context, arg database.UpdateUserTOTPSecretParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateUserTOTPSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserTOTPSecret").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) UpdateWorkspace(ctx context.Context, arg database.UpdateWorkspaceParams) (database.Workspace, error) {
 	start := time.Now()
 	workspace, err := m.s.UpdateWorkspace(ctx, arg)
@@ -2335,6 +2622,13 @@ func (m metricsStore) UpdateWorkspaceDormantDeletingAt(ctx context.Context, arg
 	return ws, r0
 }
 
+func (m metricsStore) UpdateWorkspaceDriftCheckResult(ctx context.Context, arg database.UpdateWorkspaceDriftCheckResultParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateWorkspaceDriftCheckResult(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceDriftCheckResult").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceLastUsedAt(ctx, arg)
@@ -2503,6 +2797,13 @@ func (m metricsStore) UpsertTemplateUsageStats(ctx context.Context) error {
 	return r0
 }
 
+func (m metricsStore) UpsertUserTOTP(ctx context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertUserTOTP(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertUserTOTP").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpsertWorkspaceAgentPortShare(ctx context.Context, arg database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	start := time.Now()
 	r0, r1 := m.s.UpsertWorkspaceAgentPortShare(ctx, arg)
@@ -2510,6 +2811,13 @@ func (m metricsStore) UpsertWorkspaceAgentPortShare(ctx context.Context, arg dat
 	return r0, r1
 }
 
+func (m metricsStore) UpsertWorkspaceDriftCheck(ctx context.Context, arg database.UpsertWorkspaceDriftCheckParams) (database.WorkspaceDriftCheck, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertWorkspaceDriftCheck(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceDriftCheck").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	start := time.Now()
 	templates, err := m.s.GetAuthorizedTemplates(ctx, arg, prepared)
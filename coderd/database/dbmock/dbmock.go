@@ -331,6 +331,20 @@ func (mr *MockStoreMockRecorder) DeleteCustomRole(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomRole", reflect.TypeOf((*MockStore)(nil).DeleteCustomRole), arg0, arg1)
 }
 
+// DeleteExpiredOAuth2ProviderAppDeviceCodes mocks base method.
+func (m *MockStore) DeleteExpiredOAuth2ProviderAppDeviceCodes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredOAuth2ProviderAppDeviceCodes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredOAuth2ProviderAppDeviceCodes indicates an expected call of DeleteExpiredOAuth2ProviderAppDeviceCodes.
+func (mr *MockStoreMockRecorder) DeleteExpiredOAuth2ProviderAppDeviceCodes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredOAuth2ProviderAppDeviceCodes", reflect.TypeOf((*MockStore)(nil).DeleteExpiredOAuth2ProviderAppDeviceCodes), arg0)
+}
+
 // DeleteExternalAuthLink mocks base method.
 func (m *MockStore) DeleteExternalAuthLink(arg0 context.Context, arg1 database.DeleteExternalAuthLinkParams) error {
 	m.ctrl.T.Helper()
@@ -444,6 +458,20 @@ func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppCodesByAppAndUserID(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppCodesByAppAndUserID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppCodesByAppAndUserID), arg0, arg1)
 }
 
+// DeleteOAuth2ProviderAppDeviceCodeByID mocks base method.
+func (m *MockStore) DeleteOAuth2ProviderAppDeviceCodeByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOAuth2ProviderAppDeviceCodeByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOAuth2ProviderAppDeviceCodeByID indicates an expected call of DeleteOAuth2ProviderAppDeviceCodeByID.
+func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppDeviceCodeByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppDeviceCodeByID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppDeviceCodeByID), arg0, arg1)
+}
+
 // DeleteOAuth2ProviderAppSecretByID mocks base method.
 func (m *MockStore) DeleteOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -472,6 +500,20 @@ func (mr *MockStoreMockRecorder) DeleteOAuth2ProviderAppTokensByAppAndUserID(arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOAuth2ProviderAppTokensByAppAndUserID", reflect.TypeOf((*MockStore)(nil).DeleteOAuth2ProviderAppTokensByAppAndUserID), arg0, arg1)
 }
 
+// DeleteOldDERPHealthHistory mocks base method.
+func (m *MockStore) DeleteOldDERPHealthHistory(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldDERPHealthHistory", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldDERPHealthHistory indicates an expected call of DeleteOldDERPHealthHistory.
+func (mr *MockStoreMockRecorder) DeleteOldDERPHealthHistory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldDERPHealthHistory", reflect.TypeOf((*MockStore)(nil).DeleteOldDERPHealthHistory), arg0)
+}
+
 // DeleteOldNotificationMessages mocks base method.
 func (m *MockStore) DeleteOldNotificationMessages(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -584,6 +626,20 @@ func (mr *MockStoreMockRecorder) DeleteReplicasUpdatedBefore(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplicasUpdatedBefore", reflect.TypeOf((*MockStore)(nil).DeleteReplicasUpdatedBefore), arg0, arg1)
 }
 
+// DeleteSessionAPIKeysByUserID mocks base method.
+func (m *MockStore) DeleteSessionAPIKeysByUserID(arg0 context.Context, arg1 database.DeleteSessionAPIKeysByUserIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionAPIKeysByUserID indicates an expected call of DeleteSessionAPIKeysByUserID.
+func (mr *MockStoreMockRecorder) DeleteSessionAPIKeysByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteSessionAPIKeysByUserID), arg0, arg1)
+}
+
 // DeleteTailnetAgent mocks base method.
 func (m *MockStore) DeleteTailnetAgent(arg0 context.Context, arg1 database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	m.ctrl.T.Helper()
@@ -658,6 +714,20 @@ func (mr *MockStoreMockRecorder) DeleteTailnetTunnel(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetTunnel", reflect.TypeOf((*MockStore)(nil).DeleteTailnetTunnel), arg0, arg1)
 }
 
+// DeleteUserTOTPByUserID mocks base method.
+func (m *MockStore) DeleteUserTOTPByUserID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTOTPByUserID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTOTPByUserID indicates an expected call of DeleteUserTOTPByUserID.
+func (mr *MockStoreMockRecorder) DeleteUserTOTPByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTOTPByUserID", reflect.TypeOf((*MockStore)(nil).DeleteUserTOTPByUserID), arg0, arg1)
+}
+
 // DeleteWorkspaceAgentPortShare mocks base method.
 func (m *MockStore) DeleteWorkspaceAgentPortShare(arg0 context.Context, arg1 database.DeleteWorkspaceAgentPortShareParams) error {
 	m.ctrl.T.Helper()
@@ -804,6 +874,51 @@ func (mr *MockStoreMockRecorder) GetAPIKeysLastUsedAfter(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysLastUsedAfter", reflect.TypeOf((*MockStore)(nil).GetAPIKeysLastUsedAfter), arg0, arg1)
 }
 
+// GetActiveServiceAccountCount mocks base method.
+func (m *MockStore) GetActiveServiceAccountCount(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveServiceAccountCount", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveServiceAccountCount indicates an expected call of GetActiveServiceAccountCount.
+func (mr *MockStoreMockRecorder) GetActiveServiceAccountCount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveServiceAccountCount", reflect.TypeOf((*MockStore)(nil).GetActiveServiceAccountCount), arg0)
+}
+
+// GetActiveTemplateVersionCanaries mocks base method.
+func (m *MockStore) GetActiveTemplateVersionCanaries(arg0 context.Context) ([]database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveTemplateVersionCanaries", arg0)
+	ret0, _ := ret[0].([]database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveTemplateVersionCanaries indicates an expected call of GetActiveTemplateVersionCanaries.
+func (mr *MockStoreMockRecorder) GetActiveTemplateVersionCanaries(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveTemplateVersionCanaries", reflect.TypeOf((*MockStore)(nil).GetActiveTemplateVersionCanaries), arg0)
+}
+
+// GetActiveTemplateVersionCanaryByTemplateID mocks base method.
+func (m *MockStore) GetActiveTemplateVersionCanaryByTemplateID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveTemplateVersionCanaryByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveTemplateVersionCanaryByTemplateID indicates an expected call of GetActiveTemplateVersionCanaryByTemplateID.
+func (mr *MockStoreMockRecorder) GetActiveTemplateVersionCanaryByTemplateID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveTemplateVersionCanaryByTemplateID", reflect.TypeOf((*MockStore)(nil).GetActiveTemplateVersionCanaryByTemplateID), arg0, arg1)
+}
+
 // GetActiveUserCount mocks base method.
 func (m *MockStore) GetActiveUserCount(arg0 context.Context) (int64, error) {
 	m.ctrl.T.Helper()
@@ -1059,6 +1174,21 @@ func (mr *MockStoreMockRecorder) GetDBCryptKeys(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDBCryptKeys", reflect.TypeOf((*MockStore)(nil).GetDBCryptKeys), arg0)
 }
 
+// GetDERPHealthHistory mocks base method.
+func (m *MockStore) GetDERPHealthHistory(arg0 context.Context, arg1 database.GetDERPHealthHistoryParams) ([]database.DERPHealthHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDERPHealthHistory", arg0, arg1)
+	ret0, _ := ret[0].([]database.DERPHealthHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDERPHealthHistory indicates an expected call of GetDERPHealthHistory.
+func (mr *MockStoreMockRecorder) GetDERPHealthHistory(arg0 any, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDERPHealthHistory", reflect.TypeOf((*MockStore)(nil).GetDERPHealthHistory), arg0, arg1)
+}
+
 // GetDERPMeshKey mocks base method.
 func (m *MockStore) GetDERPMeshKey(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -1164,6 +1294,21 @@ func (mr *MockStoreMockRecorder) GetDeploymentWorkspaceStats(arg0 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeploymentWorkspaceStats", reflect.TypeOf((*MockStore)(nil).GetDeploymentWorkspaceStats), arg0)
 }
 
+// GetExpiredRoleGrantRequests mocks base method.
+func (m *MockStore) GetExpiredRoleGrantRequests(arg0 context.Context, arg1 time.Time) ([]database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredRoleGrantRequests", arg0, arg1)
+	ret0, _ := ret[0].([]database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredRoleGrantRequests indicates an expected call of GetExpiredRoleGrantRequests.
+func (mr *MockStoreMockRecorder) GetExpiredRoleGrantRequests(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredRoleGrantRequests", reflect.TypeOf((*MockStore)(nil).GetExpiredRoleGrantRequests), arg0, arg1)
+}
+
 // GetExternalAuthLink mocks base method.
 func (m *MockStore) GetExternalAuthLink(arg0 context.Context, arg1 database.GetExternalAuthLinkParams) (database.ExternalAuthLink, error) {
 	m.ctrl.T.Helper()
@@ -1404,6 +1549,21 @@ func (mr *MockStoreMockRecorder) GetLastUpdateCheck(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastUpdateCheck", reflect.TypeOf((*MockStore)(nil).GetLastUpdateCheck), arg0)
 }
 
+// GetLatestTemplateVersionCanaryByTemplateID mocks base method.
+func (m *MockStore) GetLatestTemplateVersionCanaryByTemplateID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestTemplateVersionCanaryByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestTemplateVersionCanaryByTemplateID indicates an expected call of GetLatestTemplateVersionCanaryByTemplateID.
+func (mr *MockStoreMockRecorder) GetLatestTemplateVersionCanaryByTemplateID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestTemplateVersionCanaryByTemplateID", reflect.TypeOf((*MockStore)(nil).GetLatestTemplateVersionCanaryByTemplateID), arg0, arg1)
+}
+
 // GetLatestWorkspaceBuildByWorkspaceID mocks base method.
 func (m *MockStore) GetLatestWorkspaceBuildByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
@@ -1599,6 +1759,51 @@ func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppCodeByPrefix(arg0, arg1 any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppCodeByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppCodeByPrefix), arg0, arg1)
 }
 
+// GetOAuth2ProviderAppDeviceCodeByID mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppDeviceCodeByID(arg0 context.Context, arg1 uuid.UUID) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppDeviceCodeByID", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppDeviceCodeByID indicates an expected call of GetOAuth2ProviderAppDeviceCodeByID.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppDeviceCodeByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppDeviceCodeByID", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppDeviceCodeByID), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppDeviceCodeByPrefix mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppDeviceCodeByPrefix(arg0 context.Context, arg1 []byte) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppDeviceCodeByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppDeviceCodeByPrefix indicates an expected call of GetOAuth2ProviderAppDeviceCodeByPrefix.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppDeviceCodeByPrefix(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppDeviceCodeByPrefix", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppDeviceCodeByPrefix), arg0, arg1)
+}
+
+// GetOAuth2ProviderAppDeviceCodeByUserCode mocks base method.
+func (m *MockStore) GetOAuth2ProviderAppDeviceCodeByUserCode(arg0 context.Context, arg1 string) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOAuth2ProviderAppDeviceCodeByUserCode", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOAuth2ProviderAppDeviceCodeByUserCode indicates an expected call of GetOAuth2ProviderAppDeviceCodeByUserCode.
+func (mr *MockStoreMockRecorder) GetOAuth2ProviderAppDeviceCodeByUserCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuth2ProviderAppDeviceCodeByUserCode", reflect.TypeOf((*MockStore)(nil).GetOAuth2ProviderAppDeviceCodeByUserCode), arg0, arg1)
+}
+
 // GetOAuth2ProviderAppSecretByID mocks base method.
 func (m *MockStore) GetOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 uuid.UUID) (database.OAuth2ProviderAppSecret, error) {
 	m.ctrl.T.Helper()
@@ -1854,6 +2059,36 @@ func (mr *MockStoreMockRecorder) GetProvisionerJobByID(arg0, arg1 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobByID", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobByID), arg0, arg1)
 }
 
+// GetProvisionerJobResourceChangesByJobID mocks base method.
+func (m *MockStore) GetProvisionerJobResourceChangesByJobID(arg0 context.Context, arg1 uuid.UUID) ([]database.ProvisionerJobResourceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerJobResourceChangesByJobID", arg0, arg1)
+	ret0, _ := ret[0].([]database.ProvisionerJobResourceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerJobResourceChangesByJobID indicates an expected call of GetProvisionerJobResourceChangesByJobID.
+func (mr *MockStoreMockRecorder) GetProvisionerJobResourceChangesByJobID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobResourceChangesByJobID", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobResourceChangesByJobID), arg0, arg1)
+}
+
+// GetProvisionerJobTimingsByJobID mocks base method.
+func (m *MockStore) GetProvisionerJobTimingsByJobID(arg0 context.Context, arg1 uuid.UUID) ([]database.ProvisionerJobTiming, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerJobTimingsByJobID", arg0, arg1)
+	ret0, _ := ret[0].([]database.ProvisionerJobTiming)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerJobTimingsByJobID indicates an expected call of GetProvisionerJobTimingsByJobID.
+func (mr *MockStoreMockRecorder) GetProvisionerJobTimingsByJobID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobTimingsByJobID", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobTimingsByJobID), arg0, arg1)
+}
+
 // GetProvisionerJobsByIDs mocks base method.
 func (m *MockStore) GetProvisionerJobsByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.ProvisionerJob, error) {
 	m.ctrl.T.Helper()
@@ -2019,6 +2254,66 @@ func (mr *MockStoreMockRecorder) GetReplicasUpdatedAfter(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicasUpdatedAfter", reflect.TypeOf((*MockStore)(nil).GetReplicasUpdatedAfter), arg0, arg1)
 }
 
+// GetRoleGrantRequestByID mocks base method.
+func (m *MockStore) GetRoleGrantRequestByID(arg0 context.Context, arg1 uuid.UUID) (database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleGrantRequestByID", arg0, arg1)
+	ret0, _ := ret[0].(database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleGrantRequestByID indicates an expected call of GetRoleGrantRequestByID.
+func (mr *MockStoreMockRecorder) GetRoleGrantRequestByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleGrantRequestByID", reflect.TypeOf((*MockStore)(nil).GetRoleGrantRequestByID), arg0, arg1)
+}
+
+// GetRoleGrantRequests mocks base method.
+func (m *MockStore) GetRoleGrantRequests(arg0 context.Context, arg1 database.GetRoleGrantRequestsParams) ([]database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleGrantRequests", arg0, arg1)
+	ret0, _ := ret[0].([]database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleGrantRequests indicates an expected call of GetRoleGrantRequests.
+func (mr *MockStoreMockRecorder) GetRoleGrantRequests(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleGrantRequests", reflect.TypeOf((*MockStore)(nil).GetRoleGrantRequests), arg0, arg1)
+}
+
+// GetRoleGrantRequestsByUserID mocks base method.
+func (m *MockStore) GetRoleGrantRequestsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleGrantRequestsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleGrantRequestsByUserID indicates an expected call of GetRoleGrantRequestsByUserID.
+func (mr *MockStoreMockRecorder) GetRoleGrantRequestsByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleGrantRequestsByUserID", reflect.TypeOf((*MockStore)(nil).GetRoleGrantRequestsByUserID), arg0, arg1)
+}
+
+// GetSessionAPIKeysByUserID mocks base method.
+func (m *MockStore) GetSessionAPIKeysByUserID(arg0 context.Context, arg1 database.GetSessionAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionAPIKeysByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionAPIKeysByUserID indicates an expected call of GetSessionAPIKeysByUserID.
+func (mr *MockStoreMockRecorder) GetSessionAPIKeysByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).GetSessionAPIKeysByUserID), arg0, arg1)
+}
+
 // GetTailnetAgents mocks base method.
 func (m *MockStore) GetTailnetAgents(arg0 context.Context, arg1 uuid.UUID) ([]database.TailnetAgent, error) {
 	m.ctrl.T.Helper()
@@ -2334,6 +2629,51 @@ func (mr *MockStoreMockRecorder) GetTemplateVersionByTemplateIDAndName(arg0, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionByTemplateIDAndName", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionByTemplateIDAndName), arg0, arg1)
 }
 
+// GetTemplateVersionCanaryByID mocks base method.
+func (m *MockStore) GetTemplateVersionCanaryByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionCanaryByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionCanaryByID indicates an expected call of GetTemplateVersionCanaryByID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionCanaryByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionCanaryByID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionCanaryByID), arg0, arg1)
+}
+
+// GetTemplateVersionCanaryByWorkspaceID mocks base method.
+func (m *MockStore) GetTemplateVersionCanaryByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionCanaryByWorkspaceID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionCanaryByWorkspaceID indicates an expected call of GetTemplateVersionCanaryByWorkspaceID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionCanaryByWorkspaceID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionCanaryByWorkspaceID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionCanaryByWorkspaceID), arg0, arg1)
+}
+
+// GetTemplateVersionCanaryStats mocks base method.
+func (m *MockStore) GetTemplateVersionCanaryStats(arg0 context.Context, arg1 database.GetTemplateVersionCanaryStatsParams) (database.GetTemplateVersionCanaryStatsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionCanaryStats", arg0, arg1)
+	ret0, _ := ret[0].(database.GetTemplateVersionCanaryStatsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionCanaryStats indicates an expected call of GetTemplateVersionCanaryStats.
+func (mr *MockStoreMockRecorder) GetTemplateVersionCanaryStats(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionCanaryStats", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionCanaryStats), arg0, arg1)
+}
+
 // GetTemplateVersionParameters mocks base method.
 func (m *MockStore) GetTemplateVersionParameters(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionParameter, error) {
 	m.ctrl.T.Helper()
@@ -2454,6 +2794,21 @@ func (mr *MockStoreMockRecorder) GetTemplatesWithFilter(arg0, arg1 any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatesWithFilter", reflect.TypeOf((*MockStore)(nil).GetTemplatesWithFilter), arg0, arg1)
 }
 
+// GetTimedOutProvisionerJobs mocks base method.
+func (m *MockStore) GetTimedOutProvisionerJobs(arg0 context.Context, arg1 database.GetTimedOutProvisionerJobsParams) ([]database.ProvisionerJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimedOutProvisionerJobs", arg0, arg1)
+	ret0, _ := ret[0].([]database.ProvisionerJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimedOutProvisionerJobs indicates an expected call of GetTimedOutProvisionerJobs.
+func (mr *MockStoreMockRecorder) GetTimedOutProvisionerJobs(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimedOutProvisionerJobs", reflect.TypeOf((*MockStore)(nil).GetTimedOutProvisionerJobs), arg0, arg1)
+}
+
 // GetUnexpiredLicenses mocks base method.
 func (m *MockStore) GetUnexpiredLicenses(arg0 context.Context) ([]database.License, error) {
 	m.ctrl.T.Helper()
@@ -2604,6 +2959,21 @@ func (mr *MockStoreMockRecorder) GetUserNotificationPreferences(arg0, arg1 any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserNotificationPreferences", reflect.TypeOf((*MockStore)(nil).GetUserNotificationPreferences), arg0, arg1)
 }
 
+// GetUserTOTPByUserID mocks base method.
+func (m *MockStore) GetUserTOTPByUserID(arg0 context.Context, arg1 uuid.UUID) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTOTPByUserID", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTOTPByUserID indicates an expected call of GetUserTOTPByUserID.
+func (mr *MockStoreMockRecorder) GetUserTOTPByUserID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTOTPByUserID", reflect.TypeOf((*MockStore)(nil).GetUserTOTPByUserID), arg0, arg1)
+}
+
 // GetUserWorkspaceBuildParameters mocks base method.
 func (m *MockStore) GetUserWorkspaceBuildParameters(arg0 context.Context, arg1 database.GetUserWorkspaceBuildParametersParams) ([]database.GetUserWorkspaceBuildParametersRow, error) {
 	m.ctrl.T.Helper()
@@ -2979,6 +3349,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceBuildParameters(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildParameters", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildParameters), arg0, arg1)
 }
 
+// GetWorkspaceBuildProvisionerStates mocks base method.
+func (m *MockStore) GetWorkspaceBuildProvisionerStates(arg0 context.Context, arg1 database.GetWorkspaceBuildProvisionerStatesParams) ([]database.GetWorkspaceBuildProvisionerStatesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBuildProvisionerStates", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetWorkspaceBuildProvisionerStatesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBuildProvisionerStates indicates an expected call of GetWorkspaceBuildProvisionerStates.
+func (mr *MockStoreMockRecorder) GetWorkspaceBuildProvisionerStates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBuildProvisionerStates", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBuildProvisionerStates), arg0, arg1)
+}
+
 // GetWorkspaceBuildsByWorkspaceID mocks base method.
 func (m *MockStore) GetWorkspaceBuildsByWorkspaceID(arg0 context.Context, arg1 database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
@@ -3069,6 +3454,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceByWorkspaceAppID(arg0, arg1 any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceByWorkspaceAppID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceByWorkspaceAppID), arg0, arg1)
 }
 
+// GetWorkspaceDriftCheckByWorkspaceID mocks base method.
+func (m *MockStore) GetWorkspaceDriftCheckByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceDriftCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceDriftCheckByWorkspaceID", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceDriftCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceDriftCheckByWorkspaceID indicates an expected call of GetWorkspaceDriftCheckByWorkspaceID.
+func (mr *MockStoreMockRecorder) GetWorkspaceDriftCheckByWorkspaceID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceDriftCheckByWorkspaceID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceDriftCheckByWorkspaceID), arg0, arg1)
+}
+
 // GetWorkspaceProxies mocks base method.
 func (m *MockStore) GetWorkspaceProxies(arg0 context.Context) ([]database.WorkspaceProxy, error) {
 	m.ctrl.T.Helper()
@@ -3249,6 +3649,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaces(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaces", reflect.TypeOf((*MockStore)(nil).GetWorkspaces), arg0, arg1)
 }
 
+// GetWorkspacesDueForDriftCheck mocks base method.
+func (m *MockStore) GetWorkspacesDueForDriftCheck(arg0 context.Context, arg1 time.Time) ([]database.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspacesDueForDriftCheck", arg0, arg1)
+	ret0, _ := ret[0].([]database.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspacesDueForDriftCheck indicates an expected call of GetWorkspacesDueForDriftCheck.
+func (mr *MockStoreMockRecorder) GetWorkspacesDueForDriftCheck(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacesDueForDriftCheck", reflect.TypeOf((*MockStore)(nil).GetWorkspacesDueForDriftCheck), arg0, arg1)
+}
+
 // GetWorkspacesEligibleForTransition mocks base method.
 func (m *MockStore) GetWorkspacesEligibleForTransition(arg0 context.Context, arg1 time.Time) ([]database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -3352,6 +3767,20 @@ func (mr *MockStoreMockRecorder) InsertDBCryptKey(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDBCryptKey", reflect.TypeOf((*MockStore)(nil).InsertDBCryptKey), arg0, arg1)
 }
 
+// InsertDERPHealthHistory mocks base method.
+func (m *MockStore) InsertDERPHealthHistory(arg0 context.Context, arg1 database.InsertDERPHealthHistoryParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDERPHealthHistory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertDERPHealthHistory indicates an expected call of InsertDERPHealthHistory.
+func (mr *MockStoreMockRecorder) InsertDERPHealthHistory(arg0 any, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDERPHealthHistory", reflect.TypeOf((*MockStore)(nil).InsertDERPHealthHistory), arg0, arg1)
+}
+
 // InsertDERPMeshKey mocks base method.
 func (m *MockStore) InsertDERPMeshKey(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -3514,6 +3943,21 @@ func (mr *MockStoreMockRecorder) InsertOAuth2ProviderAppCode(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderAppCode", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderAppCode), arg0, arg1)
 }
 
+// InsertOAuth2ProviderAppDeviceCode mocks base method.
+func (m *MockStore) InsertOAuth2ProviderAppDeviceCode(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppDeviceCodeParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOAuth2ProviderAppDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOAuth2ProviderAppDeviceCode indicates an expected call of InsertOAuth2ProviderAppDeviceCode.
+func (mr *MockStoreMockRecorder) InsertOAuth2ProviderAppDeviceCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOAuth2ProviderAppDeviceCode", reflect.TypeOf((*MockStore)(nil).InsertOAuth2ProviderAppDeviceCode), arg0, arg1)
+}
+
 // InsertOAuth2ProviderAppSecret mocks base method.
 func (m *MockStore) InsertOAuth2ProviderAppSecret(arg0 context.Context, arg1 database.InsertOAuth2ProviderAppSecretParams) (database.OAuth2ProviderAppSecret, error) {
 	m.ctrl.T.Helper()
@@ -3604,6 +4048,21 @@ func (mr *MockStoreMockRecorder) InsertProvisionerJobLogs(arg0, arg1 any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProvisionerJobLogs", reflect.TypeOf((*MockStore)(nil).InsertProvisionerJobLogs), arg0, arg1)
 }
 
+// InsertProvisionerJobResourceChanges mocks base method.
+func (m *MockStore) InsertProvisionerJobResourceChanges(arg0 context.Context, arg1 database.InsertProvisionerJobResourceChangesParams) ([]database.ProvisionerJobResourceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertProvisionerJobResourceChanges", arg0, arg1)
+	ret0, _ := ret[0].([]database.ProvisionerJobResourceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertProvisionerJobResourceChanges indicates an expected call of InsertProvisionerJobResourceChanges.
+func (mr *MockStoreMockRecorder) InsertProvisionerJobResourceChanges(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProvisionerJobResourceChanges", reflect.TypeOf((*MockStore)(nil).InsertProvisionerJobResourceChanges), arg0, arg1)
+}
+
 // InsertProvisionerJobTimings mocks base method.
 func (m *MockStore) InsertProvisionerJobTimings(arg0 context.Context, arg1 database.InsertProvisionerJobTimingsParams) ([]database.ProvisionerJobTiming, error) {
 	m.ctrl.T.Helper()
@@ -3649,6 +4108,21 @@ func (mr *MockStoreMockRecorder) InsertReplica(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReplica", reflect.TypeOf((*MockStore)(nil).InsertReplica), arg0, arg1)
 }
 
+// InsertRoleGrantRequest mocks base method.
+func (m *MockStore) InsertRoleGrantRequest(arg0 context.Context, arg1 database.InsertRoleGrantRequestParams) (database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRoleGrantRequest", arg0, arg1)
+	ret0, _ := ret[0].(database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRoleGrantRequest indicates an expected call of InsertRoleGrantRequest.
+func (mr *MockStoreMockRecorder) InsertRoleGrantRequest(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoleGrantRequest", reflect.TypeOf((*MockStore)(nil).InsertRoleGrantRequest), arg0, arg1)
+}
+
 // InsertTemplate mocks base method.
 func (m *MockStore) InsertTemplate(arg0 context.Context, arg1 database.InsertTemplateParams) error {
 	m.ctrl.T.Helper()
@@ -3677,6 +4151,21 @@ func (mr *MockStoreMockRecorder) InsertTemplateVersion(arg0, arg1 any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersion", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersion), arg0, arg1)
 }
 
+// InsertTemplateVersionCanary mocks base method.
+func (m *MockStore) InsertTemplateVersionCanary(arg0 context.Context, arg1 database.InsertTemplateVersionCanaryParams) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionCanary", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateVersionCanary indicates an expected call of InsertTemplateVersionCanary.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionCanary(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionCanary", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionCanary), arg0, arg1)
+}
+
 // InsertTemplateVersionParameter mocks base method.
 func (m *MockStore) InsertTemplateVersionParameter(arg0 context.Context, arg1 database.InsertTemplateVersionParameterParams) (database.TemplateVersionParameter, error) {
 	m.ctrl.T.Helper()
@@ -4074,6 +4563,21 @@ func (mr *MockStoreMockRecorder) RemoveUserFromAllGroups(arg0, arg1 any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserFromAllGroups", reflect.TypeOf((*MockStore)(nil).RemoveUserFromAllGroups), arg0, arg1)
 }
 
+// RemoveUserTOTPRecoveryCode mocks base method.
+func (m *MockStore) RemoveUserTOTPRecoveryCode(arg0 context.Context, arg1 database.RemoveUserTOTPRecoveryCodeParams) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserTOTPRecoveryCode", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveUserTOTPRecoveryCode indicates an expected call of RemoveUserTOTPRecoveryCode.
+func (mr *MockStoreMockRecorder) RemoveUserTOTPRecoveryCode(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserTOTPRecoveryCode", reflect.TypeOf((*MockStore)(nil).RemoveUserTOTPRecoveryCode), arg0, arg1)
+}
+
 // RevokeDBCryptKey mocks base method.
 func (m *MockStore) RevokeDBCryptKey(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -4265,6 +4769,36 @@ func (mr *MockStoreMockRecorder) UpdateOAuth2ProviderAppByID(arg0, arg1 any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ProviderAppByID", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2ProviderAppByID), arg0, arg1)
 }
 
+// UpdateOAuth2ProviderAppDeviceCodeLastPolledAt mocks base method.
+func (m *MockStore) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(arg0 context.Context, arg1 database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2ProviderAppDeviceCodeLastPolledAt", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2ProviderAppDeviceCodeLastPolledAt indicates an expected call of UpdateOAuth2ProviderAppDeviceCodeLastPolledAt.
+func (mr *MockStoreMockRecorder) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ProviderAppDeviceCodeLastPolledAt", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2ProviderAppDeviceCodeLastPolledAt), arg0, arg1)
+}
+
+// UpdateOAuth2ProviderAppDeviceCodeStatus mocks base method.
+func (m *MockStore) UpdateOAuth2ProviderAppDeviceCodeStatus(arg0 context.Context, arg1 database.UpdateOAuth2ProviderAppDeviceCodeStatusParams) (database.OAuth2ProviderAppDeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOAuth2ProviderAppDeviceCodeStatus", arg0, arg1)
+	ret0, _ := ret[0].(database.OAuth2ProviderAppDeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateOAuth2ProviderAppDeviceCodeStatus indicates an expected call of UpdateOAuth2ProviderAppDeviceCodeStatus.
+func (mr *MockStoreMockRecorder) UpdateOAuth2ProviderAppDeviceCodeStatus(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOAuth2ProviderAppDeviceCodeStatus", reflect.TypeOf((*MockStore)(nil).UpdateOAuth2ProviderAppDeviceCodeStatus), arg0, arg1)
+}
+
 // UpdateOAuth2ProviderAppSecretByID mocks base method.
 func (m *MockStore) UpdateOAuth2ProviderAppSecretByID(arg0 context.Context, arg1 database.UpdateOAuth2ProviderAppSecretByIDParams) (database.OAuth2ProviderAppSecret, error) {
 	m.ctrl.T.Helper()
@@ -4366,6 +4900,21 @@ func (mr *MockStoreMockRecorder) UpdateReplica(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReplica", reflect.TypeOf((*MockStore)(nil).UpdateReplica), arg0, arg1)
 }
 
+// UpdateRoleGrantRequestByID mocks base method.
+func (m *MockStore) UpdateRoleGrantRequestByID(arg0 context.Context, arg1 database.UpdateRoleGrantRequestByIDParams) (database.RoleGrantRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoleGrantRequestByID", arg0, arg1)
+	ret0, _ := ret[0].(database.RoleGrantRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRoleGrantRequestByID indicates an expected call of UpdateRoleGrantRequestByID.
+func (mr *MockStoreMockRecorder) UpdateRoleGrantRequestByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoleGrantRequestByID", reflect.TypeOf((*MockStore)(nil).UpdateRoleGrantRequestByID), arg0, arg1)
+}
+
 // UpdateTailnetPeerStatusByCoordinator mocks base method.
 func (m *MockStore) UpdateTailnetPeerStatusByCoordinator(arg0 context.Context, arg1 database.UpdateTailnetPeerStatusByCoordinatorParams) error {
 	m.ctrl.T.Helper()
@@ -4478,6 +5027,21 @@ func (mr *MockStoreMockRecorder) UpdateTemplateVersionByID(arg0, arg1 any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionByID), arg0, arg1)
 }
 
+// UpdateTemplateVersionCanaryStatusByID mocks base method.
+func (m *MockStore) UpdateTemplateVersionCanaryStatusByID(arg0 context.Context, arg1 database.UpdateTemplateVersionCanaryStatusByIDParams) (database.TemplateVersionCanary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateVersionCanaryStatusByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionCanary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplateVersionCanaryStatusByID indicates an expected call of UpdateTemplateVersionCanaryStatusByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateVersionCanaryStatusByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionCanaryStatusByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionCanaryStatusByID), arg0, arg1)
+}
+
 // UpdateTemplateVersionDescriptionByJobID mocks base method.
 func (m *MockStore) UpdateTemplateVersionDescriptionByJobID(arg0 context.Context, arg1 database.UpdateTemplateVersionDescriptionByJobIDParams) error {
 	m.ctrl.T.Helper()
@@ -4712,6 +5276,49 @@ func (mr *MockStoreMockRecorder) UpdateUserStatus(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStatus", reflect.TypeOf((*MockStore)(nil).UpdateUserStatus), arg0, arg1)
 }
 
+// UpdateUserTOTPEnabledAt mocks base method.
+func (m *MockStore) UpdateUserTOTPEnabledAt(arg0 context.Context, arg1 database.UpdateUserTOTPEnabledAtParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPEnabledAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserTOTPEnabledAt indicates an expected call of UpdateUserTOTPEnabledAt.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPEnabledAt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPEnabledAt", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPEnabledAt), arg0, arg1)
+}
+
+// UpdateUserTOTPLastUsedCounter mocks base method.
+func (m *MockStore) UpdateUserTOTPLastUsedCounter(arg0 context.Context, arg1 database.UpdateUserTOTPLastUsedCounterParams) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPLastUsedCounter", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserTOTPLastUsedCounter indicates an expected call of UpdateUserTOTPLastUsedCounter.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPLastUsedCounter(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPLastUsedCounter", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPLastUsedCounter), arg0, arg1)
+}
+
+// UpdateUserTOTPSecret mocks base method.
+func (m *MockStore) UpdateUserTOTPSecret(arg0 context.Context, arg1 database.UpdateUserTOTPSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserTOTPSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserTOTPSecret indicates an expected call of UpdateUserTOTPSecret.
+func (mr *MockStoreMockRecorder) UpdateUserTOTPSecret(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserTOTPSecret", reflect.TypeOf((*MockStore)(nil).UpdateUserTOTPSecret), arg0, arg1)
+}
+
 // UpdateWorkspace mocks base method.
 func (m *MockStore) UpdateWorkspace(arg0 context.Context, arg1 database.UpdateWorkspaceParams) (database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -4910,6 +5517,20 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceDormantDeletingAt(arg0, arg1 any
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceDormantDeletingAt", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceDormantDeletingAt), arg0, arg1)
 }
 
+// UpdateWorkspaceDriftCheckResult mocks base method.
+func (m *MockStore) UpdateWorkspaceDriftCheckResult(arg0 context.Context, arg1 database.UpdateWorkspaceDriftCheckResultParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceDriftCheckResult", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceDriftCheckResult indicates an expected call of UpdateWorkspaceDriftCheckResult.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceDriftCheckResult(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceDriftCheckResult", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceDriftCheckResult), arg0, arg1)
+}
+
 // UpdateWorkspaceLastUsedAt mocks base method.
 func (m *MockStore) UpdateWorkspaceLastUsedAt(arg0 context.Context, arg1 database.UpdateWorkspaceLastUsedAtParams) error {
 	m.ctrl.T.Helper()
@@ -5254,6 +5875,21 @@ func (mr *MockStoreMockRecorder) UpsertTemplateUsageStats(arg0 any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplateUsageStats", reflect.TypeOf((*MockStore)(nil).UpsertTemplateUsageStats), arg0)
 }
 
+// UpsertUserTOTP mocks base method.
+func (m *MockStore) UpsertUserTOTP(arg0 context.Context, arg1 database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserTOTP", arg0, arg1)
+	ret0, _ := ret[0].(database.UserTOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserTOTP indicates an expected call of UpsertUserTOTP.
+func (mr *MockStoreMockRecorder) UpsertUserTOTP(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserTOTP", reflect.TypeOf((*MockStore)(nil).UpsertUserTOTP), arg0, arg1)
+}
+
 // UpsertWorkspaceAgentPortShare mocks base method.
 func (m *MockStore) UpsertWorkspaceAgentPortShare(arg0 context.Context, arg1 database.UpsertWorkspaceAgentPortShareParams) (database.WorkspaceAgentPortShare, error) {
 	m.ctrl.T.Helper()
@@ -5269,6 +5905,21 @@ func (mr *MockStoreMockRecorder) UpsertWorkspaceAgentPortShare(arg0, arg1 any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceAgentPortShare", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceAgentPortShare), arg0, arg1)
 }
 
+// UpsertWorkspaceDriftCheck mocks base method.
+func (m *MockStore) UpsertWorkspaceDriftCheck(arg0 context.Context, arg1 database.UpsertWorkspaceDriftCheckParams) (database.WorkspaceDriftCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceDriftCheck", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceDriftCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertWorkspaceDriftCheck indicates an expected call of UpsertWorkspaceDriftCheck.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceDriftCheck(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceDriftCheck", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceDriftCheck), arg0, arg1)
+}
+
 // Wrappers mocks base method.
 func (m *MockStore) Wrappers() []string {
 	m.ctrl.T.Helper()
@@ -61,6 +61,12 @@ func New(ctx context.Context, logger slog.Logger, db database.Store) io.Closer {
 			if err := tx.DeleteOldNotificationMessages(ctx); err != nil {
 				return xerrors.Errorf("failed to delete old notification messages: %w", err)
 			}
+			if err := tx.DeleteOldDERPHealthHistory(ctx); err != nil {
+				return xerrors.Errorf("failed to delete old derp health history: %w", err)
+			}
+			if err := tx.DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx); err != nil {
+				return xerrors.Errorf("failed to delete expired oauth2 app device codes: %w", err)
+			}
 
 			logger.Info(ctx, "purged old database entries", slog.F("duration", time.Since(start)))
 
@@ -19,7 +19,8 @@ CREATE TYPE audit_action AS ENUM (
     'stop',
     'login',
     'logout',
-    'register'
+    'register',
+    'open'
 );
 
 CREATE TYPE automatic_updates AS ENUM (
@@ -46,7 +47,8 @@ CREATE TYPE display_app AS ENUM (
 
 CREATE TYPE group_source AS ENUM (
     'user',
-    'oidc'
+    'oidc',
+    'scim'
 );
 
 CREATE TYPE log_level AS ENUM (
@@ -68,7 +70,8 @@ CREATE TYPE login_type AS ENUM (
     'oidc',
     'token',
     'none',
-    'oauth2_provider_app'
+    'oauth2_provider_app',
+    'ldap'
 );
 
 COMMENT ON TYPE login_type IS 'Specifies the method of authentication. "none" is a special case in which no authentication method is allowed.';
@@ -97,6 +100,12 @@ CREATE TYPE notification_template_kind AS ENUM (
     'system'
 );
 
+CREATE TYPE oauth2_provider_device_code_status AS ENUM (
+    'pending',
+    'approved',
+    'denied'
+);
+
 CREATE TYPE parameter_destination_scheme AS ENUM (
     'none',
     'environment_variable',
@@ -124,6 +133,14 @@ CREATE TYPE port_share_protocol AS ENUM (
     'https'
 );
 
+CREATE TYPE provisioner_job_priority AS ENUM (
+    'interactive',
+    'template_import',
+    'autobuild'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Scheduling class of a provisioner job. Pending jobs in an earlier class are always acquired before jobs in a later class.';
+
 CREATE TYPE provisioner_job_status AS ENUM (
     'pending',
     'running',
@@ -146,7 +163,8 @@ CREATE TYPE provisioner_job_timing_stage AS ENUM (
 CREATE TYPE provisioner_job_type AS ENUM (
     'template_version_import',
     'workspace_build',
-    'template_version_dry_run'
+    'template_version_dry_run',
+    'workspace_build_plan'
 );
 
 CREATE TYPE provisioner_storage_method AS ENUM (
@@ -155,9 +173,19 @@ CREATE TYPE provisioner_storage_method AS ENUM (
 
 CREATE TYPE provisioner_type AS ENUM (
     'echo',
-    'terraform'
+    'terraform',
+    'tofu'
 );
 
+CREATE TYPE resource_change_action AS ENUM (
+    'create',
+    'update',
+    'replace',
+    'delete'
+);
+
+COMMENT ON TYPE resource_change_action IS 'The action Terraform plans to take on a resource. Replace covers both delete-then-create and create-then-delete.';
+
 CREATE TYPE resource_type AS ENUM (
     'organization',
     'template',
@@ -177,7 +205,17 @@ CREATE TYPE resource_type AS ENUM (
     'custom_role',
     'organization_member',
     'notifications_settings',
-    'notification_template'
+    'notification_template',
+    'role_grant_request',
+    'template_version_canary'
+);
+
+CREATE TYPE role_grant_status AS ENUM (
+    'pending',
+    'approved',
+    'denied',
+    'expired',
+    'revoked'
 );
 
 CREATE TYPE startup_script_behavior AS ENUM (
@@ -190,6 +228,12 @@ CREATE TYPE tailnet_status AS ENUM (
     'lost'
 );
 
+CREATE TYPE template_version_canary_status AS ENUM (
+    'active',
+    'promoted',
+    'rolled_back'
+);
+
 CREATE TYPE user_status AS ENUM (
     'active',
     'suspended',
@@ -471,11 +515,20 @@ CREATE TABLE api_keys (
     lifetime_seconds bigint DEFAULT 86400 NOT NULL,
     ip_address inet DEFAULT '0.0.0.0'::inet NOT NULL,
     scope api_key_scope DEFAULT 'all'::api_key_scope NOT NULL,
-    token_name text DEFAULT ''::text NOT NULL
+    token_name text DEFAULT ''::text NOT NULL,
+    scopes text[] DEFAULT '{}'::text[] NOT NULL,
+    allow_list text[] DEFAULT '{}'::text[] NOT NULL,
+    user_agent text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+COMMENT ON COLUMN api_keys.scopes IS 'Named scopes from the scope catalog. If any are set, the key is limited to them instead of scope.';
+
+COMMENT ON COLUMN api_keys.allow_list IS 'Resources the key is limited to, as "<type>:<id>". An empty list allows every resource.';
+
+COMMENT ON COLUMN api_keys.user_agent IS 'User agent of the client that last used the key.';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -535,6 +588,37 @@ COMMENT ON COLUMN dbcrypt_keys.revoked_at IS 'The time at which the key was revo
 
 COMMENT ON COLUMN dbcrypt_keys.test IS 'A column used to test the encryption.';
 
+CREATE TABLE derp_health_history (
+    id bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    replica_id uuid NOT NULL,
+    region_id integer NOT NULL,
+    region_code text NOT NULL,
+    node_name text NOT NULL,
+    healthy boolean NOT NULL,
+    can_exchange_messages boolean NOT NULL,
+    round_trip_ping_ms integer NOT NULL,
+    packet_loss real NOT NULL,
+    stun_enabled boolean NOT NULL,
+    can_stun boolean NOT NULL,
+    error text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON TABLE derp_health_history IS 'Results of the DERP healthcheck for each node, recorded periodically by each replica.';
+
+COMMENT ON COLUMN derp_health_history.round_trip_ping_ms IS 'Round trip time of a message relayed through the node. Zero if no message could be exchanged.';
+
+COMMENT ON COLUMN derp_health_history.packet_loss IS 'Fraction of probe messages that were not relayed back, between 0 and 1.';
+
+CREATE SEQUENCE derp_health_history_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE derp_health_history_id_seq OWNED BY derp_health_history.id;
+
 CREATE TABLE external_auth_links (
     provider_id text NOT NULL,
     user_id uuid NOT NULL,
@@ -612,7 +696,9 @@ CREATE TABLE users (
     quiet_hours_schedule text DEFAULT ''::text NOT NULL,
     theme_preference text DEFAULT ''::text NOT NULL,
     name text DEFAULT ''::text NOT NULL,
-    github_com_user_id bigint
+    github_com_user_id bigint,
+    is_service_account boolean DEFAULT false NOT NULL,
+    service_account_organization_id uuid
 );
 
 COMMENT ON COLUMN users.quiet_hours_schedule IS 'Daily (!) cron schedule (with optional CRON_TZ) signifying the start of the user''s quiet hours. If empty, the default quiet hours on the instance is used instead.';
@@ -623,6 +709,10 @@ COMMENT ON COLUMN users.name IS 'Name of the Coder user';
 
 COMMENT ON COLUMN users.github_com_user_id IS 'The GitHub.com numerical user ID. At time of implementation, this is used to check if the user has starred the Coder repository.';
 
+COMMENT ON COLUMN users.is_service_account IS 'Service accounts belong to a single organization, cannot log in interactively and only authenticate with API tokens. They are counted against the licensed service account limit, or against the user limit if there is none.';
+
+COMMENT ON COLUMN users.service_account_organization_id IS 'The organization that owns the service account. The service account cannot be removed from it, and its admins can manage the service account.';
+
 CREATE VIEW group_members_expanded AS
  WITH all_members AS (
          SELECT group_members.user_id,
@@ -739,11 +829,36 @@ CREATE TABLE oauth2_provider_app_codes (
     secret_prefix bytea NOT NULL,
     hashed_secret bytea NOT NULL,
     user_id uuid NOT NULL,
-    app_id uuid NOT NULL
+    app_id uuid NOT NULL,
+    code_challenge text DEFAULT ''::text NOT NULL,
+    code_challenge_method text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON TABLE oauth2_provider_app_codes IS 'Codes are meant to be exchanged for access tokens.';
 
+COMMENT ON COLUMN oauth2_provider_app_codes.code_challenge IS 'PKCE code challenge sent with the authorization request. Empty if the client did not use PKCE.';
+
+CREATE TABLE oauth2_provider_app_device_codes (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL,
+    device_prefix bytea NOT NULL,
+    hashed_device_code bytea NOT NULL,
+    user_code text NOT NULL,
+    app_id uuid,
+    user_id uuid,
+    status oauth2_provider_device_code_status DEFAULT 'pending'::oauth2_provider_device_code_status NOT NULL,
+    last_polled_at timestamp with time zone
+);
+
+COMMENT ON TABLE oauth2_provider_app_device_codes IS 'Device codes are polled by input constrained devices and exchanged for access tokens once a user approves the matching user code.';
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.app_id IS 'The app that requested the code. Null for device logins of the CLI, which are exchanged for a session token instead.';
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.last_polled_at IS 'When the token endpoint was last polled with the code. Used to tell clients that poll faster than the interval to slow down.';
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.user_id IS 'The user that approved or denied the code. Null while the code is pending.';
+
 CREATE TABLE oauth2_provider_app_secrets (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -762,8 +877,9 @@ CREATE TABLE oauth2_provider_app_tokens (
     expires_at timestamp with time zone NOT NULL,
     hash_prefix bytea NOT NULL,
     refresh_hash bytea NOT NULL,
-    app_secret_id uuid NOT NULL,
-    api_key_id text NOT NULL
+    app_secret_id uuid,
+    api_key_id text NOT NULL,
+    app_id uuid NOT NULL
 );
 
 COMMENT ON COLUMN oauth2_provider_app_tokens.refresh_hash IS 'Refresh tokens provide a way to refresh an access token (API key). An expired API key can be refreshed if this token is not yet expired, meaning this expiry can outlive an API key.';
@@ -774,11 +890,17 @@ CREATE TABLE oauth2_provider_apps (
     updated_at timestamp with time zone NOT NULL,
     name character varying(64) NOT NULL,
     icon character varying(256) NOT NULL,
-    callback_url text NOT NULL
+    callback_url text NOT NULL,
+    public boolean DEFAULT false NOT NULL,
+    client_credentials_user_id uuid
 );
 
 COMMENT ON TABLE oauth2_provider_apps IS 'A table used to configure apps that can use Coder as an OAuth2 provider, the reverse of what we are calling external authentication.';
 
+COMMENT ON COLUMN oauth2_provider_apps.public IS 'Public clients cannot keep a secret, so they authenticate without one and must use PKCE for the authorization code grant.';
+
+COMMENT ON COLUMN oauth2_provider_apps.client_credentials_user_id IS 'The user that tokens issued with the client credentials grant act as. The grant is disabled when this is null.';
+
 CREATE TABLE organizations (
     id uuid NOT NULL,
     name text NOT NULL,
@@ -787,9 +909,15 @@ CREATE TABLE organizations (
     updated_at timestamp with time zone NOT NULL,
     is_default boolean DEFAULT false NOT NULL,
     display_name text NOT NULL,
-    icon text DEFAULT ''::text NOT NULL
+    icon text DEFAULT ''::text NOT NULL,
+    allowed_workspace_proxy_ids uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    allowed_derp_region_ids integer[] DEFAULT '{}'::integer[] NOT NULL
 );
 
+COMMENT ON COLUMN organizations.allowed_workspace_proxy_ids IS 'Workspace proxies that may serve apps for workspaces in this organization. The primary proxy is identified by the deployment ID. An empty list allows all proxies.';
+
+COMMENT ON COLUMN organizations.allowed_derp_region_ids IS 'DERP regions that agents in this organization may relay through. An empty list allows all regions.';
+
 CREATE TABLE parameter_schemas (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -856,6 +984,16 @@ CREATE SEQUENCE provisioner_job_logs_id_seq
 
 ALTER SEQUENCE provisioner_job_logs_id_seq OWNED BY provisioner_job_logs.id;
 
+CREATE TABLE provisioner_job_resource_changes (
+    job_id uuid NOT NULL,
+    address text NOT NULL,
+    type text NOT NULL,
+    name text NOT NULL,
+    action resource_change_action NOT NULL
+);
+
+COMMENT ON TABLE provisioner_job_resource_changes IS 'Resource changes planned by a workspace_build_plan job. Nothing in this table has been applied.';
+
 CREATE VIEW provisioner_job_stats AS
 SELECT
     NULL::uuid AS job_id,
@@ -917,11 +1055,14 @@ CASE
         WHEN (started_at IS NULL) THEN 'pending'::provisioner_job_status
         ELSE 'running'::provisioner_job_status
     END
-END) STORED NOT NULL
+END) STORED NOT NULL,
+    priority provisioner_job_priority DEFAULT 'interactive'::provisioner_job_priority NOT NULL
 );
 
 COMMENT ON COLUMN provisioner_jobs.job_status IS 'Computed column to track the status of the job.';
 
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Scheduling class of the job, set when the job is created.';
+
 CREATE TABLE provisioner_keys (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -946,6 +1087,29 @@ CREATE TABLE replicas (
     "primary" boolean DEFAULT true NOT NULL
 );
 
+CREATE TABLE role_grant_requests (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    organization_id uuid,
+    role_name text NOT NULL,
+    reason text NOT NULL,
+    lifetime_seconds bigint NOT NULL,
+    status role_grant_status DEFAULT 'pending'::role_grant_status NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    reviewed_by uuid,
+    reviewed_at timestamp with time zone,
+    expires_at timestamp with time zone
+);
+
+COMMENT ON TABLE role_grant_requests IS 'Requests for a site or organization role that is granted for a limited time once approved.';
+
+COMMENT ON COLUMN role_grant_requests.organization_id IS 'The organization of the requested role. NULL for site-wide roles.';
+
+COMMENT ON COLUMN role_grant_requests.lifetime_seconds IS 'How long the role is granted for once the request is approved.';
+
+COMMENT ON COLUMN role_grant_requests.expires_at IS 'When the granted role is removed. Set when the request is approved.';
+
 CREATE TABLE site_configs (
     key character varying(256) NOT NULL,
     value text NOT NULL
@@ -1035,6 +1199,35 @@ COMMENT ON COLUMN template_usage_stats.jetbrains_mins IS 'Total minutes the user
 
 COMMENT ON COLUMN template_usage_stats.app_usage_mins IS 'Object with app names as keys and total minutes used as values. Null means no app usage was recorded.';
 
+CREATE TABLE template_version_canaries (
+    id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    template_version_id uuid NOT NULL,
+    percent integer NOT NULL,
+    group_id uuid,
+    min_builds integer NOT NULL,
+    success_threshold integer NOT NULL,
+    status template_version_canary_status DEFAULT 'active'::template_version_canary_status NOT NULL,
+    status_reason text DEFAULT ''::text NOT NULL,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone,
+    CONSTRAINT template_version_canaries_min_builds_check CHECK ((min_builds > 0)),
+    CONSTRAINT template_version_canaries_percent_check CHECK (((percent >= 0) AND (percent <= 100))),
+    CONSTRAINT template_version_canaries_success_threshold_check CHECK (((success_threshold >= 0) AND (success_threshold <= 100)))
+);
+
+COMMENT ON TABLE template_version_canaries IS 'Staged rollouts of template versions. While a canary is active, workspaces in its cohort are built with the canary version instead of the active version.';
+
+COMMENT ON COLUMN template_version_canaries.percent IS 'Percentage of the template''s workspaces in the cohort, selected by a hash of the workspace ID.';
+
+COMMENT ON COLUMN template_version_canaries.group_id IS 'Workspaces owned by members of this group are in the cohort regardless of percent.';
+
+COMMENT ON COLUMN template_version_canaries.min_builds IS 'Number of finished canary builds required before the canary is promoted or rolled back.';
+
+COMMENT ON COLUMN template_version_canaries.success_threshold IS 'Percentage of finished canary builds that must succeed with healthy agents for the canary to be promoted.';
+
 CREATE TABLE template_version_parameters (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -1194,7 +1387,14 @@ CREATE TABLE templates (
     require_active_version boolean DEFAULT false NOT NULL,
     deprecated text DEFAULT ''::text NOT NULL,
     activity_bump bigint DEFAULT '3600000000000'::bigint NOT NULL,
-    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL
+    max_port_sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
+    allowed_workspace_proxy_ids uuid[] DEFAULT '{}'::uuid[] NOT NULL,
+    allowed_derp_region_ids integer[] DEFAULT '{}'::integer[] NOT NULL,
+    max_app_connections_per_user bigint DEFAULT 0 NOT NULL,
+    max_app_bytes_per_second bigint DEFAULT 0 NOT NULL,
+    drift_detection_interval bigint DEFAULT 0 NOT NULL,
+    build_timeout bigint DEFAULT 0 NOT NULL,
+    build_cancel_grace_period bigint DEFAULT 0 NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -1215,6 +1415,20 @@ COMMENT ON COLUMN templates.autostart_block_days_of_week IS 'A bitmap of days of
 
 COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.';
 
+COMMENT ON COLUMN templates.allowed_workspace_proxy_ids IS 'Workspace proxies that may serve apps for workspaces of this template. The primary proxy is identified by the deployment ID. An empty list allows all proxies.';
+
+COMMENT ON COLUMN templates.allowed_derp_region_ids IS 'DERP regions that agents of this template may relay through. An empty list allows all regions.';
+
+COMMENT ON COLUMN templates.max_app_connections_per_user IS 'Maximum number of concurrent workspace app connections per user on each replica or workspace proxy. Zero means the deployment limit applies.';
+
+COMMENT ON COLUMN templates.max_app_bytes_per_second IS 'Maximum throughput of each workspace app in bytes per second on each replica or workspace proxy. Zero means the deployment limit applies.';
+
+COMMENT ON COLUMN templates.drift_detection_interval IS 'How often running workspaces are checked for drift with a refresh-only plan. Zero disables drift detection.';
+
+COMMENT ON COLUMN templates.build_timeout IS 'Maximum duration of a workspace build job in nanoseconds, after which it is canceled. Zero means no limit.';
+
+COMMENT ON COLUMN templates.build_cancel_grace_period IS 'How long a canceled workspace build job may take to exit gracefully in nanoseconds before it is failed. Zero means the provisioner daemon default applies.';
+
 CREATE VIEW template_with_names AS
  SELECT templates.id,
     templates.created_at,
@@ -1244,6 +1458,13 @@ CREATE VIEW template_with_names AS
     templates.deprecated,
     templates.activity_bump,
     templates.max_port_sharing_level,
+    templates.allowed_workspace_proxy_ids,
+    templates.allowed_derp_region_ids,
+    templates.max_app_connections_per_user,
+    templates.max_app_bytes_per_second,
+    templates.drift_detection_interval,
+    templates.build_timeout,
+    templates.build_cancel_grace_period,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username,
     COALESCE(organizations.name, ''::text) AS organization_name,
@@ -1273,6 +1494,26 @@ COMMENT ON COLUMN user_links.oauth_refresh_token_key_id IS 'The ID of the key us
 
 COMMENT ON COLUMN user_links.debug_context IS 'Debug information includes information like id_token and userinfo claims.';
 
+CREATE TABLE user_totp (
+    user_id uuid NOT NULL,
+    secret text NOT NULL,
+    secret_key_id text,
+    hashed_recovery_codes text[] DEFAULT '{}'::text[] NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    enabled_at timestamp with time zone,
+    last_used_counter bigint DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE user_totp IS 'Time-based one-time password (TOTP) second factors for password logins.';
+
+COMMENT ON COLUMN user_totp.secret_key_id IS 'The ID of the key used to encrypt the secret. If this is NULL, the secret is not encrypted';
+
+COMMENT ON COLUMN user_totp.hashed_recovery_codes IS 'SHA256 hashes of the unused single-use recovery codes.';
+
+COMMENT ON COLUMN user_totp.enabled_at IS 'When the user confirmed enrollment with a valid code. Null while enrollment is pending.';
+
+COMMENT ON COLUMN user_totp.last_used_counter IS 'The time step of the last accepted code. Codes from this or an earlier time step are rejected so they cannot be replayed.';
+
 CREATE TABLE workspace_agent_log_sources (
     workspace_agent_id uuid NOT NULL,
     id uuid NOT NULL,
@@ -1502,9 +1743,12 @@ CREATE TABLE workspace_builds (
     deadline timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
     reason build_reason DEFAULT 'initiator'::build_reason NOT NULL,
     daily_cost integer DEFAULT 0 NOT NULL,
-    max_deadline timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
+    max_deadline timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
+    provisioner_state_key_id text
 );
 
+COMMENT ON COLUMN workspace_builds.provisioner_state_key_id IS 'The ID of the key used to encrypt the provisioner state. If this is NULL, the provisioner state is not encrypted';
+
 CREATE VIEW workspace_build_with_user AS
  SELECT workspace_builds.id,
     workspace_builds.created_at,
@@ -1520,6 +1764,7 @@ CREATE VIEW workspace_build_with_user AS
     workspace_builds.reason,
     workspace_builds.daily_cost,
     workspace_builds.max_deadline,
+    workspace_builds.provisioner_state_key_id,
     COALESCE(visible_users.avatar_url, ''::text) AS initiator_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS initiator_by_username
    FROM (workspace_builds
@@ -1527,6 +1772,20 @@ CREATE VIEW workspace_build_with_user AS
 
 COMMENT ON VIEW workspace_build_with_user IS 'Joins in the username + avatar url of the initiated by user.';
 
+CREATE TABLE workspace_drift_checks (
+    workspace_id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    scheduled_at timestamp with time zone NOT NULL,
+    result_job_id uuid,
+    checked_at timestamp with time zone
+);
+
+COMMENT ON TABLE workspace_drift_checks IS 'The latest drift check of each workspace. Drifted resources are the resource changes of the result job.';
+
+COMMENT ON COLUMN workspace_drift_checks.job_id IS 'The most recently scheduled drift check, which may still be pending.';
+
+COMMENT ON COLUMN workspace_drift_checks.result_job_id IS 'The most recent drift check that succeeded.';
+
 CREATE TABLE workspace_proxies (
     id uuid NOT NULL,
     name text NOT NULL,
@@ -1616,6 +1875,8 @@ CREATE TABLE workspaces (
 
 COMMENT ON COLUMN workspaces.favorite IS 'Favorite is true if the workspace owner has favorited the workspace.';
 
+ALTER TABLE ONLY derp_health_history ALTER COLUMN id SET DEFAULT nextval('derp_health_history_id_seq'::regclass);
+
 ALTER TABLE ONLY licenses ALTER COLUMN id SET DEFAULT nextval('licenses_id_seq'::regclass);
 
 ALTER TABLE ONLY provisioner_job_logs ALTER COLUMN id SET DEFAULT nextval('provisioner_job_logs_id_seq'::regclass);
@@ -1649,6 +1910,9 @@ ALTER TABLE ONLY dbcrypt_keys
 ALTER TABLE ONLY dbcrypt_keys
     ADD CONSTRAINT dbcrypt_keys_revoked_key_digest_key UNIQUE (revoked_key_digest);
 
+ALTER TABLE ONLY derp_health_history
+    ADD CONSTRAINT derp_health_history_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY files
     ADD CONSTRAINT files_hash_created_by_key UNIQUE (hash, created_by);
 
@@ -1697,6 +1961,15 @@ ALTER TABLE ONLY oauth2_provider_app_codes
 ALTER TABLE ONLY oauth2_provider_app_codes
     ADD CONSTRAINT oauth2_provider_app_codes_secret_prefix_key UNIQUE (secret_prefix);
 
+ALTER TABLE ONLY oauth2_provider_app_device_codes
+    ADD CONSTRAINT oauth2_provider_app_device_codes_device_prefix_key UNIQUE (device_prefix);
+
+ALTER TABLE ONLY oauth2_provider_app_device_codes
+    ADD CONSTRAINT oauth2_provider_app_device_codes_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY oauth2_provider_app_device_codes
+    ADD CONSTRAINT oauth2_provider_app_device_codes_user_code_key UNIQUE (user_code);
+
 ALTER TABLE ONLY oauth2_provider_app_secrets
     ADD CONSTRAINT oauth2_provider_app_secrets_pkey PRIMARY KEY (id);
 
@@ -1748,6 +2021,9 @@ ALTER TABLE ONLY provisioner_jobs
 ALTER TABLE ONLY provisioner_keys
     ADD CONSTRAINT provisioner_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY role_grant_requests
+    ADD CONSTRAINT role_grant_requests_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
@@ -1772,6 +2048,9 @@ ALTER TABLE ONLY tailnet_tunnels
 ALTER TABLE ONLY template_usage_stats
     ADD CONSTRAINT template_usage_stats_pkey PRIMARY KEY (start_time, template_id, user_id);
 
+ALTER TABLE ONLY template_version_canaries
+    ADD CONSTRAINT template_version_canaries_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_name_key UNIQUE (template_version_id, name);
 
@@ -1793,6 +2072,9 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 
+ALTER TABLE ONLY user_totp
+    ADD CONSTRAINT user_totp_pkey PRIMARY KEY (user_id);
+
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
@@ -1835,6 +2117,9 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_pkey PRIMARY KEY (workspace_id);
+
 ALTER TABLE ONLY workspace_proxies
     ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 
@@ -1853,6 +2138,8 @@ ALTER TABLE ONLY workspace_resources
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
+CREATE INDEX derp_health_history_created_at_idx ON derp_health_history USING btree (created_at);
+
 CREATE INDEX idx_agent_stats_created_at ON workspace_agent_stats USING btree (created_at);
 
 CREATE INDEX idx_agent_stats_user_id ON workspace_agent_stats USING btree (user_id);
@@ -1907,10 +2194,18 @@ CREATE UNIQUE INDEX organizations_single_default_org ON organizations USING btre
 
 CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING btree (job_id, id);
 
+CREATE INDEX provisioner_job_resource_changes_job_id_idx ON provisioner_job_resource_changes USING btree (job_id);
+
+CREATE INDEX provisioner_jobs_running_initiator_id_idx ON provisioner_jobs USING btree (initiator_id) WHERE ((started_at IS NOT NULL) AND (completed_at IS NULL));
+
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
 CREATE UNIQUE INDEX provisioner_keys_organization_id_name_idx ON provisioner_keys USING btree (organization_id, lower((name)::text));
 
+CREATE INDEX role_grant_requests_expires_at_idx ON role_grant_requests USING btree (expires_at) WHERE (status = 'approved'::role_grant_status);
+
+CREATE UNIQUE INDEX role_grant_requests_open_idx ON role_grant_requests USING btree (user_id, COALESCE(organization_id, '00000000-0000-0000-0000-000000000000'::uuid), role_name) WHERE (status = ANY (ARRAY['pending'::role_grant_status, 'approved'::role_grant_status]));
+
 CREATE INDEX template_usage_stats_start_time_idx ON template_usage_stats USING btree (start_time DESC);
 
 COMMENT ON INDEX template_usage_stats_start_time_idx IS 'Index for querying MAX(start_time).';
@@ -1919,6 +2214,8 @@ CREATE UNIQUE INDEX template_usage_stats_start_time_template_id_user_id_idx ON t
 
 COMMENT ON INDEX template_usage_stats_start_time_template_id_user_id_idx IS 'Index for primary key.';
 
+CREATE UNIQUE INDEX template_version_canaries_active_template_id_idx ON template_version_canaries USING btree (template_id) WHERE (status = 'active'::template_version_canary_status);
+
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX user_links_linked_id_login_type_idx ON user_links USING btree (linked_id, login_type) WHERE (linked_id <> ''::text);
@@ -2077,12 +2374,24 @@ ALTER TABLE ONLY oauth2_provider_app_codes
 ALTER TABLE ONLY oauth2_provider_app_secrets
     ADD CONSTRAINT oauth2_provider_app_secrets_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY oauth2_provider_app_device_codes
+    ADD CONSTRAINT oauth2_provider_app_device_codes_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY oauth2_provider_app_device_codes
+    ADD CONSTRAINT oauth2_provider_app_device_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY oauth2_provider_app_tokens
     ADD CONSTRAINT oauth2_provider_app_tokens_api_key_id_fkey FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY oauth2_provider_app_tokens
+    ADD CONSTRAINT oauth2_provider_app_tokens_app_id_fkey FOREIGN KEY (app_id) REFERENCES oauth2_provider_apps(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY oauth2_provider_app_tokens
     ADD CONSTRAINT oauth2_provider_app_tokens_app_secret_id_fkey FOREIGN KEY (app_secret_id) REFERENCES oauth2_provider_app_secrets(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY oauth2_provider_apps
+    ADD CONSTRAINT oauth2_provider_apps_client_credentials_user_id_fkey FOREIGN KEY (client_credentials_user_id) REFERENCES users(id) ON DELETE SET NULL;
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_organization_id_uuid_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
@@ -2098,6 +2407,9 @@ ALTER TABLE ONLY provisioner_daemons
 ALTER TABLE ONLY provisioner_job_logs
     ADD CONSTRAINT provisioner_job_logs_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY provisioner_job_resource_changes
+    ADD CONSTRAINT provisioner_job_resource_changes_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY provisioner_job_timings
     ADD CONSTRAINT provisioner_job_timings_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -2107,6 +2419,15 @@ ALTER TABLE ONLY provisioner_jobs
 ALTER TABLE ONLY provisioner_keys
     ADD CONSTRAINT provisioner_keys_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY role_grant_requests
+    ADD CONSTRAINT role_grant_requests_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY role_grant_requests
+    ADD CONSTRAINT role_grant_requests_reviewed_by_fkey FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY role_grant_requests
+    ADD CONSTRAINT role_grant_requests_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY tailnet_agents
     ADD CONSTRAINT tailnet_agents_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
 
@@ -2122,6 +2443,18 @@ ALTER TABLE ONLY tailnet_peers
 ALTER TABLE ONLY tailnet_tunnels
     ADD CONSTRAINT tailnet_tunnels_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_version_canaries
+    ADD CONSTRAINT template_version_canaries_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE ONLY template_version_canaries
+    ADD CONSTRAINT template_version_canaries_group_id_fkey FOREIGN KEY (group_id) REFERENCES groups(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_version_canaries
+    ADD CONSTRAINT template_version_canaries_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_canaries
+    ADD CONSTRAINT template_version_canaries_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
@@ -2155,6 +2488,15 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY user_totp
+    ADD CONSTRAINT user_totp_secret_key_id_fkey FOREIGN KEY (secret_key_id) REFERENCES dbcrypt_keys(active_key_digest);
+
+ALTER TABLE ONLY user_totp
+    ADD CONSTRAINT user_totp_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY users
+    ADD CONSTRAINT users_service_account_organization_id_fkey FOREIGN KEY (service_account_organization_id) REFERENCES organizations(id) ON DELETE SET NULL;
+
 ALTER TABLE ONLY workspace_agent_log_sources
     ADD CONSTRAINT workspace_agent_log_sources_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
@@ -2191,12 +2533,24 @@ ALTER TABLE ONLY workspace_build_parameters
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_builds
+    ADD CONSTRAINT workspace_builds_provisioner_state_key_id_fkey FOREIGN KEY (provisioner_state_key_id) REFERENCES dbcrypt_keys(active_key_digest);
+
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_result_job_id_fkey FOREIGN KEY (result_job_id) REFERENCES provisioner_jobs(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY workspace_drift_checks
+    ADD CONSTRAINT workspace_drift_checks_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_resource_metadata
     ADD CONSTRAINT workspace_resource_metadata_workspace_resource_id_fkey FOREIGN KEY (workspace_resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
@@ -0,0 +1,43 @@
+ALTER TABLE templates
+	ADD COLUMN allowed_workspace_proxy_ids uuid[] NOT NULL DEFAULT '{}',
+	ADD COLUMN allowed_derp_region_ids integer[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN templates.allowed_workspace_proxy_ids IS 'Workspace proxies that may serve apps for workspaces of this template. The primary proxy is identified by the deployment ID. An empty list allows all proxies.';
+COMMENT ON COLUMN templates.allowed_derp_region_ids IS 'DERP regions that agents of this template may relay through. An empty list allows all regions.';
+
+ALTER TABLE organizations
+	ADD COLUMN allowed_workspace_proxy_ids uuid[] NOT NULL DEFAULT '{}',
+	ADD COLUMN allowed_derp_region_ids integer[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN organizations.allowed_workspace_proxy_ids IS 'Workspace proxies that may serve apps for workspaces in this organization. The primary proxy is identified by the deployment ID. An empty list allows all proxies.';
+COMMENT ON COLUMN organizations.allowed_derp_region_ids IS 'DERP regions that agents in this organization may relay through. An empty list allows all regions.';
+
+-- Update the template_with_names view by recreating it.
+DROP VIEW template_with_names;
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
+
+-- Denied attempts to open a workspace app through a disallowed proxy are
+-- audited with this action.
+ALTER TYPE audit_action
+	ADD VALUE IF NOT EXISTS 'open';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS derp_health_history;
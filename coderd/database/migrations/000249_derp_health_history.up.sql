@@ -0,0 +1,21 @@
+CREATE TABLE derp_health_history (
+	id bigserial PRIMARY KEY,
+	created_at timestamp with time zone NOT NULL,
+	replica_id uuid NOT NULL,
+	region_id integer NOT NULL,
+	region_code text NOT NULL,
+	node_name text NOT NULL,
+	healthy boolean NOT NULL,
+	can_exchange_messages boolean NOT NULL,
+	round_trip_ping_ms integer NOT NULL,
+	packet_loss real NOT NULL,
+	stun_enabled boolean NOT NULL,
+	can_stun boolean NOT NULL,
+	error text NOT NULL DEFAULT ''
+);
+
+COMMENT ON TABLE derp_health_history IS 'Results of the DERP healthcheck for each node, recorded periodically by each replica.';
+COMMENT ON COLUMN derp_health_history.round_trip_ping_ms IS 'Round trip time of a message relayed through the node. Zero if no message could be exchanged.';
+COMMENT ON COLUMN derp_health_history.packet_loss IS 'Fraction of probe messages that were not relayed back, between 0 and 1.';
+
+CREATE INDEX derp_health_history_created_at_idx ON derp_health_history USING btree (created_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS provisioner_jobs_running_initiator_id_idx;
+
+ALTER TABLE provisioner_jobs DROP COLUMN IF EXISTS priority;
+
+DROP TYPE IF EXISTS provisioner_job_priority;
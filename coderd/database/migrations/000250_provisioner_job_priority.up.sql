@@ -0,0 +1,28 @@
+-- The order of the values matters: pending jobs are acquired in the order
+-- their priority is declared here.
+CREATE TYPE provisioner_job_priority AS ENUM (
+	'interactive',
+	'template_import',
+	'autobuild'
+);
+
+COMMENT ON TYPE provisioner_job_priority IS 'Scheduling class of a provisioner job. Pending jobs in an earlier class are always acquired before jobs in a later class.';
+
+ALTER TABLE provisioner_jobs ADD COLUMN priority provisioner_job_priority NOT NULL DEFAULT 'interactive';
+
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Scheduling class of the job, set when the job is created.';
+
+UPDATE provisioner_jobs SET priority = 'template_import' WHERE type = 'template_version_import';
+
+UPDATE
+	provisioner_jobs
+SET
+	priority = 'autobuild'
+FROM
+	workspace_builds
+WHERE
+	workspace_builds.job_id = provisioner_jobs.id
+	AND workspace_builds.reason != 'initiator';
+
+-- Used to count the jobs each user has running when acquiring jobs.
+CREATE INDEX provisioner_jobs_running_initiator_id_idx ON provisioner_jobs USING btree (initiator_id) WHERE (started_at IS NOT NULL AND completed_at IS NULL);
@@ -0,0 +1,5 @@
+-- It's not possible to drop enum values from enum types, so the up migration has "IF NOT EXISTS".
+
+DROP TABLE IF EXISTS provisioner_job_resource_changes;
+
+DROP TYPE IF EXISTS resource_change_action;
@@ -0,0 +1,22 @@
+ALTER TYPE provisioner_job_type ADD VALUE IF NOT EXISTS 'workspace_build_plan';
+
+CREATE TYPE resource_change_action AS ENUM (
+	'create',
+	'update',
+	'replace',
+	'delete'
+);
+
+COMMENT ON TYPE resource_change_action IS 'The action Terraform plans to take on a resource. Replace covers both delete-then-create and create-then-delete.';
+
+CREATE TABLE provisioner_job_resource_changes (
+	job_id uuid NOT NULL REFERENCES provisioner_jobs (id) ON DELETE CASCADE,
+	address text NOT NULL,
+	type text NOT NULL,
+	name text NOT NULL,
+	action resource_change_action NOT NULL
+);
+
+COMMENT ON TABLE provisioner_job_resource_changes IS 'Resource changes planned by a workspace_build_plan job. Nothing in this table has been applied.';
+
+CREATE INDEX provisioner_job_resource_changes_job_id_idx ON provisioner_job_resource_changes USING btree (job_id);
@@ -0,0 +1,33 @@
+DELETE FROM notification_templates
+WHERE
+	id = '3b7e8f1c-5a6d-4e2f-9c1b-7d8a0e4f6b23';
+
+DROP TABLE IF EXISTS workspace_drift_checks;
+
+DROP VIEW template_with_names;
+
+ALTER TABLE templates
+	DROP COLUMN drift_detection_interval;
+
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
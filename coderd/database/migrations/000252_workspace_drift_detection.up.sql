@@ -0,0 +1,66 @@
+ALTER TABLE templates
+	ADD COLUMN drift_detection_interval bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.drift_detection_interval IS 'How often running workspaces are checked for drift with a refresh-only plan. Zero disables drift detection.';
+
+-- Update the template_with_names view by recreating it.
+DROP VIEW template_with_names;
+CREATE VIEW
+	template_with_names
+AS
+SELECT
+	templates.*,
+	coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+	coalesce(visible_users.username, '') AS created_by_username,
+	coalesce(organizations.name, '') AS organization_name,
+	coalesce(organizations.display_name, '') AS organization_display_name,
+	coalesce(organizations.icon, '') AS organization_icon
+FROM
+	templates
+		LEFT JOIN
+	visible_users
+	ON
+		templates.created_by = visible_users.id
+		LEFT JOIN
+	organizations
+	ON templates.organization_id = organizations.id
+;
+
+COMMENT ON VIEW template_with_names IS 'Joins in the display name information such as username, avatar, and organization name.';
+
+CREATE TABLE workspace_drift_checks (
+	workspace_id uuid NOT NULL PRIMARY KEY REFERENCES workspaces (id) ON DELETE CASCADE,
+	job_id uuid NOT NULL REFERENCES provisioner_jobs (id) ON DELETE CASCADE,
+	scheduled_at timestamp with time zone NOT NULL,
+	result_job_id uuid REFERENCES provisioner_jobs (id) ON DELETE SET NULL,
+	checked_at timestamp with time zone
+);
+
+COMMENT ON TABLE workspace_drift_checks IS 'The latest drift check of each workspace. Drifted resources are the resource changes of the result job.';
+COMMENT ON COLUMN workspace_drift_checks.job_id IS 'The most recently scheduled drift check, which may still be pending.';
+COMMENT ON COLUMN workspace_drift_checks.result_job_id IS 'The most recent drift check that succeeded.';
+
+INSERT INTO
+	notification_templates (
+		id,
+		name,
+		title_template,
+		body_template,
+		"group",
+		actions
+	)
+VALUES (
+		'3b7e8f1c-5a6d-4e2f-9c1b-7d8a0e4f6b23',
+		'Workspace Drifted',
+		E'Workspace "{{.Labels.name}}" drifted',
+		E'Hi {{.UserName}}\n\n' ||
+		E'Resources of your workspace **{{.Labels.name}}** were changed outside of Coder: {{.Labels.resources}}.\n\n' ||
+		E'The next build of the workspace may revert or fail on these changes.',
+		'Workspace Events',
+		'[
+		{
+			"label": "View workspace",
+			"url": "{{ base_url }}/@{{.UserUsername}}/{{.Labels.name}}"
+		}
+	]'::jsonb
+	);
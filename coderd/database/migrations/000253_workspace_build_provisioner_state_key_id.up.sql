@@ -0,0 +1,22 @@
+ALTER TABLE workspace_builds
+	ADD COLUMN provisioner_state_key_id text REFERENCES dbcrypt_keys(active_key_digest);
+
+COMMENT ON COLUMN workspace_builds.provisioner_state_key_id IS 'The ID of the key used to encrypt the provisioner state. If this is NULL, the provisioner state is not encrypted';
+
+-- Update the workspace_build_with_user view by recreating it.
+DROP VIEW workspace_build_with_user;
+CREATE VIEW
+	workspace_build_with_user
+AS
+SELECT
+	workspace_builds.*,
+	coalesce(visible_users.avatar_url, '') AS initiator_by_avatar_url,
+	coalesce(visible_users.username, '') AS initiator_by_username
+FROM
+	workspace_builds
+	LEFT JOIN
+		visible_users
+	ON
+		workspace_builds.initiator_id = visible_users.id;
+
+COMMENT ON VIEW workspace_build_with_user IS 'Joins in the username + avatar url of the initiated by user.';
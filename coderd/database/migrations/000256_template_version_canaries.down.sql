@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS template_version_canaries;
+
+DROP TYPE IF EXISTS template_version_canary_status;
@@ -0,0 +1,30 @@
+CREATE TYPE template_version_canary_status AS ENUM (
+	'active',
+	'promoted',
+	'rolled_back'
+);
+
+CREATE TABLE template_version_canaries (
+	id uuid NOT NULL PRIMARY KEY,
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	template_version_id uuid NOT NULL REFERENCES template_versions (id) ON DELETE CASCADE,
+	percent integer NOT NULL CHECK (percent >= 0 AND percent <= 100),
+	group_id uuid REFERENCES groups (id) ON DELETE SET NULL,
+	min_builds integer NOT NULL CHECK (min_builds > 0),
+	success_threshold integer NOT NULL CHECK (success_threshold >= 0 AND success_threshold <= 100),
+	status template_version_canary_status NOT NULL DEFAULT 'active',
+	status_reason text NOT NULL DEFAULT '',
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE RESTRICT,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	completed_at timestamp with time zone
+);
+
+COMMENT ON TABLE template_version_canaries IS 'Staged rollouts of template versions. While a canary is active, workspaces in its cohort are built with the canary version instead of the active version.';
+COMMENT ON COLUMN template_version_canaries.percent IS 'Percentage of the template''s workspaces in the cohort, selected by a hash of the workspace ID.';
+COMMENT ON COLUMN template_version_canaries.group_id IS 'Workspaces owned by members of this group are in the cohort regardless of percent.';
+COMMENT ON COLUMN template_version_canaries.min_builds IS 'Number of finished canary builds required before the canary is promoted or rolled back.';
+COMMENT ON COLUMN template_version_canaries.success_threshold IS 'Percentage of finished canary builds that must succeed with healthy agents for the canary to be promoted.';
+
+-- Only one canary per template may be active at a time.
+CREATE UNIQUE INDEX template_version_canaries_active_template_id_idx ON template_version_canaries (template_id) WHERE status = 'active';
@@ -0,0 +1,3 @@
+ALTER TABLE api_keys
+	DROP COLUMN allow_list,
+	DROP COLUMN scopes;
@@ -0,0 +1,7 @@
+ALTER TABLE api_keys
+	ADD COLUMN scopes text[] NOT NULL DEFAULT '{}'::text[],
+	ADD COLUMN allow_list text[] NOT NULL DEFAULT '{}'::text[];
+
+COMMENT ON COLUMN api_keys.scopes IS 'Named scopes from the scope catalog. If any are set, the key is limited to them instead of scope.';
+
+COMMENT ON COLUMN api_keys.allow_list IS 'Resources the key is limited to, as "<type>:<id>". An empty list allows every resource.';
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS oauth2_provider_app_device_codes;
+
+DROP TYPE IF EXISTS oauth2_provider_device_code_status;
+
+-- Tokens issued to public clients have no secret to link back to.
+DELETE FROM oauth2_provider_app_tokens WHERE app_secret_id IS NULL;
+
+ALTER TABLE oauth2_provider_app_tokens
+	ALTER COLUMN app_secret_id SET NOT NULL,
+	DROP COLUMN IF EXISTS app_id;
+
+ALTER TABLE oauth2_provider_app_codes
+	DROP COLUMN IF EXISTS code_challenge,
+	DROP COLUMN IF EXISTS code_challenge_method;
+
+ALTER TABLE oauth2_provider_apps
+	DROP COLUMN IF EXISTS public,
+	DROP COLUMN IF EXISTS client_credentials_user_id;
@@ -0,0 +1,47 @@
+ALTER TABLE oauth2_provider_apps
+	ADD COLUMN public boolean NOT NULL DEFAULT false,
+	ADD COLUMN client_credentials_user_id uuid REFERENCES users (id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN oauth2_provider_apps.public IS 'Public clients cannot keep a secret, so they authenticate without one and must use PKCE for the authorization code grant.';
+COMMENT ON COLUMN oauth2_provider_apps.client_credentials_user_id IS 'The user that tokens issued with the client credentials grant act as. The grant is disabled when this is null.';
+
+ALTER TABLE oauth2_provider_app_codes
+	ADD COLUMN code_challenge text NOT NULL DEFAULT '',
+	ADD COLUMN code_challenge_method text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN oauth2_provider_app_codes.code_challenge IS 'PKCE code challenge sent with the authorization request. Empty if the client did not use PKCE.';
+
+-- Public clients have no secret, so tokens reference the app directly and the
+-- secret is optional.
+ALTER TABLE oauth2_provider_app_tokens
+	ADD COLUMN app_id uuid REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE;
+
+UPDATE oauth2_provider_app_tokens
+SET app_id = oauth2_provider_app_secrets.app_id
+FROM oauth2_provider_app_secrets
+WHERE oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id;
+
+ALTER TABLE oauth2_provider_app_tokens
+	ALTER COLUMN app_id SET NOT NULL,
+	ALTER COLUMN app_secret_id DROP NOT NULL;
+
+CREATE TYPE oauth2_provider_device_code_status AS ENUM (
+	'pending',
+	'approved',
+	'denied'
+);
+
+CREATE TABLE oauth2_provider_app_device_codes (
+	id uuid NOT NULL PRIMARY KEY,
+	created_at timestamp with time zone NOT NULL,
+	expires_at timestamp with time zone NOT NULL,
+	device_prefix bytea NOT NULL UNIQUE,
+	hashed_device_code bytea NOT NULL,
+	user_code text NOT NULL UNIQUE,
+	app_id uuid NOT NULL REFERENCES oauth2_provider_apps (id) ON DELETE CASCADE,
+	user_id uuid REFERENCES users (id) ON DELETE CASCADE,
+	status oauth2_provider_device_code_status NOT NULL DEFAULT 'pending'
+);
+
+COMMENT ON TABLE oauth2_provider_app_device_codes IS 'Device codes are polled by input constrained devices and exchanged for access tokens once a user approves the matching user code.';
+COMMENT ON COLUMN oauth2_provider_app_device_codes.user_id IS 'The user that approved or denied the code. Null while the code is pending.';
@@ -0,0 +1,6 @@
+DELETE FROM oauth2_provider_app_device_codes WHERE app_id IS NULL;
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.app_id IS NULL;
+
+ALTER TABLE oauth2_provider_app_device_codes
+	ALTER COLUMN app_id SET NOT NULL;
@@ -0,0 +1,6 @@
+-- Device logins of the CLI are stored with the device codes of OAuth2 apps,
+-- so both share the same verification page and clean up.
+ALTER TABLE oauth2_provider_app_device_codes
+	ALTER COLUMN app_id DROP NOT NULL;
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.app_id IS 'The app that requested the code. Null for device logins of the CLI, which are exchanged for a session token instead.';
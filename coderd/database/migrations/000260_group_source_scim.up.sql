@@ -0,0 +1 @@
+ALTER TYPE group_source ADD VALUE IF NOT EXISTS 'scim';
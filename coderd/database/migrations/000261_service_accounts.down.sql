@@ -0,0 +1,3 @@
+ALTER TABLE users
+	DROP COLUMN service_account_organization_id,
+	DROP COLUMN is_service_account;
@@ -0,0 +1,7 @@
+ALTER TABLE users
+	ADD COLUMN is_service_account boolean NOT NULL DEFAULT false,
+	ADD COLUMN service_account_organization_id uuid REFERENCES organizations (id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN users.is_service_account IS 'Service accounts belong to a single organization, cannot log in interactively and only authenticate with API tokens. They are counted against the licensed service account limit, or against the user limit if there is none.';
+
+COMMENT ON COLUMN users.service_account_organization_id IS 'The organization that owns the service account. The service account cannot be removed from it, and its admins can manage the service account.';
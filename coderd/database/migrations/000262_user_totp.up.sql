@@ -0,0 +1,13 @@
+CREATE TABLE user_totp (
+	user_id uuid NOT NULL PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+	secret text NOT NULL,
+	secret_key_id text REFERENCES dbcrypt_keys (active_key_digest),
+	hashed_recovery_codes text[] NOT NULL DEFAULT '{}'::text[],
+	created_at timestamp with time zone NOT NULL,
+	enabled_at timestamp with time zone
+);
+
+COMMENT ON TABLE user_totp IS 'Time-based one-time password (TOTP) second factors for password logins.';
+COMMENT ON COLUMN user_totp.secret_key_id IS 'The ID of the key used to encrypt the secret. If this is NULL, the secret is not encrypted';
+COMMENT ON COLUMN user_totp.hashed_recovery_codes IS 'SHA256 hashes of the unused single-use recovery codes.';
+COMMENT ON COLUMN user_totp.enabled_at IS 'When the user confirmed enrollment with a valid code. Null while enrollment is pending.';
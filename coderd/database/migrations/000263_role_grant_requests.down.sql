@@ -0,0 +1,8 @@
+DELETE FROM notification_templates WHERE id IN (
+	'755eb0c2-553c-4111-aaf3-2be4af251610',
+	'077e1a0c-51d1-44d4-b882-fae3b9ff5b6e',
+	'bc5162da-1835-4cc3-b0f7-a508d909296d'
+);
+
+DROP TABLE IF EXISTS role_grant_requests;
+DROP TYPE IF EXISTS role_grant_status;
@@ -0,0 +1,51 @@
+CREATE TYPE role_grant_status AS ENUM (
+	'pending',
+	'approved',
+	'denied',
+	'expired',
+	'revoked'
+);
+
+CREATE TABLE role_grant_requests (
+	id uuid NOT NULL PRIMARY KEY,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	organization_id uuid REFERENCES organizations (id) ON DELETE CASCADE,
+	role_name text NOT NULL,
+	reason text NOT NULL,
+	lifetime_seconds bigint NOT NULL,
+	status role_grant_status NOT NULL DEFAULT 'pending'::role_grant_status,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	reviewed_by uuid REFERENCES users (id) ON DELETE SET NULL,
+	reviewed_at timestamp with time zone,
+	expires_at timestamp with time zone
+);
+
+COMMENT ON TABLE role_grant_requests IS 'Requests for a site or organization role that is granted for a limited time once approved.';
+COMMENT ON COLUMN role_grant_requests.organization_id IS 'The organization of the requested role. NULL for site-wide roles.';
+COMMENT ON COLUMN role_grant_requests.lifetime_seconds IS 'How long the role is granted for once the request is approved.';
+COMMENT ON COLUMN role_grant_requests.expires_at IS 'When the granted role is removed. Set when the request is approved.';
+
+-- A user can only have one open request or active grant for a role at a time.
+CREATE UNIQUE INDEX role_grant_requests_open_idx ON role_grant_requests (user_id, COALESCE(organization_id, '00000000-0000-0000-0000-000000000000'::uuid), role_name) WHERE status IN ('pending', 'approved');
+
+CREATE INDEX role_grant_requests_expires_at_idx ON role_grant_requests (expires_at) WHERE status = 'approved';
+
+-- No equivalent in down migration because ENUM values cannot be deleted.
+ALTER TYPE resource_type ADD VALUE IF NOT EXISTS 'role_grant_request';
+
+INSERT INTO notification_templates (id, name, title_template, body_template, "group", actions)
+VALUES ('755eb0c2-553c-4111-aaf3-2be4af251610', 'Role Grant Approved', E'Role "{{.Labels.role}}" granted until {{.Labels.expires_at}}',
+		E'Hi {{.UserName}},\n\nYour request for the **{{.Labels.role}}** role was approved by **{{.Labels.reviewer}}**.\n\n' ||
+		E'The role will be removed automatically at {{.Labels.expires_at}}.',
+		'User Events', '[]'::jsonb);
+
+INSERT INTO notification_templates (id, name, title_template, body_template, "group", actions)
+VALUES ('077e1a0c-51d1-44d4-b882-fae3b9ff5b6e', 'Role Grant Denied', E'Request for role "{{.Labels.role}}" denied',
+		E'Hi {{.UserName}},\n\nYour request for the **{{.Labels.role}}** role was denied by **{{.Labels.reviewer}}**.',
+		'User Events', '[]'::jsonb);
+
+INSERT INTO notification_templates (id, name, title_template, body_template, "group", actions)
+VALUES ('bc5162da-1835-4cc3-b0f7-a508d909296d', 'Role Grant Ended', E'Role "{{.Labels.role}}" removed',
+		E'Hi {{.UserName}},\n\nYour temporary **{{.Labels.role}}** role has been removed because it {{.Labels.reason}}.',
+		'User Events', '[]'::jsonb);
@@ -0,0 +1,3 @@
+ALTER TABLE api_keys ADD COLUMN user_agent text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN api_keys.user_agent IS 'User agent of the client that last used the key.';
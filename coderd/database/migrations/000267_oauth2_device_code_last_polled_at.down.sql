@@ -0,0 +1,2 @@
+ALTER TABLE oauth2_provider_app_device_codes
+	DROP COLUMN IF EXISTS last_polled_at;
@@ -0,0 +1,4 @@
+ALTER TABLE oauth2_provider_app_device_codes
+	ADD COLUMN last_polled_at timestamp with time zone;
+
+COMMENT ON COLUMN oauth2_provider_app_device_codes.last_polled_at IS 'When the token endpoint was last polled with the code. Used to tell clients that poll faster than the interval to slow down.';
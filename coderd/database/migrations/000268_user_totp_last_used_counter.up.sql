@@ -0,0 +1,4 @@
+ALTER TABLE user_totp
+	ADD COLUMN last_used_counter bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN user_totp.last_used_counter IS 'The time step of the last accepted code. Codes from this or an earlier time step are rejected so they cannot be replayed.';
@@ -0,0 +1,4 @@
+INSERT INTO derp_health_history (created_at, replica_id, region_id, region_code, node_name, healthy, can_exchange_messages, round_trip_ping_ms, packet_loss, stun_enabled, can_stun, error)
+VALUES
+	(NOW() - INTERVAL '10 minutes', 'a4b7fc5d-2d48-4f0e-8f53-7e1b8f0e2e3c', 999, 'coder', '999a', true, true, 12, 0, true, true, ''),
+	(NOW() - INTERVAL '5 minutes', 'a4b7fc5d-2d48-4f0e-8f53-7e1b8f0e2e3c', 999, 'coder', '999a', false, false, 0, 1, true, false, 'dial tcp: i/o timeout');
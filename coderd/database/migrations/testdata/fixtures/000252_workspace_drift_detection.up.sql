@@ -0,0 +1,3 @@
+INSERT INTO workspace_drift_checks (workspace_id, job_id, scheduled_at, result_job_id, checked_at)
+VALUES
+	('b90547be-8870-4d68-8184-e8b2242b7c01', '424a58cb-61d6-4627-9907-613c396c4a38', '2024-09-20 10:00:00+00', '424a58cb-61d6-4627-9907-613c396c4a38', '2024-09-20 10:01:00+00');
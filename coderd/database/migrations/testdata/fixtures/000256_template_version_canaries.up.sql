@@ -0,0 +1,3 @@
+INSERT INTO template_version_canaries (id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at)
+VALUES
+	('6d1f5a6e-2c3b-4a8e-9f0d-1b2c3d4e5f60', '4cc1f466-f326-477e-8762-9d0c6781fc56', '4e681a60-83da-42c2-902e-6535376ebb77', 10, NULL, 5, 90, 'promoted', 'Canary builds met the success threshold.', '30095c71-380b-457a-8995-97b8ee6e5307', '2024-09-20 10:00:00+00', '2024-09-20 12:00:00+00', '2024-09-20 12:00:00+00');
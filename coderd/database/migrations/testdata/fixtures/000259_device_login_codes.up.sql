@@ -0,0 +1,12 @@
+INSERT INTO oauth2_provider_app_device_codes
+	(id, created_at, expires_at, device_prefix, hashed_device_code, user_code, user_id, status)
+VALUES (
+	'f0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11',
+	'2023-06-15 10:23:54+00',
+	'2023-06-15 10:33:54+00',
+	CAST('opqrstu' AS bytea),
+	CAST('opqrstu' AS bytea),
+	'LMNPQRST',
+	'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+	'approved'
+);
@@ -0,0 +1,9 @@
+INSERT INTO user_totp
+	(user_id, secret, hashed_recovery_codes, created_at, enabled_at)
+VALUES (
+	'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+	'JBSWY3DPEHPK3PXP',
+	ARRAY['4d967a30111bf29f0eba01c448b375c1629b2fed01cdfcc3aed91f1b57d5dd5e'],
+	'2023-06-15 10:23:54+00',
+	'2023-06-15 10:25:54+00'
+);
@@ -0,0 +1,16 @@
+INSERT INTO role_grant_requests
+	(id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at)
+VALUES (
+	'4ad1a5a3-6a37-4c5f-8f0e-0d0a5b0f7c11',
+	'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+	'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+	'organization-template-admin',
+	'Debugging a broken template',
+	3600,
+	'approved',
+	'2023-06-15 10:23:54+00',
+	'2023-06-15 10:25:54+00',
+	'30095c71-380b-457a-8995-97b8ee6e5307',
+	'2023-06-15 10:25:54+00',
+	'2023-06-15 11:25:54+00'
+);
@@ -1,8 +1,10 @@
 package database
 
 import (
+	"slices"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -127,6 +129,47 @@ func (s APIKeyScope) ToRBAC() rbac.ScopeName {
 	}
 }
 
+// ScopeRBAC returns the scope that requests made with the key are limited to.
+// Keys with named scopes are also limited to the resources in their allow
+// list, which always includes the user that owns the key.
+func (k APIKey) ScopeRBAC() rbac.ExpandableScope {
+	if len(k.Scopes) == 0 {
+		return k.Scope.ToRBAC()
+	}
+
+	set := rbac.ScopeSet{
+		Names: make([]rbac.ScopeName, 0, len(k.Scopes)),
+	}
+	for _, name := range k.Scopes {
+		set.Names = append(set.Names, rbac.ScopeName(name))
+	}
+	if len(k.AllowList) > 0 {
+		set.AllowIDList = []string{k.UserID.String()}
+		for _, entry := range k.AllowList {
+			// Entries are stored as "<type>:<id>", the type is only kept
+			// for display.
+			_, id, _ := strings.Cut(entry, ":")
+			set.AllowIDList = append(set.AllowIDList, id)
+		}
+	}
+	return set
+}
+
+// SessionLoginTypes are the login types of API keys created by logging in,
+// as opposed to API tokens and workspace agent keys.
+var SessionLoginTypes = []LoginType{
+	LoginTypePassword,
+	LoginTypeGithub,
+	LoginTypeOIDC,
+	LoginTypeLDAP,
+}
+
+// IsSession reports whether the key is a login session. Workspace app keys
+// are derived from sessions but are not sessions themselves.
+func (k APIKey) IsSession() bool {
+	return k.Scope == APIKeyScopeAll && slices.Contains(SessionLoginTypes, k.LoginType)
+}
+
 func (k APIKey) RBACObject() rbac.Object {
 	return rbac.ResourceApiKey.WithIDString(k.ID).
 		WithOwner(k.UserID.String())
@@ -262,11 +305,21 @@ func (f File) RBACObject() rbac.Object {
 
 // RBACObject returns the RBAC object for the site wide user resource.
 func (u User) RBACObject() rbac.Object {
-	return rbac.ResourceUserObject(u.ID)
+	return userRBACObject(u.ID, u.ServiceAccountOrganizationID)
 }
 
 func (u GetUsersRow) RBACObject() rbac.Object {
-	return rbac.ResourceUserObject(u.ID)
+	return userRBACObject(u.ID, u.ServiceAccountOrganizationID)
+}
+
+// userRBACObject places service accounts in the organization that owns them,
+// so the admins of that organization can manage them.
+func userRBACObject(id uuid.UUID, serviceAccountOrganizationID uuid.NullUUID) rbac.Object {
+	obj := rbac.ResourceUserObject(id)
+	if serviceAccountOrganizationID.Valid {
+		obj = obj.InOrg(serviceAccountOrganizationID.UUID)
+	}
+	return obj
 }
 
 func (u GitSSHKey) RBACObject() rbac.Object        { return rbac.ResourceUserObject(u.UserID) }
@@ -289,6 +342,14 @@ func (c OAuth2ProviderAppCode) RBACObject() rbac.Object {
 	return rbac.ResourceOauth2AppCodeToken.WithOwner(c.UserID.String())
 }
 
+func (c OAuth2ProviderAppDeviceCode) RBACObject() rbac.Object {
+	// Pending codes have not been claimed by a user yet, so they have no owner.
+	if !c.UserID.Valid {
+		return rbac.ResourceOauth2AppCodeToken
+	}
+	return rbac.ResourceOauth2AppCodeToken.WithOwner(c.UserID.UUID.String())
+}
+
 func (OAuth2ProviderAppSecret) RBACObject() rbac.Object {
 	return rbac.ResourceOauth2AppSecret
 }
@@ -360,20 +421,22 @@ func ConvertUserRows(rows []GetUsersRow) []User {
 	users := make([]User, len(rows))
 	for i, r := range rows {
 		users[i] = User{
-			ID:              r.ID,
-			Email:           r.Email,
-			Username:        r.Username,
-			Name:            r.Name,
-			HashedPassword:  r.HashedPassword,
-			CreatedAt:       r.CreatedAt,
-			UpdatedAt:       r.UpdatedAt,
-			Status:          r.Status,
-			RBACRoles:       r.RBACRoles,
-			LoginType:       r.LoginType,
-			AvatarURL:       r.AvatarURL,
-			Deleted:         r.Deleted,
-			LastSeenAt:      r.LastSeenAt,
-			ThemePreference: r.ThemePreference,
+			ID:                           r.ID,
+			Email:                        r.Email,
+			Username:                     r.Username,
+			Name:                         r.Name,
+			HashedPassword:               r.HashedPassword,
+			CreatedAt:                    r.CreatedAt,
+			UpdatedAt:                    r.UpdatedAt,
+			Status:                       r.Status,
+			RBACRoles:                    r.RBACRoles,
+			LoginType:                    r.LoginType,
+			AvatarURL:                    r.AvatarURL,
+			Deleted:                      r.Deleted,
+			LastSeenAt:                   r.LastSeenAt,
+			ThemePreference:              r.ThemePreference,
+			IsServiceAccount:             r.IsServiceAccount,
+			ServiceAccountOrganizationID: r.ServiceAccountOrganizationID,
 		}
 	}
 
@@ -432,6 +495,23 @@ func (r CustomRole) RoleIdentifier() rbac.RoleIdentifier {
 	}
 }
 
+func (r RoleGrantRequest) RoleIdentifier() rbac.RoleIdentifier {
+	return rbac.RoleIdentifier{
+		Name:           r.RoleName,
+		OrganizationID: r.OrganizationID.UUID,
+	}
+}
+
+// RBACObject returns the object an approver must be able to assign. The
+// requester is deliberately not the owner, so holding a role grant request
+// never allows reviewing it.
+func (r RoleGrantRequest) RBACObject() rbac.Object {
+	if r.OrganizationID.Valid {
+		return rbac.ResourceAssignOrgRole.WithID(r.ID).InOrg(r.OrganizationID.UUID)
+	}
+	return rbac.ResourceAssignRole.WithID(r.ID)
+}
+
 func (r GetAuthorizationUserRolesRow) RoleNames() ([]rbac.RoleIdentifier, error) {
 	names := make([]rbac.RoleIdentifier, 0, len(r.Roles))
 	for _, role := range r.Roles {
@@ -116,6 +116,13 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			pq.Array(&i.AllowedWorkspaceProxyIDs),
+			pq.Array(&i.AllowedDERPRegionIDs),
+			&i.MaxAppConnectionsPerUser,
+			&i.MaxAppBytesPerSecond,
+			&i.DriftDetectionInterval,
+			&i.BuildTimeout,
+			&i.BuildCancelGracePeriod,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -363,6 +370,8 @@ func (q *sqlQuerier) GetAuthorizedUsers(ctx context.Context, arg GetUsersParams,
 			&i.ThemePreference,
 			&i.Name,
 			&i.GithubComUserID,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -447,6 +456,7 @@ func (q *sqlQuerier) GetAuthorizedAuditLogsOffset(ctx context.Context, arg GetAu
 			&i.UserDeleted,
 			&i.UserThemePreference,
 			&i.UserQuietHoursSchedule,
+			&i.UserIsServiceAccount,
 			&i.OrganizationName,
 			&i.OrganizationDisplayName,
 			&i.OrganizationIcon,
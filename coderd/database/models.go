@@ -146,6 +146,7 @@ const (
 	AuditActionLogin    AuditAction = "login"
 	AuditActionLogout   AuditAction = "logout"
 	AuditActionRegister AuditAction = "register"
+	AuditActionOpen     AuditAction = "open"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -192,7 +193,8 @@ func (e AuditAction) Valid() bool {
 		AuditActionStop,
 		AuditActionLogin,
 		AuditActionLogout,
-		AuditActionRegister:
+		AuditActionRegister,
+		AuditActionOpen:
 		return true
 	}
 	return false
@@ -208,6 +210,7 @@ func AllAuditActionValues() []AuditAction {
 		AuditActionLogin,
 		AuditActionLogout,
 		AuditActionRegister,
+		AuditActionOpen,
 	}
 }
 
@@ -411,6 +414,7 @@ type GroupSource string
 const (
 	GroupSourceUser GroupSource = "user"
 	GroupSourceOidc GroupSource = "oidc"
+	GroupSourceScim GroupSource = "scim"
 )
 
 func (e *GroupSource) Scan(src interface{}) error {
@@ -451,7 +455,8 @@ func (ns NullGroupSource) Value() (driver.Value, error) {
 func (e GroupSource) Valid() bool {
 	switch e {
 	case GroupSourceUser,
-		GroupSourceOidc:
+		GroupSourceOidc,
+		GroupSourceScim:
 		return true
 	}
 	return false
@@ -461,6 +466,7 @@ func AllGroupSourceValues() []GroupSource {
 	return []GroupSource{
 		GroupSourceUser,
 		GroupSourceOidc,
+		GroupSourceScim,
 	}
 }
 
@@ -599,6 +605,7 @@ const (
 	LoginTypeToken             LoginType = "token"
 	LoginTypeNone              LoginType = "none"
 	LoginTypeOAuth2ProviderApp LoginType = "oauth2_provider_app"
+	LoginTypeLDAP              LoginType = "ldap"
 )
 
 func (e *LoginType) Scan(src interface{}) error {
@@ -643,7 +650,8 @@ func (e LoginType) Valid() bool {
 		LoginTypeOIDC,
 		LoginTypeToken,
 		LoginTypeNone,
-		LoginTypeOAuth2ProviderApp:
+		LoginTypeOAuth2ProviderApp,
+		LoginTypeLDAP:
 		return true
 	}
 	return false
@@ -657,6 +665,7 @@ func AllLoginTypeValues() []LoginType {
 		LoginTypeToken,
 		LoginTypeNone,
 		LoginTypeOAuth2ProviderApp,
+		LoginTypeLDAP,
 	}
 }
 
@@ -846,6 +855,67 @@ func AllNotificationTemplateKindValues() []NotificationTemplateKind {
 	}
 }
 
+type OAuth2ProviderDeviceCodeStatus string
+
+const (
+	OAuth2ProviderDeviceCodeStatusPending  OAuth2ProviderDeviceCodeStatus = "pending"
+	OAuth2ProviderDeviceCodeStatusApproved OAuth2ProviderDeviceCodeStatus = "approved"
+	OAuth2ProviderDeviceCodeStatusDenied   OAuth2ProviderDeviceCodeStatus = "denied"
+)
+
+func (e *OAuth2ProviderDeviceCodeStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = OAuth2ProviderDeviceCodeStatus(s)
+	case string:
+		*e = OAuth2ProviderDeviceCodeStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for OAuth2ProviderDeviceCodeStatus: %T", src)
+	}
+	return nil
+}
+
+type NullOAuth2ProviderDeviceCodeStatus struct {
+	OAuth2ProviderDeviceCodeStatus OAuth2ProviderDeviceCodeStatus `json:"oauth2_provider_device_code_status"`
+	Valid                          bool                           `json:"valid"` // Valid is true if OAuth2ProviderDeviceCodeStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullOAuth2ProviderDeviceCodeStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.OAuth2ProviderDeviceCodeStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.OAuth2ProviderDeviceCodeStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullOAuth2ProviderDeviceCodeStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.OAuth2ProviderDeviceCodeStatus), nil
+}
+
+func (e OAuth2ProviderDeviceCodeStatus) Valid() bool {
+	switch e {
+	case OAuth2ProviderDeviceCodeStatusPending,
+		OAuth2ProviderDeviceCodeStatusApproved,
+		OAuth2ProviderDeviceCodeStatusDenied:
+		return true
+	}
+	return false
+}
+
+func AllOAuth2ProviderDeviceCodeStatusValues() []OAuth2ProviderDeviceCodeStatus {
+	return []OAuth2ProviderDeviceCodeStatus{
+		OAuth2ProviderDeviceCodeStatusPending,
+		OAuth2ProviderDeviceCodeStatusApproved,
+		OAuth2ProviderDeviceCodeStatusDenied,
+	}
+}
+
 type ParameterDestinationScheme string
 
 const (
@@ -1142,6 +1212,68 @@ func AllPortShareProtocolValues() []PortShareProtocol {
 	}
 }
 
+// Scheduling class of a provisioner job. Pending jobs in an earlier class are always acquired before jobs in a later class.
+type ProvisionerJobPriority string
+
+const (
+	ProvisionerJobPriorityInteractive    ProvisionerJobPriority = "interactive"
+	ProvisionerJobPriorityTemplateImport ProvisionerJobPriority = "template_import"
+	ProvisionerJobPriorityAutobuild      ProvisionerJobPriority = "autobuild"
+)
+
+func (e *ProvisionerJobPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProvisionerJobPriority(s)
+	case string:
+		*e = ProvisionerJobPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProvisionerJobPriority: %T", src)
+	}
+	return nil
+}
+
+type NullProvisionerJobPriority struct {
+	ProvisionerJobPriority ProvisionerJobPriority `json:"provisioner_job_priority"`
+	Valid                  bool                   `json:"valid"` // Valid is true if ProvisionerJobPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProvisionerJobPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProvisionerJobPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProvisionerJobPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProvisionerJobPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ProvisionerJobPriority), nil
+}
+
+func (e ProvisionerJobPriority) Valid() bool {
+	switch e {
+	case ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityTemplateImport,
+		ProvisionerJobPriorityAutobuild:
+		return true
+	}
+	return false
+}
+
+func AllProvisionerJobPriorityValues() []ProvisionerJobPriority {
+	return []ProvisionerJobPriority{
+		ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityTemplateImport,
+		ProvisionerJobPriorityAutobuild,
+	}
+}
+
 // Computed status of a provisioner job. Jobs could be stuck in a hung state, these states do not guarantee any transition to another state.
 type ProvisionerJobStatus string
 
@@ -1286,6 +1418,7 @@ const (
 	ProvisionerJobTypeTemplateVersionImport ProvisionerJobType = "template_version_import"
 	ProvisionerJobTypeWorkspaceBuild        ProvisionerJobType = "workspace_build"
 	ProvisionerJobTypeTemplateVersionDryRun ProvisionerJobType = "template_version_dry_run"
+	ProvisionerJobTypeWorkspaceBuildPlan    ProvisionerJobType = "workspace_build_plan"
 )
 
 func (e *ProvisionerJobType) Scan(src interface{}) error {
@@ -1327,7 +1460,8 @@ func (e ProvisionerJobType) Valid() bool {
 	switch e {
 	case ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
-		ProvisionerJobTypeTemplateVersionDryRun:
+		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceBuildPlan:
 		return true
 	}
 	return false
@@ -1338,6 +1472,7 @@ func AllProvisionerJobTypeValues() []ProvisionerJobType {
 		ProvisionerJobTypeTemplateVersionImport,
 		ProvisionerJobTypeWorkspaceBuild,
 		ProvisionerJobTypeTemplateVersionDryRun,
+		ProvisionerJobTypeWorkspaceBuildPlan,
 	}
 }
 
@@ -1401,6 +1536,7 @@ type ProvisionerType string
 const (
 	ProvisionerTypeEcho      ProvisionerType = "echo"
 	ProvisionerTypeTerraform ProvisionerType = "terraform"
+	ProvisionerTypeTofu      ProvisionerType = "tofu"
 )
 
 func (e *ProvisionerType) Scan(src interface{}) error {
@@ -1441,7 +1577,8 @@ func (ns NullProvisionerType) Value() (driver.Value, error) {
 func (e ProvisionerType) Valid() bool {
 	switch e {
 	case ProvisionerTypeEcho,
-		ProvisionerTypeTerraform:
+		ProvisionerTypeTerraform,
+		ProvisionerTypeTofu:
 		return true
 	}
 	return false
@@ -1451,6 +1588,72 @@ func AllProvisionerTypeValues() []ProvisionerType {
 	return []ProvisionerType{
 		ProvisionerTypeEcho,
 		ProvisionerTypeTerraform,
+		ProvisionerTypeTofu,
+	}
+}
+
+// The action Terraform plans to take on a resource. Replace covers both delete-then-create and create-then-delete.
+type ResourceChangeAction string
+
+const (
+	ResourceChangeActionCreate  ResourceChangeAction = "create"
+	ResourceChangeActionUpdate  ResourceChangeAction = "update"
+	ResourceChangeActionReplace ResourceChangeAction = "replace"
+	ResourceChangeActionDelete  ResourceChangeAction = "delete"
+)
+
+func (e *ResourceChangeAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ResourceChangeAction(s)
+	case string:
+		*e = ResourceChangeAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ResourceChangeAction: %T", src)
+	}
+	return nil
+}
+
+type NullResourceChangeAction struct {
+	ResourceChangeAction ResourceChangeAction `json:"resource_change_action"`
+	Valid                bool                 `json:"valid"` // Valid is true if ResourceChangeAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullResourceChangeAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.ResourceChangeAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ResourceChangeAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullResourceChangeAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ResourceChangeAction), nil
+}
+
+func (e ResourceChangeAction) Valid() bool {
+	switch e {
+	case ResourceChangeActionCreate,
+		ResourceChangeActionUpdate,
+		ResourceChangeActionReplace,
+		ResourceChangeActionDelete:
+		return true
+	}
+	return false
+}
+
+func AllResourceChangeActionValues() []ResourceChangeAction {
+	return []ResourceChangeAction{
+		ResourceChangeActionCreate,
+		ResourceChangeActionUpdate,
+		ResourceChangeActionReplace,
+		ResourceChangeActionDelete,
 	}
 }
 
@@ -1476,6 +1679,8 @@ const (
 	ResourceTypeOrganizationMember      ResourceType = "organization_member"
 	ResourceTypeNotificationsSettings   ResourceType = "notifications_settings"
 	ResourceTypeNotificationTemplate    ResourceType = "notification_template"
+	ResourceTypeRoleGrantRequest        ResourceType = "role_grant_request"
+	ResourceTypeTemplateVersionCanary   ResourceType = "template_version_canary"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -1533,7 +1738,9 @@ func (e ResourceType) Valid() bool {
 		ResourceTypeCustomRole,
 		ResourceTypeOrganizationMember,
 		ResourceTypeNotificationsSettings,
-		ResourceTypeNotificationTemplate:
+		ResourceTypeNotificationTemplate,
+		ResourceTypeRoleGrantRequest,
+		ResourceTypeTemplateVersionCanary:
 		return true
 	}
 	return false
@@ -1560,6 +1767,75 @@ func AllResourceTypeValues() []ResourceType {
 		ResourceTypeOrganizationMember,
 		ResourceTypeNotificationsSettings,
 		ResourceTypeNotificationTemplate,
+		ResourceTypeRoleGrantRequest,
+		ResourceTypeTemplateVersionCanary,
+	}
+}
+
+type RoleGrantStatus string
+
+const (
+	RoleGrantStatusPending  RoleGrantStatus = "pending"
+	RoleGrantStatusApproved RoleGrantStatus = "approved"
+	RoleGrantStatusDenied   RoleGrantStatus = "denied"
+	RoleGrantStatusExpired  RoleGrantStatus = "expired"
+	RoleGrantStatusRevoked  RoleGrantStatus = "revoked"
+)
+
+func (e *RoleGrantStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RoleGrantStatus(s)
+	case string:
+		*e = RoleGrantStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RoleGrantStatus: %T", src)
+	}
+	return nil
+}
+
+type NullRoleGrantStatus struct {
+	RoleGrantStatus RoleGrantStatus `json:"role_grant_status"`
+	Valid           bool            `json:"valid"` // Valid is true if RoleGrantStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullRoleGrantStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.RoleGrantStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.RoleGrantStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullRoleGrantStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.RoleGrantStatus), nil
+}
+
+func (e RoleGrantStatus) Valid() bool {
+	switch e {
+	case RoleGrantStatusPending,
+		RoleGrantStatusApproved,
+		RoleGrantStatusDenied,
+		RoleGrantStatusExpired,
+		RoleGrantStatusRevoked:
+		return true
+	}
+	return false
+}
+
+func AllRoleGrantStatusValues() []RoleGrantStatus {
+	return []RoleGrantStatus{
+		RoleGrantStatusPending,
+		RoleGrantStatusApproved,
+		RoleGrantStatusDenied,
+		RoleGrantStatusExpired,
+		RoleGrantStatusRevoked,
 	}
 }
 
@@ -1679,6 +1955,67 @@ func AllTailnetStatusValues() []TailnetStatus {
 	}
 }
 
+type TemplateVersionCanaryStatus string
+
+const (
+	TemplateVersionCanaryStatusActive     TemplateVersionCanaryStatus = "active"
+	TemplateVersionCanaryStatusPromoted   TemplateVersionCanaryStatus = "promoted"
+	TemplateVersionCanaryStatusRolledBack TemplateVersionCanaryStatus = "rolled_back"
+)
+
+func (e *TemplateVersionCanaryStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateVersionCanaryStatus(s)
+	case string:
+		*e = TemplateVersionCanaryStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateVersionCanaryStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateVersionCanaryStatus struct {
+	TemplateVersionCanaryStatus TemplateVersionCanaryStatus `json:"template_version_canary_status"`
+	Valid                       bool                        `json:"valid"` // Valid is true if TemplateVersionCanaryStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateVersionCanaryStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateVersionCanaryStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateVersionCanaryStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateVersionCanaryStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateVersionCanaryStatus), nil
+}
+
+func (e TemplateVersionCanaryStatus) Valid() bool {
+	switch e {
+	case TemplateVersionCanaryStatusActive,
+		TemplateVersionCanaryStatusPromoted,
+		TemplateVersionCanaryStatusRolledBack:
+		return true
+	}
+	return false
+}
+
+func AllTemplateVersionCanaryStatusValues() []TemplateVersionCanaryStatus {
+	return []TemplateVersionCanaryStatus{
+		TemplateVersionCanaryStatusActive,
+		TemplateVersionCanaryStatusPromoted,
+		TemplateVersionCanaryStatusRolledBack,
+	}
+}
+
 // Defines the users status: active, dormant, or suspended.
 type UserStatus string
 
@@ -2023,6 +2360,12 @@ type APIKey struct {
 	IPAddress       pqtype.Inet `db:"ip_address" json:"ip_address"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	// Named scopes from the scope catalog. If any are set, the key is limited to them instead of scope.
+	Scopes []string `db:"scopes" json:"scopes"`
+	// Resources the key is limited to, as "<type>:<id>". An empty list allows every resource.
+	AllowList []string `db:"allow_list" json:"allow_list"`
+	// User agent of the client that last used the key.
+	UserAgent string `db:"user_agent" json:"user_agent"`
 }
 
 type AuditLog struct {
@@ -2074,6 +2417,25 @@ type DBCryptKey struct {
 	Test string `db:"test" json:"test"`
 }
 
+// Results of the DERP healthcheck for each node, recorded periodically by each replica.
+type DERPHealthHistory struct {
+	ID                  int64     `db:"id" json:"id"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	ReplicaID           uuid.UUID `db:"replica_id" json:"replica_id"`
+	RegionID            int32     `db:"region_id" json:"region_id"`
+	RegionCode          string    `db:"region_code" json:"region_code"`
+	NodeName            string    `db:"node_name" json:"node_name"`
+	Healthy             bool      `db:"healthy" json:"healthy"`
+	CanExchangeMessages bool      `db:"can_exchange_messages" json:"can_exchange_messages"`
+	// Round trip time of a message relayed through the node. Zero if no message could be exchanged.
+	RoundTripPingMs int32 `db:"round_trip_ping_ms" json:"round_trip_ping_ms"`
+	// Fraction of probe messages that were not relayed back, between 0 and 1.
+	PacketLoss  float32 `db:"packet_loss" json:"packet_loss"`
+	StunEnabled bool    `db:"stun_enabled" json:"stun_enabled"`
+	CanStun     bool    `db:"can_stun" json:"can_stun"`
+	Error       string  `db:"error" json:"error"`
+}
+
 type ExternalAuthLink struct {
 	ProviderID        string    `db:"provider_id" json:"provider_id"`
 	UserID            uuid.UUID `db:"user_id" json:"user_id"`
@@ -2213,6 +2575,10 @@ type OAuth2ProviderApp struct {
 	Name        string    `db:"name" json:"name"`
 	Icon        string    `db:"icon" json:"icon"`
 	CallbackURL string    `db:"callback_url" json:"callback_url"`
+	// Public clients cannot keep a secret, so they authenticate without one and must use PKCE for the authorization code grant.
+	Public bool `db:"public" json:"public"`
+	// The user that tokens issued with the client credentials grant act as. The grant is disabled when this is null.
+	ClientCredentialsUserID uuid.NullUUID `db:"client_credentials_user_id" json:"client_credentials_user_id"`
 }
 
 // Codes are meant to be exchanged for access tokens.
@@ -2224,6 +2590,26 @@ type OAuth2ProviderAppCode struct {
 	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
 	UserID       uuid.UUID `db:"user_id" json:"user_id"`
 	AppID        uuid.UUID `db:"app_id" json:"app_id"`
+	// PKCE code challenge sent with the authorization request. Empty if the client did not use PKCE.
+	CodeChallenge       string `db:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `db:"code_challenge_method" json:"code_challenge_method"`
+}
+
+// Device codes are polled by input constrained devices and exchanged for access tokens once a user approves the matching user code.
+type OAuth2ProviderAppDeviceCode struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt        time.Time `db:"expires_at" json:"expires_at"`
+	DevicePrefix     []byte    `db:"device_prefix" json:"device_prefix"`
+	HashedDeviceCode []byte    `db:"hashed_device_code" json:"hashed_device_code"`
+	UserCode         string    `db:"user_code" json:"user_code"`
+	// The app that requested the code. Null for device logins of the CLI, which are exchanged for a session token instead.
+	AppID uuid.NullUUID `db:"app_id" json:"app_id"`
+	// The user that approved or denied the code. Null while the code is pending.
+	UserID uuid.NullUUID                  `db:"user_id" json:"user_id"`
+	Status OAuth2ProviderDeviceCodeStatus `db:"status" json:"status"`
+	// When the token endpoint was last polled with the code. Used to tell clients that poll faster than the interval to slow down.
+	LastPolledAt sql.NullTime `db:"last_polled_at" json:"last_polled_at"`
 }
 
 type OAuth2ProviderAppSecret struct {
@@ -2243,9 +2629,10 @@ type OAuth2ProviderAppToken struct {
 	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
 	HashPrefix []byte    `db:"hash_prefix" json:"hash_prefix"`
 	// Refresh tokens provide a way to refresh an access token (API key). An expired API key can be refreshed if this token is not yet expired, meaning this expiry can outlive an API key.
-	RefreshHash []byte    `db:"refresh_hash" json:"refresh_hash"`
-	AppSecretID uuid.UUID `db:"app_secret_id" json:"app_secret_id"`
-	APIKeyID    string    `db:"api_key_id" json:"api_key_id"`
+	RefreshHash []byte        `db:"refresh_hash" json:"refresh_hash"`
+	AppSecretID uuid.NullUUID `db:"app_secret_id" json:"app_secret_id"`
+	APIKeyID    string        `db:"api_key_id" json:"api_key_id"`
+	AppID       uuid.UUID     `db:"app_id" json:"app_id"`
 }
 
 type Organization struct {
@@ -2257,6 +2644,10 @@ type Organization struct {
 	IsDefault   bool      `db:"is_default" json:"is_default"`
 	DisplayName string    `db:"display_name" json:"display_name"`
 	Icon        string    `db:"icon" json:"icon"`
+	// Workspace proxies that may serve apps for workspaces in this organization. The primary proxy is identified by the deployment ID. An empty list allows all proxies.
+	AllowedWorkspaceProxyIDs []uuid.UUID `db:"allowed_workspace_proxy_ids" json:"allowed_workspace_proxy_ids"`
+	// DERP regions that agents in this organization may relay through. An empty list allows all regions.
+	AllowedDERPRegionIDs []int32 `db:"allowed_derp_region_ids" json:"allowed_derp_region_ids"`
 }
 
 type OrganizationMember struct {
@@ -2334,6 +2725,8 @@ type ProvisionerJob struct {
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
 	// Computed column to track the status of the job.
 	JobStatus ProvisionerJobStatus `db:"job_status" json:"job_status"`
+	// Scheduling class of the job, set when the job is created.
+	Priority ProvisionerJobPriority `db:"priority" json:"priority"`
 }
 
 type ProvisionerJobLog struct {
@@ -2346,6 +2739,15 @@ type ProvisionerJobLog struct {
 	ID        int64     `db:"id" json:"id"`
 }
 
+// Resource changes planned by a workspace_build_plan job. Nothing in this table has been applied.
+type ProvisionerJobResourceChange struct {
+	JobID   uuid.UUID            `db:"job_id" json:"job_id"`
+	Address string               `db:"address" json:"address"`
+	Type    string               `db:"type" json:"type"`
+	Name    string               `db:"name" json:"name"`
+	Action  ResourceChangeAction `db:"action" json:"action"`
+}
+
 type ProvisionerJobStat struct {
 	JobID          uuid.UUID            `db:"job_id" json:"job_id"`
 	JobStatus      ProvisionerJobStatus `db:"job_status" json:"job_status"`
@@ -2397,6 +2799,25 @@ type Replica struct {
 	Primary         bool         `db:"primary" json:"primary"`
 }
 
+// Requests for a site or organization role that is granted for a limited time once approved.
+type RoleGrantRequest struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// The organization of the requested role. NULL for site-wide roles.
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	RoleName       string        `db:"role_name" json:"role_name"`
+	Reason         string        `db:"reason" json:"reason"`
+	// How long the role is granted for once the request is approved.
+	LifetimeSeconds int64           `db:"lifetime_seconds" json:"lifetime_seconds"`
+	Status          RoleGrantStatus `db:"status" json:"status"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updated_at"`
+	ReviewedBy      uuid.NullUUID   `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt      sql.NullTime    `db:"reviewed_at" json:"reviewed_at"`
+	// When the granted role is removed. Set when the request is approved.
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+}
+
 type SiteConfig struct {
 	Key   string `db:"key" json:"key"`
 	Value string `db:"value" json:"value"`
@@ -2474,6 +2895,13 @@ type Template struct {
 	Deprecated                    string          `db:"deprecated" json:"deprecated"`
 	ActivityBump                  int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel           AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	AllowedWorkspaceProxyIDs      []uuid.UUID     `db:"allowed_workspace_proxy_ids" json:"allowed_workspace_proxy_ids"`
+	AllowedDERPRegionIDs          []int32         `db:"allowed_derp_region_ids" json:"allowed_derp_region_ids"`
+	MaxAppConnectionsPerUser      int64           `db:"max_app_connections_per_user" json:"max_app_connections_per_user"`
+	MaxAppBytesPerSecond          int64           `db:"max_app_bytes_per_second" json:"max_app_bytes_per_second"`
+	DriftDetectionInterval        int64           `db:"drift_detection_interval" json:"drift_detection_interval"`
+	BuildTimeout                  int64           `db:"build_timeout" json:"build_timeout"`
+	BuildCancelGracePeriod        int64           `db:"build_cancel_grace_period" json:"build_cancel_grace_period"`
 	CreatedByAvatarURL            string          `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername             string          `db:"created_by_username" json:"created_by_username"`
 	OrganizationName              string          `db:"organization_name" json:"organization_name"`
@@ -2519,6 +2947,20 @@ type TemplateTable struct {
 	Deprecated          string          `db:"deprecated" json:"deprecated"`
 	ActivityBump        int64           `db:"activity_bump" json:"activity_bump"`
 	MaxPortSharingLevel AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	// Workspace proxies that may serve apps for workspaces of this template. The primary proxy is identified by the deployment ID. An empty list allows all proxies.
+	AllowedWorkspaceProxyIDs []uuid.UUID `db:"allowed_workspace_proxy_ids" json:"allowed_workspace_proxy_ids"`
+	// DERP regions that agents of this template may relay through. An empty list allows all regions.
+	AllowedDERPRegionIDs []int32 `db:"allowed_derp_region_ids" json:"allowed_derp_region_ids"`
+	// Maximum number of concurrent workspace app connections per user on each replica or workspace proxy. Zero means the deployment limit applies.
+	MaxAppConnectionsPerUser int64 `db:"max_app_connections_per_user" json:"max_app_connections_per_user"`
+	// Maximum throughput of each workspace app in bytes per second on each replica or workspace proxy. Zero means the deployment limit applies.
+	MaxAppBytesPerSecond int64 `db:"max_app_bytes_per_second" json:"max_app_bytes_per_second"`
+	// How often running workspaces are checked for drift with a refresh-only plan. Zero disables drift detection.
+	DriftDetectionInterval int64 `db:"drift_detection_interval" json:"drift_detection_interval"`
+	// Maximum duration of a workspace build job in nanoseconds, after which it is canceled. Zero means no limit.
+	BuildTimeout int64 `db:"build_timeout" json:"build_timeout"`
+	// How long a canceled workspace build job may take to exit gracefully in nanoseconds before it is failed. Zero means the provisioner daemon default applies.
+	BuildCancelGracePeriod int64 `db:"build_cancel_grace_period" json:"build_cancel_grace_period"`
 }
 
 // Records aggregated usage statistics for templates/users. All usage is rounded up to the nearest minute.
@@ -2567,6 +3009,27 @@ type TemplateVersion struct {
 	CreatedByUsername     string          `db:"created_by_username" json:"created_by_username"`
 }
 
+// Staged rollouts of template versions. While a canary is active, workspaces in its cohort are built with the canary version instead of the active version.
+type TemplateVersionCanary struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	// Percentage of the template's workspaces in the cohort, selected by a hash of the workspace ID.
+	Percent int32 `db:"percent" json:"percent"`
+	// Workspaces owned by members of this group are in the cohort regardless of percent.
+	GroupID uuid.NullUUID `db:"group_id" json:"group_id"`
+	// Number of finished canary builds required before the canary is promoted or rolled back.
+	MinBuilds int32 `db:"min_builds" json:"min_builds"`
+	// Percentage of finished canary builds that must succeed with healthy agents for the canary to be promoted.
+	SuccessThreshold int32                       `db:"success_threshold" json:"success_threshold"`
+	Status           TemplateVersionCanaryStatus `db:"status" json:"status"`
+	StatusReason     string                      `db:"status_reason" json:"status_reason"`
+	CreatedBy        uuid.UUID                   `db:"created_by" json:"created_by"`
+	CreatedAt        time.Time                   `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time                   `db:"updated_at" json:"updated_at"`
+	CompletedAt      sql.NullTime                `db:"completed_at" json:"completed_at"`
+}
+
 type TemplateVersionParameter struct {
 	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
 	// Parameter name
@@ -2665,6 +3128,10 @@ type User struct {
 	Name string `db:"name" json:"name"`
 	// The GitHub.com numerical user ID. At time of implementation, this is used to check if the user has starred the Coder repository.
 	GithubComUserID sql.NullInt64 `db:"github_com_user_id" json:"github_com_user_id"`
+	// Service accounts belong to a single organization, cannot log in interactively and only authenticate with API tokens. They do not count toward licensed seats.
+	IsServiceAccount bool `db:"is_service_account" json:"is_service_account"`
+	// The organization that owns the service account. The service account cannot be removed from it, and its admins can manage the service account.
+	ServiceAccountOrganizationID uuid.NullUUID `db:"service_account_organization_id" json:"service_account_organization_id"`
 }
 
 type UserLink struct {
@@ -2682,6 +3149,21 @@ type UserLink struct {
 	DebugContext json.RawMessage `db:"debug_context" json:"debug_context"`
 }
 
+// Time-based one-time password (TOTP) second factors for password logins.
+type UserTOTP struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Secret string    `db:"secret" json:"secret"`
+	// The ID of the key used to encrypt the secret. If this is NULL, the secret is not encrypted
+	SecretKeyID sql.NullString `db:"secret_key_id" json:"secret_key_id"`
+	// SHA256 hashes of the unused single-use recovery codes.
+	HashedRecoveryCodes []string  `db:"hashed_recovery_codes" json:"hashed_recovery_codes"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	// When the user confirmed enrollment with a valid code. Null while enrollment is pending.
+	EnabledAt sql.NullTime `db:"enabled_at" json:"enabled_at"`
+	// The time step of the last accepted code. Codes from this or an earlier time step are rejected so they cannot be replayed.
+	LastUsedCounter int64 `db:"last_used_counter" json:"last_used_counter"`
+}
+
 // Visible fields of users are allowed to be joined with other tables for including context of other resources.
 type VisibleUser struct {
 	ID        uuid.UUID `db:"id" json:"id"`
@@ -2871,22 +3353,23 @@ type WorkspaceAppStat struct {
 
 // Joins in the username + avatar url of the initiated by user.
 type WorkspaceBuild struct {
-	ID                   uuid.UUID           `db:"id" json:"id"`
-	CreatedAt            time.Time           `db:"created_at" json:"created_at"`
-	UpdatedAt            time.Time           `db:"updated_at" json:"updated_at"`
-	WorkspaceID          uuid.UUID           `db:"workspace_id" json:"workspace_id"`
-	TemplateVersionID    uuid.UUID           `db:"template_version_id" json:"template_version_id"`
-	BuildNumber          int32               `db:"build_number" json:"build_number"`
-	Transition           WorkspaceTransition `db:"transition" json:"transition"`
-	InitiatorID          uuid.UUID           `db:"initiator_id" json:"initiator_id"`
-	ProvisionerState     []byte              `db:"provisioner_state" json:"provisioner_state"`
-	JobID                uuid.UUID           `db:"job_id" json:"job_id"`
-	Deadline             time.Time           `db:"deadline" json:"deadline"`
-	Reason               BuildReason         `db:"reason" json:"reason"`
-	DailyCost            int32               `db:"daily_cost" json:"daily_cost"`
-	MaxDeadline          time.Time           `db:"max_deadline" json:"max_deadline"`
-	InitiatorByAvatarUrl string              `db:"initiator_by_avatar_url" json:"initiator_by_avatar_url"`
-	InitiatorByUsername  string              `db:"initiator_by_username" json:"initiator_by_username"`
+	ID                    uuid.UUID           `db:"id" json:"id"`
+	CreatedAt             time.Time           `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time           `db:"updated_at" json:"updated_at"`
+	WorkspaceID           uuid.UUID           `db:"workspace_id" json:"workspace_id"`
+	TemplateVersionID     uuid.UUID           `db:"template_version_id" json:"template_version_id"`
+	BuildNumber           int32               `db:"build_number" json:"build_number"`
+	Transition            WorkspaceTransition `db:"transition" json:"transition"`
+	InitiatorID           uuid.UUID           `db:"initiator_id" json:"initiator_id"`
+	ProvisionerState      []byte              `db:"provisioner_state" json:"provisioner_state"`
+	JobID                 uuid.UUID           `db:"job_id" json:"job_id"`
+	Deadline              time.Time           `db:"deadline" json:"deadline"`
+	Reason                BuildReason         `db:"reason" json:"reason"`
+	DailyCost             int32               `db:"daily_cost" json:"daily_cost"`
+	MaxDeadline           time.Time           `db:"max_deadline" json:"max_deadline"`
+	ProvisionerStateKeyID sql.NullString      `db:"provisioner_state_key_id" json:"provisioner_state_key_id"`
+	InitiatorByAvatarUrl  string              `db:"initiator_by_avatar_url" json:"initiator_by_avatar_url"`
+	InitiatorByUsername   string              `db:"initiator_by_username" json:"initiator_by_username"`
 }
 
 type WorkspaceBuildParameter struct {
@@ -2912,6 +3395,19 @@ type WorkspaceBuildTable struct {
 	Reason            BuildReason         `db:"reason" json:"reason"`
 	DailyCost         int32               `db:"daily_cost" json:"daily_cost"`
 	MaxDeadline       time.Time           `db:"max_deadline" json:"max_deadline"`
+	// The ID of the key used to encrypt the provisioner state. If this is NULL, the provisioner state is not encrypted
+	ProvisionerStateKeyID sql.NullString `db:"provisioner_state_key_id" json:"provisioner_state_key_id"`
+}
+
+// The latest drift check of each workspace. Drifted resources are the resource changes of the result job.
+type WorkspaceDriftCheck struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	// The most recently scheduled drift check, which may still be pending.
+	JobID       uuid.UUID `db:"job_id" json:"job_id"`
+	ScheduledAt time.Time `db:"scheduled_at" json:"scheduled_at"`
+	// The most recent drift check that succeeded.
+	ResultJobID uuid.NullUUID `db:"result_job_id" json:"result_job_id"`
+	CheckedAt   sql.NullTime  `db:"checked_at" json:"checked_at"`
 }
 
 type WorkspaceProxy struct {
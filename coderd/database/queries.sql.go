@@ -144,9 +144,32 @@ func (q *sqlQuerier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context
 	return err
 }
 
+const deleteSessionAPIKeysByUserID = `-- name: DeleteSessionAPIKeysByUserID :exec
+DELETE FROM
+	api_keys
+WHERE
+	user_id = $1 AND
+	login_type = ANY($2 :: login_type[]) AND
+	id != $3
+`
+
+type DeleteSessionAPIKeysByUserIDParams struct {
+	UserID     uuid.UUID   `db:"user_id" json:"user_id"`
+	LoginTypes []LoginType `db:"login_types" json:"login_types"`
+	ExceptID   string      `db:"except_id" json:"except_id"`
+}
+
+// Deletes the login sessions of a user except the one with the given ID. All
+// workspace app keys of the user are deleted too, as they cannot be traced back
+// to the session they were created from.
+func (q *sqlQuerier) DeleteSessionAPIKeysByUserID(ctx context.Context, arg DeleteSessionAPIKeysByUserIDParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSessionAPIKeysByUserID, arg.UserID, pq.Array(arg.LoginTypes), arg.ExceptID)
+	return err
+}
+
 const getAPIKeyByID = `-- name: GetAPIKeyByID :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent
 FROM
 	api_keys
 WHERE
@@ -171,13 +194,16 @@ func (q *sqlQuerier) GetAPIKeyByID(ctx context.Context, id string) (APIKey, erro
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowList),
+		&i.UserAgent,
 	)
 	return i, err
 }
 
 const getAPIKeyByName = `-- name: GetAPIKeyByName :one
 SELECT
-	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent
 FROM
 	api_keys
 WHERE
@@ -210,12 +236,15 @@ func (q *sqlQuerier) GetAPIKeyByName(ctx context.Context, arg GetAPIKeyByNamePar
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowList),
+		&i.UserAgent,
 	)
 	return i, err
 }
 
 const getAPIKeysByLoginType = `-- name: GetAPIKeysByLoginType :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent FROM api_keys WHERE login_type = $1
 `
 
 func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error) {
@@ -240,6 +269,9 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AllowList),
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -255,7 +287,7 @@ func (q *sqlQuerier) GetAPIKeysByLoginType(ctx context.Context, loginType LoginT
 }
 
 const getAPIKeysByUserID = `-- name: GetAPIKeysByUserID :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE login_type = $1 AND user_id = $2
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent FROM api_keys WHERE login_type = $1 AND user_id = $2
 `
 
 type GetAPIKeysByUserIDParams struct {
@@ -285,6 +317,9 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AllowList),
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -300,7 +335,7 @@ func (q *sqlQuerier) GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUse
 }
 
 const getAPIKeysLastUsedAfter = `-- name: GetAPIKeysLastUsedAfter :many
-SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name FROM api_keys WHERE last_used > $1
+SELECT id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent FROM api_keys WHERE last_used > $1
 `
 
 func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error) {
@@ -325,6 +360,69 @@ func (q *sqlQuerier) GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.
 			&i.IPAddress,
 			&i.Scope,
 			&i.TokenName,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AllowList),
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSessionAPIKeysByUserID = `-- name: GetSessionAPIKeysByUserID :many
+SELECT
+	id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent
+FROM
+	api_keys
+WHERE
+	user_id = $1 AND
+	login_type = ANY($2 :: login_type[]) AND
+	scope = 'all'::api_key_scope AND
+	expires_at > NOW()
+ORDER BY
+	last_used DESC
+`
+
+type GetSessionAPIKeysByUserIDParams struct {
+	UserID     uuid.UUID   `db:"user_id" json:"user_id"`
+	LoginTypes []LoginType `db:"login_types" json:"login_types"`
+}
+
+// Returns the unexpired login sessions of a user, most recently used first.
+// Workspace app keys are derived from sessions and are not included.
+func (q *sqlQuerier) GetSessionAPIKeysByUserID(ctx context.Context, arg GetSessionAPIKeysByUserIDParams) ([]APIKey, error) {
+	rows, err := q.db.QueryContext(ctx, getSessionAPIKeysByUserID, arg.UserID, pq.Array(arg.LoginTypes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []APIKey
+	for rows.Next() {
+		var i APIKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.HashedSecret,
+			&i.UserID,
+			&i.LastUsed,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LoginType,
+			&i.LifetimeSeconds,
+			&i.IPAddress,
+			&i.Scope,
+			&i.TokenName,
+			pq.Array(&i.Scopes),
+			pq.Array(&i.AllowList),
+			&i.UserAgent,
 		); err != nil {
 			return nil, err
 		}
@@ -353,7 +451,10 @@ INSERT INTO
 		updated_at,
 		login_type,
 		scope,
-		token_name
+		token_name,
+		scopes,
+		allow_list,
+		user_agent
 	)
 VALUES
 	($1,
@@ -362,7 +463,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE $2::bigint
 	 END
-	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name
+	 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, hashed_secret, user_id, last_used, expires_at, created_at, updated_at, login_type, lifetime_seconds, ip_address, scope, token_name, scopes, allow_list, user_agent
 `
 
 type InsertAPIKeyParams struct {
@@ -378,6 +479,9 @@ type InsertAPIKeyParams struct {
 	LoginType       LoginType   `db:"login_type" json:"login_type"`
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 	TokenName       string      `db:"token_name" json:"token_name"`
+	Scopes          []string    `db:"scopes" json:"scopes"`
+	AllowList       []string    `db:"allow_list" json:"allow_list"`
+	UserAgent       string      `db:"user_agent" json:"user_agent"`
 }
 
 func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error) {
@@ -394,6 +498,9 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		arg.LoginType,
 		arg.Scope,
 		arg.TokenName,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.AllowList),
+		arg.UserAgent,
 	)
 	var i APIKey
 	err := row.Scan(
@@ -409,6 +516,9 @@ func (q *sqlQuerier) InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (
 		&i.IPAddress,
 		&i.Scope,
 		&i.TokenName,
+		pq.Array(&i.Scopes),
+		pq.Array(&i.AllowList),
+		&i.UserAgent,
 	)
 	return i, err
 }
@@ -419,7 +529,8 @@ UPDATE
 SET
 	last_used = $2,
 	expires_at = $3,
-	ip_address = $4
+	ip_address = $4,
+	user_agent = $5
 WHERE
 	id = $1
 `
@@ -429,6 +540,7 @@ type UpdateAPIKeyByIDParams struct {
 	LastUsed  time.Time   `db:"last_used" json:"last_used"`
 	ExpiresAt time.Time   `db:"expires_at" json:"expires_at"`
 	IPAddress pqtype.Inet `db:"ip_address" json:"ip_address"`
+	UserAgent string      `db:"user_agent" json:"user_agent"`
 }
 
 func (q *sqlQuerier) UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error {
@@ -437,6 +549,7 @@ func (q *sqlQuerier) UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDP
 		arg.LastUsed,
 		arg.ExpiresAt,
 		arg.IPAddress,
+		arg.UserAgent,
 	)
 	return err
 }
@@ -459,6 +572,7 @@ SELECT
     users.deleted AS user_deleted,
     users.theme_preference AS user_theme_preference,
     users.quiet_hours_schedule AS user_quiet_hours_schedule,
+    users.is_service_account AS user_is_service_account,
     COALESCE(organizations.name, '') AS organization_name,
     COALESCE(organizations.display_name, '') AS organization_display_name,
     COALESCE(organizations.icon, '') AS organization_icon,
@@ -603,6 +717,7 @@ type GetAuditLogsOffsetRow struct {
 	UserDeleted             sql.NullBool   `db:"user_deleted" json:"user_deleted"`
 	UserThemePreference     sql.NullString `db:"user_theme_preference" json:"user_theme_preference"`
 	UserQuietHoursSchedule  sql.NullString `db:"user_quiet_hours_schedule" json:"user_quiet_hours_schedule"`
+	UserIsServiceAccount    sql.NullBool   `db:"user_is_service_account" json:"user_is_service_account"`
 	OrganizationName        string         `db:"organization_name" json:"organization_name"`
 	OrganizationDisplayName string         `db:"organization_display_name" json:"organization_display_name"`
 	OrganizationIcon        string         `db:"organization_icon" json:"organization_icon"`
@@ -663,6 +778,7 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 			&i.UserDeleted,
 			&i.UserThemePreference,
 			&i.UserQuietHoursSchedule,
+			&i.UserIsServiceAccount,
 			&i.OrganizationName,
 			&i.OrganizationDisplayName,
 			&i.OrganizationIcon,
@@ -829,6 +945,126 @@ func (q *sqlQuerier) RevokeDBCryptKey(ctx context.Context, activeKeyDigest strin
 	return err
 }
 
+const deleteOldDERPHealthHistory = `-- name: DeleteOldDERPHealthHistory :exec
+DELETE FROM derp_health_history WHERE created_at < NOW() - INTERVAL '7 days'
+`
+
+func (q *sqlQuerier) DeleteOldDERPHealthHistory(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOldDERPHealthHistory)
+	return err
+}
+
+const getDERPHealthHistory = `-- name: GetDERPHealthHistory :many
+SELECT
+	id, created_at, replica_id, region_id, region_code, node_name, healthy, can_exchange_messages, round_trip_ping_ms, packet_loss, stun_enabled, can_stun, error
+FROM
+	derp_health_history
+WHERE
+	created_at >= $1 :: timestamptz
+	AND CASE
+		WHEN $2 :: integer != 0 THEN
+			region_id = $2
+		ELSE true
+	END
+ORDER BY
+	created_at ASC, id ASC
+`
+
+type GetDERPHealthHistoryParams struct {
+	CreatedAfter time.Time `db:"created_after" json:"created_after"`
+	RegionID     int32     `db:"region_id" json:"region_id"`
+}
+
+func (q *sqlQuerier) GetDERPHealthHistory(ctx context.Context, arg GetDERPHealthHistoryParams) ([]DERPHealthHistory, error) {
+	rows, err := q.db.QueryContext(ctx, getDERPHealthHistory, arg.CreatedAfter, arg.RegionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DERPHealthHistory
+	for rows.Next() {
+		var i DERPHealthHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ReplicaID,
+			&i.RegionID,
+			&i.RegionCode,
+			&i.NodeName,
+			&i.Healthy,
+			&i.CanExchangeMessages,
+			&i.RoundTripPingMs,
+			&i.PacketLoss,
+			&i.StunEnabled,
+			&i.CanStun,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertDERPHealthHistory = `-- name: InsertDERPHealthHistory :exec
+INSERT INTO
+	derp_health_history (
+		created_at,
+		replica_id,
+		region_id,
+		region_code,
+		node_name,
+		healthy,
+		can_exchange_messages,
+		round_trip_ping_ms,
+		packet_loss,
+		stun_enabled,
+		can_stun,
+		error
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+`
+
+type InsertDERPHealthHistoryParams struct {
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	ReplicaID           uuid.UUID `db:"replica_id" json:"replica_id"`
+	RegionID            int32     `db:"region_id" json:"region_id"`
+	RegionCode          string    `db:"region_code" json:"region_code"`
+	NodeName            string    `db:"node_name" json:"node_name"`
+	Healthy             bool      `db:"healthy" json:"healthy"`
+	CanExchangeMessages bool      `db:"can_exchange_messages" json:"can_exchange_messages"`
+	RoundTripPingMs     int32     `db:"round_trip_ping_ms" json:"round_trip_ping_ms"`
+	PacketLoss          float32   `db:"packet_loss" json:"packet_loss"`
+	StunEnabled         bool      `db:"stun_enabled" json:"stun_enabled"`
+	CanStun             bool      `db:"can_stun" json:"can_stun"`
+	Error               string    `db:"error" json:"error"`
+}
+
+func (q *sqlQuerier) InsertDERPHealthHistory(ctx context.Context, arg InsertDERPHealthHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, insertDERPHealthHistory,
+		arg.CreatedAt,
+		arg.ReplicaID,
+		arg.RegionID,
+		arg.RegionCode,
+		arg.NodeName,
+		arg.Healthy,
+		arg.CanExchangeMessages,
+		arg.RoundTripPingMs,
+		arg.PacketLoss,
+		arg.StunEnabled,
+		arg.CanStun,
+		arg.Error,
+	)
+	return err
+}
+
 const deleteExternalAuthLink = `-- name: DeleteExternalAuthLink :exec
 DELETE FROM external_auth_links WHERE provider_id = $1 AND user_id = $2
 `
@@ -3731,6 +3967,15 @@ func (q *sqlQuerier) UpdateUserNotificationPreferences(ctx context.Context, arg
 	return result.RowsAffected()
 }
 
+const deleteExpiredOAuth2ProviderAppDeviceCodes = `-- name: DeleteExpiredOAuth2ProviderAppDeviceCodes :exec
+DELETE FROM oauth2_provider_app_device_codes WHERE expires_at < NOW()
+`
+
+func (q *sqlQuerier) DeleteExpiredOAuth2ProviderAppDeviceCodes(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredOAuth2ProviderAppDeviceCodes)
+	return err
+}
+
 const deleteOAuth2ProviderAppByID = `-- name: DeleteOAuth2ProviderAppByID :exec
 DELETE FROM oauth2_provider_apps WHERE id = $1
 `
@@ -3763,6 +4008,15 @@ func (q *sqlQuerier) DeleteOAuth2ProviderAppCodesByAppAndUserID(ctx context.Cont
 	return err
 }
 
+const deleteOAuth2ProviderAppDeviceCodeByID = `-- name: DeleteOAuth2ProviderAppDeviceCodeByID :exec
+DELETE FROM oauth2_provider_app_device_codes WHERE id = $1
+`
+
+func (q *sqlQuerier) DeleteOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuth2ProviderAppDeviceCodeByID, id)
+	return err
+}
+
 const deleteOAuth2ProviderAppSecretByID = `-- name: DeleteOAuth2ProviderAppSecretByID :exec
 DELETE FROM oauth2_provider_app_secrets WHERE id = $1
 `
@@ -3776,11 +4030,10 @@ const deleteOAuth2ProviderAppTokensByAppAndUserID = `-- name: DeleteOAuth2Provid
 DELETE FROM
   oauth2_provider_app_tokens
 USING
-  oauth2_provider_app_secrets, api_keys
+  api_keys
 WHERE
-  oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
-  AND api_keys.id = oauth2_provider_app_tokens.api_key_id
-  AND oauth2_provider_app_secrets.app_id = $1
+  api_keys.id = oauth2_provider_app_tokens.api_key_id
+  AND oauth2_provider_app_tokens.app_id = $1
 	AND api_keys.user_id = $2
 `
 
@@ -3795,7 +4048,7 @@ func (q *sqlQuerier) DeleteOAuth2ProviderAppTokensByAppAndUserID(ctx context.Con
 }
 
 const getOAuth2ProviderAppByID = `-- name: GetOAuth2ProviderAppByID :one
-SELECT id, created_at, updated_at, name, icon, callback_url FROM oauth2_provider_apps WHERE id = $1
+SELECT id, created_at, updated_at, name, icon, callback_url, public, client_credentials_user_id FROM oauth2_provider_apps WHERE id = $1
 `
 
 func (q *sqlQuerier) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderApp, error) {
@@ -3808,12 +4061,14 @@ func (q *sqlQuerier) GetOAuth2ProviderAppByID(ctx context.Context, id uuid.UUID)
 		&i.Name,
 		&i.Icon,
 		&i.CallbackURL,
+		&i.Public,
+		&i.ClientCredentialsUserID,
 	)
 	return i, err
 }
 
 const getOAuth2ProviderAppCodeByID = `-- name: GetOAuth2ProviderAppCodeByID :one
-SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id FROM oauth2_provider_app_codes WHERE id = $1
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, code_challenge, code_challenge_method FROM oauth2_provider_app_codes WHERE id = $1
 `
 
 func (q *sqlQuerier) GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppCode, error) {
@@ -3827,12 +4082,14 @@ func (q *sqlQuerier) GetOAuth2ProviderAppCodeByID(ctx context.Context, id uuid.U
 		&i.HashedSecret,
 		&i.UserID,
 		&i.AppID,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
 	)
 	return i, err
 }
 
 const getOAuth2ProviderAppCodeByPrefix = `-- name: GetOAuth2ProviderAppCodeByPrefix :one
-SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id FROM oauth2_provider_app_codes WHERE secret_prefix = $1
+SELECT id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, code_challenge, code_challenge_method FROM oauth2_provider_app_codes WHERE secret_prefix = $1
 `
 
 func (q *sqlQuerier) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secretPrefix []byte) (OAuth2ProviderAppCode, error) {
@@ -3846,6 +4103,74 @@ func (q *sqlQuerier) GetOAuth2ProviderAppCodeByPrefix(ctx context.Context, secre
 		&i.HashedSecret,
 		&i.UserID,
 		&i.AppID,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppDeviceCodeByID = `-- name: GetOAuth2ProviderAppDeviceCodeByID :one
+SELECT id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at FROM oauth2_provider_app_device_codes WHERE id = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppDeviceCodeByID(ctx context.Context, id uuid.UUID) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppDeviceCodeByID, id)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppDeviceCodeByPrefix = `-- name: GetOAuth2ProviderAppDeviceCodeByPrefix :one
+SELECT id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at FROM oauth2_provider_app_device_codes WHERE device_prefix = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppDeviceCodeByPrefix(ctx context.Context, devicePrefix []byte) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppDeviceCodeByPrefix, devicePrefix)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
+
+const getOAuth2ProviderAppDeviceCodeByUserCode = `-- name: GetOAuth2ProviderAppDeviceCodeByUserCode :one
+SELECT id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at FROM oauth2_provider_app_device_codes WHERE user_code = $1
+`
+
+func (q *sqlQuerier) GetOAuth2ProviderAppDeviceCodeByUserCode(ctx context.Context, userCode string) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuth2ProviderAppDeviceCodeByUserCode, userCode)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
 	)
 	return i, err
 }
@@ -3924,7 +4249,7 @@ func (q *sqlQuerier) GetOAuth2ProviderAppSecretsByAppID(ctx context.Context, app
 }
 
 const getOAuth2ProviderAppTokenByPrefix = `-- name: GetOAuth2ProviderAppTokenByPrefix :one
-SELECT id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id FROM oauth2_provider_app_tokens WHERE hash_prefix = $1
+SELECT id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id, app_id FROM oauth2_provider_app_tokens WHERE hash_prefix = $1
 `
 
 func (q *sqlQuerier) GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hashPrefix []byte) (OAuth2ProviderAppToken, error) {
@@ -3938,12 +4263,13 @@ func (q *sqlQuerier) GetOAuth2ProviderAppTokenByPrefix(ctx context.Context, hash
 		&i.RefreshHash,
 		&i.AppSecretID,
 		&i.APIKeyID,
+		&i.AppID,
 	)
 	return i, err
 }
 
 const getOAuth2ProviderApps = `-- name: GetOAuth2ProviderApps :many
-SELECT id, created_at, updated_at, name, icon, callback_url FROM oauth2_provider_apps ORDER BY (name, id) ASC
+SELECT id, created_at, updated_at, name, icon, callback_url, public, client_credentials_user_id FROM oauth2_provider_apps ORDER BY (name, id) ASC
 `
 
 func (q *sqlQuerier) GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2ProviderApp, error) {
@@ -3962,6 +4288,8 @@ func (q *sqlQuerier) GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2Provide
 			&i.Name,
 			&i.Icon,
 			&i.CallbackURL,
+			&i.Public,
+			&i.ClientCredentialsUserID,
 		); err != nil {
 			return nil, err
 		}
@@ -3979,12 +4307,10 @@ func (q *sqlQuerier) GetOAuth2ProviderApps(ctx context.Context) ([]OAuth2Provide
 const getOAuth2ProviderAppsByUserID = `-- name: GetOAuth2ProviderAppsByUserID :many
 SELECT
   COUNT(DISTINCT oauth2_provider_app_tokens.id) as token_count,
-  oauth2_provider_apps.id, oauth2_provider_apps.created_at, oauth2_provider_apps.updated_at, oauth2_provider_apps.name, oauth2_provider_apps.icon, oauth2_provider_apps.callback_url
+  oauth2_provider_apps.id, oauth2_provider_apps.created_at, oauth2_provider_apps.updated_at, oauth2_provider_apps.name, oauth2_provider_apps.icon, oauth2_provider_apps.callback_url, oauth2_provider_apps.public, oauth2_provider_apps.client_credentials_user_id
 FROM oauth2_provider_app_tokens
-  INNER JOIN oauth2_provider_app_secrets
-    ON oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
   INNER JOIN oauth2_provider_apps
-    ON oauth2_provider_apps.id = oauth2_provider_app_secrets.app_id
+    ON oauth2_provider_apps.id = oauth2_provider_app_tokens.app_id
   INNER JOIN api_keys
     ON api_keys.id = oauth2_provider_app_tokens.api_key_id
 WHERE
@@ -4015,6 +4341,8 @@ func (q *sqlQuerier) GetOAuth2ProviderAppsByUserID(ctx context.Context, userID u
 			&i.OAuth2ProviderApp.Name,
 			&i.OAuth2ProviderApp.Icon,
 			&i.OAuth2ProviderApp.CallbackURL,
+			&i.OAuth2ProviderApp.Public,
+			&i.OAuth2ProviderApp.ClientCredentialsUserID,
 		); err != nil {
 			return nil, err
 		}
@@ -4036,24 +4364,30 @@ INSERT INTO oauth2_provider_apps (
     updated_at,
     name,
     icon,
-    callback_url
+    callback_url,
+    public,
+    client_credentials_user_id
 ) VALUES(
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
-) RETURNING id, created_at, updated_at, name, icon, callback_url
+    $6,
+    $7,
+    $8
+) RETURNING id, created_at, updated_at, name, icon, callback_url, public, client_credentials_user_id
 `
 
 type InsertOAuth2ProviderAppParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	Name        string    `db:"name" json:"name"`
-	Icon        string    `db:"icon" json:"icon"`
-	CallbackURL string    `db:"callback_url" json:"callback_url"`
+	ID                      uuid.UUID     `db:"id" json:"id"`
+	CreatedAt               time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt               time.Time     `db:"updated_at" json:"updated_at"`
+	Name                    string        `db:"name" json:"name"`
+	Icon                    string        `db:"icon" json:"icon"`
+	CallbackURL             string        `db:"callback_url" json:"callback_url"`
+	Public                  bool          `db:"public" json:"public"`
+	ClientCredentialsUserID uuid.NullUUID `db:"client_credentials_user_id" json:"client_credentials_user_id"`
 }
 
 func (q *sqlQuerier) InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAuth2ProviderAppParams) (OAuth2ProviderApp, error) {
@@ -4064,6 +4398,8 @@ func (q *sqlQuerier) InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAut
 		arg.Name,
 		arg.Icon,
 		arg.CallbackURL,
+		arg.Public,
+		arg.ClientCredentialsUserID,
 	)
 	var i OAuth2ProviderApp
 	err := row.Scan(
@@ -4073,6 +4409,8 @@ func (q *sqlQuerier) InsertOAuth2ProviderApp(ctx context.Context, arg InsertOAut
 		&i.Name,
 		&i.Icon,
 		&i.CallbackURL,
+		&i.Public,
+		&i.ClientCredentialsUserID,
 	)
 	return i, err
 }
@@ -4085,7 +4423,9 @@ INSERT INTO oauth2_provider_app_codes (
     secret_prefix,
     hashed_secret,
     app_id,
-    user_id
+    user_id,
+    code_challenge,
+    code_challenge_method
 ) VALUES(
     $1,
     $2,
@@ -4093,18 +4433,22 @@ INSERT INTO oauth2_provider_app_codes (
     $4,
     $5,
     $6,
-    $7
-) RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id
+    $7,
+    $8,
+    $9
+) RETURNING id, created_at, expires_at, secret_prefix, hashed_secret, user_id, app_id, code_challenge, code_challenge_method
 `
 
 type InsertOAuth2ProviderAppCodeParams struct {
-	ID           uuid.UUID `db:"id" json:"id"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
-	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
-	SecretPrefix []byte    `db:"secret_prefix" json:"secret_prefix"`
-	HashedSecret []byte    `db:"hashed_secret" json:"hashed_secret"`
-	AppID        uuid.UUID `db:"app_id" json:"app_id"`
-	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	ID                  uuid.UUID `db:"id" json:"id"`
+	CreatedAt           time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt           time.Time `db:"expires_at" json:"expires_at"`
+	SecretPrefix        []byte    `db:"secret_prefix" json:"secret_prefix"`
+	HashedSecret        []byte    `db:"hashed_secret" json:"hashed_secret"`
+	AppID               uuid.UUID `db:"app_id" json:"app_id"`
+	UserID              uuid.UUID `db:"user_id" json:"user_id"`
+	CodeChallenge       string    `db:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string    `db:"code_challenge_method" json:"code_challenge_method"`
 }
 
 func (q *sqlQuerier) InsertOAuth2ProviderAppCode(ctx context.Context, arg InsertOAuth2ProviderAppCodeParams) (OAuth2ProviderAppCode, error) {
@@ -4116,6 +4460,8 @@ func (q *sqlQuerier) InsertOAuth2ProviderAppCode(ctx context.Context, arg Insert
 		arg.HashedSecret,
 		arg.AppID,
 		arg.UserID,
+		arg.CodeChallenge,
+		arg.CodeChallengeMethod,
 	)
 	var i OAuth2ProviderAppCode
 	err := row.Scan(
@@ -4126,6 +4472,64 @@ func (q *sqlQuerier) InsertOAuth2ProviderAppCode(ctx context.Context, arg Insert
 		&i.HashedSecret,
 		&i.UserID,
 		&i.AppID,
+		&i.CodeChallenge,
+		&i.CodeChallengeMethod,
+	)
+	return i, err
+}
+
+const insertOAuth2ProviderAppDeviceCode = `-- name: InsertOAuth2ProviderAppDeviceCode :one
+INSERT INTO oauth2_provider_app_device_codes (
+    id,
+    created_at,
+    expires_at,
+    device_prefix,
+    hashed_device_code,
+    user_code,
+    app_id
+) VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+) RETURNING id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at
+`
+
+type InsertOAuth2ProviderAppDeviceCodeParams struct {
+	ID               uuid.UUID     `db:"id" json:"id"`
+	CreatedAt        time.Time     `db:"created_at" json:"created_at"`
+	ExpiresAt        time.Time     `db:"expires_at" json:"expires_at"`
+	DevicePrefix     []byte        `db:"device_prefix" json:"device_prefix"`
+	HashedDeviceCode []byte        `db:"hashed_device_code" json:"hashed_device_code"`
+	UserCode         string        `db:"user_code" json:"user_code"`
+	AppID            uuid.NullUUID `db:"app_id" json:"app_id"`
+}
+
+func (q *sqlQuerier) InsertOAuth2ProviderAppDeviceCode(ctx context.Context, arg InsertOAuth2ProviderAppDeviceCodeParams) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, insertOAuth2ProviderAppDeviceCode,
+		arg.ID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+		arg.DevicePrefix,
+		arg.HashedDeviceCode,
+		arg.UserCode,
+		arg.AppID,
+	)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
 	)
 	return i, err
 }
@@ -4187,7 +4591,8 @@ INSERT INTO oauth2_provider_app_tokens (
     hash_prefix,
     refresh_hash,
     app_secret_id,
-    api_key_id
+    api_key_id,
+    app_id
 ) VALUES(
     $1,
     $2,
@@ -4195,18 +4600,20 @@ INSERT INTO oauth2_provider_app_tokens (
     $4,
     $5,
     $6,
-    $7
-) RETURNING id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id
+    $7,
+    $8
+) RETURNING id, created_at, expires_at, hash_prefix, refresh_hash, app_secret_id, api_key_id, app_id
 `
 
 type InsertOAuth2ProviderAppTokenParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
-	HashPrefix  []byte    `db:"hash_prefix" json:"hash_prefix"`
-	RefreshHash []byte    `db:"refresh_hash" json:"refresh_hash"`
-	AppSecretID uuid.UUID `db:"app_secret_id" json:"app_secret_id"`
-	APIKeyID    string    `db:"api_key_id" json:"api_key_id"`
+	ID          uuid.UUID     `db:"id" json:"id"`
+	CreatedAt   time.Time     `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time     `db:"expires_at" json:"expires_at"`
+	HashPrefix  []byte        `db:"hash_prefix" json:"hash_prefix"`
+	RefreshHash []byte        `db:"refresh_hash" json:"refresh_hash"`
+	AppSecretID uuid.NullUUID `db:"app_secret_id" json:"app_secret_id"`
+	APIKeyID    string        `db:"api_key_id" json:"api_key_id"`
+	AppID       uuid.UUID     `db:"app_id" json:"app_id"`
 }
 
 func (q *sqlQuerier) InsertOAuth2ProviderAppToken(ctx context.Context, arg InsertOAuth2ProviderAppTokenParams) (OAuth2ProviderAppToken, error) {
@@ -4218,6 +4625,7 @@ func (q *sqlQuerier) InsertOAuth2ProviderAppToken(ctx context.Context, arg Inser
 		arg.RefreshHash,
 		arg.AppSecretID,
 		arg.APIKeyID,
+		arg.AppID,
 	)
 	var i OAuth2ProviderAppToken
 	err := row.Scan(
@@ -4228,6 +4636,7 @@ func (q *sqlQuerier) InsertOAuth2ProviderAppToken(ctx context.Context, arg Inser
 		&i.RefreshHash,
 		&i.AppSecretID,
 		&i.APIKeyID,
+		&i.AppID,
 	)
 	return i, err
 }
@@ -4237,16 +4646,20 @@ UPDATE oauth2_provider_apps SET
     updated_at = $2,
     name = $3,
     icon = $4,
-    callback_url = $5
-WHERE id = $1 RETURNING id, created_at, updated_at, name, icon, callback_url
+    callback_url = $5,
+    public = $6,
+    client_credentials_user_id = $7
+WHERE id = $1 RETURNING id, created_at, updated_at, name, icon, callback_url, public, client_credentials_user_id
 `
 
 type UpdateOAuth2ProviderAppByIDParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	Name        string    `db:"name" json:"name"`
-	Icon        string    `db:"icon" json:"icon"`
-	CallbackURL string    `db:"callback_url" json:"callback_url"`
+	ID                      uuid.UUID     `db:"id" json:"id"`
+	UpdatedAt               time.Time     `db:"updated_at" json:"updated_at"`
+	Name                    string        `db:"name" json:"name"`
+	Icon                    string        `db:"icon" json:"icon"`
+	CallbackURL             string        `db:"callback_url" json:"callback_url"`
+	Public                  bool          `db:"public" json:"public"`
+	ClientCredentialsUserID uuid.NullUUID `db:"client_credentials_user_id" json:"client_credentials_user_id"`
 }
 
 func (q *sqlQuerier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg UpdateOAuth2ProviderAppByIDParams) (OAuth2ProviderApp, error) {
@@ -4256,6 +4669,8 @@ func (q *sqlQuerier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg Update
 		arg.Name,
 		arg.Icon,
 		arg.CallbackURL,
+		arg.Public,
+		arg.ClientCredentialsUserID,
 	)
 	var i OAuth2ProviderApp
 	err := row.Scan(
@@ -4265,6 +4680,73 @@ func (q *sqlQuerier) UpdateOAuth2ProviderAppByID(ctx context.Context, arg Update
 		&i.Name,
 		&i.Icon,
 		&i.CallbackURL,
+		&i.Public,
+		&i.ClientCredentialsUserID,
+	)
+	return i, err
+}
+
+const updateOAuth2ProviderAppDeviceCodeLastPolledAt = `-- name: UpdateOAuth2ProviderAppDeviceCodeLastPolledAt :one
+UPDATE oauth2_provider_app_device_codes SET
+    last_polled_at = $1
+WHERE id = $2 AND (last_polled_at IS NULL OR last_polled_at <= $3 :: timestamptz) RETURNING id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at
+`
+
+type UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams struct {
+	LastPolledAt sql.NullTime `db:"last_polled_at" json:"last_polled_at"`
+	ID           uuid.UUID    `db:"id" json:"id"`
+	PolledBefore time.Time    `db:"polled_before" json:"polled_before"`
+}
+
+// Records a poll of the token endpoint. No rows are returned if the code was
+// last polled after polled_before, which means the client should slow down.
+func (q *sqlQuerier) UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(ctx context.Context, arg UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppDeviceCodeLastPolledAt, arg.LastPolledAt, arg.ID, arg.PolledBefore)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
+	)
+	return i, err
+}
+
+const updateOAuth2ProviderAppDeviceCodeStatus = `-- name: UpdateOAuth2ProviderAppDeviceCodeStatus :one
+UPDATE oauth2_provider_app_device_codes SET
+    status = $2,
+    user_id = $3
+WHERE id = $1 AND status = 'pending' RETURNING id, created_at, expires_at, device_prefix, hashed_device_code, user_code, app_id, user_id, status, last_polled_at
+`
+
+type UpdateOAuth2ProviderAppDeviceCodeStatusParams struct {
+	ID     uuid.UUID                      `db:"id" json:"id"`
+	Status OAuth2ProviderDeviceCodeStatus `db:"status" json:"status"`
+	UserID uuid.NullUUID                  `db:"user_id" json:"user_id"`
+}
+
+// Only pending codes can be approved or denied, so a code cannot change hands
+// once a user has acted on it.
+func (q *sqlQuerier) UpdateOAuth2ProviderAppDeviceCodeStatus(ctx context.Context, arg UpdateOAuth2ProviderAppDeviceCodeStatusParams) (OAuth2ProviderAppDeviceCode, error) {
+	row := q.db.QueryRowContext(ctx, updateOAuth2ProviderAppDeviceCodeStatus, arg.ID, arg.Status, arg.UserID)
+	var i OAuth2ProviderAppDeviceCode
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.DevicePrefix,
+		&i.HashedDeviceCode,
+		&i.UserCode,
+		&i.AppID,
+		&i.UserID,
+		&i.Status,
+		&i.LastPolledAt,
 	)
 	return i, err
 }
@@ -4514,7 +4996,7 @@ func (q *sqlQuerier) DeleteOrganization(ctx context.Context, id uuid.UUID) error
 
 const getDefaultOrganization = `-- name: GetDefaultOrganization :one
 SELECT
-	id, name, description, created_at, updated_at, is_default, display_name, icon
+	id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 FROM
 	organizations
 WHERE
@@ -4535,13 +5017,15 @@ func (q *sqlQuerier) GetDefaultOrganization(ctx context.Context) (Organization,
 		&i.IsDefault,
 		&i.DisplayName,
 		&i.Icon,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
 	)
 	return i, err
 }
 
 const getOrganizationByID = `-- name: GetOrganizationByID :one
 SELECT
-	id, name, description, created_at, updated_at, is_default, display_name, icon
+	id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 FROM
 	organizations
 WHERE
@@ -4560,13 +5044,15 @@ func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Org
 		&i.IsDefault,
 		&i.DisplayName,
 		&i.Icon,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
 	)
 	return i, err
 }
 
 const getOrganizationByName = `-- name: GetOrganizationByName :one
 SELECT
-	id, name, description, created_at, updated_at, is_default, display_name, icon
+	id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 FROM
 	organizations
 WHERE
@@ -4587,13 +5073,15 @@ func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Or
 		&i.IsDefault,
 		&i.DisplayName,
 		&i.Icon,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
 	)
 	return i, err
 }
 
 const getOrganizations = `-- name: GetOrganizations :many
 SELECT
-	id, name, description, created_at, updated_at, is_default, display_name, icon
+	id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 FROM
 	organizations
 `
@@ -4616,6 +5104,8 @@ func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, erro
 			&i.IsDefault,
 			&i.DisplayName,
 			&i.Icon,
+			pq.Array(&i.AllowedWorkspaceProxyIDs),
+			pq.Array(&i.AllowedDERPRegionIDs),
 		); err != nil {
 			return nil, err
 		}
@@ -4632,7 +5122,7 @@ func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, erro
 
 const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
 SELECT
-	id, name, description, created_at, updated_at, is_default, display_name, icon
+	id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 FROM
 	organizations
 WHERE
@@ -4664,6 +5154,8 @@ func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.U
 			&i.IsDefault,
 			&i.DisplayName,
 			&i.Icon,
+			pq.Array(&i.AllowedWorkspaceProxyIDs),
+			pq.Array(&i.AllowedDERPRegionIDs),
 		); err != nil {
 			return nil, err
 		}
@@ -4683,7 +5175,7 @@ INSERT INTO
 	organizations (id, "name", display_name, description, icon, created_at, updated_at, is_default)
 VALUES
 	-- If no organizations exist, and this is the first, make it the default.
-	($1, $2, $3, $4, $5, $6, $7, (SELECT TRUE FROM organizations LIMIT 1) IS NULL) RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon
+	($1, $2, $3, $4, $5, $6, $7, (SELECT TRUE FROM organizations LIMIT 1) IS NULL) RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 `
 
 type InsertOrganizationParams struct {
@@ -4716,6 +5208,8 @@ func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizat
 		&i.IsDefault,
 		&i.DisplayName,
 		&i.Icon,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
 	)
 	return i, err
 }
@@ -4728,19 +5222,23 @@ SET
 	name = $2,
 	display_name = $3,
 	description = $4,
-	icon = $5
+	icon = $5,
+	allowed_workspace_proxy_ids = $6,
+	allowed_derp_region_ids = $7
 WHERE
-	id = $6
-RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon
+	id = $8
+RETURNING id, name, description, created_at, updated_at, is_default, display_name, icon, allowed_workspace_proxy_ids, allowed_derp_region_ids
 `
 
 type UpdateOrganizationParams struct {
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
-	Name        string    `db:"name" json:"name"`
-	DisplayName string    `db:"display_name" json:"display_name"`
-	Description string    `db:"description" json:"description"`
-	Icon        string    `db:"icon" json:"icon"`
-	ID          uuid.UUID `db:"id" json:"id"`
+	UpdatedAt                time.Time   `db:"updated_at" json:"updated_at"`
+	Name                     string      `db:"name" json:"name"`
+	DisplayName              string      `db:"display_name" json:"display_name"`
+	Description              string      `db:"description" json:"description"`
+	Icon                     string      `db:"icon" json:"icon"`
+	AllowedWorkspaceProxyIDs []uuid.UUID `db:"allowed_workspace_proxy_ids" json:"allowed_workspace_proxy_ids"`
+	AllowedDERPRegionIDs     []int32     `db:"allowed_derp_region_ids" json:"allowed_derp_region_ids"`
+	ID                       uuid.UUID   `db:"id" json:"id"`
 }
 
 func (q *sqlQuerier) UpdateOrganization(ctx context.Context, arg UpdateOrganizationParams) (Organization, error) {
@@ -4750,6 +5248,8 @@ func (q *sqlQuerier) UpdateOrganization(ctx context.Context, arg UpdateOrganizat
 		arg.DisplayName,
 		arg.Description,
 		arg.Icon,
+		pq.Array(arg.AllowedWorkspaceProxyIDs),
+		pq.Array(arg.AllowedDERPRegionIDs),
 		arg.ID,
 	)
 	var i Organization
@@ -4762,6 +5262,8 @@ func (q *sqlQuerier) UpdateOrganization(ctx context.Context, arg UpdateOrganizat
 		&i.IsDefault,
 		&i.DisplayName,
 		&i.Icon,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
 	)
 	return i, err
 }
@@ -5126,9 +5628,22 @@ SET
 WHERE
 	id = (
 		SELECT
-			id
+			nested.id
 		FROM
 			provisioner_jobs AS nested
+		LEFT JOIN (
+			SELECT
+				initiator_id,
+				COUNT(*) AS running
+			FROM
+				provisioner_jobs
+			WHERE
+				started_at IS NOT NULL
+				AND completed_at IS NULL
+				AND organization_id = $3
+			GROUP BY
+				initiator_id
+		) AS running_jobs ON running_jobs.initiator_id = nested.initiator_id
 		WHERE
 			nested.started_at IS NULL
 			AND nested.organization_id = $3
@@ -5142,12 +5657,17 @@ WHERE
 				ELSE nested.tags :: jsonb <@ $5 :: jsonb
 			END
 		ORDER BY
+			-- Jobs in a higher priority class always go first.
+			nested.priority,
+			-- Within a class, prefer users with the fewest jobs already
+			-- running so that one user can't monopolize the provisioners.
+			COALESCE(running_jobs.running, 0),
 			nested.created_at
-		FOR UPDATE
+		FOR UPDATE OF nested
 		SKIP LOCKED
 		LIMIT
 			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 `
 
 type AcquireProvisionerJobParams struct {
@@ -5193,13 +5713,14 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
 
 const getHungProvisionerJobs = `-- name: GetHungProvisionerJobs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5237,6 +5758,7 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5253,7 +5775,7 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 
 const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5283,13 +5805,96 @@ func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (P
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
 
+const getProvisionerJobResourceChangesByJobID = `-- name: GetProvisionerJobResourceChangesByJobID :many
+SELECT
+	job_id, address, type, name, action
+FROM
+	provisioner_job_resource_changes
+WHERE
+	job_id = $1
+ORDER BY
+	address ASC
+`
+
+func (q *sqlQuerier) GetProvisionerJobResourceChangesByJobID(ctx context.Context, jobID uuid.UUID) ([]ProvisionerJobResourceChange, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobResourceChangesByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobResourceChange
+	for rows.Next() {
+		var i ProvisionerJobResourceChange
+		if err := rows.Scan(
+			&i.JobID,
+			&i.Address,
+			&i.Type,
+			&i.Name,
+			&i.Action,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProvisionerJobTimingsByJobID = `-- name: GetProvisionerJobTimingsByJobID :many
+SELECT
+	job_id, started_at, ended_at, stage, source, action, resource
+FROM
+	provisioner_job_timings
+WHERE
+	job_id = $1
+ORDER BY
+	started_at ASC
+`
+
+func (q *sqlQuerier) GetProvisionerJobTimingsByJobID(ctx context.Context, jobID uuid.UUID) ([]ProvisionerJobTiming, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobTimingsByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobTiming
+	for rows.Next() {
+		var i ProvisionerJobTiming
+		if err := rows.Scan(
+			&i.JobID,
+			&i.StartedAt,
+			&i.EndedAt,
+			&i.Stage,
+			&i.Source,
+			&i.Action,
+			&i.Resource,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 FROM
 	provisioner_jobs
 WHERE
@@ -5325,6 +5930,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5342,7 +5948,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 const getProvisionerJobsByIDsWithQueuePosition = `-- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -5351,7 +5957,7 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority ASC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
@@ -5359,7 +5965,7 @@ queue_size AS (
 	SELECT COUNT(*) as count FROM unstarted_jobs
 )
 SELECT
-	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status,
+	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.job_status, pj.priority,
     COALESCE(qp.queue_position, 0) AS queue_position,
     COALESCE(qs.count, 0) AS queue_size
 FROM
@@ -5407,6 +6013,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 			&i.ProvisionerJob.ErrorCode,
 			&i.ProvisionerJob.TraceMetadata,
 			&i.ProvisionerJob.JobStatus,
+			&i.ProvisionerJob.Priority,
 			&i.QueuePosition,
 			&i.QueueSize,
 		); err != nil {
@@ -5456,6 +6063,82 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 			&i.ErrorCode,
 			&i.TraceMetadata,
 			&i.JobStatus,
+			&i.Priority,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimedOutProvisionerJobs = `-- name: GetTimedOutProvisionerJobs :many
+SELECT
+	provisioner_jobs.id, provisioner_jobs.created_at, provisioner_jobs.updated_at, provisioner_jobs.started_at, provisioner_jobs.canceled_at, provisioner_jobs.completed_at, provisioner_jobs.error, provisioner_jobs.organization_id, provisioner_jobs.initiator_id, provisioner_jobs.provisioner, provisioner_jobs.storage_method, provisioner_jobs.type, provisioner_jobs.input, provisioner_jobs.worker_id, provisioner_jobs.file_id, provisioner_jobs.tags, provisioner_jobs.error_code, provisioner_jobs.trace_metadata, provisioner_jobs.job_status, provisioner_jobs.priority
+FROM
+	provisioner_jobs
+INNER JOIN
+	workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+INNER JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	provisioner_jobs.started_at IS NOT NULL
+	AND provisioner_jobs.completed_at IS NULL
+	AND templates.build_timeout > 0
+	AND provisioner_jobs.started_at + ((
+		templates.build_timeout + CASE
+			WHEN templates.build_cancel_grace_period > 0 THEN templates.build_cancel_grace_period
+			ELSE $1 :: bigint
+		END
+	) / 1000 / 1000 / 1000 || ' seconds') :: interval < $2 :: timestamptz
+`
+
+type GetTimedOutProvisionerJobsParams struct {
+	DefaultCancelGracePeriod int64     `db:"default_cancel_grace_period" json:"default_cancel_grace_period"`
+	Now                      time.Time `db:"now" json:"now"`
+}
+
+// Returns running workspace build jobs that have exceeded their template's
+// build timeout plus cancel grace period. Templates without a build timeout
+// are skipped. Durations are stored in nanoseconds.
+func (q *sqlQuerier) GetTimedOutProvisionerJobs(ctx context.Context, arg GetTimedOutProvisionerJobsParams) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getTimedOutProvisionerJobs, arg.DefaultCancelGracePeriod, arg.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.ErrorCode,
+			&i.TraceMetadata,
+			&i.JobStatus,
+			&i.Priority,
 		); err != nil {
 			return nil, err
 		}
@@ -5484,10 +6167,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, job_status, priority
 `
 
 type InsertProvisionerJobParams struct {
@@ -5503,6 +6187,7 @@ type InsertProvisionerJobParams struct {
 	Input          json.RawMessage          `db:"input" json:"input"`
 	Tags           StringMap                `db:"tags" json:"tags"`
 	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	Priority       ProvisionerJobPriority   `db:"priority" json:"priority"`
 }
 
 func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
@@ -5519,6 +6204,7 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		arg.Input,
 		arg.Tags,
 		arg.TraceMetadata,
+		arg.Priority,
 	)
 	var i ProvisionerJob
 	err := row.Scan(
@@ -5541,10 +6227,65 @@ func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisi
 		&i.ErrorCode,
 		&i.TraceMetadata,
 		&i.JobStatus,
+		&i.Priority,
 	)
 	return i, err
 }
 
+const insertProvisionerJobResourceChanges = `-- name: InsertProvisionerJobResourceChanges :many
+INSERT INTO provisioner_job_resource_changes (job_id, address, type, name, action)
+SELECT
+    $1::uuid AS provisioner_job_id,
+    unnest($2::text[]),
+    unnest($3::text[]),
+    unnest($4::text[]),
+    unnest($5::resource_change_action[])
+RETURNING job_id, address, type, name, action
+`
+
+type InsertProvisionerJobResourceChangesParams struct {
+	JobID   uuid.UUID              `db:"job_id" json:"job_id"`
+	Address []string               `db:"address" json:"address"`
+	Type    []string               `db:"type" json:"type"`
+	Name    []string               `db:"name" json:"name"`
+	Action  []ResourceChangeAction `db:"action" json:"action"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJobResourceChanges(ctx context.Context, arg InsertProvisionerJobResourceChangesParams) ([]ProvisionerJobResourceChange, error) {
+	rows, err := q.db.QueryContext(ctx, insertProvisionerJobResourceChanges,
+		arg.JobID,
+		pq.Array(arg.Address),
+		pq.Array(arg.Type),
+		pq.Array(arg.Name),
+		pq.Array(arg.Action),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJobResourceChange
+	for rows.Next() {
+		var i ProvisionerJobResourceChange
+		if err := rows.Scan(
+			&i.JobID,
+			&i.Address,
+			&i.Type,
+			&i.Name,
+			&i.Action,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertProvisionerJobTimings = `-- name: InsertProvisionerJobTimings :many
 INSERT INTO provisioner_job_timings (job_id, started_at, ended_at, stage, source, action, resource)
 SELECT
@@ -6436,48 +7177,346 @@ UPDATE replicas SET
 WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error, "primary"
 `
 
-type UpdateReplicaParams struct {
-	ID              uuid.UUID    `db:"id" json:"id"`
-	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
-	StartedAt       time.Time    `db:"started_at" json:"started_at"`
-	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
-	RelayAddress    string       `db:"relay_address" json:"relay_address"`
-	RegionID        int32        `db:"region_id" json:"region_id"`
-	Hostname        string       `db:"hostname" json:"hostname"`
-	Version         string       `db:"version" json:"version"`
-	Error           string       `db:"error" json:"error"`
-	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
-	Primary         bool         `db:"primary" json:"primary"`
+type UpdateReplicaParams struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
+	StartedAt       time.Time    `db:"started_at" json:"started_at"`
+	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
+	RelayAddress    string       `db:"relay_address" json:"relay_address"`
+	RegionID        int32        `db:"region_id" json:"region_id"`
+	Hostname        string       `db:"hostname" json:"hostname"`
+	Version         string       `db:"version" json:"version"`
+	Error           string       `db:"error" json:"error"`
+	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
+	Primary         bool         `db:"primary" json:"primary"`
+}
+
+func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, updateReplica,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.StartedAt,
+		arg.StoppedAt,
+		arg.RelayAddress,
+		arg.RegionID,
+		arg.Hostname,
+		arg.Version,
+		arg.Error,
+		arg.DatabaseLatency,
+		arg.Primary,
+	)
+	var i Replica
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
+		&i.UpdatedAt,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
+		&i.Primary,
+	)
+	return i, err
+}
+
+const getExpiredRoleGrantRequests = `-- name: GetExpiredRoleGrantRequests :many
+SELECT
+	id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+FROM
+	role_grant_requests
+WHERE
+	status = 'approved'
+	AND expires_at <= $1 :: timestamptz
+ORDER BY
+	expires_at ASC
+`
+
+// Returns the approved requests whose granted role should be removed.
+func (q *sqlQuerier) GetExpiredRoleGrantRequests(ctx context.Context, now time.Time) ([]RoleGrantRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getExpiredRoleGrantRequests, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleGrantRequest
+	for rows.Next() {
+		var i RoleGrantRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.RoleName,
+			&i.Reason,
+			&i.LifetimeSeconds,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoleGrantRequestByID = `-- name: GetRoleGrantRequestByID :one
+SELECT
+	id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+FROM
+	role_grant_requests
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetRoleGrantRequestByID(ctx context.Context, id uuid.UUID) (RoleGrantRequest, error) {
+	row := q.db.QueryRowContext(ctx, getRoleGrantRequestByID, id)
+	var i RoleGrantRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.RoleName,
+		&i.Reason,
+		&i.LifetimeSeconds,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getRoleGrantRequests = `-- name: GetRoleGrantRequests :many
+SELECT
+	id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+FROM
+	role_grant_requests
+WHERE
+	CASE
+		WHEN $1 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			organization_id = $1
+		ELSE true
+	END
+	AND CASE
+		WHEN $2 :: text != '' THEN
+			status = $2 :: role_grant_status
+		ELSE true
+	END
+ORDER BY
+	created_at DESC
+`
+
+type GetRoleGrantRequestsParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Status         string    `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) GetRoleGrantRequests(ctx context.Context, arg GetRoleGrantRequestsParams) ([]RoleGrantRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getRoleGrantRequests, arg.OrganizationID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleGrantRequest
+	for rows.Next() {
+		var i RoleGrantRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.RoleName,
+			&i.Reason,
+			&i.LifetimeSeconds,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRoleGrantRequestsByUserID = `-- name: GetRoleGrantRequestsByUserID :many
+SELECT
+	id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+FROM
+	role_grant_requests
+WHERE
+	user_id = $1
+ORDER BY
+	created_at DESC
+`
+
+func (q *sqlQuerier) GetRoleGrantRequestsByUserID(ctx context.Context, userID uuid.UUID) ([]RoleGrantRequest, error) {
+	rows, err := q.db.QueryContext(ctx, getRoleGrantRequestsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleGrantRequest
+	for rows.Next() {
+		var i RoleGrantRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.RoleName,
+			&i.Reason,
+			&i.LifetimeSeconds,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertRoleGrantRequest = `-- name: InsertRoleGrantRequest :one
+INSERT INTO
+	role_grant_requests (
+		id,
+		user_id,
+		organization_id,
+		role_name,
+		reason,
+		lifetime_seconds,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+`
+
+type InsertRoleGrantRequestParams struct {
+	ID              uuid.UUID     `db:"id" json:"id"`
+	UserID          uuid.UUID     `db:"user_id" json:"user_id"`
+	OrganizationID  uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	RoleName        string        `db:"role_name" json:"role_name"`
+	Reason          string        `db:"reason" json:"reason"`
+	LifetimeSeconds int64         `db:"lifetime_seconds" json:"lifetime_seconds"`
+	CreatedAt       time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertRoleGrantRequest(ctx context.Context, arg InsertRoleGrantRequestParams) (RoleGrantRequest, error) {
+	row := q.db.QueryRowContext(ctx, insertRoleGrantRequest,
+		arg.ID,
+		arg.UserID,
+		arg.OrganizationID,
+		arg.RoleName,
+		arg.Reason,
+		arg.LifetimeSeconds,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i RoleGrantRequest
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.RoleName,
+		&i.Reason,
+		&i.LifetimeSeconds,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const updateRoleGrantRequestByID = `-- name: UpdateRoleGrantRequestByID :one
+UPDATE
+	role_grant_requests
+SET
+	status = $1,
+	reviewed_by = $2,
+	reviewed_at = $3,
+	expires_at = $4,
+	updated_at = $5
+WHERE
+	id = $6
+	AND status = $7
+RETURNING id, user_id, organization_id, role_name, reason, lifetime_seconds, status, created_at, updated_at, reviewed_by, reviewed_at, expires_at
+`
+
+type UpdateRoleGrantRequestByIDParams struct {
+	Status     RoleGrantStatus `db:"status" json:"status"`
+	ReviewedBy uuid.NullUUID   `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt sql.NullTime    `db:"reviewed_at" json:"reviewed_at"`
+	ExpiresAt  sql.NullTime    `db:"expires_at" json:"expires_at"`
+	UpdatedAt  time.Time       `db:"updated_at" json:"updated_at"`
+	ID         uuid.UUID       `db:"id" json:"id"`
+	FromStatus RoleGrantStatus `db:"from_status" json:"from_status"`
 }
 
-func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, updateReplica,
-		arg.ID,
+// Moves a request from one status to another. No rows are returned if the
+// request is no longer in the expected status, for example because it was
+// reviewed concurrently.
+func (q *sqlQuerier) UpdateRoleGrantRequestByID(ctx context.Context, arg UpdateRoleGrantRequestByIDParams) (RoleGrantRequest, error) {
+	row := q.db.QueryRowContext(ctx, updateRoleGrantRequestByID,
+		arg.Status,
+		arg.ReviewedBy,
+		arg.ReviewedAt,
+		arg.ExpiresAt,
 		arg.UpdatedAt,
-		arg.StartedAt,
-		arg.StoppedAt,
-		arg.RelayAddress,
-		arg.RegionID,
-		arg.Hostname,
-		arg.Version,
-		arg.Error,
-		arg.DatabaseLatency,
-		arg.Primary,
+		arg.ID,
+		arg.FromStatus,
 	)
-	var i Replica
+	var i RoleGrantRequest
 	err := row.Scan(
 		&i.ID,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.RoleName,
+		&i.Reason,
+		&i.LifetimeSeconds,
+		&i.Status,
 		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
-		&i.Primary,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.ExpiresAt,
 	)
 	return i, err
 }
@@ -7748,7 +8787,7 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTem
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, allowed_workspace_proxy_ids, allowed_derp_region_ids, max_app_connections_per_user, max_app_bytes_per_second, drift_detection_interval, build_timeout, build_cancel_grace_period, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names
 WHERE
@@ -7789,6 +8828,13 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
+		&i.MaxAppConnectionsPerUser,
+		&i.MaxAppBytesPerSecond,
+		&i.DriftDetectionInterval,
+		&i.BuildTimeout,
+		&i.BuildCancelGracePeriod,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -7800,7 +8846,7 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 
 const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, allowed_workspace_proxy_ids, allowed_derp_region_ids, max_app_connections_per_user, max_app_bytes_per_second, drift_detection_interval, build_timeout, build_cancel_grace_period, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -7849,6 +8895,13 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 		&i.Deprecated,
 		&i.ActivityBump,
 		&i.MaxPortSharingLevel,
+		pq.Array(&i.AllowedWorkspaceProxyIDs),
+		pq.Array(&i.AllowedDERPRegionIDs),
+		&i.MaxAppConnectionsPerUser,
+		&i.MaxAppBytesPerSecond,
+		&i.DriftDetectionInterval,
+		&i.BuildTimeout,
+		&i.BuildCancelGracePeriod,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 		&i.OrganizationName,
@@ -7859,7 +8912,7 @@ func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg G
 }
 
 const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, allowed_workspace_proxy_ids, allowed_derp_region_ids, max_app_connections_per_user, max_app_bytes_per_second, drift_detection_interval, build_timeout, build_cancel_grace_period, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon FROM template_with_names AS templates
 ORDER BY (name, id) ASC
 `
 
@@ -7901,6 +8954,13 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			pq.Array(&i.AllowedWorkspaceProxyIDs),
+			pq.Array(&i.AllowedDERPRegionIDs),
+			&i.MaxAppConnectionsPerUser,
+			&i.MaxAppBytesPerSecond,
+			&i.DriftDetectionInterval,
+			&i.BuildTimeout,
+			&i.BuildCancelGracePeriod,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -7922,7 +8982,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, allowed_workspace_proxy_ids, allowed_derp_region_ids, max_app_connections_per_user, max_app_bytes_per_second, drift_detection_interval, build_timeout, build_cancel_grace_period, created_by_avatar_url, created_by_username, organization_name, organization_display_name, organization_icon
 FROM
 	template_with_names AS templates
 WHERE
@@ -8022,6 +9082,13 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.Deprecated,
 			&i.ActivityBump,
 			&i.MaxPortSharingLevel,
+			pq.Array(&i.AllowedWorkspaceProxyIDs),
+			pq.Array(&i.AllowedDERPRegionIDs),
+			&i.MaxAppConnectionsPerUser,
+			&i.MaxAppBytesPerSecond,
+			&i.DriftDetectionInterval,
+			&i.BuildTimeout,
+			&i.BuildCancelGracePeriod,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 			&i.OrganizationName,
@@ -8198,7 +9265,14 @@ SET
 	display_name = $6,
 	allow_user_cancel_workspace_jobs = $7,
 	group_acl = $8,
-	max_port_sharing_level = $9
+	max_port_sharing_level = $9,
+	allowed_workspace_proxy_ids = $10,
+	allowed_derp_region_ids = $11,
+	max_app_connections_per_user = $12,
+	max_app_bytes_per_second = $13,
+	drift_detection_interval = $14,
+	build_timeout = $15,
+	build_cancel_grace_period = $16
 WHERE
 	id = $1
 `
@@ -8213,6 +9287,13 @@ type UpdateTemplateMetaByIDParams struct {
 	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
 	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
 	MaxPortSharingLevel          AppSharingLevel `db:"max_port_sharing_level" json:"max_port_sharing_level"`
+	AllowedWorkspaceProxyIDs     []uuid.UUID     `db:"allowed_workspace_proxy_ids" json:"allowed_workspace_proxy_ids"`
+	AllowedDERPRegionIDs         []int32         `db:"allowed_derp_region_ids" json:"allowed_derp_region_ids"`
+	MaxAppConnectionsPerUser     int64           `db:"max_app_connections_per_user" json:"max_app_connections_per_user"`
+	MaxAppBytesPerSecond         int64           `db:"max_app_bytes_per_second" json:"max_app_bytes_per_second"`
+	DriftDetectionInterval       int64           `db:"drift_detection_interval" json:"drift_detection_interval"`
+	BuildTimeout                 int64           `db:"build_timeout" json:"build_timeout"`
+	BuildCancelGracePeriod       int64           `db:"build_cancel_grace_period" json:"build_cancel_grace_period"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -8226,6 +9307,13 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.AllowUserCancelWorkspaceJobs,
 		arg.GroupACL,
 		arg.MaxPortSharingLevel,
+		pq.Array(arg.AllowedWorkspaceProxyIDs),
+		pq.Array(arg.AllowedDERPRegionIDs),
+		arg.MaxAppConnectionsPerUser,
+		arg.MaxAppBytesPerSecond,
+		arg.DriftDetectionInterval,
+		arg.BuildTimeout,
+		arg.BuildCancelGracePeriod,
 	)
 	return err
 }
@@ -8279,7 +9367,376 @@ func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateT
 		arg.TimeTilDormant,
 		arg.TimeTilDormantAutoDelete,
 	)
-	return err
+	return err
+}
+
+const getActiveTemplateVersionCanaries = `-- name: GetActiveTemplateVersionCanaries :many
+SELECT
+	id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+FROM
+	template_version_canaries
+WHERE
+	status = 'active'
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetActiveTemplateVersionCanaries(ctx context.Context) ([]TemplateVersionCanary, error) {
+	rows, err := q.db.QueryContext(ctx, getActiveTemplateVersionCanaries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionCanary
+	for rows.Next() {
+		var i TemplateVersionCanary
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.Percent,
+			&i.GroupID,
+			&i.MinBuilds,
+			&i.SuccessThreshold,
+			&i.Status,
+			&i.StatusReason,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveTemplateVersionCanaryByTemplateID = `-- name: GetActiveTemplateVersionCanaryByTemplateID :one
+SELECT
+	id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+FROM
+	template_version_canaries
+WHERE
+	template_id = $1
+	AND status = 'active'
+`
+
+func (q *sqlQuerier) GetActiveTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, getActiveTemplateVersionCanaryByTemplateID, templateID)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getLatestTemplateVersionCanaryByTemplateID = `-- name: GetLatestTemplateVersionCanaryByTemplateID :one
+SELECT
+	id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+FROM
+	template_version_canaries
+WHERE
+	template_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetLatestTemplateVersionCanaryByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, getLatestTemplateVersionCanaryByTemplateID, templateID)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTemplateVersionCanaryByID = `-- name: GetTemplateVersionCanaryByID :one
+SELECT
+	id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+FROM
+	template_version_canaries
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionCanaryByID(ctx context.Context, id uuid.UUID) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionCanaryByID, id)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTemplateVersionCanaryByWorkspaceID = `-- name: GetTemplateVersionCanaryByWorkspaceID :one
+SELECT
+	template_version_canaries.id, template_version_canaries.template_id, template_version_canaries.template_version_id, template_version_canaries.percent, template_version_canaries.group_id, template_version_canaries.min_builds, template_version_canaries.success_threshold, template_version_canaries.status, template_version_canaries.status_reason, template_version_canaries.created_by, template_version_canaries.created_at, template_version_canaries.updated_at, template_version_canaries.completed_at
+FROM
+	template_version_canaries
+INNER JOIN
+	workspaces ON workspaces.template_id = template_version_canaries.template_id
+WHERE
+	workspaces.id = $1
+	AND template_version_canaries.status = 'active'
+	AND (
+		(
+			template_version_canaries.group_id IS NOT NULL
+			AND EXISTS (
+				SELECT
+					1
+				FROM
+					group_members_expanded
+				WHERE
+					group_members_expanded.group_id = template_version_canaries.group_id
+					AND group_members_expanded.user_id = workspaces.owner_id
+			)
+		)
+		OR ('x' || substr(md5(workspaces.id :: text), 1, 8)) :: bit(32) :: bigint % 100 < template_version_canaries.percent
+	)
+`
+
+// Returns the active canary of the workspace's template if the workspace is
+// in its cohort. A workspace is in the cohort if its owner is a member of the
+// canary's group, or if a hash of its ID falls within the canary's percent.
+func (q *sqlQuerier) GetTemplateVersionCanaryByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionCanaryByWorkspaceID, workspaceID)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTemplateVersionCanaryStats = `-- name: GetTemplateVersionCanaryStats :one
+SELECT
+	COUNT(*) FILTER (
+		WHERE
+			provisioner_jobs.job_status = 'succeeded'
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_agents
+				INNER JOIN
+					workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+				WHERE
+					workspace_resources.job_id = provisioner_jobs.id
+					AND workspace_agents.lifecycle_state IN ('created', 'starting', 'start_timeout', 'start_error')
+			)
+	) :: bigint AS succeeded_builds,
+	COUNT(*) FILTER (
+		WHERE
+			provisioner_jobs.job_status = 'failed'
+			OR EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_agents
+				INNER JOIN
+					workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+				WHERE
+					workspace_resources.job_id = provisioner_jobs.id
+					AND workspace_agents.lifecycle_state IN ('start_timeout', 'start_error')
+			)
+	) :: bigint AS failed_builds
+FROM
+	workspace_builds
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+WHERE
+	workspace_builds.template_version_id = $1
+	AND workspace_builds.transition = 'start'
+	AND workspace_builds.created_at >= $2 :: timestamptz
+`
+
+type GetTemplateVersionCanaryStatsParams struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Since             time.Time `db:"since" json:"since"`
+}
+
+type GetTemplateVersionCanaryStatsRow struct {
+	SucceededBuilds int64 `db:"succeeded_builds" json:"succeeded_builds"`
+	FailedBuilds    int64 `db:"failed_builds" json:"failed_builds"`
+}
+
+// Counts the finished start builds of a template version since a point in
+// time. A build succeeded if its job succeeded and all of its agents became
+// ready, and failed if its job failed or any of its agents failed to start.
+// Builds whose agents are still starting are not counted yet.
+func (q *sqlQuerier) GetTemplateVersionCanaryStats(ctx context.Context, arg GetTemplateVersionCanaryStatsParams) (GetTemplateVersionCanaryStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionCanaryStats, arg.TemplateVersionID, arg.Since)
+	var i GetTemplateVersionCanaryStatsRow
+	err := row.Scan(&i.SucceededBuilds, &i.FailedBuilds)
+	return i, err
+}
+
+const insertTemplateVersionCanary = `-- name: InsertTemplateVersionCanary :one
+INSERT INTO
+	template_version_canaries (
+		id,
+		template_id,
+		template_version_id,
+		percent,
+		group_id,
+		min_builds,
+		success_threshold,
+		created_by,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+`
+
+type InsertTemplateVersionCanaryParams struct {
+	ID                uuid.UUID     `db:"id" json:"id"`
+	TemplateID        uuid.UUID     `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID     `db:"template_version_id" json:"template_version_id"`
+	Percent           int32         `db:"percent" json:"percent"`
+	GroupID           uuid.NullUUID `db:"group_id" json:"group_id"`
+	MinBuilds         int32         `db:"min_builds" json:"min_builds"`
+	SuccessThreshold  int32         `db:"success_threshold" json:"success_threshold"`
+	CreatedBy         uuid.UUID     `db:"created_by" json:"created_by"`
+	CreatedAt         time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time     `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionCanary(ctx context.Context, arg InsertTemplateVersionCanaryParams) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionCanary,
+		arg.ID,
+		arg.TemplateID,
+		arg.TemplateVersionID,
+		arg.Percent,
+		arg.GroupID,
+		arg.MinBuilds,
+		arg.SuccessThreshold,
+		arg.CreatedBy,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const updateTemplateVersionCanaryStatusByID = `-- name: UpdateTemplateVersionCanaryStatusByID :one
+UPDATE
+	template_version_canaries
+SET
+	status = $2,
+	status_reason = $3,
+	updated_at = $4,
+	completed_at = $4
+WHERE
+	id = $1
+	AND status = 'active'
+RETURNING id, template_id, template_version_id, percent, group_id, min_builds, success_threshold, status, status_reason, created_by, created_at, updated_at, completed_at
+`
+
+type UpdateTemplateVersionCanaryStatusByIDParams struct {
+	ID           uuid.UUID                   `db:"id" json:"id"`
+	Status       TemplateVersionCanaryStatus `db:"status" json:"status"`
+	StatusReason string                      `db:"status_reason" json:"status_reason"`
+	UpdatedAt    time.Time                   `db:"updated_at" json:"updated_at"`
+}
+
+// Ends an active canary. Canaries that already ended are left untouched and
+// no rows are returned.
+func (q *sqlQuerier) UpdateTemplateVersionCanaryStatusByID(ctx context.Context, arg UpdateTemplateVersionCanaryStatusByIDParams) (TemplateVersionCanary, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateVersionCanaryStatusByID,
+		arg.ID,
+		arg.Status,
+		arg.StatusReason,
+		arg.UpdatedAt,
+	)
+	var i TemplateVersionCanary
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.Percent,
+		&i.GroupID,
+		&i.MinBuilds,
+		&i.SuccessThreshold,
+		&i.Status,
+		&i.StatusReason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
 }
 
 const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
@@ -9432,13 +10889,29 @@ func (q *sqlQuerier) AllUserIDs(ctx context.Context) ([]uuid.UUID, error) {
 	return items, nil
 }
 
+const getActiveServiceAccountCount = `-- name: GetActiveServiceAccountCount :one
+SELECT
+	COUNT(*)
+FROM
+	users
+WHERE
+	status = 'active'::user_status AND deleted = false AND is_service_account = true
+`
+
+func (q *sqlQuerier) GetActiveServiceAccountCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getActiveServiceAccountCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getActiveUserCount = `-- name: GetActiveUserCount :one
 SELECT
 	COUNT(*)
 FROM
 	users
 WHERE
-	status = 'active'::user_status AND deleted = false
+	status = 'active'::user_status AND deleted = false AND is_service_account = false
 `
 
 func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
@@ -9515,7 +10988,7 @@ func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.
 
 const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 FROM
 	users
 WHERE
@@ -9550,13 +11023,15 @@ func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserBy
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 FROM
 	users
 WHERE
@@ -9585,6 +11060,8 @@ func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -9607,7 +11084,7 @@ func (q *sqlQuerier) GetUserCount(ctx context.Context) (int64, error) {
 
 const getUsers = `-- name: GetUsers :many
 SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, COUNT(*) OVER() AS count
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id, COUNT(*) OVER() AS count
 FROM
 	users
 WHERE
@@ -9691,23 +11168,25 @@ type GetUsersParams struct {
 }
 
 type GetUsersRow struct {
-	ID                 uuid.UUID      `db:"id" json:"id"`
-	Email              string         `db:"email" json:"email"`
-	Username           string         `db:"username" json:"username"`
-	HashedPassword     []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt          time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt          time.Time      `db:"updated_at" json:"updated_at"`
-	Status             UserStatus     `db:"status" json:"status"`
-	RBACRoles          pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType          LoginType      `db:"login_type" json:"login_type"`
-	AvatarURL          string         `db:"avatar_url" json:"avatar_url"`
-	Deleted            bool           `db:"deleted" json:"deleted"`
-	LastSeenAt         time.Time      `db:"last_seen_at" json:"last_seen_at"`
-	QuietHoursSchedule string         `db:"quiet_hours_schedule" json:"quiet_hours_schedule"`
-	ThemePreference    string         `db:"theme_preference" json:"theme_preference"`
-	Name               string         `db:"name" json:"name"`
-	GithubComUserID    sql.NullInt64  `db:"github_com_user_id" json:"github_com_user_id"`
-	Count              int64          `db:"count" json:"count"`
+	ID                           uuid.UUID      `db:"id" json:"id"`
+	Email                        string         `db:"email" json:"email"`
+	Username                     string         `db:"username" json:"username"`
+	HashedPassword               []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt                    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time      `db:"updated_at" json:"updated_at"`
+	Status                       UserStatus     `db:"status" json:"status"`
+	RBACRoles                    pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType                    LoginType      `db:"login_type" json:"login_type"`
+	AvatarURL                    string         `db:"avatar_url" json:"avatar_url"`
+	Deleted                      bool           `db:"deleted" json:"deleted"`
+	LastSeenAt                   time.Time      `db:"last_seen_at" json:"last_seen_at"`
+	QuietHoursSchedule           string         `db:"quiet_hours_schedule" json:"quiet_hours_schedule"`
+	ThemePreference              string         `db:"theme_preference" json:"theme_preference"`
+	Name                         string         `db:"name" json:"name"`
+	GithubComUserID              sql.NullInt64  `db:"github_com_user_id" json:"github_com_user_id"`
+	IsServiceAccount             bool           `db:"is_service_account" json:"is_service_account"`
+	ServiceAccountOrganizationID uuid.NullUUID  `db:"service_account_organization_id" json:"service_account_organization_id"`
+	Count                        int64          `db:"count" json:"count"`
 }
 
 // This will never return deleted users.
@@ -9746,6 +11225,8 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 			&i.ThemePreference,
 			&i.Name,
 			&i.GithubComUserID,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -9762,7 +11243,7 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 }
 
 const getUsersByIDs = `-- name: GetUsersByIDs :many
-SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id FROM users WHERE id = ANY($1 :: uuid [ ])
+SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id FROM users WHERE id = ANY($1 :: uuid [ ])
 `
 
 // This shouldn't check for deleted, because it's frequently used
@@ -9794,6 +11275,8 @@ func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User
 			&i.ThemePreference,
 			&i.Name,
 			&i.GithubComUserID,
+			&i.IsServiceAccount,
+			&i.ServiceAccountOrganizationID,
 		); err != nil {
 			return nil, err
 		}
@@ -9819,22 +11302,26 @@ INSERT INTO
 		created_at,
 		updated_at,
 		rbac_roles,
-		login_type
+		login_type,
+		is_service_account,
+		service_account_organization_id
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type InsertUserParams struct {
-	ID             uuid.UUID      `db:"id" json:"id"`
-	Email          string         `db:"email" json:"email"`
-	Username       string         `db:"username" json:"username"`
-	Name           string         `db:"name" json:"name"`
-	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
-	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType      LoginType      `db:"login_type" json:"login_type"`
+	ID                           uuid.UUID      `db:"id" json:"id"`
+	Email                        string         `db:"email" json:"email"`
+	Username                     string         `db:"username" json:"username"`
+	Name                         string         `db:"name" json:"name"`
+	HashedPassword               []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt                    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time      `db:"updated_at" json:"updated_at"`
+	RBACRoles                    pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType                    LoginType      `db:"login_type" json:"login_type"`
+	IsServiceAccount             bool           `db:"is_service_account" json:"is_service_account"`
+	ServiceAccountOrganizationID uuid.NullUUID  `db:"service_account_organization_id" json:"service_account_organization_id"`
 }
 
 func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
@@ -9848,6 +11335,8 @@ func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User
 		arg.UpdatedAt,
 		arg.RBACRoles,
 		arg.LoginType,
+		arg.IsServiceAccount,
+		arg.ServiceAccountOrganizationID,
 	)
 	var i User
 	err := row.Scan(
@@ -9867,6 +11356,8 @@ func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -9925,7 +11416,7 @@ SET
 	updated_at = $3
 WHERE
 	id = $1
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserAppearanceSettingsParams struct {
@@ -9954,6 +11445,8 @@ func (q *sqlQuerier) UpdateUserAppearanceSettings(ctx context.Context, arg Updat
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10017,7 +11510,7 @@ SET
 	last_seen_at = $2,
 	updated_at = $3
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserLastSeenAtParams struct {
@@ -10046,6 +11539,8 @@ func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLas
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10063,7 +11558,7 @@ SET
 		'':: bytea
 	END
 WHERE
-	id = $2 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	id = $2 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserLoginTypeParams struct {
@@ -10091,6 +11586,8 @@ func (q *sqlQuerier) UpdateUserLoginType(ctx context.Context, arg UpdateUserLogi
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10106,7 +11603,7 @@ SET
 	name = $6
 WHERE
 	id = $1
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserProfileParams struct {
@@ -10145,6 +11642,8 @@ func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfil
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10156,7 +11655,7 @@ SET
 	quiet_hours_schedule = $2
 WHERE
 	id = $1
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserQuietHoursScheduleParams struct {
@@ -10184,6 +11683,8 @@ func (q *sqlQuerier) UpdateUserQuietHoursSchedule(ctx context.Context, arg Updat
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10196,7 +11697,7 @@ SET
 	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
 WHERE
 	id = $2
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserRolesParams struct {
@@ -10224,6 +11725,8 @@ func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesPar
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
 	)
 	return i, err
 }
@@ -10235,7 +11738,7 @@ SET
 	status = $2,
 	updated_at = $3
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule, theme_preference, name, github_com_user_id, is_service_account, service_account_organization_id
 `
 
 type UpdateUserStatusParams struct {
@@ -10264,6 +11767,199 @@ func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusP
 		&i.ThemePreference,
 		&i.Name,
 		&i.GithubComUserID,
+		&i.IsServiceAccount,
+		&i.ServiceAccountOrganizationID,
+	)
+	return i, err
+}
+
+const deleteUserTOTPByUserID = `-- name: DeleteUserTOTPByUserID :exec
+DELETE FROM
+	user_totp
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteUserTOTPByUserID(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTOTPByUserID, userID)
+	return err
+}
+
+const getUserTOTPByUserID = `-- name: GetUserTOTPByUserID :one
+SELECT
+	user_id, secret, secret_key_id, hashed_recovery_codes, created_at, enabled_at, last_used_counter
+FROM
+	user_totp
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, getUserTOTPByUserID, userID)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.SecretKeyID,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.CreatedAt,
+		&i.EnabledAt,
+		&i.LastUsedCounter,
+	)
+	return i, err
+}
+
+const removeUserTOTPRecoveryCode = `-- name: RemoveUserTOTPRecoveryCode :one
+UPDATE
+	user_totp
+SET
+	hashed_recovery_codes = array_remove(hashed_recovery_codes, $1 :: text)
+WHERE
+	user_id = $2
+	AND $1 :: text = ANY(hashed_recovery_codes)
+RETURNING user_id, secret, secret_key_id, hashed_recovery_codes, created_at, enabled_at, last_used_counter
+`
+
+type RemoveUserTOTPRecoveryCodeParams struct {
+	HashedRecoveryCode string    `db:"hashed_recovery_code" json:"hashed_recovery_code"`
+	UserID             uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+// Removes a recovery code once it is used. No rows are returned if the code
+// is not one of the user's recovery codes, for example because it was already
+// used.
+func (q *sqlQuerier) RemoveUserTOTPRecoveryCode(ctx context.Context, arg RemoveUserTOTPRecoveryCodeParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, removeUserTOTPRecoveryCode, arg.HashedRecoveryCode, arg.UserID)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.SecretKeyID,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.CreatedAt,
+		&i.EnabledAt,
+		&i.LastUsedCounter,
+	)
+	return i, err
+}
+
+const updateUserTOTPEnabledAt = `-- name: UpdateUserTOTPEnabledAt :exec
+UPDATE
+	user_totp
+SET
+	enabled_at = $2
+WHERE
+	user_id = $1
+`
+
+type UpdateUserTOTPEnabledAtParams struct {
+	UserID    uuid.UUID    `db:"user_id" json:"user_id"`
+	EnabledAt sql.NullTime `db:"enabled_at" json:"enabled_at"`
+}
+
+func (q *sqlQuerier) UpdateUserTOTPEnabledAt(ctx context.Context, arg UpdateUserTOTPEnabledAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserTOTPEnabledAt, arg.UserID, arg.EnabledAt)
+	return err
+}
+
+const updateUserTOTPLastUsedCounter = `-- name: UpdateUserTOTPLastUsedCounter :one
+UPDATE
+	user_totp
+SET
+	last_used_counter = $2
+WHERE
+	user_id = $1
+	AND last_used_counter < $2
+RETURNING user_id, secret, secret_key_id, hashed_recovery_codes, created_at, enabled_at, last_used_counter
+`
+
+type UpdateUserTOTPLastUsedCounterParams struct {
+	UserID          uuid.UUID `db:"user_id" json:"user_id"`
+	LastUsedCounter int64     `db:"last_used_counter" json:"last_used_counter"`
+}
+
+// Records the time step of an accepted code. No rows are returned if a code
+// from the same or a later time step was already accepted, which means the
+// code is being replayed.
+func (q *sqlQuerier) UpdateUserTOTPLastUsedCounter(ctx context.Context, arg UpdateUserTOTPLastUsedCounterParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, updateUserTOTPLastUsedCounter, arg.UserID, arg.LastUsedCounter)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.SecretKeyID,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.CreatedAt,
+		&i.EnabledAt,
+		&i.LastUsedCounter,
+	)
+	return i, err
+}
+
+const updateUserTOTPSecret = `-- name: UpdateUserTOTPSecret :exec
+UPDATE
+	user_totp
+SET
+	secret = $2,
+	secret_key_id = $3
+WHERE
+	user_id = $1
+`
+
+type UpdateUserTOTPSecretParams struct {
+	UserID      uuid.UUID      `db:"user_id" json:"user_id"`
+	Secret      string         `db:"secret" json:"secret"`
+	SecretKeyID sql.NullString `db:"secret_key_id" json:"secret_key_id"`
+}
+
+func (q *sqlQuerier) UpdateUserTOTPSecret(ctx context.Context, arg UpdateUserTOTPSecretParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserTOTPSecret, arg.UserID, arg.Secret, arg.SecretKeyID)
+	return err
+}
+
+const upsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO
+	user_totp (user_id, secret, secret_key_id, hashed_recovery_codes, created_at)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(user_id)
+DO UPDATE SET
+	secret = $2,
+	secret_key_id = $3,
+	hashed_recovery_codes = $4,
+	created_at = $5,
+	enabled_at = NULL,
+	last_used_counter = 0
+RETURNING user_id, secret, secret_key_id, hashed_recovery_codes, created_at, enabled_at, last_used_counter
+`
+
+type UpsertUserTOTPParams struct {
+	UserID              uuid.UUID      `db:"user_id" json:"user_id"`
+	Secret              string         `db:"secret" json:"secret"`
+	SecretKeyID         sql.NullString `db:"secret_key_id" json:"secret_key_id"`
+	HashedRecoveryCodes []string       `db:"hashed_recovery_codes" json:"hashed_recovery_codes"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+}
+
+// Starts a new enrollment, replacing any previous secret and recovery codes.
+func (q *sqlQuerier) UpsertUserTOTP(ctx context.Context, arg UpsertUserTOTPParams) (UserTOTP, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserTOTP,
+		arg.UserID,
+		arg.Secret,
+		arg.SecretKeyID,
+		pq.Array(arg.HashedRecoveryCodes),
+		arg.CreatedAt,
+	)
+	var i UserTOTP
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.SecretKeyID,
+		pq.Array(&i.HashedRecoveryCodes),
+		&i.CreatedAt,
+		&i.EnabledAt,
+		&i.LastUsedCounter,
 	)
 	return i, err
 }
@@ -10468,7 +12164,7 @@ const getWorkspaceAgentAndLatestBuildByAuthToken = `-- name: GetWorkspaceAgentAn
 SELECT
 	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.dormant_at, workspaces.deleting_at, workspaces.automatic_updates, workspaces.favorite,
 	workspace_agents.id, workspace_agents.created_at, workspace_agents.updated_at, workspace_agents.name, workspace_agents.first_connected_at, workspace_agents.last_connected_at, workspace_agents.disconnected_at, workspace_agents.resource_id, workspace_agents.auth_token, workspace_agents.auth_instance_id, workspace_agents.architecture, workspace_agents.environment_variables, workspace_agents.operating_system, workspace_agents.instance_metadata, workspace_agents.resource_metadata, workspace_agents.directory, workspace_agents.version, workspace_agents.last_connected_replica_id, workspace_agents.connection_timeout_seconds, workspace_agents.troubleshooting_url, workspace_agents.motd_file, workspace_agents.lifecycle_state, workspace_agents.expanded_directory, workspace_agents.logs_length, workspace_agents.logs_overflowed, workspace_agents.started_at, workspace_agents.ready_at, workspace_agents.subsystems, workspace_agents.display_apps, workspace_agents.api_version, workspace_agents.display_order,
-	workspace_build_with_user.id, workspace_build_with_user.created_at, workspace_build_with_user.updated_at, workspace_build_with_user.workspace_id, workspace_build_with_user.template_version_id, workspace_build_with_user.build_number, workspace_build_with_user.transition, workspace_build_with_user.initiator_id, workspace_build_with_user.provisioner_state, workspace_build_with_user.job_id, workspace_build_with_user.deadline, workspace_build_with_user.reason, workspace_build_with_user.daily_cost, workspace_build_with_user.max_deadline, workspace_build_with_user.initiator_by_avatar_url, workspace_build_with_user.initiator_by_username
+	workspace_build_with_user.id, workspace_build_with_user.created_at, workspace_build_with_user.updated_at, workspace_build_with_user.workspace_id, workspace_build_with_user.template_version_id, workspace_build_with_user.build_number, workspace_build_with_user.transition, workspace_build_with_user.initiator_id, workspace_build_with_user.provisioner_state, workspace_build_with_user.job_id, workspace_build_with_user.deadline, workspace_build_with_user.reason, workspace_build_with_user.daily_cost, workspace_build_with_user.max_deadline, workspace_build_with_user.provisioner_state_key_id, workspace_build_with_user.initiator_by_avatar_url, workspace_build_with_user.initiator_by_username
 FROM
 	workspace_agents
 JOIN
@@ -10570,6 +12266,7 @@ func (q *sqlQuerier) GetWorkspaceAgentAndLatestBuildByAuthToken(ctx context.Cont
 		&i.WorkspaceBuild.Reason,
 		&i.WorkspaceBuild.DailyCost,
 		&i.WorkspaceBuild.MaxDeadline,
+		&i.WorkspaceBuild.ProvisionerStateKeyID,
 		&i.WorkspaceBuild.InitiatorByAvatarUrl,
 		&i.WorkspaceBuild.InitiatorByUsername,
 	)
@@ -12336,7 +14033,7 @@ func (q *sqlQuerier) InsertWorkspaceBuildParameters(ctx context.Context, arg Ins
 }
 
 const getActiveWorkspaceBuildsByTemplateID = `-- name: GetActiveWorkspaceBuildsByTemplateID :many
-SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.initiator_by_avatar_url, wb.initiator_by_username
+SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.provisioner_state_key_id, wb.initiator_by_avatar_url, wb.initiator_by_username
 FROM (
     SELECT
         workspace_id, MAX(build_number) as max_build_number
@@ -12390,6 +14087,7 @@ func (q *sqlQuerier) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, t
 			&i.Reason,
 			&i.DailyCost,
 			&i.MaxDeadline,
+			&i.ProvisionerStateKeyID,
 			&i.InitiatorByAvatarUrl,
 			&i.InitiatorByUsername,
 		); err != nil {
@@ -12408,7 +14106,7 @@ func (q *sqlQuerier) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, t
 
 const getLatestWorkspaceBuildByWorkspaceID = `-- name: GetLatestWorkspaceBuildByWorkspaceID :one
 SELECT
-	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username
 FROM
 	workspace_build_with_user AS workspace_builds
 WHERE
@@ -12437,6 +14135,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, w
 		&i.Reason,
 		&i.DailyCost,
 		&i.MaxDeadline,
+		&i.ProvisionerStateKeyID,
 		&i.InitiatorByAvatarUrl,
 		&i.InitiatorByUsername,
 	)
@@ -12444,7 +14143,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, w
 }
 
 const getLatestWorkspaceBuilds = `-- name: GetLatestWorkspaceBuilds :many
-SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.initiator_by_avatar_url, wb.initiator_by_username
+SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.provisioner_state_key_id, wb.initiator_by_avatar_url, wb.initiator_by_username
 FROM (
     SELECT
         workspace_id, MAX(build_number) as max_build_number
@@ -12482,6 +14181,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuilds(ctx context.Context) ([]WorkspaceB
 			&i.Reason,
 			&i.DailyCost,
 			&i.MaxDeadline,
+			&i.ProvisionerStateKeyID,
 			&i.InitiatorByAvatarUrl,
 			&i.InitiatorByUsername,
 		); err != nil {
@@ -12499,7 +14199,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuilds(ctx context.Context) ([]WorkspaceB
 }
 
 const getLatestWorkspaceBuildsByWorkspaceIDs = `-- name: GetLatestWorkspaceBuildsByWorkspaceIDs :many
-SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.initiator_by_avatar_url, wb.initiator_by_username
+SELECT wb.id, wb.created_at, wb.updated_at, wb.workspace_id, wb.template_version_id, wb.build_number, wb.transition, wb.initiator_id, wb.provisioner_state, wb.job_id, wb.deadline, wb.reason, wb.daily_cost, wb.max_deadline, wb.provisioner_state_key_id, wb.initiator_by_avatar_url, wb.initiator_by_username
 FROM (
     SELECT
         workspace_id, MAX(build_number) as max_build_number
@@ -12539,6 +14239,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuildsByWorkspaceIDs(ctx context.Context,
 			&i.Reason,
 			&i.DailyCost,
 			&i.MaxDeadline,
+			&i.ProvisionerStateKeyID,
 			&i.InitiatorByAvatarUrl,
 			&i.InitiatorByUsername,
 		); err != nil {
@@ -12557,7 +14258,7 @@ func (q *sqlQuerier) GetLatestWorkspaceBuildsByWorkspaceIDs(ctx context.Context,
 
 const getWorkspaceBuildByID = `-- name: GetWorkspaceBuildByID :one
 SELECT
-	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username
 FROM
 	workspace_build_with_user AS workspace_builds
 WHERE
@@ -12584,6 +14285,7 @@ func (q *sqlQuerier) GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (W
 		&i.Reason,
 		&i.DailyCost,
 		&i.MaxDeadline,
+		&i.ProvisionerStateKeyID,
 		&i.InitiatorByAvatarUrl,
 		&i.InitiatorByUsername,
 	)
@@ -12592,7 +14294,7 @@ func (q *sqlQuerier) GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (W
 
 const getWorkspaceBuildByJobID = `-- name: GetWorkspaceBuildByJobID :one
 SELECT
-	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username
 FROM
 	workspace_build_with_user AS workspace_builds
 WHERE
@@ -12619,6 +14321,7 @@ func (q *sqlQuerier) GetWorkspaceBuildByJobID(ctx context.Context, jobID uuid.UU
 		&i.Reason,
 		&i.DailyCost,
 		&i.MaxDeadline,
+		&i.ProvisionerStateKeyID,
 		&i.InitiatorByAvatarUrl,
 		&i.InitiatorByUsername,
 	)
@@ -12627,7 +14330,7 @@ func (q *sqlQuerier) GetWorkspaceBuildByJobID(ctx context.Context, jobID uuid.UU
 
 const getWorkspaceBuildByWorkspaceIDAndBuildNumber = `-- name: GetWorkspaceBuildByWorkspaceIDAndBuildNumber :one
 SELECT
-	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username
 FROM
 	workspace_build_with_user AS workspace_builds
 WHERE
@@ -12658,15 +14361,73 @@ func (q *sqlQuerier) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Co
 		&i.Reason,
 		&i.DailyCost,
 		&i.MaxDeadline,
+		&i.ProvisionerStateKeyID,
 		&i.InitiatorByAvatarUrl,
 		&i.InitiatorByUsername,
 	)
 	return i, err
 }
 
+const getWorkspaceBuildProvisionerStates = `-- name: GetWorkspaceBuildProvisionerStates :many
+SELECT
+	id, provisioner_state, provisioner_state_key_id, updated_at
+FROM
+	workspace_builds
+WHERE
+	id > $1 :: uuid
+	AND octet_length(provisioner_state) > 0
+ORDER BY
+	id ASC
+LIMIT
+	$2 :: int
+`
+
+type GetWorkspaceBuildProvisionerStatesParams struct {
+	AfterID  uuid.UUID `db:"after_id" json:"after_id"`
+	LimitOpt int32     `db:"limit_opt" json:"limit_opt"`
+}
+
+type GetWorkspaceBuildProvisionerStatesRow struct {
+	ID                    uuid.UUID      `db:"id" json:"id"`
+	ProvisionerState      []byte         `db:"provisioner_state" json:"provisioner_state"`
+	ProvisionerStateKeyID sql.NullString `db:"provisioner_state_key_id" json:"provisioner_state_key_id"`
+	UpdatedAt             time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Returns the provisioner state of workspace builds ordered by ID, starting
+// after the given ID. This is used to process all build states in batches,
+// e.g. when rotating database encryption keys.
+func (q *sqlQuerier) GetWorkspaceBuildProvisionerStates(ctx context.Context, arg GetWorkspaceBuildProvisionerStatesParams) ([]GetWorkspaceBuildProvisionerStatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBuildProvisionerStates, arg.AfterID, arg.LimitOpt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceBuildProvisionerStatesRow
+	for rows.Next() {
+		var i GetWorkspaceBuildProvisionerStatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProvisionerState,
+			&i.ProvisionerStateKeyID,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceBuildsByWorkspaceID = `-- name: GetWorkspaceBuildsByWorkspaceID :many
 SELECT
-	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username
+	id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username
 FROM
 	workspace_build_with_user AS workspace_builds
 WHERE
@@ -12736,6 +14497,7 @@ func (q *sqlQuerier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg Ge
 			&i.Reason,
 			&i.DailyCost,
 			&i.MaxDeadline,
+			&i.ProvisionerStateKeyID,
 			&i.InitiatorByAvatarUrl,
 			&i.InitiatorByUsername,
 		); err != nil {
@@ -12753,7 +14515,7 @@ func (q *sqlQuerier) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg Ge
 }
 
 const getWorkspaceBuildsCreatedAfter = `-- name: GetWorkspaceBuildsCreatedAfter :many
-SELECT id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, initiator_by_avatar_url, initiator_by_username FROM workspace_build_with_user WHERE created_at > $1
+SELECT id, created_at, updated_at, workspace_id, template_version_id, build_number, transition, initiator_id, provisioner_state, job_id, deadline, reason, daily_cost, max_deadline, provisioner_state_key_id, initiator_by_avatar_url, initiator_by_username FROM workspace_build_with_user WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceBuild, error) {
@@ -12780,6 +14542,7 @@ func (q *sqlQuerier) GetWorkspaceBuildsCreatedAfter(ctx context.Context, created
 			&i.Reason,
 			&i.DailyCost,
 			&i.MaxDeadline,
+			&i.ProvisionerStateKeyID,
 			&i.InitiatorByAvatarUrl,
 			&i.InitiatorByUsername,
 		); err != nil {
@@ -12811,26 +14574,28 @@ INSERT INTO
 		provisioner_state,
 		deadline,
 		max_deadline,
-		reason
+		reason,
+		provisioner_state_key_id
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 `
 
 type InsertWorkspaceBuildParams struct {
-	ID                uuid.UUID           `db:"id" json:"id"`
-	CreatedAt         time.Time           `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time           `db:"updated_at" json:"updated_at"`
-	WorkspaceID       uuid.UUID           `db:"workspace_id" json:"workspace_id"`
-	TemplateVersionID uuid.UUID           `db:"template_version_id" json:"template_version_id"`
-	BuildNumber       int32               `db:"build_number" json:"build_number"`
-	Transition        WorkspaceTransition `db:"transition" json:"transition"`
-	InitiatorID       uuid.UUID           `db:"initiator_id" json:"initiator_id"`
-	JobID             uuid.UUID           `db:"job_id" json:"job_id"`
-	ProvisionerState  []byte              `db:"provisioner_state" json:"provisioner_state"`
-	Deadline          time.Time           `db:"deadline" json:"deadline"`
-	MaxDeadline       time.Time           `db:"max_deadline" json:"max_deadline"`
-	Reason            BuildReason         `db:"reason" json:"reason"`
+	ID                    uuid.UUID           `db:"id" json:"id"`
+	CreatedAt             time.Time           `db:"created_at" json:"created_at"`
+	UpdatedAt             time.Time           `db:"updated_at" json:"updated_at"`
+	WorkspaceID           uuid.UUID           `db:"workspace_id" json:"workspace_id"`
+	TemplateVersionID     uuid.UUID           `db:"template_version_id" json:"template_version_id"`
+	BuildNumber           int32               `db:"build_number" json:"build_number"`
+	Transition            WorkspaceTransition `db:"transition" json:"transition"`
+	InitiatorID           uuid.UUID           `db:"initiator_id" json:"initiator_id"`
+	JobID                 uuid.UUID           `db:"job_id" json:"job_id"`
+	ProvisionerState      []byte              `db:"provisioner_state" json:"provisioner_state"`
+	Deadline              time.Time           `db:"deadline" json:"deadline"`
+	MaxDeadline           time.Time           `db:"max_deadline" json:"max_deadline"`
+	Reason                BuildReason         `db:"reason" json:"reason"`
+	ProvisionerStateKeyID sql.NullString      `db:"provisioner_state_key_id" json:"provisioner_state_key_id"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspaceBuildParams) error {
@@ -12848,6 +14613,7 @@ func (q *sqlQuerier) InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspa
 		arg.Deadline,
 		arg.MaxDeadline,
 		arg.Reason,
+		arg.ProvisionerStateKeyID,
 	)
 	return err
 }
@@ -12903,21 +14669,193 @@ UPDATE
 	workspace_builds
 SET
 	provisioner_state = $1::bytea,
-	updated_at = $2::timestamptz
-WHERE id = $3::uuid
+	provisioner_state_key_id = $2,
+	updated_at = $3::timestamptz
+WHERE id = $4::uuid
 `
 
 type UpdateWorkspaceBuildProvisionerStateByIDParams struct {
-	ProvisionerState []byte    `db:"provisioner_state" json:"provisioner_state"`
-	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
-	ID               uuid.UUID `db:"id" json:"id"`
+	ProvisionerState      []byte         `db:"provisioner_state" json:"provisioner_state"`
+	ProvisionerStateKeyID sql.NullString `db:"provisioner_state_key_id" json:"provisioner_state_key_id"`
+	UpdatedAt             time.Time      `db:"updated_at" json:"updated_at"`
+	ID                    uuid.UUID      `db:"id" json:"id"`
 }
 
 func (q *sqlQuerier) UpdateWorkspaceBuildProvisionerStateByID(ctx context.Context, arg UpdateWorkspaceBuildProvisionerStateByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildProvisionerStateByID, arg.ProvisionerState, arg.UpdatedAt, arg.ID)
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildProvisionerStateByID,
+		arg.ProvisionerState,
+		arg.ProvisionerStateKeyID,
+		arg.UpdatedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const getWorkspaceDriftCheckByWorkspaceID = `-- name: GetWorkspaceDriftCheckByWorkspaceID :one
+SELECT
+	workspace_id, job_id, scheduled_at, result_job_id, checked_at
+FROM
+	workspace_drift_checks
+WHERE
+	workspace_id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceDriftCheckByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceDriftCheck, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceDriftCheckByWorkspaceID, workspaceID)
+	var i WorkspaceDriftCheck
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.JobID,
+		&i.ScheduledAt,
+		&i.ResultJobID,
+		&i.CheckedAt,
+	)
+	return i, err
+}
+
+const getWorkspacesDueForDriftCheck = `-- name: GetWorkspacesDueForDriftCheck :many
+-- Returns running workspaces of templates with drift detection enabled whose
+-- previous drift check has finished and is older than the template interval.
+SELECT
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.dormant_at, workspaces.deleting_at, workspaces.automatic_updates, workspaces.favorite
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+INNER JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+LEFT JOIN
+	workspace_drift_checks ON workspace_drift_checks.workspace_id = workspaces.id
+LEFT JOIN
+	provisioner_jobs AS drift_check_jobs ON workspace_drift_checks.job_id = drift_check_jobs.id
+WHERE
+	workspaces.deleted = false AND
+	workspaces.dormant_at IS NULL AND
+	templates.deleted = false AND
+	templates.drift_detection_interval > 0 AND
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	) AND
+	workspace_builds.transition = 'start'::workspace_transition AND
+	provisioner_jobs.job_status = 'succeeded'::provisioner_job_status AND
+	(
+		workspace_drift_checks.workspace_id IS NULL OR
+		(
+			drift_check_jobs.completed_at IS NOT NULL AND
+			workspace_drift_checks.scheduled_at + (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval <= $1 :: timestamptz
+		)
+	)
+`
+
+// Returns running workspaces of templates with drift detection enabled whose
+// previous drift check has finished and is older than the template interval.
+func (q *sqlQuerier) GetWorkspacesDueForDriftCheck(ctx context.Context, now time.Time) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesDueForDriftCheck, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.DormantAt,
+			&i.DeletingAt,
+			&i.AutomaticUpdates,
+			&i.Favorite,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWorkspaceDriftCheckResult = `-- name: UpdateWorkspaceDriftCheckResult :exec
+UPDATE
+	workspace_drift_checks
+SET
+	result_job_id = $2,
+	checked_at = $3
+WHERE
+	workspace_id = $1
+`
+
+type UpdateWorkspaceDriftCheckResultParams struct {
+	WorkspaceID uuid.UUID     `db:"workspace_id" json:"workspace_id"`
+	ResultJobID uuid.NullUUID `db:"result_job_id" json:"result_job_id"`
+	CheckedAt   sql.NullTime  `db:"checked_at" json:"checked_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceDriftCheckResult(ctx context.Context, arg UpdateWorkspaceDriftCheckResultParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceDriftCheckResult, arg.WorkspaceID, arg.ResultJobID, arg.CheckedAt)
 	return err
 }
 
+const upsertWorkspaceDriftCheck = `-- name: UpsertWorkspaceDriftCheck :one
+INSERT INTO
+	workspace_drift_checks (
+		workspace_id,
+		job_id,
+		scheduled_at
+	)
+VALUES (
+	$1,
+	$2,
+	$3
+)
+ON CONFLICT (
+	workspace_id
+)
+DO UPDATE SET
+	job_id = $2,
+	scheduled_at = $3
+RETURNING workspace_id, job_id, scheduled_at, result_job_id, checked_at
+`
+
+type UpsertWorkspaceDriftCheckParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	JobID       uuid.UUID `db:"job_id" json:"job_id"`
+	ScheduledAt time.Time `db:"scheduled_at" json:"scheduled_at"`
+}
+
+func (q *sqlQuerier) UpsertWorkspaceDriftCheck(ctx context.Context, arg UpsertWorkspaceDriftCheckParams) (WorkspaceDriftCheck, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceDriftCheck, arg.WorkspaceID, arg.JobID, arg.ScheduledAt)
+	var i WorkspaceDriftCheck
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.JobID,
+		&i.ScheduledAt,
+		&i.ResultJobID,
+		&i.CheckedAt,
+	)
+	return i, err
+}
+
 const getWorkspaceResourceByID = `-- name: GetWorkspaceResourceByID :one
 SELECT
 	id, created_at, job_id, transition, type, name, hide, icon, instance_type, daily_cost
@@ -13649,7 +15587,7 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level
+		id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, allow_user_autostart, allow_user_autostop, failure_ttl, time_til_dormant, time_til_dormant_autodelete, autostop_requirement_days_of_week, autostop_requirement_weeks, autostart_block_days_of_week, require_active_version, deprecated, activity_bump, max_port_sharing_level, allowed_workspace_proxy_ids, allowed_derp_region_ids, max_app_connections_per_user, max_app_bytes_per_second, drift_detection_interval, build_timeout, build_cancel_grace_period
 	FROM
 		templates
 	WHERE
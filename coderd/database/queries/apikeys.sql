@@ -30,6 +30,21 @@ SELECT * FROM api_keys WHERE login_type = $1;
 -- name: GetAPIKeysByUserID :many
 SELECT * FROM api_keys WHERE login_type = $1 AND user_id = $2;
 
+-- name: GetSessionAPIKeysByUserID :many
+-- Returns the unexpired login sessions of a user, most recently used first.
+-- Workspace app keys are derived from sessions and are not included.
+SELECT
+	*
+FROM
+	api_keys
+WHERE
+	user_id = @user_id AND
+	login_type = ANY(@login_types :: login_type[]) AND
+	scope = 'all'::api_key_scope AND
+	expires_at > NOW()
+ORDER BY
+	last_used DESC;
+
 -- name: InsertAPIKey :one
 INSERT INTO
 	api_keys (
@@ -44,7 +59,10 @@ INSERT INTO
 		updated_at,
 		login_type,
 		scope,
-		token_name
+		token_name,
+		scopes,
+		allow_list,
+		user_agent
 	)
 VALUES
 	(@id,
@@ -53,7 +71,7 @@ VALUES
 	     WHEN 0 THEN 86400
 		 ELSE @lifetime_seconds::bigint
 	 END
-	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope, @token_name) RETURNING *;
+	 , @hashed_secret, @ip_address, @user_id, @last_used, @expires_at, @created_at, @updated_at, @login_type, @scope, @token_name, @scopes, @allow_list, @user_agent) RETURNING *;
 
 -- name: UpdateAPIKeyByID :exec
 UPDATE
@@ -61,7 +79,8 @@ UPDATE
 SET
 	last_used = $2,
 	expires_at = $3,
-	ip_address = $4
+	ip_address = $4,
+	user_agent = $5
 WHERE
 	id = $1;
 
@@ -83,3 +102,14 @@ DELETE FROM
 	api_keys
 WHERE
 	user_id = $1;
+
+-- name: DeleteSessionAPIKeysByUserID :exec
+-- Deletes the login sessions of a user except the one with the given ID. All
+-- workspace app keys of the user are deleted too, as they cannot be traced back
+-- to the session they were created from.
+DELETE FROM
+	api_keys
+WHERE
+	user_id = @user_id AND
+	login_type = ANY(@login_types :: login_type[]) AND
+	id != @except_id;
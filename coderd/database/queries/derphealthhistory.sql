@@ -0,0 +1,36 @@
+-- name: InsertDERPHealthHistory :exec
+INSERT INTO
+	derp_health_history (
+		created_at,
+		replica_id,
+		region_id,
+		region_code,
+		node_name,
+		healthy,
+		can_exchange_messages,
+		round_trip_ping_ms,
+		packet_loss,
+		stun_enabled,
+		can_stun,
+		error
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetDERPHealthHistory :many
+SELECT
+	*
+FROM
+	derp_health_history
+WHERE
+	created_at >= @created_after :: timestamptz
+	AND CASE
+		WHEN @region_id :: integer != 0 THEN
+			region_id = @region_id
+		ELSE true
+	END
+ORDER BY
+	created_at ASC, id ASC;
+
+-- name: DeleteOldDERPHealthHistory :exec
+DELETE FROM derp_health_history WHERE created_at < NOW() - INTERVAL '7 days';
@@ -11,14 +11,18 @@ INSERT INTO oauth2_provider_apps (
     updated_at,
     name,
     icon,
-    callback_url
+    callback_url,
+    public,
+    client_credentials_user_id
 ) VALUES(
     $1,
     $2,
     $3,
     $4,
     $5,
-    $6
+    $6,
+    $7,
+    $8
 ) RETURNING *;
 
 -- name: UpdateOAuth2ProviderAppByID :one
@@ -26,7 +30,9 @@ UPDATE oauth2_provider_apps SET
     updated_at = $2,
     name = $3,
     icon = $4,
-    callback_url = $5
+    callback_url = $5,
+    public = $6,
+    client_credentials_user_id = $7
 WHERE id = $1 RETURNING *;
 
 -- name: DeleteOAuth2ProviderAppByID :exec
@@ -80,7 +86,9 @@ INSERT INTO oauth2_provider_app_codes (
     secret_prefix,
     hashed_secret,
     app_id,
-    user_id
+    user_id,
+    code_challenge,
+    code_challenge_method
 ) VALUES(
     $1,
     $2,
@@ -88,7 +96,9 @@ INSERT INTO oauth2_provider_app_codes (
     $4,
     $5,
     $6,
-    $7
+    $7,
+    $8,
+    $9
 ) RETURNING *;
 
 -- name: DeleteOAuth2ProviderAppCodeByID :exec
@@ -97,6 +107,55 @@ DELETE FROM oauth2_provider_app_codes WHERE id = $1;
 -- name: DeleteOAuth2ProviderAppCodesByAppAndUserID :exec
 DELETE FROM oauth2_provider_app_codes WHERE app_id = $1 AND user_id = $2;
 
+-- name: GetOAuth2ProviderAppDeviceCodeByID :one
+SELECT * FROM oauth2_provider_app_device_codes WHERE id = $1;
+
+-- name: GetOAuth2ProviderAppDeviceCodeByPrefix :one
+SELECT * FROM oauth2_provider_app_device_codes WHERE device_prefix = $1;
+
+-- name: GetOAuth2ProviderAppDeviceCodeByUserCode :one
+SELECT * FROM oauth2_provider_app_device_codes WHERE user_code = $1;
+
+-- name: InsertOAuth2ProviderAppDeviceCode :one
+INSERT INTO oauth2_provider_app_device_codes (
+    id,
+    created_at,
+    expires_at,
+    device_prefix,
+    hashed_device_code,
+    user_code,
+    app_id
+) VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+) RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppDeviceCodeStatus :one
+-- Only pending codes can be approved or denied, so a code cannot change hands
+-- once a user has acted on it.
+UPDATE oauth2_provider_app_device_codes SET
+    status = $2,
+    user_id = $3
+WHERE id = $1 AND status = 'pending' RETURNING *;
+
+-- name: UpdateOAuth2ProviderAppDeviceCodeLastPolledAt :one
+-- Records a poll of the token endpoint. No rows are returned if the code was
+-- last polled after polled_before, which means the client should slow down.
+UPDATE oauth2_provider_app_device_codes SET
+    last_polled_at = @last_polled_at
+WHERE id = @id AND (last_polled_at IS NULL OR last_polled_at <= @polled_before :: timestamptz) RETURNING *;
+
+-- name: DeleteOAuth2ProviderAppDeviceCodeByID :exec
+DELETE FROM oauth2_provider_app_device_codes WHERE id = $1;
+
+-- name: DeleteExpiredOAuth2ProviderAppDeviceCodes :exec
+DELETE FROM oauth2_provider_app_device_codes WHERE expires_at < NOW();
+
 -- name: InsertOAuth2ProviderAppToken :one
 INSERT INTO oauth2_provider_app_tokens (
     id,
@@ -105,7 +164,8 @@ INSERT INTO oauth2_provider_app_tokens (
     hash_prefix,
     refresh_hash,
     app_secret_id,
-    api_key_id
+    api_key_id,
+    app_id
 ) VALUES(
     $1,
     $2,
@@ -113,7 +173,8 @@ INSERT INTO oauth2_provider_app_tokens (
     $4,
     $5,
     $6,
-    $7
+    $7,
+    $8
 ) RETURNING *;
 
 -- name: GetOAuth2ProviderAppTokenByPrefix :one
@@ -124,10 +185,8 @@ SELECT
   COUNT(DISTINCT oauth2_provider_app_tokens.id) as token_count,
   sqlc.embed(oauth2_provider_apps)
 FROM oauth2_provider_app_tokens
-  INNER JOIN oauth2_provider_app_secrets
-    ON oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
   INNER JOIN oauth2_provider_apps
-    ON oauth2_provider_apps.id = oauth2_provider_app_secrets.app_id
+    ON oauth2_provider_apps.id = oauth2_provider_app_tokens.app_id
   INNER JOIN api_keys
     ON api_keys.id = oauth2_provider_app_tokens.api_key_id
 WHERE
@@ -139,9 +198,8 @@ GROUP BY
 DELETE FROM
   oauth2_provider_app_tokens
 USING
-  oauth2_provider_app_secrets, api_keys
+  api_keys
 WHERE
-  oauth2_provider_app_secrets.id = oauth2_provider_app_tokens.app_secret_id
-  AND api_keys.id = oauth2_provider_app_tokens.api_key_id
-  AND oauth2_provider_app_secrets.app_id = $1
+  api_keys.id = oauth2_provider_app_tokens.api_key_id
+  AND oauth2_provider_app_tokens.app_id = $1
 	AND api_keys.user_id = $2;
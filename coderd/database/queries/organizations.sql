@@ -62,7 +62,9 @@ SET
 	name = @name,
 	display_name = @display_name,
 	description = @description,
-	icon = @icon
+	icon = @icon,
+	allowed_workspace_proxy_ids = @allowed_workspace_proxy_ids,
+	allowed_derp_region_ids = @allowed_derp_region_ids
 WHERE
 	id = @id
 RETURNING *;
@@ -14,9 +14,22 @@ SET
 WHERE
 	id = (
 		SELECT
-			id
+			nested.id
 		FROM
 			provisioner_jobs AS nested
+		LEFT JOIN (
+			SELECT
+				initiator_id,
+				COUNT(*) AS running
+			FROM
+				provisioner_jobs
+			WHERE
+				started_at IS NOT NULL
+				AND completed_at IS NULL
+				AND organization_id = @organization_id
+			GROUP BY
+				initiator_id
+		) AS running_jobs ON running_jobs.initiator_id = nested.initiator_id
 		WHERE
 			nested.started_at IS NULL
 			AND nested.organization_id = @organization_id
@@ -30,8 +43,13 @@ WHERE
 				ELSE nested.tags :: jsonb <@ @tags :: jsonb
 			END
 		ORDER BY
+			-- Jobs in a higher priority class always go first.
+			nested.priority,
+			-- Within a class, prefer users with the fewest jobs already
+			-- running so that one user can't monopolize the provisioners.
+			COALESCE(running_jobs.running, 0),
 			nested.created_at
-		FOR UPDATE
+		FOR UPDATE OF nested
 		SKIP LOCKED
 		LIMIT
 			1
@@ -56,7 +74,7 @@ WHERE
 -- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -65,7 +83,7 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority ASC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
@@ -102,10 +120,11 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING *;
 
 -- name: UpdateProvisionerJobByID :exec
 UPDATE
@@ -145,6 +164,51 @@ WHERE
 	AND started_at IS NOT NULL
 	AND completed_at IS NULL;
 
+-- name: GetTimedOutProvisionerJobs :many
+-- Returns running workspace build jobs that have exceeded their template's
+-- build timeout plus cancel grace period. Templates without a build timeout
+-- are skipped. Durations are stored in nanoseconds.
+SELECT
+	provisioner_jobs.*
+FROM
+	provisioner_jobs
+INNER JOIN
+	workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+INNER JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	provisioner_jobs.started_at IS NOT NULL
+	AND provisioner_jobs.completed_at IS NULL
+	AND templates.build_timeout > 0
+	AND provisioner_jobs.started_at + ((
+		templates.build_timeout + CASE
+			WHEN templates.build_cancel_grace_period > 0 THEN templates.build_cancel_grace_period
+			ELSE @default_cancel_grace_period :: bigint
+		END
+	) / 1000 / 1000 / 1000 || ' seconds') :: interval < @now :: timestamptz;
+
+-- name: InsertProvisionerJobResourceChanges :many
+INSERT INTO provisioner_job_resource_changes (job_id, address, type, name, action)
+SELECT
+    @job_id::uuid AS provisioner_job_id,
+    unnest(@address::text[]),
+    unnest(@type::text[]),
+    unnest(@name::text[]),
+    unnest(@action::resource_change_action[])
+RETURNING *;
+
+-- name: GetProvisionerJobResourceChangesByJobID :many
+SELECT
+	*
+FROM
+	provisioner_job_resource_changes
+WHERE
+	job_id = $1
+ORDER BY
+	address ASC;
+
 -- name: InsertProvisionerJobTimings :many
 INSERT INTO provisioner_job_timings (job_id, started_at, ended_at, stage, source, action, resource)
 SELECT
@@ -156,3 +220,13 @@ SELECT
     unnest(@action::text[]),
     unnest(@resource::text[])
 RETURNING *;
+
+-- name: GetProvisionerJobTimingsByJobID :many
+SELECT
+	*
+FROM
+	provisioner_job_timings
+WHERE
+	job_id = $1
+ORDER BY
+	started_at ASC;
@@ -0,0 +1,80 @@
+-- name: InsertRoleGrantRequest :one
+INSERT INTO
+	role_grant_requests (
+		id,
+		user_id,
+		organization_id,
+		role_name,
+		reason,
+		lifetime_seconds,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- name: GetRoleGrantRequestByID :one
+SELECT
+	*
+FROM
+	role_grant_requests
+WHERE
+	id = $1;
+
+-- name: GetRoleGrantRequestsByUserID :many
+SELECT
+	*
+FROM
+	role_grant_requests
+WHERE
+	user_id = $1
+ORDER BY
+	created_at DESC;
+
+-- name: GetRoleGrantRequests :many
+SELECT
+	*
+FROM
+	role_grant_requests
+WHERE
+	CASE
+		WHEN @organization_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			organization_id = @organization_id
+		ELSE true
+	END
+	AND CASE
+		WHEN @status :: text != '' THEN
+			status = @status :: role_grant_status
+		ELSE true
+	END
+ORDER BY
+	created_at DESC;
+
+-- name: GetExpiredRoleGrantRequests :many
+-- Returns the approved requests whose granted role should be removed.
+SELECT
+	*
+FROM
+	role_grant_requests
+WHERE
+	status = 'approved'
+	AND expires_at <= @now :: timestamptz
+ORDER BY
+	expires_at ASC;
+
+-- name: UpdateRoleGrantRequestByID :one
+-- Moves a request from one status to another. No rows are returned if the
+-- request is no longer in the expected status, for example because it was
+-- reviewed concurrently.
+UPDATE
+	role_grant_requests
+SET
+	status = @status,
+	reviewed_by = @reviewed_by,
+	reviewed_at = @reviewed_at,
+	expires_at = @expires_at,
+	updated_at = @updated_at
+WHERE
+	id = @id
+	AND status = @from_status
+RETURNING *;
@@ -124,7 +124,14 @@ SET
 	display_name = $6,
 	allow_user_cancel_workspace_jobs = $7,
 	group_acl = $8,
-	max_port_sharing_level = $9
+	max_port_sharing_level = $9,
+	allowed_workspace_proxy_ids = $10,
+	allowed_derp_region_ids = $11,
+	max_app_connections_per_user = $12,
+	max_app_bytes_per_second = $13,
+	drift_detection_interval = $14,
+	build_timeout = $15,
+	build_cancel_grace_period = $16
 WHERE
 	id = $1
 ;
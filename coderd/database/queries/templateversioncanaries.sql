@@ -0,0 +1,144 @@
+-- name: InsertTemplateVersionCanary :one
+INSERT INTO
+	template_version_canaries (
+		id,
+		template_id,
+		template_version_id,
+		percent,
+		group_id,
+		min_builds,
+		success_threshold,
+		created_by,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: GetActiveTemplateVersionCanaries :many
+SELECT
+	*
+FROM
+	template_version_canaries
+WHERE
+	status = 'active'
+ORDER BY
+	created_at ASC;
+
+-- name: GetActiveTemplateVersionCanaryByTemplateID :one
+SELECT
+	*
+FROM
+	template_version_canaries
+WHERE
+	template_id = $1
+	AND status = 'active';
+
+-- name: GetLatestTemplateVersionCanaryByTemplateID :one
+SELECT
+	*
+FROM
+	template_version_canaries
+WHERE
+	template_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	1;
+
+-- name: GetTemplateVersionCanaryByID :one
+SELECT
+	*
+FROM
+	template_version_canaries
+WHERE
+	id = $1;
+
+-- name: GetTemplateVersionCanaryByWorkspaceID :one
+-- Returns the active canary of the workspace's template if the workspace is
+-- in its cohort. A workspace is in the cohort if its owner is a member of the
+-- canary's group, or if a hash of its ID falls within the canary's percent.
+SELECT
+	template_version_canaries.*
+FROM
+	template_version_canaries
+INNER JOIN
+	workspaces ON workspaces.template_id = template_version_canaries.template_id
+WHERE
+	workspaces.id = @workspace_id
+	AND template_version_canaries.status = 'active'
+	AND (
+		(
+			template_version_canaries.group_id IS NOT NULL
+			AND EXISTS (
+				SELECT
+					1
+				FROM
+					group_members_expanded
+				WHERE
+					group_members_expanded.group_id = template_version_canaries.group_id
+					AND group_members_expanded.user_id = workspaces.owner_id
+			)
+		)
+		OR ('x' || substr(md5(workspaces.id :: text), 1, 8)) :: bit(32) :: bigint % 100 < template_version_canaries.percent
+	);
+
+-- name: GetTemplateVersionCanaryStats :one
+-- Counts the finished start builds of a template version since a point in
+-- time. A build succeeded if its job succeeded and all of its agents became
+-- ready, and failed if its job failed or any of its agents failed to start.
+-- Builds whose agents are still starting are not counted yet.
+SELECT
+	COUNT(*) FILTER (
+		WHERE
+			provisioner_jobs.job_status = 'succeeded'
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_agents
+				INNER JOIN
+					workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+				WHERE
+					workspace_resources.job_id = provisioner_jobs.id
+					AND workspace_agents.lifecycle_state IN ('created', 'starting', 'start_timeout', 'start_error')
+			)
+	) :: bigint AS succeeded_builds,
+	COUNT(*) FILTER (
+		WHERE
+			provisioner_jobs.job_status = 'failed'
+			OR EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_agents
+				INNER JOIN
+					workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+				WHERE
+					workspace_resources.job_id = provisioner_jobs.id
+					AND workspace_agents.lifecycle_state IN ('start_timeout', 'start_error')
+			)
+	) :: bigint AS failed_builds
+FROM
+	workspace_builds
+INNER JOIN
+	provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+WHERE
+	workspace_builds.template_version_id = @template_version_id
+	AND workspace_builds.transition = 'start'
+	AND workspace_builds.created_at >= @since :: timestamptz;
+
+-- name: UpdateTemplateVersionCanaryStatusByID :one
+-- Ends an active canary. Canaries that already ended are left untouched and
+-- no rows are returned.
+UPDATE
+	template_version_canaries
+SET
+	status = $2,
+	status_reason = $3,
+	updated_at = $4,
+	completed_at = $4
+WHERE
+	id = $1
+	AND status = 'active'
+RETURNING *;
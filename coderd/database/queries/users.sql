@@ -54,7 +54,15 @@ SELECT
 FROM
 	users
 WHERE
-	status = 'active'::user_status AND deleted = false;
+	status = 'active'::user_status AND deleted = false AND is_service_account = false;
+
+-- name: GetActiveServiceAccountCount :one
+SELECT
+	COUNT(*)
+FROM
+	users
+WHERE
+	status = 'active'::user_status AND deleted = false AND is_service_account = true;
 
 -- name: InsertUser :one
 INSERT INTO
@@ -67,10 +75,12 @@ INSERT INTO
 		created_at,
 		updated_at,
 		rbac_roles,
-		login_type
+		login_type,
+		is_service_account,
+		service_account_organization_id
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
 
 -- name: UpdateUserProfile :one
 UPDATE
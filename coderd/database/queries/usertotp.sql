@@ -0,0 +1,73 @@
+-- name: GetUserTOTPByUserID :one
+SELECT
+	*
+FROM
+	user_totp
+WHERE
+	user_id = $1;
+
+-- name: UpsertUserTOTP :one
+-- Starts a new enrollment, replacing any previous secret and recovery codes.
+INSERT INTO
+	user_totp (user_id, secret, secret_key_id, hashed_recovery_codes, created_at)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(user_id)
+DO UPDATE SET
+	secret = $2,
+	secret_key_id = $3,
+	hashed_recovery_codes = $4,
+	created_at = $5,
+	enabled_at = NULL,
+	last_used_counter = 0
+RETURNING *;
+
+-- name: UpdateUserTOTPEnabledAt :exec
+UPDATE
+	user_totp
+SET
+	enabled_at = $2
+WHERE
+	user_id = $1;
+
+-- name: UpdateUserTOTPLastUsedCounter :one
+-- Records the time step of an accepted code. No rows are returned if a code
+-- from the same or a later time step was already accepted, which means the
+-- code is being replayed.
+UPDATE
+	user_totp
+SET
+	last_used_counter = $2
+WHERE
+	user_id = $1
+	AND last_used_counter < $2
+RETURNING *;
+
+-- name: UpdateUserTOTPSecret :exec
+UPDATE
+	user_totp
+SET
+	secret = $2,
+	secret_key_id = $3
+WHERE
+	user_id = $1;
+
+-- name: RemoveUserTOTPRecoveryCode :one
+-- Removes a recovery code once it is used. No rows are returned if the code
+-- is not one of the user's recovery codes, for example because it was already
+-- used.
+UPDATE
+	user_totp
+SET
+	hashed_recovery_codes = array_remove(hashed_recovery_codes, @hashed_recovery_code :: text)
+WHERE
+	user_id = @user_id
+	AND @hashed_recovery_code :: text = ANY(hashed_recovery_codes)
+RETURNING *;
+
+-- name: DeleteUserTOTPByUserID :exec
+DELETE FROM
+	user_totp
+WHERE
+	user_id = $1;
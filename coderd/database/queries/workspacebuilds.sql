@@ -120,10 +120,11 @@ INSERT INTO
 		provisioner_state,
 		deadline,
 		max_deadline,
-		reason
+		reason,
+		provisioner_state_key_id
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
 
 -- name: UpdateWorkspaceBuildCostByID :exec
 UPDATE
@@ -147,9 +148,26 @@ UPDATE
 	workspace_builds
 SET
 	provisioner_state = @provisioner_state::bytea,
+	provisioner_state_key_id = @provisioner_state_key_id,
 	updated_at = @updated_at::timestamptz
 WHERE id = @id::uuid;
 
+-- name: GetWorkspaceBuildProvisionerStates :many
+-- Returns the provisioner state of workspace builds ordered by ID, starting
+-- after the given ID. This is used to process all build states in batches,
+-- e.g. when rotating database encryption keys.
+SELECT
+	id, provisioner_state, provisioner_state_key_id, updated_at
+FROM
+	workspace_builds
+WHERE
+	id > @after_id :: uuid
+	AND octet_length(provisioner_state) > 0
+ORDER BY
+	id ASC
+LIMIT
+	@limit_opt :: int;
+
 -- name: GetActiveWorkspaceBuildsByTemplateID :many
 SELECT wb.*
 FROM (
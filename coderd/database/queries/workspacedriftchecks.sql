@@ -0,0 +1,76 @@
+-- name: GetWorkspaceDriftCheckByWorkspaceID :one
+SELECT
+	*
+FROM
+	workspace_drift_checks
+WHERE
+	workspace_id = $1;
+
+-- name: UpsertWorkspaceDriftCheck :one
+INSERT INTO
+	workspace_drift_checks (
+		workspace_id,
+		job_id,
+		scheduled_at
+	)
+VALUES (
+	$1,
+	$2,
+	$3
+)
+ON CONFLICT (
+	workspace_id
+)
+DO UPDATE SET
+	job_id = $2,
+	scheduled_at = $3
+RETURNING *;
+
+-- name: UpdateWorkspaceDriftCheckResult :exec
+UPDATE
+	workspace_drift_checks
+SET
+	result_job_id = $2,
+	checked_at = $3
+WHERE
+	workspace_id = $1;
+
+-- name: GetWorkspacesDueForDriftCheck :many
+-- Returns running workspaces of templates with drift detection enabled whose
+-- previous drift check has finished and is older than the template interval.
+SELECT
+	workspaces.*
+FROM
+	workspaces
+INNER JOIN
+	templates ON workspaces.template_id = templates.id
+INNER JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+LEFT JOIN
+	workspace_drift_checks ON workspace_drift_checks.workspace_id = workspaces.id
+LEFT JOIN
+	provisioner_jobs AS drift_check_jobs ON workspace_drift_checks.job_id = drift_check_jobs.id
+WHERE
+	workspaces.deleted = false AND
+	workspaces.dormant_at IS NULL AND
+	templates.deleted = false AND
+	templates.drift_detection_interval > 0 AND
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	) AND
+	workspace_builds.transition = 'start'::workspace_transition AND
+	provisioner_jobs.job_status = 'succeeded'::provisioner_job_status AND
+	(
+		workspace_drift_checks.workspace_id IS NULL OR
+		(
+			drift_check_jobs.completed_at IS NOT NULL AND
+			workspace_drift_checks.scheduled_at + (templates.drift_detection_interval / 1000 / 1000 / 1000 || ' seconds')::interval <= @now :: timestamptz
+		)
+	);
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +17,7 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/coderd/rbac"
@@ -265,6 +267,93 @@ func (api *API) putDeploymentHealthSettings(rw http.ResponseWriter, r *http.Requ
 	httpapi.Write(r.Context(), rw, http.StatusOK, settings)
 }
 
+// @Summary Get DERP health history
+// @ID get-derp-health-history
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Debug
+// @Param since query string false "Earliest result to include, in RFC3339 format. Defaults to 24 hours ago." format(date-time)
+// @Param region_id query int false "Only include results for this DERP region"
+// @Success 200 {object} healthsdk.DERPHealthHistory
+// @Router /debug/health/derp/history [get]
+func (api *API) debugDERPHealthHistory(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser()
+	vals := r.URL.Query()
+	since := p.Time3339Nano(vals, dbtime.Now().Add(-24*time.Hour), "since")
+	regionID := p.Int(vals, 0, "region_id")
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query parameters.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	rows, err := api.Database.GetDERPHealthHistory(ctx, database.GetDERPHealthHistoryParams{
+		CreatedAfter: since,
+		RegionID:     int32(regionID),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to fetch DERP health history.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertDERPHealthHistory(rows))
+}
+
+// convertDERPHealthHistory groups results by replica and node. The rows must
+// be sorted by creation time.
+func convertDERPHealthHistory(rows []database.DERPHealthHistory) healthsdk.DERPHealthHistory {
+	type nodeKey struct {
+		replicaID uuid.UUID
+		regionID  int32
+		nodeName  string
+	}
+	history := healthsdk.DERPHealthHistory{Nodes: []healthsdk.DERPNodeHealthHistory{}}
+	nodes := map[nodeKey]int{}
+	for _, row := range rows {
+		key := nodeKey{replicaID: row.ReplicaID, regionID: row.RegionID, nodeName: row.NodeName}
+		idx, ok := nodes[key]
+		if !ok {
+			idx = len(history.Nodes)
+			nodes[key] = idx
+			history.Nodes = append(history.Nodes, healthsdk.DERPNodeHealthHistory{
+				ReplicaID:  row.ReplicaID,
+				RegionID:   int(row.RegionID),
+				RegionCode: row.RegionCode,
+				NodeName:   row.NodeName,
+				Results:    []healthsdk.DERPNodeHealthResult{},
+			})
+		}
+		history.Nodes[idx].Results = append(history.Nodes[idx].Results, healthsdk.DERPNodeHealthResult{
+			CreatedAt:           row.CreatedAt,
+			Healthy:             row.Healthy,
+			CanExchangeMessages: row.CanExchangeMessages,
+			RoundTripPingMs:     int(row.RoundTripPingMs),
+			PacketLoss:          float64(row.PacketLoss),
+			STUNEnabled:         row.StunEnabled,
+			CanSTUN:             row.CanStun,
+			Error:               row.Error,
+		})
+	}
+	slices.SortStableFunc(history.Nodes, func(a, b healthsdk.DERPNodeHealthHistory) int {
+		if a.RegionID != b.RegionID {
+			return a.RegionID - b.RegionID
+		}
+		if a.NodeName != b.NodeName {
+			return strings.Compare(a.NodeName, b.NodeName)
+		}
+		return strings.Compare(a.ReplicaID.String(), b.ReplicaID.String())
+	})
+	return history
+}
+
 func validateHealthSettings(settings healthsdk.HealthSettings) error {
 	for _, dismissed := range settings.DismissedHealthchecks {
 		ok := slices.Contains(healthsdk.HealthSections, dismissed)
@@ -9,12 +9,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"cdr.dev/slog/sloggers/slogtest"
 
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/codersdk/healthsdk"
 	"github.com/coder/coder/v2/testutil"
 )
@@ -361,6 +365,58 @@ func TestHealthSettings(t *testing.T) {
 	})
 }
 
+func TestDERPHealthHistory(t *testing.T) {
+	t.Parallel()
+
+	client, db := coderdtest.NewWithDatabase(t, nil)
+	owner := coderdtest.CreateFirstUser(t, client)
+	healthClient := healthsdk.New(client)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	replicaID := uuid.New()
+	now := dbtime.Now()
+	for _, row := range []database.InsertDERPHealthHistoryParams{
+		// Too old to be included by default.
+		{CreatedAt: now.Add(-48 * time.Hour), RegionID: 1, RegionCode: "coder", NodeName: "1a", Healthy: true},
+		{CreatedAt: now.Add(-2 * time.Minute), RegionID: 1, RegionCode: "coder", NodeName: "1a", Healthy: true, CanExchangeMessages: true, RoundTripPingMs: 10},
+		{CreatedAt: now.Add(-time.Minute), RegionID: 1, RegionCode: "coder", NodeName: "1a", CanExchangeMessages: true, RoundTripPingMs: 200, PacketLoss: 0.5},
+		{CreatedAt: now.Add(-time.Minute), RegionID: 2, RegionCode: "other", NodeName: "2a", Error: "recv derp message: EOF"},
+	} {
+		row.ReplicaID = replicaID
+		//nolint:gocritic // Inserting history requires system privileges.
+		err := db.InsertDERPHealthHistory(dbauthz.AsSystemRestricted(ctx), row)
+		require.NoError(t, err)
+	}
+
+	history, err := healthClient.DERPHealthHistory(ctx, healthsdk.DERPHealthHistoryRequest{})
+	require.NoError(t, err)
+	require.Len(t, history.Nodes, 2)
+	node := history.Nodes[0]
+	assert.Equal(t, replicaID, node.ReplicaID)
+	assert.Equal(t, 1, node.RegionID)
+	assert.Equal(t, "1a", node.NodeName)
+	require.Len(t, node.Results, 2)
+	assert.True(t, node.Results[0].Healthy)
+	assert.Equal(t, 10, node.Results[0].RoundTripPingMs)
+	assert.False(t, node.Results[1].Healthy)
+	assert.Equal(t, 0.5, node.Results[1].PacketLoss)
+	require.Len(t, history.Nodes[1].Results, 1)
+	assert.Equal(t, "recv derp message: EOF", history.Nodes[1].Results[0].Error)
+
+	history, err = healthClient.DERPHealthHistory(ctx, healthsdk.DERPHealthHistoryRequest{
+		Since:    now.Add(-72 * time.Hour),
+		RegionID: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, history.Nodes, 1)
+	require.Len(t, history.Nodes[0].Results, 3)
+
+	// Members cannot access debug endpoints.
+	memberClient, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	_, err = healthsdk.New(memberClient).DERPHealthHistory(ctx, healthsdk.DERPHealthHistoryRequest{})
+	require.Error(t, err)
+}
+
 func TestDebugWebsocket(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,316 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/apikey"
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/identityprovider"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/telemetry"
+	"github.com/coder/coder/v2/coderd/userpassword"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// deviceLoginPath is the page where users approve a device login. It is kept
+// short since users may have to type it in by hand.
+const deviceLoginPath = "/login/device"
+
+// deviceLoginAuditFields are attached to the audit logs of device logins so
+// the approval in the browser can be matched up with the login on the device.
+type deviceLoginAuditFields struct {
+	UserCode string                     `json:"user_code"`
+	Status   codersdk.DeviceLoginStatus `json:"status"`
+}
+
+// Starts a login for a device without a browser, such as the CLI on a remote
+// machine. The user approves the returned user code in a browser while the
+// device polls for a session token.
+//
+// @Summary Start device login
+// @ID start-device-login
+// @Produce json
+// @Tags Authorization
+// @Success 201 {object} codersdk.DeviceLoginResponse
+// @Router /users/login/device [post]
+func (api *API) postDeviceLogin(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	deviceCode, err := identityprovider.GenerateSecret()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate device code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	userCode, err := identityprovider.GenerateUserCode()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate user code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// Device logins share the storage of OAuth2 device codes, but do not
+	// belong to an app.
+	//nolint:gocritic // The code does not belong to any user until it is approved.
+	dbCode, err := api.Database.InsertOAuth2ProviderAppDeviceCode(dbauthz.AsSystemRestricted(ctx), database.InsertOAuth2ProviderAppDeviceCodeParams{
+		ID:               uuid.New(),
+		CreatedAt:        dbtime.Now(),
+		ExpiresAt:        dbtime.Now().Add(identityprovider.DeviceCodeLifetime),
+		DevicePrefix:     []byte(deviceCode.Prefix),
+		HashedDeviceCode: []byte(deviceCode.Hashed),
+		UserCode:         userCode,
+		AppID:            uuid.NullUUID{},
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to insert device login code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	verificationURL := api.AccessURL.ResolveReference(&url.URL{Path: deviceLoginPath})
+	completeURL := *verificationURL
+	completeURL.RawQuery = url.Values{"user_code": {identityprovider.FormatUserCode(userCode)}}.Encode()
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.DeviceLoginResponse{
+		DeviceCode:              deviceCode.Formatted,
+		UserCode:                identityprovider.FormatUserCode(userCode),
+		VerificationURL:         verificationURL.String(),
+		VerificationURLComplete: completeURL.String(),
+		ExpiresAt:               dbCode.ExpiresAt,
+		Interval:                identityprovider.DevicePollInterval,
+	})
+}
+
+// Polled by the device until the login is approved or denied. Once approved, a
+// session token is returned and the device code can no longer be used.
+//
+// @Summary Get device login token
+// @ID get-device-login-token
+// @Accept json
+// @Produce json
+// @Tags Authorization
+// @Param request body codersdk.DeviceLoginTokenRequest true "Device login token request"
+// @Success 200 {object} codersdk.DeviceLoginTokenResponse
+// @Router /users/login/device/token [post]
+func (api *API) postDeviceLoginToken(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		logger = api.Logger.Named(userAuthLoggerName)
+	)
+
+	var req codersdk.DeviceLoginTokenRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	invalidCode := func() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid device code.",
+		})
+	}
+	code, err := identityprovider.ParseSecret(req.DeviceCode)
+	if err != nil {
+		invalidCode()
+		return
+	}
+	//nolint:gocritic // There is no user until the code is approved.
+	dbCode, err := api.Database.GetOAuth2ProviderAppDeviceCodeByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(code.Prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		invalidCode()
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching device login code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	equal, err := userpassword.Compare(string(dbCode.HashedDeviceCode), code.Secret)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error comparing device code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Codes of OAuth2 apps can only be exchanged at the OAuth2 token endpoint.
+	if !equal || dbCode.AppID.Valid {
+		invalidCode()
+		return
+	}
+
+	if dbCode.ExpiresAt.Before(dbtime.Now()) {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.DeviceLoginTokenResponse{
+			Status: codersdk.DeviceLoginStatusExpired,
+		})
+		return
+	}
+	if dbCode.Status != database.OAuth2ProviderDeviceCodeStatusApproved {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.DeviceLoginTokenResponse{
+			Status: codersdk.DeviceLoginStatus(dbCode.Status),
+		})
+		return
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+		Audit:   *api.Auditor.Load(),
+		Log:     api.Logger,
+		Request: r,
+		Action:  database.AuditActionLogin,
+		AdditionalFields: deviceLoginAuditFields{
+			UserCode: identityprovider.FormatUserCode(dbCode.UserCode),
+			Status:   codersdk.DeviceLoginStatusApproved,
+		},
+	})
+	aReq.Old = database.APIKey{}
+	aReq.UserID = dbCode.UserID.UUID
+	defer commitAudit()
+
+	//nolint:gocritic // The device does not have a session yet.
+	user, err := api.Database.GetUserByID(dbauthz.AsSystemRestricted(ctx), dbCode.UserID.UUID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	subject, userStatus, err := httpmw.UserRBACSubject(ctx, api.Database, user.ID, rbac.ScopeAll)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user roles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// The user may have been suspended since approving the login.
+	if userStatus != database.UserStatusActive {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: fmt.Sprintf("Your account is %s. Contact an admin to reactivate your account.", userStatus),
+		})
+		return
+	}
+
+	// The session has the same login type as the user, so a device login
+	// cannot be used to get around the way the user is meant to sign in.
+	key, sessionToken, err := apikey.Generate(apikey.CreateParams{
+		UserID:          user.ID,
+		LoginType:       user.LoginType,
+		RemoteAddr:      r.RemoteAddr,
+		UserAgent:       r.UserAgent(),
+		DefaultLifetime: api.DeploymentValues.Sessions.DefaultDuration.Value(),
+	})
+	if err != nil {
+		logger.Error(ctx, "unable to generate API key", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The code is consumed in the same transaction that creates the session,
+	// so it can only be exchanged once and is not lost if that fails.
+	var newKey database.APIKey
+	err = api.Database.InTx(func(tx database.Store) error {
+		//nolint:gocritic // Exchanging the code as the user that approved it.
+		ctx := dbauthz.As(ctx, subject)
+		err := tx.DeleteOAuth2ProviderAppDeviceCodeByID(ctx, dbCode.ID)
+		if err != nil {
+			return xerrors.Errorf("delete device code: %w", err)
+		}
+		newKey, err = tx.InsertAPIKey(ctx, key)
+		if err != nil {
+			return xerrors.Errorf("insert API key: %w", err)
+		}
+		return nil
+	}, nil)
+	if httpapi.Is404Error(err) {
+		// Another request exchanged the code first.
+		invalidCode()
+		return
+	}
+	if err != nil {
+		logger.Error(ctx, "unable to create API key", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = newKey
+
+	api.Telemetry.Report(&telemetry.Snapshot{
+		APIKeys: []telemetry.APIKey{telemetry.ConvertAPIKey(newKey)},
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.DeviceLoginTokenResponse{
+		Status:       codersdk.DeviceLoginStatusApproved,
+		SessionToken: sessionToken,
+	})
+}
+
+// getDeviceLoginVerify displays the page where the user enters the code shown
+// on their device and then allows or denies the login.
+func (api *API) getDeviceLoginVerify() http.HandlerFunc {
+	return identityprovider.DeviceVerification(api.Database, api.AccessURL, identityprovider.DeviceVerificationOptions{
+		Path:  deviceLoginPath,
+		Login: true,
+		Check: func(r *http.Request) *identityprovider.DeviceVerificationError {
+			apiKey := httpmw.APIKey(r)
+			user, err := api.Database.GetUserByID(r.Context(), apiKey.UserID)
+			if err != nil {
+				return &identityprovider.DeviceVerificationError{
+					Status:      http.StatusInternalServerError,
+					Title:       "Internal Server Error",
+					Description: err.Error(),
+				}
+			}
+			// Only a browser session created the way the user normally signs
+			// in can approve a device, so for example an API token cannot be
+			// turned into a session on another machine.
+			if user.LoginType == database.LoginTypeNone || apiKey.LoginType != user.LoginType {
+				return &identityprovider.DeviceVerificationError{
+					Status:      http.StatusForbidden,
+					Title:       "Device Login Not Allowed",
+					Description: fmt.Sprintf("Device logins must be approved from a session signed in with your %q login type.", user.LoginType),
+				}
+			}
+			return nil
+		},
+		Audit: func(rw http.ResponseWriter, r *http.Request, code database.OAuth2ProviderAppDeviceCode, status database.OAuth2ProviderDeviceCodeStatus) func() {
+			_, commitAudit := audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+				Audit:   *api.Auditor.Load(),
+				Log:     api.Logger,
+				Request: r,
+				Action:  database.AuditActionLogin,
+				AdditionalFields: deviceLoginAuditFields{
+					UserCode: identityprovider.FormatUserCode(code.UserCode),
+					Status:   codersdk.DeviceLoginStatus(status),
+				},
+			})
+			return commitAudit
+		},
+	})
+}
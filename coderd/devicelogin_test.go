@@ -0,0 +1,183 @@
+package coderd_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestDeviceLogin(t *testing.T) {
+	t.Parallel()
+
+	auditor := audit.NewMock()
+	ownerClient := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+
+	// verify visits the verification page the way a browser would after the
+	// user pressed one of the buttons.
+	verify := func(ctx context.Context, client *codersdk.Client, userCode, action string, referer bool) *http.Response {
+		q := url.Values{"user_code": {userCode}, "action": {action}}
+		res, err := client.Request(ctx, http.MethodGet, "/login/device?"+q.Encode(), nil, func(req *http.Request) {
+			if referer {
+				req.Header.Set("Referer", req.URL.String())
+			}
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		return res
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		anonClient := codersdk.New(ownerClient.URL)
+
+		dl, err := anonClient.StartDeviceLogin(ctx)
+		require.NoError(t, err)
+		require.Regexp(t, `^[A-Z]{4}-[A-Z]{4}$`, dl.UserCode)
+		require.Contains(t, dl.VerificationURLComplete, dl.VerificationURL)
+
+		token, err := anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DeviceLoginStatusPending, token.Status)
+		require.Empty(t, token.SessionToken)
+
+		res := verify(ctx, userClient, dl.UserCode, "allow", true)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		token, err = anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DeviceLoginStatusApproved, token.Status)
+		require.NotEmpty(t, token.SessionToken)
+
+		// The session is a regular session with the user's login type.
+		newClient := codersdk.New(ownerClient.URL)
+		newClient.SetSessionToken(token.SessionToken)
+		gotUser, err := newClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, gotUser.ID)
+		key, err := newClient.APIKeyByID(ctx, codersdk.Me, token.SessionToken[:10])
+		require.NoError(t, err)
+		require.Equal(t, codersdk.LoginTypePassword, key.LoginType)
+
+		// Both the approval and the new session are audited.
+		var logins []database.AuditLog
+		for _, log := range auditor.AuditLogs() {
+			if log.Action == database.AuditActionLogin && log.UserID == user.ID {
+				var fields struct {
+					UserCode string `json:"user_code"`
+				}
+				require.NoError(t, json.Unmarshal(log.AdditionalFields, &fields))
+				if fields.UserCode == dl.UserCode {
+					logins = append(logins, log)
+				}
+			}
+		}
+		require.Len(t, logins, 2)
+
+		// The code can only be exchanged once.
+		_, err = anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		anonClient := codersdk.New(ownerClient.URL)
+
+		dl, err := anonClient.StartDeviceLogin(ctx)
+		require.NoError(t, err)
+
+		res := verify(ctx, userClient, dl.UserCode, "deny", true)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		token, err := anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DeviceLoginStatusDenied, token.Status)
+		require.Empty(t, token.SessionToken)
+
+		// Once acted on, the code cannot be approved.
+		res = verify(ctx, userClient, dl.UserCode, "allow", true)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("MissingReferer", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		anonClient := codersdk.New(ownerClient.URL)
+
+		dl, err := anonClient.StartDeviceLogin(ctx)
+		require.NoError(t, err)
+
+		res := verify(ctx, userClient, dl.UserCode, "allow", false)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		token, err := anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DeviceLoginStatusPending, token.Status)
+	})
+
+	t.Run("TokenCannotApprove", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		anonClient := codersdk.New(ownerClient.URL)
+
+		// An API token has a different login type than the user, so it
+		// cannot be used to approve a device.
+		apiToken, err := userClient.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		tokenClient := codersdk.New(ownerClient.URL)
+		tokenClient.SetSessionToken(apiToken.Key)
+
+		dl, err := anonClient.StartDeviceLogin(ctx)
+		require.NoError(t, err)
+
+		res := verify(ctx, tokenClient, dl.UserCode, "allow", true)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		token, err := anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: dl.DeviceCode})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.DeviceLoginStatusPending, token.Status)
+	})
+
+	t.Run("InvalidDeviceCode", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		anonClient := codersdk.New(ownerClient.URL)
+
+		dl, err := anonClient.StartDeviceLogin(ctx)
+		require.NoError(t, err)
+
+		for _, code := range []string{"", "invalid", dl.DeviceCode + "x"} {
+			_, err = anonClient.DeviceLoginToken(ctx, codersdk.DeviceLoginTokenRequest{DeviceCode: code})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("InvalidUserCode", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+		res := verify(ctx, userClient, "BBBB-BBBB", "allow", true)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
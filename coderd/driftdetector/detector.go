@@ -0,0 +1,253 @@
+package driftdetector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/database/provisionerjobs"
+	"github.com/coder/coder/v2/coderd/database/pubsub"
+	"github.com/coder/coder/v2/coderd/wsbuilder"
+)
+
+// MaxChecksPerRun is the maximum number of drift checks that the detector
+// will schedule in a single run. Workspaces that don't fit are picked up on
+// the next tick.
+const MaxChecksPerRun = 50
+
+// acquireLockError is returned when the detector fails to acquire a lock and
+// skips a workspace.
+type acquireLockError struct{}
+
+// Error implements error.
+func (acquireLockError) Error() string {
+	return "lock is held by another client"
+}
+
+// checkIneligibleError is returned when a workspace is no longer due for a
+// drift check.
+type checkIneligibleError struct {
+	Err error
+}
+
+// Error implements error.
+func (e checkIneligibleError) Error() string {
+	return fmt.Sprintf("workspace is no longer due for a drift check: %s", e.Err)
+}
+
+// Detector periodically schedules refresh-only plans for running workspaces
+// of templates that have drift detection enabled. The provisioner daemons
+// record the outcome of each plan as the drift of the workspace.
+type Detector struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db     database.Store
+	pubsub pubsub.Pubsub
+	log    slog.Logger
+	tick   <-chan time.Time
+	stats  chan<- Stats
+}
+
+// Stats contains statistics about the last run of the detector.
+type Stats struct {
+	// ScheduledJobIDs contains the IDs of the drift check jobs that were
+	// scheduled, keyed by workspace ID.
+	ScheduledJobIDs map[uuid.UUID]uuid.UUID
+	// Error is the fatal error that occurred during the last run of the
+	// detector, if any.
+	Error error
+}
+
+// New returns a new drift detector.
+func New(ctx context.Context, db database.Store, pub pubsub.Pubsub, log slog.Logger, tick <-chan time.Time) *Detector {
+	//nolint:gocritic // Drift detector has a limited set of permissions.
+	ctx, cancel := context.WithCancel(dbauthz.AsDriftDetector(ctx))
+	d := &Detector{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		db:     db,
+		pubsub: pub,
+		log:    log,
+		tick:   tick,
+		stats:  nil,
+	}
+	return d
+}
+
+// WithStatsChannel will cause Detector to push a Stats to ch after
+// every tick. This push is blocking, so if ch is not read, the detector will
+// hang. This should only be used in tests.
+func (d *Detector) WithStatsChannel(ch chan<- Stats) *Detector {
+	d.stats = ch
+	return d
+}
+
+// Start will cause the detector to schedule drift checks on every tick from
+// its channel. It will stop when its context is Done, or when its channel is
+// closed.
+//
+// Start should only be called once.
+func (d *Detector) Start() {
+	go func() {
+		defer close(d.done)
+		defer d.cancel()
+
+		for {
+			select {
+			case <-d.ctx.Done():
+				return
+			case t, ok := <-d.tick:
+				if !ok {
+					return
+				}
+				stats := d.run(t)
+				if stats.Error != nil {
+					d.log.Warn(d.ctx, "error running workspace drift detector once", slog.Error(stats.Error))
+				}
+				if d.stats != nil {
+					select {
+					case <-d.ctx.Done():
+						return
+					case d.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the detector is stopped.
+func (d *Detector) Wait() {
+	<-d.done
+}
+
+// Close will stop the detector.
+func (d *Detector) Close() {
+	d.cancel()
+	<-d.done
+}
+
+func (d *Detector) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(d.ctx, 5*time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		ScheduledJobIDs: map[uuid.UUID]uuid.UUID{},
+		Error:           nil,
+	}
+
+	workspaces, err := d.db.GetWorkspacesDueForDriftCheck(ctx, t)
+	if err != nil {
+		stats.Error = xerrors.Errorf("get workspaces due for drift check: %w", err)
+		return stats
+	}
+	if len(workspaces) > MaxChecksPerRun {
+		workspaces = workspaces[:MaxChecksPerRun]
+	}
+
+	for _, ws := range workspaces {
+		log := d.log.With(slog.F("workspace_id", ws.ID), slog.F("workspace_name", ws.Name))
+
+		job, err := scheduleCheck(ctx, d.db, ws.ID, t)
+		if err != nil {
+			if !(xerrors.As(err, &acquireLockError{}) || xerrors.As(err, &checkIneligibleError{})) {
+				log.Error(ctx, "error scheduling workspace drift check", slog.Error(err))
+			}
+			continue
+		}
+
+		err = provisionerjobs.PostJob(d.pubsub, *job)
+		if err != nil {
+			log.Warn(ctx, "failed to post provisioner job to pubsub", slog.Error(err))
+		}
+		log.Debug(ctx, "scheduled workspace drift check", slog.F("job_id", job.ID))
+		stats.ScheduledJobIDs[ws.ID] = job.ID
+	}
+
+	return stats
+}
+
+func scheduleCheck(ctx context.Context, db database.Store, workspaceID uuid.UUID, t time.Time) (*database.ProvisionerJob, error) {
+	var job *database.ProvisionerJob
+	err := db.InTx(func(db database.Store) error {
+		locked, err := db.TryAcquireLock(ctx, database.GenLockID(fmt.Sprintf("drift-detector:%s", workspaceID)))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			// This error is ignored.
+			return acquireLockError{}
+		}
+
+		// Refetch everything while we hold the lock, another replica may
+		// have scheduled a check in the meantime.
+		ws, err := db.GetWorkspaceByID(ctx, workspaceID)
+		if err != nil {
+			return xerrors.Errorf("get workspace: %w", err)
+		}
+		template, err := db.GetTemplateByID(ctx, ws.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		if template.DriftDetectionInterval <= 0 {
+			return checkIneligibleError{Err: xerrors.New("drift detection is disabled")}
+		}
+		// A build may have started since the workspaces were listed, only
+		// a workspace whose latest start build has finished can be checked.
+		build, err := db.GetLatestWorkspaceBuildByWorkspaceID(ctx, ws.ID)
+		if err != nil {
+			return xerrors.Errorf("get latest workspace build: %w", err)
+		}
+		buildJob, err := db.GetProvisionerJobByID(ctx, build.JobID)
+		if err != nil {
+			return xerrors.Errorf("get latest provisioner job: %w", err)
+		}
+		if build.Transition != database.WorkspaceTransitionStart || buildJob.JobStatus != database.ProvisionerJobStatusSucceeded {
+			return checkIneligibleError{Err: xerrors.Errorf("latest build is a %s that is %s", build.Transition, buildJob.JobStatus)}
+		}
+		check, err := db.GetWorkspaceDriftCheckByWorkspaceID(ctx, ws.ID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return xerrors.Errorf("get workspace drift check: %w", err)
+		}
+		if err == nil {
+			next := check.ScheduledAt.Add(time.Duration(template.DriftDetectionInterval))
+			if next.After(t) {
+				return checkIneligibleError{Err: xerrors.Errorf("next check is due at %s", next)}
+			}
+		}
+
+		builder := wsbuilder.New(ws, database.WorkspaceTransitionStart).
+			RefreshOnly()
+		_, job, err = builder.Build(ctx, db, nil, audit.WorkspaceBuildBaggage{IP: "127.0.0.1"})
+		if err != nil {
+			return xerrors.Errorf("build drift check: %w", err)
+		}
+
+		_, err = db.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+			WorkspaceID: ws.ID,
+			JobID:       job.ID,
+			ScheduledAt: dbtime.Time(t),
+		})
+		if err != nil {
+			return xerrors.Errorf("upsert workspace drift check: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,195 @@
+package driftdetector_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
+	"github.com/coder/coder/v2/coderd/database/dbgen"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/driftdetector"
+	"github.com/coder/coder/v2/coderd/provisionerdserver"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestDetectorNoWorkspaces(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = slogtest.Make(t, nil)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan driftdetector.Stats)
+	)
+
+	detector := driftdetector.New(ctx, db, pubsub, log, tickCh).WithStatsChannel(statsCh)
+	detector.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.ScheduledJobIDs)
+
+	detector.Close()
+	detector.Wait()
+}
+
+func TestDetectorDisabled(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = slogtest.Make(t, nil)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan driftdetector.Stats)
+	)
+
+	// A running workspace of a template without drift detection.
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	_ = dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+	}).Seed(database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStart,
+	}).Do()
+
+	detector := driftdetector.New(ctx, db, pubsub, log, tickCh).WithStatsChannel(statsCh)
+	detector.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.ScheduledJobIDs)
+
+	detector.Close()
+	detector.Wait()
+}
+
+func TestDetectorSchedulesChecks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = slogtest.Make(t, nil)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan driftdetector.Stats)
+		interval   = time.Hour
+	)
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	running := dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+	}).Seed(database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStart,
+	}).Do()
+	enableDriftDetection(ctx, t, db, running.Template, interval)
+
+	// A stopped workspace of the same template is never checked.
+	_ = dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+		TemplateID:     running.Template.ID,
+	}).Seed(database.WorkspaceBuild{
+		Transition: database.WorkspaceTransitionStop,
+	}).Do()
+
+	detector := driftdetector.New(ctx, db, pubsub, log, tickCh).WithStatsChannel(statsCh)
+	detector.Start()
+
+	now := dbtime.Now()
+	tickCh <- now
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.ScheduledJobIDs, 1)
+	jobID, ok := stats.ScheduledJobIDs[running.Workspace.ID]
+	require.True(t, ok, "running workspace should have been checked")
+
+	// The scheduled job is a low-priority refresh-only plan.
+	job, err := db.GetProvisionerJobByID(ctx, jobID)
+	require.NoError(t, err)
+	require.Equal(t, database.ProvisionerJobTypeWorkspaceBuildPlan, job.Type)
+	require.Equal(t, database.ProvisionerJobPriorityAutobuild, job.Priority)
+	require.Equal(t, user.ID, job.InitiatorID)
+	var input provisionerdserver.WorkspacePlanJob
+	require.NoError(t, json.Unmarshal(job.Input, &input))
+	require.True(t, input.RefreshOnly)
+	require.Equal(t, running.Workspace.ID, input.WorkspaceID)
+	require.Equal(t, running.Build.TemplateVersionID, input.TemplateVersionID)
+
+	// Nothing is scheduled while the check is still pending, even if the
+	// interval has passed.
+	tickCh <- now.Add(interval * 2)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.ScheduledJobIDs)
+
+	completeJob(ctx, t, db, jobID)
+
+	// Nor before the interval has passed.
+	tickCh <- now.Add(interval / 2)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.ScheduledJobIDs)
+
+	tickCh <- now.Add(interval)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.ScheduledJobIDs, 1)
+	require.NotEqual(t, jobID, stats.ScheduledJobIDs[running.Workspace.ID])
+
+	detector.Close()
+	detector.Wait()
+}
+
+func enableDriftDetection(ctx context.Context, t *testing.T, db database.Store, template database.Template, interval time.Duration) {
+	t.Helper()
+
+	err := db.UpdateTemplateMetaByID(ctx, database.UpdateTemplateMetaByIDParams{
+		ID:                       template.ID,
+		UpdatedAt:                dbtime.Now(),
+		Name:                     template.Name,
+		DisplayName:              template.DisplayName,
+		Description:              template.Description,
+		Icon:                     template.Icon,
+		GroupACL:                 template.GroupACL,
+		MaxPortSharingLevel:      template.MaxPortSharingLevel,
+		AllowedWorkspaceProxyIDs: template.AllowedWorkspaceProxyIDs,
+		AllowedDERPRegionIDs:     template.AllowedDERPRegionIDs,
+		DriftDetectionInterval:   int64(interval),
+	})
+	require.NoError(t, err)
+}
+
+func completeJob(ctx context.Context, t *testing.T, db database.Store, jobID uuid.UUID) {
+	t.Helper()
+
+	err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:        jobID,
+		UpdatedAt: dbtime.Now(),
+		CompletedAt: sql.NullTime{
+			Time:  dbtime.Now(),
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+}
@@ -269,6 +269,11 @@ func (r *NodeReport) Run(ctx context.Context) {
 		r.Healthy = false
 		r.Severity = health.SeverityError
 	}
+	// Packet loss is only measured once messages can be exchanged. A node
+	// that cannot be reached at all loses every packet.
+	if !r.CanExchangeMessages && !r.Node.STUNOnly {
+		r.PacketLoss = 1
+	}
 
 	if r.UsesWebsocket {
 		r.Warnings = append(r.Warnings, health.Messagef(health.CodeDERPNodeUsesWebsocket, warningNodeUsesWebsocket))
@@ -284,6 +289,9 @@ func (r *NodeReport) doExchangeMessage(ctx context.Context) {
 	var (
 		peerKey  atomic.Pointer[key.NodePublic]
 		lastSent atomic.Pointer[time.Time]
+		// exchanged is closed once the first message has been received, and
+		// signals the sender to start the packet loss probes.
+		exchanged = make(chan struct{})
 	)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -320,6 +328,13 @@ func (r *NodeReport) doExchangeMessage(ctx context.Context) {
 		r.RoundTripPingMs = int(rtt.Milliseconds())
 		r.mu.Unlock()
 
+		close(exchanged)
+		received := r.recvProbes(ctx, receive, pkt.Source)
+
+		r.mu.Lock()
+		r.PacketLoss = 1 - float64(received)/float64(packetLossProbes)
+		r.mu.Unlock()
+
 		cancel()
 	}()
 	go func() {
@@ -349,6 +364,12 @@ func (r *NodeReport) doExchangeMessage(ctx context.Context) {
 			select {
 			case <-ctx.Done():
 				return
+			case <-exchanged:
+				r.sendProbes(ctx, send, sendID, receive.SelfPublicKey())
+				// Wait for the receiver to finish counting, otherwise
+				// closing the client may drop probes in flight.
+				<-ctx.Done()
+				return
 			case <-ticker.C:
 			}
 		}
@@ -357,6 +378,57 @@ func (r *NodeReport) doExchangeMessage(ctx context.Context) {
 	wg.Wait()
 }
 
+const (
+	// packetLossProbes is the number of packets sent to estimate packet loss
+	// once the node is known to be able to exchange messages.
+	packetLossProbes = 10
+	// packetLossTimeout is how long to wait for the probes to arrive before
+	// the missing ones are counted as lost.
+	packetLossTimeout = 2 * time.Second
+	// probeMarker distinguishes probes from the single byte messages used to
+	// measure the round trip time.
+	probeMarker = 0xff
+)
+
+func (r *NodeReport) sendProbes(ctx context.Context, send *derphttp.Client, sendID int, dst key.NodePublic) {
+	for i := 0; i < packetLossProbes; i++ {
+		if ctx.Err() != nil {
+			return
+		}
+		err := send.Send(dst, []byte{probeMarker, byte(i)})
+		if err != nil {
+			r.writeClientErr(sendID, xerrors.Errorf("send derp probe: %w", err))
+			return
+		}
+	}
+}
+
+// recvProbes returns the number of distinct probes received from src before
+// the timeout.
+func (r *NodeReport) recvProbes(ctx context.Context, receive *derphttp.Client, src key.NodePublic) int {
+	ctx, cancel := context.WithTimeout(ctx, packetLossTimeout)
+	defer cancel()
+	// Recv does not take a context, so closing the client is the only way to
+	// unblock it.
+	stop := context.AfterFunc(ctx, func() {
+		_ = receive.Close()
+	})
+	defer stop()
+
+	received := map[byte]struct{}{}
+	for len(received) < packetLossProbes {
+		pkt, err := r.recvData(receive)
+		if err != nil {
+			break
+		}
+		if pkt.Source != src || len(pkt.Data) != 2 || pkt.Data[0] != probeMarker {
+			continue
+		}
+		received[pkt.Data[1]] = struct{}{}
+	}
+	return len(received)
+}
+
 func (r *NodeReport) doSTUNTest(ctx context.Context) {
 	if r.Node.STUNPort == -1 {
 		return
@@ -72,6 +72,7 @@ func TestDERP(t *testing.T) {
 				assert.Empty(t, node.Warnings)
 				assert.NotNil(t, node.Warnings)
 				assert.NotEmpty(t, node.RoundTripPing)
+				assert.Zero(t, node.PacketLoss)
 				assert.Len(t, node.ClientLogs, 2)
 				assert.Len(t, node.ClientLogs[0], 3)
 				assert.Len(t, node.ClientErrs[0], 0)
@@ -0,0 +1,132 @@
+package derphealth
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/codersdk/healthsdk"
+)
+
+type HistoryOptions struct {
+	// ReplicaID is recorded with every result, as each replica may have a
+	// different view of the DERP servers.
+	ReplicaID uuid.UUID
+	// Interval is how often the DERP health report is run and recorded.
+	Interval time.Duration
+	// DERPMap returns the DERP map to check.
+	DERPMap func() *tailcfg.DERPMap
+}
+
+// NewHistoryRecorder periodically runs the DERP health report and stores the
+// per-node results so trends can be inspected later. Old results are removed
+// by dbpurge. It is the caller's responsibility to call Close on the returned
+// instance.
+func NewHistoryRecorder(ctx context.Context, logger slog.Logger, db database.Store, opts HistoryOptions) io.Closer {
+	closed := make(chan struct{})
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	//nolint:gocritic // The system records DERP health without user input.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	// Use time.Nanosecond to force an initial tick. It will be reset to the
+	// correct duration after executing once.
+	ticker := time.NewTicker(time.Nanosecond)
+	doTick := func() {
+		defer ticker.Reset(opts.Interval)
+
+		var report Report
+		report.Run(ctx, &ReportOptions{DERPMap: opts.DERPMap()})
+		if ctx.Err() != nil {
+			return
+		}
+		if err := recordHistory(ctx, db, opts.ReplicaID, time.Now(), &report); err != nil {
+			logger.Error(ctx, "failed to record derp health history", slog.Error(err))
+			return
+		}
+		logger.Debug(ctx, "recorded derp health history", slog.F("regions", len(report.Regions)))
+	}
+
+	go func() {
+		defer close(closed)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ticker.Stop()
+				doTick()
+			}
+		}
+	}()
+	return &historyRecorder{
+		cancel: cancelFunc,
+		closed: closed,
+	}
+}
+
+type historyRecorder struct {
+	cancel context.CancelFunc
+	closed chan struct{}
+}
+
+func (h *historyRecorder) Close() error {
+	h.cancel()
+	<-h.closed
+	return nil
+}
+
+func recordHistory(ctx context.Context, db database.Store, replicaID uuid.UUID, now time.Time, report *Report) error {
+	return db.InTx(func(tx database.Store) error {
+		for _, region := range report.Regions {
+			for _, node := range region.NodeReports {
+				if node == nil || node.Node == nil {
+					continue
+				}
+				err := tx.InsertDERPHealthHistory(ctx, database.InsertDERPHealthHistoryParams{
+					CreatedAt:           now,
+					ReplicaID:           replicaID,
+					RegionID:            int32(region.Region.RegionID),
+					RegionCode:          region.Region.RegionCode,
+					NodeName:            node.Node.Name,
+					Healthy:             node.Healthy,
+					CanExchangeMessages: node.CanExchangeMessages,
+					RoundTripPingMs:     int32(node.RoundTripPingMs),
+					PacketLoss:          float32(node.PacketLoss),
+					StunEnabled:         node.STUN.Enabled,
+					CanStun:             node.STUN.CanSTUN,
+					Error:               nodeError(node),
+				})
+				if err != nil {
+					return xerrors.Errorf("insert derp health history for node %q: %w", node.Node.Name, err)
+				}
+			}
+		}
+		return nil
+	}, nil)
+}
+
+// nodeError summarizes why a node was unhealthy, if it was.
+func nodeError(node *healthsdk.DERPNodeReport) string {
+	var errs []string
+	if node.Error != nil {
+		errs = append(errs, *node.Error)
+	}
+	if node.STUN.Error != nil {
+		errs = append(errs, *node.STUN.Error)
+	}
+	for _, clientErrs := range node.ClientErrs {
+		errs = append(errs, clientErrs...)
+	}
+	return strings.Join(errs, "; ")
+}
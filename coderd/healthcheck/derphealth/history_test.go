@@ -0,0 +1,131 @@
+package derphealth_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/derp"
+	"tailscale.com/derp/derphttp"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbmem"
+	"github.com/coder/coder/v2/coderd/healthcheck/derphealth"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestHistoryRecorder(t *testing.T) {
+	t.Parallel()
+
+	derpSrv := derp.NewServer(key.NewNode(), func(format string, args ...any) { t.Logf(format, args...) })
+	defer derpSrv.Close()
+	srv := httptest.NewServer(derphttp.Handler(derpSrv))
+	defer srv.Close()
+	derpURL, _ := url.Parse(srv.URL)
+
+	var (
+		db        = dbmem.New()
+		replicaID = uuid.New()
+		derpMap   = &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+			999: {
+				EmbeddedRelay: true,
+				RegionID:      999,
+				RegionCode:    "coder",
+				Nodes: []*tailcfg.DERPNode{{
+					Name:             "999a",
+					RegionID:         999,
+					HostName:         derpURL.Host,
+					IPv4:             derpURL.Host,
+					STUNPort:         -1,
+					InsecureForTests: true,
+					ForceHTTP:        true,
+				}},
+			},
+		}}
+	)
+
+	recorder := derphealth.NewHistoryRecorder(context.Background(), slogtest.Make(t, nil), db, derphealth.HistoryOptions{
+		ReplicaID: replicaID,
+		Interval:  time.Hour,
+		DERPMap:   func() *tailcfg.DERPMap { return derpMap },
+	})
+	defer recorder.Close()
+
+	var rows []database.DERPHealthHistory
+	require.Eventually(t, func() bool {
+		var err error
+		rows, err = db.GetDERPHealthHistory(context.Background(), database.GetDERPHealthHistoryParams{})
+		return err == nil && len(rows) > 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	require.Len(t, rows, 1)
+	row := rows[0]
+	assert.Equal(t, replicaID, row.ReplicaID)
+	assert.EqualValues(t, 999, row.RegionID)
+	assert.Equal(t, "coder", row.RegionCode)
+	assert.Equal(t, "999a", row.NodeName)
+	assert.True(t, row.Healthy)
+	assert.True(t, row.CanExchangeMessages)
+	assert.Zero(t, row.PacketLoss)
+	assert.False(t, row.StunEnabled)
+	assert.Empty(t, row.Error)
+}
+
+func TestHistoryRecorder_Unreachable(t *testing.T) {
+	t.Parallel()
+
+	// Nothing is listening on the address of a closed server.
+	srv := httptest.NewServer(nil)
+	srv.Close()
+	derpURL, _ := url.Parse(srv.URL)
+
+	var (
+		db      = dbmem.New()
+		derpMap = &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+			999: {
+				RegionID:   999,
+				RegionCode: "coder",
+				Nodes: []*tailcfg.DERPNode{{
+					Name:             "999a",
+					RegionID:         999,
+					HostName:         derpURL.Host,
+					IPv4:             derpURL.Host,
+					STUNPort:         -1,
+					InsecureForTests: true,
+					ForceHTTP:        true,
+				}},
+			},
+		}}
+	)
+
+	recorder := derphealth.NewHistoryRecorder(context.Background(), slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), db, derphealth.HistoryOptions{
+		ReplicaID: uuid.New(),
+		Interval:  time.Hour,
+		DERPMap:   func() *tailcfg.DERPMap { return derpMap },
+	})
+	defer recorder.Close()
+
+	var rows []database.DERPHealthHistory
+	require.Eventually(t, func() bool {
+		var err error
+		rows, err = db.GetDERPHealthHistory(context.Background(), database.GetDERPHealthHistoryParams{})
+		return err == nil && len(rows) > 0
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	require.Len(t, rows, 1)
+	row := rows[0]
+	assert.False(t, row.Healthy)
+	assert.False(t, row.CanExchangeMessages)
+	// A node that cannot be reached loses every packet.
+	assert.EqualValues(t, 1, row.PacketLoss)
+	assert.NotEmpty(t, row.Error)
+}
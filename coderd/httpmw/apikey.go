@@ -322,7 +322,8 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 		})
 	}
 
-	// Only update LastUsed once an hour to prevent database spam.
+	// Only update LastUsed, the IP address and the user agent once an hour to
+	// prevent database spam.
 	if now.Sub(key.LastUsed) > time.Hour {
 		key.LastUsed = now
 		remoteIP := net.ParseIP(r.RemoteAddr)
@@ -337,6 +338,7 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 			},
 			Valid: true,
 		}
+		key.UserAgent = r.UserAgent()
 		changed = true
 	}
 	// Only update the ExpiresAt once an hour to prevent database spam.
@@ -355,6 +357,7 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 			LastUsed:  key.LastUsed,
 			ExpiresAt: key.ExpiresAt,
 			IPAddress: key.IPAddress,
+			UserAgent: key.UserAgent,
 		})
 		if err != nil {
 			return write(http.StatusInternalServerError, codersdk.Response{
@@ -406,7 +409,7 @@ func ExtractAPIKey(rw http.ResponseWriter, r *http.Request, cfg ExtractAPIKeyCon
 	// If the key is valid, we also fetch the user roles and status.
 	// The roles are used for RBAC authorize checks, and the status
 	// is to block 'suspended' users from accessing the platform.
-	actor, userStatus, err := UserRBACSubject(ctx, cfg.DB, key.UserID, rbac.ScopeName(key.Scope))
+	actor, userStatus, err := UserRBACSubject(ctx, cfg.DB, key.UserID, key.ScopeRBAC())
 	if err != nil {
 		return write(http.StatusUnauthorized, codersdk.Response{
 			Message: internalErrorMessage,
@@ -100,6 +100,9 @@ type OrganizationMember struct {
 	database.OrganizationMember
 	Username  string
 	AvatarURL string
+	// ServiceAccountOrganizationID is set if the member is a service account
+	// and holds the organization that owns it.
+	ServiceAccountOrganizationID uuid.NullUUID
 }
 
 // ExtractOrganizationMemberParam grabs a user membership from the "organization" and "user" URL parameter.
@@ -149,6 +152,10 @@ func ExtractOrganizationMemberParam(db database.Store) func(http.Handler) http.H
 				// the Avatar URL and this allows the FE to avoid an extra request.
 				Username:  user.Username,
 				AvatarURL: user.AvatarURL,
+				// Handlers need to know whether the member is a service
+				// account, as those cannot leave the organization that owns
+				// them.
+				ServiceAccountOrganizationID: user.ServiceAccountOrganizationID,
 			})
 			next.ServeHTTP(rw, r.WithContext(ctx))
 		})
@@ -0,0 +1,49 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+type roleGrantRequestParamContextKey struct{}
+
+// RoleGrantRequestParam returns the request from the ExtractRoleGrantRequestParam handler.
+func RoleGrantRequestParam(r *http.Request) database.RoleGrantRequest {
+	req, ok := r.Context().Value(roleGrantRequestParamContextKey{}).(database.RoleGrantRequest)
+	if !ok {
+		panic("developer error: role grant request middleware not used")
+	}
+	return req
+}
+
+// ExtractRoleGrantRequestParam grabs a role grant request from the "rolegrant" URL parameter.
+func ExtractRoleGrantRequestParam(db database.Store) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			id, parsed := ParseUUIDParam(rw, r, "rolegrant")
+			if !parsed {
+				return
+			}
+			req, err := db.GetRoleGrantRequestByID(ctx, id)
+			if httpapi.Is404Error(err) {
+				httpapi.ResourceNotFound(rw)
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching role grant request.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+
+			ctx = context.WithValue(ctx, roleGrantRequestParamContextKey{}, req)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+		})
+	}
+}
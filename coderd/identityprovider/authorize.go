@@ -18,25 +18,39 @@ import (
 )
 
 type authorizeParams struct {
-	clientID     string
-	redirectURL  *url.URL
-	responseType codersdk.OAuth2ProviderResponseType
-	scope        []string
-	state        string
+	clientID            string
+	codeChallenge       string
+	codeChallengeMethod codersdk.OAuth2ProviderCodeChallengeMethod
+	redirectURL         *url.URL
+	responseType        codersdk.OAuth2ProviderResponseType
+	scope               []string
+	state               string
 }
 
-func extractAuthorizeParams(r *http.Request, callbackURL *url.URL) (authorizeParams, []codersdk.ValidationError, error) {
+func extractAuthorizeParams(r *http.Request, app database.OAuth2ProviderApp, callbackURL *url.URL) (authorizeParams, []codersdk.ValidationError, error) {
 	p := httpapi.NewQueryParamParser()
 	vals := r.URL.Query()
 
 	p.RequiredNotEmpty("state", "response_type", "client_id")
+	// Public apps cannot authenticate when exchanging the code, so PKCE is the
+	// only thing tying the code to the app that requested it.
+	if app.Public {
+		p.RequiredNotEmpty("code_challenge")
+	}
+	// RFC 7636 defaults to "plain" but we do not support it, so the method must
+	// always be given alongside a challenge.
+	if app.Public || vals.Get("code_challenge") != "" {
+		p.RequiredNotEmpty("code_challenge_method")
+	}
 
 	params := authorizeParams{
-		clientID:     p.String(vals, "", "client_id"),
-		redirectURL:  p.RedirectURL(vals, callbackURL, "redirect_uri"),
-		responseType: httpapi.ParseCustom(p, vals, "", "response_type", httpapi.ParseEnum[codersdk.OAuth2ProviderResponseType]),
-		scope:        p.Strings(vals, []string{}, "scope"),
-		state:        p.String(vals, "", "state"),
+		clientID:            p.String(vals, "", "client_id"),
+		codeChallenge:       p.String(vals, "", "code_challenge"),
+		codeChallengeMethod: httpapi.ParseCustom(p, vals, "", "code_challenge_method", httpapi.ParseEnum[codersdk.OAuth2ProviderCodeChallengeMethod]),
+		redirectURL:         p.RedirectURL(vals, callbackURL, "redirect_uri"),
+		responseType:        httpapi.ParseCustom(p, vals, "", "response_type", httpapi.ParseEnum[codersdk.OAuth2ProviderResponseType]),
+		scope:               p.Strings(vals, []string{}, "scope"),
+		state:               p.String(vals, "", "state"),
 	}
 
 	// We add "redirected" when coming from the authorize page.
@@ -68,7 +82,7 @@ func Authorize(db database.Store, accessURL *url.URL) http.HandlerFunc {
 			return
 		}
 
-		params, validationErrs, err := extractAuthorizeParams(r, callbackURL)
+		params, validationErrs, err := extractAuthorizeParams(r, app, callbackURL)
 		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message:     "Invalid query params.",
@@ -107,11 +121,13 @@ func Authorize(db database.Store, accessURL *url.URL) http.HandlerFunc {
 				// is received.  If the application does wait before exchanging the
 				// token (for example suppose they ask the user to confirm and the user
 				// has left) then they can just retry immediately and get a new code.
-				ExpiresAt:    dbtime.Now().Add(time.Duration(10) * time.Minute),
-				SecretPrefix: []byte(code.Prefix),
-				HashedSecret: []byte(code.Hashed),
-				AppID:        app.ID,
-				UserID:       apiKey.UserID,
+				ExpiresAt:           dbtime.Now().Add(time.Duration(10) * time.Minute),
+				SecretPrefix:        []byte(code.Prefix),
+				HashedSecret:        []byte(code.Hashed),
+				AppID:               app.ID,
+				UserID:              apiKey.UserID,
+				CodeChallenge:       params.codeChallenge,
+				CodeChallengeMethod: string(params.codeChallengeMethod),
 			})
 			if err != nil {
 				return xerrors.Errorf("insert oauth2 authorization code: %w", err)
@@ -0,0 +1,355 @@
+package identityprovider
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/cryptorand"
+	"github.com/coder/coder/v2/site"
+)
+
+const (
+	// userCodeCharset leaves out vowels so codes do not spell words, and
+	// leaves out characters that are easily confused with one another, as
+	// recommended by RFC 8628 section 6.1.
+	userCodeCharset = "BCDFGHJKLMNPQRSTVWXZ"
+	// userCodeLength gives 20^8 possible codes, which along with the expiry
+	// makes guessing a code that is waiting for approval impractical.
+	userCodeLength = 8
+	// DeviceCodeLifetime matches the lifetime of authorization codes.
+	DeviceCodeLifetime = 10 * time.Minute
+	// DevicePollInterval is the number of seconds clients should wait between
+	// polls of the token endpoint.
+	DevicePollInterval = 5
+
+	// DeviceVerificationPath is where users approve the device codes of apps.
+	DeviceVerificationPath = "/oauth2/device/verify"
+)
+
+// GenerateUserCode generates a short code for the user to enter on the
+// verification page of a device flow.
+func GenerateUserCode() (string, error) {
+	return cryptorand.StringCharset(userCodeCharset, userCodeLength)
+}
+
+// FormatUserCode splits a user code in half with a dash to make it easier for
+// the user to read.
+func FormatUserCode(code string) string {
+	if len(code) != userCodeLength {
+		return code
+	}
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// NormalizeUserCode undoes FormatUserCode and any changes the user may have
+// made while typing the code in.
+func NormalizeUserCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// DeviceAuthorization issues a device code and user code to an app as
+// described in RFC 8628. The app shows the user code to the user, who enters
+// it on the verification page, while the app polls the token endpoint with the
+// device code.
+func DeviceAuthorization(db database.Store, accessURL *url.URL) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		app := httpmw.OAuth2ProviderApp(r)
+
+		err := r.ParseForm()
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Failed to parse form.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		p := httpapi.NewQueryParamParser()
+		vals := r.Form
+		if app.Public {
+			p.RequiredNotEmpty("client_id")
+		} else {
+			p.RequiredNotEmpty("client_id", "client_secret")
+		}
+		_ = p.String(vals, "", "client_id")
+		clientSecret := p.String(vals, "", "client_secret")
+		// TODO: Ignoring scope for now, same as the authorize endpoint.
+		_ = p.String(vals, "", "scope")
+		p.ErrorExcessParams(vals)
+		if len(p.Errors) > 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message:     "Invalid query params.",
+				Detail:      xerrors.Errorf("invalid query params: %w", p.Errors).Error(),
+				Validations: p.Errors,
+			})
+			return
+		}
+
+		if !app.Public || clientSecret != "" {
+			_, err = validateAppSecret(ctx, db, app, clientSecret)
+			if errors.Is(err, errBadSecret) {
+				httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+					Message: err.Error(),
+				})
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Failed to validate client secret.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+		}
+
+		deviceCode, err := GenerateSecret()
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Failed to generate device code.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		userCode, err := GenerateUserCode()
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Failed to generate user code.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		//nolint:gocritic // The code does not belong to any user until it is approved.
+		dbCode, err := db.InsertOAuth2ProviderAppDeviceCode(dbauthz.AsSystemRestricted(ctx), database.InsertOAuth2ProviderAppDeviceCodeParams{
+			ID:               uuid.New(),
+			CreatedAt:        dbtime.Now(),
+			ExpiresAt:        dbtime.Now().Add(DeviceCodeLifetime),
+			DevicePrefix:     []byte(deviceCode.Prefix),
+			HashedDeviceCode: []byte(deviceCode.Hashed),
+			UserCode:         userCode,
+			AppID:            uuid.NullUUID{UUID: app.ID, Valid: true},
+		})
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Failed to insert device code.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		verificationURL := accessURL.ResolveReference(&url.URL{Path: DeviceVerificationPath})
+		completeURL := *verificationURL
+		completeURL.RawQuery = url.Values{"user_code": {FormatUserCode(userCode)}}.Encode()
+
+		httpapi.Write(ctx, rw, http.StatusOK, oauth2.DeviceAuthResponse{
+			DeviceCode:              deviceCode.Formatted,
+			UserCode:                FormatUserCode(userCode),
+			VerificationURI:         verificationURL.String(),
+			VerificationURIComplete: completeURL.String(),
+			Expiry:                  dbCode.ExpiresAt,
+			Interval:                DevicePollInterval,
+		})
+	}
+}
+
+// DeviceVerificationError is shown to the user instead of the verification
+// page.
+type DeviceVerificationError struct {
+	Status      int
+	Title       string
+	Description string
+}
+
+// DeviceVerificationOptions configures a device verification page.
+type DeviceVerificationOptions struct {
+	// Path is where the page is served.
+	Path string
+	// Login is set for the page that approves device logins of the CLI. Those
+	// codes do not belong to an app, and are only accepted on this page.
+	Login bool
+	// Check is called before the page is shown, and returns an error if the
+	// user cannot approve devices on this page. Optional.
+	Check func(r *http.Request) *DeviceVerificationError
+	// Audit is called before the choice of the user is stored, and returns a
+	// function that commits the audit log. Optional.
+	Audit func(rw http.ResponseWriter, r *http.Request, code database.OAuth2ProviderAppDeviceCode, status database.OAuth2ProviderDeviceCodeStatus) func()
+}
+
+// DeviceVerification displays an HTML page where the user enters the code shown
+// on their device and then allows or denies it. Like the authorize endpoint,
+// the referer header is used to detect that the user actually pressed a button
+// on this page.
+func DeviceVerification(db database.Store, accessURL *url.URL, opts DeviceVerificationOptions) http.HandlerFunc {
+	return func(rw http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		apiKey := httpmw.APIKey(r)
+		ua := httpmw.UserAuthorization(r)
+
+		renderError := func(status int, title, description string) {
+			site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+				Status:       status,
+				HideStatus:   false,
+				Title:        title,
+				Description:  description,
+				RetryEnabled: false,
+				DashboardURL: accessURL.String(),
+				Warnings:     nil,
+			})
+		}
+		renderInvalidCode := func() {
+			renderError(http.StatusNotFound, "Invalid Code", "The code is invalid or has expired. Restart the login on your device to get a new code.")
+		}
+
+		if opts.Check != nil {
+			if verr := opts.Check(r); verr != nil {
+				renderError(verr.Status, verr.Title, verr.Description)
+				return
+			}
+		}
+
+		userCode := NormalizeUserCode(r.URL.Query().Get("user_code"))
+		if userCode == "" {
+			site.RenderOAuthAllowPage(rw, r, site.RenderOAuthAllowData{
+				Username:     ua.FriendlyName,
+				EnterCodeURI: opts.Path,
+			})
+			return
+		}
+
+		//nolint:gocritic // The code does not belong to any user until it is approved.
+		dbCode, err := db.GetOAuth2ProviderAppDeviceCodeByUserCode(dbauthz.AsSystemRestricted(ctx), userCode)
+		if err == nil && (dbCode.Status != database.OAuth2ProviderDeviceCodeStatusPending || dbCode.ExpiresAt.Before(dbtime.Now())) {
+			err = sql.ErrNoRows
+		}
+		// Codes of apps and device logins are not interchangeable.
+		if err == nil && dbCode.AppID.Valid == opts.Login {
+			err = sql.ErrNoRows
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			renderInvalidCode()
+			return
+		}
+		if err != nil {
+			renderError(http.StatusInternalServerError, "Internal Server Error", err.Error())
+			return
+		}
+
+		action := r.URL.Query().Get("action")
+		if action == "" {
+			actionURL := func(action string) string {
+				return (&url.URL{
+					Path: opts.Path,
+					RawQuery: url.Values{
+						"user_code": {FormatUserCode(userCode)},
+						"action":    {action},
+					}.Encode(),
+				}).String()
+			}
+			data := site.RenderOAuthAllowData{
+				AppName:     "the Coder CLI",
+				CancelURI:   actionURL("deny"),
+				RedirectURI: actionURL("allow"),
+				Username:    ua.FriendlyName,
+				UserCode:    FormatUserCode(userCode),
+			}
+			if dbCode.AppID.Valid {
+				app, err := db.GetOAuth2ProviderAppByID(ctx, dbCode.AppID.UUID)
+				if err != nil {
+					renderError(http.StatusInternalServerError, "Internal Server Error", err.Error())
+					return
+				}
+				data.AppName = app.Name
+				data.AppIcon = app.Icon
+			}
+			site.RenderOAuthAllowPage(rw, r, data)
+			return
+		}
+
+		// Anyone could send the user straight to the allow link, so only trust
+		// the action if it came from a button on this page.
+		origin := r.Header.Get(httpmw.OriginHeader)
+		originU, err := url.Parse(origin)
+		if err != nil {
+			renderError(http.StatusBadRequest, "Invalid Origin", "The origin header is invalid.")
+			return
+		}
+		refererU, err := url.Parse(r.Referer())
+		if err != nil || r.Referer() == "" {
+			renderError(http.StatusBadRequest, "Referer header missing", "We cannot continue authorization because your client has not sent the referer header.")
+			return
+		}
+		cameFromSelf := (origin == "" || originU.Hostname() == accessURL.Hostname()) &&
+			refererU.Hostname() == accessURL.Hostname() &&
+			refererU.Path == opts.Path
+		if !cameFromSelf {
+			renderError(http.StatusBadRequest, "Invalid Referer", "The request to authorize the device did not come from this page.")
+			return
+		}
+
+		var status database.OAuth2ProviderDeviceCodeStatus
+		switch action {
+		case "allow":
+			status = database.OAuth2ProviderDeviceCodeStatusApproved
+		case "deny":
+			status = database.OAuth2ProviderDeviceCodeStatusDenied
+		default:
+			renderError(http.StatusBadRequest, "Invalid Action", "The action must be either \"allow\" or \"deny\".")
+			return
+		}
+
+		if opts.Audit != nil {
+			commitAudit := opts.Audit(rw, r, dbCode, status)
+			defer commitAudit()
+		}
+
+		_, err = db.UpdateOAuth2ProviderAppDeviceCodeStatus(ctx, database.UpdateOAuth2ProviderAppDeviceCodeStatusParams{
+			ID:     dbCode.ID,
+			Status: status,
+			UserID: uuid.NullUUID{UUID: apiKey.UserID, Valid: true},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// Somebody acted on the code between loading the page and now.
+			renderInvalidCode()
+			return
+		}
+		if err != nil {
+			renderError(http.StatusInternalServerError, "Internal Server Error", err.Error())
+			return
+		}
+
+		title, description := "Device Authorized", "You can close this page and return to your device."
+		if status == database.OAuth2ProviderDeviceCodeStatusDenied {
+			title, description = "Device Denied", "The device was not given access to your account. You can close this page."
+		}
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status:       http.StatusOK,
+			HideStatus:   true,
+			Title:        title,
+			Description:  description,
+			RetryEnabled: false,
+			DashboardURL: accessURL.String(),
+			Warnings:     nil,
+		})
+	}
+}
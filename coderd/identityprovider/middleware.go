@@ -110,7 +110,7 @@ func authorizeMW(accessURL *url.URL) func(next http.Handler) http.Handler {
 			// 2. Since validation will run once the user clicks "allow", it is
 			//    better to validate now to avoid wasting the user's time clicking a
 			//    button that will just error anyway.
-			params, validationErrs, err := extractAuthorizeParams(r, callbackURL)
+			params, validationErrs, err := extractAuthorizeParams(r, app, callbackURL)
 			if err != nil {
 				errStr := make([]string, len(validationErrs))
 				for i, err := range validationErrs {
@@ -53,25 +53,27 @@ func GenerateSecret() (OAuth2ProviderAppSecret, error) {
 	}, nil
 }
 
-type parsedSecret struct {
-	prefix string
-	secret string
+// ParsedSecret is a secret generated by GenerateSecret split back into its
+// parts.
+type ParsedSecret struct {
+	Prefix string
+	Secret string
 }
 
-// parseSecret extracts the ID and original secret from a secret.
-func parseSecret(secret string) (parsedSecret, error) {
+// ParseSecret extracts the ID and original secret from a secret.
+func ParseSecret(secret string) (ParsedSecret, error) {
 	parts := strings.Split(secret, "_")
 	if len(parts) != 3 {
-		return parsedSecret{}, xerrors.Errorf("incorrect number of parts: %d", len(parts))
+		return ParsedSecret{}, xerrors.Errorf("incorrect number of parts: %d", len(parts))
 	}
 	if parts[0] != "coder" {
-		return parsedSecret{}, xerrors.Errorf("incorrect scheme: %s", parts[0])
+		return ParsedSecret{}, xerrors.Errorf("incorrect scheme: %s", parts[0])
 	}
 	if len(parts[1]) == 0 {
-		return parsedSecret{}, xerrors.Errorf("prefix is invalid")
+		return ParsedSecret{}, xerrors.Errorf("prefix is invalid")
 	}
 	if len(parts[2]) == 0 {
-		return parsedSecret{}, xerrors.Errorf("invalid")
+		return ParsedSecret{}, xerrors.Errorf("invalid")
 	}
-	return parsedSecret{parts[1], parts[2]}, nil
+	return ParsedSecret{parts[1], parts[2]}, nil
 }
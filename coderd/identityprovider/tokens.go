@@ -2,11 +2,15 @@ package identityprovider
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/oauth2"
@@ -30,18 +34,57 @@ var (
 	errBadCode = xerrors.New("Invalid code")
 	// errBadToken means the user provided a bad token.
 	errBadToken = xerrors.New("Invalid token")
+	// errBadVerifier means the user provided a PKCE code verifier that does not
+	// match the challenge, or omitted one when it was required.
+	errBadVerifier = xerrors.New("Invalid code verifier")
+	// errClientCredentialsDisabled means the app has no user configured for the
+	// client credentials grant.
+	errClientCredentialsDisabled = xerrors.New("The client_credentials grant is not enabled for this app")
+)
+
+// deviceCodeError is an error returned while a client polls with a device
+// code. Clients expect these in the format defined by RFC 8628 so they can
+// tell whether to keep polling, so they are not written as codersdk.Response.
+type deviceCodeError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e deviceCodeError) Error() string {
+	return e.Description
+}
+
+var (
+	errAuthorizationPending = deviceCodeError{
+		Code:        "authorization_pending",
+		Description: "The user has not yet approved the device.",
+	}
+	errAccessDenied = deviceCodeError{
+		Code:        "access_denied",
+		Description: "The user denied the device.",
+	}
+	errExpiredToken = deviceCodeError{
+		Code:        "expired_token",
+		Description: "The device code has expired.",
+	}
+	errSlowDown = deviceCodeError{
+		Code:        "slow_down",
+		Description: "The device is polling faster than the interval.",
+	}
 )
 
 type tokenParams struct {
 	clientID     string
 	clientSecret string
 	code         string
+	codeVerifier string
+	deviceCode   string
 	grantType    codersdk.OAuth2ProviderGrantType
 	redirectURL  *url.URL
 	refreshToken string
 }
 
-func extractTokenParams(r *http.Request, callbackURL *url.URL) (tokenParams, []codersdk.ValidationError, error) {
+func extractTokenParams(r *http.Request, app database.OAuth2ProviderApp, callbackURL *url.URL) (tokenParams, []codersdk.ValidationError, error) {
 	p := httpapi.NewQueryParamParser()
 	err := r.ParseForm()
 	if err != nil {
@@ -55,13 +98,28 @@ func extractTokenParams(r *http.Request, callbackURL *url.URL) (tokenParams, []c
 	case codersdk.OAuth2ProviderGrantTypeRefreshToken:
 		p.RequiredNotEmpty("refresh_token")
 	case codersdk.OAuth2ProviderGrantTypeAuthorizationCode:
-		p.RequiredNotEmpty("client_secret", "client_id", "code")
+		// Public apps prove possession of the code with PKCE instead of a secret.
+		if app.Public {
+			p.RequiredNotEmpty("client_id", "code", "code_verifier")
+		} else {
+			p.RequiredNotEmpty("client_secret", "client_id", "code")
+		}
+	case codersdk.OAuth2ProviderGrantTypeClientCredentials:
+		p.RequiredNotEmpty("client_secret", "client_id")
+	case codersdk.OAuth2ProviderGrantTypeDeviceCode:
+		if app.Public {
+			p.RequiredNotEmpty("client_id", "device_code")
+		} else {
+			p.RequiredNotEmpty("client_secret", "client_id", "device_code")
+		}
 	}
 
 	params := tokenParams{
 		clientID:     p.String(vals, "", "client_id"),
 		clientSecret: p.String(vals, "", "client_secret"),
 		code:         p.String(vals, "", "code"),
+		codeVerifier: p.String(vals, "", "code_verifier"),
+		deviceCode:   p.String(vals, "", "device_code"),
 		grantType:    grantType,
 		redirectURL:  p.RedirectURL(vals, callbackURL, "redirect_uri"),
 		refreshToken: p.String(vals, "", "refresh_token"),
@@ -92,7 +150,7 @@ func Tokens(db database.Store, lifetimes codersdk.SessionLifetime) http.HandlerF
 			return
 		}
 
-		params, validationErrs, err := extractTokenParams(r, callbackURL)
+		params, validationErrs, err := extractTokenParams(r, app, callbackURL)
 		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message:     "Invalid query params.",
@@ -103,13 +161,15 @@ func Tokens(db database.Store, lifetimes codersdk.SessionLifetime) http.HandlerF
 		}
 
 		var token oauth2.Token
-		//nolint:gocritic,revive // More cases will be added later.
 		switch params.grantType {
-		// TODO: Client creds, device code.
 		case codersdk.OAuth2ProviderGrantTypeRefreshToken:
 			token, err = refreshTokenGrant(ctx, db, app, lifetimes, params)
 		case codersdk.OAuth2ProviderGrantTypeAuthorizationCode:
 			token, err = authorizationCodeGrant(ctx, db, app, lifetimes, params)
+		case codersdk.OAuth2ProviderGrantTypeClientCredentials:
+			token, err = clientCredentialsGrant(ctx, db, app, lifetimes, params)
+		case codersdk.OAuth2ProviderGrantTypeDeviceCode:
+			token, err = deviceCodeGrant(ctx, db, app, lifetimes, params)
 		default:
 			// Grant types are validated by the parser, so getting through here means
 			// the developer added a type but forgot to add a case here.
@@ -120,12 +180,23 @@ func Tokens(db database.Store, lifetimes codersdk.SessionLifetime) http.HandlerF
 			return
 		}
 
-		if errors.Is(err, errBadCode) || errors.Is(err, errBadSecret) {
+		var deviceErr deviceCodeError
+		if errors.As(err, &deviceErr) {
+			httpapi.Write(r.Context(), rw, http.StatusBadRequest, deviceErr)
+			return
+		}
+		if errors.Is(err, errBadCode) || errors.Is(err, errBadSecret) || errors.Is(err, errBadVerifier) {
 			httpapi.Write(r.Context(), rw, http.StatusUnauthorized, codersdk.Response{
 				Message: err.Error(),
 			})
 			return
 		}
+		if errors.Is(err, errClientCredentialsDisabled) {
+			httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+				Message: err.Error(),
+			})
+			return
+		}
 		if err != nil {
 			httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
 				Message: "Failed to exchange token",
@@ -141,64 +212,222 @@ func Tokens(db database.Store, lifetimes codersdk.SessionLifetime) http.HandlerF
 }
 
 func authorizationCodeGrant(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, lifetimes codersdk.SessionLifetime, params tokenParams) (oauth2.Token, error) {
-	// Validate the client secret.
-	secret, err := parseSecret(params.clientSecret)
+	// Validate the client secret. Public apps cannot keep a secret, so they are
+	// only checked if they send one anyway.
+	var secretID uuid.NullUUID
+	if !app.Public || params.clientSecret != "" {
+		dbSecret, err := validateAppSecret(ctx, db, app, params.clientSecret)
+		if err != nil {
+			return oauth2.Token{}, err
+		}
+		secretID = uuid.NullUUID{UUID: dbSecret.ID, Valid: true}
+	}
+
+	// Validate the authorization code.
+	code, err := ParseSecret(params.code)
 	if err != nil {
-		return oauth2.Token{}, errBadSecret
+		return oauth2.Token{}, errBadCode
 	}
-	//nolint:gocritic // Users cannot read secrets so we must use the system.
-	dbSecret, err := db.GetOAuth2ProviderAppSecretByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(secret.prefix))
+	//nolint:gocritic // There is no user yet so we must use the system.
+	dbCode, err := db.GetOAuth2ProviderAppCodeByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(code.Prefix))
 	if errors.Is(err, sql.ErrNoRows) {
-		return oauth2.Token{}, errBadSecret
+		return oauth2.Token{}, errBadCode
 	}
 	if err != nil {
 		return oauth2.Token{}, err
 	}
-	equal, err := userpassword.Compare(string(dbSecret.HashedSecret), secret.secret)
+	equal, err := userpassword.Compare(string(dbCode.HashedSecret), code.Secret)
 	if err != nil {
-		return oauth2.Token{}, xerrors.Errorf("unable to compare secret: %w", err)
+		return oauth2.Token{}, xerrors.Errorf("unable to compare code: %w", err)
 	}
-	if !equal {
-		return oauth2.Token{}, errBadSecret
+	if !equal || dbCode.AppID != app.ID {
+		return oauth2.Token{}, errBadCode
 	}
 
-	// Validate the authorization code.
-	code, err := parseSecret(params.code)
+	// Ensure the code has not expired.
+	if dbCode.ExpiresAt.Before(dbtime.Now()) {
+		return oauth2.Token{}, errBadCode
+	}
+
+	// Without a challenge, nothing but the secret stops a public app's
+	// intercepted code from being exchanged.
+	if app.Public && dbCode.CodeChallenge == "" {
+		return oauth2.Token{}, errBadCode
+	}
+	err = verifyCodeChallenge(dbCode, params.codeVerifier)
+	if err != nil {
+		return oauth2.Token{}, err
+	}
+
+	// TODO: We are ignoring scopes for now.
+	tokenName := fmt.Sprintf("%s_%s_oauth_session_token", dbCode.UserID, app.ID)
+	return exchangeForToken(ctx, db, app, lifetimes, dbCode.UserID, tokenName, secretID, func(ctx context.Context, tx database.Store) error {
+		err := tx.DeleteOAuth2ProviderAppCodeByID(ctx, dbCode.ID)
+		if err != nil {
+			return xerrors.Errorf("delete oauth2 app code: %w", err)
+		}
+		return nil
+	})
+}
+
+// verifyCodeChallenge checks the PKCE verifier sent with the token request
+// against the challenge sent with the authorization request, as described in
+// RFC 7636.
+func verifyCodeChallenge(dbCode database.OAuth2ProviderAppCode, verifier string) error {
+	if dbCode.CodeChallenge == "" {
+		// A verifier without a challenge means the request was tampered with
+		// somewhere along the way, so do not ignore it.
+		if verifier != "" {
+			return errBadVerifier
+		}
+		return nil
+	}
+	if verifier == "" {
+		return errBadVerifier
+	}
+	// S256 is the only method accepted by the authorize endpoint.
+	if dbCode.CodeChallengeMethod != string(codersdk.OAuth2ProviderCodeChallengeMethodS256) {
+		return errBadVerifier
+	}
+	hash := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(hash[:])
+	if subtle.ConstantTimeCompare([]byte(challenge), []byte(dbCode.CodeChallenge)) != 1 {
+		return errBadVerifier
+	}
+	return nil
+}
+
+func clientCredentialsGrant(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, lifetimes codersdk.SessionLifetime, params tokenParams) (oauth2.Token, error) {
+	dbSecret, err := validateAppSecret(ctx, db, app, params.clientSecret)
+	if err != nil {
+		return oauth2.Token{}, err
+	}
+	if !app.ClientCredentialsUserID.Valid {
+		return oauth2.Token{}, errClientCredentialsDisabled
+	}
+
+	userID := app.ClientCredentialsUserID.UUID
+	tokenName := fmt.Sprintf("%s_%s_oauth_client_credentials_token", userID, app.ID)
+	token, err := exchangeForToken(ctx, db, app, lifetimes, userID, tokenName, uuid.NullUUID{UUID: dbSecret.ID, Valid: true}, nil)
+	if err != nil {
+		return oauth2.Token{}, err
+	}
+	// The app can always request a new token with its secret, so per RFC 6749
+	// section 4.4.3 it does not get a refresh token. One is still stored so the
+	// token shows up alongside the others issued to the app and can be revoked.
+	token.RefreshToken = ""
+	return token, nil
+}
+
+func deviceCodeGrant(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, lifetimes codersdk.SessionLifetime, params tokenParams) (oauth2.Token, error) {
+	var secretID uuid.NullUUID
+	if !app.Public || params.clientSecret != "" {
+		dbSecret, err := validateAppSecret(ctx, db, app, params.clientSecret)
+		if err != nil {
+			return oauth2.Token{}, err
+		}
+		secretID = uuid.NullUUID{UUID: dbSecret.ID, Valid: true}
+	}
+
+	// Validate the device code.
+	code, err := ParseSecret(params.deviceCode)
 	if err != nil {
 		return oauth2.Token{}, errBadCode
 	}
 	//nolint:gocritic // There is no user yet so we must use the system.
-	dbCode, err := db.GetOAuth2ProviderAppCodeByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(code.prefix))
+	dbCode, err := db.GetOAuth2ProviderAppDeviceCodeByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(code.Prefix))
 	if errors.Is(err, sql.ErrNoRows) {
 		return oauth2.Token{}, errBadCode
 	}
 	if err != nil {
 		return oauth2.Token{}, err
 	}
-	equal, err = userpassword.Compare(string(dbCode.HashedSecret), code.secret)
+	// Check the poll interval before comparing the code, so a device that polls
+	// too often cannot make us hash on every request.
+	now := dbtime.Now()
+	//nolint:gocritic // There is no user yet so we must use the system.
+	_, err = db.UpdateOAuth2ProviderAppDeviceCodeLastPolledAt(dbauthz.AsSystemRestricted(ctx), database.UpdateOAuth2ProviderAppDeviceCodeLastPolledAtParams{
+		ID:           dbCode.ID,
+		LastPolledAt: sql.NullTime{Time: now, Valid: true},
+		PolledBefore: now.Add(-DevicePollInterval * time.Second),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return oauth2.Token{}, errSlowDown
+	}
+	if err != nil {
+		return oauth2.Token{}, xerrors.Errorf("update device code last polled at: %w", err)
+	}
+	equal, err := userpassword.Compare(string(dbCode.HashedDeviceCode), code.Secret)
 	if err != nil {
 		return oauth2.Token{}, xerrors.Errorf("unable to compare code: %w", err)
 	}
-	if !equal {
+	if !equal || !dbCode.AppID.Valid || dbCode.AppID.UUID != app.ID {
 		return oauth2.Token{}, errBadCode
 	}
 
-	// Ensure the code has not expired.
 	if dbCode.ExpiresAt.Before(dbtime.Now()) {
-		return oauth2.Token{}, errBadCode
+		return oauth2.Token{}, errExpiredToken
+	}
+	switch dbCode.Status {
+	case database.OAuth2ProviderDeviceCodeStatusPending:
+		return oauth2.Token{}, errAuthorizationPending
+	case database.OAuth2ProviderDeviceCodeStatusDenied:
+		return oauth2.Token{}, errAccessDenied
+	}
+	if !dbCode.UserID.Valid {
+		return oauth2.Token{}, xerrors.Errorf("approved device code %s has no user", dbCode.ID)
+	}
+
+	userID := dbCode.UserID.UUID
+	tokenName := fmt.Sprintf("%s_%s_oauth_session_token", userID, app.ID)
+	return exchangeForToken(ctx, db, app, lifetimes, userID, tokenName, secretID, func(ctx context.Context, tx database.Store) error {
+		err := tx.DeleteOAuth2ProviderAppDeviceCodeByID(ctx, dbCode.ID)
+		if err != nil {
+			return xerrors.Errorf("delete oauth2 app device code: %w", err)
+		}
+		return nil
+	})
+}
+
+// validateAppSecret checks that the client secret is valid and belongs to the
+// app.
+func validateAppSecret(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, clientSecret string) (database.OAuth2ProviderAppSecret, error) {
+	secret, err := ParseSecret(clientSecret)
+	if err != nil {
+		return database.OAuth2ProviderAppSecret{}, errBadSecret
 	}
+	//nolint:gocritic // Users cannot read secrets so we must use the system.
+	dbSecret, err := db.GetOAuth2ProviderAppSecretByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(secret.Prefix))
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.OAuth2ProviderAppSecret{}, errBadSecret
+	}
+	if err != nil {
+		return database.OAuth2ProviderAppSecret{}, err
+	}
+	equal, err := userpassword.Compare(string(dbSecret.HashedSecret), secret.Secret)
+	if err != nil {
+		return database.OAuth2ProviderAppSecret{}, xerrors.Errorf("unable to compare secret: %w", err)
+	}
+	if !equal || dbSecret.AppID != app.ID {
+		return database.OAuth2ProviderAppSecret{}, errBadSecret
+	}
+	return dbSecret, nil
+}
 
+// exchangeForToken generates an API key for the user along with a refresh
+// token for the app, replacing any key with the same name. consume runs in the
+// same transaction and should delete whatever grant is being exchanged so it
+// cannot be used twice.
+func exchangeForToken(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, lifetimes codersdk.SessionLifetime, userID uuid.UUID, tokenName string, secretID uuid.NullUUID, consume func(ctx context.Context, tx database.Store) error) (oauth2.Token, error) {
 	// Generate a refresh token.
 	refreshToken, err := GenerateSecret()
 	if err != nil {
 		return oauth2.Token{}, err
 	}
 
-	// Generate the API key we will swap for the code.
-	// TODO: We are ignoring scopes for now.
-	tokenName := fmt.Sprintf("%s_%s_oauth_session_token", dbCode.UserID, app.ID)
+	// Generate the API key we will swap for the grant.
 	key, sessionToken, err := apikey.Generate(apikey.CreateParams{
-		UserID:          dbCode.UserID,
+		UserID:          userID,
 		LoginType:       database.LoginTypeOAuth2ProviderApp,
 		DefaultLifetime: lifetimes.DefaultDuration.Value(),
 		// For now, we allow only one token per app and user at a time.
@@ -209,7 +438,7 @@ func authorizationCodeGrant(ctx context.Context, db database.Store, app database
 	}
 
 	// Grab the user roles so we can perform the exchange as the user.
-	actor, _, err := httpmw.UserRBACSubject(ctx, db, dbCode.UserID, rbac.ScopeAll)
+	actor, _, err := httpmw.UserRBACSubject(ctx, db, userID, rbac.ScopeAll)
 	if err != nil {
 		return oauth2.Token{}, xerrors.Errorf("fetch user actor: %w", err)
 	}
@@ -217,14 +446,16 @@ func authorizationCodeGrant(ctx context.Context, db database.Store, app database
 	// Do the actual token exchange in the database.
 	err = db.InTx(func(tx database.Store) error {
 		ctx := dbauthz.As(ctx, actor)
-		err = tx.DeleteOAuth2ProviderAppCodeByID(ctx, dbCode.ID)
-		if err != nil {
-			return xerrors.Errorf("delete oauth2 app code: %w", err)
+		if consume != nil {
+			err := consume(ctx, tx)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Delete the previous key, if any.
 		prevKey, err := tx.GetAPIKeyByName(ctx, database.GetAPIKeyByNameParams{
-			UserID:    dbCode.UserID,
+			UserID:    userID,
 			TokenName: tokenName,
 		})
 		if err == nil {
@@ -245,8 +476,9 @@ func authorizationCodeGrant(ctx context.Context, db database.Store, app database
 			ExpiresAt:   key.ExpiresAt,
 			HashPrefix:  []byte(refreshToken.Prefix),
 			RefreshHash: []byte(refreshToken.Hashed),
-			AppSecretID: dbSecret.ID,
+			AppSecretID: secretID,
 			APIKeyID:    newKey.ID,
+			AppID:       app.ID,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert oauth2 refresh token: %w", err)
@@ -267,23 +499,23 @@ func authorizationCodeGrant(ctx context.Context, db database.Store, app database
 
 func refreshTokenGrant(ctx context.Context, db database.Store, app database.OAuth2ProviderApp, lifetimes codersdk.SessionLifetime, params tokenParams) (oauth2.Token, error) {
 	// Validate the token.
-	token, err := parseSecret(params.refreshToken)
+	token, err := ParseSecret(params.refreshToken)
 	if err != nil {
 		return oauth2.Token{}, errBadToken
 	}
 	//nolint:gocritic // There is no user yet so we must use the system.
-	dbToken, err := db.GetOAuth2ProviderAppTokenByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(token.prefix))
+	dbToken, err := db.GetOAuth2ProviderAppTokenByPrefix(dbauthz.AsSystemRestricted(ctx), []byte(token.Prefix))
 	if errors.Is(err, sql.ErrNoRows) {
 		return oauth2.Token{}, errBadToken
 	}
 	if err != nil {
 		return oauth2.Token{}, err
 	}
-	equal, err := userpassword.Compare(string(dbToken.RefreshHash), token.secret)
+	equal, err := userpassword.Compare(string(dbToken.RefreshHash), token.Secret)
 	if err != nil {
 		return oauth2.Token{}, xerrors.Errorf("unable to compare token: %w", err)
 	}
-	if !equal {
+	if !equal || dbToken.AppID != app.ID {
 		return oauth2.Token{}, errBadToken
 	}
 
@@ -345,6 +577,7 @@ func refreshTokenGrant(ctx context.Context, db database.Store, app database.OAut
 			RefreshHash: []byte(refreshToken.Hashed),
 			AppSecretID: dbToken.AppSecretID,
 			APIKeyID:    newKey.ID,
+			AppID:       dbToken.AppID,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert oauth2 refresh token: %w", err)
@@ -0,0 +1,194 @@
+// Package ldapauth authenticates users with the credentials of their LDAP
+// directory account.
+package ldapauth
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+	"golang.org/x/xerrors"
+)
+
+// UsernamePlaceholder is replaced with the escaped username in SearchFilter.
+const UsernamePlaceholder = "{username}"
+
+// DefaultSearchFilter is used if Config.SearchFilter is empty.
+const DefaultSearchFilter = "(uid=" + UsernamePlaceholder + ")"
+
+// ErrInvalidCredentials is returned if the user does not exist or the
+// password is wrong. The two cases are not distinguished so the error does
+// not reveal which users exist.
+var ErrInvalidCredentials = xerrors.New("invalid credentials")
+
+// Config configures how users are looked up and authenticated.
+type Config struct {
+	// URL is the ldap:// or ldaps:// URL of the server.
+	URL string
+	// StartTLS upgrades ldap:// connections to TLS before anything is sent.
+	StartTLS bool
+	// TLSConfig is used for ldaps:// and StartTLS. The server name defaults
+	// to the host of URL.
+	TLSConfig *tls.Config
+
+	// BindDN and BindPassword are the credentials of the service account
+	// that searches for users. Searches are anonymous if BindDN is empty.
+	BindDN       string
+	BindPassword string
+
+	// SearchBaseDN is the subtree to search for users in.
+	SearchBaseDN string
+	// SearchFilter must match exactly one entry for the user logging in.
+	// UsernamePlaceholder is replaced with the escaped username.
+	SearchFilter string
+
+	// The attributes of the user entry that make up the identity. The
+	// username falls back to the one used to log in, and groups are not
+	// read if GroupAttribute is empty.
+	UsernameAttribute string
+	EmailAttribute    string
+	NameAttribute     string
+	GroupAttribute    string
+}
+
+// Identity is the user that authenticated.
+type Identity struct {
+	DN       string
+	Username string
+	Email    string
+	Name     string
+	// Groups are the values of the group attribute. Values that are DNs,
+	// such as those of memberOf, are reduced to the value of their first
+	// RDN, so "cn=admins,ou=groups,dc=example,dc=com" becomes "admins".
+	Groups []string
+}
+
+// Authenticate finds the entry of the user with the search filter and binds
+// as it with the password. ErrInvalidCredentials is returned if either step
+// fails because of the credentials.
+func (c Config) Authenticate(ctx context.Context, username, password string) (Identity, error) {
+	// Most servers treat a bind with an empty password as an anonymous bind
+	// that always succeeds.
+	if username == "" || password == "" {
+		return Identity{}, ErrInvalidCredentials
+	}
+
+	conn, err := c.dial()
+	if err != nil {
+		return Identity{}, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	if c.BindDN != "" {
+		err = conn.Bind(c.BindDN, c.BindPassword)
+		if err != nil {
+			return Identity{}, xerrors.Errorf("bind as search user: %w", err)
+		}
+	}
+
+	filter := c.SearchFilter
+	if filter == "" {
+		filter = DefaultSearchFilter
+	}
+	filter = strings.ReplaceAll(filter, UsernamePlaceholder, ldap.EscapeFilter(username))
+
+	var attributes []string
+	for _, attribute := range []string{c.UsernameAttribute, c.EmailAttribute, c.NameAttribute, c.GroupAttribute} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	// A size limit of two is enough to tell whether the filter is ambiguous.
+	res, err := conn.Search(ldap.NewSearchRequest(
+		c.SearchBaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, 0, false, filter, attributes, nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return Identity{}, xerrors.Errorf("search filter %q matched more than one entry", filter)
+	}
+	if err != nil {
+		return Identity{}, xerrors.Errorf("search for user: %w", err)
+	}
+	if len(res.Entries) == 0 {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if len(res.Entries) > 1 {
+		return Identity{}, xerrors.Errorf("search filter %q matched more than one entry", filter)
+	}
+	entry := res.Entries[0]
+
+	err = conn.Bind(entry.DN, password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return Identity{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return Identity{}, xerrors.Errorf("bind as user: %w", err)
+	}
+
+	identity := Identity{
+		DN:       entry.DN,
+		Username: username,
+		Groups:   []string{},
+	}
+	if c.UsernameAttribute != "" {
+		if value := entry.GetEqualFoldAttributeValue(c.UsernameAttribute); value != "" {
+			identity.Username = value
+		}
+	}
+	if c.EmailAttribute != "" {
+		identity.Email = entry.GetEqualFoldAttributeValue(c.EmailAttribute)
+	}
+	if c.NameAttribute != "" {
+		identity.Name = entry.GetEqualFoldAttributeValue(c.NameAttribute)
+	}
+	if c.GroupAttribute != "" {
+		for _, value := range entry.GetEqualFoldAttributeValues(c.GroupAttribute) {
+			identity.Groups = append(identity.Groups, groupName(value))
+		}
+	}
+	return identity, nil
+}
+
+func (c Config) dial() (*ldap.Conn, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSConfig != nil {
+		tlsConfig = c.TLSConfig.Clone()
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(c.URL, ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, xerrors.Errorf("dial: %w", err)
+	}
+	if c.StartTLS {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			_ = conn.Close()
+			return nil, xerrors.Errorf("start tls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// groupName returns the value of the first RDN if value is a DN, and value
+// itself otherwise.
+func groupName(value string) string {
+	dn, err := ldap.ParseDN(value)
+	if err != nil || len(dn.RDNs) == 0 || len(dn.RDNs[0].Attributes) == 0 {
+		return value
+	}
+	return dn.RDNs[0].Attributes[0].Value
+}
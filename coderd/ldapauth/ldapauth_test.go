@@ -0,0 +1,231 @@
+package ldapauth_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/coderdtest/ldaptest"
+	"github.com/coder/coder/v2/coderd/ldapauth"
+	"github.com/coder/coder/v2/testutil"
+)
+
+const (
+	serviceDN       = "cn=coder,ou=services,dc=example,dc=com"
+	servicePassword = "service-password"
+	alicePassword   = "alice-password"
+)
+
+func entries() []ldaptest.Entry {
+	return []ldaptest.Entry{
+		{
+			DN:       serviceDN,
+			Password: servicePassword,
+		},
+		{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: alicePassword,
+			Attributes: map[string][]string{
+				"uid":      {"alice"},
+				"mail":     {"alice@example.com"},
+				"cn":       {"Alice Liddell"},
+				"memberOf": {"cn=admins,ou=groups,dc=example,dc=com", "developers"},
+			},
+		},
+		{
+			DN:       "uid=bob,ou=people,dc=example,dc=com",
+			Password: "bob-password",
+			Attributes: map[string][]string{
+				"uid":  {"bob"},
+				"mail": {"bob@example.com"},
+			},
+		},
+	}
+}
+
+func config(fake *ldaptest.FakeLDAP) ldapauth.Config {
+	return ldapauth.Config{
+		URL:               fake.URL(),
+		BindDN:            serviceDN,
+		BindPassword:      servicePassword,
+		SearchBaseDN:      "ou=people,dc=example,dc=com",
+		UsernameAttribute: "uid",
+		EmailAttribute:    "mail",
+		NameAttribute:     "cn",
+		GroupAttribute:    "memberOf",
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		identity, err := config(fake).Authenticate(ctx, "alice", alicePassword)
+		require.NoError(t, err)
+		require.Equal(t, ldapauth.Identity{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Username: "alice",
+			Email:    "alice@example.com",
+			Name:     "Alice Liddell",
+			Groups:   []string{"admins", "developers"},
+		}, identity)
+		require.Equal(t, []string{serviceDN, identity.DN}, fake.Binds())
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		_, err := config(fake).Authenticate(ctx, "alice", "wrong")
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+
+		// An empty password would be an anonymous bind.
+		_, err = config(fake).Authenticate(ctx, "alice", "")
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		_, err := config(fake).Authenticate(ctx, "carol", alicePassword)
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+	})
+
+	t.Run("EscapesUsername", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		_, err := config(fake).Authenticate(ctx, "*", alicePassword)
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+		_, err = config(fake).Authenticate(ctx, "alice)(uid=*", alicePassword)
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+	})
+
+	t.Run("AmbiguousFilter", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.SearchFilter = "(|(uid={username})(mail=*))"
+		_, err := cfg.Authenticate(ctx, "alice", alicePassword)
+		require.ErrorContains(t, err, "matched more than one entry")
+	})
+
+	t.Run("SearchFilter", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.SearchFilter = "(&(mail={username})(memberOf=cn=admins,ou=groups,dc=example,dc=com))"
+		identity, err := cfg.Authenticate(ctx, "alice@example.com", alicePassword)
+		require.NoError(t, err)
+		require.Equal(t, "alice", identity.Username)
+
+		// Bob is not an admin.
+		_, err = cfg.Authenticate(ctx, "bob@example.com", "bob-password")
+		require.ErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+	})
+
+	t.Run("AnonymousSearch", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...), ldaptest.WithAnonymousSearch())
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.BindDN = ""
+		cfg.BindPassword = ""
+		identity, err := cfg.Authenticate(ctx, "bob", "bob-password")
+		require.NoError(t, err)
+		require.Equal(t, "bob@example.com", identity.Email)
+		require.Empty(t, identity.Name)
+		require.Empty(t, identity.Groups)
+		require.Equal(t, []string{identity.DN}, fake.Binds())
+	})
+
+	t.Run("InvalidBindCredentials", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.BindPassword = "wrong"
+		_, err := cfg.Authenticate(ctx, "alice", alicePassword)
+		require.Error(t, err)
+		// A misconfigured service account is not the fault of the user.
+		require.NotErrorIs(t, err, ldapauth.ErrInvalidCredentials)
+	})
+
+	t.Run("LDAPS", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...), ldaptest.WithLDAPS())
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    fake.RootCAs(),
+		}
+		identity, err := cfg.Authenticate(ctx, "alice", alicePassword)
+		require.NoError(t, err)
+		require.Equal(t, "alice", identity.Username)
+
+		// The certificate is not trusted by default.
+		cfg.TLSConfig = nil
+		_, err = cfg.Authenticate(ctx, "alice", alicePassword)
+		require.Error(t, err)
+	})
+
+	t.Run("StartTLS", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...), ldaptest.WithStartTLS())
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.StartTLS = true
+		cfg.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    fake.RootCAs(),
+		}
+		identity, err := cfg.Authenticate(ctx, "alice", alicePassword)
+		require.NoError(t, err)
+		require.Equal(t, "alice", identity.Username)
+	})
+
+	t.Run("StartTLSUnavailable", func(t *testing.T) {
+		t.Parallel()
+
+		fake := ldaptest.New(t, ldaptest.WithEntries(entries()...))
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		cfg := config(fake)
+		cfg.StartTLS = true
+		cfg.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    fake.RootCAs(),
+		}
+		_, err := cfg.Authenticate(ctx, "alice", alicePassword)
+		require.ErrorContains(t, err, "start tls")
+		require.Empty(t, fake.Binds())
+	})
+}
@@ -0,0 +1,213 @@
+package coderd
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/ldapauth"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// LDAPConfig enables logging in with the credentials of an LDAP directory
+// account. Users are linked by the DN of their entry.
+type LDAPConfig struct {
+	ldapauth.Config
+
+	AllowSignups bool
+	// GroupMapping controls how groups of the user get mapped to groups
+	// within Coder. Groups are only synced if the GroupAttribute of the
+	// config is set.
+	// map[ldapGroupName]coderGroupName
+	GroupMapping map[string]string
+	// GroupFilter is a regular expression that filters the groups of the
+	// user after they are mapped. If the group filter is nil, then no group
+	// filtering will occur.
+	GroupFilter *regexp.Regexp
+	// CreateMissingGroups controls whether groups of the user are
+	// automatically created in Coder if they are missing.
+	CreateMissingGroups bool
+}
+
+// Authenticates the user with the username and password of their LDAP
+// directory account. The user is created on first login if signups are
+// allowed.
+//
+// @Summary Log in user with LDAP
+// @ID log-in-user-with-ldap
+// @Accept json
+// @Produce json
+// @Tags Authorization
+// @Param request body codersdk.LoginWithLDAPRequest true "Login request"
+// @Success 201 {object} codersdk.LoginWithPasswordResponse
+// @Router /users/ldap/login [post]
+func (api *API) postLDAPLogin(rw http.ResponseWriter, r *http.Request) {
+	var (
+		// postLDAPLogin is a system function.
+		//nolint:gocritic
+		ctx               = dbauthz.AsSystemRestricted(r.Context())
+		auditor           = api.Auditor.Load()
+		logger            = api.Logger.Named(userAuthLoggerName)
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionLogin,
+		})
+	)
+	aReq.Old = database.APIKey{}
+	defer commitAudit()
+
+	if api.LDAPConfig == nil {
+		httpapi.Write(ctx, rw, http.StatusPreconditionRequired, codersdk.Response{
+			Message: "LDAP authentication is not configured.",
+		})
+		return
+	}
+
+	var req codersdk.LoginWithLDAPRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	identity, err := api.LDAPConfig.Authenticate(ctx, req.Username, req.Password)
+	if errors.Is(err, ldapauth.ErrInvalidCredentials) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "Incorrect username or password.",
+		})
+		return
+	}
+	if err != nil {
+		// The error can describe the directory, which should not be shown to
+		// users that are not logged in.
+		logger.Error(ctx, "ldap: unable to authenticate user", slog.F("username", req.Username), slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to authenticate with LDAP. Contact an admin for assistance.",
+		})
+		return
+	}
+
+	if identity.Email == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "No email found in LDAP entry!",
+			Detail:  fmt.Sprintf("The %q attribute of your entry is empty.", api.LDAPConfig.EmailAttribute),
+		})
+		return
+	}
+
+	username := identity.Username
+	if httpapi.NameValid(username) != nil {
+		username = httpapi.UsernameFrom(username)
+	}
+
+	user, link, err := findLinkedUser(ctx, api.Database, identity.DN, identity.Email)
+	if err != nil {
+		logger.Error(ctx, "ldap: unable to find linked user", slog.F("email", identity.Email), slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to find linked user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// The email attribute is not verified and can often be edited by users,
+	// so it must not move a user to a different directory entry.
+	if link.LoginType == database.LoginTypeLDAP && link.LinkedID != "" && link.LinkedID != identity.DN {
+		logger.Warn(ctx, "ldap: email is linked to a different entry",
+			slog.F("email", identity.Email),
+			slog.F("dn", identity.DN),
+			slog.F("linked_dn", link.LinkedID),
+		)
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Your email is already used by a different LDAP account.",
+			Detail:  "Contact an admin for assistance.",
+		})
+		return
+	}
+	aReq.UserID = user.ID
+
+	// If a new user is authenticating for the first time
+	// the audit action is 'register', not 'login'
+	if user.ID == uuid.Nil {
+		aReq.Action = database.AuditActionRegister
+	}
+	if user.Status == database.UserStatusSuspended {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: fmt.Sprintf("Your account is %s. Contact an admin to reactivate your account.", user.Status),
+		})
+		return
+	}
+
+	var groups []string
+	for _, group := range identity.Groups {
+		if mappedGroup, ok := api.LDAPConfig.GroupMapping[group]; ok {
+			group = mappedGroup
+		}
+		groups = append(groups, group)
+	}
+
+	params := (&oauthLoginParams{
+		User: user,
+		Link: link,
+		// There are no OAuth tokens to store in the user link.
+		State:        httpmw.OAuth2State{Token: &oauth2.Token{}},
+		LinkedID:     identity.DN,
+		LoginType:    database.LoginTypeLDAP,
+		AllowSignups: api.LDAPConfig.AllowSignups,
+		Email:        identity.Email,
+		Username:     username,
+		Name:         httpapi.NormalizeRealUsername(identity.Name),
+		// LDAP does not provide avatars, so keep the one the user has.
+		AvatarURL:           user.AvatarURL,
+		UsingGroups:         api.LDAPConfig.GroupAttribute != "",
+		Groups:              groups,
+		CreateMissingGroups: api.LDAPConfig.CreateMissingGroups,
+		GroupFilter:         api.LDAPConfig.GroupFilter,
+	}).SetInitAuditRequest(func(params *audit.RequestParams) (*audit.Request[database.User], func()) {
+		return audit.InitRequest[database.User](rw, params)
+	})
+	cookies, user, key, err := api.oauthLogin(r, params)
+	defer params.CommitAuditLogs()
+	var httpErr httpError
+	if xerrors.As(err, &httpErr) {
+		// Unlike the OAuth callbacks, this is an API request and not a
+		// browser redirect.
+		httpErr.renderStaticPage = false
+		httpErr.renderDetailMarkdown = false
+		httpErr.Write(rw, r)
+		return
+	}
+	if err != nil {
+		logger.Error(ctx, "ldap: login failed", slog.F("user", user.Username), slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to process LDAP login.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = key
+	aReq.UserID = key.UserID
+
+	var sessionToken string
+	for _, cookie := range cookies {
+		http.SetCookie(rw, cookie)
+		if cookie.Name == codersdk.SessionTokenCookie {
+			sessionToken = cookie.Value
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.LoginWithPasswordResponse{
+		SessionToken: sessionToken,
+	})
+}
@@ -0,0 +1,319 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd"
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/coderdtest/ldaptest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/ldapauth"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestPostLDAPLogin(t *testing.T) {
+	t.Parallel()
+
+	const password = "alice-password"
+	alice := ldaptest.Entry{
+		DN:       "uid=alice,ou=people,dc=example,dc=com",
+		Password: password,
+		Attributes: map[string][]string{
+			"uid":  {"alice"},
+			"mail": {"alice@coder.com"},
+			"cn":   {"Alice Liddell"},
+		},
+	}
+	setup := func(t *testing.T, mutate func(cfg *coderd.LDAPConfig)) (*codersdk.Client, *ldaptest.FakeLDAP, *audit.MockAuditor) {
+		t.Helper()
+
+		fake := ldaptest.New(t, ldaptest.WithAnonymousSearch(), ldaptest.WithEntries(alice))
+		cfg := &coderd.LDAPConfig{
+			Config: ldapauth.Config{
+				URL:               fake.URL(),
+				SearchBaseDN:      "ou=people,dc=example,dc=com",
+				UsernameAttribute: "uid",
+				EmailAttribute:    "mail",
+				NameAttribute:     "cn",
+			},
+			AllowSignups: true,
+		}
+		if mutate != nil {
+			mutate(cfg)
+		}
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{
+			LDAPConfig: cfg,
+			Auditor:    auditor,
+		})
+		return client, fake, auditor
+	}
+
+	t.Run("Signup", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, auditor := setup(t, nil)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		methods, err := owner.AuthMethods(ctx)
+		require.NoError(t, err)
+		require.True(t, methods.LDAP.Enabled)
+
+		client := codersdk.New(owner.URL)
+		res, err := client.LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+		client.SetSessionToken(res.SessionToken)
+
+		user, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, "alice", user.Username)
+		require.Equal(t, "alice@coder.com", user.Email)
+		require.Equal(t, "Alice Liddell", user.Name)
+		require.Equal(t, codersdk.LoginTypeLDAP, user.LoginType)
+
+		require.True(t, auditor.Contains(t, database.AuditLog{
+			ResourceType: database.ResourceTypeApiKey,
+			Action:       database.AuditActionRegister,
+		}))
+
+		// LDAP logins are sessions.
+		sessions, err := client.UserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.Equal(t, codersdk.LoginTypeLDAP, sessions[0].LoginType)
+	})
+
+	t.Run("ExistingUser", func(t *testing.T) {
+		t.Parallel()
+
+		owner, fake, _ := setup(t, nil)
+		coderdtest.CreateFirstUser(t, owner)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		client := codersdk.New(owner.URL)
+		_, err := client.LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+
+		// Changes in the directory are synced on login.
+		updated := alice
+		updated.Attributes = map[string][]string{
+			"uid":  {"alice"},
+			"mail": {"alice@example.com"},
+			"cn":   {"Alice Pleasance Liddell"},
+		}
+		fake.AddEntry(updated)
+		res, err := client.LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+		client.SetSessionToken(res.SessionToken)
+
+		user, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, "alice", user.Username)
+		require.Equal(t, "alice@example.com", user.Email)
+		require.Equal(t, "Alice Pleasance Liddell", user.Name)
+
+		users, err := owner.Users(ctx, codersdk.UsersRequest{})
+		require.NoError(t, err)
+		require.Len(t, users.Users, 2, "the second login must not create another user")
+	})
+
+	t.Run("EmailOfOtherEntry", func(t *testing.T) {
+		t.Parallel()
+
+		owner, fake, _ := setup(t, nil)
+		coderdtest.CreateFirstUser(t, owner)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+
+		// Another entry with the same email must not log in as alice.
+		fake.AddEntry(ldaptest.Entry{
+			DN:       "uid=mallory,ou=people,dc=example,dc=com",
+			Password: "mallory-password",
+			Attributes: map[string][]string{
+				"uid":  {"mallory"},
+				"mail": {"alice@coder.com"},
+				"cn":   {"Mallory"},
+			},
+		})
+		_, err = codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "mallory",
+			Password: "mallory-password",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		_, err = codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("InvalidPassword", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, _ := setup(t, nil)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: "wrong",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		_, err = codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "bob",
+			Password: password,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+	})
+
+	t.Run("SignupsDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, _ := setup(t, func(cfg *coderd.LDAPConfig) {
+			cfg.AllowSignups = false
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, "Signups are disabled", apiErr.Message)
+	})
+
+	t.Run("CreatedByAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, _ := setup(t, func(cfg *coderd.LDAPConfig) {
+			cfg.AllowSignups = false
+		})
+		first := coderdtest.CreateFirstUser(t, owner)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		created, err := owner.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "alice@coder.com",
+			Username:       "alice",
+			OrganizationID: first.OrganizationID,
+			UserLoginType:  codersdk.LoginTypeLDAP,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.LoginTypeLDAP, created.LoginType)
+
+		client := codersdk.New(owner.URL)
+		res, err := client.LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+		client.SetSessionToken(res.SessionToken)
+		user, err := client.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, created.ID, user.ID)
+	})
+
+	t.Run("WrongLoginType", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, _ := setup(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := owner.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "alice@coder.com",
+			Username:       "alice",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: first.OrganizationID,
+		})
+		require.NoError(t, err)
+
+		_, err = codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, "Incorrect login type", apiErr.Message)
+	})
+
+	t.Run("Suspended", func(t *testing.T) {
+		t.Parallel()
+
+		owner, _, _ := setup(t, nil)
+		coderdtest.CreateFirstUser(t, owner)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		require.NoError(t, err)
+		_, err = owner.UpdateUserStatus(ctx, "alice", codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+
+		_, err = codersdk.New(owner.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		methods, err := client.AuthMethods(ctx)
+		require.NoError(t, err)
+		require.False(t, methods.LDAP.Enabled)
+
+		_, err = client.LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: password,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusPreconditionRequired, apiErr.StatusCode())
+
+		first := coderdtest.CreateFirstUser(t, client)
+		_, err = client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "alice@coder.com",
+			Username:       "alice",
+			OrganizationID: first.OrganizationID,
+			UserLoginType:  codersdk.LoginTypeLDAP,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
@@ -43,6 +43,13 @@ func (api *API) postOrganizationMember(rw http.ResponseWriter, r *http.Request)
 	aReq.Old = database.AuditableOrganizationMember{}
 	defer commitAudit()
 
+	if user.IsServiceAccount {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts cannot be added to other organizations.",
+		})
+		return
+	}
+
 	member, err := api.Database.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
 		OrganizationID: organization.ID,
 		UserID:         user.ID,
@@ -124,6 +131,14 @@ func (api *API) deleteOrganizationMember(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if member.ServiceAccountOrganizationID.Valid && member.ServiceAccountOrganizationID.UUID == organization.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Service accounts cannot be removed from the organization that owns them.",
+			Detail:  "Delete the service account instead.",
+		})
+		return
+	}
+
 	err := api.Database.DeleteOrganizationMember(ctx, database.DeleteOrganizationMemberParams{
 		OrganizationID: organization.ID,
 		UserID:         member.UserID,
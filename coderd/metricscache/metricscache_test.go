@@ -214,6 +214,7 @@ func TestCache_BuildTime(t *testing.T) {
 					Provisioner:   database.ProvisionerTypeEcho,
 					StorageMethod: database.ProvisionerStorageMethodFile,
 					Type:          database.ProvisionerJobTypeWorkspaceBuild,
+					Priority:      database.ProvisionerJobPriorityInteractive,
 				})
 				require.NoError(t, err)
 
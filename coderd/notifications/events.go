@@ -12,6 +12,7 @@ var (
 	TemplateWorkspaceDormant           = uuid.MustParse("0ea69165-ec14-4314-91f1-69566ac3c5a0")
 	TemplateWorkspaceAutoUpdated       = uuid.MustParse("c34a0c09-0704-4cac-bd1c-0c0146811c2b")
 	TemplateWorkspaceMarkedForDeletion = uuid.MustParse("51ce2fdf-c9ca-4be1-8d70-628674f9bc42")
+	TemplateWorkspaceDrifted           = uuid.MustParse("3b7e8f1c-5a6d-4e2f-9c1b-7d8a0e4f6b23")
 )
 
 // Account-related events.
@@ -20,6 +21,13 @@ var (
 	TemplateUserAccountDeleted = uuid.MustParse("f44d9314-ad03-4bc8-95d0-5cad491da6b6")
 )
 
+// Role grant events.
+var (
+	TemplateRoleGrantApproved = uuid.MustParse("755eb0c2-553c-4111-aaf3-2be4af251610")
+	TemplateRoleGrantDenied   = uuid.MustParse("077e1a0c-51d1-44d4-b882-fae3b9ff5b6e")
+	TemplateRoleGrantEnded    = uuid.MustParse("bc5162da-1835-4cc3-b0f7-a508d909296d")
+)
+
 // Template-related events.
 var (
 	TemplateTemplateDeleted = uuid.MustParse("29a09665-2a4c-403f-9648-54301670e7be")
@@ -736,6 +736,17 @@ func TestNotificationTemplatesCanRender(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TemplateWorkspaceDrifted",
+			id:   notifications.TemplateWorkspaceDrifted,
+			payload: types.MessagePayload{
+				UserName: "bobby",
+				Labels: map[string]string{
+					"name":      "bobby-workspace",
+					"resources": "aws_instance.dev, aws_ebs_volume.home",
+				},
+			},
+		},
 		{
 			name: "TemplateUserAccountCreated",
 			id:   notifications.TemplateUserAccountCreated,
@@ -756,6 +767,40 @@ func TestNotificationTemplatesCanRender(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TemplateRoleGrantApproved",
+			id:   notifications.TemplateRoleGrantApproved,
+			payload: types.MessagePayload{
+				UserName: "bobby",
+				Labels: map[string]string{
+					"role":       "Template Admin",
+					"reviewer":   "rob",
+					"expires_at": "2024-10-01 12:00 UTC",
+				},
+			},
+		},
+		{
+			name: "TemplateRoleGrantDenied",
+			id:   notifications.TemplateRoleGrantDenied,
+			payload: types.MessagePayload{
+				UserName: "bobby",
+				Labels: map[string]string{
+					"role":     "Template Admin",
+					"reviewer": "rob",
+				},
+			},
+		},
+		{
+			name: "TemplateRoleGrantEnded",
+			id:   notifications.TemplateRoleGrantEnded,
+			payload: types.MessagePayload{
+				UserName: "bobby",
+				Labels: map[string]string{
+					"role":   "Template Admin",
+					"reason": "expired",
+				},
+			},
+		},
 		{
 			name: "TemplateTemplateDeleted",
 			id:   notifications.TemplateTemplateDeleted,
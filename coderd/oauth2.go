@@ -14,6 +14,8 @@ import (
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/coderd/identityprovider"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -113,13 +115,19 @@ func (api *API) postOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
+	clientCredentialsUserID, ok := api.validateOAuth2ProviderAppClientCredentials(rw, r, req.Public, req.ClientCredentialsUserID)
+	if !ok {
+		return
+	}
 	app, err := api.Database.InsertOAuth2ProviderApp(ctx, database.InsertOAuth2ProviderAppParams{
-		ID:          uuid.New(),
-		CreatedAt:   dbtime.Now(),
-		UpdatedAt:   dbtime.Now(),
-		Name:        req.Name,
-		Icon:        req.Icon,
-		CallbackURL: req.CallbackURL,
+		ID:                      uuid.New(),
+		CreatedAt:               dbtime.Now(),
+		UpdatedAt:               dbtime.Now(),
+		Name:                    req.Name,
+		Icon:                    req.Icon,
+		CallbackURL:             req.CallbackURL,
+		Public:                  req.Public,
+		ClientCredentialsUserID: clientCredentialsUserID,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -160,12 +168,18 @@ func (api *API) putOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
+	clientCredentialsUserID, ok := api.validateOAuth2ProviderAppClientCredentials(rw, r, req.Public, req.ClientCredentialsUserID)
+	if !ok {
+		return
+	}
 	app, err := api.Database.UpdateOAuth2ProviderAppByID(ctx, database.UpdateOAuth2ProviderAppByIDParams{
-		ID:          app.ID,
-		UpdatedAt:   dbtime.Now(),
-		Name:        req.Name,
-		Icon:        req.Icon,
-		CallbackURL: req.CallbackURL,
+		ID:                      app.ID,
+		UpdatedAt:               dbtime.Now(),
+		Name:                    req.Name,
+		Icon:                    req.Icon,
+		CallbackURL:             req.CallbackURL,
+		Public:                  req.Public,
+		ClientCredentialsUserID: clientCredentialsUserID,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -178,6 +192,43 @@ func (api *API) putOAuth2ProviderApp(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusOK, db2sdk.OAuth2ProviderApp(api.AccessURL, app))
 }
 
+// validateOAuth2ProviderAppClientCredentials checks the user that an app's
+// client credentials tokens will act as. Since anyone holding the app's secret
+// can then act as that user, the caller must be allowed to create API keys for
+// them.
+func (api *API) validateOAuth2ProviderAppClientCredentials(rw http.ResponseWriter, r *http.Request, public bool, userID *uuid.UUID) (uuid.NullUUID, bool) {
+	ctx := r.Context()
+	if userID == nil {
+		return uuid.NullUUID{}, true
+	}
+	if public {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Public applications cannot use the client credentials grant.",
+			Detail:  "A public application cannot keep its client secret confidential.",
+		})
+		return uuid.NullUUID{}, false
+	}
+	user, err := api.Database.GetUserByID(ctx, *userID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Client credentials user not found.",
+			Detail:  fmt.Sprintf("No user with ID %q.", userID),
+		})
+		return uuid.NullUUID{}, false
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return uuid.NullUUID{}, false
+	}
+	if !api.Authorize(r, policy.ActionCreate, rbac.ResourceApiKey.WithOwner(user.ID.String())) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "You are not allowed to create tokens for the client credentials user.",
+		})
+		return uuid.NullUUID{}, false
+	}
+	return uuid.NullUUID{UUID: user.ID, Valid: true}, true
+}
+
 // @Summary Delete OAuth2 application.
 // @ID delete-oauth2-application
 // @Security CoderSessionToken
@@ -336,6 +387,8 @@ func (api *API) deleteOAuth2ProviderAppSecret(rw http.ResponseWriter, r *http.Re
 // @Param response_type query codersdk.OAuth2ProviderResponseType true "Response type"
 // @Param redirect_uri query string false "Redirect here after authorization"
 // @Param scope query string false "Token scopes (currently ignored)"
+// @Param code_challenge query string false "PKCE code challenge, required for public applications"
+// @Param code_challenge_method query codersdk.OAuth2ProviderCodeChallengeMethod false "PKCE code challenge method, required if code_challenge is set"
 // @Success 302
 // @Router /oauth2/authorize [post]
 func (api *API) getOAuth2ProviderAppAuthorize() http.HandlerFunc {
@@ -346,9 +399,11 @@ func (api *API) getOAuth2ProviderAppAuthorize() http.HandlerFunc {
 // @ID oauth2-token-exchange
 // @Produce json
 // @Tags Enterprise
-// @Param client_id formData string false "Client ID, required if grant_type=authorization_code"
-// @Param client_secret formData string false "Client secret, required if grant_type=authorization_code"
+// @Param client_id formData string false "Client ID, required unless grant_type=refresh_token"
+// @Param client_secret formData string false "Client secret, required unless grant_type=refresh_token or the application is public"
 // @Param code formData string false "Authorization code, required if grant_type=authorization_code"
+// @Param code_verifier formData string false "PKCE code verifier, required if a code challenge was sent during authorization"
+// @Param device_code formData string false "Device code, required if grant_type=urn:ietf:params:oauth:grant-type:device_code"
 // @Param refresh_token formData string false "Refresh token, required if grant_type=refresh_token"
 // @Param grant_type formData codersdk.OAuth2ProviderGrantType true "Grant type"
 // @Success 200 {object} oauth2.Token
@@ -357,6 +412,33 @@ func (api *API) postOAuth2ProviderAppToken() http.HandlerFunc {
 	return identityprovider.Tokens(api.Database, api.DeploymentValues.Sessions)
 }
 
+// @Summary OAuth2 device authorization request.
+// @ID oauth2-device-authorization-request
+// @Produce json
+// @Tags Enterprise
+// @Param client_id formData string true "Client ID"
+// @Param client_secret formData string false "Client secret, required unless the application is public"
+// @Param scope formData string false "Token scopes (currently ignored)"
+// @Success 200 {object} oauth2.DeviceAuthResponse
+// @Router /oauth2/device [post]
+func (api *API) postOAuth2ProviderDeviceAuthorization() http.HandlerFunc {
+	return identityprovider.DeviceAuthorization(api.Database, api.AccessURL)
+}
+
+// @Summary OAuth2 device verification page.
+// @ID oauth2-device-verification-page
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param user_code query string false "User code shown on the device"
+// @Param action query string false "Whether to allow or deny the device" Enums(allow,deny)
+// @Success 200
+// @Router /oauth2/device/verify [get]
+func (api *API) getOAuth2ProviderDeviceVerify() http.HandlerFunc {
+	return identityprovider.DeviceVerification(api.Database, api.AccessURL, identityprovider.DeviceVerificationOptions{
+		Path: identityprovider.DeviceVerificationPath,
+	})
+}
+
 // @Summary Delete OAuth2 application tokens.
 // @ID delete-oauth2-application-tokens
 // @Security CoderSessionToken
@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 
 	"github.com/coder/coder/v2/coderd/apikey"
 	"github.com/coder/coder/v2/coderd/coderdtest"
@@ -378,6 +380,8 @@ func TestOAuth2ProviderTokenExchange(t *testing.T) {
 	secret, err := ownerClient.PostOAuth2ProviderAppSecret(topCtx, apps.Default.ID)
 	require.NoError(t, err)
 
+	verifier := oauth2.GenerateVerifier()
+
 	// The typical oauth2 flow from this point is:
 	// Create an oauth2.Config using the id, secret, endpoints, and redirect:
 	//	cfg := oauth2.Config{ ... }
@@ -401,6 +405,7 @@ func TestOAuth2ProviderTokenExchange(t *testing.T) {
 		// The flow is setup(ctx, client, user) -> preAuth(cfg) -> cfg.AuthCodeURL() -> preToken(cfg) -> cfg.Exchange()
 		setup      func(context.Context, *codersdk.Client, codersdk.User) error
 		preAuth    func(valid *oauth2.Config)
+		authMutate []oauth2.AuthCodeOption
 		authError  string
 		preToken   func(valid *oauth2.Config)
 		tokenError string
@@ -655,6 +660,54 @@ func TestOAuth2ProviderTokenExchange(t *testing.T) {
 				return err
 			},
 		},
+		{
+			name:       "PKCE",
+			app:        apps.Default,
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			exchangeMutate: []oauth2.AuthCodeOption{
+				oauth2.VerifierOption(verifier),
+			},
+		},
+		{
+			name:       "PKCEMissingVerifier",
+			app:        apps.Default,
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			tokenError: "Invalid code verifier",
+		},
+		{
+			name:       "PKCEInvalidVerifier",
+			app:        apps.Default,
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			tokenError: "Invalid code verifier",
+			exchangeMutate: []oauth2.AuthCodeOption{
+				oauth2.VerifierOption(oauth2.GenerateVerifier()),
+			},
+		},
+		{
+			name:       "PKCEUnexpectedVerifier",
+			app:        apps.Default,
+			tokenError: "Invalid code verifier",
+			exchangeMutate: []oauth2.AuthCodeOption{
+				oauth2.VerifierOption(verifier),
+			},
+		},
+		{
+			name: "PKCEPlainMethod",
+			app:  apps.Default,
+			authMutate: []oauth2.AuthCodeOption{
+				oauth2.SetAuthURLParam("code_challenge", verifier),
+				oauth2.SetAuthURLParam("code_challenge_method", "plain"),
+			},
+			authError: "Invalid query params",
+		},
+		{
+			name: "PKCEMissingMethod",
+			app:  apps.Default,
+			authMutate: []oauth2.AuthCodeOption{
+				oauth2.SetAuthURLParam("code_challenge", verifier),
+			},
+			authError: "Invalid query params",
+		},
 		{
 			name: "OK",
 			app:  apps.Default,
@@ -699,7 +752,7 @@ func TestOAuth2ProviderTokenExchange(t *testing.T) {
 				code = *test.defaultCode
 			} else {
 				var err error
-				code, err = authorizationFlow(ctx, userClient, valid)
+				code, err = authorizationFlow(ctx, userClient, valid, test.authMutate...)
 				if test.authError != "" {
 					require.Error(t, err)
 					require.ErrorContains(t, err, test.authError)
@@ -836,8 +889,9 @@ func TestOAuth2ProviderTokenRefresh(t *testing.T) {
 				ExpiresAt:   expires,
 				HashPrefix:  []byte(token.Prefix),
 				RefreshHash: []byte(token.Hashed),
-				AppSecretID: secret.ID,
+				AppSecretID: uuid.NullUUID{UUID: secret.ID, Valid: true},
 				APIKeyID:    newKey.ID,
+				AppID:       test.app.ID,
 			})
 			require.NoError(t, err)
 
@@ -1053,6 +1107,373 @@ func TestOAuth2ProviderRevoke(t *testing.T) {
 	}
 }
 
+func TestOAuth2ProviderPublicApp(t *testing.T) {
+	t.Parallel()
+
+	ownerClient := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+	topCtx := testutil.Context(t, testutil.WaitLong)
+
+	//nolint:gocritic // OAauth2 app management requires owner permission.
+	app, err := ownerClient.PostOAuth2ProviderApp(topCtx, codersdk.PostOAuth2ProviderAppRequest{
+		Name:        "public-app",
+		CallbackURL: "http://localhost",
+		Public:      true,
+	})
+	require.NoError(t, err)
+	require.True(t, app.Public)
+
+	verifier := oauth2.GenerateVerifier()
+	tests := []struct {
+		name           string
+		authMutate     []oauth2.AuthCodeOption
+		authError      string
+		exchangeMutate []oauth2.AuthCodeOption
+		tokenError     string
+	}{
+		{
+			name:      "MissingChallenge",
+			authError: "Invalid query params",
+		},
+		{
+			name:       "MissingVerifier",
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			tokenError: "Invalid query params",
+		},
+		{
+			name:       "InvalidVerifier",
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			exchangeMutate: []oauth2.AuthCodeOption{
+				oauth2.VerifierOption(oauth2.GenerateVerifier()),
+			},
+			tokenError: "Invalid code verifier",
+		},
+		{
+			name:       "OK",
+			authMutate: []oauth2.AuthCodeOption{oauth2.S256ChallengeOption(verifier)},
+			exchangeMutate: []oauth2.AuthCodeOption{
+				oauth2.VerifierOption(verifier),
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := testutil.Context(t, testutil.WaitLong)
+			userClient, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+			// Public apps have no secret to send.
+			cfg := &oauth2.Config{
+				ClientID: app.ID.String(),
+				Endpoint: oauth2.Endpoint{
+					AuthURL:   app.Endpoints.Authorization,
+					TokenURL:  app.Endpoints.Token,
+					AuthStyle: oauth2.AuthStyleInParams,
+				},
+				RedirectURL: app.CallbackURL,
+				Scopes:      []string{},
+			}
+
+			code, err := authorizationFlow(ctx, userClient, cfg, test.authMutate...)
+			if test.authError != "" {
+				require.Error(t, err)
+				require.ErrorContains(t, err, test.authError)
+				return
+			}
+			require.NoError(t, err)
+
+			token, err := cfg.Exchange(ctx, code, test.exchangeMutate...)
+			if test.tokenError != "" {
+				require.Error(t, err)
+				require.ErrorContains(t, err, test.tokenError)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, token.RefreshToken)
+
+			newClient := codersdk.New(userClient.URL)
+			newClient.SetSessionToken(token.AccessToken)
+			gotUser, err := newClient.User(ctx, codersdk.Me)
+			require.NoError(t, err)
+			require.Equal(t, user.ID, gotUser.ID)
+
+			// Refreshing does not require a secret either.
+			refreshed, err := cfg.TokenSource(ctx, &oauth2.Token{
+				AccessToken:  token.AccessToken,
+				RefreshToken: token.RefreshToken,
+				Expiry:       time.Now().Add(-time.Minute),
+			}).Token()
+			require.NoError(t, err)
+			require.NotEqual(t, token.AccessToken, refreshed.AccessToken)
+		})
+	}
+}
+
+func TestOAuth2ProviderClientCredentials(t *testing.T) {
+	t.Parallel()
+
+	ownerClient := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+	_, serviceUser := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		app, err := ownerClient.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:                    "client-credentials",
+			CallbackURL:             "http://localhost",
+			ClientCredentialsUserID: &serviceUser.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, &serviceUser.ID, app.ClientCredentialsUserID)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		secret, err := ownerClient.PostOAuth2ProviderAppSecret(ctx, app.ID)
+		require.NoError(t, err)
+
+		cfg := clientcredentials.Config{
+			ClientID:     app.ID.String(),
+			ClientSecret: secret.ClientSecretFull,
+			TokenURL:     app.Endpoints.Token,
+			AuthStyle:    oauth2.AuthStyleInParams,
+		}
+		token, err := cfg.Token(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, token.AccessToken)
+		require.Empty(t, token.RefreshToken)
+
+		newClient := codersdk.New(ownerClient.URL)
+		newClient.SetSessionToken(token.AccessToken)
+		gotUser, err := newClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, serviceUser.ID, gotUser.ID)
+
+		// A bad secret is rejected.
+		cfg.ClientSecret = "coder_1234_4321"
+		_, err = cfg.Token(ctx)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "Invalid client secret")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		app, err := ownerClient.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:        "client-credentials-disabled",
+			CallbackURL: "http://localhost",
+		})
+		require.NoError(t, err)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		secret, err := ownerClient.PostOAuth2ProviderAppSecret(ctx, app.ID)
+		require.NoError(t, err)
+
+		cfg := clientcredentials.Config{
+			ClientID:     app.ID.String(),
+			ClientSecret: secret.ClientSecretFull,
+			TokenURL:     app.Endpoints.Token,
+			AuthStyle:    oauth2.AuthStyleInParams,
+		}
+		_, err = cfg.Token(ctx)
+		require.Error(t, err)
+		require.ErrorContains(t, err, "not enabled")
+	})
+
+	t.Run("PublicApp", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		_, err := ownerClient.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:                    "client-credentials-public",
+			CallbackURL:             "http://localhost",
+			Public:                  true,
+			ClientCredentialsUserID: &serviceUser.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("UnknownUser", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:gocritic // OAauth2 app management requires owner permission.
+		_, err := ownerClient.PostOAuth2ProviderApp(ctx, codersdk.PostOAuth2ProviderAppRequest{
+			Name:                    "client-credentials-unknown",
+			CallbackURL:             "http://localhost",
+			ClientCredentialsUserID: ptr.Ref(uuid.New()),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+func TestOAuth2ProviderDeviceFlow(t *testing.T) {
+	t.Parallel()
+
+	ownerClient := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+	topCtx := testutil.Context(t, testutil.WaitLong)
+	apps := generateApps(topCtx, t, ownerClient, "device")
+
+	//nolint:gocritic // OAauth2 app management requires owner permission.
+	secret, err := ownerClient.PostOAuth2ProviderAppSecret(topCtx, apps.Default.ID)
+	require.NoError(t, err)
+
+	newConfig := func() *oauth2.Config {
+		return &oauth2.Config{
+			ClientID:     apps.Default.ID.String(),
+			ClientSecret: secret.ClientSecretFull,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:       apps.Default.Endpoints.Authorization,
+				DeviceAuthURL: apps.Default.Endpoints.DeviceAuth,
+				TokenURL:      apps.Default.Endpoints.Token,
+				AuthStyle:     oauth2.AuthStyleInParams,
+			},
+			Scopes: []string{},
+		}
+	}
+	// The library does not send the secret when requesting a device code.
+	deviceAuth := func(ctx context.Context, cfg *oauth2.Config) (*oauth2.DeviceAuthResponse, error) {
+		return cfg.DeviceAuth(ctx, oauth2.SetAuthURLParam("client_secret", cfg.ClientSecret))
+	}
+	// verify visits the verification page the way a browser would after the
+	// user pressed one of the buttons.
+	verify := func(ctx context.Context, client *codersdk.Client, userCode, action string, referer bool) *http.Response {
+		q := url.Values{"user_code": {userCode}, "action": {action}}
+		res, err := client.Request(ctx, http.MethodGet, "/oauth2/device/verify?"+q.Encode(), nil, func(req *http.Request) {
+			if referer {
+				req.Header.Set("Referer", req.URL.String())
+			}
+		})
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		return res
+	}
+	// poll makes a single token request, instead of waiting on the poll
+	// interval like DeviceAccessToken.
+	poll := func(ctx context.Context, cfg *oauth2.Config, deviceCode string) string {
+		res, err := http.PostForm(cfg.Endpoint.TokenURL, url.Values{
+			"grant_type":    {string(codersdk.OAuth2ProviderGrantTypeDeviceCode)},
+			"client_id":     {cfg.ClientID},
+			"client_secret": {cfg.ClientSecret},
+			"device_code":   {deviceCode},
+		})
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		var body struct {
+			Error string `json:"error"`
+		}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+		return body.Error
+	}
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		cfg := newConfig()
+
+		da, err := deviceAuth(ctx, cfg)
+		require.NoError(t, err)
+		require.NotEmpty(t, da.DeviceCode)
+		require.Regexp(t, `^[A-Z]{4}-[A-Z]{4}$`, da.UserCode)
+		require.Contains(t, da.VerificationURIComplete, da.VerificationURI)
+		require.Equal(t, "authorization_pending", poll(ctx, cfg, da.DeviceCode))
+
+		res := verify(ctx, userClient, da.UserCode, "allow", true)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		token, err := cfg.DeviceAccessToken(ctx, da)
+		require.NoError(t, err)
+		require.NotEmpty(t, token.RefreshToken)
+
+		newClient := codersdk.New(userClient.URL)
+		newClient.SetSessionToken(token.AccessToken)
+		gotUser, err := newClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, gotUser.ID)
+
+		// The code is single use.
+		_, err = cfg.Exchange(ctx, "", oauth2.SetAuthURLParam("grant_type", string(codersdk.OAuth2ProviderGrantTypeDeviceCode)), oauth2.SetAuthURLParam("device_code", da.DeviceCode))
+		require.ErrorContains(t, err, "Invalid code")
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		cfg := newConfig()
+
+		da, err := deviceAuth(ctx, cfg)
+		require.NoError(t, err)
+
+		res := verify(ctx, userClient, da.UserCode, "deny", true)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "access_denied", poll(ctx, cfg, da.DeviceCode))
+
+		// Once acted on, the code cannot be approved.
+		res = verify(ctx, userClient, da.UserCode, "allow", true)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("MissingReferer", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		cfg := newConfig()
+
+		da, err := deviceAuth(ctx, cfg)
+		require.NoError(t, err)
+
+		// Without the referer the approval might not have come from the user.
+		res := verify(ctx, userClient, da.UserCode, "allow", false)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.Equal(t, "authorization_pending", poll(ctx, cfg, da.DeviceCode))
+	})
+
+	t.Run("SlowDown", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		cfg := newConfig()
+
+		da, err := deviceAuth(ctx, cfg)
+		require.NoError(t, err)
+
+		// Polling again before the interval has passed is rejected.
+		require.Equal(t, "authorization_pending", poll(ctx, cfg, da.DeviceCode))
+		require.Equal(t, "slow_down", poll(ctx, cfg, da.DeviceCode))
+	})
+
+	t.Run("InvalidUserCode", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		userClient, _ := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+		res := verify(ctx, userClient, "BBBB-BBBB", "allow", true)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("InvalidSecret", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		cfg := newConfig()
+		cfg.ClientSecret = "coder_1234_4321"
+
+		_, err := deviceAuth(ctx, cfg)
+		require.Error(t, err)
+	})
+}
+
 type provisionedApps struct {
 	Default   codersdk.OAuth2ProviderApp
 	NoPort    codersdk.OAuth2ProviderApp
@@ -1087,10 +1508,10 @@ func generateApps(ctx context.Context, t *testing.T, client *codersdk.Client, su
 	}
 }
 
-func authorizationFlow(ctx context.Context, client *codersdk.Client, cfg *oauth2.Config) (string, error) {
+func authorizationFlow(ctx context.Context, client *codersdk.Client, cfg *oauth2.Config, opts ...oauth2.AuthCodeOption) (string, error) {
 	state := uuid.NewString()
 	return oidctest.OAuth2GetCode(
-		cfg.AuthCodeURL(state),
+		cfg.AuthCodeURL(state, opts...),
 		func(req *http.Request) (*http.Response, error) {
 			// TODO: Would be better if client had a .Do() method.
 			// TODO: Is this the best way to handle redirects?
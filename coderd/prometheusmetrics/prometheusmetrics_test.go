@@ -706,6 +706,7 @@ func insertRunning(t *testing.T, db database.Store) database.ProvisionerJob {
 		Provisioner:   database.ProvisionerTypeEcho,
 		StorageMethod: database.ProvisionerStorageMethodFile,
 		Type:          database.ProvisionerJobTypeWorkspaceBuild,
+		Priority:      database.ProvisionerJobPriorityInteractive,
 	})
 	require.NoError(t, err)
 	err = db.InsertWorkspaceBuild(context.Background(), database.InsertWorkspaceBuildParams{
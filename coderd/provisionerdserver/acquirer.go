@@ -40,6 +40,10 @@ const (
 // As a backup to pubsub notifications, each domain is allowed to query periodically once every 30s.
 // This ensures jobs are not stuck permanently if the service that created them fails to publish
 // (e.g. a crash).
+//
+// The database decides which of the pending jobs is acquired: jobs are taken in order of their
+// priority class (interactive builds, then template imports, then automatic builds), then from the
+// initiator with the fewest jobs already running, and finally oldest first.
 type Acquirer struct {
 	ctx    context.Context
 	logger slog.Logger
@@ -147,7 +151,10 @@ func (a *Acquirer) AcquireJob(
 				logger.Warn(ctx, "error attempting to acquire job", slog.Error(err))
 				return database.ProvisionerJob{}, xerrors.Errorf("failed to acquire job: %w", err)
 			}
-			logger.Debug(ctx, "successfully acquired job")
+			logger.Debug(ctx, "successfully acquired job",
+				slog.F("job_id", job.ID),
+				slog.F("priority", job.Priority),
+				slog.F("initiator_id", job.InitiatorID))
 			return job, nil
 		}
 	}
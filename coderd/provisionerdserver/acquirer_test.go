@@ -495,6 +495,7 @@ func TestAcquirer_MatchTags(t *testing.T) {
 				StorageMethod:  database.ProvisionerStorageMethodFile,
 				FileID:         uuid.New(),
 				Type:           database.ProvisionerJobTypeWorkspaceBuild,
+				Priority:       database.ProvisionerJobPriorityInteractive,
 				Input:          []byte("{}"),
 				Tags:           tt.provisionerJobTags,
 				TraceMetadata:  pqtype.NullRawMessage{},
@@ -550,6 +551,88 @@ func TestAcquirer_MatchTags(t *testing.T) {
 	})
 }
 
+func TestAcquirer_Priority(t *testing.T) {
+	t.Parallel()
+
+	// setup returns functions to insert a pending job and to acquire the next
+	// job in a fresh organization.
+	setup := func(t *testing.T) (func(database.ProvisionerJobPriority, uuid.UUID) database.ProvisionerJob, func() database.ProvisionerJob) {
+		ctx := testutil.Context(t, testutil.WaitShort)
+		// NOTE: explicitly not using fake store for this test.
+		db, ps := dbtestutil.NewDB(t)
+		log := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		org, err := db.InsertOrganization(ctx, database.InsertOrganizationParams{
+			ID:          uuid.New(),
+			Name:        "test org",
+			Description: "the organization of testing",
+			CreatedAt:   dbtime.Now(),
+			UpdatedAt:   dbtime.Now(),
+		})
+		require.NoError(t, err)
+		tags := provisionerdserver.Tags{"scope": "organization", "owner": ""}
+		created := dbtime.Now().Add(-time.Hour)
+		insert := func(priority database.ProvisionerJobPriority, initiator uuid.UUID) database.ProvisionerJob {
+			// Each job is newer than the last, so creation order alone would
+			// acquire them in the order they're inserted.
+			created = created.Add(time.Second)
+			pj, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+				ID:             uuid.New(),
+				CreatedAt:      created,
+				UpdatedAt:      created,
+				OrganizationID: org.ID,
+				InitiatorID:    initiator,
+				Provisioner:    database.ProvisionerTypeEcho,
+				StorageMethod:  database.ProvisionerStorageMethodFile,
+				FileID:         uuid.New(),
+				Type:           database.ProvisionerJobTypeWorkspaceBuild,
+				Input:          []byte("{}"),
+				Tags:           database.StringMap(tags),
+				TraceMetadata:  pqtype.NullRawMessage{},
+				Priority:       priority,
+			})
+			require.NoError(t, err)
+			return pj
+		}
+		acq := provisionerdserver.NewAcquirer(ctx, log, db, ps)
+		acquire := func() database.ProvisionerJob {
+			job, err := acq.AcquireJob(ctx, org.ID, uuid.New(), []database.ProvisionerType{database.ProvisionerTypeEcho}, tags)
+			require.NoError(t, err)
+			return job
+		}
+		return insert, acquire
+	}
+
+	t.Run("Classes", func(t *testing.T) {
+		t.Parallel()
+		insert, acquire := setup(t)
+
+		autobuild := insert(database.ProvisionerJobPriorityAutobuild, uuid.New())
+		templateImport := insert(database.ProvisionerJobPriorityTemplateImport, uuid.New())
+		interactive := insert(database.ProvisionerJobPriorityInteractive, uuid.New())
+
+		assert.Equal(t, interactive.ID, acquire().ID)
+		assert.Equal(t, templateImport.ID, acquire().ID)
+		assert.Equal(t, autobuild.ID, acquire().ID)
+	})
+
+	t.Run("FairShare", func(t *testing.T) {
+		t.Parallel()
+		insert, acquire := setup(t)
+
+		busy, idle := uuid.New(), uuid.New()
+		running := insert(database.ProvisionerJobPriorityTemplateImport, busy)
+		require.Equal(t, running.ID, acquire().ID)
+
+		busyPending := insert(database.ProvisionerJobPriorityTemplateImport, busy)
+		idlePending := insert(database.ProvisionerJobPriorityTemplateImport, idle)
+
+		// The busy initiator already has a job running, so the idle
+		// initiator goes first even though its job is newer.
+		assert.Equal(t, idlePending.ID, acquire().ID)
+		assert.Equal(t, busyPending.ID, acquire().ID)
+	})
+}
+
 func postJob(t *testing.T, ps pubsub.Pubsub, pt database.ProvisionerType, tags provisionerdserver.Tags) {
 	t.Helper()
 	msg, err := json.Marshal(provisionerjobs.JobPosting{
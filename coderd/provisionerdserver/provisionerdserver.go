@@ -26,6 +26,7 @@ import (
 
 	"cdr.dev/slog"
 
+	"github.com/coder/coder/v2/apiversion"
 	"github.com/coder/coder/v2/coderd/apikey"
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
@@ -74,6 +75,10 @@ type Options struct {
 	// The default function just calls UpdateProvisionerDaemonLastSeenAt.
 	// This is mainly used for testing.
 	HeartbeatFn func(context.Context) error
+
+	// APIVersion is the provisionerd API version the daemon connected with.
+	// It defaults to the current version.
+	APIVersion string
 }
 
 type server struct {
@@ -108,6 +113,8 @@ type server struct {
 
 	heartbeatInterval time.Duration
 	heartbeatFn       func(ctx context.Context) error
+
+	apiVersion string
 }
 
 // We use the null byte (0x00) in generating a canonical map key for tags, so
@@ -189,6 +196,9 @@ func NewServer(
 	if options.HeartbeatInterval == 0 {
 		options.HeartbeatInterval = DefaultHeartbeatInterval
 	}
+	if options.APIVersion == "" {
+		options.APIVersion = proto.CurrentVersion.String()
+	}
 
 	s := &server{
 		lifecycleCtx:                lifecycleCtx,
@@ -215,6 +225,7 @@ func NewServer(
 		acquireJobLongPollDur:       options.AcquireJobLongPollDur,
 		heartbeatInterval:           options.HeartbeatInterval,
 		heartbeatFn:                 options.HeartbeatFn,
+		apiVersion:                  options.APIVersion,
 	}
 
 	if s.heartbeatFn == nil {
@@ -225,6 +236,27 @@ func NewServer(
 	return s, nil
 }
 
+// supportsPlanOnly reports whether the connected daemon understands plan-only
+// workspace builds. Older daemons ignore the flag and would apply the plan.
+func (s *server) supportsPlanOnly() bool {
+	major, minor, err := apiversion.Parse(s.apiVersion)
+	if err != nil {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= 2)
+}
+
+// supportsRefreshOnly reports whether the connected daemon understands
+// refresh-only plans. Older daemons would run a regular plan and report
+// planned changes as drift.
+func (s *server) supportsRefreshOnly() bool {
+	major, minor, err := apiversion.Parse(s.apiVersion)
+	if err != nil {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= 3)
+}
+
 // timeNow should be used when trying to get the current time for math
 // calculations regarding workspace start and stop time.
 func (s *server) timeNow() time.Time {
@@ -442,15 +474,52 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 	}
 
 	switch job.Type {
-	case database.ProvisionerJobTypeWorkspaceBuild:
-		var input WorkspaceProvisionJob
-		err = json.Unmarshal(job.Input, &input)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("unmarshal job input %q: %s", job.Input, err))
-		}
-		workspaceBuild, err := s.Database.GetWorkspaceBuildByID(ctx, input.WorkspaceBuildID)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get workspace build: %s", err))
+	case database.ProvisionerJobTypeWorkspaceBuild, database.ProvisionerJobTypeWorkspaceBuildPlan:
+		var (
+			planOnly                 = job.Type == database.ProvisionerJobTypeWorkspaceBuildPlan
+			refreshOnly              bool
+			workspaceBuild           database.WorkspaceBuild
+			workspaceBuildParameters []database.WorkspaceBuildParameter
+			logLevel                 string
+		)
+		if planOnly {
+			if !s.supportsPlanOnly() {
+				return nil, failJob(fmt.Sprintf("provisioner daemon API version %s does not support plan-only builds, upgrade the provisioner daemon", s.apiVersion))
+			}
+			var input WorkspacePlanJob
+			err = json.Unmarshal(job.Input, &input)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("unmarshal job input %q: %s", job.Input, err))
+			}
+			refreshOnly = input.RefreshOnly
+			if refreshOnly && !s.supportsRefreshOnly() {
+				return nil, failJob(fmt.Sprintf("provisioner daemon API version %s does not support drift detection, upgrade the provisioner daemon", s.apiVersion))
+			}
+			// A plan has no build of its own. It runs against the state of
+			// the latest build, exactly like the build it previews would.
+			workspaceBuild, err = s.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, input.WorkspaceID)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("get latest workspace build: %s", err))
+			}
+			workspaceBuild.TemplateVersionID = input.TemplateVersionID
+			workspaceBuild.Transition = input.Transition
+			workspaceBuildParameters = input.RichParameterValues
+			logLevel = input.LogLevel
+		} else {
+			var input WorkspaceProvisionJob
+			err = json.Unmarshal(job.Input, &input)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("unmarshal job input %q: %s", job.Input, err))
+			}
+			workspaceBuild, err = s.Database.GetWorkspaceBuildByID(ctx, input.WorkspaceBuildID)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("get workspace build: %s", err))
+			}
+			workspaceBuildParameters, err = s.Database.GetWorkspaceBuildParameters(ctx, workspaceBuild.ID)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
+			}
+			logLevel = input.LogLevel
 		}
 		workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceBuild.WorkspaceID)
 		if err != nil {
@@ -492,9 +561,11 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 		for _, group := range ownerGroups {
 			ownerGroupNames = append(ownerGroupNames, group.Name)
 		}
-		err = s.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspace.ID), []byte{})
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("publish workspace update: %s", err))
+		if !planOnly {
+			err = s.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspace.ID), []byte{})
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("publish workspace update: %s", err))
+			}
 		}
 
 		var workspaceOwnerOIDCAccessToken string
@@ -506,13 +577,15 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 		}
 
 		var sessionToken string
-		switch workspaceBuild.Transition {
-		case database.WorkspaceTransitionStart:
+		switch {
+		case planOnly:
+			// Plans must not rotate the token the running workspace uses.
+		case workspaceBuild.Transition == database.WorkspaceTransitionStart:
 			sessionToken, err = s.regenerateSessionToken(ctx, owner, workspace)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("regenerate session token: %s", err))
 			}
-		case database.WorkspaceTransitionStop, database.WorkspaceTransitionDelete:
+		case workspaceBuild.Transition == database.WorkspaceTransitionStop, workspaceBuild.Transition == database.WorkspaceTransitionDelete:
 			err = deleteSessionToken(ctx, s.Database, workspace)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("delete session token: %s", err))
@@ -524,11 +597,6 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 			return nil, failJob(fmt.Sprintf("convert workspace transition: %s", err))
 		}
 
-		workspaceBuildParameters, err := s.Database.GetWorkspaceBuildParameters(ctx, workspaceBuild.ID)
-		if err != nil {
-			return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
-		}
-
 		dbExternalAuthProviders := []database.ExternalAuthProvider{}
 		err = json.Unmarshal(templateVersion.ExternalAuthProviders, &dbExternalAuthProviders)
 		if err != nil {
@@ -605,7 +673,11 @@ func (s *server) acquireProtoJob(ctx context.Context, job database.ProvisionerJo
 					WorkspaceOwnerSshPrivateKey:   ownerSSHPrivateKey,
 					WorkspaceBuildId:              workspaceBuild.ID.String(),
 				},
-				LogLevel: input.LogLevel,
+				LogLevel:            logLevel,
+				PlanOnly:            planOnly,
+				RefreshOnly:         refreshOnly,
+				BuildTimeoutMs:      time.Duration(template.BuildTimeout).Milliseconds(),
+				CancelGracePeriodMs: time.Duration(template.BuildCancelGracePeriod).Milliseconds(),
 			},
 		}
 	case database.ProvisionerJobTypeTemplateVersionDryRun:
@@ -975,6 +1047,10 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 
 	switch jobType := failJob.Type.(type) {
 	case *proto.FailedJob_WorkspaceBuild_:
+		if job.Type == database.ProvisionerJobTypeWorkspaceBuildPlan {
+			// Plans have no build of their own and never touch the state.
+			break
+		}
 		var input WorkspaceProvisionJob
 		err = json.Unmarshal(job.Input, &input)
 		if err != nil {
@@ -1057,6 +1133,7 @@ func (s *server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*proto.
 					BuildNumber:   strconv.FormatInt(int64(build.BuildNumber), 10),
 					BuildReason:   database.BuildReason(string(build.Reason)),
 					WorkspaceID:   workspace.ID,
+					FailureReason: failJob.Error,
 				}
 
 				wriBytes, err := json.Marshal(buildResourceInfo)
@@ -1114,6 +1191,39 @@ func (s *server) notifyWorkspaceBuildFailed(ctx context.Context, workspace datab
 	}
 }
 
+// insertJobTimings stores the provisioner timings of a job. Timings are
+// metadata, so failures are logged rather than failing the job.
+func (s *server) insertJobTimings(ctx context.Context, jobID uuid.UUID, timings []*sdkproto.Timing) {
+	// nolint:exhaustruct // The other fields are set further down.
+	params := database.InsertProvisionerJobTimingsParams{
+		JobID: jobID,
+	}
+	for _, t := range timings {
+		if t.Start == nil || t.End == nil {
+			s.Logger.Warn(ctx, "timings entry has nil start or end time", slog.F("entry", t.String()))
+			continue
+		}
+
+		var stg database.ProvisionerJobTimingStage
+		if err := stg.Scan(t.Stage); err != nil {
+			s.Logger.Warn(ctx, "failed to parse timings stage, skipping", slog.F("value", t.Stage))
+			continue
+		}
+
+		params.Stage = append(params.Stage, stg)
+		params.Source = append(params.Source, t.Source)
+		params.Resource = append(params.Resource, t.Resource)
+		params.Action = append(params.Action, t.Action)
+		params.StartedAt = append(params.StartedAt, t.Start.AsTime())
+		params.EndedAt = append(params.EndedAt, t.End.AsTime())
+	}
+	_, err := s.Database.InsertProvisionerJobTimings(ctx, params)
+	if err != nil {
+		// Don't fail the transaction for non-critical data.
+		s.Logger.Warn(ctx, "failed to update provisioner job timings", slog.F("job_id", jobID), slog.Error(err))
+	}
+}
+
 // CompleteJob is triggered by a provision daemon to mark a provisioner job as completed.
 func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob) (*proto.Empty, error) {
 	ctx, span := s.startTrace(ctx, tracing.FuncName())
@@ -1285,7 +1395,16 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 		if err != nil {
 			return nil, xerrors.Errorf("complete job: %w", err)
 		}
+
+		s.insertJobTimings(ctx, jobID, jobType.TemplateImport.Timings)
 	case *proto.CompletedJob_WorkspaceBuild_:
+		if job.Type == database.ProvisionerJobTypeWorkspaceBuildPlan {
+			err = s.completeWorkspacePlanJob(ctx, job, jobType.WorkspaceBuild)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
 		var input WorkspaceProvisionJob
 		err = json.Unmarshal(job.Input, &input)
 		if err != nil {
@@ -1442,34 +1561,7 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 		}
 
 		// Insert timings outside transaction since it is metadata.
-		// nolint:exhaustruct // The other fields are set further down.
-		params := database.InsertProvisionerJobTimingsParams{
-			JobID: jobID,
-		}
-		for _, t := range completed.GetWorkspaceBuild().GetTimings() {
-			if t.Start == nil || t.End == nil {
-				s.Logger.Warn(ctx, "timings entry has nil start or end time", slog.F("entry", t.String()))
-				continue
-			}
-
-			var stg database.ProvisionerJobTimingStage
-			if err := stg.Scan(t.Stage); err != nil {
-				s.Logger.Warn(ctx, "failed to parse timings stage, skipping", slog.F("value", t.Stage))
-				continue
-			}
-
-			params.Stage = append(params.Stage, stg)
-			params.Source = append(params.Source, t.Source)
-			params.Resource = append(params.Resource, t.Resource)
-			params.Action = append(params.Action, t.Action)
-			params.StartedAt = append(params.StartedAt, t.Start.AsTime())
-			params.EndedAt = append(params.EndedAt, t.End.AsTime())
-		}
-		_, err = s.Database.InsertProvisionerJobTimings(ctx, params)
-		if err != nil {
-			// Don't fail the transaction for non-critical data.
-			s.Logger.Warn(ctx, "failed to update provisioner job timings", slog.F("job_id", jobID), slog.Error(err))
-		}
+		s.insertJobTimings(ctx, jobID, completed.GetWorkspaceBuild().GetTimings())
 
 		// audit the outcome of the workspace build
 		if getWorkspaceError == nil {
@@ -1578,6 +1670,136 @@ func (s *server) CompleteJob(ctx context.Context, completed *proto.CompletedJob)
 	return &proto.Empty{}, nil
 }
 
+// completeWorkspacePlanJob stores the resource changes of a plan-only
+// workspace build and marks the job as completed. Plans don't have a build of
+// their own, so no resources, agents or state are persisted. For drift checks
+// the changes are recorded as the latest drift of the workspace.
+func (s *server) completeWorkspacePlanJob(ctx context.Context, job database.ProvisionerJob, completed *proto.CompletedJob_WorkspaceBuild) error {
+	var input WorkspacePlanJob
+	err := json.Unmarshal(job.Input, &input)
+	if err != nil {
+		return xerrors.Errorf("unmarshal job data: %w", err)
+	}
+
+	// nolint:exhaustruct // The other fields are set further down.
+	params := database.InsertProvisionerJobResourceChangesParams{
+		JobID: job.ID,
+	}
+	for _, change := range completed.ResourceChanges {
+		action := database.ResourceChangeAction(change.Action)
+		if !action.Valid() {
+			s.Logger.Warn(ctx, "unknown resource change action, skipping",
+				slog.F("job_id", job.ID),
+				slog.F("address", change.Address),
+				slog.F("action", change.Action))
+			continue
+		}
+		params.Address = append(params.Address, change.Address)
+		params.Type = append(params.Type, change.Type)
+		params.Name = append(params.Name, change.Name)
+		params.Action = append(params.Action, action)
+	}
+
+	var previousDrift []database.ProvisionerJobResourceChange
+	err = s.Database.InTx(func(db database.Store) error {
+		_, err := db.InsertProvisionerJobResourceChanges(ctx, params)
+		if err != nil {
+			return xerrors.Errorf("insert resource changes: %w", err)
+		}
+		err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:        job.ID,
+			UpdatedAt: dbtime.Now(),
+			CompletedAt: sql.NullTime{
+				Time:  dbtime.Now(),
+				Valid: true,
+			},
+			Error:     sql.NullString{},
+			ErrorCode: sql.NullString{},
+		})
+		if err != nil {
+			return xerrors.Errorf("update provisioner job: %w", err)
+		}
+		if !input.RefreshOnly {
+			return nil
+		}
+
+		check, err := db.GetWorkspaceDriftCheckByWorkspaceID(ctx, input.WorkspaceID)
+		if xerrors.Is(err, sql.ErrNoRows) {
+			// The drift check was not scheduled by the drift detector, so
+			// there's nothing to record it on.
+			return nil
+		}
+		if err != nil {
+			return xerrors.Errorf("get workspace drift check: %w", err)
+		}
+		if check.ResultJobID.Valid {
+			previousDrift, err = db.GetProvisionerJobResourceChangesByJobID(ctx, check.ResultJobID.UUID)
+			if err != nil {
+				return xerrors.Errorf("get previous drift: %w", err)
+			}
+		}
+		err = db.UpdateWorkspaceDriftCheckResult(ctx, database.UpdateWorkspaceDriftCheckResultParams{
+			WorkspaceID: input.WorkspaceID,
+			ResultJobID: uuid.NullUUID{UUID: job.ID, Valid: true},
+			CheckedAt:   sql.NullTime{Time: dbtime.Now(), Valid: true},
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace drift check result: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return xerrors.Errorf("complete workspace plan job: %w", err)
+	}
+	s.Logger.Debug(ctx, "marked workspace plan job as completed", slog.F("job_id", job.ID))
+
+	s.insertJobTimings(ctx, job.ID, completed.Timings)
+
+	if input.RefreshOnly && len(params.Address) > 0 && !sameDrift(previousDrift, params.Address, params.Action) {
+		s.notifyWorkspaceDrifted(ctx, input.WorkspaceID, params.Address)
+	}
+	return nil
+}
+
+// sameDrift reports whether a drift check found the same resources with the
+// same changes as the previous one, so owners aren't notified of drift they
+// already know about.
+func sameDrift(previous []database.ProvisionerJobResourceChange, addresses []string, actions []database.ResourceChangeAction) bool {
+	if len(previous) != len(addresses) {
+		return false
+	}
+	seen := make(map[string]database.ResourceChangeAction, len(previous))
+	for _, change := range previous {
+		seen[change.Address] = change.Action
+	}
+	for i, address := range addresses {
+		action, ok := seen[address]
+		if !ok || action != actions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *server) notifyWorkspaceDrifted(ctx context.Context, workspaceID uuid.UUID, addresses []string) {
+	workspace, err := s.Database.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		s.Logger.Warn(ctx, "failed to get workspace for drift notification", slog.F("workspace_id", workspaceID), slog.Error(err))
+		return
+	}
+
+	if _, err := s.NotificationsEnqueuer.Enqueue(ctx, workspace.OwnerID, notifications.TemplateWorkspaceDrifted,
+		map[string]string{
+			"name":      workspace.Name,
+			"resources": strings.Join(addresses, ", "),
+		}, "provisionerdserver",
+		// Associate this notification with all the related entities.
+		workspace.ID, workspace.OwnerID, workspace.TemplateID, workspace.OrganizationID,
+	); err != nil {
+		s.Logger.Warn(ctx, "failed to notify of workspace drift", slog.Error(err))
+	}
+}
+
 func (s *server) notifyWorkspaceDeleted(ctx context.Context, workspace database.Workspace, build database.WorkspaceBuild) {
 	var reason string
 	initiator := build.InitiatorByUsername
@@ -2062,6 +2284,17 @@ type WorkspaceProvisionJob struct {
 	LogLevel         string    `json:"log_level,omitempty"`
 }
 
+// WorkspacePlanJob is the payload for the "workspace_build_plan" job type.
+type WorkspacePlanJob struct {
+	WorkspaceID         uuid.UUID                          `json:"workspace_id"`
+	TemplateVersionID   uuid.UUID                          `json:"template_version_id"`
+	Transition          database.WorkspaceTransition       `json:"transition"`
+	RichParameterValues []database.WorkspaceBuildParameter `json:"rich_parameter_values"`
+	LogLevel            string                             `json:"log_level,omitempty"`
+	// RefreshOnly is set for drift checks.
+	RefreshOnly bool `json:"refresh_only,omitempty"`
+}
+
 // TemplateVersionDryRunJob is the payload for the "template_version_dry_run" job type.
 type TemplateVersionDryRunJob struct {
 	TemplateVersionID   uuid.UUID                          `json:"template_version_id"`
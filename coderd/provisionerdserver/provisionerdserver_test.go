@@ -157,6 +157,7 @@ func TestAcquireJob(t *testing.T) {
 				Provisioner:    database.ProvisionerTypeEcho,
 				StorageMethod:  database.ProvisionerStorageMethodFile,
 				Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+				Priority:       database.ProvisionerJobPriorityInteractive,
 			})
 			require.NoError(t, err)
 			_, err = tc.acquire(ctx, srv)
@@ -557,6 +558,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.UpdateJob(ctx, &proto.UpdateJobRequest{
@@ -573,6 +575,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -594,6 +597,7 @@ func TestUpdateJob(t *testing.T) {
 			ID:            uuid.New(),
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityTemplateImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 		})
 		require.NoError(t, err)
@@ -816,6 +820,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityTemplateImport,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -838,6 +843,7 @@ func TestFailJob(t *testing.T) {
 			ID:            uuid.New(),
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityTemplateImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 		})
 		require.NoError(t, err)
@@ -890,6 +896,7 @@ func TestFailJob(t *testing.T) {
 			Input:         input,
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 		})
 		require.NoError(t, err)
@@ -974,6 +981,7 @@ func TestCompleteJob(t *testing.T) {
 			Provisioner:    database.ProvisionerTypeEcho,
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 			OrganizationID: pd.OrganizationID,
 		})
 		require.NoError(t, err)
@@ -1009,6 +1017,7 @@ func TestCompleteJob(t *testing.T) {
 			Input:          []byte(`{"template_version_id": "` + versionID.String() + `"}`),
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 			OrganizationID: pd.OrganizationID,
 		})
 		require.NoError(t, err)
@@ -1067,6 +1076,7 @@ func TestCompleteJob(t *testing.T) {
 			Input:          []byte(`{"template_version_id": "` + versionID.String() + `"}`),
 			StorageMethod:  database.ProvisionerStorageMethodFile,
 			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:       database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -1370,6 +1380,7 @@ func TestCompleteJob(t *testing.T) {
 			ID:            uuid.New(),
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 		})
 		require.NoError(t, err)
@@ -1804,6 +1815,100 @@ func TestNotifications(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Workspace drifted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		notifEnq := &testutil.FakeNotificationsEnqueuer{}
+
+		srv, db, ps, pd := setup(t, false, &overrides{
+			notificationEnqueuer: notifEnq,
+		})
+
+		user := dbgen.User(t, db, database.User{})
+		template := dbgen.Template(t, db, database.Template{
+			Name:           "template",
+			Provisioner:    database.ProvisionerTypeEcho,
+			OrganizationID: pd.OrganizationID,
+		})
+		file := dbgen.File(t, db, database.File{CreatedBy: user.ID})
+		workspace := dbgen.Workspace(t, db, database.Workspace{
+			TemplateID:     template.ID,
+			OwnerID:        user.ID,
+			OrganizationID: pd.OrganizationID,
+		})
+
+		// checkDrift runs a drift check that finds the given resources.
+		checkDrift := func(addresses ...string) uuid.UUID {
+			job := dbgen.ProvisionerJob(t, db, ps, database.ProvisionerJob{
+				FileID: file.ID,
+				Type:   database.ProvisionerJobTypeWorkspaceBuildPlan,
+				Input: must(json.Marshal(provisionerdserver.WorkspacePlanJob{
+					WorkspaceID: workspace.ID,
+					Transition:  database.WorkspaceTransitionStart,
+					RefreshOnly: true,
+				})),
+				OrganizationID: pd.OrganizationID,
+			})
+			_, err := db.UpsertWorkspaceDriftCheck(ctx, database.UpsertWorkspaceDriftCheckParams{
+				WorkspaceID: workspace.ID,
+				JobID:       job.ID,
+				ScheduledAt: dbtime.Now(),
+			})
+			require.NoError(t, err)
+			_, err = db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+				StartedAt:      sql.NullTime{Time: dbtime.Now(), Valid: true},
+				OrganizationID: pd.OrganizationID,
+				WorkerID: uuid.NullUUID{
+					UUID:  pd.ID,
+					Valid: true,
+				},
+				Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			})
+			require.NoError(t, err)
+
+			changes := make([]*sdkproto.ResourceChange, 0, len(addresses))
+			for _, address := range addresses {
+				changes = append(changes, &sdkproto.ResourceChange{
+					Address: address,
+					Type:    "aws_instance",
+					Name:    "example",
+					Action:  string(database.ResourceChangeActionUpdate),
+				})
+			}
+			_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+				JobId: job.ID.String(),
+				Type: &proto.CompletedJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.CompletedJob_WorkspaceBuild{
+						ResourceChanges: changes,
+					},
+				},
+			})
+			require.NoError(t, err)
+			return job.ID
+		}
+
+		jobID := checkDrift("aws_instance.example")
+		check, err := db.GetWorkspaceDriftCheckByWorkspaceID(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, jobID, check.ResultJobID.UUID)
+		require.True(t, check.CheckedAt.Valid)
+
+		require.Len(t, notifEnq.Sent, 1)
+		require.Equal(t, notifications.TemplateWorkspaceDrifted, notifEnq.Sent[0].TemplateID)
+		require.Equal(t, user.ID, notifEnq.Sent[0].UserID)
+		require.Contains(t, notifEnq.Sent[0].Targets, workspace.ID)
+		require.Equal(t, "aws_instance.example", notifEnq.Sent[0].Labels["resources"])
+
+		// The same drift again is not worth another notification.
+		checkDrift("aws_instance.example")
+		require.Len(t, notifEnq.Sent, 1)
+
+		// Neither is a workspace that didn't drift.
+		checkDrift()
+		require.Len(t, notifEnq.Sent, 1)
+	})
 }
 
 type overrides struct {
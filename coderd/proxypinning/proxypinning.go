@@ -0,0 +1,122 @@
+package proxypinning
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"github.com/coder/coder/v2/coderd/database"
+)
+
+// Pinner restricts which workspace proxies may serve apps for a workspace and
+// which DERP regions its agents may relay through.
+type Pinner interface {
+	// ValidateWorkspaceProxyIDs returns an error if the allow-list cannot be
+	// stored on a template or organization.
+	ValidateWorkspaceProxyIDs(ctx context.Context, ids []uuid.UUID) error
+	// ValidateDERPRegionIDs returns an error if the allow-list cannot be
+	// stored on a template or organization.
+	ValidateDERPRegionIDs(ctx context.Context, ids []int) error
+	// WorkspacePins returns the restrictions that apply to the workspace.
+	WorkspacePins(ctx context.Context, workspace database.Workspace) (Pins, error)
+}
+
+type AGPLPinner struct{}
+
+func (AGPLPinner) ValidateWorkspaceProxyIDs(_ context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return xerrors.New("Restricting workspace proxies is an enterprise feature that is not enabled.")
+}
+
+func (AGPLPinner) ValidateDERPRegionIDs(_ context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return xerrors.New("Restricting DERP regions is an enterprise feature that is not enabled.")
+}
+
+// WorkspacePins never restricts anything, even if restrictions were stored
+// while the feature was licensed.
+func (AGPLPinner) WorkspacePins(_ context.Context, _ database.Workspace) (Pins, error) {
+	return Pins{}, nil
+}
+
+var DefaultPinner Pinner = AGPLPinner{}
+
+// Pins are the workspace proxy and DERP region allow-lists of a template and
+// its organization. A proxy or region must be allowed by both, and an empty
+// list allows everything.
+type Pins struct {
+	TemplateWorkspaceProxyIDs     []uuid.UUID
+	TemplateDERPRegionIDs         []int32
+	OrganizationWorkspaceProxyIDs []uuid.UUID
+	OrganizationDERPRegionIDs     []int32
+}
+
+// New returns the pins that apply to workspaces of the template.
+func New(template database.Template, organization database.Organization) Pins {
+	return Pins{
+		TemplateWorkspaceProxyIDs:     template.AllowedWorkspaceProxyIDs,
+		TemplateDERPRegionIDs:         template.AllowedDERPRegionIDs,
+		OrganizationWorkspaceProxyIDs: organization.AllowedWorkspaceProxyIDs,
+		OrganizationDERPRegionIDs:     organization.AllowedDERPRegionIDs,
+	}
+}
+
+// AllowsWorkspaceProxy returns true if the proxy may serve apps. The primary
+// proxy is identified by the deployment ID.
+func (p Pins) AllowsWorkspaceProxy(id uuid.UUID) bool {
+	return allows(p.TemplateWorkspaceProxyIDs, id) && allows(p.OrganizationWorkspaceProxyIDs, id)
+}
+
+// AllowsDERPRegion returns true if agents may relay through the region.
+func (p Pins) AllowsDERPRegion(id int) bool {
+	return allows(p.TemplateDERPRegionIDs, int32(id)) && allows(p.OrganizationDERPRegionIDs, int32(id))
+}
+
+// RestrictsDERPRegions returns true if any DERP region is disallowed.
+func (p Pins) RestrictsDERPRegions() bool {
+	return len(p.TemplateDERPRegionIDs) > 0 || len(p.OrganizationDERPRegionIDs) > 0
+}
+
+// FilterDERPMap returns a copy of the DERP map without the regions that are
+// not allowed. The map is returned as-is if no region is restricted.
+func (p Pins) FilterDERPMap(derpMap *tailcfg.DERPMap) *tailcfg.DERPMap {
+	if derpMap == nil || !p.RestrictsDERPRegions() {
+		return derpMap
+	}
+
+	filtered := derpMap.Clone()
+	for id := range filtered.Regions {
+		if !p.AllowsDERPRegion(id) {
+			delete(filtered.Regions, id)
+		}
+	}
+	if filtered.HomeParams != nil {
+		for id := range filtered.HomeParams.RegionScore {
+			if !p.AllowsDERPRegion(id) {
+				delete(filtered.HomeParams.RegionScore, id)
+			}
+		}
+	}
+	return filtered
+}
+
+// DERPRegionIDs converts DERP region IDs from the API to the type they are
+// stored as. The returned list is never nil.
+func DERPRegionIDs(ids []int) []int32 {
+	converted := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		converted = append(converted, int32(id))
+	}
+	return converted
+}
+
+func allows[T comparable](allowed []T, id T) bool {
+	return len(allowed) == 0 || slices.Contains(allowed, id)
+}
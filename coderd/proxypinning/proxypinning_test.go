@@ -0,0 +1,127 @@
+package proxypinning_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/proxypinning"
+)
+
+func TestAGPLPinner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pinner := proxypinning.AGPLPinner{}
+	require.NoError(t, pinner.ValidateWorkspaceProxyIDs(ctx, nil))
+	require.NoError(t, pinner.ValidateDERPRegionIDs(ctx, []int{}))
+	require.Error(t, pinner.ValidateWorkspaceProxyIDs(ctx, []uuid.UUID{uuid.New()}))
+	require.Error(t, pinner.ValidateDERPRegionIDs(ctx, []int{1}))
+
+	pins, err := pinner.WorkspacePins(ctx, database.Workspace{})
+	require.NoError(t, err)
+	require.False(t, pins.RestrictsDERPRegions())
+	require.True(t, pins.AllowsWorkspaceProxy(uuid.New()))
+}
+
+func TestPins(t *testing.T) {
+	t.Parallel()
+
+	var (
+		primary = uuid.New()
+		eu      = uuid.New()
+		us      = uuid.New()
+	)
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		pins := proxypinning.New(database.Template{}, database.Organization{})
+		require.True(t, pins.AllowsWorkspaceProxy(primary))
+		require.True(t, pins.AllowsDERPRegion(999))
+		require.False(t, pins.RestrictsDERPRegions())
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		t.Parallel()
+
+		pins := proxypinning.New(database.Template{
+			AllowedWorkspaceProxyIDs: []uuid.UUID{eu},
+			AllowedDERPRegionIDs:     []int32{10},
+		}, database.Organization{})
+		require.True(t, pins.AllowsWorkspaceProxy(eu))
+		require.False(t, pins.AllowsWorkspaceProxy(primary))
+		require.True(t, pins.AllowsDERPRegion(10))
+		require.False(t, pins.AllowsDERPRegion(11))
+		require.True(t, pins.RestrictsDERPRegions())
+	})
+
+	t.Run("TemplateAndOrganization", func(t *testing.T) {
+		t.Parallel()
+
+		// Both allow-lists must allow the proxy or region.
+		pins := proxypinning.New(database.Template{
+			AllowedWorkspaceProxyIDs: []uuid.UUID{eu, us},
+			AllowedDERPRegionIDs:     []int32{10, 11},
+		}, database.Organization{
+			AllowedWorkspaceProxyIDs: []uuid.UUID{eu, primary},
+			AllowedDERPRegionIDs:     []int32{10, 999},
+		})
+		require.True(t, pins.AllowsWorkspaceProxy(eu))
+		require.False(t, pins.AllowsWorkspaceProxy(us))
+		require.False(t, pins.AllowsWorkspaceProxy(primary))
+		require.True(t, pins.AllowsDERPRegion(10))
+		require.False(t, pins.AllowsDERPRegion(11))
+		require.False(t, pins.AllowsDERPRegion(999))
+	})
+}
+
+func TestPins_FilterDERPMap(t *testing.T) {
+	t.Parallel()
+
+	derpMap := &tailcfg.DERPMap{
+		HomeParams: &tailcfg.DERPHomeParams{
+			RegionScore: map[int]float64{
+				1:  1,
+				10: 2,
+			},
+		},
+		Regions: map[int]*tailcfg.DERPRegion{
+			1:  {RegionID: 1, RegionCode: "us"},
+			10: {RegionID: 10, RegionCode: "eu"},
+		},
+	}
+
+	t.Run("Unrestricted", func(t *testing.T) {
+		t.Parallel()
+
+		pins := proxypinning.Pins{}
+		require.Same(t, derpMap, pins.FilterDERPMap(derpMap))
+		require.Nil(t, pins.FilterDERPMap(nil))
+	})
+
+	t.Run("Restricted", func(t *testing.T) {
+		t.Parallel()
+
+		pins := proxypinning.Pins{TemplateDERPRegionIDs: []int32{10}}
+		filtered := pins.FilterDERPMap(derpMap)
+		require.Len(t, filtered.Regions, 1)
+		require.Contains(t, filtered.Regions, 10)
+		require.Equal(t, map[int]float64{10: 2}, filtered.HomeParams.RegionScore)
+
+		// The original map must not be modified.
+		require.Len(t, derpMap.Regions, 2)
+		require.Len(t, derpMap.HomeParams.RegionScore, 2)
+	})
+}
+
+func TestDERPRegionIDs(t *testing.T) {
+	t.Parallel()
+
+	require.NotNil(t, proxypinning.DERPRegionIDs(nil))
+	require.Equal(t, []int32{1, 10}, proxypinning.DERPRegionIDs([]int{1, 10}))
+}
@@ -1053,6 +1053,49 @@ func TestAuthorizeScope(t *testing.T) {
 			{resource: ResourceWorkspace.InOrg(unusedID).WithOwner("not-me"), actions: []policy.Action{policy.ActionCreate}, allow: false},
 		},
 	)
+
+	// This scope is built from the scope catalog.
+	templateID := uuid.New()
+	user = Subject{
+		ID: "me",
+		Roles: Roles{
+			must(RoleByName(RoleMember())),
+			must(RoleByName(ScopedRoleOrgMember(defOrg))),
+		},
+		Scope: must(ScopeSet{
+			Names:       []ScopeName{ScopeWorkspaceRead, ScopeWorkspaceStop},
+			AllowIDList: []string{workspaceID.String(), templateID.String()},
+		}.Expand()),
+	}
+
+	testAuthorize(t, "User_ScopeSet", user,
+		// Actions outside of the scopes are never allowed.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []policy.Action{policy.ActionCreate, policy.ActionDelete, policy.ActionWorkspaceStart, policy.ActionSSH}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner(user.ID)},
+			{resource: ResourceWorkspace.InOrg(defOrg).WithOwner(user.ID)},
+		}),
+		// Resources outside of the allow list are never allowed.
+		cases(func(c authTestCase) authTestCase {
+			c.actions = []policy.Action{policy.ActionRead, policy.ActionWorkspaceStop}
+			c.allow = false
+			return c
+		}, []authTestCase{
+			{resource: ResourceWorkspace.WithID(uuid.New()).InOrg(defOrg).WithOwner(user.ID)},
+			{resource: ResourceTemplate.WithID(uuid.New()).InOrg(defOrg)},
+		}),
+		// Allowed by scope:
+		[]authTestCase{
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner(user.ID), actions: []policy.Action{policy.ActionRead, policy.ActionWorkspaceStop}, allow: true},
+			{resource: ResourceTemplate.WithID(templateID).InOrg(defOrg).WithACLUserList(map[string][]policy.Action{user.ID: {policy.WildcardSymbol}}), actions: []policy.Action{policy.ActionRead}, allow: true},
+			{resource: ResourceTemplate.WithID(templateID).InOrg(defOrg).WithACLUserList(map[string][]policy.Action{user.ID: {policy.WildcardSymbol}}), actions: []policy.Action{policy.ActionUpdate}, allow: false},
+			// The scope will return true, but the user perms return false for resources not owned by the user.
+			{resource: ResourceWorkspace.WithID(workspaceID).InOrg(defOrg).WithOwner("not-me"), actions: []policy.Action{policy.ActionRead}, allow: false},
+		},
+	)
 }
 
 // cases applies a given function to all test cases. This makes generalities easier to create.
@@ -2,6 +2,8 @@ package rbac
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -52,7 +54,85 @@ const (
 	ScopeApplicationConnect ScopeName = "application_connect"
 )
 
-// TODO: Support passing in scopeID list for allowlisting resources.
+// Scopes in the scope catalog. They are named "<resource>:<action>" and can be
+// combined into a ScopeSet.
+const (
+	ScopeWorkspaceRead   ScopeName = "workspace:read"
+	ScopeWorkspaceCreate ScopeName = "workspace:create"
+	ScopeWorkspaceStart  ScopeName = "workspace:start"
+	ScopeWorkspaceStop   ScopeName = "workspace:stop"
+	ScopeWorkspaceDelete ScopeName = "workspace:delete"
+	ScopeWorkspaceSSH    ScopeName = "workspace:ssh"
+	ScopeTemplateRead    ScopeName = "template:read"
+	ScopeTemplateUpdate  ScopeName = "template:update"
+	ScopeUserRead        ScopeName = "user:read"
+)
+
+// scopeCatalog contains the permissions of every scope that a ScopeSet can be
+// built from. Workspace scopes include reading templates, as workspaces cannot
+// be built or displayed without their template. Every scope includes reading
+// users, so that a key can always look up the user that owns it.
+var scopeCatalog = map[ScopeName]map[string][]policy.Action{
+	ScopeWorkspaceRead: {
+		ResourceWorkspace.Type: {policy.ActionRead},
+		ResourceTemplate.Type:  {policy.ActionRead},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeWorkspaceCreate: {
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionCreate, policy.ActionUpdate, policy.ActionWorkspaceStart},
+		ResourceTemplate.Type:  {policy.ActionRead, policy.ActionUse},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeWorkspaceStart: {
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionUpdate, policy.ActionWorkspaceStart},
+		ResourceTemplate.Type:  {policy.ActionRead},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeWorkspaceStop: {
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionUpdate, policy.ActionWorkspaceStop},
+		ResourceTemplate.Type:  {policy.ActionRead},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeWorkspaceDelete: {
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionUpdate, policy.ActionDelete},
+		ResourceTemplate.Type:  {policy.ActionRead},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeWorkspaceSSH: {
+		ResourceWorkspace.Type: {policy.ActionRead, policy.ActionSSH, policy.ActionApplicationConnect},
+		ResourceTemplate.Type:  {policy.ActionRead},
+		ResourceUser.Type:      {policy.ActionRead},
+	},
+	ScopeTemplateRead: {
+		ResourceTemplate.Type: {policy.ActionRead},
+		ResourceUser.Type:     {policy.ActionRead},
+	},
+	ScopeTemplateUpdate: {
+		ResourceTemplate.Type: {policy.ActionRead, policy.ActionUpdate},
+		ResourceUser.Type:     {policy.ActionRead},
+	},
+	ScopeUserRead: {
+		ResourceUser.Type: {policy.ActionRead},
+	},
+}
+
+// CatalogScopes returns the names of the scopes that a ScopeSet can be built
+// from, sorted by name.
+func CatalogScopes() []ScopeName {
+	names := make([]ScopeName, 0, len(scopeCatalog))
+	for name := range scopeCatalog {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// IsCatalogScope returns true if the scope can be used in a ScopeSet.
+func IsCatalogScope(name ScopeName) bool {
+	_, ok := scopeCatalog[name]
+	return ok
+}
+
 var builtinScopes = map[ScopeName]Scope{
 	// ScopeAll is a special scope that allows access to all resources. During
 	// authorize checks it is usually not used directly and skips scope checks.
@@ -118,6 +198,61 @@ func (s Scope) Name() RoleIdentifier {
 	return s.Role.Identifier
 }
 
+// ScopeSet is a scope built from scopes in the scope catalog. It has the
+// combined permissions of every scope in Names, and can only affect resources
+// in the AllowIDList. An empty AllowIDList allows every resource.
+type ScopeSet struct {
+	Names       []ScopeName `json:"names"`
+	AllowIDList []string    `json:"allow_list"`
+}
+
+func (s ScopeSet) Expand() (Scope, error) {
+	if len(s.Names) == 0 {
+		return Scope{}, xerrors.New("scope set must contain at least one scope")
+	}
+
+	merged := map[string][]policy.Action{}
+	for _, name := range s.Names {
+		perms, ok := scopeCatalog[name]
+		if !ok {
+			return Scope{}, xerrors.Errorf("no scope named %q in the scope catalog", name)
+		}
+		for resource, actions := range perms {
+			for _, action := range actions {
+				if !slices.Contains(merged[resource], action) {
+					merged[resource] = append(merged[resource], action)
+				}
+			}
+		}
+	}
+
+	allowList := []string{policy.WildcardSymbol}
+	if len(s.AllowIDList) > 0 {
+		// Objects that are being created have no ID yet, the empty string
+		// lets them through so long as the scope allows creating them.
+		allowList = append([]string{""}, s.AllowIDList...)
+	}
+
+	return Scope{
+		Role: Role{
+			Identifier:  RoleIdentifier{Name: fmt.Sprintf("Scope_%s", s.Name().Name)},
+			DisplayName: "Scopes " + s.Name().Name,
+			Site:        Permissions(merged),
+			Org:         map[string][]Permission{},
+			User:        []Permission{},
+		},
+		AllowIDList: allowList,
+	}, nil
+}
+
+func (s ScopeSet) Name() RoleIdentifier {
+	names := make([]string, 0, len(s.Names))
+	for _, name := range s.Names {
+		names = append(names, string(name))
+	}
+	return RoleIdentifier{Name: strings.Join(names, ",")}
+}
+
 func ExpandScope(scope ScopeName) (Scope, error) {
 	role, ok := builtinScopes[scope]
 	if !ok {
@@ -0,0 +1,276 @@
+package rolegrant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/notifications"
+)
+
+// acquireLockError is returned when the expirer fails to acquire a lock and
+// skips a request.
+type acquireLockError struct{}
+
+// Error implements error.
+func (acquireLockError) Error() string {
+	return "lock is held by another client"
+}
+
+// grantIneligibleError is returned when a request no longer holds a role that
+// should be removed.
+type grantIneligibleError struct {
+	Err error
+}
+
+// Error implements error.
+func (e grantIneligibleError) Error() string {
+	return fmt.Sprintf("role grant is no longer active: %s", e.Err)
+}
+
+// Expirer periodically removes the roles of approved role grant requests once
+// their lifetime has passed.
+type Expirer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db       database.Store
+	log      slog.Logger
+	tick     <-chan time.Time
+	stats    chan<- Stats
+	auditor  *atomic.Pointer[audit.Auditor]
+	enqueuer notifications.Enqueuer
+}
+
+// Stats contains statistics about the last run of the expirer.
+type Stats struct {
+	// Expired contains the IDs of the requests whose role was removed.
+	Expired []uuid.UUID
+	// Error is the fatal error that occurred during the last run of the
+	// expirer, if any.
+	Error error
+}
+
+// New returns a new role grant expirer.
+func New(ctx context.Context, db database.Store, log slog.Logger, tick <-chan time.Time) *Expirer {
+	// Removing a role requires being able to assign it, which only the system
+	// actor can do for every built-in and custom role.
+	//nolint:gocritic // Role grant expirer needs to remove any role.
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(ctx))
+	e := &Expirer{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		db:     db,
+		log:    log,
+		tick:   tick,
+		stats:  nil,
+	}
+	return e
+}
+
+// WithStatsChannel will cause Expirer to push a Stats to ch after every tick.
+// This push is blocking, so if ch is not read, the expirer will hang. This
+// should only be used in tests.
+func (e *Expirer) WithStatsChannel(ch chan<- Stats) *Expirer {
+	e.stats = ch
+	return e
+}
+
+// WithAuditor will cause Expirer to write an audit log entry for every request
+// it expires.
+func (e *Expirer) WithAuditor(auditor *atomic.Pointer[audit.Auditor]) *Expirer {
+	e.auditor = auditor
+	return e
+}
+
+// WithNotificationsEnqueuer will cause Expirer to notify the requester when
+// their role is removed.
+func (e *Expirer) WithNotificationsEnqueuer(enqueuer notifications.Enqueuer) *Expirer {
+	e.enqueuer = enqueuer
+	return e
+}
+
+// Start will cause the expirer to remove expired roles on every tick from its
+// channel. It will stop when its context is Done, or when its channel is
+// closed.
+//
+// Start should only be called once.
+func (e *Expirer) Start() {
+	go func() {
+		defer close(e.done)
+		defer e.cancel()
+
+		for {
+			select {
+			case <-e.ctx.Done():
+				return
+			case t, ok := <-e.tick:
+				if !ok {
+					return
+				}
+				stats := e.run(t)
+				if stats.Error != nil {
+					e.log.Warn(e.ctx, "error running role grant expirer once", slog.Error(stats.Error))
+				}
+				if e.stats != nil {
+					select {
+					case <-e.ctx.Done():
+						return
+					case e.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the expirer is stopped.
+func (e *Expirer) Wait() {
+	<-e.done
+}
+
+// Close will stop the expirer.
+func (e *Expirer) Close() {
+	e.cancel()
+	<-e.done
+}
+
+func (e *Expirer) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		Expired: []uuid.UUID{},
+		Error:   nil,
+	}
+
+	reqs, err := e.db.GetExpiredRoleGrantRequests(ctx, t)
+	if err != nil {
+		stats.Error = xerrors.Errorf("get expired role grant requests: %w", err)
+		return stats
+	}
+
+	for _, req := range reqs {
+		log := e.log.With(
+			slog.F("role_grant_request_id", req.ID),
+			slog.F("user_id", req.UserID),
+			slog.F("role", req.RoleName),
+		)
+
+		old, expired, err := expire(ctx, e.db, req.ID, t)
+		if err != nil {
+			if !(xerrors.As(err, &acquireLockError{}) || xerrors.As(err, &grantIneligibleError{})) {
+				log.Error(ctx, "error expiring role grant request", slog.Error(err))
+			}
+			continue
+		}
+
+		log.Info(ctx, "removed expired role grant")
+		stats.Expired = append(stats.Expired, req.ID)
+		e.audit(ctx, log, old, expired)
+		e.notify(ctx, log, expired)
+	}
+
+	return stats
+}
+
+// expire removes the role of the request and marks it as expired.
+func expire(ctx context.Context, db database.Store, id uuid.UUID, t time.Time) (old database.RoleGrantRequest, expired database.RoleGrantRequest, err error) {
+	err = db.InTx(func(db database.Store) error {
+		locked, err := db.TryAcquireLock(ctx, database.GenLockID(fmt.Sprintf("role-grant-expirer:%s", id)))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			// This error is ignored.
+			return acquireLockError{}
+		}
+
+		// Refetch the request while we hold the lock, another replica or an
+		// approver may have ended it in the meantime.
+		old, err = db.GetRoleGrantRequestByID(ctx, id)
+		if err != nil {
+			return xerrors.Errorf("get role grant request: %w", err)
+		}
+		if old.Status != database.RoleGrantStatusApproved {
+			return grantIneligibleError{Err: xerrors.Errorf("request is %s", old.Status)}
+		}
+		if !old.ExpiresAt.Valid || old.ExpiresAt.Time.After(t) {
+			return grantIneligibleError{Err: xerrors.New("request has not expired")}
+		}
+
+		err = Remove(ctx, db, old)
+		if err != nil {
+			return xerrors.Errorf("remove role: %w", err)
+		}
+
+		expired, err = db.UpdateRoleGrantRequestByID(ctx, database.UpdateRoleGrantRequestByIDParams{
+			ID:         old.ID,
+			Status:     database.RoleGrantStatusExpired,
+			FromStatus: database.RoleGrantStatusApproved,
+			ReviewedBy: old.ReviewedBy,
+			ReviewedAt: old.ReviewedAt,
+			ExpiresAt:  old.ExpiresAt,
+			UpdatedAt:  dbtime.Time(t),
+		})
+		if err != nil {
+			return xerrors.Errorf("update role grant request: %w", err)
+		}
+		return nil
+	}, nil)
+	return old, expired, err
+}
+
+func (e *Expirer) audit(ctx context.Context, log slog.Logger, old, expired database.RoleGrantRequest) {
+	if e.auditor == nil {
+		return
+	}
+	auditor := e.auditor.Load()
+	if auditor == nil {
+		return
+	}
+
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.RoleGrantRequest]{
+		Audit:          *auditor,
+		Log:            log,
+		UserID:         expired.UserID,
+		OrganizationID: expired.OrganizationID.UUID,
+		RequestID:      expired.ID,
+		Action:         database.AuditActionWrite,
+		Old:            old,
+		New:            expired,
+		Status:         http.StatusOK,
+	})
+}
+
+func (e *Expirer) notify(ctx context.Context, log slog.Logger, expired database.RoleGrantRequest) {
+	if e.enqueuer == nil {
+		return
+	}
+
+	_, err := e.enqueuer.Enqueue(ctx, expired.UserID, notifications.TemplateRoleGrantEnded,
+		map[string]string{
+			"role":   db2sdk.RoleGrantRequest(expired).Role.String(),
+			"reason": "expired",
+		}, "role-grant-expirer",
+		// Associate this notification with the request.
+		expired.ID,
+	)
+	if err != nil {
+		log.Warn(ctx, "failed to notify of expired role grant", slog.Error(err))
+	}
+}
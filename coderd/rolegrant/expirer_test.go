@@ -0,0 +1,179 @@
+package rolegrant_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbgen"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/notifications"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rolegrant"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestExpirerNoRequests(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan rolegrant.Stats)
+	)
+
+	expirer := rolegrant.New(ctx, db, log, tickCh).WithStatsChannel(statsCh)
+	expirer.Start()
+	tickCh <- time.Now()
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Expired)
+
+	expirer.Close()
+	expirer.Wait()
+}
+
+func TestExpirerSiteRole(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = testutil.Context(t, testutil.WaitLong)
+		rawDB, _ = dbtestutil.NewDB(t)
+		log      = slogtest.Make(t, nil)
+		db       = dbauthz.New(rawDB, rbac.NewAuthorizer(prometheus.NewRegistry()), log, coderdtest.AccessControlStorePointer())
+		tickCh   = make(chan time.Time)
+		statsCh  = make(chan rolegrant.Stats)
+		enqueuer = &testutil.FakeNotificationsEnqueuer{}
+		now      = dbtime.Now()
+	)
+
+	reviewer := dbgen.User(t, rawDB, database.User{})
+	user := dbgen.User(t, rawDB, database.User{RBACRoles: []string{rbac.RoleTemplateAdmin().Name}})
+	expired := dbgen.RoleGrantRequest(t, rawDB, database.RoleGrantRequest{
+		UserID:     user.ID,
+		RoleName:   rbac.RoleTemplateAdmin().Name,
+		Status:     database.RoleGrantStatusApproved,
+		ReviewedBy: uuid.NullUUID{UUID: reviewer.ID, Valid: true},
+		ReviewedAt: sql.NullTime{Time: now.Add(-2 * time.Hour), Valid: true},
+		ExpiresAt:  sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+	})
+	// A grant that has not expired yet is left alone.
+	other := dbgen.User(t, rawDB, database.User{RBACRoles: []string{rbac.RoleUserAdmin().Name}})
+	active := dbgen.RoleGrantRequest(t, rawDB, database.RoleGrantRequest{
+		UserID:     other.ID,
+		RoleName:   rbac.RoleUserAdmin().Name,
+		Status:     database.RoleGrantStatusApproved,
+		ReviewedBy: uuid.NullUUID{UUID: reviewer.ID, Valid: true},
+		ReviewedAt: sql.NullTime{Time: now, Valid: true},
+		ExpiresAt:  sql.NullTime{Time: now.Add(time.Hour), Valid: true},
+	})
+
+	expirer := rolegrant.New(ctx, db, log, tickCh).
+		WithStatsChannel(statsCh).
+		WithNotificationsEnqueuer(enqueuer)
+	expirer.Start()
+	tickCh <- now
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Equal(t, []uuid.UUID{expired.ID}, stats.Expired)
+
+	req, err := rawDB.GetRoleGrantRequestByID(ctx, expired.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.RoleGrantStatusExpired, req.Status)
+	user, err = rawDB.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotContains(t, user.RBACRoles, rbac.RoleTemplateAdmin().Name)
+
+	req, err = rawDB.GetRoleGrantRequestByID(ctx, active.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.RoleGrantStatusApproved, req.Status)
+	other, err = rawDB.GetUserByID(ctx, other.ID)
+	require.NoError(t, err)
+	require.Contains(t, other.RBACRoles, rbac.RoleUserAdmin().Name)
+
+	require.Len(t, enqueuer.Sent, 1)
+	require.Equal(t, user.ID, enqueuer.Sent[0].UserID)
+	require.Equal(t, notifications.TemplateRoleGrantEnded, enqueuer.Sent[0].TemplateID)
+	require.Equal(t, "expired", enqueuer.Sent[0].Labels["reason"])
+
+	expirer.Close()
+	expirer.Wait()
+}
+
+func TestExpirerOrganizationRole(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = testutil.Context(t, testutil.WaitLong)
+		rawDB, _ = dbtestutil.NewDB(t)
+		log      = slogtest.Make(t, nil)
+		db       = dbauthz.New(rawDB, rbac.NewAuthorizer(prometheus.NewRegistry()), log, coderdtest.AccessControlStorePointer())
+		tickCh   = make(chan time.Time)
+		statsCh  = make(chan rolegrant.Stats)
+		now      = dbtime.Now()
+	)
+
+	org := dbgen.Organization(t, rawDB, database.Organization{})
+	user := dbgen.User(t, rawDB, database.User{})
+	_ = dbgen.OrganizationMember(t, rawDB, database.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		Roles:          []string{rbac.RoleOrgTemplateAdmin(), rbac.RoleOrgAuditor()},
+	})
+	expired := dbgen.RoleGrantRequest(t, rawDB, database.RoleGrantRequest{
+		UserID:         user.ID,
+		OrganizationID: uuid.NullUUID{UUID: org.ID, Valid: true},
+		RoleName:       rbac.RoleOrgTemplateAdmin(),
+		Status:         database.RoleGrantStatusApproved,
+		ExpiresAt:      sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+	})
+	// The requester left the organization, so there is no role to remove.
+	left := dbgen.User(t, rawDB, database.User{})
+	orphaned := dbgen.RoleGrantRequest(t, rawDB, database.RoleGrantRequest{
+		UserID:         left.ID,
+		OrganizationID: uuid.NullUUID{UUID: org.ID, Valid: true},
+		RoleName:       rbac.RoleOrgTemplateAdmin(),
+		Status:         database.RoleGrantStatusApproved,
+		ExpiresAt:      sql.NullTime{Time: now.Add(-time.Minute), Valid: true},
+	})
+
+	expirer := rolegrant.New(ctx, db, log, tickCh).WithStatsChannel(statsCh)
+	expirer.Start()
+	tickCh <- now
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.ElementsMatch(t, []uuid.UUID{expired.ID, orphaned.ID}, stats.Expired)
+
+	member, err := database.ExpectOne(rawDB.OrganizationMembers(ctx, database.OrganizationMembersParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{rbac.RoleOrgAuditor()}, member.OrganizationMember.Roles)
+
+	req, err := rawDB.GetRoleGrantRequestByID(ctx, orphaned.ID)
+	require.NoError(t, err)
+	require.Equal(t, database.RoleGrantStatusExpired, req.Status)
+
+	expirer.Close()
+	expirer.Wait()
+}
@@ -0,0 +1,98 @@
+// Package rolegrant assigns the roles of approved role grant requests and
+// removes them again once the requests expire.
+package rolegrant
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/database"
+)
+
+// HasRole returns whether the requester currently holds the role of a request.
+func HasRole(ctx context.Context, db database.Store, req database.RoleGrantRequest) (bool, error) {
+	roles, err := currentRoles(ctx, db, req)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(roles, req.RoleName), nil
+}
+
+// Grant assigns the role of an approved request to the requester. The actor
+// in ctx must be able to assign the role.
+func Grant(ctx context.Context, db database.Store, req database.RoleGrantRequest) error {
+	roles, err := currentRoles(ctx, db, req)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(roles, req.RoleName) {
+		return nil
+	}
+	return setRoles(ctx, db, req, append(slices.Clone(roles), req.RoleName))
+}
+
+// Remove removes the role of a request from the requester. It is not an error
+// if the requester has since left the organization of the role. The actor in
+// ctx must be able to remove the role.
+func Remove(ctx context.Context, db database.Store, req database.RoleGrantRequest) error {
+	roles, err := currentRoles(ctx, db, req)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(roles, req.RoleName) {
+		return nil
+	}
+	return setRoles(ctx, db, req, slices.DeleteFunc(slices.Clone(roles), func(role string) bool {
+		return role == req.RoleName
+	}))
+}
+
+// currentRoles returns the site roles of the requester, or their roles in the
+// organization of an organization role.
+func currentRoles(ctx context.Context, db database.Store, req database.RoleGrantRequest) ([]string, error) {
+	if !req.OrganizationID.Valid {
+		user, err := db.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return nil, xerrors.Errorf("get user: %w", err)
+		}
+		return user.RBACRoles, nil
+	}
+
+	member, err := database.ExpectOne(db.OrganizationMembers(ctx, database.OrganizationMembersParams{
+		OrganizationID: req.OrganizationID.UUID,
+		UserID:         req.UserID,
+	}))
+	if err != nil {
+		return nil, xerrors.Errorf("get organization member: %w", err)
+	}
+	return member.OrganizationMember.Roles, nil
+}
+
+func setRoles(ctx context.Context, db database.Store, req database.RoleGrantRequest, roles []string) error {
+	if !req.OrganizationID.Valid {
+		_, err := db.UpdateUserRoles(ctx, database.UpdateUserRolesParams{
+			GrantedRoles: roles,
+			ID:           req.UserID,
+		})
+		if err != nil {
+			return xerrors.Errorf("update user roles: %w", err)
+		}
+		return nil
+	}
+
+	_, err := db.UpdateMemberRoles(ctx, database.UpdateMemberRolesParams{
+		GrantedRoles: roles,
+		UserID:       req.UserID,
+		OrgID:        req.OrganizationID.UUID,
+	})
+	if err != nil {
+		return xerrors.Errorf("update member roles: %w", err)
+	}
+	return nil
+}
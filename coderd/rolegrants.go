@@ -0,0 +1,465 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/db2sdk"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/notifications"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/rolestore"
+	"github.com/coder/coder/v2/coderd/rolegrant"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// maxRoleGrantLifetime is the longest a requested role can be held for.
+const maxRoleGrantLifetime = 7 * 24 * time.Hour
+
+// @Summary Request role for user
+// @ID request-role-for-user
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Members
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.CreateRoleGrantRequest true "Role grant request"
+// @Success 201 {object} codersdk.RoleGrantRequest
+// @Router /users/{user}/role-grants [post]
+func (api *API) postUserRoleGrant(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.RoleGrantRequest](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	if apiKey.UserID != user.ID {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "You can only request roles for yourself.",
+		})
+		return
+	}
+
+	var req codersdk.CreateRoleGrantRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.Lifetime <= 0 || req.Lifetime > maxRoleGrantLifetime {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to create a role grant request.",
+			Validations: []codersdk.ValidationError{
+				{Field: "lifetime", Detail: fmt.Sprintf("Must be positive and at most %s.", maxRoleGrantLifetime)},
+			},
+		})
+		return
+	}
+	aReq.UpdateOrganizationID(req.OrganizationID)
+
+	if req.OrganizationID == uuid.Nil && user.LoginType == database.LoginTypeOIDC && api.OIDCConfig.RoleSyncEnabled() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Cannot request site wide roles for OIDC users when role sync is enabled.",
+			Detail:  "'User Role Field' is set in the OIDC configuration. All role changes must come from the oidc identity provider.",
+		})
+		return
+	}
+
+	// Requesters usually can't read the roles they are asking for, so the role
+	// is looked up as the system.
+	//nolint:gocritic // Any existing role can be requested.
+	roles, err := rolestore.Expand(dbauthz.AsSystemRestricted(ctx), api.Database, []rbac.RoleIdentifier{{
+		Name:           req.RoleName,
+		OrganizationID: req.OrganizationID,
+	}})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(roles) == 0 || roles[0].Identifier.IsOrgRole() != (req.OrganizationID != uuid.Nil) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Role %q does not exist.", req.RoleName),
+			Validations: []codersdk.ValidationError{
+				{Field: "role_name", Detail: "Must be an existing role of the organization, or a site wide role."},
+			},
+		})
+		return
+	}
+
+	grant := database.RoleGrantRequest{
+		UserID:         user.ID,
+		OrganizationID: uuid.NullUUID{UUID: req.OrganizationID, Valid: req.OrganizationID != uuid.Nil},
+		RoleName:       req.RoleName,
+	}
+	hasRole, err := rolegrant.HasRole(ctx, api.Database, grant)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You must be a member of the organization to request one of its roles.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching current roles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if hasRole {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("You already have the %q role.", req.RoleName),
+		})
+		return
+	}
+
+	now := dbtime.Now()
+	grant, err = api.Database.InsertRoleGrantRequest(ctx, database.InsertRoleGrantRequestParams{
+		ID:              uuid.New(),
+		UserID:          grant.UserID,
+		OrganizationID:  grant.OrganizationID,
+		RoleName:        grant.RoleName,
+		Reason:          req.Reason,
+		LifetimeSeconds: int64(req.Lifetime.Seconds()),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if database.IsUniqueViolation(err, database.UniqueRoleGrantRequestsOpenIndex) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("You already have an open request for the %q role.", req.RoleName),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating role grant request.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = grant
+
+	httpapi.Write(ctx, rw, http.StatusCreated, db2sdk.RoleGrantRequest(grant))
+}
+
+// @Summary Get role grant requests by user
+// @ID get-role-grant-requests-by-user
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.RoleGrantRequest
+// @Router /users/{user}/role-grants [get]
+func (api *API) userRoleGrants(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	grants, err := api.Database.GetRoleGrantRequestsByUserID(ctx, user.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching role grant requests.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertRoleGrantRequests(grants))
+}
+
+// @Summary Get role grant requests
+// @ID get-role-grant-requests
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param organization_id query string false "Organization ID" format(uuid)
+// @Param status query string false "Status" Enums(pending,approved,denied,expired,revoked)
+// @Success 200 {array} codersdk.RoleGrantRequest
+// @Router /role-grants [get]
+func (api *API) roleGrants(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser()
+	vals := r.URL.Query()
+	orgID := p.UUID(vals, uuid.Nil, "organization_id")
+	status := httpapi.ParseCustom(p, vals, "", "status", httpapi.ParseEnum[database.RoleGrantStatus])
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid query parameters.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	grants, err := api.Database.GetRoleGrantRequests(ctx, database.GetRoleGrantRequestsParams{
+		OrganizationID: orgID,
+		Status:         string(status),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching role grant requests.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertRoleGrantRequests(grants))
+}
+
+// @Summary Get role grant request
+// @ID get-role-grant-request
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param rolegrant path string true "Role grant request ID" format(uuid)
+// @Success 200 {object} codersdk.RoleGrantRequest
+// @Router /role-grants/{rolegrant} [get]
+func (*API) roleGrant(rw http.ResponseWriter, r *http.Request) {
+	httpapi.Write(r.Context(), rw, http.StatusOK, db2sdk.RoleGrantRequest(httpmw.RoleGrantRequestParam(r)))
+}
+
+// @Summary Approve role grant request
+// @ID approve-role-grant-request
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param rolegrant path string true "Role grant request ID" format(uuid)
+// @Success 200 {object} codersdk.RoleGrantRequest
+// @Router /role-grants/{rolegrant}/approve [post]
+func (api *API) postApproveRoleGrant(rw http.ResponseWriter, r *http.Request) {
+	api.reviewRoleGrant(rw, r, database.RoleGrantStatusApproved)
+}
+
+// @Summary Deny role grant request
+// @ID deny-role-grant-request
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param rolegrant path string true "Role grant request ID" format(uuid)
+// @Success 200 {object} codersdk.RoleGrantRequest
+// @Router /role-grants/{rolegrant}/deny [post]
+func (api *API) postDenyRoleGrant(rw http.ResponseWriter, r *http.Request) {
+	api.reviewRoleGrant(rw, r, database.RoleGrantStatusDenied)
+}
+
+// @Summary Revoke role grant request
+// @ID revoke-role-grant-request
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Param rolegrant path string true "Role grant request ID" format(uuid)
+// @Success 200 {object} codersdk.RoleGrantRequest
+// @Router /role-grants/{rolegrant}/revoke [post]
+func (api *API) postRevokeRoleGrant(rw http.ResponseWriter, r *http.Request) {
+	api.reviewRoleGrant(rw, r, database.RoleGrantStatusRevoked)
+}
+
+// reviewRoleGrant moves a role grant request to the given status, assigning
+// or removing the role of the request as needed.
+func (api *API) reviewRoleGrant(rw http.ResponseWriter, r *http.Request, status database.RoleGrantStatus) {
+	var (
+		ctx               = r.Context()
+		grant             = httpmw.RoleGrantRequestParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.RoleGrantRequest](rw, &audit.RequestParams{
+			Audit:          auditor,
+			Log:            api.Logger,
+			Request:        r,
+			Action:         database.AuditActionWrite,
+			OrganizationID: grant.OrganizationID.UUID,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = grant
+
+	from := database.RoleGrantStatusPending
+	if status == database.RoleGrantStatusRevoked {
+		from = database.RoleGrantStatusApproved
+	}
+	if grant.Status != from {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Only %s requests can be %s.", from, status),
+			Detail:  fmt.Sprintf("The request is %s.", grant.Status),
+		})
+		return
+	}
+	if status != database.RoleGrantStatusRevoked && grant.UserID == apiKey.UserID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "You cannot review your own role grant request.",
+		})
+		return
+	}
+
+	now := dbtime.Now()
+	params := database.UpdateRoleGrantRequestByIDParams{
+		ID:         grant.ID,
+		Status:     status,
+		FromStatus: from,
+		ReviewedBy: grant.ReviewedBy,
+		ReviewedAt: grant.ReviewedAt,
+		ExpiresAt:  grant.ExpiresAt,
+		UpdatedAt:  now,
+	}
+	if status != database.RoleGrantStatusRevoked {
+		params.ReviewedBy = uuid.NullUUID{UUID: apiKey.UserID, Valid: true}
+		params.ReviewedAt = sql.NullTime{Time: now, Valid: true}
+	}
+	if status == database.RoleGrantStatusApproved {
+		params.ExpiresAt = sql.NullTime{Time: now.Add(time.Duration(grant.LifetimeSeconds) * time.Second), Valid: true}
+	}
+
+	var updated database.RoleGrantRequest
+	err := api.Database.InTx(func(tx database.Store) error {
+		if status == database.RoleGrantStatusApproved {
+			hasRole, err := rolegrant.HasRole(ctx, tx, grant)
+			if err != nil {
+				return err
+			}
+			if hasRole {
+				// Granting a role the user already holds would remove it when
+				// the request expires.
+				return errRoleAlreadyHeld
+			}
+		}
+		// The update only matches a request that is still in the expected
+		// status, so of two concurrent reviews only one changes the role.
+		var err error
+		updated, err = tx.UpdateRoleGrantRequestByID(ctx, params)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errRoleGrantReviewed
+		}
+		if err != nil {
+			return xerrors.Errorf("update role grant request: %w", err)
+		}
+		switch status {
+		case database.RoleGrantStatusApproved:
+			if err := rolegrant.Grant(ctx, tx, grant); err != nil {
+				return xerrors.Errorf("grant role: %w", err)
+			}
+		case database.RoleGrantStatusRevoked:
+			if err := rolegrant.Remove(ctx, tx, grant); err != nil {
+				return xerrors.Errorf("remove role: %w", err)
+			}
+		}
+		return nil
+	}, nil)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if errors.Is(err, errRoleGrantReviewed) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The role grant request was reviewed concurrently.",
+			Detail:  fmt.Sprintf("Only %s requests can be %s.", from, status),
+		})
+		return
+	}
+	if errors.Is(err, errRoleAlreadyHeld) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("The user already has the %q role.", grant.RoleName),
+		})
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The requester is no longer a member of the organization.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating role grant request.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = updated
+
+	api.notifyRoleGrantReviewed(ctx, updated, apiKey.UserID)
+
+	httpapi.Write(ctx, rw, http.StatusOK, db2sdk.RoleGrantRequest(updated))
+}
+
+var (
+	errRoleAlreadyHeld   = xerrors.New("role is already held")
+	errRoleGrantReviewed = xerrors.New("role grant request was already reviewed")
+)
+
+func (api *API) notifyRoleGrantReviewed(ctx context.Context, grant database.RoleGrantRequest, reviewerID uuid.UUID) {
+	reviewer, err := api.Database.GetUserByID(ctx, reviewerID)
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to fetch reviewer for role grant notification", slog.F("reviewer_id", reviewerID), slog.Error(err))
+		return
+	}
+
+	role := db2sdk.RoleGrantRequest(grant).Role.String()
+	var (
+		templateID uuid.UUID
+		labels     map[string]string
+	)
+	switch grant.Status {
+	case database.RoleGrantStatusApproved:
+		templateID = notifications.TemplateRoleGrantApproved
+		labels = map[string]string{
+			"role":       role,
+			"reviewer":   reviewer.Username,
+			"expires_at": grant.ExpiresAt.Time.UTC().Format(time.RFC1123),
+		}
+	case database.RoleGrantStatusDenied:
+		templateID = notifications.TemplateRoleGrantDenied
+		labels = map[string]string{
+			"role":     role,
+			"reviewer": reviewer.Username,
+		}
+	default:
+		templateID = notifications.TemplateRoleGrantEnded
+		labels = map[string]string{
+			"role":   role,
+			"reason": fmt.Sprintf("was revoked by %s", reviewer.Username),
+		}
+	}
+
+	if _, err := api.NotificationsEnqueuer.Enqueue(ctx, grant.UserID, templateID, labels, "api-role-grants",
+		// Associate this notification with the request.
+		grant.ID,
+	); err != nil {
+		api.Logger.Warn(ctx, "failed to notify of reviewed role grant", slog.F("role_grant_request_id", grant.ID), slog.Error(err))
+	}
+}
+
+func convertRoleGrantRequests(grants []database.RoleGrantRequest) []codersdk.RoleGrantRequest {
+	converted := make([]codersdk.RoleGrantRequest, 0, len(grants))
+	for _, grant := range grants {
+		converted = append(converted, db2sdk.RoleGrantRequest(grant))
+	}
+	return converted
+}
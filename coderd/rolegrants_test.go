@@ -0,0 +1,283 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/notifications"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestRoleGrants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ApproveAndRevoke", func(t *testing.T) {
+		t.Parallel()
+
+		notifyEnq := &testutil.FakeNotificationsEnqueuer{}
+		owner := coderdtest.New(t, &coderdtest.Options{
+			NotificationsEnqueuer: notifyEnq,
+		})
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		grant, err := memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, codersdk.CreateRoleGrantRequest{
+			RoleName:       rbac.RoleOrgTemplateAdmin(),
+			OrganizationID: first.OrganizationID,
+			Reason:         "Fix the broken template",
+			Lifetime:       time.Hour,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleGrantStatusPending, grant.Status)
+		require.Equal(t, member.ID, grant.UserID)
+		require.EqualValues(t, time.Hour.Seconds(), grant.LifetimeSeconds)
+
+		mine, err := memberClient.UserRoleGrantRequests(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, mine, 1)
+
+		pending, err := owner.RoleGrantRequests(ctx, codersdk.RoleGrantRequestsFilter{
+			OrganizationID: first.OrganizationID,
+			Status:         codersdk.RoleGrantStatusPending,
+		})
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, grant.ID, pending[0].ID)
+
+		// Members cannot see requests they are unable to approve.
+		pending, err = memberClient.RoleGrantRequests(ctx, codersdk.RoleGrantRequestsFilter{})
+		require.NoError(t, err)
+		require.Empty(t, pending)
+
+		approved, err := owner.ApproveRoleGrantRequest(ctx, grant.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleGrantStatusApproved, approved.Status)
+		require.NotNil(t, approved.ExpiresAt)
+		require.NotNil(t, approved.ReviewedBy)
+		require.Equal(t, first.UserID, *approved.ReviewedBy)
+
+		roles, err := owner.UserRoles(ctx, member.ID.String())
+		require.NoError(t, err)
+		require.Contains(t, roles.OrganizationRoles[first.OrganizationID], rbac.RoleOrgTemplateAdmin())
+
+		sent := notifyEnq.Sent[len(notifyEnq.Sent)-1]
+		require.Equal(t, notifications.TemplateRoleGrantApproved, sent.TemplateID)
+		require.Equal(t, member.ID, sent.UserID)
+
+		revoked, err := owner.RevokeRoleGrantRequest(ctx, grant.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleGrantStatusRevoked, revoked.Status)
+
+		roles, err = owner.UserRoles(ctx, member.ID.String())
+		require.NoError(t, err)
+		require.NotContains(t, roles.OrganizationRoles[first.OrganizationID], rbac.RoleOrgTemplateAdmin())
+
+		sent = notifyEnq.Sent[len(notifyEnq.Sent)-1]
+		require.Equal(t, notifications.TemplateRoleGrantEnded, sent.TemplateID)
+		require.Equal(t, member.ID, sent.UserID)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		t.Parallel()
+
+		notifyEnq := &testutil.FakeNotificationsEnqueuer{}
+		owner := coderdtest.New(t, &coderdtest.Options{
+			NotificationsEnqueuer: notifyEnq,
+		})
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		grant, err := memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, codersdk.CreateRoleGrantRequest{
+			RoleName: rbac.RoleTemplateAdmin().Name,
+			Reason:   "Investigate a failing build",
+			Lifetime: time.Hour,
+		})
+		require.NoError(t, err)
+
+		denied, err := owner.DenyRoleGrantRequest(ctx, grant.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.RoleGrantStatusDenied, denied.Status)
+		require.Nil(t, denied.ExpiresAt)
+
+		user, err := owner.User(ctx, member.ID.String())
+		require.NoError(t, err)
+		for _, role := range user.Roles {
+			require.NotEqual(t, rbac.RoleTemplateAdmin().Name, role.Name)
+		}
+
+		sent := notifyEnq.Sent[len(notifyEnq.Sent)-1]
+		require.Equal(t, notifications.TemplateRoleGrantDenied, sent.TemplateID)
+		require.Equal(t, member.ID, sent.UserID)
+
+		// A denied request can no longer be approved.
+		_, err = owner.ApproveRoleGrantRequest(ctx, grant.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("ConcurrentReview", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		grant, err := memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, codersdk.CreateRoleGrantRequest{
+			RoleName: rbac.RoleTemplateAdmin().Name,
+			Reason:   "Rotate the provisioner keys",
+			Lifetime: time.Hour,
+		})
+		require.NoError(t, err)
+
+		// Only one of an approval and a denial racing each other may win,
+		// and the role must match the status of the request afterwards.
+		var (
+			eg                  errgroup.Group
+			approved            codersdk.RoleGrantRequest
+			denied              codersdk.RoleGrantRequest
+			approveErr, denyErr error
+		)
+		eg.Go(func() error {
+			approved, approveErr = owner.ApproveRoleGrantRequest(ctx, grant.ID)
+			return nil
+		})
+		eg.Go(func() error {
+			denied, denyErr = owner.DenyRoleGrantRequest(ctx, grant.ID)
+			return nil
+		})
+		require.NoError(t, eg.Wait())
+		require.True(t, (approveErr == nil) != (denyErr == nil), "exactly one review must succeed")
+
+		failed := approveErr
+		if failed == nil {
+			failed = denyErr
+		}
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, failed, &apiErr)
+		// The losing review either saw the updated request or lost the
+		// race inside the transaction.
+		require.Contains(t, []int{http.StatusBadRequest, http.StatusConflict}, apiErr.StatusCode())
+
+		user, err := owner.User(ctx, member.ID.String())
+		require.NoError(t, err)
+		hasRole := false
+		for _, role := range user.Roles {
+			if role.Name == rbac.RoleTemplateAdmin().Name {
+				hasRole = true
+			}
+		}
+		if approveErr == nil {
+			require.Equal(t, codersdk.RoleGrantStatusApproved, approved.Status)
+			require.True(t, hasRole)
+		} else {
+			require.Equal(t, codersdk.RoleGrantStatusDenied, denied.Status)
+			require.False(t, hasRole)
+		}
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		req := codersdk.CreateRoleGrantRequest{
+			RoleName: rbac.RoleTemplateAdmin().Name,
+			Reason:   "Update the base image",
+			Lifetime: time.Hour,
+		}
+		_, err := memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, req)
+		require.NoError(t, err)
+
+		_, err = memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		for _, req := range []codersdk.CreateRoleGrantRequest{
+			// Too long.
+			{RoleName: rbac.RoleTemplateAdmin().Name, Reason: "reason", Lifetime: 30 * 24 * time.Hour},
+			// Unknown role.
+			{RoleName: "not-a-role", Reason: "reason", Lifetime: time.Hour},
+			// Organization role without an organization.
+			{RoleName: rbac.RoleOrgTemplateAdmin(), Reason: "reason", Lifetime: time.Hour},
+		} {
+			_, err := memberClient.CreateRoleGrantRequest(ctx, codersdk.Me, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), req)
+		}
+	})
+
+	t.Run("SelfReview", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		adminClient, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID, rbac.RoleUserAdmin())
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		grant, err := adminClient.CreateRoleGrantRequest(ctx, codersdk.Me, codersdk.CreateRoleGrantRequest{
+			RoleName: rbac.RoleTemplateAdmin().Name,
+			Reason:   "Approve my own request",
+			Lifetime: time.Hour,
+		})
+		require.NoError(t, err)
+
+		_, err = adminClient.ApproveRoleGrantRequest(ctx, grant.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotApprove", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		requester, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+		other, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		grant, err := requester.CreateRoleGrantRequest(ctx, codersdk.Me, codersdk.CreateRoleGrantRequest{
+			RoleName: rbac.RoleTemplateAdmin().Name,
+			Reason:   "Edit templates",
+			Lifetime: time.Hour,
+		})
+		require.NoError(t, err)
+
+		_, err = other.ApproveRoleGrantRequest(ctx, grant.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
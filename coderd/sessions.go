@@ -0,0 +1,228 @@
+package coderd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/apikey"
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// @Summary Get user login sessions
+// @ID get-user-login-sessions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.Session
+// @Router /users/{user}/sessions [get]
+func (api *API) userSessions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		user   = httpmw.UserParam(r)
+		apiKey = httpmw.APIKey(r)
+	)
+
+	if !api.Authorize(r, policy.ActionRead, rbac.ResourceApiKey.WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	keys, err := api.Database.GetSessionAPIKeysByUserID(ctx, database.GetSessionAPIKeysByUserIDParams{
+		UserID:     user.ID,
+		LoginTypes: database.SessionLoginTypes,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching sessions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sessions := make([]codersdk.Session, 0, len(keys))
+	for _, key := range keys {
+		sessions = append(sessions, convertSession(key, apiKey.ID))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sessions)
+}
+
+// @Summary Revoke user login session
+// @ID revoke-user-login-session
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param session path string true "Session ID"
+// @Success 204
+// @Router /users/{user}/sessions/{session} [delete]
+func (api *API) deleteUserSession(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		sessionID         = chi.URLParam(r, "session")
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	key, err := api.Database.GetAPIKeyByID(ctx, sessionID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Tokens and keys of other users cannot be revoked through this route.
+	if key.UserID != user.ID || !key.IsSession() {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	aReq.Old = key
+
+	err = api.Database.DeleteAPIKeyByID(ctx, key.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error revoking session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Signed workspace app tokens only record the user, so they cannot be
+	// traced back to the revoked session. The tokens of every session of the
+	// user are dropped instead, and sessions that are still valid, including
+	// the caller's, are issued new ones on their next app request.
+	api.publishSessionsRevoked(ctx, user.ID)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// deleteUserSessions revokes every login session of the user except for the
+// one making the request. When an admin targets another user, none of the
+// sessions belong to the admin, so the user is logged out everywhere.
+//
+// @Summary Revoke user login sessions
+// @ID revoke-user-login-sessions
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 204
+// @Router /users/{user}/sessions [delete]
+func (api *API) deleteUserSessions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionLogout,
+		})
+	)
+	// The audit log entry targets the user rather than a single session.
+	aReq.Old = database.APIKey{UserID: user.ID}
+	defer commitAudit()
+
+	err := api.Database.DeleteSessionAPIKeysByUserID(ctx, database.DeleteSessionAPIKeysByUserIDParams{
+		UserID:     user.ID,
+		LoginTypes: database.SessionLoginTypes,
+		ExceptID:   apiKey.ID,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error revoking sessions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.publishSessionsRevoked(ctx, user.ID)
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// publishSessionsRevoked tells every replica that sessions of the user were
+// revoked, so that anything derived from them stops being trusted at once.
+func (api *API) publishSessionsRevoked(ctx context.Context, userID uuid.UUID) {
+	msg, err := json.Marshal(apikey.SessionsRevokedMessage{
+		UserID: userID,
+		// dbtime.Now rounds to the microsecond, which could end up before
+		// tokens issued in the same instant.
+		RevokedAt: time.Now(),
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to marshal sessions revoked message", slog.F("user_id", userID), slog.Error(err))
+		return
+	}
+	err = api.Pubsub.Publish(apikey.SessionsRevokedChannel, msg)
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to publish sessions revoked", slog.F("user_id", userID), slog.Error(err))
+	}
+}
+
+// subscribeSessionsRevoked drops the workspace app tokens of users whose
+// sessions were revoked on any replica.
+func (api *API) subscribeSessionsRevoked(provider *workspaceapps.DBTokenProvider) (func(), error) {
+	cancel, err := api.Pubsub.Subscribe(apikey.SessionsRevokedChannel, func(ctx context.Context, message []byte) {
+		var msg apikey.SessionsRevokedMessage
+		err := json.Unmarshal(message, &msg)
+		if err != nil {
+			api.Logger.Warn(ctx, "failed to unmarshal sessions revoked message", slog.Error(err))
+			return
+		}
+		provider.RevokeUserTokens(msg.UserID, msg.RevokedAt)
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("subscribe: %w", err)
+	}
+	return cancel, nil
+}
+
+func convertSession(key database.APIKey, currentID string) codersdk.Session {
+	session := codersdk.Session{
+		ID:        key.ID,
+		LoginType: codersdk.LoginType(key.LoginType),
+		UserAgent: key.UserAgent,
+		CreatedAt: key.CreatedAt,
+		LastUsed:  key.LastUsed,
+		ExpiresAt: key.ExpiresAt,
+		Current:   key.ID == currentID,
+	}
+	if key.IPAddress.Valid {
+		session.IPAddress = key.IPAddress.IPNet.IP.String()
+	}
+	return session
+}
@@ -0,0 +1,186 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestUserSessions(t *testing.T) {
+	t.Parallel()
+
+	// login creates a new session for the user with its own client.
+	login := func(ctx context.Context, t *testing.T, owner *codersdk.Client, email string) *codersdk.Client {
+		t.Helper()
+
+		client := codersdk.New(owner.URL)
+		res, err := client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    email,
+			Password: "SomeSecurePassword!",
+		})
+		require.NoError(t, err)
+		client.SetSessionToken(res.SessionToken)
+		return client
+	}
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_ = login(ctx, t, owner, member.Email)
+
+		sessions, err := memberClient.UserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+
+		current := 0
+		for _, session := range sessions {
+			require.Equal(t, codersdk.LoginTypePassword, session.LoginType)
+			require.NotEmpty(t, session.UserAgent)
+			require.NotEmpty(t, session.IPAddress)
+			if session.Current {
+				current++
+			}
+		}
+		require.Equal(t, 1, current)
+
+		// Tokens are not sessions.
+		_, err = memberClient.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		sessions, err = memberClient.UserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+	})
+
+	t.Run("RevokeOne", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		other := login(ctx, t, owner, member.Email)
+
+		sessions, err := memberClient.UserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		var otherID string
+		for _, session := range sessions {
+			if !session.Current {
+				otherID = session.ID
+			}
+		}
+		require.NotEmpty(t, otherID)
+
+		err = memberClient.RevokeUserSession(ctx, codersdk.Me, otherID)
+		require.NoError(t, err)
+
+		_, err = other.User(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		_, err = memberClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		// Tokens cannot be revoked as sessions.
+		token, err := memberClient.CreateToken(ctx, codersdk.Me, codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		err = memberClient.RevokeUserSession(ctx, codersdk.Me, token.Key[:10])
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("RevokeOthers", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		other := login(ctx, t, owner, member.Email)
+
+		err := memberClient.RevokeUserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		_, err = other.User(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		sessions, err := memberClient.UserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.True(t, sessions[0].Current)
+	})
+
+	t.Run("ForceLogout", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		sessions, err := owner.UserSessions(ctx, member.ID.String())
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		require.False(t, sessions[0].Current)
+
+		err = owner.RevokeUserSessions(ctx, member.ID.String())
+		require.NoError(t, err)
+
+		_, err = memberClient.User(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		sessions, err = owner.UserSessions(ctx, member.ID.String())
+		require.NoError(t, err)
+		require.Empty(t, sessions)
+
+		// The owner is still logged in.
+		_, err = owner.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+	})
+
+	t.Run("UserAdminCannotRevoke", func(t *testing.T) {
+		t.Parallel()
+
+		owner := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, owner)
+		adminClient, _ := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID, rbac.RoleUserAdmin())
+		memberClient, member := coderdtest.CreateAnotherUser(t, owner, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// User admins can manage users, but not their API keys.
+		_, err := adminClient.UserSessions(ctx, member.ID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		err = adminClient.RevokeUserSessions(ctx, member.ID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		_, err = memberClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+	})
+}
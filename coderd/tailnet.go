@@ -27,6 +27,7 @@ import (
 	"github.com/coder/coder/v2/coderd/tracing"
 	"github.com/coder/coder/v2/coderd/workspaceapps"
 	"github.com/coder/coder/v2/coderd/workspaceapps/appurl"
+	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
 	"github.com/coder/coder/v2/site"
 	"github.com/coder/coder/v2/tailnet"
@@ -129,6 +130,7 @@ func NewServerTailnet(
 		agentConnectionTimes: map[uuid.UUID]time.Time{},
 		agentTickets:         map[uuid.UUID]map[uuid.UUID]struct{}{},
 		transport:            tailnetTransport.Clone(),
+		unixTransports:       map[string]*unixTransport{},
 		connsPerAgent: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: "coder",
 			Subsystem: "servertailnet",
@@ -248,6 +250,18 @@ func (s *ServerTailnet) doExpireOldAgents(cutoff time.Duration) {
 		}
 	}
 	s.nodesMu.Unlock()
+
+	// Unix socket transports are shared by every agent, so they are expired
+	// by their own last use rather than with the agents above.
+	s.unixTransportsMu.Lock()
+	for socketPath, transport := range s.unixTransports {
+		if time.Since(transport.lastUsed) > cutoff {
+			transport.CloseIdleConnections()
+			delete(s.unixTransports, socketPath)
+		}
+	}
+	s.unixTransportsMu.Unlock()
+
 	s.logger.Debug(s.ctx, "successfully pruned inactive agents",
 		slog.F("deleted", deletedCount),
 		slog.F("took", time.Since(start)),
@@ -340,6 +354,12 @@ type ServerTailnet struct {
 	agentTickets map[uuid.UUID]map[uuid.UUID]struct{}
 
 	transport *http.Transport
+	// unixTransports holds a transport for each unix socket path, so
+	// connections to unix socket apps are reused like those to ports.
+	// Transports that are not used for a while are removed along with
+	// expired agents.
+	unixTransportsMu sync.Mutex
+	unixTransports   map[string]*unixTransport
 
 	connsPerAgent *prometheus.GaugeVec
 	totalConns    *prometheus.CounterVec
@@ -351,8 +371,16 @@ func (s *ServerTailnet) ReverseProxy(targetURL, dashboardURL *url.URL, agentID u
 	// addressed invidivually. Otherwise, all connections get dialed as
 	// "localhost:port", causing connections to be shared across agents.
 	tgt := *targetURL
-	_, port, _ := net.SplitHostPort(tgt.Host)
-	tgt.Host = net.JoinHostPort(tailnet.IPFromUUID(agentID).String(), port)
+	var transport http.RoundTripper = s.transport
+	if codersdk.WorkspaceAppProtocolFromURL(&tgt) == codersdk.WorkspaceAppProtocolUnix {
+		// Unix socket apps serve plain HTTP. The socket path is not part of
+		// the request, so the app needs a transport of its own.
+		transport = s.unixSocketTransport(tgt.Path)
+		tgt = url.URL{Scheme: "http", Host: tailnet.IPFromUUID(agentID).String()}
+	} else {
+		_, port, _ := net.SplitHostPort(tgt.Host)
+		tgt.Host = net.JoinHostPort(tailnet.IPFromUUID(agentID).String(), port)
+	}
 
 	proxy := httputil.NewSingleHostReverseProxy(&tgt)
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, theErr error) {
@@ -398,11 +426,35 @@ func (s *ServerTailnet) ReverseProxy(targetURL, dashboardURL *url.URL, agentID u
 		})
 	}
 	proxy.Director = s.director(agentID, proxy.Director)
-	proxy.Transport = s.transport
+	proxy.Transport = transport
 
 	return proxy
 }
 
+type unixTransport struct {
+	*http.Transport
+	lastUsed time.Time
+}
+
+// unixSocketTransport returns the transport that dials the unix socket at
+// socketPath on the agent set by the director. Connections are pooled by the
+// agent's address, so one transport can be shared by every agent.
+func (s *ServerTailnet) unixSocketTransport(socketPath string) *http.Transport {
+	s.unixTransportsMu.Lock()
+	defer s.unixTransportsMu.Unlock()
+
+	transport, ok := s.unixTransports[socketPath]
+	if !ok {
+		transport = &unixTransport{Transport: s.transport.Clone()}
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return s.dialContext(ctx, "unix", socketPath)
+		}
+		s.unixTransports[socketPath] = transport
+	}
+	transport.lastUsed = time.Now()
+	return transport.Transport
+}
+
 type agentIDKey struct{}
 
 // director makes sure agentIDKey is set on the context in the reverse proxy.
@@ -535,6 +587,11 @@ func (s *ServerTailnet) Close() error {
 	s.cancel()
 	_ = s.conn.Close()
 	s.transport.CloseIdleConnections()
+	s.unixTransportsMu.Lock()
+	for _, transport := range s.unixTransports {
+		transport.CloseIdleConnections()
+	}
+	s.unixTransportsMu.Unlock()
 	<-s.derpMapUpdaterClosed
 	return nil
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"sync/atomic"
 	"testing"
@@ -244,6 +245,57 @@ func TestServerTailnet_ReverseProxy(t *testing.T) {
 		assert.Equal(t, 1, wln.getDials())
 	})
 
+	t.Run("CachesUnixSocketConnection", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		agents, serverTailnet := setupServerTailnetAgent(t, 1)
+		a := agents[0]
+		socketPath := filepath.Join(t.TempDir(), "app.sock")
+		ln, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		wln := &wrappedListener{Listener: ln}
+
+		serverClosed := make(chan struct{})
+		go func() {
+			defer close(serverClosed)
+			//nolint:gosec
+			_ = http.Serve(wln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("hello from agent"))
+			}))
+		}()
+		defer func() {
+			// wait for server to close
+			<-serverClosed
+		}()
+
+		defer ln.Close()
+
+		u := &url.URL{Scheme: "unix", Path: socketPath}
+		for i := 0; i < 5; i++ {
+			// A new proxy is made for every request to an app.
+			rp := serverTailnet.ReverseProxy(u, u, a.id, appurl.ApplicationURL{}, "")
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(
+				http.MethodGet,
+				"http://127.0.0.1",
+				nil,
+			).WithContext(ctx)
+
+			rp.ServeHTTP(rw, req)
+			res := rw.Result()
+
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}
+
+		assert.Equal(t, 1, wln.getDials())
+	})
+
 	t.Run("NotReusedBetweenAgents", func(t *testing.T) {
 		t.Parallel()
 
@@ -11,17 +11,20 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
 
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/db2sdk"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/coderd/notifications"
+	"github.com/coder/coder/v2/coderd/proxypinning"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/coderd/schedule"
@@ -598,6 +601,7 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		template          = httpmw.TemplateParam(r)
 		auditor           = *api.Auditor.Load()
 		portSharer        = *api.PortSharer.Load()
+		pinner            = *api.WorkspaceProxyPinner.Load()
 		aReq, commitAudit = audit.InitRequest[database.Template](rw, &audit.RequestParams{
 			Audit:          auditor,
 			Log:            api.Logger,
@@ -699,6 +703,61 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	allowedWorkspaceProxyIDs := template.AllowedWorkspaceProxyIDs
+	if req.AllowedWorkspaceProxyIDs != nil && !slices.Equal(*req.AllowedWorkspaceProxyIDs, template.AllowedWorkspaceProxyIDs) {
+		err := pinner.ValidateWorkspaceProxyIDs(ctx, *req.AllowedWorkspaceProxyIDs)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "allowed_workspace_proxy_ids", Detail: err.Error()})
+		} else {
+			allowedWorkspaceProxyIDs = append([]uuid.UUID{}, *req.AllowedWorkspaceProxyIDs...)
+		}
+	}
+	allowedDERPRegionIDs := template.AllowedDERPRegionIDs
+	if req.AllowedDERPRegionIDs != nil && !slices.Equal(proxypinning.DERPRegionIDs(*req.AllowedDERPRegionIDs), template.AllowedDERPRegionIDs) {
+		err := pinner.ValidateDERPRegionIDs(ctx, *req.AllowedDERPRegionIDs)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "allowed_derp_region_ids", Detail: err.Error()})
+		} else {
+			allowedDERPRegionIDs = proxypinning.DERPRegionIDs(*req.AllowedDERPRegionIDs)
+		}
+	}
+
+	maxAppConnectionsPerUser := template.MaxAppConnectionsPerUser
+	if req.MaxAppConnectionsPerUser != nil {
+		if *req.MaxAppConnectionsPerUser < 0 {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "max_app_connections_per_user", Detail: "Must not be negative."})
+		}
+		maxAppConnectionsPerUser = *req.MaxAppConnectionsPerUser
+	}
+	maxAppBytesPerSecond := template.MaxAppBytesPerSecond
+	if req.MaxAppBytesPerSecond != nil {
+		if *req.MaxAppBytesPerSecond < 0 {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "max_app_bytes_per_second", Detail: "Must not be negative."})
+		}
+		maxAppBytesPerSecond = *req.MaxAppBytesPerSecond
+	}
+	driftDetectionInterval := time.Duration(template.DriftDetectionInterval)
+	if req.DriftDetectionIntervalMillis != nil {
+		driftDetectionInterval = time.Duration(*req.DriftDetectionIntervalMillis) * time.Millisecond
+		if driftDetectionInterval < 0 || (driftDetectionInterval > 0 && driftDetectionInterval < time.Hour) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "drift_detection_interval_ms", Detail: "Value must be zero or at least one hour."})
+		}
+	}
+	buildTimeout := time.Duration(template.BuildTimeout)
+	if req.BuildTimeoutMillis != nil {
+		buildTimeout = time.Duration(*req.BuildTimeoutMillis) * time.Millisecond
+		if buildTimeout < 0 || (buildTimeout > 0 && buildTimeout < time.Minute) {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "build_timeout_ms", Detail: "Value must be zero or at least one minute."})
+		}
+	}
+	buildCancelGracePeriod := time.Duration(template.BuildCancelGracePeriod)
+	if req.BuildCancelGracePeriodMillis != nil {
+		buildCancelGracePeriod = time.Duration(*req.BuildCancelGracePeriodMillis) * time.Millisecond
+		if buildCancelGracePeriod < 0 {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "build_cancel_grace_period_ms", Detail: "Must not be negative."})
+		}
+	}
+
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message:     "Invalid request to update template metadata!",
@@ -726,7 +785,14 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			req.TimeTilDormantAutoDeleteMillis == time.Duration(template.TimeTilDormantAutoDelete).Milliseconds() &&
 			req.RequireActiveVersion == template.RequireActiveVersion &&
 			(deprecationMessage == template.Deprecated) &&
-			maxPortShareLevel == template.MaxPortSharingLevel {
+			maxPortShareLevel == template.MaxPortSharingLevel &&
+			slices.Equal(allowedWorkspaceProxyIDs, template.AllowedWorkspaceProxyIDs) &&
+			slices.Equal(allowedDERPRegionIDs, template.AllowedDERPRegionIDs) &&
+			maxAppConnectionsPerUser == template.MaxAppConnectionsPerUser &&
+			maxAppBytesPerSecond == template.MaxAppBytesPerSecond &&
+			int64(driftDetectionInterval) == template.DriftDetectionInterval &&
+			int64(buildTimeout) == template.BuildTimeout &&
+			int64(buildCancelGracePeriod) == template.BuildCancelGracePeriod {
 			return nil
 		}
 
@@ -767,6 +833,13 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			AllowUserCancelWorkspaceJobs: req.AllowUserCancelWorkspaceJobs,
 			GroupACL:                     groupACL,
 			MaxPortSharingLevel:          maxPortShareLevel,
+			AllowedWorkspaceProxyIDs:     allowedWorkspaceProxyIDs,
+			AllowedDERPRegionIDs:         allowedDERPRegionIDs,
+			MaxAppConnectionsPerUser:     maxAppConnectionsPerUser,
+			MaxAppBytesPerSecond:         maxAppBytesPerSecond,
+			DriftDetectionInterval:       int64(driftDetectionInterval),
+			BuildTimeout:                 int64(buildTimeout),
+			BuildCancelGracePeriod:       int64(buildCancelGracePeriod),
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -958,6 +1031,7 @@ func (api *API) convertTemplate(
 
 	portSharer := *(api.PortSharer.Load())
 	maxPortShareLevel := portSharer.ConvertMaxLevel(template.MaxPortSharingLevel)
+	allowedWorkspaceProxyIDs, allowedDERPRegionIDs := db2sdk.WorkspaceProxyPins(template.AllowedWorkspaceProxyIDs, template.AllowedDERPRegionIDs)
 
 	return codersdk.Template{
 		ID:                             template.ID,
@@ -997,6 +1071,15 @@ func (api *API) convertTemplate(
 		Deprecated:           templateAccessControl.IsDeprecated(),
 		DeprecationMessage:   templateAccessControl.Deprecated,
 		MaxPortShareLevel:    maxPortShareLevel,
+
+		AllowedWorkspaceProxyIDs: allowedWorkspaceProxyIDs,
+		AllowedDERPRegionIDs:     allowedDERPRegionIDs,
+		MaxAppConnectionsPerUser: template.MaxAppConnectionsPerUser,
+		MaxAppBytesPerSecond:     template.MaxAppBytesPerSecond,
+
+		DriftDetectionIntervalMillis: time.Duration(template.DriftDetectionInterval).Milliseconds(),
+		BuildTimeoutMillis:           time.Duration(template.BuildTimeout).Milliseconds(),
+		BuildCancelGracePeriodMillis: time.Duration(template.BuildCancelGracePeriod).Milliseconds(),
 	}
 }
 
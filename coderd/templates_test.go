@@ -706,6 +706,163 @@ func TestPatchTemplateMeta(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("AGPL_AllowedWorkspaceProxies", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: false})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Empty(t, template.AllowedWorkspaceProxyIDs)
+		require.Empty(t, template.AllowedDERPRegionIDs)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// AGPL cannot restrict workspace proxies or DERP regions.
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			AllowedWorkspaceProxyIDs: &[]uuid.UUID{uuid.New()},
+		})
+		require.ErrorContains(t, err, "Restricting workspace proxies is an enterprise feature")
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			AllowedDERPRegionIDs: &[]int{1},
+		})
+		require.ErrorContains(t, err, "Restricting DERP regions is an enterprise feature")
+
+		// Clearing the restrictions is a no-op.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                     coderdtest.RandomUsername(t),
+			AllowedWorkspaceProxyIDs: &[]uuid.UUID{},
+			AllowedDERPRegionIDs:     &[]int{},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("AppLimits", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: false})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.MaxAppConnectionsPerUser)
+		require.Zero(t, template.MaxAppBytesPerSecond)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaxAppConnectionsPerUser: ptr.Ref[int64](10),
+			MaxAppBytesPerSecond:     ptr.Ref[int64](1 << 20),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 10, updated.MaxAppConnectionsPerUser)
+		require.EqualValues(t, 1<<20, updated.MaxAppBytesPerSecond)
+
+		// Omitting the limits leaves them unchanged.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name: coderdtest.RandomUsername(t),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 10, updated.MaxAppConnectionsPerUser)
+		require.EqualValues(t, 1<<20, updated.MaxAppBytesPerSecond)
+
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			MaxAppConnectionsPerUser: ptr.Ref[int64](-1),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "max_app_connections_per_user", apiErr.Validations[0].Field)
+	})
+
+	t.Run("DriftDetectionInterval", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: false})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.DriftDetectionIntervalMillis)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DriftDetectionIntervalMillis: ptr.Ref((6 * time.Hour).Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, (6 * time.Hour).Milliseconds(), updated.DriftDetectionIntervalMillis)
+
+		// Omitting the interval leaves it unchanged.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name: coderdtest.RandomUsername(t),
+		})
+		require.NoError(t, err)
+		require.Equal(t, (6 * time.Hour).Milliseconds(), updated.DriftDetectionIntervalMillis)
+
+		// Checks more often than hourly are too expensive.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DriftDetectionIntervalMillis: ptr.Ref(time.Minute.Milliseconds()),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "drift_detection_interval_ms", apiErr.Validations[0].Field)
+
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DriftDetectionIntervalMillis: ptr.Ref[int64](0),
+		})
+		require.NoError(t, err)
+		require.Zero(t, updated.DriftDetectionIntervalMillis)
+	})
+
+	t.Run("BuildTimeout", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: false})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.BuildTimeoutMillis)
+		require.Zero(t, template.BuildCancelGracePeriodMillis)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			BuildTimeoutMillis:           ptr.Ref(time.Hour.Milliseconds()),
+			BuildCancelGracePeriodMillis: ptr.Ref((5 * time.Minute).Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, time.Hour.Milliseconds(), updated.BuildTimeoutMillis)
+		require.Equal(t, (5 * time.Minute).Milliseconds(), updated.BuildCancelGracePeriodMillis)
+
+		// Omitting the settings leaves them unchanged.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name: coderdtest.RandomUsername(t),
+		})
+		require.NoError(t, err)
+		require.Equal(t, time.Hour.Milliseconds(), updated.BuildTimeoutMillis)
+		require.Equal(t, (5 * time.Minute).Milliseconds(), updated.BuildCancelGracePeriodMillis)
+
+		// Timeouts shorter than a minute would fail most builds.
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			BuildTimeoutMillis:           ptr.Ref(time.Second.Milliseconds()),
+			BuildCancelGracePeriodMillis: ptr.Ref[int64](-1),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+		require.Equal(t, "build_timeout_ms", apiErr.Validations[0].Field)
+		require.Equal(t, "build_cancel_grace_period_ms", apiErr.Validations[1].Field)
+
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			BuildTimeoutMillis: ptr.Ref[int64](0),
+		})
+		require.NoError(t, err)
+		require.Zero(t, updated.BuildTimeoutMillis)
+	})
+
 	t.Run("NoDefaultTTL", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,363 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// @Summary Get template version canary by template ID
+// @ID get-template-version-canary-by-template-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionCanary
+// @Router /templates/{template}/canary [get]
+func (api *API) templateVersionCanary(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	canary, err := api.Database.GetLatestTemplateVersionCanaryByTemplateID(ctx, template.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "No canary has been started for this template.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version canary.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	stats, err := api.Database.GetTemplateVersionCanaryStats(ctx, database.GetTemplateVersionCanaryStatsParams{
+		TemplateVersionID: canary.TemplateVersionID,
+		Since:             canary.CreatedAt,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version canary stats.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionCanary(canary, stats))
+}
+
+// @Summary Create template version canary by template ID
+// @ID create-template-version-canary-by-template-id
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.CreateTemplateVersionCanaryRequest true "Canary request"
+// @Success 201 {object} codersdk.TemplateVersionCanary
+// @Router /templates/{template}/canary [post]
+func (api *API) postTemplateVersionCanary(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+		apiKey   = httpmw.APIKey(r)
+	)
+
+	var req codersdk.CreateTemplateVersionCanaryRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	var validErrs []codersdk.ValidationError
+	if req.Percent < 0 || req.Percent > 100 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "percent", Detail: "Must be between 0 and 100."})
+	}
+	if req.MinBuilds < 1 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "min_builds", Detail: "Must be at least 1."})
+	}
+	if req.SuccessThreshold < 0 || req.SuccessThreshold > 100 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "success_threshold", Detail: "Must be between 0 and 100."})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to create a template version canary.",
+			Validations: validErrs,
+		})
+		return
+	}
+	if req.Percent == 0 && req.GroupID == nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "A canary must target a percentage of workspaces, a group, or both.",
+		})
+		return
+	}
+	if req.TemplateVersionID == template.ActiveVersionID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version is already the active version.",
+		})
+		return
+	}
+
+	version, err := api.Database.GetTemplateVersionByID(ctx, req.TemplateVersionID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Template version not found.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if version.TemplateID.UUID != template.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version doesn't belong to the specified template.",
+		})
+		return
+	}
+	if version.Archived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version is archived.",
+		})
+		return
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version job status.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if job.JobStatus != database.ProvisionerJobStatusSucceeded {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Only versions that have been built successfully can be rolled out.",
+			Detail:  fmt.Sprintf("Attempted to roll out a version with a %s build", job.JobStatus),
+		})
+		return
+	}
+
+	var groupID uuid.NullUUID
+	if req.GroupID != nil {
+		group, err := api.Database.GetGroupByID(ctx, *req.GroupID)
+		if httpapi.Is404Error(err) || (err == nil && group.OrganizationID != template.OrganizationID) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Group not found.",
+				Validations: []codersdk.ValidationError{
+					{Field: "group_id", Detail: "Group must exist in the template's organization."},
+				},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching group.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		groupID = uuid.NullUUID{UUID: group.ID, Valid: true}
+	}
+
+	now := dbtime.Now()
+	canary, err := api.Database.InsertTemplateVersionCanary(ctx, database.InsertTemplateVersionCanaryParams{
+		ID:                uuid.New(),
+		TemplateID:        template.ID,
+		TemplateVersionID: version.ID,
+		Percent:           req.Percent,
+		GroupID:           groupID,
+		MinBuilds:         req.MinBuilds,
+		SuccessThreshold:  req.SuccessThreshold,
+		CreatedBy:         apiKey.UserID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A canary is already active for this template.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating template version canary.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.publishTemplateUpdate(ctx, template.ID)
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertTemplateVersionCanary(canary, database.GetTemplateVersionCanaryStatsRow{}))
+}
+
+// @Summary Promote or roll back template version canary by template ID
+// @ID promote-or-roll-back-template-version-canary-by-template-id
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateVersionCanaryRequest true "Canary update request"
+// @Success 200 {object} codersdk.TemplateVersionCanary
+// @Router /templates/{template}/canary [patch]
+func (api *API) patchTemplateVersionCanary(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Template](rw, &audit.RequestParams{
+			Audit:          auditor,
+			Log:            api.Logger,
+			Request:        r,
+			Action:         database.AuditActionWrite,
+			OrganizationID: template.OrganizationID,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = template
+
+	var req codersdk.UpdateTemplateVersionCanaryRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var canary database.TemplateVersionCanary
+	newTemplate := template
+	err := api.Database.InTx(func(tx database.Store) error {
+		active, err := tx.GetActiveTemplateVersionCanaryByTemplateID(ctx, template.ID)
+		if err != nil {
+			return xerrors.Errorf("get active canary: %w", err)
+		}
+		if req.Status == codersdk.TemplateVersionCanaryStatusPromoted {
+			err = tx.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
+				ID:              template.ID,
+				ActiveVersionID: active.TemplateVersionID,
+				UpdatedAt:       dbtime.Now(),
+			})
+			if err != nil {
+				return xerrors.Errorf("update active version: %w", err)
+			}
+			newTemplate.ActiveVersionID = active.TemplateVersionID
+		}
+		canary, err = tx.UpdateTemplateVersionCanaryStatusByID(ctx, database.UpdateTemplateVersionCanaryStatusByIDParams{
+			ID:           active.ID,
+			Status:       database.TemplateVersionCanaryStatus(req.Status),
+			StatusReason: fmt.Sprintf("%s manually", canaryStatusVerb(req.Status)),
+			UpdatedAt:    dbtime.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("update canary status: %w", err)
+		}
+		return nil
+	}, nil)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "No canary is active for this template.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template version canary.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = newTemplate
+
+	stats, err := api.Database.GetTemplateVersionCanaryStats(ctx, database.GetTemplateVersionCanaryStatsParams{
+		TemplateVersionID: canary.TemplateVersionID,
+		Since:             canary.CreatedAt,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version canary stats.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.publishTemplateUpdate(ctx, template.ID)
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionCanary(canary, stats))
+}
+
+// endActiveTemplateVersionCanary ends the active canary of a template when its
+// active version is changed directly. The canary counts as promoted if its
+// version became the active version, and as rolled back otherwise.
+func endActiveTemplateVersionCanary(ctx context.Context, db database.Store, templateID, activeVersionID uuid.UUID) error {
+	canary, err := db.GetActiveTemplateVersionCanaryByTemplateID(ctx, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get active canary: %w", err)
+	}
+	status := database.TemplateVersionCanaryStatusRolledBack
+	reason := "another version was made active"
+	if canary.TemplateVersionID == activeVersionID {
+		status = database.TemplateVersionCanaryStatusPromoted
+		reason = "the version was made active"
+	}
+	_, err = db.UpdateTemplateVersionCanaryStatusByID(ctx, database.UpdateTemplateVersionCanaryStatusByIDParams{
+		ID:           canary.ID,
+		Status:       status,
+		StatusReason: reason,
+		UpdatedAt:    dbtime.Now(),
+	})
+	if err != nil {
+		return xerrors.Errorf("update canary status: %w", err)
+	}
+	return nil
+}
+
+func canaryStatusVerb(status codersdk.TemplateVersionCanaryStatus) string {
+	if status == codersdk.TemplateVersionCanaryStatusPromoted {
+		return "promoted"
+	}
+	return "rolled back"
+}
+
+func convertTemplateVersionCanary(canary database.TemplateVersionCanary, stats database.GetTemplateVersionCanaryStatsRow) codersdk.TemplateVersionCanary {
+	sdkCanary := codersdk.TemplateVersionCanary{
+		ID:                canary.ID,
+		TemplateID:        canary.TemplateID,
+		TemplateVersionID: canary.TemplateVersionID,
+		Percent:           canary.Percent,
+		MinBuilds:         canary.MinBuilds,
+		SuccessThreshold:  canary.SuccessThreshold,
+		Status:            codersdk.TemplateVersionCanaryStatus(canary.Status),
+		StatusReason:      canary.StatusReason,
+		SucceededBuilds:   stats.SucceededBuilds,
+		FailedBuilds:      stats.FailedBuilds,
+		CreatedBy:         canary.CreatedBy,
+		CreatedAt:         canary.CreatedAt,
+		UpdatedAt:         canary.UpdatedAt,
+	}
+	if canary.GroupID.Valid {
+		sdkCanary.GroupID = &canary.GroupID.UUID
+	}
+	if canary.CompletedAt.Valid {
+		sdkCanary.CompletedAt = &canary.CompletedAt.Time
+	}
+	return sdkCanary
+}
@@ -0,0 +1,169 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestTemplateVersionCanary(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.TemplateVersionCanary(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("EmptyCohort", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		canaryVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, canaryVersion.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateTemplateVersionCanary(ctx, template.ID, codersdk.CreateTemplateVersionCanaryRequest{
+			TemplateVersionID: canaryVersion.ID,
+			MinBuilds:         1,
+			SuccessThreshold:  90,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("BuildsCanaryVersion", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		canaryVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, canaryVersion.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		canary, err := client.CreateTemplateVersionCanary(ctx, template.ID, codersdk.CreateTemplateVersionCanaryRequest{
+			TemplateVersionID: canaryVersion.ID,
+			Percent:           100,
+			MinBuilds:         5,
+			SuccessThreshold:  90,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionCanaryStatusActive, canary.Status)
+
+		// Only one canary may be active at a time.
+		_, err = client.CreateTemplateVersionCanary(ctx, template.ID, codersdk.CreateTemplateVersionCanaryRequest{
+			TemplateVersionID: canaryVersion.ID,
+			Percent:           100,
+			MinBuilds:         5,
+			SuccessThreshold:  90,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		// Every workspace is in the cohort, so new workspaces are built with
+		// the canary version.
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+		require.Equal(t, canaryVersion.ID, workspace.LatestBuild.TemplateVersionID)
+
+		canary, err = client.TemplateVersionCanary(ctx, template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, canary.SucceededBuilds)
+		require.EqualValues(t, 0, canary.FailedBuilds)
+	})
+
+	t.Run("Promote", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		canaryVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, canaryVersion.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateTemplateVersionCanary(ctx, template.ID, codersdk.CreateTemplateVersionCanaryRequest{
+			TemplateVersionID: canaryVersion.ID,
+			Percent:           10,
+			MinBuilds:         5,
+			SuccessThreshold:  90,
+		})
+		require.NoError(t, err)
+
+		canary, err := client.UpdateTemplateVersionCanary(ctx, template.ID, codersdk.UpdateTemplateVersionCanaryRequest{
+			Status: codersdk.TemplateVersionCanaryStatusPromoted,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionCanaryStatusPromoted, canary.Status)
+		require.NotNil(t, canary.CompletedAt)
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, canaryVersion.ID, template.ActiveVersionID)
+
+		// The canary has ended, so there is nothing left to promote.
+		_, err = client.UpdateTemplateVersionCanary(ctx, template.ID, codersdk.UpdateTemplateVersionCanaryRequest{
+			Status: codersdk.TemplateVersionCanaryStatusPromoted,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("EndedByActiveVersionChange", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		canaryVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, canaryVersion.ID)
+		otherVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, otherVersion.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateTemplateVersionCanary(ctx, template.ID, codersdk.CreateTemplateVersionCanaryRequest{
+			TemplateVersionID: canaryVersion.ID,
+			Percent:           10,
+			MinBuilds:         5,
+			SuccessThreshold:  90,
+		})
+		require.NoError(t, err)
+
+		err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: otherVersion.ID,
+		})
+		require.NoError(t, err)
+
+		canary, err := client.TemplateVersionCanary(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionCanaryStatusRolledBack, canary.Status)
+	})
+}
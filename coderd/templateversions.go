@@ -20,6 +20,7 @@ import (
 
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/db2sdk"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/database/provisionerjobs"
 	"github.com/coder/coder/v2/coderd/externalauth"
@@ -504,6 +505,8 @@ func (api *API) postTemplateVersionDryRun(rw http.ResponseWriter, r *http.Reques
 			Valid:      true,
 			RawMessage: metadataRaw,
 		},
+		// Dry-runs are shown to users while they create a workspace.
+		Priority: database.ProvisionerJobPriorityInteractive,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -1270,6 +1273,10 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 		if err != nil {
 			return xerrors.Errorf("update active version: %w", err)
 		}
+		err = endActiveTemplateVersionCanary(ctx, store, template.ID, req.ID)
+		if err != nil {
+			return xerrors.Errorf("end active canary: %w", err)
+		}
 		return nil
 	}, nil)
 	if err != nil {
@@ -1471,6 +1478,7 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 				Valid:      true,
 				RawMessage: traceMetadataRaw,
 			},
+			Priority: database.ProvisionerJobPriorityTemplateImport,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert provisioner job: %w", err)
@@ -1561,6 +1569,31 @@ func (api *API) templateVersionResources(rw http.ResponseWriter, r *http.Request
 	api.provisionerJobResources(rw, r, job)
 }
 
+// @Summary Get template version timings by ID
+// @ID get-template-version-timings-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Success 200 {array} codersdk.ProvisionerTiming
+// @Router /templateversions/{templateversion}/timings [get]
+func (api *API) templateVersionTimings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx             = r.Context()
+		templateVersion = httpmw.TemplateVersionParam(r)
+	)
+
+	timings, err := api.Database.GetProvisionerJobTimingsByJobID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job timings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, db2sdk.ProvisionerTimings(timings))
+}
+
 // templateVersionLogs returns the logs returned by the provisioner for the given
 // template version. These logs are only associated with the template version,
 // and not any build logs for a workspace.
@@ -6,6 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand" //#nosec // this is only used for shuffling an array to pick random jobs to unhang
+	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -13,6 +16,7 @@ import (
 	"github.com/google/uuid"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
@@ -33,6 +37,12 @@ const (
 	// time after failing to send an update to the job.
 	HungJobExitTimeout = 3 * time.Minute
 
+	// DefaultBuildCancelGracePeriod is the time a workspace build job that
+	// exceeded its template's build timeout is given to exit gracefully when
+	// the template doesn't set a cancel grace period. It matches the default
+	// force cancel interval of provisioner daemons.
+	DefaultBuildCancelGracePeriod = 10 * time.Minute
+
 	// MaxJobsPerRun is the maximum number of hung jobs that the detector will
 	// terminate in a single run.
 	MaxJobsPerRun = 10
@@ -48,6 +58,31 @@ var HungJobLogMessages = []string{
 	"",
 }
 
+// terminationReason is the reason a job is forcefully terminated.
+type terminationReason string
+
+const (
+	// terminationReasonHung is used for jobs that have not received an update
+	// for HungJobDuration.
+	terminationReasonHung terminationReason = "hung"
+	// terminationReasonTimeout is used for workspace build jobs that have
+	// exceeded their template's build timeout and cancel grace period.
+	terminationReasonTimeout terminationReason = "timeout"
+)
+
+// TimedOutJobLogMessages returns the messages written to provisioner job logs
+// when a workspace build exceeds its template's build timeout and is
+// terminated.
+func TimedOutJobLogMessages(timeout time.Duration) []string {
+	return []string{
+		"",
+		"====================",
+		fmt.Sprintf("Coder: Build exceeded the template's build timeout of %s and will be terminated.", timeout),
+		"====================",
+		"",
+	}
+}
+
 // acquireLockError is returned when the detector fails to acquire a lock and
 // cancels the current run.
 type acquireLockError struct{}
@@ -80,6 +115,8 @@ type Detector struct {
 	log    slog.Logger
 	tick   <-chan time.Time
 	stats  chan<- Stats
+
+	auditor *atomic.Pointer[audit.Auditor]
 }
 
 // Stats contains statistics about the last run of the detector.
@@ -118,6 +155,13 @@ func (d *Detector) WithStatsChannel(ch chan<- Stats) *Detector {
 	return d
 }
 
+// WithAuditor will cause the detector to write an audit log for every
+// workspace build it terminates.
+func (d *Detector) WithAuditor(auditor *atomic.Pointer[audit.Auditor]) *Detector {
+	d.auditor = auditor
+	return d
+}
+
 // Start will cause the detector to detect and unhang provisioner jobs on every
 // tick from its channel. It will stop when its context is Done, or when its
 // channel is closed.
@@ -180,6 +224,30 @@ func (d *Detector) run(t time.Time) Stats {
 		return stats
 	}
 
+	// Find all workspace build jobs that have exceeded their template's
+	// build timeout and haven't exited within the cancel grace period.
+	timedOutJobs, err := d.db.GetTimedOutProvisionerJobs(ctx, database.GetTimedOutProvisionerJobsParams{
+		DefaultCancelGracePeriod: int64(DefaultBuildCancelGracePeriod),
+		Now:                      t,
+	})
+	if err != nil {
+		stats.Error = xerrors.Errorf("get timed out provisioner jobs: %w", err)
+		return stats
+	}
+
+	// A job can be both hung and timed out, in which case the timeout is
+	// the more useful reason to report.
+	reasons := make(map[uuid.UUID]terminationReason, len(jobs)+len(timedOutJobs))
+	for _, job := range jobs {
+		reasons[job.ID] = terminationReasonHung
+	}
+	for _, job := range timedOutJobs {
+		if _, ok := reasons[job.ID]; !ok {
+			jobs = append(jobs, job)
+		}
+		reasons[job.ID] = terminationReasonTimeout
+	}
+
 	// Limit the number of jobs we'll unhang in a single run to avoid
 	// timing out.
 	if len(jobs) > MaxJobsPerRun {
@@ -190,28 +258,123 @@ func (d *Detector) run(t time.Time) Stats {
 		jobs = jobs[:MaxJobsPerRun]
 	}
 
-	// Send a message into the build log for each hung job saying that it
-	// has been detected and will be terminated, then mark the job as
-	// failed.
+	// Send a message into the build log for each hung or timed out job
+	// saying that it has been detected and will be terminated, then mark
+	// the job as failed.
 	for _, job := range jobs {
-		log := d.log.With(slog.F("job_id", job.ID))
+		reason := reasons[job.ID]
+		log := d.log.With(slog.F("job_id", job.ID), slog.F("reason", reason))
 
-		err := unhangJob(ctx, log, d.db, d.pubsub, job.ID)
+		jobErr, err := unhangJob(ctx, log, d.db, d.pubsub, job.ID, reason)
 		if err != nil {
 			if !(xerrors.As(err, &acquireLockError{}) || xerrors.As(err, &jobInelligibleError{})) {
-				log.Error(ctx, "error forcefully terminating hung provisioner job", slog.Error(err))
+				log.Error(ctx, "error forcefully terminating provisioner job", slog.Error(err))
 			}
 			continue
 		}
 
 		stats.TerminatedJobIDs = append(stats.TerminatedJobIDs, job.ID)
+
+		if job.Type == database.ProvisionerJobTypeWorkspaceBuild {
+			d.auditTerminatedBuild(ctx, log, job, jobErr)
+		}
 	}
 
 	return stats
 }
 
-func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubsub.Pubsub, jobID uuid.UUID) error {
-	var lowestLogID int64
+// auditTerminatedBuild writes an audit log for a workspace build that was
+// terminated by the detector, including the reason it was terminated.
+func (d *Detector) auditTerminatedBuild(ctx context.Context, log slog.Logger, job database.ProvisionerJob, jobErr string) {
+	if d.auditor == nil {
+		return
+	}
+	auditor := d.auditor.Load()
+	if auditor == nil {
+		return
+	}
+
+	build, err := d.db.GetWorkspaceBuildByJobID(ctx, job.ID)
+	if err != nil {
+		log.Error(ctx, "audit log - get build", slog.Error(err))
+		return
+	}
+	workspace, err := d.db.GetWorkspaceByID(ctx, build.WorkspaceID)
+	if err != nil {
+		log.Error(ctx, "audit log - get workspace", slog.Error(err))
+		return
+	}
+	previousBuild, err := d.db.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, database.GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams{
+		WorkspaceID: workspace.ID,
+		BuildNumber: build.BuildNumber - 1,
+	})
+	if err != nil {
+		previousBuild = database.WorkspaceBuild{}
+	}
+
+	additionalFields, err := json.Marshal(audit.AdditionalFields{
+		WorkspaceName: workspace.Name,
+		BuildNumber:   strconv.FormatInt(int64(build.BuildNumber), 10),
+		BuildReason:   build.Reason,
+		WorkspaceID:   workspace.ID,
+		FailureReason: jobErr,
+	})
+	if err != nil {
+		log.Error(ctx, "marshal workspace resource info for terminated job", slog.Error(err))
+	}
+
+	action := database.AuditActionWrite
+	switch build.Transition {
+	case database.WorkspaceTransitionStart:
+		action = database.AuditActionStart
+	case database.WorkspaceTransitionStop:
+		action = database.AuditActionStop
+	case database.WorkspaceTransitionDelete:
+		action = database.AuditActionDelete
+	}
+
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.WorkspaceBuild]{
+		Audit:            *auditor,
+		Log:              log,
+		UserID:           job.InitiatorID,
+		OrganizationID:   workspace.OrganizationID,
+		RequestID:        job.ID,
+		Action:           action,
+		Old:              previousBuild,
+		New:              build,
+		Status:           http.StatusInternalServerError,
+		AdditionalFields: additionalFields,
+	})
+}
+
+// buildTimeout returns the build timeout and cancel grace period of the
+// template a workspace build job belongs to.
+func buildTimeout(ctx context.Context, db database.Store, job database.ProvisionerJob) (timeout, gracePeriod time.Duration, err error) {
+	build, err := db.GetWorkspaceBuildByJobID(ctx, job.ID)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("get workspace build: %w", err)
+	}
+	workspace, err := db.GetWorkspaceByID(ctx, build.WorkspaceID)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("get workspace: %w", err)
+	}
+	template, err := db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return 0, 0, xerrors.Errorf("get template: %w", err)
+	}
+	gracePeriod = time.Duration(template.BuildCancelGracePeriod)
+	if gracePeriod <= 0 {
+		gracePeriod = DefaultBuildCancelGracePeriod
+	}
+	return time.Duration(template.BuildTimeout), gracePeriod, nil
+}
+
+// unhangJob terminates the job and returns the error it was failed with.
+func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubsub.Pubsub, jobID uuid.UUID, reason terminationReason) (string, error) {
+	var (
+		lowestLogID int64
+		jobErr      string
+	)
 
 	err := db.InTx(func(db database.Store) error {
 		locked, err := db.TryAcquireLock(ctx, database.GenLockID(fmt.Sprintf("hang-detector:%s", jobID)))
@@ -242,16 +405,47 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 				Err: xerrors.Errorf("job is completed (status %s)", job.JobStatus),
 			}
 		}
-		if job.UpdatedAt.After(time.Now().Add(-HungJobDuration)) {
-			return jobInelligibleError{
-				Err: xerrors.New("job has been updated recently"),
+
+		logMessages := HungJobLogMessages
+		jobErr = "Coder: Build has been detected as hung for 5 minutes and has been terminated by hang detector."
+		switch reason {
+		case terminationReasonHung:
+			if job.UpdatedAt.After(time.Now().Add(-HungJobDuration)) {
+				return jobInelligibleError{
+					Err: xerrors.New("job has been updated recently"),
+				}
 			}
-		}
 
-		log.Warn(
-			ctx, "detected hung provisioner job, forcefully terminating",
-			"threshold", HungJobDuration,
-		)
+			log.Warn(
+				ctx, "detected hung provisioner job, forcefully terminating",
+				"threshold", HungJobDuration,
+			)
+		case terminationReasonTimeout:
+			timeout, gracePeriod, err := buildTimeout(ctx, db, job)
+			if err != nil {
+				return err
+			}
+			if timeout <= 0 {
+				return jobInelligibleError{
+					Err: xerrors.New("template no longer has a build timeout"),
+				}
+			}
+			if job.StartedAt.Time.Add(timeout + gracePeriod).After(time.Now()) {
+				return jobInelligibleError{
+					Err: xerrors.New("job has not exceeded the build timeout"),
+				}
+			}
+
+			log.Warn(
+				ctx, "detected timed out provisioner job, forcefully terminating",
+				slog.F("timeout", timeout),
+				slog.F("grace_period", gracePeriod),
+			)
+			logMessages = TimedOutJobLogMessages(timeout)
+			jobErr = fmt.Sprintf("Coder: Build exceeded the template's build timeout of %s and has been terminated.", timeout)
+		default:
+			return xerrors.Errorf("unknown termination reason %q", reason)
+		}
 
 		// First, get the latest logs from the build so we can make sure
 		// our messages are in the latest stage.
@@ -280,7 +474,7 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 			Output:    nil,
 		}
 		now := dbtime.Now()
-		for i, msg := range HungJobLogMessages {
+		for i, msg := range logMessages {
 			// Set the created at in a way that ensures each message has
 			// a unique timestamp so they will be sorted correctly.
 			insertParams.CreatedAt = append(insertParams.CreatedAt, now.Add(time.Millisecond*time.Duration(i)))
@@ -305,7 +499,7 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 				Valid: true,
 			},
 			Error: sql.NullString{
-				String: "Coder: Build has been detected as hung for 5 minutes and has been terminated by hang detector.",
+				String: jobErr,
 				Valid:  true,
 			},
 			ErrorCode: sql.NullString{
@@ -352,7 +546,7 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 		return nil
 	}, nil)
 	if err != nil {
-		return xerrors.Errorf("in tx: %w", err)
+		return "", xerrors.Errorf("in tx: %w", err)
 	}
 
 	// Publish the new log notification to pubsub. Use the lowest log ID
@@ -362,12 +556,12 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 		EndOfLogs:    true,
 	})
 	if err != nil {
-		return xerrors.Errorf("marshal log notification: %w", err)
+		return "", xerrors.Errorf("marshal log notification: %w", err)
 	}
 	err = pub.Publish(provisionersdk.ProvisionerJobLogsNotifyChannel(jobID), data)
 	if err != nil {
-		return xerrors.Errorf("publish log notification: %w", err)
+		return "", xerrors.Errorf("publish log notification: %w", err)
 	}
 
-	return nil
+	return jobErr, nil
 }
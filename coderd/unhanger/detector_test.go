@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"go.uber.org/goleak"
 
 	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbgen"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
@@ -591,6 +593,157 @@ func TestDetectorHungCanceledJob(t *testing.T) {
 	detector.Wait()
 }
 
+func TestDetectorTimedOutWorkspaceBuild(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx        = testutil.Context(t, testutil.WaitLong)
+		db, pubsub = dbtestutil.NewDB(t)
+		log        = slogtest.Make(t, nil)
+		tickCh     = make(chan time.Time)
+		statsCh    = make(chan unhanger.Stats)
+		auditor    = audit.NewMock()
+	)
+
+	var (
+		now          = time.Now()
+		fortyMinAgo  = now.Add(-time.Minute * 40)
+		twentyMinAgo = now.Add(-time.Minute * 20)
+		oneMinAgo    = now.Add(-time.Minute)
+		org          = dbgen.Organization(t, db, database.Organization{})
+		user         = dbgen.User(t, db, database.User{})
+		file         = dbgen.File(t, db, database.File{})
+	)
+
+	// newBuild creates a running workspace build on a new template with the
+	// given build timeout. The job was started at startedAt and received an
+	// update a minute ago, so it isn't hung.
+	newBuild := func(timeout, gracePeriod time.Duration, startedAt time.Time) database.ProvisionerJob {
+		template := dbgen.Template(t, db, database.Template{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+		})
+		err := db.UpdateTemplateMetaByID(ctx, database.UpdateTemplateMetaByIDParams{
+			ID:                     template.ID,
+			UpdatedAt:              now,
+			Name:                   template.Name,
+			DisplayName:            template.DisplayName,
+			Description:            template.Description,
+			Icon:                   template.Icon,
+			GroupACL:               template.GroupACL,
+			MaxPortSharingLevel:    template.MaxPortSharingLevel,
+			BuildTimeout:           int64(timeout),
+			BuildCancelGracePeriod: int64(gracePeriod),
+		})
+		require.NoError(t, err)
+		templateVersion := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			TemplateID: uuid.NullUUID{
+				UUID:  template.ID,
+				Valid: true,
+			},
+			CreatedBy: user.ID,
+		})
+		workspace := dbgen.Workspace(t, db, database.Workspace{
+			OwnerID:        user.ID,
+			OrganizationID: org.ID,
+			TemplateID:     template.ID,
+		})
+		job := dbgen.ProvisionerJob(t, db, pubsub, database.ProvisionerJob{
+			CreatedAt: startedAt,
+			StartedAt: sql.NullTime{
+				Time:  startedAt,
+				Valid: true,
+			},
+			OrganizationID: org.ID,
+			InitiatorID:    user.ID,
+			Provisioner:    database.ProvisionerTypeEcho,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			FileID:         file.ID,
+			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			Input:          []byte("{}"),
+		})
+		// Acquiring the job sets the update time to the start time.
+		err = db.UpdateProvisionerJobByID(ctx, database.UpdateProvisionerJobByIDParams{
+			ID:        job.ID,
+			UpdatedAt: oneMinAgo,
+		})
+		require.NoError(t, err)
+		_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID:       workspace.ID,
+			TemplateVersionID: templateVersion.ID,
+			BuildNumber:       1,
+			JobID:             job.ID,
+		})
+		return job
+	}
+
+	var (
+		// Exceeded the timeout and the grace period.
+		timedOutJob = newBuild(30*time.Minute, time.Minute, fortyMinAgo)
+		// Exceeded the timeout, but is still within the default grace
+		// period.
+		withinGracePeriodJob = newBuild(15*time.Minute, 0, twentyMinAgo)
+		// No build timeout.
+		noTimeoutJob = newBuild(0, 0, fortyMinAgo)
+	)
+
+	t.Log("timed out job ID: ", timedOutJob.ID)
+	t.Log("within grace period job ID: ", withinGracePeriodJob.ID)
+	t.Log("no timeout job ID: ", noTimeoutJob.ID)
+
+	var auditorPtr atomic.Pointer[audit.Auditor]
+	var a audit.Auditor = auditor
+	auditorPtr.Store(&a)
+
+	detector := unhanger.New(ctx, db, pubsub, log, tickCh).WithStatsChannel(statsCh).WithAuditor(&auditorPtr)
+	detector.Start()
+	tickCh <- now
+
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.TerminatedJobIDs, 1)
+	require.Equal(t, timedOutJob.ID, stats.TerminatedJobIDs[0])
+
+	// Check that the job was failed with the timeout as the reason.
+	job, err := db.GetProvisionerJobByID(ctx, timedOutJob.ID)
+	require.NoError(t, err)
+	require.True(t, job.CompletedAt.Valid)
+	require.True(t, job.Error.Valid)
+	require.Contains(t, job.Error.String, "exceeded the template's build timeout of 30m0s")
+	require.False(t, job.ErrorCode.Valid)
+
+	logs, err := db.GetProvisionerLogsAfterID(ctx, database.GetProvisionerLogsAfterIDParams{
+		JobID: timedOutJob.ID,
+	})
+	require.NoError(t, err)
+	expectedLogs := unhanger.TimedOutJobLogMessages(30 * time.Minute)
+	require.Len(t, logs, len(expectedLogs))
+	for i, log := range logs {
+		assert.Equal(t, expectedLogs[i], log.Output)
+	}
+
+	// Check that the termination was audited with the reason.
+	auditLogs := auditor.AuditLogs()
+	require.Len(t, auditLogs, 1)
+	require.Equal(t, database.ResourceTypeWorkspaceBuild, auditLogs[0].ResourceType)
+	require.Equal(t, timedOutJob.ID, auditLogs[0].RequestID)
+	var fields audit.AdditionalFields
+	err = json.Unmarshal(auditLogs[0].AdditionalFields, &fields)
+	require.NoError(t, err)
+	require.Equal(t, job.Error.String, fields.FailureReason)
+
+	// The other jobs are left running.
+	for _, id := range []uuid.UUID{withinGracePeriodJob.ID, noTimeoutJob.ID} {
+		job, err := db.GetProvisionerJobByID(ctx, id)
+		require.NoError(t, err)
+		require.False(t, job.CompletedAt.Valid)
+	}
+
+	detector.Close()
+	detector.Wait()
+}
+
 func TestDetectorPushesLogs(t *testing.T) {
 	t.Parallel()
 
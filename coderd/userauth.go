@@ -20,7 +20,6 @@ import (
 	"github.com/google/go-github/v43/github"
 	"github.com/google/uuid"
 	"github.com/moby/moby/pkg/namesgenerator"
-	"golang.org/x/exp/slices"
 	"golang.org/x/oauth2"
 	"golang.org/x/xerrors"
 
@@ -241,12 +240,16 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 		// user failed to login
 		return
 	}
+	if !api.loginMFA(ctx, rw, user, loginWithPassword) {
+		return
+	}
 
 	//nolint:gocritic // Creating the API key as the user instead of as system.
 	cookie, key, err := api.createAPIKey(dbauthz.As(ctx, actor), apikey.CreateParams{
 		UserID:          user.ID,
 		LoginType:       database.LoginTypePassword,
 		RemoteAddr:      r.RemoteAddr,
+		UserAgent:       r.UserAgent(),
 		DefaultLifetime: api.DeploymentValues.Sessions.DefaultDuration.Value(),
 	})
 	if err != nil {
@@ -476,6 +479,7 @@ func (api *API) userAuthMethods(rw http.ResponseWriter, r *http.Request) {
 			SignInText: signInText,
 			IconURL:    iconURL,
 		},
+		LDAP: codersdk.AuthMethod{Enabled: api.LDAPConfig != nil},
 	})
 }
 
@@ -767,6 +771,15 @@ type OIDCConfig struct {
 	// UserRolesDefault is the default set of roles to assign to a user if role sync
 	// is enabled.
 	UserRolesDefault []string
+	// OrganizationField selects the claim field to be used to sync the
+	// user's organization memberships. If the field is the empty string,
+	// then no organization updates will ever come from the OIDC provider.
+	OrganizationField string
+	// OrganizationMapping controls how values returned by the OIDC provider
+	// get mapped to organizations within Coder. Organizations are referenced
+	// by ID or name.
+	// map[oidcOrganization][]coderOrganization
+	OrganizationMapping map[string][]string
 	// SignInText is the text to display on the OIDC login button
 	SignInText string
 	// IconURL points to the URL of an icon to display on the OIDC login button
@@ -779,6 +792,10 @@ func (cfg OIDCConfig) RoleSyncEnabled() bool {
 	return cfg.UserRoleField != ""
 }
 
+func (cfg OIDCConfig) OrganizationSyncEnabled() bool {
+	return cfg.OrganizationField != ""
+}
+
 // @Summary OpenID Connect Callback
 // @ID openid-connect-callback
 // @Security CoderSessionToken
@@ -1010,6 +1027,12 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	organizations, orgErr := api.oidcOrganizations(ctx, mergedClaims)
+	if orgErr != nil {
+		orgErr.Write(rw, r)
+		return
+	}
+
 	user, link, err := findLinkedUser(ctx, api.Database, oidcLinkedID(idToken), email)
 	if err != nil {
 		logger.Error(ctx, "oauth2: unable to find linked user", slog.F("email", email), slog.Error(err))
@@ -1043,6 +1066,8 @@ func (api *API) userOIDC(rw http.ResponseWriter, r *http.Request) {
 		Groups:              groups,
 		CreateMissingGroups: api.OIDCConfig.CreateMissingGroups,
 		GroupFilter:         api.OIDCConfig.GroupFilter,
+		UsingOrganizations:  api.OIDCConfig.OrganizationSyncEnabled(),
+		Organizations:       organizations,
 		DebugContext: OauthDebugContext{
 			IDTokenClaims:  idtokenClaims,
 			UserInfoClaims: userInfoClaims,
@@ -1207,6 +1232,97 @@ func (api *API) oidcRoles(ctx context.Context, mergedClaims map[string]interface
 	return roles, nil
 }
 
+// oidcOrganizations returns the organizations for the user from the OIDC
+// claims. Organizations are returned as configured, so they may be IDs or
+// names.
+func (api *API) oidcOrganizations(ctx context.Context, mergedClaims map[string]interface{}) ([]string, *httpError) {
+	if !api.OIDCConfig.OrganizationSyncEnabled() {
+		return nil, nil
+	}
+
+	orgsRaw, ok := mergedClaims[api.OIDCConfig.OrganizationField]
+	if !ok {
+		// A missing claim means the user belongs to no organizations
+		// beyond the default one. IDPs omit claims if they are empty.
+		orgsRaw = []interface{}{}
+	}
+
+	parsedOrgs, err := parseStringSliceClaim(orgsRaw)
+	if err != nil {
+		api.Logger.Error(ctx, "oidc claims organization field was an unknown type",
+			slog.F("type", fmt.Sprintf("%T", orgsRaw)),
+			slog.Error(err),
+		)
+		return nil, &httpError{
+			code:             http.StatusInternalServerError,
+			msg:              "Login disabled until OIDC config is fixed",
+			detail:           fmt.Sprintf("Organizations claim must be an array of strings, type found: %T. Disabling organization sync will allow login to proceed.", orgsRaw),
+			renderStaticPage: false,
+		}
+	}
+
+	api.Logger.Debug(ctx, "organizations returned in oidc claims",
+		slog.F("len", len(parsedOrgs)),
+		slog.F("organizations", parsedOrgs),
+	)
+	orgs := make([]string, 0, len(parsedOrgs))
+	for _, org := range parsedOrgs {
+		if mappedOrgs, ok := api.OIDCConfig.OrganizationMapping[org]; ok {
+			orgs = append(orgs, mappedOrgs...)
+			continue
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+// OIDCClaimSync is what a set of OIDC claims resolves to under the
+// deployment's OIDC sync settings.
+type OIDCClaimSync struct {
+	UsingOrganizations bool
+	// Organizations are organization IDs or names.
+	Organizations []string
+	UsingGroups   bool
+	// Groups are already mapped and filtered.
+	Groups []string
+}
+
+// OIDCClaimSync resolves the organizations and groups a set of merged OIDC
+// claims would sync to on login, without touching the database. It is used
+// to preview the effect of the OIDC sync settings. OIDC must be configured.
+func (api *API) OIDCClaimSync(ctx context.Context, mergedClaims map[string]interface{}) (OIDCClaimSync, error) {
+	usingGroups, groups, groupErr := api.oidcGroups(ctx, mergedClaims)
+	if groupErr != nil {
+		return OIDCClaimSync{}, *groupErr
+	}
+	orgs, orgErr := api.oidcOrganizations(ctx, mergedClaims)
+	if orgErr != nil {
+		return OIDCClaimSync{}, *orgErr
+	}
+
+	return OIDCClaimSync{
+		UsingOrganizations: api.OIDCConfig.OrganizationSyncEnabled(),
+		Organizations:      orgs,
+		UsingGroups:        usingGroups,
+		Groups:             filterGroups(groups, api.OIDCConfig.GroupFilter),
+	}, nil
+}
+
+// filterGroups returns the groups matching the filter. A nil filter matches
+// all groups.
+func filterGroups(groups []string, filter *regexp.Regexp) []string {
+	if filter == nil {
+		return groups
+	}
+	filtered := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if filter.MatchString(group) {
+			filtered = append(filtered, group)
+		}
+	}
+	return filtered
+}
+
 // claimFields returns the sorted list of fields in the claims map.
 func claimFields(claims map[string]interface{}) []string {
 	fields := []string{}
@@ -1276,6 +1392,10 @@ type oauthLoginParams struct {
 	// the roles provided.
 	UsingRoles bool
 	Roles      []string
+	// Is UsingOrganizations is true, then the user's organization
+	// memberships will be synced to the Organizations provided.
+	UsingOrganizations bool
+	Organizations      []string
 
 	DebugContext OauthDebugContext
 
@@ -1348,12 +1468,16 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 		logger  = api.Logger.Named(userAuthLoggerName)
 	)
 
-	var isConvertLoginType bool
+	var (
+		isConvertLoginType bool
+		commitOrgAudit     func()
+	)
 	err := api.Database.InTx(func(tx database.Store) error {
 		var (
 			link database.UserLink
 			err  error
 		)
+		commitOrgAudit = nil
 		user = params.User
 		link = params.Link
 
@@ -1373,7 +1497,7 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 
 		if user.ID == uuid.Nil && !params.AllowSignups {
 			signupsDisabledText := "Please contact your Coder administrator to request access."
-			if api.OIDCConfig != nil && api.OIDCConfig.SignupsDisabledText != "" {
+			if params.LoginType == database.LoginTypeOIDC && api.OIDCConfig != nil && api.OIDCConfig.SignupsDisabledText != "" {
 				signupsDisabledText = render.HTMLFromMarkdown(api.OIDCConfig.SignupsDisabledText)
 			}
 			return httpError{
@@ -1503,28 +1627,23 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 			}
 		}
 
-		// Ensure groups are correct.
-		// This places all groups into the default organization.
-		// To go multi-org, we need to add a mapping feature here to know which
-		// groups go to which orgs.
-		if params.UsingGroups {
-			filtered := params.Groups
-			if params.GroupFilter != nil {
-				filtered = make([]string, 0, len(params.Groups))
-				for _, group := range params.Groups {
-					if params.GroupFilter.MatchString(group) {
-						filtered = append(filtered, group)
-					}
-				}
-			}
-
+		// Ensure organization memberships are correct. This runs before
+		// group sync so groups are synced within the resulting orgs. The
+		// changes are audited once the transaction has been committed.
+		if params.UsingOrganizations {
 			//nolint:gocritic // No user present in the context.
-			defaultOrganization, err := tx.GetDefaultOrganization(dbauthz.AsSystemRestricted(ctx))
+			commitOrgAudit, err = api.Options.SetUserOrganizations(dbauthz.AsSystemRestricted(ctx), logger, tx, user.ID, params.Organizations)
 			if err != nil {
-				// If there is no default org, then we can't assign groups.
-				// By default, we assume all groups belong to the default org.
-				return xerrors.Errorf("get default organization: %w", err)
+				return xerrors.Errorf("set user organizations: %w", err)
 			}
+		}
+
+		// Ensure groups are correct.
+		// Group names are scoped to each organization the user is a member
+		// of, so a group is only assigned in the orgs where it exists. Missing
+		// groups are only created in the default organization.
+		if params.UsingGroups {
+			filtered := filterGroups(params.Groups, params.GroupFilter)
 
 			//nolint:gocritic // No user present in the context.
 			memberships, err := tx.OrganizationMembers(dbauthz.AsSystemRestricted(ctx), database.OrganizationMembersParams{
@@ -1535,18 +1654,13 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 				return xerrors.Errorf("get organization memberships: %w", err)
 			}
 
-			// If the user is not in the default organization, then we can't assign groups.
-			// A user cannot be in groups to an org they are not a member of.
-			if !slices.ContainsFunc(memberships, func(member database.OrganizationMembersRow) bool {
-				return member.OrganizationMember.OrganizationID == defaultOrganization.ID
-			}) {
-				return xerrors.Errorf("user %s is not a member of the default organization, cannot assign to groups in the org", user.ID)
+			orgGroupNames := make(map[uuid.UUID][]string, len(memberships))
+			for _, member := range memberships {
+				orgGroupNames[member.OrganizationMember.OrganizationID] = filtered
 			}
 
 			//nolint:gocritic
-			err = api.Options.SetUserGroups(dbauthz.AsSystemRestricted(ctx), logger, tx, user.ID, map[uuid.UUID][]string{
-				defaultOrganization.ID: filtered,
-			}, params.CreateMissingGroups)
+			err = api.Options.SetUserGroups(dbauthz.AsSystemRestricted(ctx), logger, tx, user.ID, orgGroupNames, params.CreateMissingGroups)
 			if err != nil {
 				return xerrors.Errorf("set user groups: %w", err)
 			}
@@ -1632,6 +1746,9 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 	if err != nil {
 		return nil, database.User{}, database.APIKey{}, xerrors.Errorf("in tx: %w", err)
 	}
+	if commitOrgAudit != nil {
+		commitOrgAudit()
+	}
 
 	var key database.APIKey
 	oldKey, _, ok := httpmw.APIKeyFromRequest(ctx, api.Database, nil, r)
@@ -1665,6 +1782,7 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 			LoginType:       params.LoginType,
 			DefaultLifetime: api.DeploymentValues.Sessions.DefaultDuration.Value(),
 			RemoteAddr:      r.RemoteAddr,
+			UserAgent:       r.UserAgent(),
 		})
 		if err != nil {
 			return nil, database.User{}, database.APIKey{}, xerrors.Errorf("create API key: %w", err)
@@ -1857,7 +1975,8 @@ func clearOAuthConvertCookie() *http.Cookie {
 
 func wrongLoginTypeHTTPError(user database.LoginType, params database.LoginType) httpError {
 	addedMsg := ""
-	if user == database.LoginTypePassword {
+	// Password accounts cannot be converted to LDAP.
+	if user == database.LoginTypePassword && params != database.LoginTypeLDAP {
 		addedMsg = " You can convert your account to use this login type by visiting your account settings."
 	}
 	return httpError{
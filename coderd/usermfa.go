@@ -0,0 +1,485 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/usermfa"
+	"github.com/coder/coder/v2/coderd/util/slice"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+type mfaAuditFields struct {
+	TOTP string `json:"totp"`
+}
+
+// @Summary Get user MFA status
+// @ID get-user-mfa-status
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {object} codersdk.UserMFAStatus
+// @Router /users/{user}/mfa [get]
+func (api *API) userMFAStatus(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+	)
+
+	if !api.Authorize(r, policy.ActionReadPersonal, user) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	status := codersdk.UserMFAStatus{
+		Required: api.ownerMFARequired(user),
+	}
+	totp, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if err == nil && totp.EnabledAt.Valid {
+		status.TOTPEnabled = true
+		status.RecoveryCodesRemaining = len(totp.HashedRecoveryCodes)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, status)
+}
+
+// Generates a new TOTP secret and recovery codes for the user. TOTP is not
+// enabled until the user confirms they added the secret to their
+// authenticator app with a valid code.
+//
+// @Summary Enroll user TOTP
+// @ID enroll-user-totp
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 201 {object} codersdk.TOTPEnrollment
+// @Router /users/{user}/mfa/totp [post]
+func (api *API) postUserTOTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		user = httpmw.UserParam(r)
+		key  = httpmw.APIKey(r)
+	)
+
+	if key.UserID != user.ID {
+		// A second factor must be in the possession of the user, so nobody
+		// else can enroll one for them.
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "You can only enroll TOTP for yourself.",
+		})
+		return
+	}
+
+	api.enrollTOTP(ctx, rw, user)
+}
+
+// Starts a TOTP enrollment for a user that cannot log in until they have
+// enrolled. The enrollment is confirmed by logging in with a code from the
+// new secret.
+//
+// @Summary Enroll TOTP during login
+// @ID enroll-totp-during-login
+// @Accept json
+// @Produce json
+// @Tags Authorization
+// @Param request body codersdk.LoginWithPasswordRequest true "Login request"
+// @Success 201 {object} codersdk.TOTPEnrollment
+// @Router /users/login/mfa/totp [post]
+func (api *API) postLoginTOTP(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req codersdk.LoginWithPasswordRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	user, _, ok := api.loginRequest(ctx, rw, req)
+	if !ok {
+		return
+	}
+
+	//nolint:gocritic // The user does not have a session yet.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	totp, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Otherwise anyone with the password could replace the second factor.
+	if !api.ownerMFARequired(user) || (err == nil && totp.EnabledAt.Valid) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "TOTP enrollment during login is only available to users that are required to enroll.",
+		})
+		return
+	}
+
+	api.enrollTOTP(ctx, rw, user)
+}
+
+func (api *API) enrollTOTP(ctx context.Context, rw http.ResponseWriter, user database.User) {
+	if user.LoginType != database.LoginTypePassword {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "TOTP is only available to users with the password login type.",
+		})
+		return
+	}
+
+	existing, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if err == nil && existing.EnabledAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "TOTP is already enabled. Ask an administrator to reset it to enroll again.",
+		})
+		return
+	}
+
+	key, err := usermfa.GenerateTOTP("Coder", user.Username+"@"+api.AccessURL.Hostname())
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error generating TOTP secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	codes, hashedCodes, err := usermfa.GenerateRecoveryCodes()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error generating recovery codes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	_, err = api.Database.UpsertUserTOTP(ctx, database.UpsertUserTOTPParams{
+		UserID:              user.ID,
+		Secret:              key.Secret,
+		HashedRecoveryCodes: hashedCodes,
+		CreatedAt:           dbtime.Now(),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error saving TOTP secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.TOTPEnrollment{
+		Secret:        key.Secret,
+		URL:           key.URL,
+		QRCode:        key.QRCode,
+		RecoveryCodes: codes,
+	})
+}
+
+// @Summary Confirm user TOTP enrollment
+// @ID confirm-user-totp-enrollment
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.ConfirmTOTPRequest true "Confirm request"
+// @Success 204
+// @Router /users/{user}/mfa/totp/confirm [post]
+func (api *API) postUserTOTPConfirm(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		key               = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
+			Audit:            auditor,
+			Log:              api.Logger,
+			Request:          r,
+			Action:           database.AuditActionWrite,
+			AdditionalFields: mfaAuditFields{TOTP: "enabled"},
+		})
+	)
+	defer commitAudit()
+	aReq.Old = user
+
+	if key.UserID != user.ID {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "You can only enroll TOTP for yourself.",
+		})
+		return
+	}
+
+	var req codersdk.ConfirmTOTPRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	totp, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "There is no pending TOTP enrollment.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if totp.EnabledAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "TOTP is already enabled.",
+		})
+		return
+	}
+	ok, err := api.useTOTPCode(ctx, totp, req.Code)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error checking one-time code.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid one-time code.",
+			Validations: []codersdk.ValidationError{
+				{Field: "code", Detail: "The code does not match the secret or has already been used. Check the time on your device."},
+			},
+		})
+		return
+	}
+
+	err = api.Database.UpdateUserTOTPEnabledAt(ctx, database.UpdateUserTOTPEnabledAtParams{
+		UserID:    user.ID,
+		EnabledAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error enabling TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = user
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// Removes the TOTP secret and recovery codes of a user that lost access to
+// their authenticator app. Users cannot remove their own second factor.
+//
+// @Summary Reset user TOTP
+// @ID reset-user-totp
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 204
+// @Router /users/{user}/mfa/totp [delete]
+func (api *API) deleteUserTOTP(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		user              = httpmw.UserParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
+			Audit:            auditor,
+			Log:              api.Logger,
+			Request:          r,
+			Action:           database.AuditActionWrite,
+			AdditionalFields: mfaAuditFields{TOTP: "reset"},
+		})
+	)
+	defer commitAudit()
+	aReq.Old = user
+
+	if !api.Authorize(r, policy.ActionUpdate, user) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteUserTOTPByUserID(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error resetting TOTP.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = user
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// ownerMFARequired returns true if the deployment requires the user to log in
+// with a second factor.
+func (api *API) ownerMFARequired(user database.User) bool {
+	return api.DeploymentValues.RequireOwnerMFA.Value() &&
+		slice.Contains(user.RBACRoles, rbac.RoleOwner().String())
+}
+
+// loginMFA checks the second factor of a password login for a user that has
+// already provided the correct password. If 'false' is returned, the login
+// failed and the appropriate error has been written to the ResponseWriter.
+func (api *API) loginMFA(ctx context.Context, rw http.ResponseWriter, user database.User, req codersdk.LoginWithPasswordRequest) bool {
+	logger := api.Logger.Named(userAuthLoggerName)
+
+	//nolint:gocritic // The user does not have a session yet.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	totp, err := api.Database.GetUserTOTPByUserID(ctx, user.ID)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		logger.Error(ctx, "unable to fetch user totp", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error.",
+		})
+		return false
+	}
+	pending := err == nil && !totp.EnabledAt.Valid
+
+	if err != nil || pending {
+		if !api.ownerMFARequired(user) {
+			return true
+		}
+		// Logging in with a code from a pending enrollment confirms it.
+		if pending && req.TOTPCode != "" {
+			ok, err := api.useTOTPCode(ctx, totp, req.TOTPCode)
+			if err != nil {
+				logger.Error(ctx, "unable to check user totp code", slog.Error(err))
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error.",
+				})
+				return false
+			}
+			if !ok {
+				writeInvalidTOTPCode(ctx, rw)
+				return false
+			}
+			err = api.Database.UpdateUserTOTPEnabledAt(ctx, database.UpdateUserTOTPEnabledAtParams{
+				UserID:    user.ID,
+				EnabledAt: sql.NullTime{Time: dbtime.Now(), Valid: true},
+			})
+			if err != nil {
+				logger.Error(ctx, "unable to enable user totp", slog.Error(err))
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error.",
+				})
+				return false
+			}
+			return true
+		}
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "Multi-factor authentication is required for owners. Enroll an authenticator app to continue.",
+			Validations: []codersdk.ValidationError{
+				{Field: "totp_enrollment", Detail: "TOTP enrollment is required."},
+			},
+		})
+		return false
+	}
+
+	if req.RecoveryCode != "" {
+		// The code is removed in a single statement, so it can only be used
+		// once even by concurrent logins.
+		_, err = api.Database.RemoveUserTOTPRecoveryCode(ctx, database.RemoveUserTOTPRecoveryCodeParams{
+			UserID:             user.ID,
+			HashedRecoveryCode: usermfa.HashRecoveryCode(req.RecoveryCode),
+		})
+		if httpapi.Is404Error(err) {
+			httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+				Message: "Invalid recovery code.",
+				Validations: []codersdk.ValidationError{
+					{Field: "recovery_code", Detail: "The recovery code is invalid or has already been used."},
+				},
+			})
+			return false
+		}
+		if err != nil {
+			logger.Error(ctx, "unable to update user recovery codes", slog.Error(err))
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error.",
+			})
+			return false
+		}
+		return true
+	}
+
+	if req.TOTPCode == "" {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "A one-time code from your authenticator app is required.",
+			Validations: []codersdk.ValidationError{
+				{Field: "totp_code", Detail: "A one-time code is required."},
+			},
+		})
+		return false
+	}
+	ok, err := api.useTOTPCode(ctx, totp, req.TOTPCode)
+	if err != nil {
+		logger.Error(ctx, "unable to check user totp code", slog.Error(err))
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error.",
+		})
+		return false
+	}
+	if !ok {
+		writeInvalidTOTPCode(ctx, rw)
+		return false
+	}
+	return true
+}
+
+// useTOTPCode checks a code against the TOTP secret of a user and records its
+// time step, so that each code is only accepted once. Concurrent requests with
+// the same code race on the update, and only one of them succeeds.
+func (api *API) useTOTPCode(ctx context.Context, totp database.UserTOTP, code string) (bool, error) {
+	counter, ok := usermfa.ValidateTOTP(totp.Secret, code, totp.LastUsedCounter, dbtime.Now())
+	if !ok {
+		return false, nil
+	}
+	_, err := api.Database.UpdateUserTOTPLastUsedCounter(ctx, database.UpdateUserTOTPLastUsedCounterParams{
+		UserID:          totp.UserID,
+		LastUsedCounter: counter,
+	})
+	if httpapi.Is404Error(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, xerrors.Errorf("update last used counter: %w", err)
+	}
+	return true, nil
+}
+
+func writeInvalidTOTPCode(ctx context.Context, rw http.ResponseWriter) {
+	httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+		Message: "Invalid one-time code.",
+		Validations: []codersdk.ValidationError{
+			{Field: "totp_code", Detail: "The code does not match or has already been used. Check the time on your device."},
+		},
+	})
+}
@@ -0,0 +1,125 @@
+// Package usermfa implements time-based one-time passwords (TOTP) and
+// recovery codes used as a second factor for password logins.
+package usermfa
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/cryptorand"
+)
+
+const (
+	// RecoveryCodeCount is the number of recovery codes generated on
+	// enrollment.
+	RecoveryCodeCount = 10
+	// recoveryCodeLength is the number of characters in a recovery code,
+	// excluding the separator.
+	recoveryCodeLength = 10
+	// qrCodeSize is the width and height of the QR code image in pixels.
+	qrCodeSize = 256
+	// totpPeriod is the number of seconds each code is valid for.
+	totpPeriod = 30
+	// totpSkew is the number of periods either side of the current one that
+	// codes are accepted from.
+	totpSkew = 1
+)
+
+// TOTPKey is a newly generated TOTP secret.
+type TOTPKey struct {
+	// Secret is the base32 encoded shared secret.
+	Secret string
+	// URL is the otpauth:// URL that authenticator apps import.
+	URL string
+	// QRCode is a PNG image of URL.
+	QRCode []byte
+}
+
+// GenerateTOTP generates a new TOTP secret for the given account.
+func GenerateTOTP(issuer, accountName string) (TOTPKey, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: accountName,
+		Algorithm:   otp.AlgorithmSHA1,
+		Digits:      otp.DigitsSix,
+	})
+	if err != nil {
+		return TOTPKey{}, xerrors.Errorf("generate totp key: %w", err)
+	}
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return TOTPKey{}, xerrors.Errorf("generate qr code: %w", err)
+	}
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return TOTPKey{}, xerrors.Errorf("encode qr code: %w", err)
+	}
+	return TOTPKey{
+		Secret: key.Secret(),
+		URL:    key.URL(),
+		QRCode: buf.Bytes(),
+	}, nil
+}
+
+// ValidateTOTP checks code against the secret at the given time. Codes from the
+// previous and next period are accepted to allow for clock drift, but only if
+// their time step is after lastCounter, so that an accepted code cannot be
+// used again. The time step of the matching code is returned so it can be
+// stored as the new lastCounter.
+func ValidateTOTP(secret, code string, lastCounter int64, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	counter := now.Unix() / totpPeriod
+	for step := counter - totpSkew; step <= counter+totpSkew; step++ {
+		if step <= lastCounter {
+			continue
+		}
+		want, err := totp.GenerateCodeCustom(secret, time.Unix(step*totpPeriod, 0), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns new single-use recovery codes along with their
+// hashes. Only the hashes should be stored.
+func GenerateRecoveryCodes() (codes []string, hashed []string, err error) {
+	codes = make([]string, 0, RecoveryCodeCount)
+	hashed = make([]string, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := cryptorand.StringCharset(cryptorand.Human, recoveryCodeLength)
+		if err != nil {
+			return nil, nil, xerrors.Errorf("generate recovery code: %w", err)
+		}
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		codes = append(codes, code)
+		hashed = append(hashed, HashRecoveryCode(code))
+	}
+	return codes, hashed, nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage. Codes are compared
+// case-insensitively and without separators.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
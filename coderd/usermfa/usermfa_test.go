@@ -0,0 +1,68 @@
+package usermfa_test
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/usermfa"
+)
+
+func TestTOTP(t *testing.T) {
+	t.Parallel()
+
+	key, err := usermfa.GenerateTOTP("Coder", "user@coder.com")
+	require.NoError(t, err)
+	require.NotEmpty(t, key.Secret)
+	require.True(t, strings.HasPrefix(key.URL, "otpauth://totp/"))
+	_, err = png.Decode(bytes.NewReader(key.QRCode))
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := totp.GenerateCode(key.Secret, now)
+	require.NoError(t, err)
+	counter, ok := usermfa.ValidateTOTP(key.Secret, code, 0, now)
+	require.True(t, ok)
+	require.Equal(t, now.Unix()/30, counter)
+	// Codes are accepted one period either side to allow for clock drift.
+	_, ok = usermfa.ValidateTOTP(key.Secret, code, 0, now.Add(30*time.Second))
+	require.True(t, ok)
+	_, ok = usermfa.ValidateTOTP(key.Secret, code, 0, now.Add(5*time.Minute))
+	require.False(t, ok)
+	_, ok = usermfa.ValidateTOTP(key.Secret, "", 0, now)
+	require.False(t, ok)
+	_, ok = usermfa.ValidateTOTP(key.Secret, "000000x", 0, now)
+	require.False(t, ok)
+
+	// A code cannot be used again once its time step has been used.
+	_, ok = usermfa.ValidateTOTP(key.Secret, code, counter, now)
+	require.False(t, ok)
+	_, ok = usermfa.ValidateTOTP(key.Secret, code, counter, now.Add(30*time.Second))
+	require.False(t, ok)
+	next, err := totp.GenerateCode(key.Secret, now.Add(30*time.Second))
+	require.NoError(t, err)
+	_, ok = usermfa.ValidateTOTP(key.Secret, next, counter, now)
+	require.True(t, ok)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	codes, hashed, err := usermfa.GenerateRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, usermfa.RecoveryCodeCount)
+	require.Len(t, hashed, usermfa.RecoveryCodeCount)
+	for i, code := range codes {
+		require.NotContains(t, hashed, code)
+		require.Equal(t, hashed[i], usermfa.HashRecoveryCode(code))
+	}
+
+	// Codes are matched case-insensitively and without the separator.
+	require.Equal(t, hashed[3], usermfa.HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[3], "-", ""))))
+	require.Equal(t, hashed[3], usermfa.HashRecoveryCode(" "+codes[3]+" "))
+}
@@ -0,0 +1,254 @@
+package coderd_test
+
+import (
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/usermfa"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestUserTOTP(t *testing.T) {
+	t.Parallel()
+
+	ownerClient := coderdtest.New(t, nil)
+	owner := coderdtest.CreateFirstUser(t, ownerClient)
+
+	// enroll enrolls and enables TOTP for the user of the client.
+	enroll := func(t *testing.T, client *codersdk.Client) codersdk.TOTPEnrollment {
+		t.Helper()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		err = client.ConfirmTOTP(ctx, codersdk.Me, codersdk.ConfirmTOTPRequest{Code: code})
+		require.NoError(t, err)
+		return enrollment
+	}
+
+	t.Run("Login", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+		enrollment, err := client.EnrollTOTP(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.NotEmpty(t, enrollment.Secret)
+		require.Contains(t, enrollment.URL, "otpauth://totp/")
+		require.NotEmpty(t, enrollment.QRCode)
+		require.Len(t, enrollment.RecoveryCodes, usermfa.RecoveryCodeCount)
+
+		// TOTP is not enabled until the enrollment is confirmed.
+		status, err := client.UserMFAStatus(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.False(t, status.TOTPEnabled)
+
+		err = client.ConfirmTOTP(ctx, codersdk.Me, codersdk.ConfirmTOTPRequest{Code: "000000"})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		code, err := totp.GenerateCode(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		err = client.ConfirmTOTP(ctx, codersdk.Me, codersdk.ConfirmTOTPRequest{Code: code})
+		require.NoError(t, err)
+
+		status, err = client.UserMFAStatus(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.True(t, status.TOTPEnabled)
+		require.Equal(t, usermfa.RecoveryCodeCount, status.RecoveryCodesRemaining)
+
+		// A password alone is no longer enough.
+		anonClient := codersdk.New(ownerClient.URL)
+		req := codersdk.LoginWithPasswordRequest{
+			Email:    user.Email,
+			Password: "SomeSecurePassword!",
+		}
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+
+		req.TOTPCode = "000000"
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+
+		// The code used to confirm the enrollment cannot be replayed.
+		req.TOTPCode = code
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+
+		// The code of the next period is accepted to allow for clock drift,
+		// but only once.
+		req.TOTPCode, err = totp.GenerateCode(enrollment.Secret, time.Now().Add(30*time.Second))
+		require.NoError(t, err)
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.NoError(t, err)
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+
+		// Recovery codes can be used once instead of a code.
+		req.TOTPCode = ""
+		req.RecoveryCode = enrollment.RecoveryCodes[0]
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.NoError(t, err)
+		_, err = anonClient.LoginWithPassword(ctx, req)
+		require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+
+		status, err = client.UserMFAStatus(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, usermfa.RecoveryCodeCount-1, status.RecoveryCodesRemaining)
+
+		// Enrolling again requires an administrator to reset TOTP first.
+		_, err = client.EnrollTOTP(ctx, codersdk.Me)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("ConcurrentRecoveryCodes", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		enrollment := enroll(t, client)
+
+		login := func(recoveryCode string) error {
+			_, err := codersdk.New(ownerClient.URL).LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+				Email:        user.Email,
+				Password:     "SomeSecurePassword!",
+				RecoveryCode: recoveryCode,
+			})
+			return err
+		}
+
+		// Only one of the logins using the same code succeeds.
+		const attempts = 5
+		var succeeded atomic.Int32
+		var eg errgroup.Group
+		for i := 0; i < attempts; i++ {
+			eg.Go(func() error {
+				err := login(enrollment.RecoveryCodes[0])
+				if err == nil {
+					succeeded.Add(1)
+					return nil
+				}
+				if !codersdk.IsTOTPRequiredError(err) {
+					return err
+				}
+				return nil
+			})
+		}
+		require.NoError(t, eg.Wait())
+		require.EqualValues(t, 1, succeeded.Load())
+
+		// Logins using different codes do not restore each other's code.
+		for _, code := range enrollment.RecoveryCodes[1:3] {
+			code := code
+			eg.Go(func() error {
+				return login(code)
+			})
+		}
+		require.NoError(t, eg.Wait())
+		for _, code := range enrollment.RecoveryCodes[:3] {
+			err := login(code)
+			require.True(t, codersdk.IsTOTPRequiredError(err), "expected totp required error, got %v", err)
+		}
+
+		status, err := client.UserMFAStatus(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, usermfa.RecoveryCodeCount-3, status.RecoveryCodesRemaining)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+
+		_, err := ownerClient.EnrollTOTP(ctx, user.ID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client, user := coderdtest.CreateAnotherUser(t, ownerClient, owner.OrganizationID)
+		_ = enroll(t, client)
+
+		// Users cannot remove their own second factor.
+		err := client.ResetUserTOTP(ctx, codersdk.Me)
+		require.Error(t, err)
+
+		err = ownerClient.ResetUserTOTP(ctx, user.ID.String())
+		require.NoError(t, err)
+
+		status, err := client.UserMFAStatus(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.False(t, status.TOTPEnabled)
+
+		_, err = codersdk.New(ownerClient.URL).LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    user.Email,
+			Password: "SomeSecurePassword!",
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestRequireOwnerMFA(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	dv := coderdtest.DeploymentValues(t)
+	dv.RequireOwnerMFA = true
+	client := coderdtest.New(t, &coderdtest.Options{DeploymentValues: dv})
+	_, err := client.CreateFirstUser(ctx, coderdtest.FirstUserParams)
+	require.NoError(t, err)
+
+	req := codersdk.LoginWithPasswordRequest{
+		Email:    coderdtest.FirstUserParams.Email,
+		Password: coderdtest.FirstUserParams.Password,
+	}
+	_, err = client.LoginWithPassword(ctx, req)
+	require.True(t, codersdk.IsTOTPEnrollmentRequiredError(err), "expected enrollment required error, got %v", err)
+
+	// The owner enrolls during login, and the first login with a code
+	// confirms the enrollment.
+	enrollment, err := client.EnrollTOTPWithPassword(ctx, req)
+	require.NoError(t, err)
+	req.TOTPCode, err = totp.GenerateCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	login, err := client.LoginWithPassword(ctx, req)
+	require.NoError(t, err)
+	client.SetSessionToken(login.SessionToken)
+
+	status, err := client.UserMFAStatus(ctx, codersdk.Me)
+	require.NoError(t, err)
+	require.True(t, status.TOTPEnabled)
+	require.True(t, status.Required)
+
+	// Enrollment during login cannot replace an enabled second factor.
+	req.TOTPCode = ""
+	_, err = client.EnrollTOTPWithPassword(ctx, req)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+	// Members are not required to use a second factor.
+	first, err := client.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+	_, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationIDs[0])
+	memberReq := codersdk.LoginWithPasswordRequest{
+		Email:    member.Email,
+		Password: "SomeSecurePassword!",
+	}
+	_, err = codersdk.New(client.URL).EnrollTOTPWithPassword(ctx, memberReq)
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
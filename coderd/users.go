@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
@@ -361,6 +362,22 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ServiceAccount {
+		// Service accounts only ever authenticate with API tokens.
+		if req.UserLoginType != "" && req.UserLoginType != codersdk.LoginTypeNone {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Service accounts cannot use the %q login type.", req.UserLoginType),
+			})
+			return
+		}
+		if req.Password != "" {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Password cannot be set for service accounts.",
+			})
+			return
+		}
+		req.UserLoginType = codersdk.LoginTypeNone
+	}
 	if req.UserLoginType == "" && req.DisableLogin {
 		// Handle the deprecated field
 		req.UserLoginType = codersdk.LoginTypeNone
@@ -471,6 +488,14 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 			return
 		}
 		loginType = database.LoginTypeOIDC
+	case codersdk.LoginTypeLDAP:
+		if api.LDAPConfig == nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "You must configure LDAP before creating LDAP users.",
+			})
+			return
+		}
+		loginType = database.LoginTypeLDAP
 	case codersdk.LoginTypeGithub:
 		loginType = database.LoginTypeGithub
 	default:
@@ -480,7 +505,21 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, _, err := api.CreateUser(ctx, api.Database, CreateUserRequest{
+	createCtx := ctx
+	if req.ServiceAccount {
+		// Service accounts are owned by the organization, so its admins can
+		// create them without being allowed to create users site-wide.
+		if !api.Authorize(r, policy.ActionCreate, rbac.ResourceUser.InOrg(req.OrganizationID)) {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: "You are not authorized to create service accounts in this organization.",
+			})
+			return
+		}
+		//nolint:gocritic // The organization was authorized above.
+		createCtx = dbauthz.AsSystemRestricted(ctx)
+	}
+
+	user, _, err := api.CreateUser(createCtx, api.Database, CreateUserRequest{
 		CreateUserRequest: req,
 		LoginType:         loginType,
 	})
@@ -1261,8 +1300,13 @@ func (api *API) CreateUser(ctx context.Context, store database.Store, req Create
 			UpdatedAt:      dbtime.Now(),
 			HashedPassword: []byte{},
 			// All new users are defaulted to members of the site.
-			RBACRoles: []string{},
-			LoginType: req.LoginType,
+			RBACRoles:        []string{},
+			LoginType:        req.LoginType,
+			IsServiceAccount: req.ServiceAccount,
+			ServiceAccountOrganizationID: uuid.NullUUID{
+				UUID:  req.OrganizationID,
+				Valid: req.ServiceAccount,
+			},
 		}
 		// If a user signs up with OAuth, they can have no password!
 		if req.Password != "" {
@@ -1392,5 +1436,31 @@ func convertAPIKey(k database.APIKey) codersdk.APIKey {
 		Scope:           codersdk.APIKeyScope(k.Scope),
 		LifetimeSeconds: k.LifetimeSeconds,
 		TokenName:       k.TokenName,
+		Scopes:          convertAPIKeyScopes(k.Scopes),
+		AllowList:       convertAPIKeyAllowList(k.AllowList),
+	}
+}
+
+func convertAPIKeyScopes(scopes []string) []codersdk.APIKeyScope {
+	converted := make([]codersdk.APIKeyScope, 0, len(scopes))
+	for _, scope := range scopes {
+		converted = append(converted, codersdk.APIKeyScope(scope))
 	}
+	return converted
+}
+
+func convertAPIKeyAllowList(allowList []string) []codersdk.APIAllowListTarget {
+	converted := make([]codersdk.APIAllowListTarget, 0, len(allowList))
+	for _, entry := range allowList {
+		resource, rawID, _ := strings.Cut(entry, ":")
+		id, err := uuid.Parse(rawID)
+		if err != nil {
+			continue
+		}
+		converted = append(converted, codersdk.APIAllowListTarget{
+			Type: codersdk.RBACResource(resource),
+			ID:   id,
+		})
+	}
+	return converted
 }
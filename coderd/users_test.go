@@ -681,6 +681,129 @@ func TestPostUsers(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, found.LoginType, codersdk.LoginTypeOIDC)
 	})
+
+	t.Run("ServiceAccount", func(t *testing.T) {
+		t.Parallel()
+		client, db := coderdtest.NewWithDatabase(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Service accounts cannot have any way to log in.
+		for _, req := range []codersdk.CreateUserRequest{
+			{Password: "SomeSecurePassword!", UserLoginType: ""},
+			{UserLoginType: codersdk.LoginTypePassword},
+			{UserLoginType: codersdk.LoginTypeOIDC},
+		} {
+			req.OrganizationID = first.OrganizationID
+			req.Email = "ci@coder.com"
+			req.Username = "ci"
+			req.ServiceAccount = true
+			_, err := client.CreateUser(ctx, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+
+		sa, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			OrganizationID: first.OrganizationID,
+			Email:          "ci@coder.com",
+			Username:       "ci",
+			ServiceAccount: true,
+		})
+		require.NoError(t, err)
+		require.True(t, sa.IsServiceAccount)
+		require.Equal(t, codersdk.LoginTypeNone, sa.LoginType)
+		require.Equal(t, []uuid.UUID{first.OrganizationID}, sa.OrganizationIDs)
+
+		// Service accounts authenticate with API tokens.
+		token, err := client.CreateToken(ctx, sa.ID.String(), codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+		saClient := codersdk.New(client.URL)
+		saClient.SetSessionToken(token.Key)
+		me, err := saClient.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, sa.ID, me.ID)
+		require.True(t, me.IsServiceAccount)
+
+		// Session keys are reserved for interactive logins.
+		_, err = client.CreateAPIKey(ctx, sa.ID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Service accounts are owned by a single organization.
+		other := dbgen.Organization(t, db, database.Organization{})
+		_, err = client.PostOrganizationMember(ctx, other.ID, sa.ID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// The service account is active now that it has made a request, and
+		// it is counted separately from users.
+		// nolint:gocritic // Unit testing.
+		count, err := db.GetActiveUserCount(dbauthz.AsSystemRestricted(ctx))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, count)
+		// nolint:gocritic // Unit testing.
+		count, err = db.GetActiveServiceAccountCount(dbauthz.AsSystemRestricted(ctx))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, count)
+		found, err := client.User(ctx, sa.ID.String())
+		require.NoError(t, err)
+		require.Equal(t, codersdk.UserStatusActive, found.Status)
+	})
+
+	t.Run("ServiceAccountOrganizationAdmin", func(t *testing.T) {
+		t.Parallel()
+		client, db := coderdtest.NewWithDatabase(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		org := dbgen.Organization(t, db, database.Organization{})
+		orgAdmin, _ := coderdtest.CreateAnotherUser(t, client, org.ID, rbac.ScopedRoleOrgAdmin(org.ID))
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Organization admins cannot create users.
+		var apiErr *codersdk.Error
+		_, err := orgAdmin.CreateUser(ctx, codersdk.CreateUserRequest{
+			OrganizationID: org.ID,
+			Email:          "user@coder.com",
+			Username:       "user",
+			Password:       "SomeSecurePassword!",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		// Nor service accounts of organizations they cannot see.
+		_, err = orgAdmin.CreateUser(ctx, codersdk.CreateUserRequest{
+			OrganizationID: first.OrganizationID,
+			Email:          "ci@coder.com",
+			Username:       "ci",
+			ServiceAccount: true,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		sa, err := orgAdmin.CreateUser(ctx, codersdk.CreateUserRequest{
+			OrganizationID: org.ID,
+			Email:          "ci@coder.com",
+			Username:       "ci",
+			ServiceAccount: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{org.ID}, sa.OrganizationIDs)
+
+		_, err = orgAdmin.CreateToken(ctx, sa.ID.String(), codersdk.CreateTokenRequest{})
+		require.NoError(t, err)
+
+		// The service account cannot leave the organization that owns it.
+		err = client.DeleteOrganizationMember(ctx, org.ID, sa.ID.String())
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = orgAdmin.UpdateUserStatus(ctx, sa.ID.String(), codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+		err = orgAdmin.DeleteUser(ctx, sa.ID)
+		require.NoError(t, err)
+	})
 }
 
 func TestNotifyCreatedUser(t *testing.T) {
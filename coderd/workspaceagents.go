@@ -690,9 +690,19 @@ func (api *API) workspaceAgentListeningPorts(rw http.ResponseWriter, r *http.Req
 // @Router /workspaceagents/{workspaceagent}/connection [get]
 func (api *API) workspaceAgentConnection(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	pins, err := (*api.WorkspaceProxyPinner.Load()).WorkspacePins(ctx, workspace)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace proxy restrictions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, workspacesdk.AgentConnectionInfo{
-		DERPMap:                  api.DERPMap(),
+		DERPMap:                  pins.FilterDERPMap(api.DERPMap()),
 		DERPForceWebSockets:      api.DeploymentValues.DERP.Config.ForceWebSockets.Value(),
 		DisableDirectConnections: api.DeploymentValues.DERP.Config.BlockDirect.Value(),
 	})
@@ -866,6 +876,16 @@ func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.R
 		}
 	}
 
+	// Clients must not relay through DERP regions that the agent can't use.
+	pins, err := (*api.WorkspaceProxyPinner.Load()).WorkspacePins(ctx, workspace)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace proxy restrictions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	api.WebsocketWaitMutex.Lock()
 	api.WebsocketWaitGroup.Add(1)
 	api.WebsocketWaitMutex.Unlock()
@@ -886,6 +906,9 @@ func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.R
 	go httpapi.Heartbeat(ctx, conn)
 
 	defer conn.Close(websocket.StatusNormalClosure, "")
+	if pins.RestrictsDERPRegions() {
+		ctx = tailnet.WithDERPMapFilter(ctx, pins.FilterDERPMap)
+	}
 	err = api.TailnetClientService.ServeClient(ctx, version, wsNetConn, peerID, workspaceAgent.ID)
 	if err != nil && !xerrors.Is(err, io.EOF) && !xerrors.Is(err, context.Canceled) {
 		_ = conn.Close(websocket.StatusInternalError, err.Error())
@@ -15,6 +15,7 @@ import (
 	"github.com/hashicorp/yamux"
 	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/v2/agent/proto"
@@ -84,6 +85,16 @@ func (api *API) workspaceAgentRPC(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Agents only receive the DERP regions their workspace may relay through.
+	pins, err := (*api.WorkspaceProxyPinner.Load()).WorkspacePins(ctx, workspace)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace proxy restrictions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	logger = logger.With(
 		slog.F("owner", owner.Username),
 		slog.F("workspace_name", workspace.Name),
@@ -130,7 +141,7 @@ func (api *API) workspaceAgentRPC(rw http.ResponseWriter, r *http.Request) {
 		Log:                               logger,
 		Database:                          api.Database,
 		Pubsub:                            api.Pubsub,
-		DerpMapFn:                         api.DERPMap,
+		DerpMapFn:                         func() *tailcfg.DERPMap { return pins.FilterDERPMap(api.DERPMap()) },
 		TailnetCoordinator:                &api.TailnetCoordinator,
 		AppearanceFetcher:                 &api.AppearanceFetcher,
 		StatsReporter:                     api.statsReporter,
@@ -28,6 +28,7 @@ import (
 	"github.com/coder/coder/v2/coderd/coderdtest"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/util/ptr"
 	"github.com/coder/coder/v2/coderd/workspaceapps"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/workspacesdk"
@@ -1658,6 +1659,44 @@ func Run(t *testing.T, appHostIsPrimary bool, factory DeploymentFactory) {
 		assert.Equal(t, 1, stats[0].Requests)
 	})
 
+	t.Run("ConnectionLimit", func(t *testing.T) {
+		t.Parallel()
+
+		appDetails := setupProxyTest(t, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := appDetails.SDKClient.UpdateTemplateMeta(ctx, appDetails.Workspace.TemplateID, codersdk.UpdateTemplateMeta{
+			MaxAppConnectionsPerUser: ptr.Ref[int64](1),
+		})
+		require.NoError(t, err)
+
+		// The first request stays in flight until its body is closed.
+		u := appDetails.PathAppURL(appDetails.Apps.Owner)
+		hang := *u
+		hang.Path = strings.TrimSuffix(hang.Path, "/") + proxyTestAppHangPath
+		hanging, err := requestWithRetries(ctx, t, appDetails.AppClient(t), http.MethodGet, hang.String(), nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, hanging.StatusCode)
+
+		resp, err := appDetails.AppClient(t).Request(ctx, http.MethodGet, u.String(), nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+		// Closing the first request frees up the connection.
+		_ = hanging.Body.Close()
+		require.Eventually(t, func() bool {
+			resp, err := appDetails.AppClient(t).Request(ctx, http.MethodGet, u.String(), nil)
+			if !assert.NoError(t, err) {
+				return false
+			}
+			_ = resp.Body.Close()
+			return resp.StatusCode == http.StatusOK
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
+
 	t.Run("WorkspaceOffline", func(t *testing.T) {
 		t.Parallel()
 
@@ -40,6 +40,7 @@ const (
 	proxyTestAppNamePublic        = "test-app-public"
 	proxyTestAppQuery             = "query=true"
 	proxyTestAppBody              = "hello world from apps test"
+	proxyTestAppHangPath          = "/hang"
 
 	proxyTestSubdomainRaw = "*.test.coder.com"
 	proxyTestSubdomain    = "test.coder.com"
@@ -265,6 +266,13 @@ func appServer(t *testing.T, headers http.Header, isHTTPS bool) uint16 {
 			func(w http.ResponseWriter, r *http.Request) {
 				_, err := r.Cookie(codersdk.SessionTokenCookie)
 				assert.ErrorIs(t, err, http.ErrNoCookie)
+				if strings.HasSuffix(r.URL.Path, proxyTestAppHangPath) {
+					// Keep the request in flight until the client goes away.
+					w.WriteHeader(http.StatusOK)
+					http.NewResponseController(w).Flush()
+					<-r.Context().Done()
+					return
+				}
 				w.Header().Set("X-Forwarded-For", r.Header.Get("X-Forwarded-For"))
 				w.Header().Set("X-Got-Host", r.Host)
 				for name, values := range headers {
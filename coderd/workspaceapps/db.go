@@ -3,21 +3,27 @@ package workspaceapps
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/proxypinning"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/codersdk"
@@ -36,11 +42,21 @@ type DBTokenProvider struct {
 	OAuth2Configs                 *httpmw.OAuth2Configs
 	WorkspaceAgentInactiveTimeout time.Duration
 	SigningKey                    SecurityKey
+	Auditor                       *atomic.Pointer[audit.Auditor]
+	Pinner                        *atomic.Pointer[proxypinning.Pinner]
+	// PrimaryProxyID identifies the primary proxy when checking whether a
+	// workspace allows its apps to be served by the proxy issuing the token.
+	PrimaryProxyID uuid.UUID
+
+	// revokedMu guards revoked, the time the sessions of each user were last
+	// revoked. Tokens issued to a user before then are rejected.
+	revokedMu sync.Mutex
+	revoked   map[uuid.UUID]time.Time
 }
 
 var _ SignedTokenProvider = &DBTokenProvider{}
 
-func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKey SecurityKey) SignedTokenProvider {
+func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKey SecurityKey, auditor *atomic.Pointer[audit.Auditor], pinner *atomic.Pointer[proxypinning.Pinner], primaryProxyID uuid.UUID) *DBTokenProvider {
 	if workspaceAgentInactiveTimeout == 0 {
 		workspaceAgentInactiveTimeout = 1 * time.Minute
 	}
@@ -54,11 +70,67 @@ func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authoriz
 		OAuth2Configs:                 oauth2Cfgs,
 		WorkspaceAgentInactiveTimeout: workspaceAgentInactiveTimeout,
 		SigningKey:                    signingKey,
+		Auditor:                       auditor,
+		Pinner:                        pinner,
+		PrimaryProxyID:                primaryProxyID,
+		revoked:                       map[uuid.UUID]time.Time{},
 	}
 }
 
+type workspaceProxyContextKey struct{}
+
+// WithWorkspaceProxy records that a token is being issued for apps served by
+// the given workspace proxy. Tokens issued without it are for the primary
+// proxy.
+func WithWorkspaceProxy(ctx context.Context, proxyID uuid.UUID) context.Context {
+	return context.WithValue(ctx, workspaceProxyContextKey{}, proxyID)
+}
+
 func (p *DBTokenProvider) FromRequest(r *http.Request) (*SignedToken, bool) {
-	return FromRequest(r, p.SigningKey)
+	token, ok := FromRequest(r, p.SigningKey)
+	if !ok {
+		return nil, false
+	}
+	if p.issuedBeforeRevocation(token) {
+		// Issuing a new token checks the session token again, which fails if
+		// it was the session that got revoked.
+		return nil, false
+	}
+	return token, true
+}
+
+// RevokeUserTokens rejects the tokens issued to the user before revokedAt. It
+// is called when sessions of the user are revoked, as tokens are otherwise
+// trusted until they expire.
+func (p *DBTokenProvider) RevokeUserTokens(userID uuid.UUID, revokedAt time.Time) {
+	p.revokedMu.Lock()
+	defer p.revokedMu.Unlock()
+
+	// Every token issued before a revocation has expired once
+	// DefaultTokenExpiry has passed, so older entries can be forgotten.
+	for id, at := range p.revoked {
+		if time.Since(at) > DefaultTokenExpiry {
+			delete(p.revoked, id)
+		}
+	}
+	if revokedAt.After(p.revoked[userID]) {
+		p.revoked[userID] = revokedAt
+	}
+}
+
+func (p *DBTokenProvider) issuedBeforeRevocation(token *SignedToken) bool {
+	if token.RequesterID == uuid.Nil {
+		return false
+	}
+
+	p.revokedMu.Lock()
+	revokedAt, ok := p.revoked[token.RequesterID]
+	p.revokedMu.Unlock()
+	if !ok {
+		return false
+	}
+	issuedAt := token.Expiry.Add(-DefaultTokenExpiry)
+	return issuedAt.Before(revokedAt)
 }
 
 func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *http.Request, issueReq IssueTokenRequest) (*SignedToken, string, bool) {
@@ -199,6 +271,41 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		return nil, "", false
 	}
 
+	// Check that the workspace's template and organization allow its apps to
+	// be served by the proxy the token is for.
+	proxyID := p.PrimaryProxyID
+	if id, ok := ctx.Value(workspaceProxyContextKey{}).(uuid.UUID); ok {
+		proxyID = id
+	}
+	pins, err := (*p.Pinner.Load()).WorkspacePins(ctx, dbReq.Workspace)
+	if err != nil {
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get workspace proxy restrictions")
+		return nil, "", false
+	}
+	if !pins.AllowsWorkspaceProxy(proxyID) {
+		p.auditProxyNotAllowed(dangerousSystemCtx, r, apiKey, dbReq, proxyID)
+		WriteWorkspaceAppProxyForbidden(p.Logger, p.DashboardURL, rw, r, &appReq, fmt.Sprintf("workspace proxy %q is not allowed", proxyID))
+		return nil, "", false
+	}
+
+	template, err := p.Database.GetTemplateByID(dangerousSystemCtx, dbReq.Workspace.TemplateID)
+	if err != nil {
+		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get template")
+		return nil, "", false
+	}
+	// These are limits set by admins, so unlike the API rate limits they are
+	// not turned off by RateLimit.DisableAll.
+	token.Limits = ResolveLimits(Limits{
+		MaxConnectionsPerUser: p.DeploymentValues.RateLimit.WorkspaceAppMaxConnectionsPerUser.Value(),
+		MaxBytesPerSecond:     p.DeploymentValues.RateLimit.WorkspaceAppMaxBytesPerSecond.Value(),
+	}, Limits{
+		MaxConnectionsPerUser: template.MaxAppConnectionsPerUser,
+		MaxBytesPerSecond:     template.MaxAppBytesPerSecond,
+	})
+	if apiKey != nil {
+		token.RequesterID = apiKey.UserID
+	}
+
 	// This is where we used to check app health, but we don't do that anymore
 	// in case there are bugs with the healthcheck code that lock users out of
 	// their apps completely.
@@ -221,6 +328,46 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	return &token, tokenStr, true
 }
 
+// auditProxyNotAllowed records an attempt to open a workspace app through a
+// workspace proxy that the workspace's template or organization does not allow.
+func (p *DBTokenProvider) auditProxyNotAllowed(ctx context.Context, r *http.Request, apiKey *database.APIKey, dbReq *databaseRequest, proxyID uuid.UUID) {
+	var userID uuid.UUID
+	if apiKey != nil {
+		userID = apiKey.UserID
+	}
+	appName := dbReq.AppSlugOrPort
+	if dbReq.AccessMethod == AccessMethodTerminal {
+		appName = "terminal"
+	}
+	additionalFields, err := json.Marshal(audit.AdditionalFields{
+		WorkspaceName:  dbReq.Workspace.Name,
+		WorkspaceOwner: dbReq.User.Username,
+		WorkspaceID:    dbReq.Workspace.ID,
+	})
+	if err != nil {
+		p.Logger.Warn(ctx, "marshal additional fields", slog.Error(err))
+		additionalFields = nil
+	}
+
+	p.Logger.Info(ctx, "workspace app request denied by workspace proxy restrictions",
+		slog.F("workspace_id", dbReq.Workspace.ID),
+		slog.F("workspace_proxy_id", proxyID),
+		slog.F("app", appName),
+	)
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.Workspace]{
+		Audit:            *p.Auditor.Load(),
+		Log:              p.Logger,
+		UserID:           userID,
+		Status:           http.StatusForbidden,
+		Action:           database.AuditActionOpen,
+		OrganizationID:   dbReq.Workspace.OrganizationID,
+		IP:               r.RemoteAddr,
+		AdditionalFields: additionalFields,
+		Old:              dbReq.Workspace,
+		New:              dbReq.Workspace,
+	})
+}
+
 // authorizeRequest returns true/false if the request is authorized. The returned []string
 // are warnings that aid in debugging. These messages do not prevent authorization,
 // but may indicate that the request is not configured correctly.
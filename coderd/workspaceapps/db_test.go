@@ -282,6 +282,7 @@ func Test_ResolveRequest(t *testing.T) {
 						WorkspaceID: workspace.ID,
 						AgentID:     agentID,
 						AppURL:      appURL,
+						RequesterID: me.ID,
 					}, token)
 					require.NotZero(t, token.Expiry)
 					require.WithinDuration(t, time.Now().Add(workspaceapps.DefaultTokenExpiry), token.Expiry, time.Minute)
@@ -363,6 +364,65 @@ func Test_ResolveRequest(t *testing.T) {
 		}
 	})
 
+	t.Run("RevokedSessions", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitMedium)
+
+		// Use a separate user, as revoking affects every token of the user.
+		userClient, _ := coderdtest.CreateAnotherUser(t, client, firstUser.OrganizationID)
+
+		req := (workspaceapps.Request{
+			AccessMethod:      workspaceapps.AccessMethodPath,
+			BasePath:          "/app",
+			UsernameOrID:      me.Username,
+			WorkspaceNameOrID: workspace.Name,
+			AgentNameOrID:     agentName,
+			AppSlugOrPort:     appNameAuthed,
+		}).Normalize()
+		resolve := func(r *http.Request) (*workspaceapps.SignedToken, *http.Response, bool) {
+			rw := httptest.NewRecorder()
+			token, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+				Logger:              api.Logger,
+				SignedTokenProvider: api.WorkspaceAppsProvider,
+				DashboardURL:        api.AccessURL,
+				PathAppBaseURL:      api.AccessURL,
+				AppHostname:         api.AppHostname,
+				AppRequest:          req,
+			})
+			w := rw.Result()
+			_ = w.Body.Close()
+			return token, w, ok
+		}
+
+		r := httptest.NewRequest("GET", "/app", nil)
+		r.Header.Set(codersdk.SessionTokenHeader, userClient.SessionToken())
+		_, w, ok := resolve(r)
+		require.True(t, ok)
+		require.Len(t, w.Cookies(), 1)
+		cookie := w.Cookies()[0]
+
+		err := userClient.RevokeUserSessions(ctx, codersdk.Me)
+		require.NoError(t, err)
+
+		// The token is no longer trusted on its own once the revocation has
+		// been received.
+		require.Eventually(t, func() bool {
+			r := httptest.NewRequest("GET", "/app", nil)
+			r.AddCookie(cookie)
+			_, _, ok := resolve(r)
+			return !ok
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		// The session making the request was kept, so a new token is issued.
+		r = httptest.NewRequest("GET", "/app", nil)
+		r.AddCookie(cookie)
+		r.Header.Set(codersdk.SessionTokenHeader, userClient.SessionToken())
+		token, _, ok := resolve(r)
+		require.True(t, ok)
+		require.NotNil(t, token)
+	})
+
 	t.Run("Unauthenticated", func(t *testing.T) {
 		t.Parallel()
 
@@ -117,3 +117,56 @@ func WriteWorkspaceOffline(log slog.Logger, accessURL *url.URL, rw http.Response
 		DashboardURL: accessURL.String(),
 	})
 }
+
+// WriteWorkspaceAppProxyForbidden writes a HTML 403 error page for a workspace
+// app that may not be served by the workspace proxy handling the request. If
+// appReq is not nil, it will be used to log the request details at debug level.
+func WriteWorkspaceAppProxyForbidden(log slog.Logger, accessURL *url.URL, rw http.ResponseWriter, r *http.Request, appReq *Request, msg string) {
+	if appReq != nil {
+		slog.Helper()
+		log.Debug(r.Context(),
+			"workspace app forbidden: "+msg,
+			slog.F("username_or_id", appReq.UsernameOrID),
+			slog.F("workspace_and_agent", appReq.WorkspaceAndAgent),
+			slog.F("workspace_name_or_id", appReq.WorkspaceNameOrID),
+			slog.F("agent_name_or_id", appReq.AgentNameOrID),
+			slog.F("app_slug_or_port", appReq.AppSlugOrPort),
+			slog.F("hostname_prefix", appReq.Prefix),
+		)
+	}
+
+	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+		Status:       http.StatusForbidden,
+		Title:        "Workspace Proxy Not Allowed",
+		Description:  "The template or organization of this workspace does not allow its applications to be accessed through this workspace proxy. Select a different workspace proxy and try again.",
+		RetryEnabled: false,
+		DashboardURL: accessURL.String(),
+	})
+}
+
+// WriteWorkspaceAppTooManyConnections writes a HTML 429 error page for a user
+// that is at their workspace app connection limit.
+func WriteWorkspaceAppTooManyConnections(log slog.Logger, accessURL *url.URL, rw http.ResponseWriter, r *http.Request, appReq *Request, limit int64) {
+	if appReq != nil {
+		slog.Helper()
+		log.Debug(r.Context(),
+			"workspace app connection limit reached",
+			slog.F("username_or_id", appReq.UsernameOrID),
+			slog.F("workspace_and_agent", appReq.WorkspaceAndAgent),
+			slog.F("workspace_name_or_id", appReq.WorkspaceNameOrID),
+			slog.F("agent_name_or_id", appReq.AgentNameOrID),
+			slog.F("app_slug_or_port", appReq.AppSlugOrPort),
+			slog.F("hostname_prefix", appReq.Prefix),
+			slog.F("limit", limit),
+		)
+	}
+
+	rw.Header().Set("Retry-After", "5")
+	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+		Status:       http.StatusTooManyRequests,
+		Title:        "Too Many Connections",
+		Description:  fmt.Sprintf("You have reached the limit of %d concurrent workspace application connections. Close some applications and try again.", limit),
+		RetryEnabled: true,
+		DashboardURL: accessURL.String(),
+	})
+}
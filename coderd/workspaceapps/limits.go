@@ -0,0 +1,300 @@
+package workspaceapps
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/quartz"
+)
+
+// Limits restrict how heavily workspace apps may be used. They are resolved
+// from the deployment and template when a token is issued, and enforced by
+// each replica and workspace proxy independently. Zero means unlimited.
+type Limits struct {
+	// MaxConnectionsPerUser is the maximum number of in-flight requests,
+	// including WebSocket connections, a user may have to workspace apps.
+	MaxConnectionsPerUser int64 `json:"max_connections_per_user,omitempty"`
+	// MaxBytesPerSecond is the maximum combined throughput of all
+	// connections to a single app, in both directions.
+	MaxBytesPerSecond int64 `json:"max_bytes_per_second,omitempty"`
+}
+
+// ResolveLimits returns the stricter of the deployment and template limits.
+// Zero values are ignored, so a template can only tighten the deployment
+// limits.
+func ResolveLimits(deployment, template Limits) Limits {
+	return Limits{
+		MaxConnectionsPerUser: stricterLimit(deployment.MaxConnectionsPerUser, template.MaxConnectionsPerUser),
+		MaxBytesPerSecond:     stricterLimit(deployment.MaxBytesPerSecond, template.MaxBytesPerSecond),
+	}
+}
+
+func stricterLimit(a, b int64) int64 {
+	if a <= 0 {
+		return max(b, 0)
+	}
+	if b <= 0 {
+		return a
+	}
+	return min(a, b)
+}
+
+const (
+	limiterNamespace = "coderd"
+	limiterSubsystem = "workspace_apps"
+
+	limitLabel             = "limit"
+	limitConnectionPerUser = "connections_per_user"
+)
+
+// Limiter enforces Limits for proxied workspace app connections.
+type Limiter struct {
+	clock       quartz.Clock
+	mu          sync.Mutex
+	connections map[string]int64
+	apps        map[string]*appBandwidth
+
+	activeConnections prometheus.Gauge
+	rejected          *prometheus.CounterVec
+	proxiedBytes      prometheus.Counter
+	throttledSeconds  prometheus.Counter
+}
+
+type appBandwidth struct {
+	limiter *rate.Limiter
+	refs    int
+}
+
+func NewLimiter(reg prometheus.Registerer) *Limiter {
+	return NewLimiterWithClock(reg, quartz.NewReal())
+}
+
+// NewLimiterWithClock returns a limiter that uses the given clock to throttle
+// bandwidth. Product code should always call NewLimiter.
+func NewLimiterWithClock(reg prometheus.Registerer, clock quartz.Clock) *Limiter {
+	return &Limiter{
+		clock:       clock,
+		connections: map[string]int64{},
+		apps:        map[string]*appBandwidth{},
+
+		activeConnections: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "active_connections", Namespace: limiterNamespace, Subsystem: limiterSubsystem,
+			Help: "The number of in-flight proxied workspace app requests, including WebSocket connections.",
+		}),
+		rejected: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "rejected_connections_total", Namespace: limiterNamespace, Subsystem: limiterSubsystem,
+			Help: "The number of workspace app requests rejected with a 429, aggregated by the limit that was exceeded.",
+		}, []string{limitLabel}),
+		proxiedBytes: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "proxied_bytes_total", Namespace: limiterNamespace, Subsystem: limiterSubsystem,
+			Help: "The number of bytes proxied to and from bandwidth limited workspace apps.",
+		}),
+		throttledSeconds: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "throttled_seconds_total", Namespace: limiterNamespace, Subsystem: limiterSubsystem,
+			Help: "The total time workspace app connections spent waiting because of bandwidth limits.",
+		}),
+	}
+}
+
+// Acquire reserves a connection for the user the token was issued to, or for
+// the client IP address if the app was accessed anonymously. If the
+// user is at their connection limit, ok is false and nothing is reserved.
+// Otherwise the caller must call release once the connection is closed, and
+// should use the returned bandwidth to throttle the connection.
+func (l *Limiter) Acquire(token SignedToken, remoteAddr string) (bandwidth *Bandwidth, release func(), ok bool) {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	userKey := "ip:" + remoteAddr
+	if token.RequesterID != uuid.Nil {
+		userKey = "user:" + token.RequesterID.String()
+	}
+	appKey := token.AgentID.String() + "/" + token.AppSlugOrPort
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if limit := token.Limits.MaxConnectionsPerUser; limit > 0 && l.connections[userKey] >= limit {
+		l.rejected.WithLabelValues(limitConnectionPerUser).Inc()
+		return nil, nil, false
+	}
+	l.connections[userKey]++
+	l.activeConnections.Inc()
+
+	if limit := token.Limits.MaxBytesPerSecond; limit > 0 {
+		app, ok := l.apps[appKey]
+		if !ok {
+			app = &appBandwidth{limiter: rate.NewLimiter(rate.Limit(limit), burstForLimit(limit))}
+			l.apps[appKey] = app
+		} else if app.limiter.Limit() != rate.Limit(limit) {
+			// The limit changed since the connections that are still open
+			// were made. The most recent token wins.
+			now := l.clock.Now()
+			app.limiter.SetLimitAt(now, rate.Limit(limit))
+			app.limiter.SetBurstAt(now, burstForLimit(limit))
+		}
+		app.refs++
+		bandwidth = &Bandwidth{limiter: app.limiter, metrics: l}
+	}
+
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+
+			l.connections[userKey]--
+			if l.connections[userKey] <= 0 {
+				delete(l.connections, userKey)
+			}
+			l.activeConnections.Dec()
+			if bandwidth == nil {
+				return
+			}
+			app := l.apps[appKey]
+			app.refs--
+			if app.refs <= 0 {
+				delete(l.apps, appKey)
+			}
+		})
+	}
+	return bandwidth, release, true
+}
+
+// burstForLimit allows a second worth of traffic to be sent at once.
+func burstForLimit(limit int64) int {
+	const maxBurst = 1 << 30
+	return int(min(limit, maxBurst))
+}
+
+// Bandwidth throttles reads and writes that share an app's bandwidth limit.
+type Bandwidth struct {
+	limiter *rate.Limiter
+	metrics *Limiter
+}
+
+// wait blocks until n bytes may be transferred. n must not exceed the burst.
+func (b *Bandwidth) wait(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+	now := b.metrics.clock.Now()
+	reservation := b.limiter.ReserveN(now, n)
+	if !reservation.OK() {
+		return xerrors.Errorf("wait for bandwidth: %d bytes exceeds the burst", n)
+	}
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		timer := b.metrics.clock.NewTimer(delay, "Bandwidth", "wait")
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			reservation.CancelAt(b.metrics.clock.Now())
+			return xerrors.Errorf("wait for bandwidth: %w", ctx.Err())
+		case <-timer.C:
+		}
+		b.metrics.throttledSeconds.Add(delay.Seconds())
+	}
+	b.metrics.proxiedBytes.Add(float64(n))
+	return nil
+}
+
+// write writes p to w in chunks no larger than the burst, waiting for
+// bandwidth before each chunk.
+func (b *Bandwidth) write(ctx context.Context, w io.Writer, p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p[:min(len(p), b.limiter.Burst())]
+		if err := b.wait(ctx, len(chunk)); err != nil {
+			return written, err
+		}
+		n, err := w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// read reads at most a burst from r into p, waiting for bandwidth for the
+// bytes that were read.
+func (b *Bandwidth) read(ctx context.Context, r io.Reader, p []byte) (int, error) {
+	if len(p) > b.limiter.Burst() {
+		p = p[:b.limiter.Burst()]
+	}
+	n, err := r.Read(p)
+	if waitErr := b.wait(ctx, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// ResponseWriter throttles writes to rw, and to the connection if it is
+// hijacked for a WebSocket.
+func (b *Bandwidth) ResponseWriter(ctx context.Context, rw http.ResponseWriter) http.ResponseWriter {
+	return &bandwidthResponseWriter{ResponseWriter: rw, ctx: ctx, bandwidth: b}
+}
+
+// Body throttles reads from the request body.
+func (b *Bandwidth) Body(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	return &bandwidthBody{ReadCloser: body, ctx: ctx, bandwidth: b}
+}
+
+type bandwidthResponseWriter struct {
+	http.ResponseWriter
+	ctx       context.Context
+	bandwidth *Bandwidth
+}
+
+func (w *bandwidthResponseWriter) Write(p []byte) (int, error) {
+	return w.bandwidth.write(w.ctx, w.ResponseWriter, p)
+}
+
+// Unwrap allows http.ResponseController to flush the underlying writer.
+func (w *bandwidthResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *bandwidthResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	// The connection outlives the request context.
+	return &bandwidthConn{Conn: conn, ctx: context.Background(), bandwidth: w.bandwidth}, brw, nil
+}
+
+type bandwidthBody struct {
+	io.ReadCloser
+	ctx       context.Context
+	bandwidth *Bandwidth
+}
+
+func (b *bandwidthBody) Read(p []byte) (int, error) {
+	return b.bandwidth.read(b.ctx, b.ReadCloser, p)
+}
+
+type bandwidthConn struct {
+	net.Conn
+	ctx       context.Context
+	bandwidth *Bandwidth
+}
+
+func (c *bandwidthConn) Read(p []byte) (int, error) {
+	return c.bandwidth.read(c.ctx, c.Conn, p)
+}
+
+func (c *bandwidthConn) Write(p []byte) (int, error) {
+	return c.bandwidth.write(c.ctx, c.Conn, p)
+}
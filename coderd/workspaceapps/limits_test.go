@@ -0,0 +1,177 @@
+package workspaceapps_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/coderd/workspaceapps"
+	"github.com/coder/coder/v2/testutil"
+	"github.com/coder/quartz"
+)
+
+func TestResolveLimits(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		name       string
+		deployment workspaceapps.Limits
+		template   workspaceapps.Limits
+		expected   workspaceapps.Limits
+	}{
+		{
+			name: "Unlimited",
+		},
+		{
+			name:       "DeploymentOnly",
+			deployment: workspaceapps.Limits{MaxConnectionsPerUser: 10, MaxBytesPerSecond: 1000},
+			expected:   workspaceapps.Limits{MaxConnectionsPerUser: 10, MaxBytesPerSecond: 1000},
+		},
+		{
+			name:     "TemplateOnly",
+			template: workspaceapps.Limits{MaxConnectionsPerUser: 5},
+			expected: workspaceapps.Limits{MaxConnectionsPerUser: 5},
+		},
+		{
+			name:       "Stricter",
+			deployment: workspaceapps.Limits{MaxConnectionsPerUser: 10, MaxBytesPerSecond: 1000},
+			template:   workspaceapps.Limits{MaxConnectionsPerUser: 20, MaxBytesPerSecond: 500},
+			expected:   workspaceapps.Limits{MaxConnectionsPerUser: 10, MaxBytesPerSecond: 500},
+		},
+	} {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.expected, workspaceapps.ResolveLimits(c.deployment, c.template))
+		})
+	}
+}
+
+func TestLimiter_Connections(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	limiter := workspaceapps.NewLimiter(reg)
+	token := workspaceapps.SignedToken{
+		AgentID:     uuid.New(),
+		RequesterID: uuid.New(),
+		Limits:      workspaceapps.Limits{MaxConnectionsPerUser: 2},
+	}
+
+	_, release1, ok := limiter.Acquire(token, "1.1.1.1")
+	require.True(t, ok)
+	_, release2, ok := limiter.Acquire(token, "2.2.2.2")
+	require.True(t, ok)
+	_, _, ok = limiter.Acquire(token, "3.3.3.3")
+	require.False(t, ok, "third connection from the same user should be rejected")
+
+	// Other users have their own limit.
+	other := token
+	other.RequesterID = uuid.New()
+	_, releaseOther, ok := limiter.Acquire(other, "1.1.1.1")
+	require.True(t, ok)
+	releaseOther()
+
+	// Releasing twice must not free up two connections.
+	release1()
+	release1()
+	_, release3, ok := limiter.Acquire(token, "1.1.1.1")
+	require.True(t, ok)
+	_, _, ok = limiter.Acquire(token, "1.1.1.1")
+	require.False(t, ok)
+	release2()
+	release3()
+
+	// Anonymous requests are limited per IP address.
+	anonymous := token
+	anonymous.RequesterID = uuid.Nil
+	anonymous.Limits.MaxConnectionsPerUser = 1
+	_, releaseAnonymous, ok := limiter.Acquire(anonymous, "1.1.1.1:1234")
+	require.True(t, ok)
+	_, _, ok = limiter.Acquire(anonymous, "1.1.1.1:5678")
+	require.False(t, ok)
+	_, releaseOtherIP, ok := limiter.Acquire(anonymous, "2.2.2.2:1234")
+	require.True(t, ok)
+	releaseAnonymous()
+	releaseOtherIP()
+
+	metrics, err := reg.Gather()
+	require.NoError(t, err)
+	require.NotEmpty(t, metrics)
+	for _, m := range metrics {
+		switch m.GetName() {
+		case "coderd_workspace_apps_active_connections":
+			require.Zero(t, m.GetMetric()[0].GetGauge().GetValue())
+		case "coderd_workspace_apps_rejected_connections_total":
+			require.EqualValues(t, 3, m.GetMetric()[0].GetCounter().GetValue())
+		}
+	}
+}
+
+func TestLimiter_Bandwidth(t *testing.T) {
+	t.Parallel()
+
+	clock := quartz.NewMock(t)
+	limiter := workspaceapps.NewLimiterWithClock(prometheus.NewRegistry(), clock)
+	token := workspaceapps.SignedToken{
+		AgentID:     uuid.New(),
+		RequesterID: uuid.New(),
+		Limits:      workspaceapps.Limits{MaxBytesPerSecond: 100},
+	}
+
+	unlimited := token
+	unlimited.Limits.MaxBytesPerSecond = 0
+	bandwidth, release, ok := limiter.Acquire(unlimited, "")
+	require.True(t, ok)
+	require.Nil(t, bandwidth)
+	release()
+
+	bandwidth, release, ok = limiter.Acquire(token, "")
+	require.True(t, ok)
+	require.NotNil(t, bandwidth)
+	defer release()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	trap := clock.Trap().NewTimer("Bandwidth", "wait")
+	defer trap.Close()
+
+	// The first second worth of bytes is sent immediately, and the rest must
+	// wait for the limiter.
+	rec := httptest.NewRecorder()
+	rw := bandwidth.ResponseWriter(ctx, rec)
+	written := make(chan int, 1)
+	go func() {
+		n, err := rw.Write(bytes.Repeat([]byte("a"), 150))
+		assert.NoError(t, err)
+		written <- n
+	}()
+	call := trap.MustWait(ctx)
+	require.Equal(t, 500*time.Millisecond, call.Duration)
+	require.Equal(t, 100, rec.Body.Len())
+	call.Release()
+	clock.Advance(call.Duration).MustWait(ctx)
+	require.Equal(t, 150, testutil.RequireRecvCtx(ctx, t, written))
+	require.Equal(t, 150, rec.Body.Len())
+
+	// Reads share the same limit as writes to the same app, so the bucket is
+	// still empty.
+	body := bandwidth.Body(ctx, io.NopCloser(bytes.NewReader(bytes.Repeat([]byte("b"), 50))))
+	read := make(chan []byte, 1)
+	go func() {
+		data, err := io.ReadAll(body)
+		assert.NoError(t, err)
+		read <- data
+	}()
+	call = trap.MustWait(ctx)
+	require.Equal(t, 500*time.Millisecond, call.Duration)
+	call.Release()
+	clock.Advance(call.Duration).MustWait(ctx)
+	require.Len(t, testutil.RequireRecvCtx(ctx, t, read), 50)
+}
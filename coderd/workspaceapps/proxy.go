@@ -111,6 +111,9 @@ type Server struct {
 
 	AgentProvider  AgentProvider
 	StatsCollector *StatsCollector
+	// Limiter enforces the connection and bandwidth limits in app tokens. If
+	// nil, no limits are enforced.
+	Limiter *Limiter
 
 	websocketWaitMutex sync.Mutex
 	websocketWaitGroup sync.WaitGroup
@@ -501,6 +504,21 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 		return
 	}
 
+	if protocol := codersdk.WorkspaceAppProtocolFromURL(appURL); !protocol.Proxied() {
+		workspace := appToken.UsernameOrID + "/" + appToken.WorkspaceNameOrID
+		if appToken.AgentNameOrID != "" {
+			workspace += "." + appToken.AgentNameOrID
+		}
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status: http.StatusBadRequest,
+			Title:  "Bad Request",
+			Description: fmt.Sprintf("Application %q uses %s and cannot be opened in a browser. Run \"coder port-forward %s --app %s\" to reach it from your machine.",
+				appToken.AppSlugOrPort, strings.ToUpper(string(protocol)), workspace, appToken.AppSlugOrPort),
+			DashboardURL: s.DashboardURL.String(),
+		})
+		return
+	}
+
 	// Verify that the port is allowed. See the docs above
 	// `codersdk.MinimumListeningPort` for more details.
 	port := appURL.Port()
@@ -550,6 +568,22 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 		appURL.Scheme = protocol
 	}
 
+	if s.Limiter != nil {
+		bandwidth, release, ok := s.Limiter.Acquire(appToken, r.RemoteAddr)
+		if !ok {
+			WriteWorkspaceAppTooManyConnections(s.Logger, s.DashboardURL, rw, r, &appToken.Request, appToken.Limits.MaxConnectionsPerUser)
+			return
+		}
+		// Hijacked connections are closed before ServeHTTP returns.
+		defer release()
+		if bandwidth != nil {
+			rw = bandwidth.ResponseWriter(ctx, rw)
+			if r.Body != nil {
+				r.Body = bandwidth.Body(ctx, r.Body)
+			}
+		}
+	}
+
 	proxy := s.AgentProvider.ReverseProxy(appURL, s.DashboardURL, appToken.AgentID, app, s.Hostname)
 
 	proxy.ModifyResponse = func(r *http.Response) error {
@@ -34,6 +34,10 @@ type SignedToken struct {
 	WorkspaceID uuid.UUID `json:"workspace_id"`
 	AgentID     uuid.UUID `json:"agent_id"`
 	AppURL      string    `json:"app_url"`
+	// RequesterID is the user the token was issued to. It is unset for
+	// anonymous access to public apps.
+	RequesterID uuid.UUID `json:"requester_id,omitempty"`
+	Limits      Limits    `json:"limits"`
 }
 
 // MatchesRequest returns true if the token matches the request. Any token that
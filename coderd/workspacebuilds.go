@@ -647,6 +647,89 @@ func (api *API) workspaceBuildState(rw http.ResponseWriter, r *http.Request) {
 	_, _ = rw.Write(workspaceBuild.ProvisionerState)
 }
 
+// @Summary Get workspace build timings by ID
+// @ID get-workspace-build-timings-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Builds
+// @Param workspacebuild path string true "Workspace build ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceBuildTimings
+// @Router /workspacebuilds/{workspacebuild}/timings [get]
+func (api *API) workspaceBuildTimings(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceBuild := httpmw.WorkspaceBuildParam(r)
+
+	provisionerTimings, err := api.Database.GetProvisionerJobTimingsByJobID(ctx, workspaceBuild.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job timings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// nolint:gocritic // GetWorkspaceResourcesByJobID is a system function.
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(dbauthz.AsSystemRestricted(ctx), workspaceBuild.JobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	// nolint:gocritic // GetWorkspaceAgentsByResourceIDs is a system function.
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(dbauthz.AsSystemRestricted(ctx), resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agents.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceBuildTimings{
+		ProvisionerTimings: db2sdk.ProvisionerTimings(provisionerTimings),
+		AgentTimings:       convertAgentTimings(agents),
+	})
+}
+
+// convertAgentTimings returns the connection and startup timings of the
+// agents that have reached those stages.
+func convertAgentTimings(agents []database.WorkspaceAgent) []codersdk.AgentTiming {
+	timings := make([]codersdk.AgentTiming, 0, len(agents)*2)
+	for _, agent := range agents {
+		status := codersdk.WorkspaceAgentLifecycle(agent.LifecycleState)
+		if agent.FirstConnectedAt.Valid {
+			timings = append(timings, codersdk.AgentTiming{
+				AgentID:   agent.ID,
+				AgentName: agent.Name,
+				Stage:     codersdk.AgentTimingStageConnect,
+				StartedAt: agent.CreatedAt,
+				EndedAt:   agent.FirstConnectedAt.Time,
+				Status:    status,
+			})
+		}
+		if agent.StartedAt.Valid && agent.ReadyAt.Valid {
+			timings = append(timings, codersdk.AgentTiming{
+				AgentID:   agent.ID,
+				AgentName: agent.Name,
+				Stage:     codersdk.AgentTimingStageStart,
+				StartedAt: agent.StartedAt.Time,
+				EndedAt:   agent.ReadyAt.Time,
+				Status:    status,
+			})
+		}
+	}
+	slices.SortStableFunc(timings, func(a, b codersdk.AgentTiming) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return timings
+}
+
 type workspaceBuildsData struct {
 	users            []database.User
 	jobs             []database.GetProvisionerJobsByIDsWithQueuePositionRow
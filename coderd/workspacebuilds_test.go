@@ -15,9 +15,11 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/v2/agent/agenttest"
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/coderdtest"
 	"github.com/coder/coder/v2/coderd/coderdtest/oidctest"
@@ -646,6 +648,91 @@ func TestWorkspaceBuildState(t *testing.T) {
 	require.Equal(t, wantState, gotState)
 }
 
+func TestWorkspaceBuildTimings(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	planTiming := &proto.Timing{
+		Start:    timestamppb.New(now),
+		End:      timestamppb.New(now.Add(time.Second)),
+		Action:   "read",
+		Source:   "coder",
+		Resource: "data.coder_workspace.me",
+		Stage:    "plan",
+	}
+	applyTiming := &proto.Timing{
+		Start:    timestamppb.New(now.Add(2 * time.Second)),
+		End:      timestamppb.New(now.Add(5 * time.Second)),
+		Action:   "create",
+		Source:   "docker",
+		Resource: "docker_container.workspace",
+		Stage:    "apply",
+	}
+	authToken := uuid.NewString()
+	apply := echo.ProvisionApplyWithAgent(authToken)
+	apply[0].GetApply().Timings = []*proto.Timing{applyTiming}
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse: echo.ParseComplete,
+		ProvisionPlan: []*proto.Response{{
+			Type: &proto.Response_Plan{
+				Plan: &proto.PlanComplete{
+					Timings: []*proto.Timing{planTiming},
+				},
+			},
+		}},
+		ProvisionApply: apply,
+	})
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+	_ = agenttest.New(t, client.URL, authToken)
+	coderdtest.NewWorkspaceAgentWaiter(t, client, workspace.ID).Wait()
+
+	t.Run("TemplateVersion", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// The template is planned once for start and once for stop.
+		timings, err := client.TemplateVersionTimings(ctx, version.ID)
+		require.NoError(t, err)
+		require.Len(t, timings, 2)
+		for _, timing := range timings {
+			assert.Equal(t, version.Job.ID, timing.JobID)
+			assert.Equal(t, codersdk.ProvisionerTimingStagePlan, timing.Stage)
+			assert.Equal(t, "data.coder_workspace.me", timing.Resource)
+			assert.Equal(t, "read", timing.Action)
+			assert.Equal(t, "coder", timing.Source)
+			assert.True(t, timing.StartedAt.Equal(now))
+			assert.True(t, timing.EndedAt.Equal(now.Add(time.Second)))
+		}
+	})
+
+	t.Run("WorkspaceBuild", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		timings, err := client.WorkspaceBuildTimings(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		require.Len(t, timings.ProvisionerTimings, 2)
+		assert.Equal(t, codersdk.ProvisionerTimingStagePlan, timings.ProvisionerTimings[0].Stage)
+		assert.Equal(t, codersdk.ProvisionerTimingStageApply, timings.ProvisionerTimings[1].Stage)
+		assert.Equal(t, "docker_container.workspace", timings.ProvisionerTimings[1].Resource)
+		assert.True(t, timings.ProvisionerTimings[1].EndedAt.Equal(now.Add(5*time.Second)))
+
+		require.Len(t, timings.AgentTimings, 2)
+		assert.Equal(t, codersdk.AgentTimingStageConnect, timings.AgentTimings[0].Stage)
+		assert.Equal(t, codersdk.AgentTimingStageStart, timings.AgentTimings[1].Stage)
+		for _, timing := range timings.AgentTimings {
+			assert.Equal(t, "example", timing.AgentName)
+			assert.Equal(t, codersdk.WorkspaceAgentLifecycleReady, timing.Status)
+			assert.False(t, timing.EndedAt.Before(timing.StartedAt))
+		}
+	})
+}
+
 func TestWorkspaceBuildStatus(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,358 @@
+package coderd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/v2/coderd/audit"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/database/provisionerjobs"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
+	"github.com/coder/coder/v2/coderd/provisionerdserver"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
+	"github.com/coder/coder/v2/coderd/wsbuilder"
+	"github.com/coder/coder/v2/codersdk"
+)
+
+// @Summary Create workspace plan
+// @ID create-workspace-plan
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.CreateWorkspacePlanRequest true "Plan request"
+// @Success 201 {object} codersdk.ProvisionerJob
+// @Router /workspaces/{workspace}/plans [post]
+func (api *API) postWorkspacePlan(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+	workspace := httpmw.WorkspaceParam(r)
+	var req codersdk.CreateWorkspacePlanRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	builder := wsbuilder.New(workspace, database.WorkspaceTransition(req.Transition)).
+		Initiator(apiKey.UserID).
+		RichParameterValues(req.RichParameterValues).
+		LogLevel(string(req.LogLevel)).
+		DeploymentValues(api.Options.DeploymentValues).
+		PlanOnly()
+	if req.TemplateVersionID != uuid.Nil {
+		builder = builder.VersionID(req.TemplateVersionID)
+	}
+
+	_, provisionerJob, err := builder.Build(
+		ctx,
+		api.Database,
+		func(action policy.Action, object rbac.Objecter) bool {
+			return api.Authorize(r, action, object)
+		},
+		audit.WorkspaceBuildBaggageFromRequest(r),
+	)
+	var buildErr wsbuilder.BuildError
+	if xerrors.As(err, &buildErr) {
+		var authErr dbauthz.NotAuthorizedError
+		if xerrors.As(err, &authErr) {
+			buildErr.Status = http.StatusForbidden
+		}
+
+		if buildErr.Status == http.StatusInternalServerError {
+			api.Logger.Error(ctx, "workspace plan error", slog.Error(buildErr.Wrapped))
+		}
+
+		httpapi.Write(ctx, rw, buildErr.Status, codersdk.Response{
+			Message: buildErr.Message,
+			Detail:  buildErr.Error(),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Error posting new plan",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	err = provisionerjobs.PostJob(api.Pubsub, *provisionerJob)
+	if err != nil {
+		// Client probably doesn't care about this error, so just log it.
+		api.Logger.Error(ctx, "failed to post provisioner job to pubsub", slog.Error(err))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertProvisionerJob(database.GetProvisionerJobsByIDsWithQueuePositionRow{
+		ProvisionerJob: *provisionerJob,
+		QueuePosition:  0,
+	}))
+}
+
+// @Summary Get workspace plan by job ID
+// @ID get-workspace-plan-by-job-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param jobID path string true "Job ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspacePlan
+// @Router /workspaces/{workspace}/plans/{jobID} [get]
+func (api *API) workspacePlan(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	job, ok := api.fetchWorkspacePlanJob(rw, r)
+	if !ok {
+		return
+	}
+
+	changes, err := api.Database.GetProvisionerJobResourceChangesByJobID(ctx, job.ProvisionerJob.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching resource changes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspacePlan{
+		Job:             convertProvisionerJob(job),
+		ResourceChanges: convertWorkspaceResourceChanges(changes),
+	})
+}
+
+// @Summary Get workspace plan logs by job ID
+// @ID get-workspace-plan-logs-by-job-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param jobID path string true "Job ID" format(uuid)
+// @Param before query int false "Before Unix timestamp"
+// @Param after query int false "After Unix timestamp"
+// @Param follow query bool false "Follow log stream"
+// @Success 200 {array} codersdk.ProvisionerJobLog
+// @Router /workspaces/{workspace}/plans/{jobID}/logs [get]
+func (api *API) workspacePlanLogs(rw http.ResponseWriter, r *http.Request) {
+	job, ok := api.fetchWorkspacePlanJob(rw, r)
+	if !ok {
+		return
+	}
+
+	api.provisionerJobLogs(rw, r, job.ProvisionerJob)
+}
+
+// @Summary Cancel workspace plan by job ID
+// @ID cancel-workspace-plan-by-job-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param jobID path string true "Job ID" format(uuid)
+// @Success 200 {object} codersdk.Response
+// @Router /workspaces/{workspace}/plans/{jobID}/cancel [patch]
+func (api *API) patchWorkspacePlanCancel(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	if !api.Authorize(r, policy.ActionUpdate, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	job, ok := api.fetchWorkspacePlanJob(rw, r)
+	if !ok {
+		return
+	}
+
+	if job.ProvisionerJob.CompletedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Job has already completed.",
+		})
+		return
+	}
+	if job.ProvisionerJob.CanceledAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Job has already been marked as canceled.",
+		})
+		return
+	}
+
+	err := api.Database.UpdateProvisionerJobWithCancelByID(ctx, database.UpdateProvisionerJobWithCancelByIDParams{
+		ID: job.ProvisionerJob.ID,
+		CanceledAt: sql.NullTime{
+			Time:  dbtime.Now(),
+			Valid: true,
+		},
+		CompletedAt: sql.NullTime{
+			Time: dbtime.Now(),
+			// If the job is running, don't mark it completed!
+			Valid: !job.ProvisionerJob.WorkerID.Valid,
+		},
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Job has been marked as canceled.",
+	})
+}
+
+// @Summary Get workspace drift
+// @ID get-workspace-drift
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceDrift
+// @Router /workspaces/{workspace}/drift [get]
+func (api *API) workspaceDrift(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspace := httpmw.WorkspaceParam(r)
+
+	drift := codersdk.WorkspaceDrift{
+		Resources: []codersdk.WorkspaceResourceChange{},
+	}
+	check, err := api.Database.GetWorkspaceDriftCheckByWorkspaceID(ctx, workspace.ID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusOK, drift)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace drift check.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	jobs, err := api.Database.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{check.JobID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(jobs) > 0 {
+		latestCheck := convertProvisionerJob(jobs[0])
+		drift.LatestCheck = &latestCheck
+	}
+	if !check.ResultJobID.Valid || !check.CheckedAt.Valid {
+		httpapi.Write(ctx, rw, http.StatusOK, drift)
+		return
+	}
+
+	// A build after the last check replaces the state the check compared
+	// against, so the drift it found no longer applies.
+	build, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	resultJob, err := api.Database.GetProvisionerJobByID(ctx, check.ResultJobID.UUID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching drift check job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if resultJob.CreatedAt.Before(build.CreatedAt) {
+		httpapi.Write(ctx, rw, http.StatusOK, drift)
+		return
+	}
+
+	changes, err := api.Database.GetProvisionerJobResourceChangesByJobID(ctx, resultJob.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching resource changes.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	drift.CheckedAt = &check.CheckedAt.Time
+	drift.Resources = convertWorkspaceResourceChanges(changes)
+
+	httpapi.Write(ctx, rw, http.StatusOK, drift)
+}
+
+func (api *API) fetchWorkspacePlanJob(rw http.ResponseWriter, r *http.Request) (database.GetProvisionerJobsByIDsWithQueuePositionRow, bool) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		jobID     = chi.URLParam(r, "jobID")
+	)
+
+	jobUUID, err := uuid.Parse(jobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Job ID %q must be a valid UUID.", jobID),
+			Detail:  err.Error(),
+		})
+		return database.GetProvisionerJobsByIDsWithQueuePositionRow{}, false
+	}
+
+	jobs, err := api.Database.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{jobUUID})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return database.GetProvisionerJobsByIDsWithQueuePositionRow{}, false
+	}
+	if len(jobs) == 0 || jobs[0].ProvisionerJob.Type != database.ProvisionerJobTypeWorkspaceBuildPlan {
+		httpapi.ResourceNotFound(rw)
+		return database.GetProvisionerJobsByIDsWithQueuePositionRow{}, false
+	}
+	job := jobs[0]
+
+	// Reading the workspace is enough to read its plans, which the workspace
+	// middleware has already checked. Make sure the plan belongs to it.
+	var input provisionerdserver.WorkspacePlanJob
+	err = json.Unmarshal(job.ProvisionerJob.Input, &input)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error unmarshalling job metadata.",
+			Detail:  err.Error(),
+		})
+		return database.GetProvisionerJobsByIDsWithQueuePositionRow{}, false
+	}
+	if input.WorkspaceID != workspace.ID {
+		httpapi.ResourceNotFound(rw)
+		return database.GetProvisionerJobsByIDsWithQueuePositionRow{}, false
+	}
+
+	return job, true
+}
+
+func convertWorkspaceResourceChanges(changes []database.ProvisionerJobResourceChange) []codersdk.WorkspaceResourceChange {
+	converted := make([]codersdk.WorkspaceResourceChange, 0, len(changes))
+	for _, change := range changes {
+		action := codersdk.ResourceChangeAction(change.Action)
+		converted = append(converted, codersdk.WorkspaceResourceChange{
+			Address:     change.Address,
+			Type:        change.Type,
+			Name:        change.Name,
+			Action:      action,
+			Destructive: action.Destructive(),
+		})
+	}
+	return converted
+}
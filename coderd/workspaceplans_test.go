@@ -0,0 +1,195 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/driftdetector"
+	"github.com/coder/coder/v2/coderd/util/ptr"
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisioner/echo"
+	"github.com/coder/coder/v2/provisionersdk/proto"
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestWorkspacePlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ApplyComplete,
+			ProvisionPlanMap: map[proto.WorkspaceTransition][]*proto.Response{
+				proto.WorkspaceTransition_STOP: {{
+					Type: &proto.Response_Plan{
+						Plan: &proto.PlanComplete{
+							ResourceChanges: []*proto.ResourceChange{
+								{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: "delete"},
+								{Address: "coder_agent.main", Type: "coder_agent", Name: "main", Action: "update"},
+							},
+						},
+					},
+				}},
+			},
+		})
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		job, err := client.CreateWorkspacePlan(ctx, workspace.ID, codersdk.CreateWorkspacePlanRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+
+		var plan codersdk.WorkspacePlan
+		require.Eventually(t, func() bool {
+			plan, err = client.WorkspacePlan(ctx, workspace.ID, job.ID)
+			return assert.NoError(t, err) && plan.Job.CompletedAt != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.ProvisionerJobSucceeded, plan.Job.Status)
+		require.Equal(t, []codersdk.WorkspaceResourceChange{
+			{Address: "coder_agent.main", Type: "coder_agent", Name: "main", Action: codersdk.ResourceChangeActionUpdate},
+			{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: codersdk.ResourceChangeActionDelete, Destructive: true},
+		}, plan.ResourceChanges)
+
+		logs, closer, err := client.WorkspacePlanLogsAfter(ctx, workspace.ID, job.ID, 0)
+		require.NoError(t, err)
+		defer closer.Close()
+		logCount := 0
+		for range logs {
+			logCount++
+		}
+		require.GreaterOrEqual(t, logCount, 1, "unexpected log count")
+
+		// The plan must not have touched the workspace.
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+		require.Equal(t, int32(1), workspace.LatestBuild.BuildNumber)
+	})
+
+	t.Run("OtherWorkspace", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+		other := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, other.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		job, err := client.CreateWorkspacePlan(ctx, workspace.ID, codersdk.CreateWorkspacePlanRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+		})
+		require.NoError(t, err)
+
+		_, err = client.WorkspacePlan(ctx, other.ID, job.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// Builds are not plans.
+		_, err = client.WorkspacePlan(ctx, workspace.ID, workspace.LatestBuild.Job.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceDrift(t *testing.T) {
+	t.Parallel()
+
+	db, ps := dbtestutil.NewDB(t)
+	client := coderdtest.New(t, &coderdtest.Options{
+		Database:                 db,
+		Pubsub:                   ps,
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionApply: echo.ApplyComplete,
+		ProvisionPlan: []*proto.Response{{
+			Type: &proto.Response_Plan{
+				Plan: &proto.PlanComplete{
+					ResourceChanges: []*proto.ResourceChange{
+						{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: "update"},
+					},
+				},
+			},
+		}},
+	})
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	// Drift is never checked unless the template enables it.
+	drift, err := client.WorkspaceDrift(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Nil(t, drift.CheckedAt)
+	require.Nil(t, drift.LatestCheck)
+	require.Empty(t, drift.Resources)
+
+	_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		DriftDetectionIntervalMillis: ptr.Ref(time.Hour.Milliseconds()),
+	})
+	require.NoError(t, err)
+
+	tickCh := make(chan time.Time)
+	statsCh := make(chan driftdetector.Stats)
+	detector := driftdetector.New(ctx, db, ps, slogtest.Make(t, nil), tickCh).WithStatsChannel(statsCh)
+	detector.Start()
+	t.Cleanup(detector.Close)
+
+	tickCh <- time.Now()
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Contains(t, stats.ScheduledJobIDs, workspace.ID)
+
+	require.Eventually(t, func() bool {
+		drift, err = client.WorkspaceDrift(ctx, workspace.ID)
+		return assert.NoError(t, err) && drift.CheckedAt != nil
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.NotNil(t, drift.LatestCheck)
+	require.Equal(t, stats.ScheduledJobIDs[workspace.ID], drift.LatestCheck.ID)
+	require.Equal(t, codersdk.ProvisionerJobSucceeded, drift.LatestCheck.Status)
+	require.Equal(t, []codersdk.WorkspaceResourceChange{
+		{Address: "docker_container.dev", Type: "docker_container", Name: "dev", Action: codersdk.ResourceChangeActionUpdate},
+	}, drift.Resources)
+
+	// The drift check didn't touch the workspace.
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), workspace.LatestBuild.BuildNumber)
+
+	// A new build reconciles the drift.
+	build := coderdtest.CreateWorkspaceBuild(t, client, workspace, database.WorkspaceTransitionStart)
+	coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, build.ID)
+	drift, err = client.WorkspaceDrift(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Nil(t, drift.CheckedAt)
+	require.Empty(t, drift.Resources)
+}
@@ -92,3 +92,13 @@ func (api *API) regions(rw http.ResponseWriter, r *http.Request) {
 		Regions: []codersdk.Region{region},
 	})
 }
+
+// PrimaryWorkspaceProxyID returns the ID of the primary workspace proxy. It is
+// the deployment ID, the same as the ID returned by PrimaryRegion.
+func (api *API) PrimaryWorkspaceProxyID() uuid.UUID {
+	id, err := uuid.Parse(api.DeploymentID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return id
+}
@@ -53,6 +53,8 @@ type Builder struct {
 	richParameterValues []codersdk.WorkspaceBuildParameter
 	initiator           uuid.UUID
 	reason              database.BuildReason
+	planOnly            bool
+	refreshOnly         bool
 
 	// used during build, makes function arguments less verbose
 	ctx   context.Context
@@ -64,6 +66,7 @@ type Builder struct {
 	templateVersionJob           *database.ProvisionerJob
 	templateVersionParameters    *[]database.TemplateVersionParameter
 	templateVersionWorkspaceTags *[]database.TemplateVersionWorkspaceTag
+	canaryVersionID              *uuid.NullUUID
 	lastBuild                    *database.WorkspaceBuild
 	lastBuildErr                 *error
 	lastBuildParameters          *[]database.WorkspaceBuildParameter
@@ -166,6 +169,23 @@ func (b Builder) RichParameterValues(p []codersdk.WorkspaceBuildParameter) Build
 	return b
 }
 
+// PlanOnly makes Build insert a plan job instead of a workspace build.  The job runs a Terraform plan against the
+// state of the last build and records the resulting resource changes without applying them.
+func (b Builder) PlanOnly() Builder {
+	// nolint: revive
+	b.planOnly = true
+	return b
+}
+
+// RefreshOnly is like PlanOnly, but runs a refresh-only plan that records resources which drifted from the state of
+// the last build.  Drift checks run in the background, so they are scheduled after other jobs.
+func (b Builder) RefreshOnly() Builder {
+	// nolint: revive
+	b.planOnly = true
+	b.refreshOnly = true
+	return b
+}
+
 // SetLastWorkspaceBuildInTx prepopulates the Builder's cache with the last workspace build.  This allows us
 // to avoid a repeated database query when the Builder's caller also needs the workspace build, e.g. auto-start &
 // auto-stop.
@@ -207,6 +227,8 @@ func (e BuildError) Unwrap() error {
 
 // Build computes and inserts a new workspace build into the database.  If authFunc is provided, it also performs
 // authorization preflight checks.
+//
+// For plan-only builders no workspace build is inserted, and the returned build is nil.
 func (b *Builder) Build(
 	ctx context.Context,
 	store database.Store,
@@ -288,6 +310,10 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 		b.reason = database.BuildReasonInitiator
 	}
 
+	if b.planOnly {
+		return b.planTx()
+	}
+
 	workspaceBuildID := uuid.New()
 	input, err := json.Marshal(provisionerdserver.WorkspaceProvisionJob{
 		WorkspaceBuildID: workspaceBuildID,
@@ -310,6 +336,13 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 		return nil, nil, err // already wrapped BuildError
 	}
 
+	// Builds that nobody is waiting on are scheduled after user builds and
+	// template imports.
+	priority := database.ProvisionerJobPriorityInteractive
+	if b.reason != database.BuildReasonInitiator {
+		priority = database.ProvisionerJobPriorityAutobuild
+	}
+
 	now := dbtime.Now()
 	provisionerJob, err := b.store.InsertProvisionerJob(b.ctx, database.InsertProvisionerJobParams{
 		ID:             uuid.New(),
@@ -317,7 +350,7 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 		UpdatedAt:      now,
 		InitiatorID:    b.initiator,
 		OrganizationID: template.OrganizationID,
-		Provisioner:    template.Provisioner,
+		Provisioner:    templateVersionJob.Provisioner,
 		Type:           database.ProvisionerJobTypeWorkspaceBuild,
 		StorageMethod:  templateVersionJob.StorageMethod,
 		FileID:         templateVersionJob.FileID,
@@ -327,6 +360,7 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 			Valid:      true,
 			RawMessage: traceMetadataRaw,
 		},
+		Priority: priority,
 	})
 	if err != nil {
 		return nil, nil, BuildError{http.StatusInternalServerError, "insert provisioner job", err}
@@ -399,6 +433,87 @@ func (b *Builder) buildTx(authFunc func(action policy.Action, object rbac.Object
 	return &workspaceBuild, &provisionerJob, nil
 }
 
+// planTx inserts a plan job for the workspace.  Parameters are resolved and validated exactly as they would be for a
+// real build, but they are carried in the job input rather than stored against a build.
+func (b *Builder) planTx() (*database.WorkspaceBuild, *database.ProvisionerJob, error) {
+	template, err := b.getTemplate()
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "failed to fetch template", err}
+	}
+	templateVersionJob, err := b.getTemplateVersionJob()
+	if err != nil {
+		return nil, nil, BuildError{
+			http.StatusInternalServerError, "failed to fetch template version job", err,
+		}
+	}
+	templateVersionID, err := b.getTemplateVersionID()
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "compute template version ID", err}
+	}
+	names, values, err := b.getParameters()
+	if err != nil {
+		// getParameters already wraps errors in BuildError
+		return nil, nil, err
+	}
+	parameters := make([]database.WorkspaceBuildParameter, 0, len(names))
+	for i, name := range names {
+		parameters = append(parameters, database.WorkspaceBuildParameter{
+			Name:  name,
+			Value: values[i],
+		})
+	}
+
+	input, err := json.Marshal(provisionerdserver.WorkspacePlanJob{
+		WorkspaceID:         b.workspace.ID,
+		TemplateVersionID:   templateVersionID,
+		Transition:          b.trans,
+		RichParameterValues: parameters,
+		LogLevel:            b.logLevel,
+		RefreshOnly:         b.refreshOnly,
+	})
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "marshal plan job", err}
+	}
+	traceMetadataRaw, err := json.Marshal(tracing.MetadataFromContext(b.ctx))
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "marshal metadata", err}
+	}
+	tags, err := b.getProvisionerTags()
+	if err != nil {
+		return nil, nil, err // already wrapped BuildError
+	}
+
+	// Somebody is always waiting on a plan, except for drift checks.
+	priority := database.ProvisionerJobPriorityInteractive
+	if b.refreshOnly {
+		priority = database.ProvisionerJobPriorityAutobuild
+	}
+
+	now := dbtime.Now()
+	provisionerJob, err := b.store.InsertProvisionerJob(b.ctx, database.InsertProvisionerJobParams{
+		ID:             uuid.New(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		InitiatorID:    b.initiator,
+		OrganizationID: template.OrganizationID,
+		Provisioner:    templateVersionJob.Provisioner,
+		Type:           database.ProvisionerJobTypeWorkspaceBuildPlan,
+		StorageMethod:  templateVersionJob.StorageMethod,
+		FileID:         templateVersionJob.FileID,
+		Input:          input,
+		Tags:           tags,
+		TraceMetadata: pqtype.NullRawMessage{
+			Valid:      true,
+			RawMessage: traceMetadataRaw,
+		},
+		Priority: priority,
+	})
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "insert provisioner job", err}
+	}
+	return nil, &provisionerJob, nil
+}
+
 func (b *Builder) getTemplate() (*database.Template, error) {
 	if b.template != nil {
 		return b.template, nil
@@ -445,14 +560,23 @@ func (b *Builder) getTemplateVersion() (*database.TemplateVersion, error) {
 
 func (b *Builder) getTemplateVersionID() (uuid.UUID, error) {
 	if b.version.specific != nil {
-		return *b.version.specific, nil
+		t, err := b.getTemplate()
+		if err != nil {
+			return uuid.Nil, xerrors.Errorf("get template so we can compare active version: %w", err)
+		}
+		// Asking for the active version by ID, e.g. when updating a
+		// workspace, is treated the same as asking for the active version.
+		if *b.version.specific != t.ActiveVersionID {
+			return *b.version.specific, nil
+		}
+		return b.getActiveOrCanaryVersionID(t)
 	}
 	if b.version.active {
 		t, err := b.getTemplate()
 		if err != nil {
 			return uuid.Nil, xerrors.Errorf("get template so we can get active version: %w", err)
 		}
-		return t.ActiveVersionID, nil
+		return b.getActiveOrCanaryVersionID(t)
 	}
 	// default is prior version
 	bld, err := b.getLastBuild()
@@ -462,6 +586,27 @@ func (b *Builder) getTemplateVersionID() (uuid.UUID, error) {
 	return bld.TemplateVersionID, nil
 }
 
+// getActiveOrCanaryVersionID returns the version of an active canary rollout if
+// the workspace is part of its cohort, and the template's active version
+// otherwise.
+func (b *Builder) getActiveOrCanaryVersionID(t *database.Template) (uuid.UUID, error) {
+	if b.canaryVersionID == nil {
+		var canaryVersionID uuid.NullUUID
+		canary, err := b.store.GetTemplateVersionCanaryByWorkspaceID(b.ctx, b.workspace.ID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, xerrors.Errorf("get template version canary: %w", err)
+		}
+		if err == nil {
+			canaryVersionID = uuid.NullUUID{UUID: canary.TemplateVersionID, Valid: true}
+		}
+		b.canaryVersionID = &canaryVersionID
+	}
+	if b.canaryVersionID.Valid {
+		return b.canaryVersionID.UUID, nil
+	}
+	return t.ActiveVersionID, nil
+}
+
 func (b *Builder) getLastBuild() (*database.WorkspaceBuild, error) {
 	if b.lastBuild != nil {
 		return b.lastBuild, nil
@@ -251,6 +251,45 @@ func TestBuilder_ActiveVersion(t *testing.T) {
 	req.NoError(err)
 }
 
+func TestBuilder_CanaryVersion(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		withCanary(inactiveVersionID),
+		withInactiveVersion(nil),
+		withLastBuildNotFound,
+		withParameterSchemas(inactiveJobID, nil),
+		withWorkspaceTags(inactiveVersionID, nil),
+
+		// Outputs
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			asrt.Equal(inactiveFileID, job.FileID)
+		}),
+
+		withInTx,
+		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+			// The workspace is in the canary cohort, so it gets the canary
+			// version instead of the active one.
+			asrt.Equal(inactiveVersionID, bld.TemplateVersionID)
+		}),
+		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+		}),
+		withBuild,
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).ActiveVersion()
+	_, _, err := uut.Build(ctx, mDB, nil, audit.WorkspaceBuildBaggage{})
+	req.NoError(err)
+}
+
 func TestWorkspaceBuildWithTags(t *testing.T) {
 	t.Parallel()
 
@@ -771,6 +810,10 @@ func withInTx(mTx *dbmock.MockStore) {
 
 func withActiveVersion(params []database.TemplateVersionParameter) func(mTx *dbmock.MockStore) {
 	return func(mTx *dbmock.MockStore) {
+		mTx.EXPECT().GetTemplateVersionCanaryByWorkspaceID(gomock.Any(), workspaceID).
+			Times(1).
+			Return(database.TemplateVersionCanary{}, sql.ErrNoRows)
+
 		mTx.EXPECT().GetTemplateVersionByID(gomock.Any(), activeVersionID).
 			Times(1).
 			Return(database.TemplateVersion{
@@ -809,6 +852,18 @@ func withActiveVersion(params []database.TemplateVersionParameter) func(mTx *dbm
 	}
 }
 
+func withCanary(versionID uuid.UUID) func(mTx *dbmock.MockStore) {
+	return func(mTx *dbmock.MockStore) {
+		mTx.EXPECT().GetTemplateVersionCanaryByWorkspaceID(gomock.Any(), workspaceID).
+			Times(1).
+			Return(database.TemplateVersionCanary{
+				TemplateID:        templateID,
+				TemplateVersionID: versionID,
+				Status:            database.TemplateVersionCanaryStatusActive,
+			}, nil)
+	}
+}
+
 func withInactiveVersion(params []database.TemplateVersionParameter) func(mTx *dbmock.MockStore) {
 	return func(mTx *dbmock.MockStore) {
 		mTx.EXPECT().GetTemplateVersionByID(gomock.Any(), inactiveVersionID).
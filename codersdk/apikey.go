@@ -18,10 +18,22 @@ type APIKey struct {
 	ExpiresAt       time.Time   `json:"expires_at" validate:"required" format:"date-time"`
 	CreatedAt       time.Time   `json:"created_at" validate:"required" format:"date-time"`
 	UpdatedAt       time.Time   `json:"updated_at" validate:"required" format:"date-time"`
-	LoginType       LoginType   `json:"login_type" validate:"required" enums:"password,github,oidc,token"`
+	LoginType       LoginType   `json:"login_type" validate:"required" enums:"password,github,oidc,ldap,token"`
 	Scope           APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect"`
 	TokenName       string      `json:"token_name" validate:"required"`
 	LifetimeSeconds int64       `json:"lifetime_seconds" validate:"required"`
+	// Scopes are the named scopes the key is limited to. If empty, the key is
+	// limited to Scope instead.
+	Scopes []APIKeyScope `json:"scopes" enums:"workspace:read,workspace:create,workspace:start,workspace:stop,workspace:delete,workspace:ssh,template:read,template:update,user:read"`
+	// AllowList contains the resources the key is limited to. If empty, the
+	// key can be used with every resource its scopes allow.
+	AllowList []APIAllowListTarget `json:"allow_list"`
+}
+
+// APIAllowListTarget is a resource that an API key is limited to.
+type APIAllowListTarget struct {
+	Type RBACResource `json:"type" enums:"workspace,template"`
+	ID   uuid.UUID    `json:"id" format:"uuid"`
 }
 
 // LoginType is the type of login used to create the API key.
@@ -32,6 +44,7 @@ const (
 	LoginTypePassword LoginType = "password"
 	LoginTypeGithub   LoginType = "github"
 	LoginTypeOIDC     LoginType = "oidc"
+	LoginTypeLDAP     LoginType = "ldap"
 	LoginTypeToken    LoginType = "token"
 	// LoginTypeNone is used if no login method is available for this user.
 	// If this is set, the user has no method of logging in.
@@ -50,10 +63,30 @@ const (
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
 )
 
+// Named scopes that tokens can be limited to. They can be combined, and a
+// token with any of them can only be used for what they allow.
+const (
+	APIKeyScopeWorkspaceRead   APIKeyScope = "workspace:read"
+	APIKeyScopeWorkspaceCreate APIKeyScope = "workspace:create"
+	APIKeyScopeWorkspaceStart  APIKeyScope = "workspace:start"
+	APIKeyScopeWorkspaceStop   APIKeyScope = "workspace:stop"
+	APIKeyScopeWorkspaceDelete APIKeyScope = "workspace:delete"
+	APIKeyScopeWorkspaceSSH    APIKeyScope = "workspace:ssh"
+	APIKeyScopeTemplateRead    APIKeyScope = "template:read"
+	APIKeyScopeTemplateUpdate  APIKeyScope = "template:update"
+	APIKeyScopeUserRead        APIKeyScope = "user:read"
+)
+
 type CreateTokenRequest struct {
 	Lifetime  time.Duration `json:"lifetime"`
 	Scope     APIKeyScope   `json:"scope" enums:"all,application_connect"`
 	TokenName string        `json:"token_name"`
+	// Scopes limits the token to the named scopes. Scope must be empty or
+	// "all" if any are set.
+	Scopes []APIKeyScope `json:"scopes,omitempty" enums:"workspace:read,workspace:create,workspace:start,workspace:stop,workspace:delete,workspace:ssh,template:read,template:update,user:read"`
+	// AllowList limits the token to the given resources. It requires Scopes.
+	// Allowing a workspace also allows its template.
+	AllowList []APIAllowListTarget `json:"allow_list,omitempty"`
 }
 
 // GenerateAPIKeyResponse contains an API key for a user.
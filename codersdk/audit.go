@@ -34,6 +34,8 @@ const (
 	ResourceTypeCustomRole              ResourceType = "custom_role"
 	ResourceTypeOrganizationMember                   = "organization_member"
 	ResourceTypeNotificationTemplate                 = "notification_template"
+	ResourceTypeRoleGrantRequest        ResourceType = "role_grant_request"
+	ResourceTypeTemplateVersionCanary   ResourceType = "template_version_canary"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -78,6 +80,10 @@ func (r ResourceType) FriendlyString() string {
 		return "organization member"
 	case ResourceTypeNotificationTemplate:
 		return "notification template"
+	case ResourceTypeRoleGrantRequest:
+		return "role grant request"
+	case ResourceTypeTemplateVersionCanary:
+		return "template version canary"
 	default:
 		return "unknown"
 	}
@@ -94,6 +100,7 @@ const (
 	AuditActionLogin    AuditAction = "login"
 	AuditActionLogout   AuditAction = "logout"
 	AuditActionRegister AuditAction = "register"
+	AuditActionOpen     AuditAction = "open"
 )
 
 func (a AuditAction) Friendly() string {
@@ -114,6 +121,8 @@ func (a AuditAction) Friendly() string {
 		return "logged out"
 	case AuditActionRegister:
 		return "registered"
+	case AuditActionOpen:
+		return "opened"
 	default:
 		return "unknown"
 	}
@@ -57,6 +57,7 @@ type FeatureName string
 
 const (
 	FeatureUserLimit                  FeatureName = "user_limit"
+	FeatureServiceAccountLimit        FeatureName = "service_account_limit"
 	FeatureAuditLog                   FeatureName = "audit_log"
 	FeatureBrowserOnly                FeatureName = "browser_only"
 	FeatureSCIM                       FeatureName = "scim"
@@ -79,6 +80,7 @@ const (
 // FeatureNames must be kept in-sync with the Feature enum above.
 var FeatureNames = []FeatureName{
 	FeatureUserLimit,
+	FeatureServiceAccountLimit,
 	FeatureAuditLog,
 	FeatureBrowserOnly,
 	FeatureSCIM,
@@ -110,6 +112,18 @@ func (n FeatureName) Humanize() string {
 	}
 }
 
+// UsesLimit returns true if the feature is a limit that must be specifically
+// defined in the license, rather than a feature that is granted by a feature
+// set.
+func (n FeatureName) UsesLimit() bool {
+	switch n {
+	case FeatureUserLimit, FeatureServiceAccountLimit:
+		return true
+	default:
+		return false
+	}
+}
+
 // AlwaysEnable returns if the feature is always enabled if entitled.
 // This is required because some features are only enabled if they are entitled
 // and not required.
@@ -347,6 +361,7 @@ type DeploymentValues struct {
 	PostgresAuth                    string                               `json:"pg_auth,omitempty" typescript:",notnull"`
 	OAuth2                          OAuth2Config                         `json:"oauth2,omitempty" typescript:",notnull"`
 	OIDC                            OIDCConfig                           `json:"oidc,omitempty" typescript:",notnull"`
+	LDAP                            LDAPConfig                           `json:"ldap,omitempty" typescript:",notnull"`
 	Telemetry                       TelemetryConfig                      `json:"telemetry,omitempty" typescript:",notnull"`
 	TLS                             TLSConfig                            `json:"tls,omitempty" typescript:",notnull"`
 	Trace                           TraceConfig                          `json:"trace,omitempty" typescript:",notnull"`
@@ -370,6 +385,7 @@ type DeploymentValues struct {
 	DisablePathApps                 serpent.Bool                         `json:"disable_path_apps,omitempty" typescript:",notnull"`
 	Sessions                        SessionLifetime                      `json:"session_lifetime,omitempty" typescript:",notnull"`
 	DisablePasswordAuth             serpent.Bool                         `json:"disable_password_auth,omitempty" typescript:",notnull"`
+	RequireOwnerMFA                 serpent.Bool                         `json:"require_owner_mfa,omitempty" typescript:",notnull"`
 	Support                         SupportConfig                        `json:"support,omitempty" typescript:",notnull"`
 	ExternalAuthConfigs             serpent.Struct[[]ExternalAuthConfig] `json:"external_auth,omitempty" typescript:",notnull"`
 	SSHConfig                       SSHConfig                            `json:"config_ssh,omitempty" typescript:",notnull"`
@@ -525,12 +541,35 @@ type OIDCConfig struct {
 	UserRoleField       serpent.String                      `json:"user_role_field" typescript:",notnull"`
 	UserRoleMapping     serpent.Struct[map[string][]string] `json:"user_role_mapping" typescript:",notnull"`
 	UserRolesDefault    serpent.StringArray                 `json:"user_roles_default" typescript:",notnull"`
+	OrganizationField   serpent.String                      `json:"organization_field" typescript:",notnull"`
+	OrganizationMapping serpent.Struct[map[string][]string] `json:"organization_mapping" typescript:",notnull"`
 	SignInText          serpent.String                      `json:"sign_in_text" typescript:",notnull"`
 	IconURL             serpent.URL                         `json:"icon_url" typescript:",notnull"`
 	SignupsDisabledText serpent.String                      `json:"signups_disabled_text" typescript:",notnull"`
 	SkipIssuerChecks    serpent.Bool                        `json:"skip_issuer_checks" typescript:",notnull"`
 }
 
+// LDAPConfig configures logging in with the credentials of an LDAP directory
+// account. Login with LDAP is enabled when URL is set.
+type LDAPConfig struct {
+	URL                serpent.String                    `json:"url" typescript:",notnull"`
+	StartTLS           serpent.Bool                      `json:"start_tls" typescript:",notnull"`
+	InsecureSkipVerify serpent.Bool                      `json:"insecure_skip_verify" typescript:",notnull"`
+	CAFile             serpent.String                    `json:"ca_file" typescript:",notnull"`
+	BindDN             serpent.String                    `json:"bind_dn" typescript:",notnull"`
+	BindPassword       serpent.String                    `json:"bind_password" typescript:",notnull"`
+	SearchBaseDN       serpent.String                    `json:"search_base_dn" typescript:",notnull"`
+	SearchFilter       serpent.String                    `json:"search_filter" typescript:",notnull"`
+	UsernameAttribute  serpent.String                    `json:"username_attribute" typescript:",notnull"`
+	EmailAttribute     serpent.String                    `json:"email_attribute" typescript:",notnull"`
+	NameAttribute      serpent.String                    `json:"name_attribute" typescript:",notnull"`
+	AllowSignups       serpent.Bool                      `json:"allow_signups" typescript:",notnull"`
+	GroupAttribute     serpent.String                    `json:"group_attribute" typescript:",notnull"`
+	GroupMapping       serpent.Struct[map[string]string] `json:"group_mapping" typescript:",notnull"`
+	GroupRegexFilter   serpent.Regexp                    `json:"group_regex_filter" typescript:",notnull"`
+	GroupAutoCreate    serpent.Bool                      `json:"group_auto_create" typescript:",notnull"`
+}
+
 type TelemetryConfig struct {
 	Enable serpent.Bool `json:"enable" typescript:",notnull"`
 	Trace  serpent.Bool `json:"trace" typescript:",notnull"`
@@ -598,11 +637,17 @@ type ProvisionerConfig struct {
 	DaemonPollJitter    serpent.Duration    `json:"daemon_poll_jitter" typescript:",notnull"`
 	ForceCancelInterval serpent.Duration    `json:"force_cancel_interval" typescript:",notnull"`
 	DaemonPSK           serpent.String      `json:"daemon_psk" typescript:",notnull"`
+	CacheMaxSize        serpent.Int64       `json:"cache_max_size" typescript:",notnull"`
+	ProviderMirror      serpent.String      `json:"provider_mirror" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
 	DisableAll serpent.Bool  `json:"disable_all" typescript:",notnull"`
 	API        serpent.Int64 `json:"api" typescript:",notnull"`
+	// WorkspaceAppMaxConnectionsPerUser and WorkspaceAppMaxBytesPerSecond
+	// are enforced by each replica and workspace proxy independently.
+	WorkspaceAppMaxConnectionsPerUser serpent.Int64 `json:"workspace_app_max_connections_per_user" typescript:",notnull"`
+	WorkspaceAppMaxBytesPerSecond     serpent.Int64 `json:"workspace_app_max_bytes_per_second" typescript:",notnull"`
 }
 
 type SwaggerConfig struct {
@@ -632,8 +677,9 @@ type UserQuietHoursScheduleConfig struct {
 
 // HealthcheckConfig contains configuration for healthchecks.
 type HealthcheckConfig struct {
-	Refresh           serpent.Duration `json:"refresh" typescript:",notnull"`
-	ThresholdDatabase serpent.Duration `json:"threshold_database" typescript:",notnull"`
+	Refresh             serpent.Duration `json:"refresh" typescript:",notnull"`
+	ThresholdDatabase   serpent.Duration `json:"threshold_database" typescript:",notnull"`
+	DERPHistoryInterval serpent.Duration `json:"derp_history_interval" typescript:",notnull"`
 }
 
 type NotificationsConfig struct {
@@ -843,6 +889,11 @@ func (c *DeploymentValues) Options() serpent.OptionSet {
 			Name: "OIDC",
 			YAML: "oidc",
 		}
+		deploymentGroupLDAP = serpent.Group{
+			Name:        "LDAP",
+			Description: "Allow users to log in with the credentials of an LDAP directory account.",
+			YAML:        "ldap",
+		}
 		deploymentGroupTelemetry = serpent.Group{
 			Name: "Telemetry",
 			YAML: "telemetry",
@@ -1622,6 +1673,28 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupOIDC,
 			YAML:        "userRoleDefault",
 		},
+		{
+			Name:        "OIDC Organization Field",
+			Description: "This field must be set if using the organization sync feature. Set this to the name of the claim used to store the organizations the user belongs to. The organizations should be sent as an array of strings. Users are added to and removed from organizations on every login, but are never removed from the default organization.",
+			Flag:        "oidc-organization-field",
+			Env:         "CODER_OIDC_ORGANIZATION_FIELD",
+			// This value is intentionally blank. If this is empty, then OIDC
+			// organization sync behavior is disabled.
+			Default: "",
+			Value:   &c.OIDC.OrganizationField,
+			Group:   &deploymentGroupOIDC,
+			YAML:    "organizationField",
+		},
+		{
+			Name:        "OIDC Organization Mapping",
+			Description: "A map of the OIDC passed in organization claim values and the organizations in Coder they should map to. Organizations can be referenced by ID or name. Claim values that are not mapped are matched against organization IDs and names directly.",
+			Flag:        "oidc-organization-mapping",
+			Env:         "CODER_OIDC_ORGANIZATION_MAPPING",
+			Default:     "{}",
+			Value:       &c.OIDC.OrganizationMapping,
+			Group:       &deploymentGroupOIDC,
+			YAML:        "organizationMapping",
+		},
 		{
 			Name:        "OpenID Connect sign in text",
 			Description: "The text to show on the OpenID Connect sign in button.",
@@ -1660,6 +1733,161 @@ when required by your organization's security policy.`,
 			Group: &deploymentGroupOIDC,
 			YAML:  "dangerousSkipIssuerChecks",
 		},
+		// LDAP settings.
+		{
+			Name:        "LDAP URL",
+			Description: "URL of the LDAP server, for example ldaps://ldap.example.com:636. Login with LDAP is enabled when this is set.",
+			Flag:        "ldap-url",
+			Env:         "CODER_LDAP_URL",
+			Value:       &c.LDAP.URL,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "url",
+		},
+		{
+			Name:        "LDAP StartTLS",
+			Description: "Upgrade ldap:// connections to TLS with StartTLS before binding.",
+			Flag:        "ldap-start-tls",
+			Env:         "CODER_LDAP_START_TLS",
+			Default:     "false",
+			Value:       &c.LDAP.StartTLS,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "startTLS",
+		},
+		{
+			Name:        "LDAP Insecure Skip Verify",
+			Description: "Skip verifying the certificate of the LDAP server. This is insecure and should only be used for testing.",
+			Flag:        "ldap-insecure-skip-verify",
+			Env:         "CODER_LDAP_INSECURE_SKIP_VERIFY",
+			Default:     "false",
+			Value:       &c.LDAP.InsecureSkipVerify,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "insecureSkipVerify",
+		},
+		{
+			Name:        "LDAP CA File",
+			Description: "PEM-encoded CA certificates used to verify the certificate of the LDAP server. The system roots are used if unset.",
+			Flag:        "ldap-ca-file",
+			Env:         "CODER_LDAP_CA_FILE",
+			Value:       &c.LDAP.CAFile,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "caFile",
+		},
+		{
+			Name:        "LDAP Bind DN",
+			Description: "DN of the service account used to search for users. Searches are anonymous if unset.",
+			Flag:        "ldap-bind-dn",
+			Env:         "CODER_LDAP_BIND_DN",
+			Value:       &c.LDAP.BindDN,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "bindDN",
+		},
+		{
+			Name:        "LDAP Bind Password",
+			Description: "Password of the service account used to search for users.",
+			Flag:        "ldap-bind-password",
+			Env:         "CODER_LDAP_BIND_PASSWORD",
+			Annotations: serpent.Annotations{}.Mark(annotationSecretKey, "true"),
+			Value:       &c.LDAP.BindPassword,
+			Group:       &deploymentGroupLDAP,
+		},
+		{
+			Name:        "LDAP Search Base DN",
+			Description: "DN of the subtree to search for users in.",
+			Flag:        "ldap-search-base-dn",
+			Env:         "CODER_LDAP_SEARCH_BASE_DN",
+			Value:       &c.LDAP.SearchBaseDN,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "searchBaseDN",
+		},
+		{
+			Name:        "LDAP Search Filter",
+			Description: "Filter used to find the entry of the user logging in. {username} is replaced with the escaped username. The filter must match exactly one entry.",
+			Flag:        "ldap-search-filter",
+			Env:         "CODER_LDAP_SEARCH_FILTER",
+			Default:     "(uid={username})",
+			Value:       &c.LDAP.SearchFilter,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "searchFilter",
+		},
+		{
+			Name:        "LDAP Username Attribute",
+			Description: "Attribute of the user entry to use as the Coder username.",
+			Flag:        "ldap-username-attribute",
+			Env:         "CODER_LDAP_USERNAME_ATTRIBUTE",
+			Default:     "uid",
+			Value:       &c.LDAP.UsernameAttribute,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "usernameAttribute",
+		},
+		{
+			Name:        "LDAP Email Attribute",
+			Description: "Attribute of the user entry to use as the email address.",
+			Flag:        "ldap-email-attribute",
+			Env:         "CODER_LDAP_EMAIL_ATTRIBUTE",
+			Default:     "mail",
+			Value:       &c.LDAP.EmailAttribute,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "emailAttribute",
+		},
+		{
+			Name:        "LDAP Name Attribute",
+			Description: "Attribute of the user entry to use as the display name.",
+			Flag:        "ldap-name-attribute",
+			Env:         "CODER_LDAP_NAME_ATTRIBUTE",
+			Default:     "cn",
+			Value:       &c.LDAP.NameAttribute,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "nameAttribute",
+		},
+		{
+			Name:        "LDAP Allow Signups",
+			Description: "Whether new users can sign up with LDAP.",
+			Flag:        "ldap-allow-signups",
+			Env:         "CODER_LDAP_ALLOW_SIGNUPS",
+			Default:     "true",
+			Value:       &c.LDAP.AllowSignups,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "allowSignups",
+		},
+		{
+			Name:        "LDAP Group Attribute",
+			Description: "Attribute of the user entry that lists the groups of the user, such as memberOf. Group sync is disabled if unset. Values that are DNs are reduced to the value of their first RDN.",
+			Flag:        "ldap-group-attribute",
+			Env:         "CODER_LDAP_GROUP_ATTRIBUTE",
+			Value:       &c.LDAP.GroupAttribute,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "groupAttribute",
+		},
+		{
+			Name:        "LDAP Group Mapping",
+			Description: "A map of LDAP group names and the group in Coder it should map to.",
+			Flag:        "ldap-group-mapping",
+			Env:         "CODER_LDAP_GROUP_MAPPING",
+			Default:     "{}",
+			Value:       &c.LDAP.GroupMapping,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "groupMapping",
+		},
+		{
+			Name:        "LDAP Regex Group Filter",
+			Description: "If provided any group name not matching the regex is ignored. This filter is applied after the group mapping.",
+			Flag:        "ldap-group-regex-filter",
+			Env:         "CODER_LDAP_GROUP_REGEX_FILTER",
+			Default:     ".*",
+			Value:       &c.LDAP.GroupRegexFilter,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "groupRegexFilter",
+		},
+		{
+			Name:        "Enable LDAP Group Auto Create",
+			Description: "Automatically creates missing groups from the groups of a user.",
+			Flag:        "ldap-group-auto-create",
+			Env:         "CODER_LDAP_GROUP_AUTO_CREATE",
+			Default:     "false",
+			Value:       &c.LDAP.GroupAutoCreate,
+			Group:       &deploymentGroupLDAP,
+			YAML:        "enableGroupAutoCreate",
+		},
 		// Telemetry settings
 		{
 			Name:        "Telemetry Enable",
@@ -1743,7 +1971,7 @@ when required by your organization's security policy.`,
 			Name: "Provisioner Daemon Types",
 			Description: fmt.Sprintf("The supported job types for the built-in provisioners. By default, this is only the terraform type. Supported types: %s.",
 				strings.Join([]string{
-					string(ProvisionerTypeTerraform), string(ProvisionerTypeEcho),
+					string(ProvisionerTypeTerraform), string(ProvisionerTypeTofu), string(ProvisionerTypeEcho),
 				}, ",")),
 			Flag:    "provisioner-types",
 			Env:     "CODER_PROVISIONER_TYPES",
@@ -1807,6 +2035,25 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupProvisioning,
 			Annotations: serpent.Annotations{}.Mark(annotationSecretKey, "true"),
 		},
+		{
+			Name:        "Provisioner Cache Max Size",
+			Description: "Maximum size in bytes of the Terraform provider and module cache of each built-in provisioner daemon. The least recently used providers and modules are evicted once it is exceeded. Negative values disable eviction.",
+			Flag:        "provisioner-cache-max-size",
+			Env:         "CODER_PROVISIONER_CACHE_MAX_SIZE",
+			Default:     strconv.FormatInt(10<<30, 10),
+			Value:       &c.Provisioner.CacheMaxSize,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "cacheMaxSize",
+		},
+		{
+			Name:        "Provisioner Provider Mirror",
+			Description: "URL of a Terraform provider network mirror. When set, the built-in provisioner daemons install all providers from the mirror instead of their origin registries. The mirror must be served over HTTPS.",
+			Flag:        "provisioner-provider-mirror",
+			Env:         "CODER_PROVISIONER_PROVIDER_MIRROR",
+			Value:       &c.Provisioner.ProviderMirror,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "providerMirror",
+		},
 		// RateLimit settings
 		{
 			Name:        "Disable All Rate Limits",
@@ -1830,6 +2077,26 @@ when required by your organization's security policy.`,
 			Hidden:      true,
 			Annotations: serpent.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "Workspace App Max Connections Per User",
+			Description: "Maximum number of concurrent workspace app connections per user on each replica or workspace proxy. Templates may set a lower limit. Zero means no limit.",
+			Flag:        "workspace-app-max-connections-per-user",
+			Env:         "CODER_WORKSPACE_APP_MAX_CONNECTIONS_PER_USER",
+			Default:     "0",
+			Value:       &c.RateLimit.WorkspaceAppMaxConnectionsPerUser,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppMaxConnectionsPerUser",
+		},
+		{
+			Name:        "Workspace App Max Bytes Per Second",
+			Description: "Maximum throughput of each workspace app in bytes per second on each replica or workspace proxy. Templates may set a lower limit. Zero means no limit.",
+			Flag:        "workspace-app-max-bytes-per-second",
+			Env:         "CODER_WORKSPACE_APP_MAX_BYTES_PER_SECOND",
+			Default:     "0",
+			Value:       &c.RateLimit.WorkspaceAppMaxBytesPerSecond,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "workspaceAppMaxBytesPerSecond",
+		},
 		// Logging settings
 		{
 			Name:          "Verbose",
@@ -2175,6 +2442,16 @@ when required by your organization's security policy.`,
 			Group: &deploymentGroupNetworkingHTTP,
 			YAML:  "disablePasswordAuth",
 		},
+		{
+			Name:        "Require Owner MFA",
+			Description: "Require users with the owner role to log in with a time-based one-time password (TOTP) when using password authentication. Owners that have not enrolled an authenticator app will be asked to do so on their next login.",
+			Flag:        "require-owner-mfa",
+			Env:         "CODER_REQUIRE_OWNER_MFA",
+
+			Value: &c.RequireOwnerMFA,
+			Group: &deploymentGroupNetworkingHTTP,
+			YAML:  "requireOwnerMFA",
+		},
 		{
 			Name:          "Config Path",
 			Description:   `Specify a YAML file to load configuration from.`,
@@ -2329,6 +2606,17 @@ Write out the current server config as YAML to stdout.`,
 			YAML:        "thresholdDatabase",
 			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
 		},
+		{
+			Name:        "Health Check DERP History Interval",
+			Description: "How often each replica checks the health of every DERP node and records the results, so trends in latency and packet loss can be inspected. Results are kept for 7 days. Disabled when 0, a value such as 5m is a good starting point.",
+			Flag:        "health-check-derp-history-interval",
+			Env:         "CODER_HEALTH_CHECK_DERP_HISTORY_INTERVAL",
+			Default:     "0",
+			Value:       &c.Healthcheck.DERPHistoryInterval,
+			Group:       &deploymentGroupIntrospectionHealthcheck,
+			YAML:        "derpHistoryInterval",
+			Annotations: serpent.Annotations{}.Mark(annotationFormatDuration, "true"),
+		},
 		// Notifications Options
 		{
 			Name:        "Notifications: Method",
@@ -16,6 +16,7 @@ type GroupSource string
 const (
 	GroupSourceUser GroupSource = "user"
 	GroupSourceOIDC GroupSource = "oidc"
+	GroupSourceSCIM GroupSource = "scim"
 )
 
 type CreateGroupRequest struct {
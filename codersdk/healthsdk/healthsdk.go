@@ -4,9 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 	"tailscale.com/derp"
 	"tailscale.com/net/netcheck"
@@ -96,6 +98,66 @@ func (c *HealthClient) PutHealthSettings(ctx context.Context, settings HealthSet
 	return nil
 }
 
+type DERPHealthHistoryRequest struct {
+	// Since is the earliest result to include. Zero means the last 24 hours.
+	Since time.Time
+	// RegionID limits the results to a single region. Zero means all regions.
+	RegionID int
+}
+
+func (r DERPHealthHistoryRequest) asRequestOption() codersdk.RequestOption {
+	return func(req *http.Request) {
+		q := req.URL.Query()
+		if !r.Since.IsZero() {
+			q.Set("since", r.Since.UTC().Format(time.RFC3339Nano))
+		}
+		if r.RegionID != 0 {
+			q.Set("region_id", strconv.Itoa(r.RegionID))
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+}
+
+// DERPHealthHistory contains the recorded DERP health results of every node,
+// as seen from every replica.
+type DERPHealthHistory struct {
+	Nodes []DERPNodeHealthHistory `json:"nodes"`
+}
+
+// DERPNodeHealthHistory contains the results for a single DERP node as seen
+// from a single replica, oldest first.
+type DERPNodeHealthHistory struct {
+	ReplicaID  uuid.UUID              `json:"replica_id" format:"uuid"`
+	RegionID   int                    `json:"region_id"`
+	RegionCode string                 `json:"region_code"`
+	NodeName   string                 `json:"node_name"`
+	Results    []DERPNodeHealthResult `json:"results"`
+}
+
+type DERPNodeHealthResult struct {
+	CreatedAt           time.Time `json:"created_at" format:"date-time"`
+	Healthy             bool      `json:"healthy"`
+	CanExchangeMessages bool      `json:"can_exchange_messages"`
+	RoundTripPingMs     int       `json:"round_trip_ping_ms"`
+	PacketLoss          float64   `json:"packet_loss"`
+	STUNEnabled         bool      `json:"stun_enabled"`
+	CanSTUN             bool      `json:"can_stun"`
+	Error               string    `json:"error,omitempty"`
+}
+
+func (c *HealthClient) DERPHealthHistory(ctx context.Context, req DERPHealthHistoryRequest) (DERPHealthHistory, error) {
+	res, err := c.client.Request(ctx, http.MethodGet, "/api/v2/debug/health/derp/history", nil, req.asRequestOption())
+	if err != nil {
+		return DERPHealthHistory{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return DERPHealthHistory{}, codersdk.ReadBodyAsError(res)
+	}
+	var history DERPHealthHistory
+	return history, json.NewDecoder(res.Body).Decode(&history)
+}
+
 // HealthcheckReport contains information about the health status of a Coder deployment.
 type HealthcheckReport struct {
 	// Time is the time the report was generated at.
@@ -215,6 +277,7 @@ type DERPNodeReport struct {
 	CanExchangeMessages bool                   `json:"can_exchange_messages"`
 	RoundTripPing       string                 `json:"round_trip_ping"`
 	RoundTripPingMs     int                    `json:"round_trip_ping_ms"`
+	PacketLoss          float64                `json:"packet_loss"`
 	UsesWebsocket       bool                   `json:"uses_websocket"`
 	ClientLogs          [][]string             `json:"client_logs"`
 	ClientErrs          [][]string             `json:"client_errs"`
@@ -270,6 +333,32 @@ type WorkspaceProxyReport struct {
 
 // @typescript-ignore ClientNetcheckReport
 type ClientNetcheckReport struct {
-	DERP       DERPHealthReport `json:"derp"`
-	Interfaces InterfacesReport `json:"interfaces"`
+	DERP       DERPHealthReport  `json:"derp"`
+	Interfaces InterfacesReport  `json:"interfaces"`
+	Proxy      ClientProxyReport `json:"proxy"`
+}
+
+// ClientProxyReport shows which region's DERP relay the CLI prefers when
+// connecting to workspaces, and the latency measured to each region.
+// @typescript-ignore ClientProxyReport
+type ClientProxyReport struct {
+	// Selected is the name of the preferred region. Empty if no region is
+	// preferred.
+	Selected string `json:"selected"`
+	// Source is how the region was chosen: "flag" or "latency".
+	Source string `json:"source"`
+	// DERPRegionID is the ID of the selected region's relay in the DERP map.
+	// Zero if the region does not serve DERP.
+	DERPRegionID int                       `json:"derp_region_id"`
+	Regions      []ClientProxyRegionReport `json:"regions"`
+}
+
+// @typescript-ignore ClientProxyRegionReport
+type ClientProxyRegionReport struct {
+	Name        string  `json:"name"`
+	DisplayName string  `json:"display_name"`
+	Healthy     bool    `json:"healthy"`
+	Latency     string  `json:"latency"`
+	LatencyMS   int64   `json:"latency_ms"`
+	Error       *string `json:"error"`
 }
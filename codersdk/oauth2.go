@@ -14,6 +14,12 @@ type OAuth2ProviderApp struct {
 	Name        string    `json:"name"`
 	CallbackURL string    `json:"callback_url"`
 	Icon        string    `json:"icon"`
+	// Public apps cannot keep a client secret confidential, so they must use
+	// PKCE to exchange authorization codes instead of a secret.
+	Public bool `json:"public"`
+	// ClientCredentialsUserID is the user that tokens issued through the
+	// client_credentials grant act as. The grant is disabled when unset.
+	ClientCredentialsUserID *uuid.UUID `json:"client_credentials_user_id,omitempty" format:"uuid"`
 
 	// Endpoints are included in the app response for easier discovery. The OAuth2
 	// spec does not have a defined place to find these (for comparison, OIDC has
@@ -70,9 +76,11 @@ func (c *Client) OAuth2ProviderApp(ctx context.Context, id uuid.UUID) (OAuth2Pro
 }
 
 type PostOAuth2ProviderAppRequest struct {
-	Name        string `json:"name" validate:"required,oauth2_app_name"`
-	CallbackURL string `json:"callback_url" validate:"required,http_url"`
-	Icon        string `json:"icon" validate:"omitempty"`
+	Name                    string     `json:"name" validate:"required,oauth2_app_name"`
+	CallbackURL             string     `json:"callback_url" validate:"required,http_url"`
+	Icon                    string     `json:"icon" validate:"omitempty"`
+	Public                  bool       `json:"public,omitempty"`
+	ClientCredentialsUserID *uuid.UUID `json:"client_credentials_user_id,omitempty" format:"uuid"`
 }
 
 // PostOAuth2ProviderApp adds an application that can authenticate using Coder
@@ -91,9 +99,11 @@ func (c *Client) PostOAuth2ProviderApp(ctx context.Context, app PostOAuth2Provid
 }
 
 type PutOAuth2ProviderAppRequest struct {
-	Name        string `json:"name" validate:"required,oauth2_app_name"`
-	CallbackURL string `json:"callback_url" validate:"required,http_url"`
-	Icon        string `json:"icon" validate:"omitempty"`
+	Name                    string     `json:"name" validate:"required,oauth2_app_name"`
+	CallbackURL             string     `json:"callback_url" validate:"required,http_url"`
+	Icon                    string     `json:"icon" validate:"omitempty"`
+	Public                  bool       `json:"public,omitempty"`
+	ClientCredentialsUserID *uuid.UUID `json:"client_credentials_user_id,omitempty" format:"uuid"`
 }
 
 // PutOAuth2ProviderApp updates an application that can authenticate using Coder
@@ -185,11 +195,31 @@ type OAuth2ProviderGrantType string
 const (
 	OAuth2ProviderGrantTypeAuthorizationCode OAuth2ProviderGrantType = "authorization_code"
 	OAuth2ProviderGrantTypeRefreshToken      OAuth2ProviderGrantType = "refresh_token"
+	OAuth2ProviderGrantTypeClientCredentials OAuth2ProviderGrantType = "client_credentials"
+	OAuth2ProviderGrantTypeDeviceCode        OAuth2ProviderGrantType = "urn:ietf:params:oauth:grant-type:device_code"
 )
 
 func (e OAuth2ProviderGrantType) Valid() bool {
 	switch e {
-	case OAuth2ProviderGrantTypeAuthorizationCode, OAuth2ProviderGrantTypeRefreshToken:
+	case OAuth2ProviderGrantTypeAuthorizationCode, OAuth2ProviderGrantTypeRefreshToken,
+		OAuth2ProviderGrantTypeClientCredentials, OAuth2ProviderGrantTypeDeviceCode:
+		return true
+	}
+	return false
+}
+
+type OAuth2ProviderCodeChallengeMethod string
+
+const (
+	// OAuth2ProviderCodeChallengeMethodS256 is the only supported PKCE method.
+	// The "plain" method offers no protection if the challenge is intercepted.
+	OAuth2ProviderCodeChallengeMethodS256 OAuth2ProviderCodeChallengeMethod = "S256"
+)
+
+func (e OAuth2ProviderCodeChallengeMethod) Valid() bool {
+	//nolint:gocritic,revive // More cases might be added later.
+	switch e {
+	case OAuth2ProviderCodeChallengeMethodS256:
 		return true
 	}
 	return false
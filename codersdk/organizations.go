@@ -26,13 +26,14 @@ type ProvisionerType string
 const (
 	ProvisionerTypeEcho      ProvisionerType = "echo"
 	ProvisionerTypeTerraform ProvisionerType = "terraform"
+	ProvisionerTypeTofu      ProvisionerType = "tofu"
 )
 
 // ProvisionerTypeValid accepts string or ProvisionerType for easier usage.
 // Will validate the enum is in the set.
 func ProvisionerTypeValid[T ProvisionerType | string](pt T) error {
 	switch string(pt) {
-	case string(ProvisionerTypeEcho), string(ProvisionerTypeTerraform):
+	case string(ProvisionerTypeEcho), string(ProvisionerTypeTerraform), string(ProvisionerTypeTofu):
 		return nil
 	default:
 		return xerrors.Errorf("provisioner type '%s' is not supported", pt)
@@ -53,6 +54,12 @@ type Organization struct {
 	CreatedAt           time.Time `table:"created at" json:"created_at" validate:"required" format:"date-time"`
 	UpdatedAt           time.Time `table:"updated at" json:"updated_at" validate:"required" format:"date-time"`
 	IsDefault           bool      `table:"default" json:"is_default" validate:"required"`
+	// AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs restrict which
+	// workspace proxies may serve apps for workspaces in this organization, and
+	// which DERP regions their agents may relay through. Empty lists allow all
+	// proxies and regions.
+	AllowedWorkspaceProxyIDs []uuid.UUID `table:"allowed workspace proxies" json:"allowed_workspace_proxy_ids" format:"uuid"`
+	AllowedDERPRegionIDs     []int       `table:"allowed derp regions" json:"allowed_derp_region_ids"`
 }
 
 func (o Organization) HumanName() string {
@@ -92,6 +99,11 @@ type UpdateOrganizationRequest struct {
 	DisplayName string  `json:"display_name,omitempty" validate:"omitempty,organization_display_name"`
 	Description *string `json:"description,omitempty"`
 	Icon        *string `json:"icon,omitempty"`
+	// AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs are enterprise-only. A
+	// nil value leaves the current restriction unchanged, and an empty list
+	// removes it.
+	AllowedWorkspaceProxyIDs *[]uuid.UUID `json:"allowed_workspace_proxy_ids,omitempty" format:"uuid"`
+	AllowedDERPRegionIDs     *[]int       `json:"allowed_derp_region_ids,omitempty"`
 }
 
 // CreateTemplateVersionRequest enables callers to create a new Template Version.
@@ -103,7 +115,7 @@ type CreateTemplateVersionRequest struct {
 	StorageMethod   ProvisionerStorageMethod `json:"storage_method" validate:"oneof=file,required" enums:"file"`
 	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without=ExampleID" format:"uuid"`
 	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without=FileID"`
-	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform echo,required"`
+	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform echo tofu,required"`
 	ProvisionerTags map[string]string        `json:"tags"`
 
 	UserVariableValues []VariableValue `json:"user_variable_values,omitempty"`
@@ -109,6 +109,27 @@ type ProvisionerJobLog struct {
 	Output    string    `json:"output"`
 }
 
+type ProvisionerTimingStage string
+
+const (
+	ProvisionerTimingStageInit  ProvisionerTimingStage = "init"
+	ProvisionerTimingStagePlan  ProvisionerTimingStage = "plan"
+	ProvisionerTimingStageGraph ProvisionerTimingStage = "graph"
+	ProvisionerTimingStageApply ProvisionerTimingStage = "apply"
+)
+
+// ProvisionerTiming is the time the provisioner spent on a single resource,
+// or on a whole stage when Resource is empty.
+type ProvisionerTiming struct {
+	JobID     uuid.UUID              `json:"job_id" format:"uuid"`
+	StartedAt time.Time              `json:"started_at" format:"date-time"`
+	EndedAt   time.Time              `json:"ended_at" format:"date-time"`
+	Stage     ProvisionerTimingStage `json:"stage" enums:"init,plan,graph,apply"`
+	Source    string                 `json:"source"`
+	Action    string                 `json:"action"`
+	Resource  string                 `json:"resource"`
+}
+
 // provisionerJobLogsAfter streams logs that occurred after a specific time.
 func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after int64) (<-chan ProvisionerJobLog, io.Closer, error) {
 	afterQuery := ""
@@ -0,0 +1,151 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type RoleGrantStatus string
+
+const (
+	RoleGrantStatusPending  RoleGrantStatus = "pending"
+	RoleGrantStatusApproved RoleGrantStatus = "approved"
+	RoleGrantStatusDenied   RoleGrantStatus = "denied"
+	RoleGrantStatusExpired  RoleGrantStatus = "expired"
+	RoleGrantStatusRevoked  RoleGrantStatus = "revoked"
+)
+
+// RoleGrantRequest is a request from a user to hold a role for a limited
+// time. Once approved, the role is assigned to the user until ExpiresAt, when
+// it is removed automatically.
+type RoleGrantRequest struct {
+	ID     uuid.UUID `json:"id" format:"uuid"`
+	UserID uuid.UUID `json:"user_id" format:"uuid"`
+	// Role is the requested role. Organization roles include the organization
+	// ID.
+	Role   SlimRole `json:"role"`
+	Reason string   `json:"reason"`
+	// LifetimeSeconds is how long the role is held once approved.
+	LifetimeSeconds int64           `json:"lifetime_seconds"`
+	Status          RoleGrantStatus `json:"status" enums:"pending,approved,denied,expired,revoked"`
+	CreatedAt       time.Time       `json:"created_at" format:"date-time"`
+	ReviewedBy      *uuid.UUID      `json:"reviewed_by,omitempty" format:"uuid"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty" format:"date-time"`
+	ExpiresAt       *time.Time      `json:"expires_at,omitempty" format:"date-time"`
+}
+
+// CreateRoleGrantRequest requests a role for a limited time. Leave
+// OrganizationID empty to request a site wide role.
+type CreateRoleGrantRequest struct {
+	RoleName       string        `json:"role_name" validate:"required"`
+	OrganizationID uuid.UUID     `json:"organization_id,omitempty" format:"uuid"`
+	Reason         string        `json:"reason" validate:"required"`
+	Lifetime       time.Duration `json:"lifetime" validate:"required"`
+}
+
+// RoleGrantRequestsFilter filters the role grant requests an approver can
+// review.
+type RoleGrantRequestsFilter struct {
+	OrganizationID uuid.UUID       `json:"organization_id,omitempty" format:"uuid"`
+	Status         RoleGrantStatus `json:"status,omitempty"`
+}
+
+// CreateRoleGrantRequest requests a role on behalf of the user.
+func (c *Client) CreateRoleGrantRequest(ctx context.Context, user string, req CreateRoleGrantRequest) (RoleGrantRequest, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/role-grants", user), req)
+	if err != nil {
+		return RoleGrantRequest{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return RoleGrantRequest{}, ReadBodyAsError(res)
+	}
+	var grant RoleGrantRequest
+	return grant, json.NewDecoder(res.Body).Decode(&grant)
+}
+
+// UserRoleGrantRequests returns the role grant requests made by the user.
+func (c *Client) UserRoleGrantRequests(ctx context.Context, user string) ([]RoleGrantRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/role-grants", user), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var grants []RoleGrantRequest
+	return grants, json.NewDecoder(res.Body).Decode(&grants)
+}
+
+// RoleGrantRequests returns the role grant requests the caller can review.
+func (c *Client) RoleGrantRequests(ctx context.Context, filter RoleGrantRequestsFilter) ([]RoleGrantRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/role-grants", nil, func(r *http.Request) {
+		q := r.URL.Query()
+		if filter.OrganizationID != uuid.Nil {
+			q.Set("organization_id", filter.OrganizationID.String())
+		}
+		if filter.Status != "" {
+			q.Set("status", string(filter.Status))
+		}
+		r.URL.RawQuery = q.Encode()
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var grants []RoleGrantRequest
+	return grants, json.NewDecoder(res.Body).Decode(&grants)
+}
+
+// RoleGrantRequest returns a single role grant request.
+func (c *Client) RoleGrantRequest(ctx context.Context, id uuid.UUID) (RoleGrantRequest, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/role-grants/%s", id), nil)
+	if err != nil {
+		return RoleGrantRequest{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return RoleGrantRequest{}, ReadBodyAsError(res)
+	}
+	var grant RoleGrantRequest
+	return grant, json.NewDecoder(res.Body).Decode(&grant)
+}
+
+// ApproveRoleGrantRequest grants the requested role until the lifetime of the
+// request has passed.
+func (c *Client) ApproveRoleGrantRequest(ctx context.Context, id uuid.UUID) (RoleGrantRequest, error) {
+	return c.reviewRoleGrantRequest(ctx, id, "approve")
+}
+
+// DenyRoleGrantRequest denies a pending role grant request.
+func (c *Client) DenyRoleGrantRequest(ctx context.Context, id uuid.UUID) (RoleGrantRequest, error) {
+	return c.reviewRoleGrantRequest(ctx, id, "deny")
+}
+
+// RevokeRoleGrantRequest removes an approved role before it expires.
+func (c *Client) RevokeRoleGrantRequest(ctx context.Context, id uuid.UUID) (RoleGrantRequest, error) {
+	return c.reviewRoleGrantRequest(ctx, id, "revoke")
+}
+
+func (c *Client) reviewRoleGrantRequest(ctx context.Context, id uuid.UUID, action string) (RoleGrantRequest, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/role-grants/%s/%s", id, action), nil)
+	if err != nil {
+		return RoleGrantRequest{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return RoleGrantRequest{}, ReadBodyAsError(res)
+	}
+	var grant RoleGrantRequest
+	return grant, json.NewDecoder(res.Body).Decode(&grant)
+}
@@ -0,0 +1,65 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Session is an API key created by logging in through the browser or the CLI,
+// as opposed to a token created explicitly. IPAddress, UserAgent and LastUsed
+// are refreshed at most once an hour while the session is in use.
+type Session struct {
+	ID        string    `json:"id" validate:"required"`
+	LoginType LoginType `json:"login_type" validate:"required" enums:"password,github,oidc,ldap"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at" validate:"required" format:"date-time"`
+	LastUsed  time.Time `json:"last_used" validate:"required" format:"date-time"`
+	ExpiresAt time.Time `json:"expires_at" validate:"required" format:"date-time"`
+	// Current is true for the session that made the request.
+	Current bool `json:"current"`
+}
+
+// UserSessions lists the active login sessions of a user.
+func (c *Client) UserSessions(ctx context.Context, user string) ([]Session, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/sessions", user), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var sessions []Session
+	return sessions, json.NewDecoder(res.Body).Decode(&sessions)
+}
+
+// RevokeUserSession logs a single session of a user out.
+func (c *Client) RevokeUserSession(ctx context.Context, user string, id string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/sessions/%s", user, id), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// RevokeUserSessions logs every session of a user out, except for the session
+// making the request.
+func (c *Client) RevokeUserSessions(ctx context.Context, user string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/sessions", user), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -24,7 +24,7 @@ type Template struct {
 	OrganizationIcon        string          `json:"organization_icon"`
 	Name                    string          `json:"name"`
 	DisplayName             string          `json:"display_name"`
-	Provisioner             ProvisionerType `json:"provisioner" enums:"terraform"`
+	Provisioner             ProvisionerType `json:"provisioner" enums:"terraform,tofu"`
 	ActiveVersionID         uuid.UUID       `json:"active_version_id" format:"uuid"`
 	// ActiveUserCount is set to -1 when loading.
 	ActiveUserCount    int                    `json:"active_user_count"`
@@ -61,6 +61,33 @@ type Template struct {
 	// template version.
 	RequireActiveVersion bool                         `json:"require_active_version"`
 	MaxPortShareLevel    WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
+
+	// AllowedWorkspaceProxyIDs and AllowedDERPRegionIDs are enterprise-only.
+	// They restrict which workspace proxies may serve apps for workspaces of
+	// this template, and which DERP regions their agents may relay through.
+	// The primary proxy is identified by the deployment ID. Empty lists allow
+	// all proxies and regions.
+	AllowedWorkspaceProxyIDs []uuid.UUID `json:"allowed_workspace_proxy_ids" format:"uuid"`
+	AllowedDERPRegionIDs     []int       `json:"allowed_derp_region_ids"`
+
+	// MaxAppConnectionsPerUser and MaxAppBytesPerSecond limit workspace app
+	// traffic on each replica and workspace proxy. The stricter of these and
+	// the deployment limits applies. Zero means the deployment limit applies.
+	MaxAppConnectionsPerUser int64 `json:"max_app_connections_per_user"`
+	MaxAppBytesPerSecond     int64 `json:"max_app_bytes_per_second"`
+
+	// DriftDetectionIntervalMillis is how often running workspaces are
+	// checked for resources that changed outside of Coder. Zero disables
+	// drift detection.
+	DriftDetectionIntervalMillis int64 `json:"drift_detection_interval_ms"`
+
+	// BuildTimeoutMillis is the maximum duration of a workspace build, after
+	// which it is canceled. Zero means no limit.
+	BuildTimeoutMillis int64 `json:"build_timeout_ms"`
+	// BuildCancelGracePeriodMillis is how long a canceled workspace build may
+	// take to exit gracefully before it is failed. Zero means the provisioner
+	// daemon default applies.
+	BuildCancelGracePeriodMillis int64 `json:"build_cancel_grace_period_ms"`
 }
 
 // WeekdaysToBitmap converts a list of weekdays to a bitmap in accordance with
@@ -250,6 +277,33 @@ type UpdateTemplateMeta struct {
 	// of the template.
 	DisableEveryoneGroupAccess bool                          `json:"disable_everyone_group_access"`
 	MaxPortShareLevel          *WorkspaceAgentPortShareLevel `json:"max_port_share_level"`
+	// AllowedWorkspaceProxyIDs restricts which workspace proxies may serve
+	// apps for workspaces of this template. A nil value leaves the current
+	// restriction unchanged, and an empty list removes it.
+	AllowedWorkspaceProxyIDs *[]uuid.UUID `json:"allowed_workspace_proxy_ids,omitempty" format:"uuid"`
+	// AllowedDERPRegionIDs restricts which DERP regions agents of this
+	// template may relay through. A nil value leaves the current restriction
+	// unchanged, and an empty list removes it.
+	AllowedDERPRegionIDs *[]int `json:"allowed_derp_region_ids,omitempty"`
+	// MaxAppConnectionsPerUser limits concurrent workspace app connections
+	// per user. A nil value leaves the current limit unchanged, and zero
+	// removes it.
+	MaxAppConnectionsPerUser *int64 `json:"max_app_connections_per_user,omitempty"`
+	// MaxAppBytesPerSecond limits the throughput of each workspace app. A nil
+	// value leaves the current limit unchanged, and zero removes it.
+	MaxAppBytesPerSecond *int64 `json:"max_app_bytes_per_second,omitempty"`
+	// DriftDetectionIntervalMillis sets how often running workspaces are
+	// checked for drift. It must be at least an hour. A nil value leaves the
+	// current interval unchanged, and zero disables drift detection.
+	DriftDetectionIntervalMillis *int64 `json:"drift_detection_interval_ms,omitempty"`
+	// BuildTimeoutMillis sets the maximum duration of a workspace build. It
+	// must be at least a minute. A nil value leaves the current timeout
+	// unchanged, and zero removes it.
+	BuildTimeoutMillis *int64 `json:"build_timeout_ms,omitempty"`
+	// BuildCancelGracePeriodMillis sets how long a canceled workspace build
+	// may take to exit gracefully. A nil value leaves the current grace
+	// period unchanged, and zero restores the provisioner daemon default.
+	BuildCancelGracePeriodMillis *int64 `json:"build_cancel_grace_period_ms,omitempty"`
 }
 
 type TemplateExample struct {
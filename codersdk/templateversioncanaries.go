@@ -0,0 +1,109 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+type TemplateVersionCanaryStatus string
+
+const (
+	TemplateVersionCanaryStatusActive     TemplateVersionCanaryStatus = "active"
+	TemplateVersionCanaryStatusPromoted   TemplateVersionCanaryStatus = "promoted"
+	TemplateVersionCanaryStatusRolledBack TemplateVersionCanaryStatus = "rolled_back"
+)
+
+// TemplateVersionCanary is a staged rollout of a template version. While it is
+// active, workspaces in its cohort are built with the canary version instead
+// of the active version of the template.
+type TemplateVersionCanary struct {
+	ID                uuid.UUID `json:"id" format:"uuid"`
+	TemplateID        uuid.UUID `json:"template_id" format:"uuid"`
+	TemplateVersionID uuid.UUID `json:"template_version_id" format:"uuid"`
+	// Percent is the percentage of the template's workspaces that are part of
+	// the cohort.
+	Percent int32 `json:"percent"`
+	// GroupID is a group whose members' workspaces are always part of the
+	// cohort.
+	GroupID *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	// MinBuilds is the number of finished builds of the canary version needed
+	// before it is promoted or rolled back.
+	MinBuilds int32 `json:"min_builds"`
+	// SuccessThreshold is the percentage of builds that must succeed for the
+	// canary version to be promoted.
+	SuccessThreshold int32                       `json:"success_threshold"`
+	Status           TemplateVersionCanaryStatus `json:"status" enums:"active,promoted,rolled_back"`
+	StatusReason     string                      `json:"status_reason"`
+	SucceededBuilds  int64                       `json:"succeeded_builds"`
+	FailedBuilds     int64                       `json:"failed_builds"`
+	CreatedBy        uuid.UUID                   `json:"created_by" format:"uuid"`
+	CreatedAt        time.Time                   `json:"created_at" format:"date-time"`
+	UpdatedAt        time.Time                   `json:"updated_at" format:"date-time"`
+	CompletedAt      *time.Time                  `json:"completed_at,omitempty" format:"date-time"`
+}
+
+// CreateTemplateVersionCanaryRequest starts a canary rollout of a template
+// version. A workspace is part of the cohort if its owner is a member of the
+// group, or if it falls within the percentage of workspaces.
+type CreateTemplateVersionCanaryRequest struct {
+	TemplateVersionID uuid.UUID  `json:"template_version_id" validate:"required" format:"uuid"`
+	Percent           int32      `json:"percent"`
+	GroupID           *uuid.UUID `json:"group_id,omitempty" format:"uuid"`
+	MinBuilds         int32      `json:"min_builds" validate:"required"`
+	SuccessThreshold  int32      `json:"success_threshold"`
+}
+
+// UpdateTemplateVersionCanaryRequest ends the active canary of a template
+// before the evaluator does.
+type UpdateTemplateVersionCanaryRequest struct {
+	Status TemplateVersionCanaryStatus `json:"status" validate:"required,oneof=promoted rolled_back" enums:"promoted,rolled_back"`
+}
+
+// TemplateVersionCanary returns the most recent canary of a template.
+func (c *Client) TemplateVersionCanary(ctx context.Context, template uuid.UUID) (TemplateVersionCanary, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/canary", template), nil)
+	if err != nil {
+		return TemplateVersionCanary{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionCanary{}, ReadBodyAsError(res)
+	}
+	var canary TemplateVersionCanary
+	return canary, json.NewDecoder(res.Body).Decode(&canary)
+}
+
+// CreateTemplateVersionCanary starts a canary rollout of a template version.
+func (c *Client) CreateTemplateVersionCanary(ctx context.Context, template uuid.UUID, req CreateTemplateVersionCanaryRequest) (TemplateVersionCanary, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/canary", template), req)
+	if err != nil {
+		return TemplateVersionCanary{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TemplateVersionCanary{}, ReadBodyAsError(res)
+	}
+	var canary TemplateVersionCanary
+	return canary, json.NewDecoder(res.Body).Decode(&canary)
+}
+
+// UpdateTemplateVersionCanary promotes or rolls back the active canary of a
+// template.
+func (c *Client) UpdateTemplateVersionCanary(ctx context.Context, template uuid.UUID, req UpdateTemplateVersionCanaryRequest) (TemplateVersionCanary, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s/canary", template), req)
+	if err != nil {
+		return TemplateVersionCanary{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionCanary{}, ReadBodyAsError(res)
+	}
+	var canary TemplateVersionCanary
+	return canary, json.NewDecoder(res.Body).Decode(&canary)
+}
@@ -164,6 +164,21 @@ func (c *Client) TemplateVersionResources(ctx context.Context, version uuid.UUID
 	return resources, json.NewDecoder(res.Body).Decode(&resources)
 }
 
+// TemplateVersionTimings returns the time the provisioner spent on each
+// resource when importing the template version.
+func (c *Client) TemplateVersionTimings(ctx context.Context, version uuid.UUID) ([]ProvisionerTiming, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/timings", version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var timings []ProvisionerTiming
+	return timings, json.NewDecoder(res.Body).Decode(&timings)
+}
+
 // TemplateVersionVariables returns resources a template version variables.
 func (c *Client) TemplateVersionVariables(ctx context.Context, version uuid.UUID) ([]TemplateVersionVariable, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/variables", version), nil)
@@ -65,6 +65,9 @@ type User struct {
 
 	OrganizationIDs []uuid.UUID `json:"organization_ids" format:"uuid"`
 	Roles           []SlimRole  `json:"roles"`
+	// IsServiceAccount is true for non-human users that belong to a single
+	// organization and can only authenticate with API tokens.
+	IsServiceAccount bool `json:"is_service_account" table:"service account"`
 }
 
 type GetUsersResponse struct {
@@ -125,6 +128,11 @@ type CreateUserRequest struct {
 	// Deprecated: Set UserLoginType=LoginTypeDisabled instead.
 	DisableLogin   bool      `json:"disable_login"`
 	OrganizationID uuid.UUID `json:"organization_id" validate:"" format:"uuid"`
+	// ServiceAccount creates a service account owned by OrganizationID.
+	// Service accounts cannot log in and must use API tokens, so
+	// UserLoginType must be empty or LoginTypeNone and Password must be
+	// empty.
+	ServiceAccount bool `json:"service_account,omitempty"`
 }
 
 type UpdateUserProfileRequest struct {
@@ -192,6 +200,13 @@ type ConvertLoginRequest struct {
 type LoginWithPasswordRequest struct {
 	Email    string `json:"email" validate:"required,email" format:"email"`
 	Password string `json:"password" validate:"required"`
+	// TOTPCode is the one-time code from the user's authenticator app. It is
+	// required if the user has enabled TOTP.
+	TOTPCode string `json:"totp_code,omitempty"`
+	// RecoveryCode can be used instead of TOTPCode if the user has lost
+	// access to their authenticator app. Each recovery code can only be used
+	// once.
+	RecoveryCode string `json:"recovery_code,omitempty"`
 }
 
 // LoginWithPasswordResponse contains a session token for the newly authenticated user.
@@ -199,6 +214,73 @@ type LoginWithPasswordResponse struct {
 	SessionToken string `json:"session_token" validate:"required"`
 }
 
+// LoginWithLDAPRequest enables callers to authenticate with the username and
+// password of their LDAP directory account.
+type LoginWithLDAPRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// DeviceLoginResponse is returned when a device login is started. The user
+// enters UserCode at VerificationURL in any browser while the device polls
+// for a session token with DeviceCode.
+type DeviceLoginResponse struct {
+	DeviceCode              string    `json:"device_code"`
+	UserCode                string    `json:"user_code"`
+	VerificationURL         string    `json:"verification_url"`
+	VerificationURLComplete string    `json:"verification_url_complete"`
+	ExpiresAt               time.Time `json:"expires_at" format:"date-time"`
+	// Interval is the number of seconds to wait between polls.
+	Interval int `json:"interval"`
+}
+
+type DeviceLoginTokenRequest struct {
+	DeviceCode string `json:"device_code" validate:"required"`
+}
+
+type DeviceLoginStatus string
+
+const (
+	DeviceLoginStatusPending  DeviceLoginStatus = "pending"
+	DeviceLoginStatusApproved DeviceLoginStatus = "approved"
+	DeviceLoginStatusDenied   DeviceLoginStatus = "denied"
+	DeviceLoginStatusExpired  DeviceLoginStatus = "expired"
+)
+
+// DeviceLoginTokenResponse contains the status of a device login. The session
+// token is only set once the login has been approved, and can only be
+// retrieved once.
+type DeviceLoginTokenResponse struct {
+	Status       DeviceLoginStatus `json:"status"`
+	SessionToken string            `json:"session_token,omitempty"`
+}
+
+// UserMFAStatus describes the second factors a user has enrolled.
+type UserMFAStatus struct {
+	TOTPEnabled bool `json:"totp_enabled"`
+	// RecoveryCodesRemaining is the number of unused recovery codes.
+	RecoveryCodesRemaining int `json:"recovery_codes_remaining"`
+	// Required is true if the deployment requires the user to log in with a
+	// second factor.
+	Required bool `json:"required"`
+}
+
+// TOTPEnrollment contains everything needed to add a TOTP secret to an
+// authenticator app. The recovery codes are only returned once.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URL is the otpauth:// URL of the secret.
+	URL string `json:"url"`
+	// QRCode is a PNG image of URL.
+	QRCode        []byte   `json:"qr_code"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ConfirmTOTPRequest enables TOTP for a pending enrollment.
+type ConfirmTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
 type OAuthConversionResponse struct {
 	StateString string    `json:"state_string"`
 	ExpiresAt   time.Time `json:"expires_at" format:"date-time"`
@@ -212,6 +294,7 @@ type AuthMethods struct {
 	Password          AuthMethod     `json:"password"`
 	Github            AuthMethod     `json:"github"`
 	OIDC              OIDCAuthMethod `json:"oidc"`
+	LDAP              AuthMethod     `json:"ldap"`
 }
 
 type AuthMethod struct {
@@ -228,6 +311,47 @@ type OIDCAuthMethod struct {
 	IconURL    string `json:"iconUrl"`
 }
 
+// OIDCSyncDryRunRequest is a set of OIDC claims to evaluate against the
+// deployment's OIDC sync settings without logging anyone in.
+type OIDCSyncDryRunRequest struct {
+	// Claims are the merged ID token and user info claims.
+	Claims map[string]interface{} `json:"claims"`
+	// UserID optionally selects an existing user whose current memberships
+	// the result is compared against. If unset, the result is what a new user
+	// would get.
+	UserID uuid.UUID `json:"user_id,omitempty" format:"uuid"`
+}
+
+// OIDCSyncDryRunResponse is the effect a set of OIDC claims would have on
+// login.
+type OIDCSyncDryRunResponse struct {
+	OrganizationSyncEnabled bool `json:"organization_sync_enabled"`
+	GroupSyncEnabled        bool `json:"group_sync_enabled"`
+	// Organizations are the organizations the user would be added to, kept in
+	// or removed from.
+	Organizations []OIDCSyncDryRunOrganization `json:"organizations"`
+	// UnmatchedOrganizations are claim values that did not match any
+	// organization, after mapping.
+	UnmatchedOrganizations []string `json:"unmatched_organizations"`
+}
+
+type OIDCSyncDryRunOrganization struct {
+	ID     uuid.UUID      `json:"id" format:"uuid"`
+	Name   string         `json:"name"`
+	Action OIDCSyncAction `json:"action"`
+	// Groups are the groups the user would be a member of in the
+	// organization. Always empty if the user would be removed.
+	Groups []string `json:"groups"`
+}
+
+type OIDCSyncAction string
+
+const (
+	OIDCSyncActionAdd    OIDCSyncAction = "add"
+	OIDCSyncActionKeep   OIDCSyncAction = "keep"
+	OIDCSyncActionRemove OIDCSyncAction = "remove"
+)
+
 type UserParameter struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
@@ -488,6 +612,154 @@ func (c *Client) LoginWithPassword(ctx context.Context, req LoginWithPasswordReq
 	return resp, nil
 }
 
+// LoginWithLDAP authenticates the user with their LDAP directory credentials.
+// The user is created on first login if the deployment allows signups.
+func (c *Client) LoginWithLDAP(ctx context.Context, req LoginWithLDAPRequest) (LoginWithPasswordResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/ldap/login", req)
+	if err != nil {
+		return LoginWithPasswordResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return LoginWithPasswordResponse{}, ReadBodyAsError(res)
+	}
+	var resp LoginWithPasswordResponse
+	err = json.NewDecoder(res.Body).Decode(&resp)
+	if err != nil {
+		return LoginWithPasswordResponse{}, err
+	}
+	return resp, nil
+}
+
+// EnrollTOTPWithPassword starts a TOTP enrollment for a user that must enroll
+// before they can log in. The enrollment is confirmed by calling
+// LoginWithPassword with a code from the new secret.
+func (c *Client) EnrollTOTPWithPassword(ctx context.Context, req LoginWithPasswordRequest) (TOTPEnrollment, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/login/mfa/totp", req)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TOTPEnrollment{}, ReadBodyAsError(res)
+	}
+	var resp TOTPEnrollment
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// IsTOTPRequiredError returns true if a password login failed because a
+// valid one-time code or recovery code is required.
+func IsTOTPRequiredError(err error) bool {
+	return hasLoginValidationError(err, "totp_code") || hasLoginValidationError(err, "recovery_code")
+}
+
+// IsTOTPEnrollmentRequiredError returns true if a password login failed
+// because the user must enroll TOTP first. See EnrollTOTPWithPassword.
+func IsTOTPEnrollmentRequiredError(err error) bool {
+	return hasLoginValidationError(err, "totp_enrollment")
+}
+
+func hasLoginValidationError(err error, field string) bool {
+	var apiErr *Error
+	if !xerrors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusUnauthorized {
+		return false
+	}
+	for _, v := range apiErr.Validations {
+		if v.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// UserMFAStatus returns the second factors of a user.
+func (c *Client) UserMFAStatus(ctx context.Context, user string) (UserMFAStatus, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/mfa", user), nil)
+	if err != nil {
+		return UserMFAStatus{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserMFAStatus{}, ReadBodyAsError(res)
+	}
+	var resp UserMFAStatus
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// EnrollTOTP generates a new TOTP secret and recovery codes for the user. TOTP
+// is not enabled until the enrollment is confirmed with ConfirmTOTP.
+func (c *Client) EnrollTOTP(ctx context.Context, user string) (TOTPEnrollment, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/mfa/totp", user), nil)
+	if err != nil {
+		return TOTPEnrollment{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TOTPEnrollment{}, ReadBodyAsError(res)
+	}
+	var resp TOTPEnrollment
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ConfirmTOTP enables TOTP for the user with a code from the pending
+// enrollment.
+func (c *Client) ConfirmTOTP(ctx context.Context, user string, req ConfirmTOTPRequest) error {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/mfa/totp/confirm", user), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// ResetUserTOTP removes the TOTP secret and recovery codes of a user, so they
+// can log in with their password alone and enroll again.
+func (c *Client) ResetUserTOTP(ctx context.Context, user string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/mfa/totp", user), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// StartDeviceLogin starts a login for a device without a browser. The user
+// code must be approved by the user in a browser before DeviceLoginToken
+// returns a session token.
+func (c *Client) StartDeviceLogin(ctx context.Context) (DeviceLoginResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/login/device", nil)
+	if err != nil {
+		return DeviceLoginResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return DeviceLoginResponse{}, ReadBodyAsError(res)
+	}
+	var resp DeviceLoginResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// DeviceLoginToken checks whether a device login has been approved, and
+// returns a session token if it has.
+func (c *Client) DeviceLoginToken(ctx context.Context, req DeviceLoginTokenRequest) (DeviceLoginTokenResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/login/device/token", req)
+	if err != nil {
+		return DeviceLoginTokenResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return DeviceLoginTokenResponse{}, ReadBodyAsError(res)
+	}
+	var resp DeviceLoginTokenResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // ConvertLoginType will send a request to convert the user from password
 // based authentication to oauth based. The response has the oauth state code
 // to use in the oauth flow.
@@ -644,3 +916,18 @@ func (c *Client) AuthMethods(ctx context.Context) (AuthMethods, error) {
 	var userAuth AuthMethods
 	return userAuth, json.NewDecoder(res.Body).Decode(&userAuth)
 }
+
+// OIDCSyncDryRun previews the organizations and groups a set of OIDC claims
+// would sync a user to on login.
+func (c *Client) OIDCSyncDryRun(ctx context.Context, req OIDCSyncDryRunRequest) (OIDCSyncDryRunResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/users/oidc/sync/dry-run", req)
+	if err != nil {
+		return OIDCSyncDryRunResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return OIDCSyncDryRunResponse{}, ReadBodyAsError(res)
+	}
+	var resp OIDCSyncDryRunResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -1,7 +1,13 @@
 package codersdk
 
 import (
+	"net"
+	"net/url"
+	"path"
+	"strconv"
+
 	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 )
 
 type WorkspaceAppHealth string
@@ -34,6 +40,68 @@ var MapWorkspaceAppSharingLevels = map[WorkspaceAppSharingLevel]struct{}{
 	WorkspaceAppSharingLevelPublic:        {},
 }
 
+// WorkspaceAppProtocol is how a workspace app is reached, and is derived from
+// the scheme of the app URL.
+type WorkspaceAppProtocol string
+
+const (
+	WorkspaceAppProtocolHTTP  WorkspaceAppProtocol = "http"
+	WorkspaceAppProtocolHTTPS WorkspaceAppProtocol = "https"
+	// WorkspaceAppProtocolUnix apps serve HTTP on a unix socket inside the
+	// workspace, e.g. "unix:///run/jupyter.sock".
+	WorkspaceAppProtocolUnix WorkspaceAppProtocol = "unix"
+	// WorkspaceAppProtocolTCP and WorkspaceAppProtocolUDP apps cannot be opened
+	// in a browser, and are reached with "coder port-forward --app" instead,
+	// e.g. "tcp://localhost:5432".
+	WorkspaceAppProtocolTCP WorkspaceAppProtocol = "tcp"
+	WorkspaceAppProtocolUDP WorkspaceAppProtocol = "udp"
+)
+
+// Proxied returns true if apps using the protocol can be opened in a browser
+// through the workspace app proxy.
+func (p WorkspaceAppProtocol) Proxied() bool {
+	return p != WorkspaceAppProtocolTCP && p != WorkspaceAppProtocolUDP
+}
+
+// WorkspaceAppProtocolFromURL returns the protocol of an app URL. Unknown
+// schemes are proxied as HTTP.
+func WorkspaceAppProtocolFromURL(u *url.URL) WorkspaceAppProtocol {
+	switch p := WorkspaceAppProtocol(u.Scheme); p {
+	case WorkspaceAppProtocolHTTPS, WorkspaceAppProtocolUnix, WorkspaceAppProtocolTCP, WorkspaceAppProtocolUDP:
+		return p
+	default:
+		return WorkspaceAppProtocolHTTP
+	}
+}
+
+// ValidateWorkspaceAppURL checks that the URL of an app proxied by Coder is
+// well formed for its protocol. Unix socket apps must use an absolute path,
+// and TCP and UDP apps must specify a port.
+func ValidateWorkspaceAppURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return xerrors.Errorf("parse app URL %q: %w", rawURL, err)
+	}
+	switch WorkspaceAppProtocolFromURL(u) {
+	case WorkspaceAppProtocolUnix:
+		if u.Host != "" || !path.IsAbs(u.Path) {
+			return xerrors.Errorf("unix socket app URL %q must be an absolute path, e.g. unix:///run/app.sock", rawURL)
+		}
+	case WorkspaceAppProtocolTCP, WorkspaceAppProtocolUDP:
+		_, port, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			return xerrors.Errorf("%s app URL %q must include a host and port, e.g. %s://localhost:5432", u.Scheme, rawURL, u.Scheme)
+		}
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return xerrors.Errorf("%s app URL %q has an invalid port %q", u.Scheme, rawURL, port)
+		}
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return xerrors.Errorf("%s app URL %q must not include a path or query", u.Scheme, rawURL)
+		}
+	}
+	return nil
+}
+
 type WorkspaceApp struct {
 	ID uuid.UUID `json:"id" format:"uuid"`
 	// URL is the address being proxied to inside the workspace.
@@ -42,6 +110,9 @@ type WorkspaceApp struct {
 	// External specifies whether the URL should be opened externally on
 	// the client or not.
 	External bool `json:"external"`
+	// Protocol is derived from the URL scheme. TCP and UDP apps cannot be
+	// opened in a browser and must be reached with "coder port-forward".
+	Protocol WorkspaceAppProtocol `json:"protocol,omitempty" enums:"http,https,unix,tcp,udp"`
 	// Slug is a unique identifier within the agent.
 	Slug string `json:"slug"`
 	// DisplayName is a friendly name for the app.
@@ -103,6 +103,37 @@ type WorkspaceBuildParameter struct {
 	Value string `json:"value"`
 }
 
+// AgentTimingStage is a stage of starting a workspace agent that is timed.
+type AgentTimingStage string
+
+const (
+	// AgentTimingStageConnect is the time from the agent being created by
+	// the build until it first connected to coderd.
+	AgentTimingStageConnect AgentTimingStage = "connect"
+	// AgentTimingStageStart is the time the agent spent running its startup
+	// scripts.
+	AgentTimingStageStart AgentTimingStage = "start"
+)
+
+// AgentTiming is the time a workspace agent spent in one stage of starting.
+type AgentTiming struct {
+	AgentID   uuid.UUID        `json:"agent_id" format:"uuid"`
+	AgentName string           `json:"agent_name"`
+	Stage     AgentTimingStage `json:"stage" enums:"connect,start"`
+	StartedAt time.Time        `json:"started_at" format:"date-time"`
+	EndedAt   time.Time        `json:"ended_at" format:"date-time"`
+	// Status is the current lifecycle state of the agent, e.g. "ready" or
+	// "start_error".
+	Status WorkspaceAgentLifecycle `json:"status"`
+}
+
+// WorkspaceBuildTimings are the provisioner and agent timings of a build, in
+// the order they started.
+type WorkspaceBuildTimings struct {
+	ProvisionerTimings []ProvisionerTiming `json:"provisioner_timings"`
+	AgentTimings       []AgentTiming       `json:"agent_timings"`
+}
+
 // WorkspaceBuild returns a single workspace build for a workspace.
 // If history is "", the latest version is returned.
 func (c *Client) WorkspaceBuild(ctx context.Context, id uuid.UUID) (WorkspaceBuild, error) {
@@ -162,6 +193,19 @@ func (c *Client) WorkspaceBuildByUsernameAndWorkspaceNameAndBuildNumber(ctx cont
 	return workspaceBuild, json.NewDecoder(res.Body).Decode(&workspaceBuild)
 }
 
+func (c *Client) WorkspaceBuildTimings(ctx context.Context, build uuid.UUID) (WorkspaceBuildTimings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspacebuilds/%s/timings", build), nil)
+	if err != nil {
+		return WorkspaceBuildTimings{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceBuildTimings{}, ReadBodyAsError(res)
+	}
+	var timings WorkspaceBuildTimings
+	return timings, json.NewDecoder(res.Body).Decode(&timings)
+}
+
 func (c *Client) WorkspaceBuildParameters(ctx context.Context, build uuid.UUID) ([]WorkspaceBuildParameter, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspacebuilds/%s/parameters", build), nil)
 	if err != nil {
@@ -0,0 +1,138 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResourceChangeAction is what applying a plan would do to a resource.
+type ResourceChangeAction string
+
+const (
+	ResourceChangeActionCreate  ResourceChangeAction = "create"
+	ResourceChangeActionUpdate  ResourceChangeAction = "update"
+	ResourceChangeActionReplace ResourceChangeAction = "replace"
+	ResourceChangeActionDelete  ResourceChangeAction = "delete"
+)
+
+// Destructive returns true if the action destroys an existing resource,
+// either outright or to recreate it.
+func (a ResourceChangeAction) Destructive() bool {
+	return a == ResourceChangeActionReplace || a == ResourceChangeActionDelete
+}
+
+// WorkspaceResourceChange is a single resource change in a workspace plan.
+type WorkspaceResourceChange struct {
+	Address string               `json:"address" table:"address,default_sort"`
+	Type    string               `json:"type" table:"type"`
+	Name    string               `json:"name" table:"name"`
+	Action  ResourceChangeAction `json:"action" table:"action" enums:"create,update,replace,delete"`
+	// Destructive is set for changes that destroy an existing resource, and
+	// with it any data that lives on the resource.
+	Destructive bool `json:"destructive" table:"destructive"`
+}
+
+// CreateWorkspacePlanRequest defines the build a workspace plan previews.
+type CreateWorkspacePlanRequest struct {
+	// TemplateVersionID defaults to the version of the latest build.
+	TemplateVersionID   uuid.UUID                 `json:"template_version_id,omitempty" format:"uuid"`
+	Transition          WorkspaceTransition       `json:"transition" validate:"oneof=start stop delete,required"`
+	RichParameterValues []WorkspaceBuildParameter `json:"rich_parameter_values,omitempty"`
+
+	// Log level changes the default logging verbosity of a provider ("info" if empty).
+	LogLevel ProvisionerLogLevel `json:"log_level,omitempty" validate:"omitempty,oneof=debug"`
+}
+
+// WorkspacePlan is the outcome of a plan-only workspace build. Resource
+// changes are empty until the job has succeeded.
+type WorkspacePlan struct {
+	Job             ProvisionerJob            `json:"job"`
+	ResourceChanges []WorkspaceResourceChange `json:"resource_changes"`
+}
+
+// WorkspaceDrift is the outcome of the latest drift check of a workspace.
+// Drift checks are refresh-only plans that compare the live infrastructure of
+// a running workspace with the state of its latest build. They are scheduled
+// periodically for templates with drift detection enabled.
+type WorkspaceDrift struct {
+	// CheckedAt is when drift was last checked for the latest build, nil if
+	// it hasn't been checked yet.
+	CheckedAt *time.Time `json:"checked_at,omitempty" format:"date-time"`
+	// LatestCheck is the most recently scheduled drift check, which may still
+	// be pending.
+	LatestCheck *ProvisionerJob `json:"latest_check,omitempty"`
+	// Resources are the resources that changed outside of Coder.
+	Resources []WorkspaceResourceChange `json:"resources"`
+}
+
+// CreateWorkspacePlan begins a plan-only build of the workspace. Nothing is
+// applied, the plan only records what the build would change.
+func (c *Client) CreateWorkspacePlan(ctx context.Context, workspace uuid.UUID, req CreateWorkspacePlanRequest) (ProvisionerJob, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/workspaces/%s/plans", workspace), req)
+	if err != nil {
+		return ProvisionerJob{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return ProvisionerJob{}, ReadBodyAsError(res)
+	}
+
+	var job ProvisionerJob
+	return job, json.NewDecoder(res.Body).Decode(&job)
+}
+
+// WorkspacePlan returns a workspace plan and the resource changes it found.
+func (c *Client) WorkspacePlan(ctx context.Context, workspace, job uuid.UUID) (WorkspacePlan, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/plans/%s", workspace, job), nil)
+	if err != nil {
+		return WorkspacePlan{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspacePlan{}, ReadBodyAsError(res)
+	}
+
+	var plan WorkspacePlan
+	return plan, json.NewDecoder(res.Body).Decode(&plan)
+}
+
+// WorkspaceDrift returns the resources of a workspace that drifted from the
+// state of its latest build.
+func (c *Client) WorkspaceDrift(ctx context.Context, workspace uuid.UUID) (WorkspaceDrift, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/drift", workspace), nil)
+	if err != nil {
+		return WorkspaceDrift{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceDrift{}, ReadBodyAsError(res)
+	}
+
+	var drift WorkspaceDrift
+	return drift, json.NewDecoder(res.Body).Decode(&drift)
+}
+
+// WorkspacePlanLogsAfter streams logs for a workspace plan that occurred
+// after a specific log ID.
+func (c *Client) WorkspacePlanLogsAfter(ctx context.Context, workspace, job uuid.UUID, after int64) (<-chan ProvisionerJobLog, io.Closer, error) {
+	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/workspaces/%s/plans/%s/logs", workspace, job), after)
+}
+
+// CancelWorkspacePlan marks a workspace plan job as canceled.
+func (c *Client) CancelWorkspacePlan(ctx context.Context, workspace, job uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/workspaces/%s/plans/%s/cancel", workspace, job), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
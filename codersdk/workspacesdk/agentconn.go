@@ -201,7 +201,8 @@ func (c *AgentConn) Speedtest(ctx context.Context, direction speedtest.Direction
 }
 
 // DialContext dials the address provided in the workspace agent.
-// The network must be "tcp" or "udp".
+// The network must be "tcp", "udp" or "unix". For "unix", addr is the path of
+// the socket in the workspace.
 func (c *AgentConn) DialContext(ctx context.Context, network string, addr string) (net.Conn, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
@@ -219,11 +220,41 @@ func (c *AgentConn) DialContext(ctx context.Context, network string, addr string
 		return c.Conn.DialContextTCP(ctx, ipp)
 	case "udp":
 		return c.Conn.DialContextUDP(ctx, ipp)
+	case "unix":
+		return c.dialUnix(ctx, addr)
 	default:
 		return nil, xerrors.Errorf("unknown network %q", network)
 	}
 }
 
+// dialUnix dials a unix socket in the workspace. The agent does not expose
+// unix sockets over tailnet, so the connection is forwarded by the agent's SSH
+// server instead.
+func (c *AgentConn) dialUnix(ctx context.Context, socketPath string) (net.Conn, error) {
+	sshClient, err := c.SSHClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sshClient.Dial("unix", socketPath)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, xerrors.Errorf("dial unix socket %q: %w", socketPath, err)
+	}
+	return &sshForwardedConn{Conn: conn, client: sshClient}, nil
+}
+
+// sshForwardedConn closes the SSH client it was forwarded by when closed.
+type sshForwardedConn struct {
+	net.Conn
+	client *ssh.Client
+}
+
+func (c *sshForwardedConn) Close() error {
+	err := c.Conn.Close()
+	_ = c.client.Close()
+	return err
+}
+
 // ListeningPorts lists the ports that are currently in use by the workspace.
 func (c *AgentConn) ListeningPorts(ctx context.Context) (codersdk.WorkspaceAgentListeningPortsResponse, error) {
 	ctx, span := tracing.StartSpan(ctx)
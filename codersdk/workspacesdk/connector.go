@@ -67,6 +67,8 @@ type tailnetAPIConnector struct {
 	dialOptions   *websocket.DialOptions
 	conn          tailnetConn
 	customDialFn  func() (proto.DRPCTailnetClient, error)
+	// preferredRegion is applied to every DERP map received from the server.
+	preferredRegion string
 
 	clientMu sync.RWMutex
 	client   proto.DRPCTailnetClient
@@ -314,7 +316,7 @@ func (tac *tailnetAPIConnector) derpMap(client proto.DRPCTailnetClient) error {
 			return err
 		}
 		tac.logger.Debug(tac.ctx, "got new DERP Map", slog.F("derp_map", dmp))
-		dm := tailnet.DERPMapFromProto(dmp)
+		dm := preferRegion(tailnet.DERPMapFromProto(dmp), tac.preferredRegion)
 		tac.conn.SetDERPMap(dm)
 	}
 }
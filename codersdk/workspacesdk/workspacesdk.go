@@ -190,6 +190,10 @@ type DialAgentOptions struct {
 	// Whether the client will send network telemetry events.
 	// Enable instead of Disable so it's initialized to false (in tests).
 	EnableTelemetry bool
+	// PreferredRegion is the name of a region from the regions API whose DERP
+	// relay should be favored as the home DERP for this connection. Ignored if
+	// the region has no relay in the DERP map.
+	PreferredRegion string
 }
 
 func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *DialAgentOptions) (agentConn *AgentConn, err error) {
@@ -204,6 +208,7 @@ func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *
 	if connInfo.DisableDirectConnections {
 		options.BlockEndpoints = true
 	}
+	connInfo.DERPMap = preferRegion(connInfo.DERPMap, options.PreferredRegion)
 
 	headers := make(http.Header)
 	tokenHeader := codersdk.SessionTokenHeader
@@ -244,6 +249,7 @@ func (c *Client) DialAgent(dialCtx context.Context, agentID uuid.UUID, options *
 			// Need to disable compression to avoid a data-race.
 			CompressionMode: websocket.CompressionDisabled,
 		})
+	connector.preferredRegion = options.PreferredRegion
 
 	ip := tailnet.IP()
 	var header http.Header
@@ -367,3 +373,13 @@ func (c *Client) AgentReconnectingPTY(ctx context.Context, opts WorkspaceAgentRe
 	}
 	return websocket.NetConn(context.Background(), conn, websocket.MessageBinary), nil
 }
+
+// preferRegion favors the DERP relay of the named region in the DERP map. The
+// map is returned unchanged if no region is named or it has no relay.
+func preferRegion(derpMap *tailcfg.DERPMap, regionName string) *tailcfg.DERPMap {
+	regionID, ok := tailnet.DERPRegionIDByName(derpMap, regionName)
+	if !ok {
+		return derpMap
+	}
+	return tailnet.PreferDERPRegion(derpMap, regionID)
+}
@@ -20,7 +20,7 @@ var AuditActionMap = map[string][]codersdk.AuditAction{
 	"Template":        {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
 	"TemplateVersion": {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
 	"User":            {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete, codersdk.AuditActionOpen},
 	"WorkspaceBuild":  {codersdk.AuditActionStart, codersdk.AuditActionStop},
 	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
 	"APIKey":          {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
@@ -111,6 +111,13 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"deprecated":                        ActionTrack,
 		"max_port_sharing_level":            ActionTrack,
 		"activity_bump":                     ActionTrack,
+		"allowed_workspace_proxy_ids":       ActionTrack,
+		"allowed_derp_region_ids":           ActionTrack,
+		"max_app_connections_per_user":      ActionTrack,
+		"max_app_bytes_per_second":          ActionTrack,
+		"drift_detection_interval":          ActionTrack,
+		"build_timeout":                     ActionTrack,
+		"build_cancel_grace_period":         ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                      ActionTrack,
@@ -129,22 +136,24 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"archived":                ActionTrack,
 	},
 	&database.User{}: {
-		"id":                   ActionTrack,
-		"email":                ActionTrack,
-		"username":             ActionTrack,
-		"hashed_password":      ActionSecret, // Do not expose a users hashed password.
-		"created_at":           ActionIgnore, // Never changes.
-		"updated_at":           ActionIgnore, // Changes, but is implicit and not helpful in a diff.
-		"status":               ActionTrack,
-		"rbac_roles":           ActionTrack,
-		"login_type":           ActionTrack,
-		"avatar_url":           ActionIgnore,
-		"last_seen_at":         ActionIgnore,
-		"deleted":              ActionTrack,
-		"quiet_hours_schedule": ActionTrack,
-		"theme_preference":     ActionIgnore,
-		"name":                 ActionTrack,
-		"github_com_user_id":   ActionIgnore,
+		"id":                              ActionTrack,
+		"email":                           ActionTrack,
+		"username":                        ActionTrack,
+		"hashed_password":                 ActionSecret, // Do not expose a users hashed password.
+		"created_at":                      ActionIgnore, // Never changes.
+		"updated_at":                      ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"status":                          ActionTrack,
+		"rbac_roles":                      ActionTrack,
+		"login_type":                      ActionTrack,
+		"avatar_url":                      ActionIgnore,
+		"last_seen_at":                    ActionIgnore,
+		"deleted":                         ActionTrack,
+		"quiet_hours_schedule":            ActionTrack,
+		"theme_preference":                ActionIgnore,
+		"name":                            ActionTrack,
+		"github_com_user_id":              ActionIgnore,
+		"is_service_account":              ActionTrack,
+		"service_account_organization_id": ActionTrack,
 	},
 	&database.Workspace{}: {
 		"id":                 ActionTrack,
@@ -164,22 +173,23 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"favorite":           ActionTrack,
 	},
 	&database.WorkspaceBuild{}: {
-		"id":                      ActionIgnore,
-		"created_at":              ActionIgnore,
-		"updated_at":              ActionIgnore,
-		"workspace_id":            ActionIgnore,
-		"template_version_id":     ActionTrack,
-		"build_number":            ActionIgnore,
-		"transition":              ActionIgnore,
-		"initiator_id":            ActionIgnore,
-		"provisioner_state":       ActionIgnore,
-		"job_id":                  ActionIgnore,
-		"deadline":                ActionIgnore,
-		"reason":                  ActionIgnore,
-		"daily_cost":              ActionIgnore,
-		"max_deadline":            ActionIgnore,
-		"initiator_by_avatar_url": ActionIgnore,
-		"initiator_by_username":   ActionIgnore,
+		"id":                       ActionIgnore,
+		"created_at":               ActionIgnore,
+		"updated_at":               ActionIgnore,
+		"workspace_id":             ActionIgnore,
+		"template_version_id":      ActionTrack,
+		"build_number":             ActionIgnore,
+		"transition":               ActionIgnore,
+		"initiator_id":             ActionIgnore,
+		"provisioner_state":        ActionIgnore,
+		"job_id":                   ActionIgnore,
+		"deadline":                 ActionIgnore,
+		"reason":                   ActionIgnore,
+		"daily_cost":               ActionIgnore,
+		"max_deadline":             ActionIgnore,
+		"provisioner_state_key_id": ActionIgnore,
+		"initiator_by_avatar_url":  ActionIgnore,
+		"initiator_by_username":    ActionIgnore,
 	},
 	&database.AuditableGroup{}: {
 		"id":              ActionTrack,
@@ -204,6 +214,9 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"ip_address":       ActionIgnore,
 		"scope":            ActionIgnore,
 		"token_name":       ActionIgnore,
+		"scopes":           ActionTrack,
+		"allow_list":       ActionTrack,
+		"user_agent":       ActionIgnore,
 	},
 	&database.AuditOAuthConvertState{}: {
 		"created_at":      ActionTrack,
@@ -246,12 +259,14 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"version":             ActionTrack,
 	},
 	&database.OAuth2ProviderApp{}: {
-		"id":           ActionIgnore,
-		"created_at":   ActionIgnore,
-		"updated_at":   ActionIgnore,
-		"name":         ActionTrack,
-		"icon":         ActionTrack,
-		"callback_url": ActionTrack,
+		"id":                         ActionIgnore,
+		"created_at":                 ActionIgnore,
+		"updated_at":                 ActionIgnore,
+		"name":                       ActionTrack,
+		"icon":                       ActionTrack,
+		"callback_url":               ActionTrack,
+		"public":                     ActionTrack,
+		"client_credentials_user_id": ActionTrack,
 	},
 	&database.OAuth2ProviderAppSecret{}: {
 		"id":             ActionIgnore,
@@ -263,14 +278,16 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"secret_prefix":  ActionIgnore,
 	},
 	&database.Organization{}: {
-		"id":           ActionIgnore,
-		"name":         ActionTrack,
-		"description":  ActionTrack,
-		"created_at":   ActionIgnore,
-		"updated_at":   ActionTrack,
-		"is_default":   ActionTrack,
-		"display_name": ActionTrack,
-		"icon":         ActionTrack,
+		"id":                          ActionIgnore,
+		"name":                        ActionTrack,
+		"description":                 ActionTrack,
+		"created_at":                  ActionIgnore,
+		"updated_at":                  ActionTrack,
+		"is_default":                  ActionTrack,
+		"display_name":                ActionTrack,
+		"icon":                        ActionTrack,
+		"allowed_workspace_proxy_ids": ActionTrack,
+		"allowed_derp_region_ids":     ActionTrack,
 	},
 	&database.NotificationTemplate{}: {
 		"id":             ActionIgnore,
@@ -282,6 +299,35 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"method":         ActionTrack,
 		"kind":           ActionTrack,
 	},
+	&database.RoleGrantRequest{}: {
+		"id":               ActionIgnore,
+		"user_id":          ActionTrack,
+		"organization_id":  ActionIgnore, // Never changes.
+		"role_name":        ActionTrack,
+		"reason":           ActionTrack,
+		"lifetime_seconds": ActionTrack,
+		"status":           ActionTrack,
+		"created_at":       ActionIgnore, // Never changes.
+		"updated_at":       ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"reviewed_by":      ActionTrack,
+		"reviewed_at":      ActionTrack,
+		"expires_at":       ActionTrack,
+	},
+	&database.TemplateVersionCanary{}: {
+		"id":                  ActionIgnore,
+		"template_id":         ActionIgnore, // Never changes.
+		"template_version_id": ActionTrack,
+		"percent":             ActionTrack,
+		"group_id":            ActionTrack,
+		"min_builds":          ActionTrack,
+		"success_threshold":   ActionTrack,
+		"status":              ActionTrack,
+		"status_reason":       ActionTrack,
+		"created_by":          ActionTrack,
+		"created_at":          ActionIgnore, // Never changes.
+		"updated_at":          ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"completed_at":        ActionTrack,
+	},
 }
 
 // auditMap converts a map of struct pointers to a map of struct names as
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,7 +25,6 @@ import (
 	"github.com/coder/coder/v2/cli/clilog"
 	"github.com/coder/coder/v2/cli/cliui"
 	"github.com/coder/coder/v2/cli/cliutil"
-	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/provisionerkey"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/codersdk/drpc"
@@ -38,6 +39,7 @@ import (
 func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 	var (
 		cacheDir       string
+		cacheMaxSize   int64
 		logHuman       string
 		logJSON        string
 		logStackdriver string
@@ -48,6 +50,8 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 		pollJitter     time.Duration
 		preSharedKey   string
 		provisionerKey string
+		provisioner    string
+		providerMirror string
 		verbose        bool
 
 		prometheusEnable  bool
@@ -173,6 +177,34 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				return err
 			}
 
+			var metrics *provisionerd.Metrics
+			if prometheusEnable {
+				logger.Info(ctx, "starting Prometheus endpoint", slog.F("address", prometheusAddress))
+
+				prometheusRegistry := prometheus.NewRegistry()
+				prometheusRegistry.MustRegister(collectors.NewGoCollector())
+				prometheusRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+				m := provisionerd.NewMetrics(prometheusRegistry)
+				m.Runner.NumDaemons.Set(float64(1)) // Set numDaemons to 1 as this is standalone mode.
+				metrics = &m
+
+				closeFunc := agpl.ServeHandler(ctx, logger, promhttp.InstrumentMetricHandler(
+					prometheusRegistry, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}),
+				), prometheusAddress, "prometheus")
+				defer closeFunc()
+			}
+
+			// OpenTofu daemons keep their providers apart from Terraform
+			// daemons that share the same cache directory. The directory is
+			// a sibling, since Terraform daemons clean up and evict every
+			// directory below theirs.
+			engine := terraform.Engine(provisioner)
+			engineCacheDir := cacheDir
+			if engine == terraform.EngineOpenTofu {
+				engineCacheDir = filepath.Clean(cacheDir) + "-tofu"
+			}
+
 			terraformClient, terraformServer := drpc.MemTransportPipe()
 			go func() {
 				<-ctx.Done()
@@ -180,6 +212,10 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				_ = terraformServer.Close()
 			}()
 
+			var cacheMetrics *prometheus.CounterVec
+			if metrics != nil {
+				cacheMetrics = metrics.TerraformCache
+			}
 			errCh := make(chan error, 1)
 			go func() {
 				defer cancel()
@@ -187,10 +223,14 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				err := terraform.Serve(ctx, &terraform.ServeOptions{
 					ServeOptions: &provisionersdk.ServeOptions{
 						Listener:      terraformServer,
-						Logger:        logger.Named("terraform"),
+						Logger:        logger.Named(engine.BinaryName()),
 						WorkDirectory: tempDir,
 					},
-					CachePath: cacheDir,
+					Engine:         engine,
+					CachePath:      engineCacheDir,
+					CacheMaxSize:   cacheMaxSize,
+					CacheMetrics:   cacheMetrics,
+					ProviderMirror: providerMirror,
 				})
 				if err != nil && !xerrors.Is(err, context.Canceled) {
 					select {
@@ -200,35 +240,17 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 				}
 			}()
 
-			var metrics *provisionerd.Metrics
-			if prometheusEnable {
-				logger.Info(ctx, "starting Prometheus endpoint", slog.F("address", prometheusAddress))
-
-				prometheusRegistry := prometheus.NewRegistry()
-				prometheusRegistry.MustRegister(collectors.NewGoCollector())
-				prometheusRegistry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-
-				m := provisionerd.NewMetrics(prometheusRegistry)
-				m.Runner.NumDaemons.Set(float64(1)) // Set numDaemons to 1 as this is standalone mode.
-				metrics = &m
-
-				closeFunc := agpl.ServeHandler(ctx, logger, promhttp.InstrumentMetricHandler(
-					prometheusRegistry, promhttp.HandlerFor(prometheusRegistry, promhttp.HandlerOpts{}),
-				), prometheusAddress, "prometheus")
-				defer closeFunc()
-			}
-
-			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", tags), slog.F("name", name))
+			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", tags), slog.F("name", name), slog.F("provisioner", provisioner))
 
 			connector := provisionerd.LocalProvisioners{
-				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
+				provisioner: proto.NewDRPCProvisionerClient(terraformClient),
 			}
 			srv := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
 				return client.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
 					ID:   uuid.New(),
 					Name: name,
 					Provisioners: []codersdk.ProvisionerType{
-						codersdk.ProvisionerTypeTerraform,
+						codersdk.ProvisionerType(provisioner),
 					},
 					Tags:           tags,
 					PreSharedKey:   preSharedKey,
@@ -290,6 +312,26 @@ func (r *RootCmd) provisionerDaemonStart() *serpent.Command {
 			Default:       codersdk.DefaultCacheDir(),
 			Value:         serpent.StringOf(&cacheDir),
 		},
+		{
+			Flag:        "cache-max-size",
+			Env:         "CODER_PROVISIONER_DAEMON_CACHE_MAX_SIZE",
+			Description: "Maximum size in bytes of the Terraform provider and module cache. The least recently used providers and modules are evicted once it is exceeded. Negative values disable eviction.",
+			Default:     strconv.FormatInt(terraform.DefaultCacheMaxSize, 10),
+			Value:       serpent.Int64Of(&cacheMaxSize),
+		},
+		{
+			Flag:        "provisioner",
+			Env:         "CODER_PROVISIONER_DAEMON_PROVISIONER",
+			Description: "The provisioner type to advertise. Only jobs for templates that use this provisioner are acquired. The matching binary must be installed, OpenTofu isn't downloaded automatically.",
+			Default:     string(codersdk.ProvisionerTypeTerraform),
+			Value:       serpent.EnumOf(&provisioner, string(codersdk.ProvisionerTypeTerraform), string(codersdk.ProvisionerTypeTofu)),
+		},
+		{
+			Flag:        "provider-mirror",
+			Env:         "CODER_PROVISIONER_DAEMON_PROVIDER_MIRROR",
+			Description: "URL of a Terraform provider network mirror. When set, all providers are installed from the mirror instead of their origin registries. The mirror must be served over HTTPS.",
+			Value:       serpent.StringOf(&providerMirror),
+		},
 		{
 			Flag:          "tag",
 			FlagShorthand: "t",
@@ -81,7 +81,7 @@ func (*RootCmd) dbcryptRotateCmd() *serpent.Command {
 				act = "Data will be decrypted with all available keys and re-encrypted with new key."
 			}
 
-			msg := fmt.Sprintf("%s\n\n- New key: %s\n- Old keys: %s\n\nRotate external token encryption keys?\n",
+			msg := fmt.Sprintf("%s\n\n- New key: %s\n- Old keys: %s\n\nRotate database encryption keys?\n",
 				act,
 				flags.New,
 				strings.Join(flags.Old, ", "),
@@ -201,6 +201,9 @@ func (*RootCmd) dbcryptDeleteCmd() *serpent.Command {
 			msg := `All encrypted data will be deleted from the database:
 - Encrypted user OAuth access and refresh tokens
 - Encrypted user Git authentication access and refresh tokens
+- Encrypted user TOTP second factors. Affected users will need to enroll again.
+- Encrypted workspace build provisioner (Terraform) state. Affected workspaces
+  will need their state restored with "coder state push" or be recreated.
 
 Are you sure you want to continue?`
 			if _, err := cliui.Prompt(inv, cliui.PromptOptions{
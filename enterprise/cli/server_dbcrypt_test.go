@@ -12,6 +12,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
 	"github.com/coder/coder/v2/coderd/database/dbgen"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/enterprise/dbcrypt"
@@ -64,6 +65,7 @@ func TestServerDBCrypt(t *testing.T) {
 	// Validate that newly created users were encrypted with cipher A
 	for _, usr := range newUsers {
 		requireEncryptedWithCipher(ctx, t, db, cipherA[0], usr.ID)
+		requireBuildStatesEncryptedWithCipher(ctx, t, db, cipherA[0], usr.ID)
 	}
 	users = append(users, newUsers...)
 
@@ -83,6 +85,7 @@ func TestServerDBCrypt(t *testing.T) {
 	// Validate that all existing data has been encrypted with cipher A.
 	for _, usr := range users {
 		requireEncryptedWithCipher(ctx, t, db, cipherA[0], usr.ID)
+		requireBuildStatesEncryptedWithCipher(ctx, t, db, cipherA[0], usr.ID)
 	}
 
 	// Re-encrypt all existing data with a new cipher.
@@ -106,6 +109,7 @@ func TestServerDBCrypt(t *testing.T) {
 	// Validate that all data has been re-encrypted with cipher B.
 	for _, usr := range users {
 		requireEncryptedWithCipher(ctx, t, db, cipherBA[0], usr.ID)
+		requireBuildStatesEncryptedWithCipher(ctx, t, db, cipherBA[0], usr.ID)
 	}
 
 	// Assert that we can revoke the old key.
@@ -156,6 +160,7 @@ func TestServerDBCrypt(t *testing.T) {
 	// Validate that all data has been decrypted.
 	for _, usr := range users {
 		requireEncryptedWithCipher(ctx, t, db, &nullCipher{}, usr.ID)
+		requireBuildStatesEncryptedWithCipher(ctx, t, db, &nullCipher{}, usr.ID)
 	}
 
 	// Re-encrypt all existing data with a new cipher.
@@ -179,6 +184,7 @@ func TestServerDBCrypt(t *testing.T) {
 	// Validate that all data has been re-encrypted with cipher C.
 	for _, usr := range users {
 		requireEncryptedWithCipher(ctx, t, db, cipherC[0], usr.ID)
+		requireBuildStatesEncryptedWithCipher(ctx, t, db, cipherC[0], usr.ID)
 	}
 
 	// Now delete all the encrypted data.
@@ -194,7 +200,7 @@ func TestServerDBCrypt(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, pty.Close())
 
-	// Assert that no user links remain.
+	// Assert that no user links or TOTP secrets remain.
 	for _, usr := range users {
 		userLinks, err := db.GetUserLinksByUserID(ctx, usr.ID)
 		require.NoError(t, err, "failed to get user links for user %s", usr.ID)
@@ -202,6 +208,17 @@ func TestServerDBCrypt(t *testing.T) {
 		gitAuthLinks, err := db.GetExternalAuthLinksByUserID(ctx, usr.ID)
 		require.NoError(t, err, "failed to get git auth links for user %s", usr.ID)
 		require.Empty(t, gitAuthLinks)
+		_, err = db.GetUserTOTPByUserID(ctx, usr.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows, "expected no totp for user %s", usr.ID)
+	}
+
+	// Assert that no encrypted provisioner state remains.
+	states, err := db.GetWorkspaceBuildProvisionerStates(ctx, database.GetWorkspaceBuildProvisionerStatesParams{
+		LimitOpt: 1000,
+	})
+	require.NoError(t, err, "failed to get workspace build provisioner states")
+	for _, state := range states {
+		require.False(t, state.ProvisionerStateKeyID.Valid, "expected no encrypted provisioner state to remain")
 	}
 
 	// Validate that the key has been revoked in the database.
@@ -217,6 +234,7 @@ func TestServerDBCrypt(t *testing.T) {
 func genData(t *testing.T, db database.Store) []database.User {
 	t.Helper()
 	var users []database.User
+	org := dbgen.Organization(t, db, database.Organization{})
 	// Make some users
 	for _, status := range database.AllUserStatusValues() {
 		for _, loginType := range database.AllLoginTypeValues() {
@@ -235,6 +253,10 @@ func genData(t *testing.T, db database.Store) []database.User {
 					OAuthAccessToken:  "access-" + usr.ID.String(),
 					OAuthRefreshToken: "refresh-" + usr.ID.String(),
 				})
+				_ = dbgen.UserTOTP(t, db, database.UserTOTP{
+					UserID: usr.ID,
+					Secret: "totp-" + usr.ID.String(),
+				})
 				// Deleted users cannot have user_links
 				if !deleted {
 					// Fun fact: our schema allows _all_ login types to have
@@ -246,6 +268,12 @@ func genData(t *testing.T, db database.Store) []database.User {
 						OAuthAccessToken:  "access-" + usr.ID.String(),
 						OAuthRefreshToken: "refresh-" + usr.ID.String(),
 					})
+					_ = dbfake.WorkspaceBuild(t, db, database.Workspace{
+						OrganizationID: org.ID,
+						OwnerID:        usr.ID,
+					}).Seed(database.WorkspaceBuild{
+						ProvisionerState: []byte("state-" + usr.ID.String()),
+					}).Do()
 				}
 				users = append(users, usr)
 			}
@@ -288,6 +316,39 @@ func requireEncryptedWithCipher(ctx context.Context, t *testing.T, db database.S
 		require.Equal(t, c.HexDigest(), gal.OAuthAccessTokenKeyID.String)
 		require.Equal(t, c.HexDigest(), gal.OAuthRefreshTokenKeyID.String)
 	}
+	totp, err := db.GetUserTOTPByUserID(ctx, userID)
+	require.NoError(t, err, "failed to get totp for user %s", userID)
+	requireEncryptedEquals(t, c, "totp-"+userID.String(), totp.Secret)
+	require.Equal(t, c.HexDigest(), totp.SecretKeyID.String)
+}
+
+func requireBuildStatesEncryptedWithCipher(ctx context.Context, t *testing.T, db database.Store, c dbcrypt.Cipher, userID uuid.UUID) {
+	t.Helper()
+	// The provisioner state is stored as raw bytes rather than base64.
+	states, err := db.GetWorkspaceBuildProvisionerStates(ctx, database.GetWorkspaceBuildProvisionerStatesParams{
+		LimitOpt: 1000,
+	})
+	require.NoError(t, err, "failed to get workspace build provisioner states")
+	var found bool
+	for _, state := range states {
+		build, err := db.GetWorkspaceBuildByID(ctx, state.ID)
+		require.NoError(t, err, "failed to get workspace build %s", state.ID)
+		if build.InitiatorID != userID {
+			continue
+		}
+		found = true
+		val, err := c.Decrypt(state.ProvisionerState)
+		require.NoError(t, err, "failed to decrypt provisioner state")
+		require.Equal(t, "state-"+userID.String(), string(val))
+		require.Equal(t, c.HexDigest(), state.ProvisionerStateKeyID.String)
+	}
+	if found {
+		return
+	}
+	// Deleted users have no workspaces.
+	usr, err := db.GetUserByID(ctx, userID)
+	require.NoError(t, err, "failed to get user %s", userID)
+	require.True(t, usr.Deleted, "expected a workspace build for user %s", userID)
 }
 
 // nullCipher is a dbcrypt.Cipher that does not encrypt or decrypt.
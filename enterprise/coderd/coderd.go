@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -16,8 +15,10 @@ import (
 	"github.com/coder/coder/v2/coderd/appearance"
 	"github.com/coder/coder/v2/coderd/database"
 	agplportsharing "github.com/coder/coder/v2/coderd/portsharing"
+	agplproxypinning "github.com/coder/coder/v2/coderd/proxypinning"
 	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/enterprise/coderd/portsharing"
+	"github.com/coder/coder/v2/enterprise/coderd/proxypinning"
 
 	"golang.org/x/xerrors"
 	"tailscale.com/tailcfg"
@@ -132,6 +133,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	}
 	api.AGPL.Options.SetUserGroups = api.setUserGroups
 	api.AGPL.Options.SetUserSiteRoles = api.setUserSiteRoles
+	api.AGPL.Options.SetUserOrganizations = api.setUserOrganizations
 	api.AGPL.SiteHandler.RegionsFetcher = func(ctx context.Context) (any, error) {
 		// If the user can read the workspace proxy resource, return that.
 		// If not, always default to the regions.
@@ -252,6 +254,15 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			r.Post("/organizations", api.postOrganizations)
 		})
 
+		r.Group(func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				api.RequireFeatureMW(codersdk.FeatureMultipleOrganizations),
+				httpmw.RequireExperiment(api.AGPL.Experiments, codersdk.ExperimentMultiOrganization),
+			)
+			r.Post("/users/oidc/sync/dry-run", api.postOIDCSyncDryRun)
+		})
+
 		r.Group(func(r chi.Router) {
 			r.Use(
 				apiKeyMiddleware,
@@ -442,6 +453,17 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/{id}", api.scimGetUser)
 				r.Patch("/{id}", api.scimPatchUser)
 			})
+			r.Route("/Groups", func(r chi.Router) {
+				r.Get("/", api.scimGetGroups)
+				r.Post("/", api.scimPostGroup)
+				r.Get("/{id}", api.scimGetGroup)
+				r.Put("/{id}", api.scimPutGroup)
+				r.Patch("/{id}", api.scimPatchGroup)
+				r.Delete("/{id}", api.scimDeleteGroup)
+			})
+			r.Get("/ServiceProviderConfig", api.scimGetServiceProviderConfig)
+			r.Get("/ResourceTypes", api.scimGetResourceTypes)
+			r.Get("/Schemas", api.scimGetSchemas)
 		})
 	}
 
@@ -791,12 +813,15 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 	}
 
 	if initial, changed, enabled := featureChanged(codersdk.FeatureWorkspaceProxy); shouldUpdate(initial, changed, enabled) {
+		var pinner agplproxypinning.Pinner = agplproxypinning.DefaultPinner
 		if enabled {
 			fn := derpMapper(api.Logger, api.ProxyHealth)
 			api.AGPL.DERPMapper.Store(&fn)
+			pinner = proxypinning.NewEnterprisePinner(api.Database, api.AGPL.PrimaryWorkspaceProxyID(), api.AGPL.DERPMap)
 		} else {
 			api.AGPL.DERPMapper.Store(nil)
 		}
+		api.AGPL.WorkspaceProxyPinner.Store(&pinner)
 	}
 
 	if initial, changed, enabled := featureChanged(codersdk.FeatureAccessControl); shouldUpdate(initial, changed, enabled) {
@@ -960,7 +985,7 @@ func derpMapper(logger slog.Logger, proxyHealth *proxyhealth.ProxyHealth) func(*
 			// unique by the database and the computed ID is greater than any
 			// existing ID in the DERP map.
 			regionID := int(startingRegionID) + int(status.Proxy.RegionID)
-			regionCode := fmt.Sprintf("coder_%s", strings.ToLower(status.Proxy.Name))
+			regionCode := agpltailnet.WorkspaceProxyDERPRegionCode(status.Proxy.Name)
 			regionName := status.Proxy.DisplayName
 			if regionName == "" {
 				regionName = status.Proxy.Name
@@ -38,11 +38,18 @@ func Entitlements(
 		return codersdk.Entitlements{}, xerrors.Errorf("query active user count: %w", err)
 	}
 
+	// nolint:gocritic // Getting active service account count is a system function.
+	activeServiceAccountCount, err := db.GetActiveServiceAccountCount(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		return codersdk.Entitlements{}, xerrors.Errorf("query active service account count: %w", err)
+	}
+
 	// always shows active user count regardless of license
 	entitlements, err := LicensesEntitlements(now, licenses, enablements, keys, FeatureArguments{
-		ActiveUserCount:   activeUserCount,
-		ReplicaCount:      replicaCount,
-		ExternalAuthCount: externalAuthCount,
+		ActiveUserCount:           activeUserCount,
+		ActiveServiceAccountCount: activeServiceAccountCount,
+		ReplicaCount:              replicaCount,
+		ExternalAuthCount:         externalAuthCount,
 	})
 	if err != nil {
 		return entitlements, err
@@ -52,9 +59,12 @@ func Entitlements(
 }
 
 type FeatureArguments struct {
-	ActiveUserCount   int64
-	ReplicaCount      int
-	ExternalAuthCount int
+	ActiveUserCount int64
+	// ActiveServiceAccountCount is counted against the service account limit
+	// if the license has one, and against the user limit otherwise.
+	ActiveServiceAccountCount int64
+	ReplicaCount              int
+	ExternalAuthCount         int
 }
 
 // LicensesEntitlements returns the entitlements for licenses. Entitlements are
@@ -83,6 +93,11 @@ func LicensesEntitlements(
 				Enabled:     enablements[codersdk.FeatureUserLimit],
 				Actual:      &featureArguments.ActiveUserCount,
 			},
+			codersdk.FeatureServiceAccountLimit: {
+				Entitlement: codersdk.EntitlementNotEntitled,
+				Enabled:     enablements[codersdk.FeatureServiceAccountLimit],
+				Actual:      &featureArguments.ActiveServiceAccountCount,
+			},
 		},
 		Warnings: []string{},
 		Errors:   []string{},
@@ -135,8 +150,8 @@ func LicensesEntitlements(
 
 		// Add all features from the feature set defined.
 		for _, featureName := range claims.FeatureSet.Features() {
-			if featureName == codersdk.FeatureUserLimit {
-				// FeatureUserLimit is unique in that it must be specifically defined
+			if featureName.UsesLimit() {
+				// Limits are unique in that they must be specifically defined
 				// in the license. There is no default meaning if no "limit" is set.
 				continue
 			}
@@ -165,6 +180,14 @@ func LicensesEntitlements(
 					Limit:       &limit,
 					Actual:      &featureArguments.ActiveUserCount,
 				})
+			case codersdk.FeatureServiceAccountLimit:
+				limit := featureValue
+				entitlements.AddFeature(codersdk.FeatureServiceAccountLimit, codersdk.Feature{
+					Enabled:     true,
+					Entitlement: entitlement,
+					Limit:       &limit,
+					Actual:      &featureArguments.ActiveServiceAccountCount,
+				})
 			default:
 				entitlements.Features[featureName] = codersdk.Feature{
 					Entitlement: entitlement,
@@ -174,6 +197,12 @@ func LicensesEntitlements(
 		}
 	}
 
+	// Service accounts take user seats unless the license limits them
+	// separately. The user limit's actual count points to ActiveUserCount.
+	if entitlements.Features[codersdk.FeatureServiceAccountLimit].Limit == nil {
+		featureArguments.ActiveUserCount += featureArguments.ActiveServiceAccountCount
+	}
+
 	// Now the license specific warnings and errors are added to the entitlements.
 
 	// If HA is enabled, ensure the feature is entitled.
@@ -228,11 +257,22 @@ func LicensesEntitlements(
 				featureArguments.ActiveUserCount, *userLimit.Limit))
 		}
 
+		serviceAccountLimit := entitlements.Features[codersdk.FeatureServiceAccountLimit]
+		if serviceAccountLimit.Limit != nil && featureArguments.ActiveServiceAccountCount > *serviceAccountLimit.Limit {
+			entitlements.Warnings = append(entitlements.Warnings, fmt.Sprintf(
+				"Your deployment has %d active service accounts but is only licensed for %d.",
+				featureArguments.ActiveServiceAccountCount, *serviceAccountLimit.Limit))
+		} else if serviceAccountLimit.Limit != nil && serviceAccountLimit.Entitlement == codersdk.EntitlementGracePeriod {
+			entitlements.Warnings = append(entitlements.Warnings, fmt.Sprintf(
+				"Your deployment has %d active service accounts but the license with the limit %d is expired.",
+				featureArguments.ActiveServiceAccountCount, *serviceAccountLimit.Limit))
+		}
+
 		// Add a warning for every feature that is enabled but not entitled or
 		// is in a grace period.
 		for _, featureName := range codersdk.FeatureNames {
-			// The user limit has it's own warnings!
-			if featureName == codersdk.FeatureUserLimit {
+			// The user and service account limits have their own warnings!
+			if featureName.UsesLimit() {
 				continue
 			}
 			// High availability has it's own warnings based on replica count!
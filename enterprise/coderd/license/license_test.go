@@ -243,7 +243,7 @@ func TestEntitlements(t *testing.T) {
 		require.True(t, entitlements.HasLicense)
 		require.False(t, entitlements.Trial)
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 			if featureName == codersdk.FeatureHighAvailability {
@@ -375,7 +375,7 @@ func TestEntitlements(t *testing.T) {
 		// All enterprise features should be entitled
 		enterpriseFeatures := codersdk.FeatureSetEnterprise.Features()
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 			if slices.Contains(enterpriseFeatures, featureName) {
@@ -406,7 +406,7 @@ func TestEntitlements(t *testing.T) {
 		// All premium features should be entitled
 		enterpriseFeatures := codersdk.FeatureSetPremium.Features()
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 			if slices.Contains(enterpriseFeatures, featureName) {
@@ -458,7 +458,7 @@ func TestEntitlements(t *testing.T) {
 		// All enterprise features should be entitled
 		enterpriseFeatures := codersdk.FeatureSetEnterprise.Features()
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 			if slices.Contains(enterpriseFeatures, featureName) {
@@ -487,7 +487,7 @@ func TestEntitlements(t *testing.T) {
 		// All enterprise features should be entitled
 		enterpriseFeatures := codersdk.FeatureSetEnterprise.Features()
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 
@@ -520,7 +520,7 @@ func TestEntitlements(t *testing.T) {
 		// All enterprise features should be entitled
 		enterpriseFeatures := codersdk.FeatureSetEnterprise.Features()
 		for _, featureName := range codersdk.FeatureNames {
-			if featureName == codersdk.FeatureUserLimit {
+			if featureName.UsesLimit() {
 				continue
 			}
 			if slices.Contains(enterpriseFeatures, featureName) {
@@ -800,6 +800,50 @@ func TestLicenseEntitlements(t *testing.T) {
 				assert.Equalf(t, int64(50), *userFeature.Actual, "user count")
 			},
 		},
+		{
+			Name: "ServiceAccountsTakeUserSeats",
+			Licenses: []*coderdenttest.LicenseOptions{
+				enterpriseLicense().UserLimit(100),
+			},
+			Enablements: defaultEnablements,
+			Arguments: license.FeatureArguments{
+				ActiveUserCount:           95,
+				ActiveServiceAccountCount: 10,
+			},
+			AssertEntitlements: func(t *testing.T, entitlements codersdk.Entitlements) {
+				userFeature := entitlements.Features[codersdk.FeatureUserLimit]
+				assert.Equalf(t, int64(105), *userFeature.Actual, "user count")
+				serviceAccountFeature := entitlements.Features[codersdk.FeatureServiceAccountLimit]
+				assert.Equal(t, codersdk.EntitlementNotEntitled, serviceAccountFeature.Entitlement)
+				assert.Nil(t, serviceAccountFeature.Limit)
+
+				require.Len(t, entitlements.Warnings, 1)
+				require.Equal(t, "Your deployment has 105 active users but is only licensed for 100.", entitlements.Warnings[0])
+			},
+		},
+		{
+			Name: "ServiceAccountLimit",
+			Licenses: []*coderdenttest.LicenseOptions{
+				enterpriseLicense().UserLimit(100).Feature(codersdk.FeatureServiceAccountLimit, 5),
+			},
+			Enablements: defaultEnablements,
+			Arguments: license.FeatureArguments{
+				ActiveUserCount:           95,
+				ActiveServiceAccountCount: 10,
+			},
+			AssertEntitlements: func(t *testing.T, entitlements codersdk.Entitlements) {
+				assertEnterpriseFeatures(t, entitlements)
+				userFeature := entitlements.Features[codersdk.FeatureUserLimit]
+				assert.Equalf(t, int64(95), *userFeature.Actual, "user count")
+				serviceAccountFeature := entitlements.Features[codersdk.FeatureServiceAccountLimit]
+				assert.Equal(t, codersdk.EntitlementEntitled, serviceAccountFeature.Entitlement)
+				assert.Equalf(t, int64(5), *serviceAccountFeature.Limit, "service account limit")
+				assert.Equalf(t, int64(10), *serviceAccountFeature.Actual, "service account count")
+
+				require.Len(t, entitlements.Warnings, 1)
+				require.Equal(t, "Your deployment has 10 active service accounts but is only licensed for 5.", entitlements.Warnings[0])
+			},
+		},
 		{
 			Name: "EnterpriseDisabledMultiOrg",
 			Licenses: []*coderdenttest.LicenseOptions{
@@ -867,6 +911,9 @@ func assertNoWarnings(t *testing.T, entitlements codersdk.Entitlements) {
 
 func assertEnterpriseFeatures(t *testing.T, entitlements codersdk.Entitlements) {
 	for _, expected := range codersdk.FeatureSetEnterprise.Features() {
+		if expected.UsesLimit() {
+			continue
+		}
 		f := entitlements.Features[expected]
 		assert.Equalf(t, codersdk.EntitlementEntitled, f.Entitlement, "%s entitled", expected)
 		assert.Equalf(t, true, f.Enabled, "%s enabled", expected)
@@ -14,6 +14,7 @@ import (
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
 	"github.com/coder/coder/v2/coderd/httpmw"
+	agplproxypinning "github.com/coder/coder/v2/coderd/proxypinning"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -57,6 +58,26 @@ func (api *API) patchOrganization(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pinner := *api.AGPL.WorkspaceProxyPinner.Load()
+	var validErrs []codersdk.ValidationError
+	if req.AllowedWorkspaceProxyIDs != nil {
+		if err := pinner.ValidateWorkspaceProxyIDs(ctx, *req.AllowedWorkspaceProxyIDs); err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "allowed_workspace_proxy_ids", Detail: err.Error()})
+		}
+	}
+	if req.AllowedDERPRegionIDs != nil {
+		if err := pinner.ValidateDERPRegionIDs(ctx, *req.AllowedDERPRegionIDs); err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "allowed_derp_region_ids", Detail: err.Error()})
+		}
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update organization.",
+			Validations: validErrs,
+		})
+		return
+	}
+
 	err := database.ReadModifyUpdate(api.Database, func(tx database.Store) error {
 		var err error
 		organization, err = tx.GetOrganizationByID(ctx, organization.ID)
@@ -71,6 +92,9 @@ func (api *API) patchOrganization(rw http.ResponseWriter, r *http.Request) {
 			DisplayName: organization.DisplayName,
 			Description: organization.Description,
 			Icon:        organization.Icon,
+
+			AllowedWorkspaceProxyIDs: organization.AllowedWorkspaceProxyIDs,
+			AllowedDERPRegionIDs:     organization.AllowedDERPRegionIDs,
 		}
 
 		if req.Name != "" {
@@ -85,6 +109,12 @@ func (api *API) patchOrganization(rw http.ResponseWriter, r *http.Request) {
 		if req.Icon != nil {
 			updateOrgParams.Icon = *req.Icon
 		}
+		if req.AllowedWorkspaceProxyIDs != nil {
+			updateOrgParams.AllowedWorkspaceProxyIDs = append([]uuid.UUID{}, *req.AllowedWorkspaceProxyIDs...)
+		}
+		if req.AllowedDERPRegionIDs != nil {
+			updateOrgParams.AllowedDERPRegionIDs = agplproxypinning.DERPRegionIDs(*req.AllowedDERPRegionIDs)
+		}
 
 		organization, err = tx.UpdateOrganization(ctx, updateOrgParams)
 		if err != nil {
@@ -183,6 +183,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			provisionersMap[codersdk.ProvisionerTypeEcho] = struct{}{}
 		case string(codersdk.ProvisionerTypeTerraform):
 			provisionersMap[codersdk.ProvisionerTypeTerraform] = struct{}{}
+		case string(codersdk.ProvisionerTypeTofu):
+			provisionersMap[codersdk.ProvisionerTypeTofu] = struct{}{}
 		default:
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message: fmt.Sprintf("Unknown provisioner type %q", provisioner),
@@ -225,6 +227,8 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		switch p {
 		case codersdk.ProvisionerTypeTerraform:
 			provisioners = append(provisioners, database.ProvisionerTypeTerraform)
+		case codersdk.ProvisionerTypeTofu:
+			provisioners = append(provisioners, database.ProvisionerTypeTofu)
 		case codersdk.ProvisionerTypeEcho:
 			provisioners = append(provisioners, database.ProvisionerTypeEcho)
 		}
@@ -350,6 +354,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		provisionerdserver.Options{
 			ExternalAuthConfigs: api.ExternalAuthConfigs,
 			OIDCConfig:          api.OIDCConfig,
+			APIVersion:          apiVersion,
 		},
 		api.NotificationsEnqueuer,
 	)
@@ -0,0 +1,95 @@
+package proxypinning
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	agplproxypinning "github.com/coder/coder/v2/coderd/proxypinning"
+)
+
+type EnterprisePinner struct {
+	db database.Store
+	// primaryID identifies the primary proxy in allow-lists. It is the
+	// deployment ID.
+	primaryID uuid.UUID
+	derpMapFn func() *tailcfg.DERPMap
+}
+
+var _ agplproxypinning.Pinner = (*EnterprisePinner)(nil)
+
+func NewEnterprisePinner(db database.Store, primaryID uuid.UUID, derpMapFn func() *tailcfg.DERPMap) *EnterprisePinner {
+	return &EnterprisePinner{
+		db:        db,
+		primaryID: primaryID,
+		derpMapFn: derpMapFn,
+	}
+}
+
+func (p *EnterprisePinner) ValidateWorkspaceProxyIDs(ctx context.Context, ids []uuid.UUID) error {
+	// nolint:gocritic // Validating that a proxy exists does not leak anything
+	// meaningful, and template admins cannot otherwise read proxies.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	for _, id := range ids {
+		if id == p.primaryID {
+			continue
+		}
+		proxy, err := p.db.GetWorkspaceProxyByID(ctx, id)
+		if xerrors.Is(err, sql.ErrNoRows) || (err == nil && proxy.Deleted) {
+			return xerrors.Errorf("workspace proxy %q does not exist.", id)
+		}
+		if err != nil {
+			return xerrors.Errorf("get workspace proxy %q: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (p *EnterprisePinner) ValidateDERPRegionIDs(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Proxy regions are removed from the DERP map while the proxy is
+	// unhealthy, so accept the regions of all registered proxies as well.
+	known := map[int]struct{}{}
+	if derpMap := p.derpMapFn(); derpMap != nil {
+		for id := range derpMap.Regions {
+			known[id] = struct{}{}
+		}
+	}
+	// nolint:gocritic // See ValidateWorkspaceProxyIDs.
+	proxies, err := p.db.GetWorkspaceProxies(dbauthz.AsSystemRestricted(ctx))
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get workspace proxies: %w", err)
+	}
+	for _, proxy := range proxies {
+		known[int(proxy.RegionID)] = struct{}{}
+	}
+
+	for _, id := range ids {
+		if _, ok := known[id]; !ok {
+			return xerrors.Errorf("DERP region %d does not exist.", id)
+		}
+	}
+	return nil
+}
+
+func (p *EnterprisePinner) WorkspacePins(ctx context.Context, workspace database.Workspace) (agplproxypinning.Pins, error) {
+	// nolint:gocritic // Pins are enforced regardless of who is connecting.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	template, err := p.db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return agplproxypinning.Pins{}, xerrors.Errorf("get template: %w", err)
+	}
+	organization, err := p.db.GetOrganizationByID(ctx, workspace.OrganizationID)
+	if err != nil {
+		return agplproxypinning.Pins{}, xerrors.Errorf("get organization: %w", err)
+	}
+	return agplproxypinning.New(template, organization), nil
+}
@@ -1,10 +1,16 @@
 package coderd
 
 import (
+	"context"
 	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -12,14 +18,17 @@ import (
 	scimjson "github.com/imulab/go-scim/pkg/v2/json"
 	"github.com/imulab/go-scim/pkg/v2/service"
 	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	agpl "github.com/coder/coder/v2/coderd"
 	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/httpmw"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -346,3 +355,1065 @@ func (api *API) scimPatchUser(rw http.ResponseWriter, r *http.Request) {
 	aReq.New = dbUser
 	httpapi.Write(ctx, rw, http.StatusOK, sUser)
 }
+
+const (
+	scimSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimSchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	scimSchemaSchema                = "urn:ietf:params:scim:schemas:core:2.0:Schema"
+
+	scimMaxResultsPerPage = 100
+)
+
+// scimErrUnauthorized is returned when the request does not include the SCIM
+// API key.
+var scimErrUnauthorized = &spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"}
+
+// scimWriteError writes a SCIM error with the status and type of the given
+// prototype. handlerutil.WriteError only picks up the status from wrapped
+// errors, anything else is reported as an internal error.
+func scimWriteError(rw http.ResponseWriter, prototype *spec.Error, format string, args ...interface{}) {
+	_ = handlerutil.WriteError(rw, xerrors.Errorf("%s: %w", fmt.Sprintf(format, args...), prototype))
+}
+
+// SCIMGroup is a Coder group as represented by SCIM. The SCIM display name
+// maps to the group name, which is also what OIDC group sync matches on, so
+// both keep the same groups up to date.
+type SCIMGroup struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members,omitempty"`
+	Meta        struct {
+		ResourceType string `json:"resourceType"`
+	} `json:"meta"`
+}
+
+type SCIMGroupMember struct {
+	// Value is the ID of the user, as returned when the user was created
+	// through SCIM.
+	Value   string `json:"value" format:"uuid"`
+	Display string `json:"display,omitempty"`
+}
+
+type SCIMListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMPatchOperation struct {
+	// Op is one of "add", "remove" or "replace". Entra ID capitalizes these,
+	// so they are matched case insensitively.
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+func scimListResponse(resources []interface{}, total, startIndex int) SCIMListResponse {
+	return SCIMListResponse{
+		Schemas:      []string{scimSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}
+}
+
+func scimGroup(group database.Group, members []database.GroupMember, excludeMembers bool) SCIMGroup {
+	sGroup := SCIMGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+	}
+	sGroup.Meta.ResourceType = "Group"
+	if excludeMembers {
+		return sGroup
+	}
+	for _, member := range members {
+		sGroup.Members = append(sGroup.Members, SCIMGroupMember{
+			Value:   member.UserID.String(),
+			Display: member.UserUsername,
+		})
+	}
+	return sGroup
+}
+
+// scimExcludeMembers reports whether the client asked for group members to
+// be left out of the response. Entra ID does this to avoid listing large
+// groups.
+func scimExcludeMembers(r *http.Request) bool {
+	for _, attr := range strings.Split(r.URL.Query().Get("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attr), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// scimFilterTerm is a single `attribute eq "value"` comparison. Attribute is
+// lowercase, and `members.value` is shortened to `members`.
+type scimFilterTerm struct {
+	Attribute string
+	Value     string
+}
+
+var (
+	scimFilterTermRegex  = regexp.MustCompile(`(?i)^([a-z][a-z0-9.]*)(?:\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]|\s+eq\s+("(?:[^"\\]|\\.)*"))\s*`)
+	scimFilterAndRegex   = regexp.MustCompile(`(?i)^and\s+`)
+	scimMemberPathRegex  = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+	scimFilterAttributes = []string{"id", "displayname", "members"}
+)
+
+// parseSCIMFilter parses the subset of SCIM filters that Okta and Entra ID
+// send for groups: `eq` comparisons on id, displayName and members joined
+// by `and`. `members[value eq "..."]` is accepted as an alias of
+// `members eq "..."`.
+func parseSCIMFilter(filter string) ([]scimFilterTerm, error) {
+	var terms []scimFilterTerm
+	rest := strings.TrimSpace(filter)
+	for rest != "" {
+		if len(terms) > 0 {
+			and := scimFilterAndRegex.FindString(rest)
+			if and == "" {
+				return nil, xerrors.Errorf("expected \"and\" at %q: %w", rest, spec.ErrInvalidFilter)
+			}
+			rest = rest[len(and):]
+		}
+
+		match := scimFilterTermRegex.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, xerrors.Errorf("unsupported filter %q: %w", rest, spec.ErrInvalidFilter)
+		}
+		rest = rest[len(match[0]):]
+
+		attr := strings.ToLower(match[1])
+		literal := match[3]
+		if match[2] != "" {
+			if attr != "members" {
+				return nil, xerrors.Errorf("unsupported value filter on %q: %w", match[1], spec.ErrInvalidFilter)
+			}
+			literal = match[2]
+		}
+		if attr == "members.value" {
+			attr = "members"
+		}
+		if !slices.Contains(scimFilterAttributes, attr) {
+			return nil, xerrors.Errorf("unsupported filter attribute %q: %w", match[1], spec.ErrInvalidFilter)
+		}
+
+		var value string
+		if err := json.Unmarshal([]byte(literal), &value); err != nil {
+			return nil, xerrors.Errorf("invalid filter value %s: %w", literal, spec.ErrInvalidFilter)
+		}
+		terms = append(terms, scimFilterTerm{Attribute: attr, Value: value})
+	}
+	return terms, nil
+}
+
+// scimGroupMemberIDs parses the user IDs of SCIM group members.
+func scimGroupMemberIDs(members []SCIMGroupMember) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			return nil, xerrors.Errorf("member %q is not a valid user ID: %w", member.Value, spec.ErrInvalidValue)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// scimGroupParam returns the group in the URL. Only groups of the default
+// organization are managed through SCIM, and the Everyone group is never
+// exposed since its membership is implicit.
+func (api *API) scimGroupParam(ctx context.Context, rw http.ResponseWriter, r *http.Request) (database.Group, bool) {
+	//nolint:gocritic // needed for SCIM
+	org, err := api.Database.GetDefaultOrganization(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return database.Group{}, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		scimWriteError(rw, spec.ErrNotFound, "group %q not found", chi.URLParam(r, "id"))
+		return database.Group{}, false
+	}
+
+	//nolint:gocritic // needed for SCIM
+	group, err := api.Database.GetGroupByID(dbauthz.AsSystemRestricted(ctx), id)
+	if httpapi.Is404Error(err) || (err == nil && (group.OrganizationID != org.ID || group.IsEveryone())) {
+		scimWriteError(rw, spec.ErrNotFound, "group %q not found", id)
+		return database.Group{}, false
+	}
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return database.Group{}, false
+	}
+	return group, true
+}
+
+// scimGroupManaged reports whether SCIM may change the group. Groups created
+// by hand or by OIDC group sync can be read, but are left to whoever manages
+// them.
+func scimGroupManaged(rw http.ResponseWriter, group database.Group) bool {
+	if group.Source != database.GroupSourceScim {
+		scimWriteError(rw, spec.ErrMutability, "group %q is not managed by SCIM", group.Name)
+		return false
+	}
+	return true
+}
+
+// scimValidateGroupName checks a SCIM display name before it is used as a
+// group name. Like OIDC group sync, any other name is accepted as is.
+func scimValidateGroupName(name string) error {
+	if name == "" {
+		return xerrors.Errorf("displayName is required: %w", spec.ErrInvalidValue)
+	}
+	if name == database.EveryoneGroup {
+		return xerrors.Errorf("%q is a reserved group name: %w", name, spec.ErrUniqueness)
+	}
+	return nil
+}
+
+// scimUpdateGroup renames the group and changes its members to exactly the
+// given users. It returns the updated group and its members.
+func (api *API) scimUpdateGroup(ctx context.Context, group database.Group, name string, memberIDs []uuid.UUID) (database.Group, []database.GroupMember, error) {
+	if err := scimValidateGroupName(name); err != nil {
+		return database.Group{}, nil, err
+	}
+	if name != group.Name {
+		_, err := api.Database.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+			OrganizationID: group.OrganizationID,
+			Name:           name,
+		})
+		if err == nil {
+			return database.Group{}, nil, xerrors.Errorf("a group named %q already exists: %w", name, spec.ErrUniqueness)
+		}
+		if !httpapi.Is404Error(err) {
+			return database.Group{}, nil, xerrors.Errorf("get group by name: %w", err)
+		}
+	}
+
+	currentMembers, err := api.Database.GetGroupMembersByGroupID(ctx, group.ID)
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("get group members: %w", err)
+	}
+	current := make(map[uuid.UUID]struct{}, len(currentMembers))
+	for _, member := range currentMembers {
+		current[member.UserID] = struct{}{}
+	}
+	desired := make(map[uuid.UUID]struct{}, len(memberIDs))
+	for _, id := range memberIDs {
+		desired[id] = struct{}{}
+	}
+
+	var add, remove []uuid.UUID
+	for id := range desired {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		_, err := database.ExpectOne(api.Database.OrganizationMembers(ctx, database.OrganizationMembersParams{
+			OrganizationID: group.OrganizationID,
+			UserID:         id,
+		}))
+		if xerrors.Is(err, sql.ErrNoRows) {
+			return database.Group{}, nil, xerrors.Errorf("user %q is not a member of the organization: %w", id, spec.ErrInvalidValue)
+		}
+		if err != nil {
+			return database.Group{}, nil, xerrors.Errorf("get organization member: %w", err)
+		}
+		add = append(add, id)
+	}
+	for id := range current {
+		if _, ok := desired[id]; !ok {
+			remove = append(remove, id)
+		}
+	}
+
+	err = api.Database.InTx(func(tx database.Store) error {
+		if name != group.Name {
+			group, err = tx.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
+				ID:             group.ID,
+				Name:           name,
+				DisplayName:    group.DisplayName,
+				AvatarURL:      group.AvatarURL,
+				QuotaAllowance: group.QuotaAllowance,
+			})
+			if err != nil {
+				return xerrors.Errorf("update group: %w", err)
+			}
+		}
+		for _, id := range add {
+			err := tx.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+				GroupID: group.ID,
+				UserID:  id,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert group member %q: %w", id, err)
+			}
+		}
+		for _, id := range remove {
+			err := tx.DeleteGroupMemberFromGroup(ctx, database.DeleteGroupMemberFromGroupParams{
+				GroupID: group.ID,
+				UserID:  id,
+			})
+			if err != nil {
+				return xerrors.Errorf("delete group member %q: %w", id, err)
+			}
+		}
+		return nil
+	}, nil)
+	if database.IsUniqueViolation(err, database.UniqueGroupsNameOrganizationIDKey) {
+		return database.Group{}, nil, xerrors.Errorf("a group named %q already exists: %w", name, spec.ErrUniqueness)
+	}
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+
+	members, err := api.Database.GetGroupMembersByGroupID(ctx, group.ID)
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("get group members: %w", err)
+	}
+	return group, members, nil
+}
+
+// scimAuditGroup audits a group change. SCIM user changes are attributed to
+// the user being changed, but group changes have no user to attribute them
+// to, so they are recorded as automatic changes by Coder instead.
+func (api *API) scimAuditGroup(r *http.Request, status int, action database.AuditAction, oldGroup, newGroup database.AuditableGroup) {
+	fields, err := json.Marshal(SCIMAuditAdditionalFields)
+	if err != nil {
+		api.Logger.Warn(r.Context(), "marshal scim audit fields", slog.Error(err))
+		fields = nil
+	}
+	orgID := newGroup.OrganizationID
+	if action == database.AuditActionDelete {
+		orgID = oldGroup.OrganizationID
+	}
+
+	audit.BackgroundAudit(r.Context(), &audit.BackgroundAuditParams[database.AuditableGroup]{
+		Audit:            *api.AGPL.Auditor.Load(),
+		Log:              api.Logger,
+		RequestID:        httpmw.RequestID(r),
+		Status:           status,
+		Action:           action,
+		OrganizationID:   orgID,
+		IP:               r.RemoteAddr,
+		AdditionalFields: fields,
+		Old:              oldGroup,
+		New:              newGroup,
+	})
+}
+
+// scimGetGroups lists the groups of the default organization.
+//
+// @Summary SCIM 2.0: Get groups
+// @ID scim-get-groups
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param filter query string false "Filter, e.g. displayName eq \"name\""
+// @Param startIndex query int false "1-based index of the first result"
+// @Param count query int false "Maximum number of results"
+// @Param excludedAttributes query string false "Set to \"members\" to omit group members"
+// @Success 200 {object} coderd.SCIMListResponse
+// @Router /scim/v2/Groups [get]
+func (api *API) scimGetGroups(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	terms, err := parseSCIMFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	startIndex := 1
+	count := scimMaxResultsPerPage
+	for param, value := range map[string]*int{"startIndex": &startIndex, "count": &count} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			scimWriteError(rw, spec.ErrInvalidValue, "%s must be an integer", param)
+			return
+		}
+		*value = v
+	}
+	// Out of range values are interpreted as the closest valid value,
+	// as required by RFC 7644.
+	startIndex = max(startIndex, 1)
+	count = min(max(count, 0), scimMaxResultsPerPage)
+
+	//nolint:gocritic // needed for SCIM
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	org, err := api.Database.GetDefaultOrganization(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	params := database.GetGroupsParams{OrganizationID: org.ID}
+	for _, term := range terms {
+		if term.Attribute != "members" {
+			continue
+		}
+		memberID, err := uuid.Parse(term.Value)
+		if err != nil || (params.HasMemberID != uuid.Nil && params.HasMemberID != memberID) {
+			// No group can match the filter.
+			params.HasMemberID = uuid.New()
+			continue
+		}
+		params.HasMemberID = memberID
+	}
+	groups, err := api.Database.GetGroups(ctx, params)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	groups = slices.DeleteFunc(groups, func(group database.Group) bool {
+		if group.IsEveryone() {
+			return true
+		}
+		for _, term := range terms {
+			switch term.Attribute {
+			case "id":
+				if group.ID.String() != strings.ToLower(term.Value) {
+					return true
+				}
+			case "displayname":
+				if !strings.EqualFold(group.Name, term.Value) {
+					return true
+				}
+			}
+		}
+		return false
+	})
+	slices.SortFunc(groups, func(a, b database.Group) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	page := groups[min(startIndex-1, len(groups)):]
+	page = page[:min(count, len(page))]
+	excludeMembers := scimExcludeMembers(r)
+	resources := make([]interface{}, 0, len(page))
+	for _, group := range page {
+		var members []database.GroupMember
+		if !excludeMembers {
+			members, err = api.Database.GetGroupMembersByGroupID(ctx, group.ID)
+			if err != nil {
+				_ = handlerutil.WriteError(rw, err)
+				return
+			}
+		}
+		resources = append(resources, scimGroup(group, members, excludeMembers))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, scimListResponse(resources, len(groups), startIndex))
+}
+
+// @Summary SCIM 2.0: Get group by ID
+// @ID scim-get-group-by-id
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param excludedAttributes query string false "Set to \"members\" to omit group members"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [get]
+func (api *API) scimGetGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	group, ok := api.scimGroupParam(ctx, rw, r)
+	if !ok {
+		return
+	}
+
+	var members []database.GroupMember
+	excludeMembers := scimExcludeMembers(r)
+	if !excludeMembers {
+		var err error
+		//nolint:gocritic // needed for SCIM
+		members, err = api.Database.GetGroupMembersByGroupID(dbauthz.AsSystemRestricted(ctx), group.ID)
+		if err != nil {
+			_ = handlerutil.WriteError(rw, err)
+			return
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, scimGroup(group, members, excludeMembers))
+}
+
+// scimPostGroup creates a new group. Groups that already exist, for example
+// because they were created by hand or by OIDC group sync, are never taken
+// over.
+//
+// @Summary SCIM 2.0: Create new group
+// @ID scim-create-new-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param request body coderd.SCIMGroup true "New group"
+// @Success 201 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups [post]
+func (api *API) scimPostGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimWriteError(rw, spec.ErrInvalidSyntax, "decode group: %s", err)
+		return
+	}
+	if err := scimValidateGroupName(sGroup.DisplayName); err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	memberIDs, err := scimGroupMemberIDs(sGroup.Members)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	// TODO: Like users, SCIM groups always belong to the default
+	//	organization until SCIM supports multi-org deployments.
+	//nolint:gocritic // needed for SCIM
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	org, err := api.Database.GetDefaultOrganization(ctx)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	created, err := api.Database.InsertMissingGroups(ctx, database.InsertMissingGroupsParams{
+		OrganizationID: org.ID,
+		GroupNames:     []string{sGroup.DisplayName},
+		Source:         database.GroupSourceScim,
+	})
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	if len(created) != 1 {
+		scimWriteError(rw, spec.ErrUniqueness, "a group named %q already exists", sGroup.DisplayName)
+		return
+	}
+
+	group, members, err := api.scimUpdateGroup(ctx, created[0], sGroup.DisplayName, memberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	api.scimAuditGroup(r, http.StatusCreated, database.AuditActionCreate, database.AuditableGroup{}, group.Auditable(members))
+	httpapi.Write(ctx, rw, http.StatusCreated, scimGroup(group, members, false))
+}
+
+// scimPutGroup replaces the name and members of a group.
+//
+// @Summary SCIM 2.0: Replace group
+// @ID scim-replace-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMGroup true "Replace group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [put]
+func (api *API) scimPutGroup(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimWriteError(rw, spec.ErrInvalidSyntax, "decode group: %s", err)
+		return
+	}
+	memberIDs, err := scimGroupMemberIDs(sGroup.Members)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	api.scimReplaceGroup(rw, r, func(database.Group, []uuid.UUID) (string, []uuid.UUID, error) {
+		return sGroup.DisplayName, memberIDs, nil
+	})
+}
+
+// scimPatchGroup renames groups and adds, removes or replaces group members.
+//
+// @Summary SCIM 2.0: Update group
+// @ID scim-update-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMPatchRequest true "Patch group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [patch]
+func (api *API) scimPatchGroup(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	var req SCIMPatchRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		scimWriteError(rw, spec.ErrInvalidSyntax, "decode patch request: %s", err)
+		return
+	}
+
+	api.scimReplaceGroup(rw, r, func(group database.Group, memberIDs []uuid.UUID) (string, []uuid.UUID, error) {
+		name := group.Name
+		members := make(map[uuid.UUID]struct{}, len(memberIDs))
+		for _, id := range memberIDs {
+			members[id] = struct{}{}
+		}
+		for _, op := range req.Operations {
+			err := applySCIMGroupPatch(op, &name, members)
+			if err != nil {
+				return "", nil, err
+			}
+		}
+		return name, maps.Keys(members), nil
+	})
+}
+
+// scimReplaceGroup updates the group in the URL to the name and members
+// returned by update, which receives the current state of the group.
+func (api *API) scimReplaceGroup(rw http.ResponseWriter, r *http.Request, update func(group database.Group, memberIDs []uuid.UUID) (string, []uuid.UUID, error)) {
+	ctx := r.Context()
+	group, ok := api.scimGroupParam(ctx, rw, r)
+	if !ok || !scimGroupManaged(rw, group) {
+		return
+	}
+
+	//nolint:gocritic // needed for SCIM
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	oldMembers, err := api.Database.GetGroupMembersByGroupID(ctx, group.ID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	oldMemberIDs := make([]uuid.UUID, 0, len(oldMembers))
+	for _, member := range oldMembers {
+		oldMemberIDs = append(oldMemberIDs, member.UserID)
+	}
+	name, memberIDs, err := update(group, oldMemberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	newGroup, members, err := api.scimUpdateGroup(ctx, group, name, memberIDs)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+	changed := newGroup.Name != group.Name || len(members) != len(oldMembers)
+	for _, member := range members {
+		if !slices.Contains(oldMemberIDs, member.UserID) {
+			changed = true
+		}
+	}
+	// Do not push an audit log if there is no change.
+	if changed {
+		api.scimAuditGroup(r, http.StatusOK, database.AuditActionWrite, group.Auditable(oldMembers), newGroup.Auditable(members))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, scimGroup(newGroup, members, false))
+}
+
+// applySCIMGroupPatch applies a single PATCH operation to the name and
+// members of a group.
+func applySCIMGroupPatch(op SCIMPatchOperation, name *string, members map[uuid.UUID]struct{}) error {
+	opName := strings.ToLower(op.Op)
+	path := strings.ToLower(strings.TrimSpace(op.Path))
+
+	// Operations without a path carry the attributes to change as an
+	// object, e.g. Okta renames groups with
+	// {"op": "replace", "value": {"id": "...", "displayName": "..."}}.
+	if path == "" && opName != "remove" {
+		var value struct {
+			DisplayName *string            `json:"displayName"`
+			Members     *[]SCIMGroupMember `json:"members"`
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return xerrors.Errorf("invalid %s value: %w", op.Op, spec.ErrInvalidValue)
+		}
+		if value.DisplayName != nil {
+			*name = *value.DisplayName
+		}
+		if value.Members != nil {
+			ids, err := scimGroupMemberIDs(*value.Members)
+			if err != nil {
+				return err
+			}
+			if opName == "replace" {
+				clear(members)
+			}
+			for _, id := range ids {
+				members[id] = struct{}{}
+			}
+		}
+		return nil
+	}
+
+	switch opName {
+	case "add", "replace":
+		switch path {
+		case "displayname":
+			if err := json.Unmarshal(op.Value, name); err != nil {
+				return xerrors.Errorf("displayName must be a string: %w", spec.ErrInvalidValue)
+			}
+		case "members":
+			var value []SCIMGroupMember
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return xerrors.Errorf("members must be a list of members: %w", spec.ErrInvalidValue)
+			}
+			ids, err := scimGroupMemberIDs(value)
+			if err != nil {
+				return err
+			}
+			if opName == "replace" {
+				clear(members)
+			}
+			for _, id := range ids {
+				members[id] = struct{}{}
+			}
+		case "externalid":
+			// External IDs are not stored, groups are matched by name.
+		default:
+			return xerrors.Errorf("unsupported path %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+	case "remove":
+		if path == "members" {
+			// Entra ID lists the members to remove in the value, while a
+			// remove without a value removes all members.
+			if len(op.Value) == 0 || string(op.Value) == "null" {
+				clear(members)
+				return nil
+			}
+			var value []SCIMGroupMember
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return xerrors.Errorf("members must be a list of members: %w", spec.ErrInvalidValue)
+			}
+			ids, err := scimGroupMemberIDs(value)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				delete(members, id)
+			}
+			return nil
+		}
+
+		// Okta removes members one at a time with a value filter.
+		match := scimMemberPathRegex.FindStringSubmatch(strings.TrimSpace(op.Path))
+		if match == nil {
+			return xerrors.Errorf("unsupported path %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+		var value string
+		if err := json.Unmarshal([]byte(match[1]), &value); err != nil {
+			return xerrors.Errorf("unsupported path %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return xerrors.Errorf("member %q is not a valid user ID: %w", value, spec.ErrInvalidValue)
+		}
+		delete(members, id)
+	default:
+		return xerrors.Errorf("unsupported operation %q: %w", op.Op, spec.ErrInvalidSyntax)
+	}
+	return nil
+}
+
+// @Summary SCIM 2.0: Delete group
+// @ID scim-delete-group
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204
+// @Router /scim/v2/Groups/{id} [delete]
+func (api *API) scimDeleteGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	group, ok := api.scimGroupParam(ctx, rw, r)
+	if !ok || !scimGroupManaged(rw, group) {
+		return
+	}
+
+	//nolint:gocritic // needed for SCIM
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	members, err := api.Database.GetGroupMembersByGroupID(ctx, group.ID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	err = api.Database.DeleteGroupByID(ctx, group.ID)
+	if err != nil {
+		_ = handlerutil.WriteError(rw, err)
+		return
+	}
+
+	api.scimAuditGroup(r, http.StatusNoContent, database.AuditActionDelete, group.Auditable(members), database.AuditableGroup{})
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimSupported struct {
+	Supported bool `json:"supported"`
+}
+
+type scimServiceProviderConfig struct {
+	Schemas          []string      `json:"schemas"`
+	DocumentationURI string        `json:"documentationUri"`
+	Patch            scimSupported `json:"patch"`
+	Bulk             struct {
+		Supported      bool `json:"supported"`
+		MaxOperations  int  `json:"maxOperations"`
+		MaxPayloadSize int  `json:"maxPayloadSize"`
+	} `json:"bulk"`
+	Filter struct {
+		Supported  bool `json:"supported"`
+		MaxResults int  `json:"maxResults"`
+	} `json:"filter"`
+	ChangePassword        scimSupported `json:"changePassword"`
+	Sort                  scimSupported `json:"sort"`
+	ETag                  scimSupported `json:"etag"`
+	AuthenticationSchemes []struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"authenticationSchemes"`
+	Meta scimMeta `json:"meta"`
+}
+
+type scimResourceType struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Endpoint    string   `json:"endpoint"`
+	Description string   `json:"description"`
+	Schema      string   `json:"schema"`
+	Meta        scimMeta `json:"meta"`
+}
+
+type scimSchema struct {
+	Schemas     []string              `json:"schemas"`
+	ID          string                `json:"id"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Attributes  []scimSchemaAttribute `json:"attributes"`
+	Meta        scimMeta              `json:"meta"`
+}
+
+type scimSchemaAttribute struct {
+	Name          string                `json:"name"`
+	Type          string                `json:"type"`
+	MultiValued   bool                  `json:"multiValued"`
+	Required      bool                  `json:"required"`
+	CaseExact     bool                  `json:"caseExact"`
+	Mutability    string                `json:"mutability"`
+	Returned      string                `json:"returned"`
+	Uniqueness    string                `json:"uniqueness"`
+	SubAttributes []scimSchemaAttribute `json:"subAttributes,omitempty"`
+}
+
+// scimAttribute returns a single valued, optional, read-write attribute
+// which callers adjust as needed.
+func scimAttribute(name, typ string) scimSchemaAttribute {
+	return scimSchemaAttribute{
+		Name:       name,
+		Type:       typ,
+		Mutability: "readWrite",
+		Returned:   "default",
+		Uniqueness: "none",
+	}
+}
+
+func (api *API) scimLocation(path string) string {
+	return api.AccessURL.JoinPath("/scim/v2", path).String()
+}
+
+// @Summary SCIM 2.0: Get service provider config
+// @ID scim-get-service-provider-config
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Success 200
+// @Router /scim/v2/ServiceProviderConfig [get]
+func (api *API) scimGetServiceProviderConfig(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	config := scimServiceProviderConfig{
+		Schemas:          []string{scimSchemaServiceProviderConfig},
+		DocumentationURI: "https://coder.com/docs/admin/auth#scim-enterprise",
+		Patch:            scimSupported{Supported: true},
+		Meta: scimMeta{
+			ResourceType: "ServiceProviderConfig",
+			Location:     api.scimLocation("/ServiceProviderConfig"),
+		},
+	}
+	config.Filter.Supported = true
+	config.Filter.MaxResults = scimMaxResultsPerPage
+	config.AuthenticationSchemes = append(config.AuthenticationSchemes, struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}{
+		Type:        "httpheader",
+		Name:        "HTTP Header",
+		Description: "The SCIM API key sent as the value of the Authorization header.",
+	})
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, config)
+}
+
+// @Summary SCIM 2.0: Get resource types
+// @ID scim-get-resource-types
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Success 200 {object} coderd.SCIMListResponse
+// @Router /scim/v2/ResourceTypes [get]
+func (api *API) scimGetResourceTypes(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	resources := []interface{}{
+		scimResourceType{
+			Schemas:     []string{scimSchemaResourceType},
+			ID:          "User",
+			Name:        "User",
+			Endpoint:    "/Users",
+			Description: "Coder users",
+			Schema:      scimSchemaUser,
+			Meta:        scimMeta{ResourceType: "ResourceType", Location: api.scimLocation("/ResourceTypes/User")},
+		},
+		scimResourceType{
+			Schemas:     []string{scimSchemaResourceType},
+			ID:          "Group",
+			Name:        "Group",
+			Endpoint:    "/Groups",
+			Description: "Coder groups of the default organization",
+			Schema:      scimSchemaGroup,
+			Meta:        scimMeta{ResourceType: "ResourceType", Location: api.scimLocation("/ResourceTypes/Group")},
+		},
+	}
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, scimListResponse(resources, len(resources), 1))
+}
+
+// scimGetSchemas describes the attributes supported by SCIMUser and
+// SCIMGroup. Other attributes are ignored.
+//
+// @Summary SCIM 2.0: Get schemas
+// @ID scim-get-schemas
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Success 200 {object} coderd.SCIMListResponse
+// @Router /scim/v2/Schemas [get]
+func (api *API) scimGetSchemas(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		scimWriteError(rw, scimErrUnauthorized, "invalid authorization")
+		return
+	}
+
+	userName := scimAttribute("userName", "string")
+	userName.Required = true
+	userName.Uniqueness = "server"
+	name := scimAttribute("name", "complex")
+	name.SubAttributes = []scimSchemaAttribute{
+		scimAttribute("givenName", "string"),
+		scimAttribute("familyName", "string"),
+	}
+	emailValue := scimAttribute("value", "string")
+	emails := scimAttribute("emails", "complex")
+	emails.MultiValued = true
+	emails.Required = true
+	emails.SubAttributes = []scimSchemaAttribute{
+		emailValue,
+		scimAttribute("type", "string"),
+		scimAttribute("display", "string"),
+		scimAttribute("primary", "boolean"),
+	}
+
+	displayName := scimAttribute("displayName", "string")
+	displayName.Required = true
+	displayName.Uniqueness = "server"
+	memberValue := scimAttribute("value", "string")
+	memberValue.CaseExact = true
+	memberValue.Mutability = "immutable"
+	memberDisplay := scimAttribute("display", "string")
+	memberDisplay.Mutability = "readOnly"
+	members := scimAttribute("members", "complex")
+	members.MultiValued = true
+	members.SubAttributes = []scimSchemaAttribute{memberValue, memberDisplay}
+
+	resources := []interface{}{
+		scimSchema{
+			Schemas:     []string{scimSchemaSchema},
+			ID:          scimSchemaUser,
+			Name:        "User",
+			Description: "User Account",
+			Attributes:  []scimSchemaAttribute{userName, name, emails, scimAttribute("active", "boolean")},
+			Meta:        scimMeta{ResourceType: "Schema", Location: api.scimLocation("/Schemas/" + scimSchemaUser)},
+		},
+		scimSchema{
+			Schemas:     []string{scimSchemaSchema},
+			ID:          scimSchemaGroup,
+			Name:        "Group",
+			Description: "Group",
+			Attributes:  []scimSchemaAttribute{displayName, members},
+			Meta:        scimMeta{ResourceType: "Schema", Location: api.scimLocation("/Schemas/" + scimSchemaGroup)},
+		},
+	}
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, scimListResponse(resources, len(resources), 1))
+}
@@ -0,0 +1,216 @@
+package coderd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseSCIMFilter(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		Name          string
+		Filter        string
+		Expected      []scimFilterTerm
+		ExpectedError bool
+	}{
+		{
+			Name:   "Empty",
+			Filter: "",
+		},
+		{
+			Name:     "DisplayName",
+			Filter:   `displayName eq "Engineering Team"`,
+			Expected: []scimFilterTerm{{Attribute: "displayname", Value: "Engineering Team"}},
+		},
+		{
+			Name:     "Uppercase",
+			Filter:   `DISPLAYNAME EQ "a \"quoted\" name"`,
+			Expected: []scimFilterTerm{{Attribute: "displayname", Value: `a "quoted" name`}},
+		},
+		{
+			Name:   "IDAndMembers",
+			Filter: `id eq "abc" and members eq "def"`,
+			Expected: []scimFilterTerm{
+				{Attribute: "id", Value: "abc"},
+				{Attribute: "members", Value: "def"},
+			},
+		},
+		{
+			Name:     "MembersValue",
+			Filter:   `members.value eq "def"`,
+			Expected: []scimFilterTerm{{Attribute: "members", Value: "def"}},
+		},
+		{
+			Name:     "MembersValueFilter",
+			Filter:   `members[value eq "def"]`,
+			Expected: []scimFilterTerm{{Attribute: "members", Value: "def"}},
+		},
+		{
+			Name:          "Or",
+			Filter:        `id eq "abc" or id eq "def"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "UnsupportedOperator",
+			Filter:        `displayName co "eng"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "UnsupportedAttribute",
+			Filter:        `externalId eq "abc"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "Unquoted",
+			Filter:        `displayName eq eng`,
+			ExpectedError: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Parallel()
+
+			terms, err := parseSCIMFilter(tt.Filter)
+			if tt.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.Expected, terms)
+		})
+	}
+}
+
+func Test_applySCIMGroupPatch(t *testing.T) {
+	t.Parallel()
+
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	members := func(ids ...uuid.UUID) string {
+		m := make([]SCIMGroupMember, 0, len(ids))
+		for _, id := range ids {
+			m = append(m, SCIMGroupMember{Value: id.String()})
+		}
+		raw, err := json.Marshal(m)
+		require.NoError(t, err)
+		return string(raw)
+	}
+
+	testcases := []struct {
+		Name            string
+		Op              string
+		Path            string
+		Value           string
+		ExpectedName    string
+		ExpectedMembers []uuid.UUID
+		ExpectedError   bool
+	}{
+		{
+			Name:            "AddMembers",
+			Op:              "add",
+			Path:            "members",
+			Value:           members(bob, carol),
+			ExpectedMembers: []uuid.UUID{alice, bob, carol},
+		},
+		{
+			Name:            "RemoveMembersByValue",
+			Op:              "Remove",
+			Path:            "members",
+			Value:           members(alice),
+			ExpectedMembers: []uuid.UUID{bob},
+		},
+		{
+			Name:            "RemoveMemberByFilter",
+			Op:              "remove",
+			Path:            `members[value eq "` + bob.String() + `"]`,
+			ExpectedMembers: []uuid.UUID{alice},
+		},
+		{
+			Name: "RemoveAllMembers",
+			Op:   "remove",
+			Path: "members",
+		},
+		{
+			Name:            "ReplaceMembers",
+			Op:              "replace",
+			Path:            "members",
+			Value:           members(carol),
+			ExpectedMembers: []uuid.UUID{carol},
+		},
+		{
+			Name:            "ReplaceDisplayName",
+			Op:              "Replace",
+			Path:            "displayName",
+			Value:           `"renamed"`,
+			ExpectedName:    "renamed",
+			ExpectedMembers: []uuid.UUID{alice, bob},
+		},
+		{
+			Name:            "ReplaceWithoutPath",
+			Op:              "replace",
+			Value:           `{"id": "ignored", "displayName": "renamed"}`,
+			ExpectedName:    "renamed",
+			ExpectedMembers: []uuid.UUID{alice, bob},
+		},
+		{
+			Name:            "AddWithoutPath",
+			Op:              "add",
+			Value:           `{"members": ` + members(carol) + `}`,
+			ExpectedMembers: []uuid.UUID{alice, bob, carol},
+		},
+		{
+			Name:          "InvalidMember",
+			Op:            "add",
+			Path:          "members",
+			Value:         `[{"value": "not-a-uuid"}]`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "UnsupportedPath",
+			Op:            "replace",
+			Path:          "owner",
+			Value:         `"someone"`,
+			ExpectedError: true,
+		},
+		{
+			Name:          "UnsupportedOp",
+			Op:            "move",
+			Path:          "members",
+			ExpectedError: true,
+		},
+	}
+
+	for _, tt := range testcases {
+		tt := tt
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Parallel()
+
+			name := "group"
+			set := map[uuid.UUID]struct{}{alice: {}, bob: {}}
+			err := applySCIMGroupPatch(SCIMPatchOperation{
+				Op:    tt.Op,
+				Path:  tt.Path,
+				Value: json.RawMessage(tt.Value),
+			}, &name, set)
+			if tt.ExpectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			if tt.ExpectedName == "" {
+				tt.ExpectedName = "group"
+			}
+			require.Equal(t, tt.ExpectedName, name)
+			require.Len(t, set, len(tt.ExpectedMembers))
+			for _, id := range tt.ExpectedMembers {
+				require.Contains(t, set, id)
+			}
+		})
+	}
+}
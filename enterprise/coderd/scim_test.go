@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -56,6 +58,20 @@ func setScimAuth(key []byte) func(*http.Request) {
 	}
 }
 
+// scimRequest makes a SCIM request and decodes the response into resp, if
+// set. It returns the status code.
+func scimRequest(ctx context.Context, t testing.TB, client *codersdk.Client, key []byte, method, path string, body, resp interface{}) int {
+	t.Helper()
+
+	res, err := client.Request(ctx, method, path, body, setScimAuth(key))
+	require.NoError(t, err)
+	defer res.Body.Close()
+	if resp != nil && res.StatusCode < 300 {
+		require.NoError(t, json.NewDecoder(res.Body).Decode(resp))
+	}
+	return res.StatusCode
+}
+
 //nolint:gocritic // SCIM authenticates via a special header and bypasses internal RBAC.
 func TestScim(t *testing.T) {
 	t.Parallel()
@@ -455,4 +471,250 @@ func TestScim(t *testing.T) {
 			require.Equal(t, codersdk.UserStatusActive, scimUser.Status, "user is still active")
 		})
 	})
+
+	t.Run("groups", func(t *testing.T) {
+		t.Parallel()
+
+		t.Run("noAuth", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			client, _ := coderdenttest.New(t, &coderdenttest.Options{
+				SCIMAPIKey: []byte("hi"),
+				LicenseOptions: &coderdenttest.LicenseOptions{
+					AccountID: "coolin",
+					Features: license.Features{
+						codersdk.FeatureSCIM: 1,
+					},
+				},
+			})
+
+			status := scimRequest(ctx, t, client, []byte("wrong"), "GET", "/scim/v2/Groups", nil, nil)
+			assert.Equal(t, http.StatusUnauthorized, status)
+		})
+
+		t.Run("OK", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			scimAPIKey := []byte("hi")
+			mockAudit := audit.NewMock()
+			client, _ := coderdenttest.New(t, &coderdenttest.Options{
+				Options:      &coderdtest.Options{Auditor: mockAudit},
+				SCIMAPIKey:   scimAPIKey,
+				AuditLogging: true,
+				LicenseOptions: &coderdenttest.LicenseOptions{
+					AccountID: "coolin",
+					Features: license.Features{
+						codersdk.FeatureSCIM:         1,
+						codersdk.FeatureAuditLog:     1,
+						codersdk.FeatureTemplateRBAC: 1,
+					},
+				},
+			})
+
+			alice, bob := makeScimUser(t), makeScimUser(t)
+			require.Equal(t, http.StatusOK, scimRequest(ctx, t, client, scimAPIKey, "POST", "/scim/v2/Users", alice, &alice))
+			require.Equal(t, http.StatusOK, scimRequest(ctx, t, client, scimAPIKey, "POST", "/scim/v2/Users", bob, &bob))
+			mockAudit.ResetLogs()
+
+			// Create a group with a single member.
+			var sGroup coderd.SCIMGroup
+			status := scimRequest(ctx, t, client, scimAPIKey, "POST", "/scim/v2/Groups", coderd.SCIMGroup{
+				DisplayName: "Engineering Team",
+				Members:     []coderd.SCIMGroupMember{{Value: alice.ID}},
+			}, &sGroup)
+			require.Equal(t, http.StatusCreated, status)
+			require.Equal(t, "Engineering Team", sGroup.DisplayName)
+			require.Len(t, sGroup.Members, 1)
+			require.Equal(t, alice.ID, sGroup.Members[0].Value)
+
+			aLogs := mockAudit.AuditLogs()
+			require.Len(t, aLogs, 1)
+			assert.Equal(t, database.AuditActionCreate, aLogs[0].Action)
+			assert.Equal(t, database.ResourceTypeGroup, aLogs[0].ResourceType)
+
+			group, err := client.Group(ctx, uuid.MustParse(sGroup.ID))
+			require.NoError(t, err)
+			require.Equal(t, "Engineering Team", group.Name)
+			require.Equal(t, codersdk.GroupSourceSCIM, group.Source)
+			require.Len(t, group.Members, 1)
+
+			// Okta looks groups up by name before pushing them.
+			var list coderd.SCIMListResponse
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups?filter="+url.QueryEscape(`displayName eq "Engineering Team"`), nil, &list)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, 1, list.TotalResults)
+
+			// Add and remove members the way Okta does.
+			mockAudit.ResetLogs()
+			status = scimRequest(ctx, t, client, scimAPIKey, "PATCH", "/scim/v2/Groups/"+sGroup.ID, coderd.SCIMPatchRequest{
+				Operations: []coderd.SCIMPatchOperation{
+					{Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "` + bob.ID + `"}]`)},
+					{Op: "remove", Path: `members[value eq "` + alice.ID + `"]`},
+				},
+			}, &sGroup)
+			require.Equal(t, http.StatusOK, status)
+			require.Len(t, sGroup.Members, 1)
+			require.Equal(t, bob.ID, sGroup.Members[0].Value)
+			require.Len(t, mockAudit.AuditLogs(), 1)
+
+			// Entra ID checks membership with a filter.
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups?excludedAttributes=members&filter="+url.QueryEscape(`id eq "`+sGroup.ID+`" and members eq "`+bob.ID+`"`), nil, &list)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, 1, list.TotalResults)
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups?filter="+url.QueryEscape(`members eq "`+alice.ID+`"`), nil, &list)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, 0, list.TotalResults)
+
+			// Rename the group the way Entra ID does.
+			status = scimRequest(ctx, t, client, scimAPIKey, "PATCH", "/scim/v2/Groups/"+sGroup.ID, coderd.SCIMPatchRequest{
+				Operations: []coderd.SCIMPatchOperation{
+					{Op: "Replace", Path: "displayName", Value: json.RawMessage(`"Platform Team"`)},
+				},
+			}, &sGroup)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, "Platform Team", sGroup.DisplayName)
+
+			// A patch without changes is not audited.
+			mockAudit.ResetLogs()
+			status = scimRequest(ctx, t, client, scimAPIKey, "PATCH", "/scim/v2/Groups/"+sGroup.ID, coderd.SCIMPatchRequest{
+				Operations: []coderd.SCIMPatchOperation{
+					{Op: "add", Path: "members", Value: json.RawMessage(`[{"value": "` + bob.ID + `"}]`)},
+				},
+			}, &sGroup)
+			require.Equal(t, http.StatusOK, status)
+			require.Empty(t, mockAudit.AuditLogs())
+
+			// Replace the group entirely.
+			status = scimRequest(ctx, t, client, scimAPIKey, "PUT", "/scim/v2/Groups/"+sGroup.ID, coderd.SCIMGroup{
+				DisplayName: "Platform Team",
+				Members:     []coderd.SCIMGroupMember{{Value: alice.ID}, {Value: bob.ID}},
+			}, &sGroup)
+			require.Equal(t, http.StatusOK, status)
+			require.Len(t, sGroup.Members, 2)
+
+			group, err = client.Group(ctx, uuid.MustParse(sGroup.ID))
+			require.NoError(t, err)
+			require.Equal(t, "Platform Team", group.Name)
+			require.Len(t, group.Members, 2)
+
+			// Delete the group.
+			mockAudit.ResetLogs()
+			status = scimRequest(ctx, t, client, scimAPIKey, "DELETE", "/scim/v2/Groups/"+sGroup.ID, nil, nil)
+			require.Equal(t, http.StatusNoContent, status)
+			aLogs = mockAudit.AuditLogs()
+			require.Len(t, aLogs, 1)
+			assert.Equal(t, database.AuditActionDelete, aLogs[0].Action)
+
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups/"+sGroup.ID, nil, nil)
+			require.Equal(t, http.StatusNotFound, status)
+		})
+
+		t.Run("ExistingGroup", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			scimAPIKey := []byte("hi")
+			client, first := coderdenttest.New(t, &coderdenttest.Options{
+				SCIMAPIKey: scimAPIKey,
+				LicenseOptions: &coderdenttest.LicenseOptions{
+					AccountID: "coolin",
+					Features: license.Features{
+						codersdk.FeatureSCIM:         1,
+						codersdk.FeatureTemplateRBAC: 1,
+					},
+				},
+			})
+
+			group, err := client.CreateGroup(ctx, first.OrganizationID, codersdk.CreateGroupRequest{Name: "existing"})
+			require.NoError(t, err)
+
+			// Groups that were not created through SCIM are never taken over.
+			status := scimRequest(ctx, t, client, scimAPIKey, "POST", "/scim/v2/Groups", coderd.SCIMGroup{DisplayName: "existing"}, nil)
+			require.Equal(t, http.StatusConflict, status)
+
+			// They can be read, but not changed or deleted.
+			var sGroup coderd.SCIMGroup
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups/"+group.ID.String(), nil, &sGroup)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, "existing", sGroup.DisplayName)
+			status = scimRequest(ctx, t, client, scimAPIKey, "PUT", "/scim/v2/Groups/"+group.ID.String(), coderd.SCIMGroup{DisplayName: "renamed"}, nil)
+			require.Equal(t, http.StatusBadRequest, status)
+			status = scimRequest(ctx, t, client, scimAPIKey, "PATCH", "/scim/v2/Groups/"+group.ID.String(), coderd.SCIMPatchRequest{
+				Operations: []coderd.SCIMPatchOperation{
+					{Op: "Replace", Path: "displayName", Value: json.RawMessage(`"renamed"`)},
+				},
+			}, nil)
+			require.Equal(t, http.StatusBadRequest, status)
+			status = scimRequest(ctx, t, client, scimAPIKey, "DELETE", "/scim/v2/Groups/"+group.ID.String(), nil, nil)
+			require.Equal(t, http.StatusBadRequest, status)
+
+			group, err = client.Group(ctx, group.ID)
+			require.NoError(t, err)
+			require.Equal(t, "existing", group.Name)
+
+			// The Everyone group is never exposed.
+			var list coderd.SCIMListResponse
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups", nil, &list)
+			require.Equal(t, http.StatusOK, status)
+			require.Equal(t, 1, list.TotalResults)
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups/"+first.OrganizationID.String(), nil, nil)
+			require.Equal(t, http.StatusNotFound, status)
+			status = scimRequest(ctx, t, client, scimAPIKey, "POST", "/scim/v2/Groups", coderd.SCIMGroup{DisplayName: database.EveryoneGroup}, nil)
+			require.Equal(t, http.StatusConflict, status)
+		})
+
+		t.Run("InvalidFilter", func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			scimAPIKey := []byte("hi")
+			client, _ := coderdenttest.New(t, &coderdenttest.Options{
+				SCIMAPIKey: scimAPIKey,
+				LicenseOptions: &coderdenttest.LicenseOptions{
+					AccountID: "coolin",
+					Features: license.Features{
+						codersdk.FeatureSCIM:     1,
+						codersdk.FeatureAuditLog: 1,
+					},
+				},
+			})
+
+			status := scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/Groups?filter="+url.QueryEscape(`displayName sw "eng"`), nil, nil)
+			require.Equal(t, http.StatusBadRequest, status)
+		})
+	})
+
+	t.Run("discovery", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		scimAPIKey := []byte("hi")
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			SCIMAPIKey: scimAPIKey,
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				AccountID: "coolin",
+				Features: license.Features{
+					codersdk.FeatureSCIM: 1,
+				},
+			},
+		})
+
+		var config struct {
+			Patch struct {
+				Supported bool `json:"supported"`
+			} `json:"patch"`
+		}
+		status := scimRequest(ctx, t, client, scimAPIKey, "GET", "/scim/v2/ServiceProviderConfig", nil, &config)
+		require.Equal(t, http.StatusOK, status)
+		require.True(t, config.Patch.Supported)
+
+		for _, path := range []string{"/scim/v2/ResourceTypes", "/scim/v2/Schemas"} {
+			var list coderd.SCIMListResponse
+			status = scimRequest(ctx, t, client, scimAPIKey, "GET", path, nil, &list)
+			require.Equal(t, http.StatusOK, status, path)
+			require.Equal(t, 2, list.TotalResults, path)
+		}
+	})
 }
@@ -2,13 +2,23 @@ package coderd
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
+	"github.com/coder/coder/v2/coderd/httpapi"
+	"github.com/coder/coder/v2/coderd/rbac"
+	"github.com/coder/coder/v2/coderd/rbac/policy"
 	"github.com/coder/coder/v2/codersdk"
 )
 
@@ -25,19 +35,16 @@ func (api *API) setUserGroups(ctx context.Context, logger slog.Logger, db databa
 	return db.InTx(func(tx database.Store) error {
 		// When setting the user's groups, it's easier to just clear their groups and re-add them.
 		// This ensures that the user's groups are always in sync with the auth provider.
-		orgs, err := tx.GetOrganizationsByUserID(ctx, userID)
+		// nolint:gocritic // Requires system context to remove user from all groups.
+		err := tx.RemoveUserFromAllGroups(dbauthz.AsSystemRestricted(ctx), userID)
 		if err != nil {
-			return xerrors.Errorf("get user orgs: %w", err)
-		}
-		if len(orgs) != 1 {
-			return xerrors.Errorf("expected 1 org, got %d", len(orgs))
+			return xerrors.Errorf("delete user groups: %w", err)
 		}
 
-		// Delete all groups the user belongs to.
-		// nolint:gocritic // Requires system context to remove user from all groups.
-		err = tx.RemoveUserFromAllGroups(dbauthz.AsSystemRestricted(ctx), userID)
+		// nolint:gocritic // Requires system context to read the default organization.
+		defaultOrg, err := tx.GetDefaultOrganization(dbauthz.AsSystemRestricted(ctx))
 		if err != nil {
-			return xerrors.Errorf("delete user groups: %w", err)
+			return xerrors.Errorf("get default organization: %w", err)
 		}
 
 		// TODO: This could likely be improved by making these single queries.
@@ -45,8 +52,11 @@ func (api *API) setUserGroups(ctx context.Context, logger slog.Logger, db databa
 		//	inefficient if there are a lot of organizations. There was deployments
 		//	on v1 with >100 orgs.
 		for orgID, groupNames := range orgGroupNames {
-			// Create the missing groups for each organization.
-			if createMissingGroups {
+			// The auth provider does not say which organization a group
+			// belongs to, so missing groups are only created in the default
+			// organization. Other organizations only assign groups that
+			// already exist there.
+			if createMissingGroups && orgID == defaultOrg.ID {
 				// This is the system creating these additional groups, so we use the system restricted context.
 				// nolint:gocritic
 				created, err := tx.InsertMissingGroups(dbauthz.AsSystemRestricted(ctx), database.InsertMissingGroupsParams{
@@ -106,3 +116,341 @@ func (api *API) setUserSiteRoles(ctx context.Context, logger slog.Logger, db dat
 		return nil
 	}, nil)
 }
+
+func (api *API) organizationSyncEnabled() bool {
+	api.entitlementsMu.RLock()
+	enabled := api.entitlements.Features[codersdk.FeatureMultipleOrganizations].Enabled
+	api.entitlementsMu.RUnlock()
+
+	return enabled && api.AGPL.Experiments.Enabled(codersdk.ExperimentMultiOrganization)
+}
+
+// setUserOrganizations syncs the organization memberships of the user. db is
+// usually the transaction of the login, so the membership changes are only
+// audited when the returned commitAudit function is called after it commits.
+func (api *API) setUserOrganizations(ctx context.Context, logger slog.Logger, db database.Store, userID uuid.UUID, organizations []string) (func(), error) {
+	if !api.organizationSyncEnabled() {
+		logger.Warn(ctx, "attempted to assign OIDC organizations without multiple organizations enabled, organizations left unchanged",
+			slog.F("user_id", userID), slog.F("organizations", organizations),
+		)
+		return func() {}, nil
+	}
+
+	var added, removed []database.AuditableOrganizationMember
+	err := db.InTx(func(tx database.Store) error {
+		added, removed = nil, nil
+		sync, err := resolveOrganizationSync(ctx, tx, userID, organizations)
+		if err != nil {
+			return err
+		}
+		user, err := tx.GetUserByID(ctx, userID)
+		if err != nil {
+			return xerrors.Errorf("get user: %w", err)
+		}
+		memberships, err := tx.OrganizationMembers(ctx, database.OrganizationMembersParams{
+			UserID: userID,
+		})
+		if err != nil {
+			return xerrors.Errorf("get organization memberships: %w", err)
+		}
+		if len(sync.unmatched) > 0 {
+			logger.Debug(ctx, "OIDC organizations ignored in assignment",
+				slog.F("user_id", userID),
+				slog.F("ignored", sync.unmatched),
+			)
+		}
+
+		for _, org := range sync.add {
+			member, err := tx.InsertOrganizationMember(ctx, database.InsertOrganizationMemberParams{
+				OrganizationID: org.ID,
+				UserID:         userID,
+				CreatedAt:      dbtime.Now(),
+				UpdatedAt:      dbtime.Now(),
+				Roles:          []string{},
+			})
+			if err != nil {
+				return xerrors.Errorf("add user to organization %s: %w", org.ID, err)
+			}
+			added = append(added, member.Auditable(user.Username))
+		}
+
+		for _, org := range sync.remove {
+			// Group memberships are not removed along with the organization
+			// membership, so they have to be cleaned up first.
+			groups, err := tx.GetGroups(ctx, database.GetGroupsParams{
+				OrganizationID: org.ID,
+				HasMemberID:    userID,
+			})
+			if err != nil {
+				return xerrors.Errorf("get user groups in organization %s: %w", org.ID, err)
+			}
+			for _, group := range groups {
+				if group.IsEveryone() {
+					continue
+				}
+				err = tx.DeleteGroupMemberFromGroup(ctx, database.DeleteGroupMemberFromGroupParams{
+					UserID:  userID,
+					GroupID: group.ID,
+				})
+				if err != nil {
+					return xerrors.Errorf("remove user from group %s: %w", group.ID, err)
+				}
+			}
+
+			err = tx.DeleteOrganizationMember(ctx, database.DeleteOrganizationMemberParams{
+				OrganizationID: org.ID,
+				UserID:         userID,
+			})
+			if err != nil {
+				return xerrors.Errorf("remove user from organization %s: %w", org.ID, err)
+			}
+			for _, member := range memberships {
+				if member.OrganizationMember.OrganizationID == org.ID {
+					removed = append(removed, member.OrganizationMember.Auditable(user.Username))
+				}
+			}
+		}
+
+		if len(sync.add) > 0 || len(sync.remove) > 0 {
+			logger.Debug(ctx, "synced OIDC organizations",
+				slog.F("user_id", userID),
+				slog.F("added", len(sync.add)),
+				slog.F("removed", len(sync.remove)),
+			)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		for _, member := range added {
+			api.auditOrganizationSync(ctx, database.AuditActionCreate, database.AuditableOrganizationMember{}, member)
+		}
+		for _, member := range removed {
+			api.auditOrganizationSync(ctx, database.AuditActionDelete, member, database.AuditableOrganizationMember{})
+		}
+	}, nil
+}
+
+// auditOrganizationSync audits an organization membership that was added or
+// removed by OIDC organization sync. The change is attributed to the user
+// that logged in.
+func (api *API) auditOrganizationSync(ctx context.Context, action database.AuditAction, oldMember, newMember database.AuditableOrganizationMember) {
+	member := newMember
+	if action == database.AuditActionDelete {
+		member = oldMember
+	}
+	audit.BackgroundAudit(ctx, &audit.BackgroundAuditParams[database.AuditableOrganizationMember]{
+		Audit:          *api.AGPL.Auditor.Load(),
+		Log:            api.Logger,
+		UserID:         member.UserID,
+		Status:         http.StatusOK,
+		Action:         action,
+		OrganizationID: member.OrganizationID,
+		Old:            oldMember,
+		New:            newMember,
+	})
+}
+
+// organizationSync is the difference between a user's current organization
+// memberships and the organizations they should be a member of.
+type organizationSync struct {
+	keep   []database.Organization
+	add    []database.Organization
+	remove []database.Organization
+	// unmatched are the requested organizations that do not exist.
+	unmatched []string
+}
+
+// resolveOrganizationSync resolves organization IDs or names and compares them
+// to the user's current memberships. The default organization is always kept.
+// A nil userID is treated as a user without any memberships.
+func resolveOrganizationSync(ctx context.Context, db database.Store, userID uuid.UUID, organizations []string) (organizationSync, error) {
+	var sync organizationSync
+
+	all, err := db.GetOrganizations(ctx)
+	if err != nil {
+		return sync, xerrors.Errorf("get organizations: %w", err)
+	}
+
+	want := make(map[uuid.UUID]bool)
+	for _, org := range all {
+		if org.IsDefault {
+			want[org.ID] = true
+		}
+	}
+	for _, ident := range organizations {
+		idx := slices.IndexFunc(all, func(org database.Organization) bool {
+			return org.ID.String() == ident || strings.EqualFold(org.Name, ident)
+		})
+		if idx < 0 {
+			sync.unmatched = append(sync.unmatched, ident)
+			continue
+		}
+		want[all[idx].ID] = true
+	}
+
+	have := make(map[uuid.UUID]bool)
+	if userID != uuid.Nil {
+		current, err := db.GetOrganizationsByUserID(ctx, userID)
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return sync, xerrors.Errorf("get user organizations: %w", err)
+		}
+		for _, org := range current {
+			have[org.ID] = true
+		}
+	}
+
+	for _, org := range all {
+		switch {
+		case want[org.ID] && have[org.ID]:
+			sync.keep = append(sync.keep, org)
+		case want[org.ID]:
+			sync.add = append(sync.add, org)
+		case have[org.ID]:
+			sync.remove = append(sync.remove, org)
+		}
+	}
+	return sync, nil
+}
+
+// @Summary Preview OIDC claim sync
+// @ID preview-oidc-claim-sync
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param request body codersdk.OIDCSyncDryRunRequest true "OIDC claims"
+// @Success 200 {object} codersdk.OIDCSyncDryRunResponse
+// @Router /users/oidc/sync/dry-run [post]
+func (api *API) postOIDCSyncDryRun(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !api.Authorize(r, policy.ActionRead, rbac.ResourceDeploymentConfig) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.OIDCSyncDryRunRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if api.AGPL.OIDCConfig == nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "OIDC is not configured.",
+		})
+		return
+	}
+
+	claimSync, err := api.AGPL.OIDCClaimSync(ctx, req.Claims)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The claims would fail to log in.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if req.UserID != uuid.Nil {
+		_, err = api.Database.GetUserByID(ctx, req.UserID)
+		if httpapi.Is404Error(err) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("User %q does not exist.", req.UserID),
+			})
+			return
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+
+	var orgSync organizationSync
+	switch {
+	case claimSync.UsingOrganizations:
+		orgSync, err = resolveOrganizationSync(ctx, api.Database, req.UserID, claimSync.Organizations)
+	case req.UserID != uuid.Nil:
+		// Without organization sync, memberships are left as they are.
+		orgSync.keep, err = api.Database.GetOrganizationsByUserID(ctx, req.UserID)
+		if xerrors.Is(err, sql.ErrNoRows) {
+			err = nil
+		}
+	default:
+		// New users only join the default organization.
+		orgSync, err = resolveOrganizationSync(ctx, api.Database, uuid.Nil, nil)
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	resp := codersdk.OIDCSyncDryRunResponse{
+		OrganizationSyncEnabled: claimSync.UsingOrganizations,
+		GroupSyncEnabled:        claimSync.UsingGroups,
+		Organizations:           []codersdk.OIDCSyncDryRunOrganization{},
+		UnmatchedOrganizations:  orgSync.unmatched,
+	}
+	if resp.UnmatchedOrganizations == nil {
+		resp.UnmatchedOrganizations = []string{}
+	}
+	for action, orgs := range map[codersdk.OIDCSyncAction][]database.Organization{
+		codersdk.OIDCSyncActionKeep:   orgSync.keep,
+		codersdk.OIDCSyncActionAdd:    orgSync.add,
+		codersdk.OIDCSyncActionRemove: orgSync.remove,
+	} {
+		for _, org := range orgs {
+			groups := []string{}
+			if claimSync.UsingGroups && action != codersdk.OIDCSyncActionRemove {
+				groups, err = api.oidcSyncGroups(ctx, org, claimSync.Groups)
+				if err != nil {
+					httpapi.InternalServerError(rw, err)
+					return
+				}
+			}
+			resp.Organizations = append(resp.Organizations, codersdk.OIDCSyncDryRunOrganization{
+				ID:     org.ID,
+				Name:   org.Name,
+				Action: action,
+				Groups: groups,
+			})
+		}
+	}
+	slices.SortFunc(resp.Organizations, func(a, b codersdk.OIDCSyncDryRunOrganization) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// oidcSyncGroups returns the groups a user would be a member of in an
+// organization, given the group names from their claims. Missing groups are
+// only created in the default organization.
+func (api *API) oidcSyncGroups(ctx context.Context, org database.Organization, groupNames []string) ([]string, error) {
+	groups := []string{}
+	if api.AGPL.OIDCConfig.CreateMissingGroups && org.IsDefault {
+		for _, name := range groupNames {
+			if !slices.Contains(groups, name) {
+				groups = append(groups, name)
+			}
+		}
+		slices.Sort(groups)
+		return groups, nil
+	}
+
+	existing, err := api.Database.GetGroups(ctx, database.GetGroupsParams{
+		OrganizationID: org.ID,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get groups: %w", err)
+	}
+	for _, group := range existing {
+		if !group.IsEveryone() && slices.Contains(groupNames, group.Name) {
+			groups = append(groups, group.Name)
+		}
+	}
+	slices.Sort(groups)
+	return groups, nil
+}
@@ -7,15 +7,19 @@ import (
 	"testing"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/coderd"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/coderdtest/ldaptest"
 	"github.com/coder/coder/v2/coderd/coderdtest/oidctest"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/ldapauth"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/coderd/util/slice"
 	"github.com/coder/coder/v2/codersdk"
@@ -421,6 +425,211 @@ func TestUserOIDC(t *testing.T) {
 		})
 	})
 
+	t.Run("Organizations", func(t *testing.T) {
+		t.Parallel()
+
+		multiOrg := func(dv *codersdk.DeploymentValues) {
+			dv.Experiments = []string{string(codersdk.ExperimentMultiOrganization)}
+		}
+
+		// orgMemberAudits returns the organizations whose membership was
+		// changed with the given action.
+		orgMemberAudits := func(runner *oidcTestRunner, action database.AuditAction) []uuid.UUID {
+			var orgIDs []uuid.UUID
+			for _, alog := range runner.Auditor.AuditLogs() {
+				if alog.ResourceType == database.ResourceTypeOrganizationMember && alog.Action == action {
+					orgIDs = append(orgIDs, alog.OrganizationID)
+				}
+			}
+			return orgIDs
+		}
+
+		// AddThenRemove syncs the user into orgs from a mapped claim, then
+		// removes them from an org dropped from the claim along with their
+		// groups in it.
+		t.Run("AddThenRemove", func(t *testing.T) {
+			t.Parallel()
+
+			const orgClaim = "custom-orgs"
+			const groupName = "bingbong"
+			const missingGroupName = "bongbing"
+			runner := setupOIDCTest(t, oidcTestConfig{
+				Config: func(cfg *coderd.OIDCConfig) {
+					cfg.AllowSignups = true
+					cfg.GroupField = "groups"
+					cfg.CreateMissingGroups = true
+					cfg.OrganizationField = orgClaim
+					cfg.OrganizationMapping = map[string][]string{
+						"engineering": {"alpha", "beta"},
+					}
+				},
+				DeploymentValues: multiOrg,
+			})
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			alpha, err := runner.AdminClient.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "alpha"})
+			require.NoError(t, err)
+			beta, err := runner.AdminClient.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "beta"})
+			require.NoError(t, err)
+			alphaGroup, err := runner.AdminClient.CreateGroup(ctx, alpha.ID, codersdk.CreateGroupRequest{Name: groupName})
+			require.NoError(t, err)
+
+			_, resp := runner.Login(t, jwt.MapClaims{
+				"email":  "alice@coder.com",
+				orgClaim: []string{"engineering"},
+				"groups": []string{groupName, missingGroupName},
+			})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			runner.AssertOrganizations(t, "alice", []uuid.UUID{runner.AdminUser.OrganizationIDs[0], alpha.ID, beta.ID})
+			require.ElementsMatch(t, []uuid.UUID{alpha.ID, beta.ID}, orgMemberAudits(runner, database.AuditActionCreate))
+
+			alphaGroup, err = runner.AdminClient.Group(ctx, alphaGroup.ID)
+			require.NoError(t, err)
+			require.Len(t, alphaGroup.Members, 1)
+
+			// Missing groups are only created in the default org.
+			_, err = runner.AdminClient.GroupByOrgAndName(ctx, runner.AdminUser.OrganizationIDs[0], missingGroupName)
+			require.NoError(t, err)
+			for _, orgID := range []uuid.UUID{alpha.ID, beta.ID} {
+				_, err = runner.AdminClient.GroupByOrgAndName(ctx, orgID, missingGroupName)
+				require.Error(t, err)
+			}
+
+			// Unmapped values are matched by ID directly.
+			_, resp = runner.Login(t, jwt.MapClaims{
+				"email":  "alice@coder.com",
+				orgClaim: []string{beta.ID.String()},
+				"groups": []string{groupName},
+			})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			runner.AssertOrganizations(t, "alice", []uuid.UUID{runner.AdminUser.OrganizationIDs[0], beta.ID})
+			require.Equal(t, []uuid.UUID{alpha.ID}, orgMemberAudits(runner, database.AuditActionDelete))
+
+			alphaGroup, err = runner.AdminClient.Group(ctx, alphaGroup.ID)
+			require.NoError(t, err)
+			require.Len(t, alphaGroup.Members, 0)
+
+			// The default org is never removed.
+			_, resp = runner.Login(t, jwt.MapClaims{
+				"email": "alice@coder.com",
+			})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			runner.AssertOrganizations(t, "alice", []uuid.UUID{runner.AdminUser.OrganizationIDs[0]})
+		})
+
+		// ExperimentDisabled leaves memberships alone without the
+		// multi-organization experiment.
+		t.Run("ExperimentDisabled", func(t *testing.T) {
+			t.Parallel()
+
+			const orgClaim = "custom-orgs"
+			runner := setupOIDCTest(t, oidcTestConfig{
+				Config: func(cfg *coderd.OIDCConfig) {
+					cfg.AllowSignups = true
+					cfg.OrganizationField = orgClaim
+				},
+			})
+
+			_, resp := runner.Login(t, jwt.MapClaims{
+				"email":  "alice@coder.com",
+				orgClaim: []string{"alpha"},
+			})
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			runner.AssertOrganizations(t, "alice", []uuid.UUID{runner.AdminUser.OrganizationIDs[0]})
+		})
+
+		t.Run("InvalidClaim", func(t *testing.T) {
+			t.Parallel()
+
+			const orgClaim = "custom-orgs"
+			runner := setupOIDCTest(t, oidcTestConfig{
+				Config: func(cfg *coderd.OIDCConfig) {
+					cfg.AllowSignups = true
+					cfg.OrganizationField = orgClaim
+				},
+				DeploymentValues: multiOrg,
+			})
+
+			_, resp := runner.AttemptLogin(t, jwt.MapClaims{
+				"email":  "alice@coder.com",
+				orgClaim: 42,
+			})
+			require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		})
+
+		t.Run("DryRun", func(t *testing.T) {
+			t.Parallel()
+
+			const orgClaim = "custom-orgs"
+			const groupName = "bingbong"
+			runner := setupOIDCTest(t, oidcTestConfig{
+				Config: func(cfg *coderd.OIDCConfig) {
+					cfg.AllowSignups = true
+					cfg.GroupField = "groups"
+					cfg.OrganizationField = orgClaim
+				},
+				DeploymentValues: multiOrg,
+			})
+
+			ctx := testutil.Context(t, testutil.WaitLong)
+			defaultOrg, err := runner.AdminClient.Organization(ctx, runner.AdminUser.OrganizationIDs[0])
+			require.NoError(t, err)
+			alpha, err := runner.AdminClient.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "alpha"})
+			require.NoError(t, err)
+			beta, err := runner.AdminClient.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{Name: "beta"})
+			require.NoError(t, err)
+			_, err = runner.AdminClient.CreateGroup(ctx, alpha.ID, codersdk.CreateGroupRequest{Name: groupName})
+			require.NoError(t, err)
+
+			memberClient, member := coderdtest.CreateAnotherUser(t, runner.AdminClient, defaultOrg.ID)
+			_, err = runner.AdminClient.PostOrganizationMember(ctx, beta.ID, member.ID.String())
+			require.NoError(t, err)
+
+			claims := map[string]interface{}{
+				orgClaim: []string{"alpha", "gamma"},
+				"groups": []string{groupName},
+			}
+
+			// A new user joins the claimed orgs.
+			res, err := runner.AdminClient.OIDCSyncDryRun(ctx, codersdk.OIDCSyncDryRunRequest{Claims: claims})
+			require.NoError(t, err)
+			require.True(t, res.OrganizationSyncEnabled)
+			require.True(t, res.GroupSyncEnabled)
+			require.Equal(t, []string{"gamma"}, res.UnmatchedOrganizations)
+			require.Equal(t, []codersdk.OIDCSyncDryRunOrganization{
+				{ID: alpha.ID, Name: alpha.Name, Action: codersdk.OIDCSyncActionAdd, Groups: []string{groupName}},
+				{ID: defaultOrg.ID, Name: defaultOrg.Name, Action: codersdk.OIDCSyncActionAdd, Groups: []string{}},
+			}, res.Organizations)
+
+			// An existing user is compared against their memberships.
+			res, err = runner.AdminClient.OIDCSyncDryRun(ctx, codersdk.OIDCSyncDryRunRequest{Claims: claims, UserID: member.ID})
+			require.NoError(t, err)
+			require.Equal(t, []codersdk.OIDCSyncDryRunOrganization{
+				{ID: alpha.ID, Name: alpha.Name, Action: codersdk.OIDCSyncActionAdd, Groups: []string{groupName}},
+				{ID: beta.ID, Name: beta.Name, Action: codersdk.OIDCSyncActionRemove, Groups: []string{}},
+				{ID: defaultOrg.ID, Name: defaultOrg.Name, Action: codersdk.OIDCSyncActionKeep, Groups: []string{}},
+			}, res.Organizations)
+
+			// Nothing was changed.
+			orgs, err := runner.AdminClient.OrganizationsByUser(ctx, member.ID.String())
+			require.NoError(t, err)
+			require.Len(t, orgs, 2)
+
+			// Claims that would fail to log in are rejected.
+			_, err = runner.AdminClient.OIDCSyncDryRun(ctx, codersdk.OIDCSyncDryRunRequest{Claims: map[string]interface{}{
+				orgClaim: 42,
+			}})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+			// Members cannot read the deployment's sync settings.
+			_, err = memberClient.OIDCSyncDryRun(ctx, codersdk.OIDCSyncDryRunRequest{Claims: claims})
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		})
+	})
+
 	t.Run("Refresh", func(t *testing.T) {
 		t.Run("RefreshTokensMultiple", func(t *testing.T) {
 			t.Parallel()
@@ -779,6 +988,7 @@ type oidcTestRunner struct {
 	AdminClient *codersdk.Client
 	AdminUser   codersdk.User
 	API         *coderden.API
+	Auditor     *audit.MockAuditor
 
 	// Login will call the OIDC flow with an unauthenticated client.
 	// The IDP will return the idToken claims.
@@ -795,8 +1005,11 @@ type oidcTestConfig struct {
 	Userinfo jwt.MapClaims
 
 	// Config allows modifying the Coderd OIDC configuration.
-	Config   func(cfg *coderd.OIDCConfig)
-	FakeOpts []oidctest.FakeIDPOpt
+	Config func(cfg *coderd.OIDCConfig)
+	// DeploymentValues allows modifying the deployment values, e.g. to
+	// enable experiments.
+	DeploymentValues func(dv *codersdk.DeploymentValues)
+	FakeOpts         []oidctest.FakeIDPOpt
 }
 
 func (r *oidcTestRunner) AssertRoles(t *testing.T, userIdent string, roles []string) {
@@ -842,6 +1055,16 @@ func (r *oidcTestRunner) AssertGroups(t *testing.T, userIdent string, groups []s
 	require.ElementsMatch(t, groups, userInGroups, "expected groups")
 }
 
+func (r *oidcTestRunner) AssertOrganizations(t *testing.T, userIdent string, orgIDs []uuid.UUID) {
+	t.Helper()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	user, err := r.AdminClient.User(ctx, userIdent)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, orgIDs, user.OrganizationIDs, "expected organizations")
+}
+
 func setupOIDCTest(t *testing.T, settings oidcTestConfig) *oidcTestRunner {
 	t.Helper()
 
@@ -856,14 +1079,24 @@ func setupOIDCTest(t *testing.T, settings oidcTestConfig) *oidcTestRunner {
 
 	ctx := testutil.Context(t, testutil.WaitMedium)
 	cfg := fake.OIDCConfig(t, nil, settings.Config)
+	dv := coderdtest.DeploymentValues(t)
+	if settings.DeploymentValues != nil {
+		settings.DeploymentValues(dv)
+	}
+	auditor := audit.NewMock()
 	owner, _, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
 		Options: &coderdtest.Options{
-			OIDCConfig: cfg,
+			OIDCConfig:       cfg,
+			DeploymentValues: dv,
+			Auditor:          auditor,
 		},
+		AuditLogging: true,
 		LicenseOptions: &coderdenttest.LicenseOptions{
 			Features: license.Features{
-				codersdk.FeatureUserRoleManagement: 1,
-				codersdk.FeatureTemplateRBAC:       1,
+				codersdk.FeatureUserRoleManagement:    1,
+				codersdk.FeatureTemplateRBAC:          1,
+				codersdk.FeatureMultipleOrganizations: 1,
+				codersdk.FeatureAuditLog:              1,
 			},
 		},
 	})
@@ -876,6 +1109,7 @@ func setupOIDCTest(t *testing.T, settings oidcTestConfig) *oidcTestRunner {
 		AdminClient:  owner,
 		AdminUser:    admin,
 		API:          api,
+		Auditor:      auditor,
 		Login:        helper.Login,
 		AttemptLogin: helper.AttemptLogin,
 		ForceRefresh: func(t *testing.T, client *codersdk.Client, idToken jwt.MapClaims) {
@@ -886,3 +1120,111 @@ func setupOIDCTest(t *testing.T, settings oidcTestConfig) *oidcTestRunner {
 		},
 	}
 }
+
+func TestUserLDAP(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T, mutate func(cfg *coderd.LDAPConfig)) (*oidcTestRunner, *ldaptest.FakeLDAP) {
+		t.Helper()
+
+		fake := ldaptest.New(t, ldaptest.WithAnonymousSearch())
+		cfg := &coderd.LDAPConfig{
+			Config: ldapauth.Config{
+				URL:               fake.URL(),
+				SearchBaseDN:      "ou=people,dc=example,dc=com",
+				UsernameAttribute: "uid",
+				EmailAttribute:    "mail",
+				GroupAttribute:    "memberOf",
+			},
+			AllowSignups: true,
+		}
+		if mutate != nil {
+			mutate(cfg)
+		}
+		owner, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				LDAPConfig: cfg,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureTemplateRBAC: 1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitShort)
+		admin, err := owner.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		return &oidcTestRunner{AdminClient: owner, AdminUser: admin}, fake
+	}
+	alice := func(groups ...string) ldaptest.Entry {
+		return ldaptest.Entry{
+			DN:       "uid=alice,ou=people,dc=example,dc=com",
+			Password: "alice-password",
+			Attributes: map[string][]string{
+				"uid":      {"alice"},
+				"mail":     {"alice@coder.com"},
+				"memberOf": groups,
+			},
+		}
+	}
+	login := func(t *testing.T, runner *oidcTestRunner) {
+		t.Helper()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := codersdk.New(runner.AdminClient.URL).LoginWithLDAP(ctx, codersdk.LoginWithLDAPRequest{
+			Username: "alice",
+			Password: "alice-password",
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("GroupsMapped", func(t *testing.T) {
+		t.Parallel()
+
+		runner, fake := setup(t, func(cfg *coderd.LDAPConfig) {
+			cfg.GroupMapping = map[string]string{"admins": "coder-admins"}
+			cfg.GroupFilter = regexp.MustCompile("^coder-")
+		})
+		ctx := testutil.Context(t, testutil.WaitShort)
+		for _, name := range []string{"coder-admins", "coder-devs"} {
+			_, err := runner.AdminClient.CreateGroup(ctx, runner.AdminUser.OrganizationIDs[0], codersdk.CreateGroupRequest{
+				Name: name,
+			})
+			require.NoError(t, err)
+		}
+
+		fake.AddEntry(alice("cn=admins,ou=groups,dc=example,dc=com", "coder-devs", "other"))
+		login(t, runner)
+		runner.AssertGroups(t, "alice", []string{"coder-admins", "coder-devs"})
+
+		// Groups are synced again on every login.
+		fake.AddEntry(alice("coder-devs"))
+		login(t, runner)
+		runner.AssertGroups(t, "alice", []string{"coder-devs"})
+	})
+
+	t.Run("GroupsAutoCreate", func(t *testing.T) {
+		t.Parallel()
+
+		runner, fake := setup(t, func(cfg *coderd.LDAPConfig) {
+			cfg.CreateMissingGroups = true
+		})
+
+		fake.AddEntry(alice("cn=make-me,ou=groups,dc=example,dc=com"))
+		login(t, runner)
+		runner.AssertGroups(t, "alice", []string{"make-me"})
+	})
+
+	t.Run("NoGroupAttribute", func(t *testing.T) {
+		t.Parallel()
+
+		runner, fake := setup(t, func(cfg *coderd.LDAPConfig) {
+			cfg.GroupAttribute = ""
+			cfg.CreateMissingGroups = true
+		})
+
+		fake.AddEntry(alice("make-me"))
+		login(t, runner)
+		runner.AssertGroups(t, "alice", []string{})
+	})
+}
@@ -1,16 +1,19 @@
 package coderd_test
 
 import (
+	"bytes"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/v2/coderd/coderdtest"
+	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/coderd/rbac"
 	"github.com/coder/coder/v2/codersdk"
 	"github.com/coder/coder/v2/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/v2/enterprise/coderd/license"
+	"github.com/coder/coder/v2/enterprise/dbcrypt"
 	"github.com/coder/coder/v2/testutil"
 )
 
@@ -125,4 +128,52 @@ func TestWorkspaceBuild(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("EncryptedState", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		db, ps := dbtestutil.NewDB(t)
+		ciphers, err := dbcrypt.NewCiphers(bytes.Repeat([]byte("a"), 32))
+		require.NoError(t, err)
+		client, closeDaemon, _, owner := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			ExternalTokenEncryption: ciphers,
+			Options: &coderdtest.Options{
+				Database:                 db,
+				Pubsub:                   ps,
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureExternalTokenEncryption: 1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+		coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+		// Stop the provisioner so the pushed state isn't replaced by the build.
+		_ = closeDaemon.Close()
+
+		// Pushing state stores it encrypted.
+		wantState := []byte("some secret state")
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			TemplateVersionID: template.ActiveVersionID,
+			Transition:        codersdk.WorkspaceTransitionStart,
+			ProvisionerState:  wantState,
+		})
+		require.NoError(t, err)
+
+		rawBuild, err := db.GetWorkspaceBuildByID(ctx, build.ID)
+		require.NoError(t, err)
+		require.Equal(t, ciphers[0].HexDigest(), rawBuild.ProvisionerStateKeyID.String)
+		require.NotEqual(t, wantState, rawBuild.ProvisionerState)
+
+		// Pulling state returns it decrypted.
+		gotState, err := client.WorkspaceBuildState(ctx, build.ID)
+		require.NoError(t, err)
+		require.Equal(t, wantState, gotState)
+	})
 }
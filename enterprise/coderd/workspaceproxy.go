@@ -457,6 +457,10 @@ func (api *API) fetchWorkspaceProxies(ctx context.Context) (codersdk.RegionsResp
 // @x-apidocgen {"skip": true}
 func (api *API) workspaceProxyIssueSignedAppToken(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	proxy := httpmw.WorkspaceProxy(r)
+	// The token must only be issued if the workspace allows its apps to be
+	// served by this proxy.
+	ctx = workspaceapps.WithWorkspaceProxy(ctx, proxy.ID)
 
 	// NOTE: this endpoint will return JSON on success, but will (usually)
 	// return a self-contained HTML error page on failure. The external proxy
@@ -854,7 +858,23 @@ func (api *API) reconnectingPTYSignedToken(rw http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	_, tokenStr, ok := api.AGPL.WorkspaceAppsProvider.Issue(ctx, rw, r, workspaceapps.IssueTokenRequest{
+	// The token is only accepted by the proxy serving the URL, so the
+	// workspace must allow its apps to be served by that proxy.
+	// nolint:gocritic // system query
+	proxy, err := api.Database.GetWorkspaceProxyByHostname(dbauthz.AsSystemRestricted(ctx), database.GetWorkspaceProxyByHostnameParams{
+		Hostname:              u.Hostname(),
+		AllowAccessUrl:        true,
+		AllowWildcardHostname: false,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to get workspace proxy for URL.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	_, tokenStr, ok := api.AGPL.WorkspaceAppsProvider.Issue(workspaceapps.WithWorkspaceProxy(ctx, proxy.ID), rw, r, workspaceapps.IssueTokenRequest{
 		AppRequest: workspaceapps.Request{
 			AccessMethod:  workspaceapps.AccessMethodTerminal,
 			BasePath:      u.Path,
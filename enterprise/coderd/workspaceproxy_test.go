@@ -15,12 +15,14 @@ import (
 
 	"github.com/coder/coder/v2/agent/agenttest"
 	"github.com/coder/coder/v2/buildinfo"
+	"github.com/coder/coder/v2/coderd/audit"
 	"github.com/coder/coder/v2/coderd/coderdtest"
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
 	"github.com/coder/coder/v2/coderd/database/dbtime"
 	"github.com/coder/coder/v2/coderd/workspaceapps"
 	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/codersdk/workspacesdk"
 	"github.com/coder/coder/v2/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/v2/enterprise/coderd/license"
 	"github.com/coder/coder/v2/enterprise/wsproxy/wsproxysdk"
@@ -887,3 +889,97 @@ func TestReconnectingPTYSignedToken(t *testing.T) {
 		// validate it here.
 	})
 }
+
+func TestIssueSignedAppTokenAllowedWorkspaceProxies(t *testing.T) {
+	t.Parallel()
+
+	auditor := audit.NewMock()
+	db, pubsub := dbtestutil.NewDB(t)
+	client, user := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			Database:                 db,
+			Pubsub:                   pubsub,
+			Auditor:                  auditor,
+			IncludeProvisionerDaemon: true,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+				codersdk.FeatureAuditLog:       1,
+			},
+		},
+	})
+
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJobCompleted(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJobCompleted(t, client, workspace.LatestBuild.ID)
+
+	_ = agenttest.New(t, client.URL, authToken)
+	_ = coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	allowedProxy, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name: testutil.GetRandomName(t),
+	})
+	require.NoError(t, err)
+	otherProxy, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name: testutil.GetRandomName(t),
+	})
+	require.NoError(t, err)
+
+	// Proxies that don't exist are rejected.
+	_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		AllowedWorkspaceProxyIDs: &[]uuid.UUID{uuid.New()},
+	})
+	require.ErrorContains(t, err, "does not exist")
+
+	template, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		AllowedWorkspaceProxyIDs: &[]uuid.UUID{allowedProxy.Proxy.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{allowedProxy.Proxy.ID}, template.AllowedWorkspaceProxyIDs)
+
+	req := workspaceapps.IssueTokenRequest{
+		AppRequest: workspaceapps.Request{
+			BasePath:      "/app",
+			AccessMethod:  workspaceapps.AccessMethodTerminal,
+			AgentNameOrID: build.Resources[0].Agents[0].ID.String(),
+		},
+		SessionToken: client.SessionToken(),
+	}
+
+	allowedClient := wsproxysdk.New(client.URL)
+	allowedClient.SetSessionToken(allowedProxy.ProxyToken)
+	_, err = allowedClient.IssueSignedAppToken(ctx, req)
+	require.NoError(t, err)
+
+	auditor.ResetLogs()
+	otherClient := wsproxysdk.New(client.URL)
+	otherClient.SetSessionToken(otherProxy.ProxyToken)
+	_, err = otherClient.IssueSignedAppToken(ctx, req)
+	var sdkErr *codersdk.Error
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+	require.True(t, auditor.Contains(t, database.AuditLog{
+		Action:         database.AuditActionOpen,
+		ResourceType:   database.ResourceTypeWorkspace,
+		ResourceID:     workspace.ID,
+		StatusCode:     http.StatusForbidden,
+		UserID:         user.UserID,
+		OrganizationID: user.OrganizationID,
+	}))
+
+	// The primary proxy is not in the allow-list either.
+	_, err = workspacesdk.New(client).AgentReconnectingPTY(ctx, workspacesdk.WorkspaceAgentReconnectingPTYOpts{
+		AgentID:   build.Resources[0].Agents[0].ID,
+		Reconnect: uuid.New(),
+		Command:   "echo test",
+	})
+	require.Error(t, err)
+}
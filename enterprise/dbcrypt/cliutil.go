@@ -4,14 +4,22 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/v2/coderd/database"
 )
 
-// Rotate rotates the database encryption keys by re-encrypting all user tokens
-// with the first cipher and revoking all other ciphers.
+// workspaceBuildStateBatchSize is the number of workspace build provisioner
+// states that are re-encrypted or decrypted in a single transaction. The
+// workspace_builds table is usually large and states can be sizeable, so they
+// are processed in batches rather than all at once.
+const workspaceBuildStateBatchSize = 500
+
+// Rotate rotates the database encryption keys by re-encrypting all user tokens,
+// TOTP secrets and workspace build provisioner states with the first cipher
+// and revoking all other ciphers.
 func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Cipher) error {
 	db := database.New(sqlDB)
 	cryptDB, err := New(ctx, db, ciphers...)
@@ -72,6 +80,20 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 					return xerrors.Errorf("update external auth link user_id=%s provider_id=%s: %w", externalAuthLink.UserID, externalAuthLink.ProviderID, err)
 				}
 			}
+
+			totp, err := cryptTx.GetUserTOTPByUserID(ctx, uid)
+			if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get totp for user: %w", err)
+			}
+			if err == nil && totp.SecretKeyID.String != ciphers[0].HexDigest() {
+				if err := cryptTx.UpdateUserTOTPSecret(ctx, database.UpdateUserTOTPSecretParams{
+					UserID:      uid,
+					Secret:      totp.Secret,
+					SecretKeyID: sql.NullString{}, // dbcrypt will update as required
+				}); err != nil {
+					return xerrors.Errorf("update totp user_id=%s: %w", uid, err)
+				}
+			}
 			return nil
 		}, &sql.TxOptions{
 			Isolation: sql.LevelRepeatableRead,
@@ -82,6 +104,14 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 		log.Debug(ctx, "encrypted user tokens", slog.F("user_id", uid), slog.F("current", idx+1), slog.F("cipher", ciphers[0].HexDigest()))
 	}
 
+	log.Info(ctx, "encrypting workspace build provisioner states")
+	err = updateWorkspaceBuildStates(ctx, log, cryptDB, func(keyID sql.NullString) bool {
+		return keyID.String == ciphers[0].HexDigest()
+	})
+	if err != nil {
+		return xerrors.Errorf("encrypt workspace build provisioner states: %w", err)
+	}
+
 	// Revoke old keys
 	for _, c := range ciphers[1:] {
 		if err := db.RevokeDBCryptKey(ctx, c.HexDigest()); err != nil {
@@ -93,7 +123,8 @@ func Rotate(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciphe
 	return nil
 }
 
-// Decrypt decrypts all user tokens and revokes all ciphers.
+// Decrypt decrypts all user tokens, TOTP secrets and workspace build
+// provisioner states and revokes all ciphers.
 func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Cipher) error {
 	db := database.New(sqlDB)
 	cdb, err := New(ctx, db, ciphers...)
@@ -162,6 +193,20 @@ func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciph
 					return xerrors.Errorf("update external auth link user_id=%s provider_id=%s: %w", externalAuthLink.UserID, externalAuthLink.ProviderID, err)
 				}
 			}
+
+			totp, err := tx.GetUserTOTPByUserID(ctx, uid)
+			if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get totp for user: %w", err)
+			}
+			if err == nil && totp.SecretKeyID.Valid {
+				if err := tx.UpdateUserTOTPSecret(ctx, database.UpdateUserTOTPSecretParams{
+					UserID:      uid,
+					Secret:      totp.Secret,
+					SecretKeyID: sql.NullString{}, // we explicitly want to clear the key id
+				}); err != nil {
+					return xerrors.Errorf("update totp user_id=%s: %w", uid, err)
+				}
+			}
 			return nil
 		}, &sql.TxOptions{
 			Isolation: sql.LevelRepeatableRead,
@@ -172,6 +217,14 @@ func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciph
 		log.Debug(ctx, "decrypted user tokens", slog.F("user_id", uid), slog.F("current", idx+1), slog.F("cipher", ciphers[0].HexDigest()))
 	}
 
+	log.Info(ctx, "decrypting workspace build provisioner states")
+	err = updateWorkspaceBuildStates(ctx, log, cryptDB, func(keyID sql.NullString) bool {
+		return !keyID.Valid
+	})
+	if err != nil {
+		return xerrors.Errorf("decrypt workspace build provisioner states: %w", err)
+	}
+
 	// Revoke _all_ keys
 	for _, c := range ciphers {
 		if err := db.RevokeDBCryptKey(ctx, c.HexDigest()); err != nil {
@@ -183,8 +236,56 @@ func Decrypt(ctx context.Context, log slog.Logger, sqlDB *sql.DB, ciphers []Ciph
 	return nil
 }
 
+// updateWorkspaceBuildStates writes back the provisioner state of every
+// workspace build through the given dbcrypt store in batches, which encrypts or
+// decrypts it as configured. Builds for which skip returns true are left as-is.
+func updateWorkspaceBuildStates(ctx context.Context, log slog.Logger, cryptDB database.Store, skip func(keyID sql.NullString) bool) error {
+	var (
+		afterID uuid.UUID
+		total   int
+	)
+	for {
+		var count int
+		err := cryptDB.InTx(func(tx database.Store) error {
+			rows, err := tx.GetWorkspaceBuildProvisionerStates(ctx, database.GetWorkspaceBuildProvisionerStatesParams{
+				AfterID:  afterID,
+				LimitOpt: workspaceBuildStateBatchSize,
+			})
+			if err != nil {
+				return xerrors.Errorf("get workspace build provisioner states: %w", err)
+			}
+			count = len(rows)
+			for _, row := range rows {
+				afterID = row.ID
+				if skip(row.ProvisionerStateKeyID) {
+					continue
+				}
+				if err := tx.UpdateWorkspaceBuildProvisionerStateByID(ctx, database.UpdateWorkspaceBuildProvisionerStateByIDParams{
+					ID:                    row.ID,
+					ProvisionerState:      row.ProvisionerState,
+					ProvisionerStateKeyID: sql.NullString{}, // dbcrypt will update as required
+					UpdatedAt:             row.UpdatedAt,
+				}); err != nil {
+					return xerrors.Errorf("update workspace build id=%s: %w", row.ID, err)
+				}
+			}
+			return nil
+		}, &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+		})
+		if err != nil {
+			return err
+		}
+		total += count
+		log.Debug(ctx, "processed workspace build provisioner states", slog.F("count", total))
+		if count < workspaceBuildStateBatchSize {
+			return nil
+		}
+	}
+}
+
 // nolint: gosec
-const sqlDeleteEncryptedUserTokens = `
+const sqlDeleteEncryptedData = `
 BEGIN;
 DELETE FROM user_links
   WHERE oauth_access_token_key_id IS NOT NULL
@@ -192,20 +293,26 @@ DELETE FROM user_links
 DELETE FROM external_auth_links
 	WHERE oauth_access_token_key_id IS NOT NULL
 	OR oauth_refresh_token_key_id IS NOT NULL;
+UPDATE workspace_builds
+	SET provisioner_state = ''::bytea, provisioner_state_key_id = NULL
+	WHERE provisioner_state_key_id IS NOT NULL;
+DELETE FROM user_totp
+	WHERE secret_key_id IS NOT NULL;
 COMMIT;
 `
 
-// Delete deletes all user tokens and revokes all ciphers.
+// Delete deletes all user tokens, TOTP second factors and workspace build
+// provisioner states that are encrypted and revokes all ciphers.
 // This is a destructive operation and should only be used
 // as a last resort, for example, if the database encryption key has been
 // lost.
 func Delete(ctx context.Context, log slog.Logger, sqlDB *sql.DB) error {
 	store := database.New(sqlDB)
-	_, err := sqlDB.ExecContext(ctx, sqlDeleteEncryptedUserTokens)
+	_, err := sqlDB.ExecContext(ctx, sqlDeleteEncryptedData)
 	if err != nil {
-		return xerrors.Errorf("delete user links: %w", err)
+		return xerrors.Errorf("delete encrypted data: %w", err)
 	}
-	log.Info(ctx, "deleted encrypted user tokens")
+	log.Info(ctx, "deleted encrypted user tokens, TOTP second factors and workspace build provisioner states")
 
 	log.Info(ctx, "revoking all active keys")
 	keys, err := store.GetDBCryptKeys(ctx)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/base64"
+	"time"
 
 	"github.com/coder/coder/v2/coderd/database"
 	"github.com/coder/coder/v2/coderd/database/dbauthz"
@@ -261,6 +262,193 @@ func (db *dbCrypt) UpdateExternalAuthLink(ctx context.Context, params database.U
 	return link, nil
 }
 
+func (db *dbCrypt) GetUserTOTPByUserID(ctx context.Context, userID uuid.UUID) (database.UserTOTP, error) {
+	totp, err := db.Store.GetUserTOTPByUserID(ctx, userID)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := db.decryptField(&totp.Secret, totp.SecretKeyID); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return totp, nil
+}
+
+func (db *dbCrypt) UpsertUserTOTP(ctx context.Context, arg database.UpsertUserTOTPParams) (database.UserTOTP, error) {
+	if err := db.encryptField(&arg.Secret, &arg.SecretKeyID); err != nil {
+		return database.UserTOTP{}, err
+	}
+	totp, err := db.Store.UpsertUserTOTP(ctx, arg)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := db.decryptField(&totp.Secret, totp.SecretKeyID); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return totp, nil
+}
+
+func (db *dbCrypt) UpdateUserTOTPLastUsedCounter(ctx context.Context, arg database.UpdateUserTOTPLastUsedCounterParams) (database.UserTOTP, error) {
+	totp, err := db.Store.UpdateUserTOTPLastUsedCounter(ctx, arg)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := db.decryptField(&totp.Secret, totp.SecretKeyID); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return totp, nil
+}
+
+func (db *dbCrypt) RemoveUserTOTPRecoveryCode(ctx context.Context, arg database.RemoveUserTOTPRecoveryCodeParams) (database.UserTOTP, error) {
+	totp, err := db.Store.RemoveUserTOTPRecoveryCode(ctx, arg)
+	if err != nil {
+		return database.UserTOTP{}, err
+	}
+	if err := db.decryptField(&totp.Secret, totp.SecretKeyID); err != nil {
+		return database.UserTOTP{}, err
+	}
+	return totp, nil
+}
+
+func (db *dbCrypt) UpdateUserTOTPSecret(ctx context.Context, arg database.UpdateUserTOTPSecretParams) error {
+	if err := db.encryptField(&arg.Secret, &arg.SecretKeyID); err != nil {
+		return err
+	}
+	return db.Store.UpdateUserTOTPSecret(ctx, arg)
+}
+
+func (db *dbCrypt) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.WorkspaceBuild, error) {
+	builds, err := db.Store.GetActiveWorkspaceBuildsByTemplateID(ctx, templateID)
+	if err != nil {
+		return nil, err
+	}
+	return builds, db.decryptWorkspaceBuilds(builds)
+}
+
+func (db *dbCrypt) GetLatestWorkspaceBuildByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceBuild, error) {
+	build, err := db.Store.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspaceID)
+	if err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	if err := db.decryptBytes(&build.ProvisionerState, build.ProvisionerStateKeyID); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	return build, nil
+}
+
+func (db *dbCrypt) GetLatestWorkspaceBuilds(ctx context.Context) ([]database.WorkspaceBuild, error) {
+	builds, err := db.Store.GetLatestWorkspaceBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return builds, db.decryptWorkspaceBuilds(builds)
+}
+
+func (db *dbCrypt) GetLatestWorkspaceBuildsByWorkspaceIDs(ctx context.Context, ids []uuid.UUID) ([]database.WorkspaceBuild, error) {
+	builds, err := db.Store.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return builds, db.decryptWorkspaceBuilds(builds)
+}
+
+func (db *dbCrypt) GetWorkspaceAgentAndLatestBuildByAuthToken(ctx context.Context, authToken uuid.UUID) (database.GetWorkspaceAgentAndLatestBuildByAuthTokenRow, error) {
+	row, err := db.Store.GetWorkspaceAgentAndLatestBuildByAuthToken(ctx, authToken)
+	if err != nil {
+		return database.GetWorkspaceAgentAndLatestBuildByAuthTokenRow{}, err
+	}
+	if err := db.decryptBytes(&row.WorkspaceBuild.ProvisionerState, row.WorkspaceBuild.ProvisionerStateKeyID); err != nil {
+		return database.GetWorkspaceAgentAndLatestBuildByAuthTokenRow{}, err
+	}
+	return row, nil
+}
+
+func (db *dbCrypt) GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (database.WorkspaceBuild, error) {
+	build, err := db.Store.GetWorkspaceBuildByID(ctx, id)
+	if err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	if err := db.decryptBytes(&build.ProvisionerState, build.ProvisionerStateKeyID); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	return build, nil
+}
+
+func (db *dbCrypt) GetWorkspaceBuildByJobID(ctx context.Context, jobID uuid.UUID) (database.WorkspaceBuild, error) {
+	build, err := db.Store.GetWorkspaceBuildByJobID(ctx, jobID)
+	if err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	if err := db.decryptBytes(&build.ProvisionerState, build.ProvisionerStateKeyID); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	return build, nil
+}
+
+func (db *dbCrypt) GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Context, arg database.GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams) (database.WorkspaceBuild, error) {
+	build, err := db.Store.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, arg)
+	if err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	if err := db.decryptBytes(&build.ProvisionerState, build.ProvisionerStateKeyID); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+	return build, nil
+}
+
+func (db *dbCrypt) GetWorkspaceBuildProvisionerStates(ctx context.Context, arg database.GetWorkspaceBuildProvisionerStatesParams) ([]database.GetWorkspaceBuildProvisionerStatesRow, error) {
+	rows, err := db.Store.GetWorkspaceBuildProvisionerStates(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range rows {
+		if err := db.decryptBytes(&rows[idx].ProvisionerState, rows[idx].ProvisionerStateKeyID); err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+func (db *dbCrypt) GetWorkspaceBuildsByWorkspaceID(ctx context.Context, arg database.GetWorkspaceBuildsByWorkspaceIDParams) ([]database.WorkspaceBuild, error) {
+	builds, err := db.Store.GetWorkspaceBuildsByWorkspaceID(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return builds, db.decryptWorkspaceBuilds(builds)
+}
+
+func (db *dbCrypt) GetWorkspaceBuildsCreatedAfter(ctx context.Context, createdAt time.Time) ([]database.WorkspaceBuild, error) {
+	builds, err := db.Store.GetWorkspaceBuildsCreatedAfter(ctx, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return builds, db.decryptWorkspaceBuilds(builds)
+}
+
+func (db *dbCrypt) InsertWorkspaceBuild(ctx context.Context, arg database.InsertWorkspaceBuildParams) error {
+	if err := db.encryptBytes(&arg.ProvisionerState, &arg.ProvisionerStateKeyID); err != nil {
+		return err
+	}
+	return db.Store.InsertWorkspaceBuild(ctx, arg)
+}
+
+func (db *dbCrypt) UpdateWorkspaceBuildProvisionerStateByID(ctx context.Context, arg database.UpdateWorkspaceBuildProvisionerStateByIDParams) error {
+	if err := db.encryptBytes(&arg.ProvisionerState, &arg.ProvisionerStateKeyID); err != nil {
+		return err
+	}
+	return db.Store.UpdateWorkspaceBuildProvisionerStateByID(ctx, arg)
+}
+
+// decryptWorkspaceBuilds decrypts the provisioner state of the given builds
+// in place.
+func (db *dbCrypt) decryptWorkspaceBuilds(builds []database.WorkspaceBuild) error {
+	for idx := range builds {
+		if err := db.decryptBytes(&builds[idx].ProvisionerState, builds[idx].ProvisionerStateKeyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (db *dbCrypt) encryptField(field *string, digest *sql.NullString) error {
 	// If no cipher is loaded, then we can't encrypt anything!
 	if db.ciphers == nil || db.primaryCipherDigest == "" {
@@ -318,6 +506,64 @@ func (db *dbCrypt) decryptField(field *string, digest sql.NullString) error {
 	return nil
 }
 
+// encryptBytes is like encryptField, but for binary columns. As these are
+// stored as bytea, the ciphertext is stored as-is rather than base64-encoded.
+// Empty values are left unencrypted as they do not hold anything worth
+// protecting.
+func (db *dbCrypt) encryptBytes(field *[]byte, digest *sql.NullString) error {
+	// If no cipher is loaded, then we can't encrypt anything!
+	if db.ciphers == nil || db.primaryCipherDigest == "" {
+		return nil
+	}
+
+	if field == nil {
+		return xerrors.Errorf("developer error: encryptBytes called with nil field")
+	}
+	if digest == nil {
+		return xerrors.Errorf("developer error: encryptBytes called with nil digest")
+	}
+
+	if len(*field) == 0 {
+		*digest = sql.NullString{}
+		return nil
+	}
+
+	encrypted, err := db.ciphers[db.primaryCipherDigest].Encrypt(*field)
+	if err != nil {
+		return err
+	}
+	*field = encrypted
+	*digest = sql.NullString{String: db.primaryCipherDigest, Valid: true}
+	return nil
+}
+
+// decryptBytes decrypts the given binary field using the key with the given
+// digest.
+func (db *dbCrypt) decryptBytes(field *[]byte, digest sql.NullString) error {
+	if field == nil {
+		return xerrors.Errorf("developer error: decryptBytes called with nil field")
+	}
+
+	if !digest.Valid || digest.String == "" {
+		// This field is not encrypted.
+		return nil
+	}
+
+	key, ok := db.ciphers[digest.String]
+	if !ok {
+		return &DecryptFailedError{
+			Inner: xerrors.Errorf("no cipher with digest %q", digest.String),
+		}
+	}
+
+	decrypted, err := key.Decrypt(*field)
+	if err != nil {
+		return &DecryptFailedError{Inner: err}
+	}
+	*field = decrypted
+	return nil
+}
+
 func (db *dbCrypt) ensureEncryptedWithRetry(ctx context.Context) error {
 	var err error
 	for i := 0; i < 3; i++ {
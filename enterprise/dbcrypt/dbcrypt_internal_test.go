@@ -14,9 +14,11 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/coder/coder/v2/coderd/database"
+	"github.com/coder/coder/v2/coderd/database/dbfake"
 	"github.com/coder/coder/v2/coderd/database/dbgen"
 	"github.com/coder/coder/v2/coderd/database/dbmock"
 	"github.com/coder/coder/v2/coderd/database/dbtestutil"
+	"github.com/coder/coder/v2/coderd/database/dbtime"
 )
 
 func TestUserLinks(t *testing.T) {
@@ -349,6 +351,206 @@ func TestExternalAuthLinks(t *testing.T) {
 	})
 }
 
+func TestWorkspaceBuilds(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("InsertWorkspaceBuild", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		r := workspaceBuild(t, crypt, []byte("state"))
+		require.Equal(t, []byte("state"), r.Build.ProvisionerState)
+		require.Equal(t, ciphers[0].HexDigest(), r.Build.ProvisionerStateKeyID.String)
+
+		rawBuild, err := db.GetWorkspaceBuildByID(ctx, r.Build.ID)
+		require.NoError(t, err)
+		requireEncryptedBytesEquals(t, ciphers[0], rawBuild.ProvisionerState, []byte("state"))
+	})
+
+	t.Run("InsertWorkspaceBuildEmptyState", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, _ := setup(t)
+		r := workspaceBuild(t, crypt, nil)
+
+		rawBuild, err := db.GetWorkspaceBuildByID(ctx, r.Build.ID)
+		require.NoError(t, err)
+		require.Empty(t, rawBuild.ProvisionerState)
+		require.False(t, rawBuild.ProvisionerStateKeyID.Valid)
+	})
+
+	t.Run("UpdateWorkspaceBuildProvisionerStateByID", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		r := workspaceBuild(t, crypt, nil)
+
+		err := crypt.UpdateWorkspaceBuildProvisionerStateByID(ctx, database.UpdateWorkspaceBuildProvisionerStateByIDParams{
+			ID:               r.Build.ID,
+			ProvisionerState: []byte("state"),
+			UpdatedAt:        dbtime.Now(),
+		})
+		require.NoError(t, err)
+
+		build, err := crypt.GetWorkspaceBuildByID(ctx, r.Build.ID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("state"), build.ProvisionerState)
+		require.Equal(t, ciphers[0].HexDigest(), build.ProvisionerStateKeyID.String)
+
+		rawBuild, err := db.GetWorkspaceBuildByID(ctx, r.Build.ID)
+		require.NoError(t, err)
+		requireEncryptedBytesEquals(t, ciphers[0], rawBuild.ProvisionerState, []byte("state"))
+	})
+
+	t.Run("GetLatestWorkspaceBuildByWorkspaceID", func(t *testing.T) {
+		t.Parallel()
+		_, crypt, ciphers := setup(t)
+		r := workspaceBuild(t, crypt, []byte("state"))
+
+		build, err := crypt.GetLatestWorkspaceBuildByWorkspaceID(ctx, r.Workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, []byte("state"), build.ProvisionerState)
+		require.Equal(t, ciphers[0].HexDigest(), build.ProvisionerStateKeyID.String)
+	})
+
+	t.Run("GetWorkspaceBuildsByWorkspaceID", func(t *testing.T) {
+		t.Parallel()
+		_, crypt, _ := setup(t)
+		r := workspaceBuild(t, crypt, []byte("state"))
+
+		builds, err := crypt.GetWorkspaceBuildsByWorkspaceID(ctx, database.GetWorkspaceBuildsByWorkspaceIDParams{
+			WorkspaceID: r.Workspace.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, builds, 1)
+		require.Equal(t, []byte("state"), builds[0].ProvisionerState)
+	})
+
+	t.Run("GetWorkspaceBuildProvisionerStates", func(t *testing.T) {
+		t.Parallel()
+		_, crypt, ciphers := setup(t)
+		r := workspaceBuild(t, crypt, []byte("state"))
+
+		rows, err := crypt.GetWorkspaceBuildProvisionerStates(ctx, database.GetWorkspaceBuildProvisionerStatesParams{
+			LimitOpt: 10,
+		})
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		require.Equal(t, r.Build.ID, rows[0].ID)
+		require.Equal(t, []byte("state"), rows[0].ProvisionerState)
+		require.Equal(t, ciphers[0].HexDigest(), rows[0].ProvisionerStateKeyID.String)
+	})
+
+	t.Run("DecryptErr", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		r := workspaceBuild(t, db, nil)
+		err := db.UpdateWorkspaceBuildProvisionerStateByID(ctx, database.UpdateWorkspaceBuildProvisionerStateByIDParams{
+			ID:                    r.Build.ID,
+			ProvisionerState:      fakeRandomData(t, 32),
+			ProvisionerStateKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+			UpdatedAt:             dbtime.Now(),
+		})
+		require.NoError(t, err)
+
+		_, err = crypt.GetWorkspaceBuildByID(ctx, r.Build.ID)
+		require.Error(t, err, "expected an error")
+		var derr *DecryptFailedError
+		require.ErrorAs(t, err, &derr, "expected a decrypt error")
+	})
+}
+
+func TestUserTOTP(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("UpsertUserTOTP", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		totp := dbgen.UserTOTP(t, crypt, database.UserTOTP{
+			UserID: user.ID,
+			Secret: "secret",
+		})
+		require.Equal(t, "secret", totp.Secret)
+		require.Equal(t, ciphers[0].HexDigest(), totp.SecretKeyID.String)
+
+		rawTOTP, err := db.GetUserTOTPByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], rawTOTP.Secret, "secret")
+	})
+
+	t.Run("UpdateUserTOTPSecret", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		_ = dbgen.UserTOTP(t, db, database.UserTOTP{UserID: user.ID})
+		err := crypt.UpdateUserTOTPSecret(ctx, database.UpdateUserTOTPSecretParams{
+			UserID: user.ID,
+			Secret: "secret",
+		})
+		require.NoError(t, err)
+
+		totp, err := crypt.GetUserTOTPByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		require.Equal(t, "secret", totp.Secret)
+		require.Equal(t, ciphers[0].HexDigest(), totp.SecretKeyID.String)
+
+		rawTOTP, err := db.GetUserTOTPByUserID(ctx, user.ID)
+		require.NoError(t, err)
+		requireEncryptedEquals(t, ciphers[0], rawTOTP.Secret, "secret")
+	})
+
+	t.Run("UpdateUserTOTPLastUsedCounter", func(t *testing.T) {
+		t.Parallel()
+		_, crypt, _ := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		_ = dbgen.UserTOTP(t, crypt, database.UserTOTP{
+			UserID: user.ID,
+			Secret: "secret",
+		})
+		totp, err := crypt.UpdateUserTOTPLastUsedCounter(ctx, database.UpdateUserTOTPLastUsedCounterParams{
+			UserID:          user.ID,
+			LastUsedCounter: 1,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", totp.Secret)
+		require.EqualValues(t, 1, totp.LastUsedCounter)
+	})
+
+	t.Run("RemoveUserTOTPRecoveryCode", func(t *testing.T) {
+		t.Parallel()
+		_, crypt, _ := setup(t)
+		user := dbgen.User(t, crypt, database.User{})
+		_ = dbgen.UserTOTP(t, crypt, database.UserTOTP{
+			UserID:              user.ID,
+			Secret:              "secret",
+			HashedRecoveryCodes: []string{"a", "b"},
+		})
+		totp, err := crypt.RemoveUserTOTPRecoveryCode(ctx, database.RemoveUserTOTPRecoveryCodeParams{
+			UserID:             user.ID,
+			HashedRecoveryCode: "a",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "secret", totp.Secret)
+		require.Equal(t, []string{"b"}, totp.HashedRecoveryCodes)
+	})
+
+	t.Run("DecryptErr", func(t *testing.T) {
+		t.Parallel()
+		db, crypt, ciphers := setup(t)
+		user := dbgen.User(t, db, database.User{})
+		_ = dbgen.UserTOTP(t, db, database.UserTOTP{
+			UserID:      user.ID,
+			Secret:      fakeBase64RandomData(t, 32),
+			SecretKeyID: sql.NullString{String: ciphers[0].HexDigest(), Valid: true},
+		})
+
+		_, err := crypt.GetUserTOTPByUserID(ctx, user.ID)
+		require.Error(t, err, "expected an error")
+		var derr *DecryptFailedError
+		require.ErrorAs(t, err, &derr, "expected a decrypt error")
+	})
+}
+
 func TestNew(t *testing.T) {
 	t.Parallel()
 
@@ -635,6 +837,25 @@ func requireEncryptedEquals(t *testing.T, c Cipher, value, expected string) {
 	require.Equal(t, expected, string(got), "decrypted data does not match")
 }
 
+func requireEncryptedBytesEquals(t *testing.T, c Cipher, value, expected []byte) {
+	t.Helper()
+	got, err := c.Decrypt(value)
+	require.NoError(t, err, "failed to decrypt data")
+	require.Equal(t, expected, got, "decrypted data does not match")
+}
+
+func workspaceBuild(t *testing.T, db database.Store, state []byte) dbfake.WorkspaceResponse {
+	t.Helper()
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	return dbfake.WorkspaceBuild(t, db, database.Workspace{
+		OrganizationID: org.ID,
+		OwnerID:        user.ID,
+	}).Seed(database.WorkspaceBuild{
+		ProvisionerState: state,
+	}).Do()
+}
+
 func initCipher(t *testing.T) *aes256 {
 	t.Helper()
 	key := make([]byte, 32) // AES-256 key size is 32 bytes
@@ -667,9 +888,14 @@ func setupNoCiphers(t *testing.T) (db database.Store, cryptodb *dbCrypt) {
 }
 
 func fakeBase64RandomData(t *testing.T, n int) string {
+	t.Helper()
+	return base64.StdEncoding.EncodeToString(fakeRandomData(t, n))
+}
+
+func fakeRandomData(t *testing.T, n int) []byte {
 	t.Helper()
 	b := make([]byte, n)
 	_, err := io.ReadFull(rand.Reader, b)
 	require.NoError(t, err)
-	return base64.StdEncoding.EncodeToString(b)
+	return b
 }
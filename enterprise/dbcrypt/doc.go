@@ -11,6 +11,8 @@
 // - database.UserLink.OAuthRefreshToken
 // - database.GitAuthLink.OAuthAccessToken
 // - database.GitAuthLink.OAuthRefreshToken
+// - database.UserTOTP.Secret
+// - database.WorkspaceBuild.ProvisionerState
 // - database.DBCryptSentinelValue
 //
 // Multiple ciphers can be provided to support key rotation. The primary cipher
@@ -28,6 +30,7 @@
 //   - test: the encrypted value of the string "coder". This is used to ensure that the key is valid.
 //
 // Encrypted fields are stored in the database as a base64-encoded string.
+// Binary (bytea) fields such as the provisioner state store the ciphertext as-is.
 // Each encrypted column MUST have a corresponding _key_id column that is a foreign key
 // reference to `dbcrypt_keys.active_key_digest`. This ensures that a key cannot be
 // revoked until all rows that use that key have been migrated to a new key.
@@ -23,6 +23,10 @@ type TokenProvider struct {
 	Logger      slog.Logger
 }
 
+// FromRequest only verifies the signature and expiry of the token. Unlike
+// coderd, proxies are not notified when the sessions of a user are revoked, so
+// tokens of revoked users are accepted until they expire, which takes up to
+// workspaceapps.DefaultTokenExpiry.
 func (p *TokenProvider) FromRequest(r *http.Request) (*workspaceapps.SignedToken, bool) {
 	return workspaceapps.FromRequest(r, p.SecurityKey)
 }
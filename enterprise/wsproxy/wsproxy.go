@@ -291,6 +291,7 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 
 		AgentProvider:  agentProvider,
 		StatsCollector: workspaceapps.NewStatsCollector(opts.StatsCollectorOptions),
+		Limiter:        workspaceapps.NewLimiter(opts.PrometheusRegistry),
 	}
 
 	derpHandler := derphttp.Handler(derpServer)
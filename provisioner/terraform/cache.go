@@ -0,0 +1,377 @@
+package terraform
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// DefaultCacheMaxSize is the default size limit of the Terraform provider
+	// and module cache of a provisioner daemon.
+	DefaultCacheMaxSize int64 = 10 << 30 // 10 GiB
+
+	// moduleCacheDir is the directory in the cache path where downloaded
+	// Terraform modules are stored.
+	moduleCacheDir = "modules"
+	// moduleCacheTTL is how long cached modules are used after they were
+	// downloaded. Module sources may use version ranges or unpinned git
+	// refs, so entries must expire to pick up new releases.
+	moduleCacheTTL = time.Hour
+	// cacheLockFile guards the cache path against concurrent use by multiple
+	// provisioner jobs or processes.
+	cacheLockFile = "cache.lock"
+)
+
+// Labels of the Terraform cache lookup metric.
+const (
+	cacheLabelProvider = "provider"
+	cacheLabelModule   = "module"
+	cacheResultHit     = "hit"
+	cacheResultMiss    = "miss"
+)
+
+// lockTerraformCache acquires an exclusive lock on the cache path. The
+// returned function releases the lock.
+func lockTerraformCache(ctx context.Context, cachePath string) (func(), error) {
+	err := os.MkdirAll(cachePath, 0o750)
+	if err != nil {
+		return nil, xerrors.Errorf("create cache directory: %w", err)
+	}
+
+	// Windows requires a separate lock file.
+	lockFilePath := filepath.Join(cachePath, cacheLockFile)
+	lock := flock.New(lockFilePath)
+	ok, err := lock.TryLockContext(ctx, time.Millisecond*100)
+	if !ok {
+		return nil, xerrors.Errorf("could not acquire flock for %v: %w", lockFilePath, err)
+	}
+	return func() {
+		_ = lock.Close()
+	}, nil
+}
+
+// moduleCacheKey returns a key identifying the module dependencies of the
+// Terraform configuration in workdir. Module sources and versions are declared
+// in the configuration files, so two configurations with identical files
+// resolve to the same modules.
+func moduleCacheKey(workdir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(workdir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(d.Name(), ".tf") || strings.HasSuffix(d.Name(), ".tf.json") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", xerrors.Errorf("walk work directory: %w", err)
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(workdir, path)
+		if err != nil {
+			return "", xerrors.Errorf("relative path of %q: %w", path, err)
+		}
+		_, _ = io.WriteString(h, filepath.ToSlash(rel))
+		_, _ = h.Write([]byte{0})
+		f, err := os.Open(path)
+		if err != nil {
+			return "", xerrors.Errorf("open %q: %w", path, err)
+		}
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return "", xerrors.Errorf("read %q: %w", path, err)
+		}
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// restoreModules copies the cached modules for key into the work directory.
+// It returns false if there are no cached modules for key, or if they were
+// stored more than moduleCacheTTL before now.
+func restoreModules(cachePath, key, workdir string, now time.Time) (bool, error) {
+	src := filepath.Join(cachePath, moduleCacheDir, key)
+	info, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, xerrors.Errorf("stat %q: %w", src, err)
+	}
+	// The mtime is only set when the entry is stored, hits don't refresh
+	// it.
+	if info.ModTime().Add(moduleCacheTTL).Before(now) {
+		return false, nil
+	}
+
+	err = copyDir(src, filepath.Join(workdir, ".terraform", "modules"))
+	if err != nil {
+		return false, xerrors.Errorf("copy cached modules: %w", err)
+	}
+	return true, nil
+}
+
+// storeModules copies the modules installed by "terraform init" in the work
+// directory into the cache under key. It returns false if the configuration
+// doesn't use any modules.
+func storeModules(cachePath, key, workdir string) (bool, error) {
+	src := filepath.Join(workdir, ".terraform", "modules")
+	_, err := os.Stat(src)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, xerrors.Errorf("stat %q: %w", src, err)
+	}
+
+	modulesPath := filepath.Join(cachePath, moduleCacheDir)
+	err = os.MkdirAll(modulesPath, 0o750)
+	if err != nil {
+		return true, xerrors.Errorf("create module cache directory: %w", err)
+	}
+
+	// Copy into a temporary directory first, so a partially written entry is
+	// never picked up by a later job.
+	tmp, err := os.MkdirTemp(modulesPath, ".tmp-")
+	if err != nil {
+		return true, xerrors.Errorf("create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	err = copyDir(src, tmp)
+	if err != nil {
+		return true, xerrors.Errorf("copy modules: %w", err)
+	}
+
+	dst := filepath.Join(modulesPath, key)
+	err = os.RemoveAll(dst)
+	if err != nil {
+		return true, xerrors.Errorf("remove %q: %w", dst, err)
+	}
+	err = os.Rename(tmp, dst)
+	if err != nil {
+		return true, xerrors.Errorf("rename %q: %w", tmp, err)
+	}
+	return true, nil
+}
+
+// copyDir recursively copies the directory src to dst. Symlinks are
+// recreated rather than followed.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// providerCacheWriter counts provider cache hits and misses in the output of
+// "terraform init" while passing it through to the underlying writer.
+type providerCacheWriter struct {
+	w       io.WriteCloser
+	pw      *io.PipeWriter
+	done    chan struct{}
+	metrics *prometheus.CounterVec
+}
+
+func newProviderCacheWriter(w io.WriteCloser, metrics *prometheus.CounterVec) io.WriteCloser {
+	if metrics == nil {
+		return w
+	}
+	pr, pw := io.Pipe()
+	c := &providerCacheWriter{
+		w:       w,
+		pw:      pw,
+		done:    make(chan struct{}),
+		metrics: metrics,
+	}
+	go func() {
+		defer close(c.done)
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.Contains(line, "from the shared cache directory"):
+				c.metrics.WithLabelValues(cacheLabelProvider, cacheResultHit).Inc()
+			case strings.HasPrefix(line, "- Installing "):
+				c.metrics.WithLabelValues(cacheLabelProvider, cacheResultMiss).Inc()
+			}
+		}
+		// Drain the pipe in case of an overly long line so that writers
+		// never block.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	return c
+}
+
+func (c *providerCacheWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	_, _ = c.pw.Write(p[:n])
+	return n, nil
+}
+
+func (c *providerCacheWriter) Close() error {
+	_ = c.pw.Close()
+	<-c.done
+	return c.w.Close()
+}
+
+// initWithCache runs "terraform init" with the provider and module cache of
+// the server. The cache is locked for the duration of the call, so jobs
+// sharing a cache path never observe a partially written entry. Cache errors
+// are not fatal: the job falls back to downloading the dependencies.
+//
+// Template imports run "terraform init" as well, which warms the cache for
+// the workspace builds of the template version.
+func (e *executor) initWithCache(ctx, killCtx context.Context, logr logSink, now time.Time) error {
+	if e.cachePath == "" {
+		return e.init(ctx, killCtx, logr, e.basicEnv())
+	}
+
+	unlock, err := lockTerraformCache(ctx, e.cachePath)
+	if err != nil {
+		// Other jobs may be writing to the cache, so the plugin cache must
+		// not be used either.
+		e.logger.Warn(ctx, "unable to lock Terraform cache, initializing without it", slog.Error(err))
+		env := slices.DeleteFunc(e.basicEnv(), func(kv string) bool {
+			return strings.HasPrefix(kv, "TF_PLUGIN_CACHE_DIR=")
+		})
+		return e.init(ctx, killCtx, logr, env)
+	}
+	defer unlock()
+
+	err = CleanStaleTerraformPlugins(ctx, e.cachePath, afero.NewOsFs(), now, e.logger)
+	if err != nil {
+		return staleTerraformPluginsError{err: err}
+	}
+
+	key, err := moduleCacheKey(e.workdir)
+	if err != nil {
+		e.logger.Warn(ctx, "unable to compute module cache key", slog.Error(err))
+	}
+	var hit bool
+	if key != "" {
+		hit, err = restoreModules(e.cachePath, key, e.workdir, now)
+		if err != nil {
+			e.logger.Warn(ctx, "unable to restore cached modules", slog.Error(err))
+		}
+	}
+
+	err = e.init(ctx, killCtx, logr, e.basicEnv())
+	if err != nil {
+		return err
+	}
+
+	if hit {
+		e.observeCache(cacheLabelModule, cacheResultHit)
+	} else if key != "" {
+		// Configurations without modules are neither a hit nor a miss.
+		used, err := storeModules(e.cachePath, key, e.workdir)
+		if err != nil {
+			e.logger.Warn(ctx, "unable to store modules in cache", slog.Error(err))
+		}
+		if used {
+			e.observeCache(cacheLabelModule, cacheResultMiss)
+		}
+	}
+
+	err = EvictTerraformCache(ctx, e.cachePath, afero.NewOsFs(), e.server.cacheMaxSize, e.logger)
+	if err != nil {
+		e.logger.Warn(ctx, "unable to evict Terraform cache entries", slog.Error(err))
+	}
+	return nil
+}
+
+func (e *executor) observeCache(cache, result string) {
+	if e.server.cacheMetrics == nil {
+		return
+	}
+	e.server.cacheMetrics.WithLabelValues(cache, result).Inc()
+}
+
+// staleTerraformPluginsError is returned by initWithCache when stale plugins
+// could not be removed from the cache.
+type staleTerraformPluginsError struct {
+	err error
+}
+
+func (e staleTerraformPluginsError) Error() string {
+	return e.err.Error()
+}
+
+func (e staleTerraformPluginsError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,130 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/provisionersdk/proto"
+)
+
+func TestModuleCacheKey(t *testing.T) {
+	t.Parallel()
+
+	writeConfig := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755)
+			require.NoError(t, err)
+			err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+			require.NoError(t, err)
+		}
+		return dir
+	}
+
+	config := map[string]string{
+		"main.tf":          `module "foo" { source = "./foo" }`,
+		"foo/main.tf.json": `{}`,
+		"README.md":        "ignored",
+	}
+	key, err := moduleCacheKey(writeConfig(t, config))
+	require.NoError(t, err)
+
+	// Non-Terraform files and the .terraform directory don't affect the key.
+	config["README.md"] = "still ignored"
+	config[".terraform/modules/modules.json"] = `{}`
+	same, err := moduleCacheKey(writeConfig(t, config))
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	config["main.tf"] = `module "bar" { source = "./foo" }`
+	different, err := moduleCacheKey(writeConfig(t, config))
+	require.NoError(t, err)
+	require.NotEqual(t, key, different)
+}
+
+func TestModuleCache_StoreRestore(t *testing.T) {
+	t.Parallel()
+
+	cachePath := t.TempDir()
+	const key = "key"
+	now := time.Now()
+
+	// Nothing is cached yet.
+	workdir := t.TempDir()
+	hit, err := restoreModules(cachePath, key, workdir, now)
+	require.NoError(t, err)
+	require.False(t, hit)
+
+	// Configurations without modules aren't stored.
+	used, err := storeModules(cachePath, key, workdir)
+	require.NoError(t, err)
+	require.False(t, used)
+
+	modulesPath := filepath.Join(workdir, ".terraform", "modules")
+	require.NoError(t, os.MkdirAll(filepath.Join(modulesPath, "foo"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(modulesPath, "modules.json"), []byte(`{}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(modulesPath, "foo", "main.tf"), []byte(`# foo`), 0o600))
+	require.NoError(t, os.Symlink("main.tf", filepath.Join(modulesPath, "foo", "link.tf")))
+
+	used, err = storeModules(cachePath, key, workdir)
+	require.NoError(t, err)
+	require.True(t, used)
+
+	// The modules are restored into a fresh work directory.
+	workdir = t.TempDir()
+	hit, err = restoreModules(cachePath, key, workdir, now)
+	require.NoError(t, err)
+	require.True(t, hit)
+
+	modulesPath = filepath.Join(workdir, ".terraform", "modules")
+	content, err := os.ReadFile(filepath.Join(modulesPath, "foo", "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, "# foo", string(content))
+	link, err := os.Readlink(filepath.Join(modulesPath, "foo", "link.tf"))
+	require.NoError(t, err)
+	require.Equal(t, "main.tf", link)
+
+	// Entries expire, so modules with version ranges pick up new releases.
+	hit, err = restoreModules(cachePath, key, t.TempDir(), now.Add(moduleCacheTTL+time.Minute))
+	require.NoError(t, err)
+	require.False(t, hit)
+
+	// No temporary directories are left behind.
+	entries, err := os.ReadDir(filepath.Join(cachePath, moduleCacheDir))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, key, entries[0].Name())
+}
+
+func TestProviderCacheWriter(t *testing.T) {
+	t.Parallel()
+
+	metrics := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lookups_total",
+	}, []string{"cache", "result"})
+
+	logr := &mockLogger{}
+	writer, doneLogging := logWriter(logr, proto.LogLevel_DEBUG)
+	writer = newProviderCacheWriter(writer, metrics)
+
+	_, err := writer.Write([]byte(`Initializing provider plugins...
+- Finding coder/coder versions matching "~> 0.11.0"...
+- Finding kreuzwerker/docker versions matching "~> 3.0.1"...
+- Using coder/coder v0.11.1 from the shared cache directory
+- Installing kreuzwerker/docker v3.0.2...
+- Installed kreuzwerker/docker v3.0.2 (self-signed, key ID BD080C4571C6104C)
+`))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	<-doneLogging
+
+	require.Len(t, logr.logs, 6)
+	require.Equal(t, float64(1), promtestutil.ToFloat64(metrics.WithLabelValues(cacheLabelProvider, cacheResultHit)))
+	require.Equal(t, float64(1), promtestutil.ToFloat64(metrics.WithLabelValues(cacheLabelProvider, cacheResultMiss)))
+}
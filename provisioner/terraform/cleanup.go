@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,6 +38,129 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 
 	logger.Info(ctx, "clean stale Terraform plugins", slog.F("cache_path", cachePath))
 
+	pluginPaths, err := terraformPluginPaths(ctx, fs, cachePath, logger)
+	if err != nil {
+		return err
+	}
+
+	// Identify stale plugins
+	var stalePlugins []string
+	for _, pluginPath := range pluginPaths {
+		modTime, err := latestModTime(fs, pluginPath)
+		if err != nil {
+			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", pluginPath, err)
+		}
+
+		if modTime.Add(staleTerraformPluginRetention).Before(now) {
+			logger.Info(ctx, "plugin directory is stale and will be removed", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
+			stalePlugins = append(stalePlugins, pluginPath)
+		} else {
+			logger.Debug(ctx, "plugin directory is not stale", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
+		}
+	}
+
+	// Remove stale plugins
+	for _, stalePluginPath := range stalePlugins {
+		err = removeTerraformPlugin(ctx, fs, stalePluginPath, logger)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EvictTerraformCache removes the least recently modified plugins and modules
+// from the Terraform cache directory until its total size is at most maxSize
+// bytes. A maxSize of zero or less disables eviction.
+func EvictTerraformCache(ctx context.Context, cachePath string, fs afero.Fs, maxSize int64, logger slog.Logger) error {
+	if maxSize <= 0 {
+		return nil
+	}
+	cachePath, err := filepath.Abs(cachePath) // sanity check in case the path is e.g. ../../../cache
+	if err != nil {
+		return xerrors.Errorf("unable to determine absolute path %q: %w", cachePath, err)
+	}
+
+	_, err = fs.Stat(cachePath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return xerrors.Errorf("unable to stat cache path %q: %w", cachePath, err)
+	}
+
+	type cacheEntry struct {
+		path    string
+		plugin  bool
+		size    int64
+		modTime time.Time
+	}
+	var entries []cacheEntry
+
+	pluginPaths, err := terraformPluginPaths(ctx, fs, cachePath, logger)
+	if err != nil {
+		return err
+	}
+	for _, pluginPath := range pluginPaths {
+		modTime, err := latestModTime(fs, pluginPath)
+		if err != nil {
+			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", pluginPath, err)
+		}
+		entries = append(entries, cacheEntry{path: pluginPath, plugin: true, modTime: modTime})
+	}
+
+	modulesPath := filepath.Join(cachePath, moduleCacheDir)
+	moduleDirs, err := afero.ReadDir(fs, modulesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("unable to read module cache directory %q: %w", modulesPath, err)
+	}
+	for _, info := range moduleDirs {
+		if !info.IsDir() {
+			continue
+		}
+		// The directory mtime of a module cache entry is the time it was
+		// stored.
+		entries = append(entries, cacheEntry{path: filepath.Join(modulesPath, info.Name()), modTime: info.ModTime()})
+	}
+
+	var total int64
+	for i := range entries {
+		size, err := dirSize(fs, entries[i].path)
+		if err != nil {
+			return xerrors.Errorf("unable to evaluate size of directory %q: %w", entries[i].path, err)
+		}
+		entries[i].size = size
+		total += size
+	}
+	if total <= maxSize {
+		logger.Debug(ctx, "Terraform cache is within size limit", slog.F("size", total), slog.F("max_size", maxSize))
+		return nil
+	}
+
+	// Evict the least recently modified entries first.
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	for _, entry := range entries {
+		if total <= maxSize {
+			break
+		}
+		logger.Info(ctx, "evicting Terraform cache entry", slog.F("path", entry.path), slog.F("size", entry.size), slog.F("mtime", entry.modTime))
+		if entry.plugin {
+			err = removeTerraformPlugin(ctx, fs, entry.path, logger)
+		} else {
+			err = fs.RemoveAll(entry.path)
+		}
+		if err != nil {
+			return xerrors.Errorf("unable to evict %q: %w", entry.path, err)
+		}
+		total -= entry.size
+	}
+	return nil
+}
+
+// terraformPluginPaths returns the plugin directories in the Terraform cache
+// directory.
+func terraformPluginPaths(ctx context.Context, fs afero.Fs, cachePath string, logger slog.Logger) ([]string, error) {
 	// Filter directory trees matching pattern: <repositoryURL>/<company>/<plugin>/<version>/<distribution>
 	filterFunc := func(path string, info os.FileInfo) bool {
 		if !info.IsDir() {
@@ -55,11 +179,16 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 
 	// Review cached Terraform plugins
 	var pluginPaths []string
-	err = afero.Walk(fs, cachePath, func(path string, info os.FileInfo, err error) error {
+	err := afero.Walk(fs, cachePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Modules are cached alongside plugins, but are not plugins.
+		if info.IsDir() && path == filepath.Join(cachePath, moduleCacheDir) {
+			return filepath.SkipDir
+		}
+
 		if !filterFunc(path, info) {
 			return nil
 		}
@@ -69,63 +198,64 @@ func CleanStaleTerraformPlugins(ctx context.Context, cachePath string, fs afero.
 		return nil
 	})
 	if err != nil {
-		return xerrors.Errorf("unable to walk through cache directory %q: %w", cachePath, err)
+		return nil, xerrors.Errorf("unable to walk through cache directory %q: %w", cachePath, err)
 	}
+	return pluginPaths, nil
+}
 
-	// Identify stale plugins
-	var stalePlugins []string
-	for _, pluginPath := range pluginPaths {
-		modTime, err := latestModTime(fs, pluginPath)
-		if err != nil {
-			return xerrors.Errorf("unable to evaluate latest mtime for directory %q: %w", pluginPath, err)
-		}
-
-		if modTime.Add(staleTerraformPluginRetention).Before(now) {
-			logger.Info(ctx, "plugin directory is stale and will be removed", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
-			stalePlugins = append(stalePlugins, pluginPath)
-		} else {
-			logger.Debug(ctx, "plugin directory is not stale", slog.F("plugin_path", pluginPath), slog.F("mtime", modTime))
-		}
+// removeTerraformPlugin removes the plugin directory and compacts the plugin
+// structure by removing the parent directories that are left empty.
+func removeTerraformPlugin(ctx context.Context, fs afero.Fs, pluginPath string, logger slog.Logger) error {
+	// Remove the plugin directory
+	err := fs.RemoveAll(pluginPath)
+	if err != nil {
+		return xerrors.Errorf("unable to remove stale plugin %q: %w", pluginPath, err)
 	}
 
-	// Remove stale plugins
-	for _, stalePluginPath := range stalePlugins {
-		// Remove the plugin directory
-		err = fs.RemoveAll(stalePluginPath)
-		if err != nil {
-			return xerrors.Errorf("unable to remove stale plugin %q: %w", stalePluginPath, err)
+	// Compact the plugin structure by removing empty directories.
+	wd := pluginPath
+	level := 5 // <repositoryURL>/<company>/<plugin>/<version>/<distribution>
+	for {
+		level--
+		if level == 0 {
+			break // do not compact further
 		}
 
-		// Compact the plugin structure by removing empty directories.
-		wd := stalePluginPath
-		level := 5 // <repositoryURL>/<company>/<plugin>/<version>/<distribution>
-		for {
-			level--
-			if level == 0 {
-				break // do not compact further
-			}
+		wd = filepath.Dir(wd)
 
-			wd = filepath.Dir(wd)
-
-			files, err := afero.ReadDir(fs, wd)
-			if err != nil {
-				return xerrors.Errorf("unable to read directory content %q: %w", wd, err)
-			}
+		files, err := afero.ReadDir(fs, wd)
+		if err != nil {
+			return xerrors.Errorf("unable to read directory content %q: %w", wd, err)
+		}
 
-			if len(files) > 0 {
-				break // there are still other plugins
-			}
+		if len(files) > 0 {
+			break // there are still other plugins
+		}
 
-			logger.Debug(ctx, "remove empty directory", slog.F("path", wd))
-			err = fs.Remove(wd)
-			if err != nil {
-				return xerrors.Errorf("unable to remove directory %q: %w", wd, err)
-			}
+		logger.Debug(ctx, "remove empty directory", slog.F("path", wd))
+		err = fs.Remove(wd)
+		if err != nil {
+			return xerrors.Errorf("unable to remove directory %q: %w", wd, err)
 		}
 	}
 	return nil
 }
 
+// dirSize returns the total size of the regular files in the directory.
+func dirSize(fs afero.Fs, path string) (int64, error) {
+	var size int64
+	err := afero.Walk(fs, path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
 // latestModTime walks recursively through the directory content, and locates
 // the last created/modified file.
 func latestModTime(fs afero.Fs, pluginPath string) (time.Time, error) {
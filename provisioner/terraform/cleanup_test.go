@@ -32,6 +32,7 @@ var (
 	now              = time.Date(2023, 6, 3, 4, 5, 6, 0, time.UTC)
 	coderPluginPath  = filepath.Join("registry.terraform.io", "coder", "coder", "0.11.1", "darwin_arm64")
 	dockerPluginPath = filepath.Join("registry.terraform.io", "kreuzwerker", "docker", "2.25.0", "darwin_arm64")
+	moduleKey        = "4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
 )
 
 func TestPluginCache_Golden(t *testing.T) {
@@ -127,6 +128,74 @@ func TestPluginCache_Golden(t *testing.T) {
 		// then
 		diffFileSystem(t, fs)
 	})
+
+	t.Run("modules are not plugins", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		fs, logger := prepare()
+
+		// given
+		addPluginFile(t, fs, coderPluginPath, "terraform-provider-coder_v0.11.1", now.Add(-2*time.Hour))
+
+		// This module is older than 30 days, and nested deep enough to look
+		// like a plugin.
+		addModuleFile(t, fs, moduleKey, filepath.Join("code-server", "examples", "foo", "bar", "main.tf"), now.Add(-31*24*time.Hour))
+
+		// when
+		terraform.CleanStaleTerraformPlugins(ctx, cachePath, fs, now, logger)
+
+		// then
+		diffFileSystem(t, fs)
+	})
+
+	t.Run("least recently used entries are evicted", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+		defer cancel()
+
+		fs, logger := prepare()
+
+		// given
+		// 12 bytes
+		addPluginFile(t, fs, coderPluginPath, "terraform-provider-coder_v0.11.1", now.Add(-2*time.Hour))
+		addPluginFile(t, fs, coderPluginPath, "LICENSE", now.Add(-3*time.Hour))
+		addPluginFile(t, fs, coderPluginPath, "README.md", now.Add(-4*time.Hour))
+		addPluginFolder(t, fs, coderPluginPath, "new_folder", now.Add(-5*time.Hour))
+		addPluginFile(t, fs, coderPluginPath, filepath.Join("new_folder", "foobar.tf"), now.Add(-4*time.Hour))
+
+		// 9 bytes, least recently used
+		addPluginFile(t, fs, dockerPluginPath, "terraform-provider-docker_v2.25.0", now.Add(-3*24*time.Hour))
+		addPluginFile(t, fs, dockerPluginPath, "LICENSE", now.Add(-3*24*time.Hour))
+		addPluginFile(t, fs, dockerPluginPath, "README.md", now.Add(-3*24*time.Hour))
+
+		// 6 bytes
+		addModuleFile(t, fs, moduleKey, filepath.Join("code-server", "main.tf"), now.Add(-24*time.Hour))
+		addModuleFile(t, fs, moduleKey, filepath.Join("code-server", "README.md"), now.Add(-24*time.Hour))
+
+		// when
+		err := terraform.EvictTerraformCache(ctx, cachePath, fs, 20, logger)
+		require.NoError(t, err)
+
+		// then
+		diffFileSystem(t, fs)
+	})
+}
+
+func addModuleFile(t *testing.T, fs afero.Fs, key string, resourcePath string, mtime time.Time) {
+	modulePath := filepath.Join(cachePath, "modules", key)
+	err := fs.MkdirAll(filepath.Dir(filepath.Join(modulePath, resourcePath)), 0o755)
+	require.NoError(t, err, "can't create test folder for module file")
+
+	err = afero.WriteFile(fs, filepath.Join(modulePath, resourcePath), []byte("foo"), 0o644)
+	require.NoError(t, err, "can't create test file")
+
+	// The module cache entry is as old as its directory.
+	err = fs.Chtimes(modulePath, now, mtime)
+	require.NoError(t, err, "can't set times")
 }
 
 func addPluginFile(t *testing.T, fs afero.Fs, pluginPath string, resourcePath string, mtime time.Time) {
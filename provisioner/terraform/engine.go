@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"golang.org/x/xerrors"
+)
+
+// Engine is the Terraform-compatible tool that executes templates.
+type Engine string
+
+const (
+	EngineTerraform Engine = "terraform"
+	EngineOpenTofu  Engine = "tofu"
+)
+
+// providerMirrorConfigFile is written to the cache directory when a provider
+// mirror is configured, and passed to the engine with TF_CLI_CONFIG_FILE.
+const providerMirrorConfigFile = "provider-mirror.tfrc"
+
+func (e Engine) Valid() bool {
+	switch e {
+	case EngineTerraform, EngineOpenTofu:
+		return true
+	}
+	return false
+}
+
+// BinaryName is the name of the executable that's looked up on the $PATH.
+func (e Engine) BinaryName() string {
+	return string(e)
+}
+
+func (e Engine) String() string {
+	if e == EngineOpenTofu {
+		return "OpenTofu"
+	}
+	return "Terraform"
+}
+
+// versionRange returns the versions of the engine that are known to work.
+// Versions below the minimum are rejected, versions at or above the maximum
+// only log a warning.
+func (e Engine) versionRange() (minVersion, maxVersion *version.Version) {
+	if e == EngineOpenTofu {
+		return minOpenTofuVersion, maxOpenTofuVersion
+	}
+	return minTerraformVersion, maxTerraformVersion
+}
+
+// writeProviderMirrorConfig writes a CLI configuration file that installs
+// all providers from the network mirror at mirrorURL, and returns its path.
+func writeProviderMirrorConfig(dir, mirrorURL string) (string, error) {
+	u, err := url.Parse(mirrorURL)
+	if err != nil {
+		return "", xerrors.Errorf("parse provider mirror url: %w", err)
+	}
+	// Both Terraform and OpenTofu refuse to use network mirrors over
+	// plain HTTP.
+	if u.Scheme != "https" || u.Host == "" {
+		return "", xerrors.Errorf("provider mirror url %q must be an absolute https url", mirrorURL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+
+	err = os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return "", xerrors.Errorf("create cache dir: %w", err)
+	}
+	path := filepath.Join(dir, providerMirrorConfigFile)
+	config := fmt.Sprintf(`provider_installation {
+  network_mirror {
+    url = %q
+  }
+}
+`, u.String())
+	err = os.WriteFile(path, []byte(config), 0o600)
+	if err != nil {
+		return "", xerrors.Errorf("write provider mirror config: %w", err)
+	}
+	return path, nil
+}
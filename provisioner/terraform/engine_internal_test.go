@@ -0,0 +1,96 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/v2/testutil"
+)
+
+func TestWriteProviderMirrorConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		path, err := writeProviderMirrorConfig(dir, "https://mirror.example.com/providers")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, providerMirrorConfigFile), path)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, `provider_installation {
+  network_mirror {
+    url = "https://mirror.example.com/providers/"
+  }
+}
+`, string(content))
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := writeProviderMirrorConfig(t.TempDir(), "http://mirror.example.com/")
+		require.ErrorContains(t, err, "must be an absolute https url")
+	})
+
+	t.Run("Relative", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := writeProviderMirrorConfig(t.TempDir(), "mirror/providers")
+		require.ErrorContains(t, err, "must be an absolute https url")
+	})
+}
+
+// nolint:paralleltest // t.Setenv
+func Test_absoluteBinaryPathOpenTofu(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Dummy tofu executable on Windows requires sh which isn't very practical.")
+	}
+
+	for _, tt := range []struct {
+		name          string
+		tofuVersion   string
+		errorContains string
+	}{
+		{
+			name:        "TestCorrectVersion",
+			tofuVersion: "1.8.1",
+		},
+		{
+			name:          "TestOldVersion",
+			tofuVersion:   "1.5.7",
+			errorContains: "OpenTofu 1.5.7 is older than the minimum supported version 1.6.0",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			// OpenTofu reports its version with the same key as Terraform.
+			// #nosec
+			err := os.WriteFile(filepath.Join(tempDir, "tofu"), []byte(`#!/bin/sh
+cat <<EOF
+{"terraform_version": "`+tt.tofuVersion+`", "platform": "linux_amd64"}
+EOF
+`), 0o770)
+			require.NoError(t, err)
+			t.Setenv("PATH", strings.Join([]string{tempDir, os.Getenv("PATH")}, ":"))
+
+			ctx := testutil.Context(t, testutil.WaitShort)
+			path, err := absoluteBinaryPath(ctx, EngineOpenTofu, slogtest.Make(t, nil))
+			if tt.errorContains != "" {
+				require.ErrorContains(t, err, tt.errorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(tempDir, "tofu"), path)
+		})
+	}
+}
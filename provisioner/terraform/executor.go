@@ -34,7 +34,8 @@ type executor struct {
 	server     *server
 	mut        *sync.Mutex
 	binaryPath string
-	// cachePath and workdir must not be used by multiple processes at once.
+	// workdir must not be used by multiple processes at once. Access to
+	// cachePath is guarded by a lock file, see initWithCache.
 	cachePath string
 	workdir   string
 	// used to capture execution times at various stages
@@ -50,6 +51,9 @@ func (e *executor) basicEnv() []string {
 	if e.cachePath != "" && runtime.GOOS == "linux" {
 		env = append(env, "TF_PLUGIN_CACHE_DIR="+e.cachePath)
 	}
+	if e.server.cliConfig != "" {
+		env = append(env, "TF_CLI_CONFIG_FILE="+e.server.cliConfig)
+	}
 	return env
 }
 
@@ -200,7 +204,7 @@ func versionFromBinaryPath(ctx context.Context, binaryPath string) (*version.Ver
 	return version.NewVersion(vj.Version)
 }
 
-func (e *executor) init(ctx, killCtx context.Context, logr logSink) error {
+func (e *executor) init(ctx, killCtx context.Context, logr logSink, env []string) error {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
 	defer span.End()
 
@@ -209,6 +213,7 @@ func (e *executor) init(ctx, killCtx context.Context, logr logSink) error {
 
 	outWriter, doneOut := logWriter(logr, proto.LogLevel_DEBUG)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
+	outWriter = newProviderCacheWriter(outWriter, e.server.cacheMetrics)
 	defer func() {
 		_ = outWriter.Close()
 		_ = errWriter.Close()
@@ -222,7 +227,7 @@ func (e *executor) init(ctx, killCtx context.Context, logr logSink) error {
 		"-input=false",
 	}
 
-	return e.execWriteOutput(ctx, killCtx, args, e.basicEnv(), outWriter, errWriter)
+	return e.execWriteOutput(ctx, killCtx, args, env, outWriter, errWriter)
 }
 
 func getPlanFilePath(workdir string) string {
@@ -234,7 +239,7 @@ func getStateFilePath(workdir string) string {
 }
 
 // revive:disable-next-line:flag-parameter
-func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr logSink, destroy bool) (*proto.PlanComplete, error) {
+func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr logSink, destroy, refreshOnly bool) (*proto.PlanComplete, error) {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
 	defer span.End()
 
@@ -253,6 +258,9 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 	if destroy {
 		args = append(args, "-destroy")
 	}
+	if refreshOnly {
+		args = append(args, "-refresh-only")
+	}
 	for _, variable := range vars {
 		args = append(args, "-var", variable)
 	}
@@ -275,7 +283,7 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 	graphTimings := newTimingAggregator(database.ProvisionerJobTimingStageGraph)
 	graphTimings.ingest(createGraphTimingsEvent(timingGraphStart))
 
-	state, err := e.planResources(ctx, killCtx, planfilePath)
+	state, changes, err := e.planResources(ctx, killCtx, planfilePath, refreshOnly)
 	if err != nil {
 		graphTimings.ingest(createGraphTimingsEvent(timingGraphErrored))
 		return nil, err
@@ -288,6 +296,7 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 		Resources:             state.Resources,
 		ExternalAuthProviders: state.ExternalAuthProviders,
 		Timings:               append(e.timings.aggregate(), graphTimings.aggregate()...),
+		ResourceChanges:       changes,
 	}, nil
 }
 
@@ -308,19 +317,55 @@ func onlyDataResources(sm tfjson.StateModule) tfjson.StateModule {
 	return filtered
 }
 
-// planResources must only be called while the lock is held.
-func (e *executor) planResources(ctx, killCtx context.Context, planfilePath string) (*State, error) {
+// resourceChanges converts the managed resource changes in a plan to the
+// provisioner representation. Data sources and no-op changes are dropped.
+func resourceChanges(changes []*tfjson.ResourceChange) []*proto.ResourceChange {
+	converted := make([]*proto.ResourceChange, 0, len(changes))
+	for _, rc := range changes {
+		if rc.Mode != tfjson.ManagedResourceMode || rc.Change == nil {
+			continue
+		}
+		var action string
+		switch actions := rc.Change.Actions; {
+		// Replace must be checked first, since a replacement is also a
+		// create and a delete.
+		case actions.Replace():
+			action = string(database.ResourceChangeActionReplace)
+		case actions.Create():
+			action = string(database.ResourceChangeActionCreate)
+		case actions.Update():
+			action = string(database.ResourceChangeActionUpdate)
+		case actions.Delete():
+			action = string(database.ResourceChangeActionDelete)
+		default:
+			continue
+		}
+		converted = append(converted, &proto.ResourceChange{
+			Address: rc.Address,
+			Type:    rc.Type,
+			Name:    rc.Name,
+			Action:  action,
+		})
+	}
+	return converted
+}
+
+// planResources must only be called while the lock is held. For refresh-only
+// plans the returned changes are the resources that drifted from the state.
+//
+// revive:disable-next-line:flag-parameter
+func (e *executor) planResources(ctx, killCtx context.Context, planfilePath string, refreshOnly bool) (*State, []*proto.ResourceChange, error) {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
 	defer span.End()
 
 	plan, err := e.showPlan(ctx, killCtx, planfilePath)
 	if err != nil {
-		return nil, xerrors.Errorf("show terraform plan file: %w", err)
+		return nil, nil, xerrors.Errorf("show terraform plan file: %w", err)
 	}
 
 	rawGraph, err := e.graph(ctx, killCtx)
 	if err != nil {
-		return nil, xerrors.Errorf("graph: %w", err)
+		return nil, nil, xerrors.Errorf("graph: %w", err)
 	}
 	modules := []*tfjson.StateModule{}
 	if plan.PriorState != nil {
@@ -338,9 +383,14 @@ func (e *executor) planResources(ctx, killCtx context.Context, planfilePath stri
 
 	state, err := ConvertState(modules, rawGraph)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return state, nil
+	if refreshOnly {
+		// A refresh-only plan never changes resources, it only records the
+		// differences between the state and the live infrastructure.
+		return state, resourceChanges(plan.ResourceDrift), nil
+	}
+	return state, resourceChanges(plan.ResourceChanges), nil
 }
 
 // showPlan must only be called while the lock is held.
@@ -173,3 +173,40 @@ func TestOnlyDataResources(t *testing.T) {
 		})
 	}
 }
+
+func TestResourceChanges(t *testing.T) {
+	t.Parallel()
+
+	change := func(mode tfjson.ResourceMode, address string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    mode,
+			Type:    "docker_volume",
+			Name:    address,
+			Change:  &tfjson.Change{Actions: actions},
+		}
+	}
+
+	got := resourceChanges([]*tfjson.ResourceChange{
+		change(tfjson.ManagedResourceMode, "created", tfjson.ActionCreate),
+		change(tfjson.ManagedResourceMode, "updated", tfjson.ActionUpdate),
+		change(tfjson.ManagedResourceMode, "deleted", tfjson.ActionDelete),
+		change(tfjson.ManagedResourceMode, "replaced", tfjson.ActionDelete, tfjson.ActionCreate),
+		change(tfjson.ManagedResourceMode, "replaced_cbd", tfjson.ActionCreate, tfjson.ActionDelete),
+		change(tfjson.ManagedResourceMode, "unchanged", tfjson.ActionNoop),
+		change(tfjson.DataResourceMode, "data", tfjson.ActionRead),
+		{Address: "no_change", Mode: tfjson.ManagedResourceMode},
+	})
+
+	actions := map[string]string{}
+	for _, rc := range got {
+		actions[rc.Address] = rc.Action
+	}
+	require.Equal(t, map[string]string{
+		"created":      "create",
+		"updated":      "update",
+		"deleted":      "delete",
+		"replaced":     "replace",
+		"replaced_cbd": "replace",
+	}, actions)
+}
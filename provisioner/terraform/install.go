@@ -25,6 +25,11 @@ var (
 	minTerraformVersion = version.Must(version.NewVersion("1.1.0"))
 	maxTerraformVersion = version.Must(version.NewVersion("1.9.9")) // use .9 to automatically allow patch releases
 
+	// OpenTofu isn't downloaded automatically, so it must be installed
+	// alongside the provisioner.
+	minOpenTofuVersion = version.Must(version.NewVersion("1.6.0"))
+	maxOpenTofuVersion = version.Must(version.NewVersion("1.8.9"))
+
 	terraformMinorVersionMismatch = xerrors.New("Terraform binary minor version mismatch.")
 )
 
@@ -3,9 +3,7 @@ package terraform
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"os"
-	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
@@ -14,10 +12,10 @@ import (
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
-	"github.com/mitchellh/go-wordwrap"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/v2/coderd/tracing"
+	"github.com/coder/coder/v2/provisioner/terraform/tfparse"
 	"github.com/coder/coder/v2/provisionersdk"
 	"github.com/coder/coder/v2/provisionersdk/proto"
 )
@@ -29,9 +27,9 @@ func (s *server) Parse(sess *provisionersdk.Session, _ *proto.ParseRequest, _ <-
 	defer span.End()
 
 	// Load the module and print any parse errors.
-	module, diags := tfconfig.LoadModule(sess.WorkDirectory)
+	module, diags := tfparse.LoadModule(sess.WorkDirectory)
 	if diags.HasErrors() {
-		return provisionersdk.ParseErrorf("load module: %s", formatDiagnostics(sess.WorkDirectory, diags))
+		return provisionersdk.ParseErrorf("load module: %s", tfparse.FormatDiagnostics(sess.WorkDirectory, diags))
 	}
 
 	workspaceTags, err := s.loadWorkspaceTags(ctx, module)
@@ -80,8 +78,8 @@ func (s *server) loadWorkspaceTags(ctx context.Context, module *tfconfig.Module)
 		var diags hcl.Diagnostics
 		parser := hclparse.NewParser()
 
-		if !strings.HasSuffix(dataResource.Pos.Filename, ".tf") {
-			s.logger.Debug(ctx, "only .tf files can be parsed", "filename", dataResource.Pos.Filename)
+		if !strings.HasSuffix(dataResource.Pos.Filename, ".tf") && !strings.HasSuffix(dataResource.Pos.Filename, ".tofu") {
+			s.logger.Debug(ctx, "only .tf and .tofu files can be parsed", "filename", dataResource.Pos.Filename)
 			continue
 		}
 		// We know in which HCL file is the data resource defined.
@@ -203,50 +201,6 @@ func convertTerraformVariable(variable *tfconfig.Variable) (*proto.TemplateVaria
 	}, nil
 }
 
-// formatDiagnostics returns a nicely formatted string containing all of the
-// error details within the tfconfig.Diagnostics. We need to use this because
-// the default format doesn't provide much useful information.
-func formatDiagnostics(baseDir string, diags tfconfig.Diagnostics) string {
-	var msgs strings.Builder
-	for _, d := range diags {
-		// Convert severity.
-		severity := "UNKNOWN SEVERITY"
-		switch {
-		case d.Severity == tfconfig.DiagError:
-			severity = "ERROR"
-		case d.Severity == tfconfig.DiagWarning:
-			severity = "WARN"
-		}
-
-		// Determine filepath and line
-		location := "unknown location"
-		if d.Pos != nil {
-			filename, err := filepath.Rel(baseDir, d.Pos.Filename)
-			if err != nil {
-				filename = d.Pos.Filename
-			}
-			location = fmt.Sprintf("%s:%d", filename, d.Pos.Line)
-		}
-
-		_, _ = msgs.WriteString(fmt.Sprintf("\n%s: %s (%s)\n", severity, d.Summary, location))
-
-		// Wrap the details to 80 characters and indent them.
-		if d.Detail != "" {
-			wrapped := wordwrap.WrapString(d.Detail, 78)
-			for _, line := range strings.Split(wrapped, "\n") {
-				_, _ = msgs.WriteString(fmt.Sprintf("> %s\n", line))
-			}
-		}
-	}
-
-	spacer := " "
-	if len(diags) > 1 {
-		spacer = "\n\n"
-	}
-
-	return spacer + strings.TrimSpace(msgs.String())
-}
-
 func compareSourcePos(x, y tfconfig.SourcePos) bool {
 	if x.Filename != y.Filename {
 		return x.Filename < y.Filename
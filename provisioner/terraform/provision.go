@@ -8,7 +8,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/spf13/afero"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
@@ -96,11 +95,6 @@ func (s *server) Plan(
 		}
 	}
 
-	err := CleanStaleTerraformPlugins(sess.Context(), s.cachePath, afero.NewOsFs(), time.Now(), s.logger)
-	if err != nil {
-		return provisionersdk.PlanErrorf("unable to clean stale Terraform plugins: %s", err)
-	}
-
 	s.logger.Debug(ctx, "running initialization")
 
 	// The JSON output of `terraform init` doesn't include discrete fields for capturing timings of each plugin,
@@ -108,10 +102,14 @@ func (s *server) Plan(
 	initTimings := newTimingAggregator(database.ProvisionerJobTimingStageInit)
 	initTimings.ingest(createInitTimingsEvent(timingInitStart))
 
-	err = e.init(ctx, killCtx, sess)
+	err := e.initWithCache(ctx, killCtx, sess, time.Now())
 	if err != nil {
 		initTimings.ingest(createInitTimingsEvent(timingInitErrored))
 
+		if xerrors.As(err, &staleTerraformPluginsError{}) {
+			return provisionersdk.PlanErrorf("unable to clean stale Terraform plugins: %s", err)
+		}
+
 		s.logger.Debug(ctx, "init failed", slog.Error(err))
 		return provisionersdk.PlanErrorf("initialize terraform: %s", err)
 	}
@@ -133,6 +131,7 @@ func (s *server) Plan(
 	resp, err := e.plan(
 		ctx, killCtx, env, vars, sess,
 		request.Metadata.GetWorkspaceTransition() == proto.WorkspaceTransition_DESTROY,
+		request.GetRefreshOnly(),
 	)
 	if err != nil {
 		return provisionersdk.PlanErrorf(err.Error())
@@ -400,6 +400,12 @@ func ConvertState(modules []*tfjson.StateModule, rawGraph string) (*State, error
 				return nil, xerrors.Errorf("invalid app slug %q, please update your coder/coder provider to the latest version and specify the slug property on each coder_app", attrs.Slug)
 			}
 
+			if attrs.URL != "" && !attrs.External {
+				if err := codersdk.ValidateWorkspaceAppURL(attrs.URL); err != nil {
+					return nil, xerrors.Errorf("invalid url for app %q: %w", attrs.Slug, err)
+				}
+			}
+
 			if _, exists := appSlugs[attrs.Slug]; exists {
 				return nil, xerrors.Errorf("duplicate app slug, they must be unique per template: %q", attrs.Slug)
 			}
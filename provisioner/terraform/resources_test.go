@@ -846,6 +846,51 @@ func TestAppSlugValidation(t *testing.T) {
 	require.ErrorContains(t, err, "duplicate app slug")
 }
 
+func TestAppURLValidation(t *testing.T) {
+	t.Parallel()
+
+	// nolint:dogsled
+	_, filename, _, _ := runtime.Caller(0)
+
+	dir := filepath.Join(filepath.Dir(filename), "testdata", "multiple-apps")
+	tfPlanRaw, err := os.ReadFile(filepath.Join(dir, "multiple-apps.tfplan.json"))
+	require.NoError(t, err)
+	tfPlanGraph, err := os.ReadFile(filepath.Join(dir, "multiple-apps.tfplan.dot"))
+	require.NoError(t, err)
+
+	for _, c := range []struct {
+		url   string
+		error string
+	}{
+		{url: "http://localhost:8080"},
+		{url: "unix:///run/jupyter.sock"},
+		{url: "tcp://localhost:5432"},
+		{url: "udp://127.0.0.1:53"},
+		{url: "unix://run/jupyter.sock", error: "must be an absolute path"},
+		{url: "tcp://localhost", error: "must include a host and port"},
+		{url: "udp://localhost:99999", error: "invalid port"},
+		{url: "tcp://localhost:5432/path", error: "must not include a path"},
+	} {
+		var tfPlan tfjson.Plan
+		err = json.Unmarshal(tfPlanRaw, &tfPlan)
+		require.NoError(t, err)
+		for _, resource := range tfPlan.PlannedValues.RootModule.Resources {
+			if resource.Type == "coder_app" {
+				resource.AttributeValues["url"] = c.url
+			}
+		}
+
+		state, err := terraform.ConvertState([]*tfjson.StateModule{tfPlan.PlannedValues.RootModule}, string(tfPlanGraph))
+		if c.error == "" {
+			require.NoError(t, err, c.url)
+			require.NotNil(t, state)
+			continue
+		}
+		require.Nil(t, state)
+		require.ErrorContains(t, err, c.error, c.url)
+	}
+}
+
 func TestMetadataResourceDuplicate(t *testing.T) {
 	t.Parallel()
 
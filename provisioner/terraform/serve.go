@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/cli/safeexec"
+	"github.com/prometheus/client_golang/prometheus"
 	semconv "go.opentelemetry.io/otel/semconv/v1.14.0"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
@@ -21,12 +22,30 @@ import (
 type ServeOptions struct {
 	*provisionersdk.ServeOptions
 
-	// BinaryPath specifies the "terraform" binary to use.
+	// Engine selects whether Terraform or OpenTofu runs templates.
+	//
+	// Default value: EngineTerraform.
+	Engine Engine
+	// BinaryPath specifies the "terraform" or "tofu" binary to use.
 	// If omitted, the $PATH will attempt to find it.
 	BinaryPath string
-	// CachePath must not be used by multiple processes at once.
+	// ProviderMirror is the URL of a provider network mirror. When set,
+	// all providers are installed from the mirror instead of their origin
+	// registries.
+	ProviderMirror string
+	// CachePath is where Terraform providers and modules are cached across
+	// provisioner jobs. Access is guarded by a lock file.
 	CachePath string
-	Tracer    trace.Tracer
+	// CacheMaxSize is the size limit of the cache in bytes. The least recently
+	// used providers and modules are evicted once it is exceeded. A negative
+	// value disables eviction.
+	//
+	// Default value: 10 GiB (DefaultCacheMaxSize).
+	CacheMaxSize int64
+	// CacheMetrics counts cache lookups by the "cache" (provider or module)
+	// and "result" (hit or miss) labels. It is optional.
+	CacheMetrics *prometheus.CounterVec
+	Tracer       trace.Tracer
 
 	// ExitTimeout defines how long we will wait for a running Terraform
 	// command to exit (cleanly) if the provision was stopped. This
@@ -41,10 +60,10 @@ type ServeOptions struct {
 	ExitTimeout time.Duration
 }
 
-func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error) {
-	binaryPath, err := safeexec.LookPath("terraform")
+func absoluteBinaryPath(ctx context.Context, engine Engine, logger slog.Logger) (string, error) {
+	binaryPath, err := safeexec.LookPath(engine.BinaryName())
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary not found: %w", err)
+		return "", xerrors.Errorf("%s binary not found: %w", engine, err)
 	}
 
 	// If the "coder" binary is in the same directory as
@@ -54,21 +73,25 @@ func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error)
 	// to execute this properly!
 	absoluteBinary, err := filepath.Abs(binaryPath)
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary absolute path not found: %w", err)
+		return "", xerrors.Errorf("%s binary absolute path not found: %w", engine, err)
 	}
 
-	// Checking the installed version of Terraform.
+	// Checking the installed version of the engine.
 	installedVersion, err := versionFromBinaryPath(ctx, absoluteBinary)
 	if err != nil {
-		return "", xerrors.Errorf("Terraform binary get version failed: %w", err)
+		return "", xerrors.Errorf("%s binary get version failed: %w", engine, err)
 	}
 
-	logger.Info(ctx, "detected terraform version",
+	minVersion, maxVersion := engine.versionRange()
+	logger.Info(ctx, "detected "+engine.BinaryName()+" version",
 		slog.F("installed_version", installedVersion.String()),
-		slog.F("min_version", minTerraformVersion.String()),
-		slog.F("max_version", maxTerraformVersion.String()))
+		slog.F("min_version", minVersion.String()),
+		slog.F("max_version", maxVersion.String()))
 
-	if installedVersion.LessThan(minTerraformVersion) {
+	if installedVersion.LessThan(minVersion) {
+		if engine == EngineOpenTofu {
+			return "", xerrors.Errorf("OpenTofu %s is older than the minimum supported version %s", installedVersion, minVersion)
+		}
 		logger.Warn(ctx, "installed terraform version too old, will download known good version to cache")
 		return "", terraformMinorVersionMismatch
 	}
@@ -76,10 +99,10 @@ func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error)
 	// Warn if the installed version is newer than what we've decided is the max.
 	// We used to ignore it and download our own version but this makes it easier
 	// to test out newer versions of Terraform.
-	if installedVersion.GreaterThanOrEqual(maxTerraformVersion) {
-		logger.Warn(ctx, "installed terraform version newer than expected, you may experience bugs",
+	if installedVersion.GreaterThanOrEqual(maxVersion) {
+		logger.Warn(ctx, "installed "+engine.BinaryName()+" version newer than expected, you may experience bugs",
 			slog.F("installed_version", installedVersion.String()),
-			slog.F("max_version", maxTerraformVersion.String()))
+			slog.F("max_version", maxVersion.String()))
 	}
 
 	return absoluteBinary, nil
@@ -87,8 +110,14 @@ func absoluteBinaryPath(ctx context.Context, logger slog.Logger) (string, error)
 
 // Serve starts a dRPC server on the provided transport speaking Terraform provisioner.
 func Serve(ctx context.Context, options *ServeOptions) error {
+	if options.Engine == "" {
+		options.Engine = EngineTerraform
+	}
+	if !options.Engine.Valid() {
+		return xerrors.Errorf("unknown engine %q", options.Engine)
+	}
 	if options.BinaryPath == "" {
-		absoluteBinary, err := absoluteBinaryPath(ctx, options.Logger)
+		absoluteBinary, err := absoluteBinaryPath(ctx, options.Engine, options.Logger)
 		if err != nil {
 			// This is an early exit to prevent extra execution in case the context is canceled.
 			// It generally happens in unit tests since this method is asynchronous and
@@ -96,6 +125,10 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 			if xerrors.Is(err, context.Canceled) {
 				return xerrors.Errorf("absolute binary context canceled: %w", err)
 			}
+			// Only Terraform can be downloaded on demand.
+			if options.Engine != EngineTerraform {
+				return xerrors.Errorf("find %s binary: %w", options.Engine, err)
+			}
 
 			options.Logger.Warn(ctx, "no usable terraform binary found, downloading to cache dir",
 				slog.F("terraform_version", TerraformVersion.String()),
@@ -115,23 +148,40 @@ func Serve(ctx context.Context, options *ServeOptions) error {
 	if options.ExitTimeout == 0 {
 		options.ExitTimeout = unhanger.HungJobExitTimeout
 	}
+	if options.CacheMaxSize == 0 {
+		options.CacheMaxSize = DefaultCacheMaxSize
+	}
+	var cliConfigPath string
+	if options.ProviderMirror != "" {
+		var err error
+		cliConfigPath, err = writeProviderMirrorConfig(options.CachePath, options.ProviderMirror)
+		if err != nil {
+			return xerrors.Errorf("configure provider mirror: %w", err)
+		}
+	}
 	return provisionersdk.Serve(ctx, &server{
-		execMut:     &sync.Mutex{},
-		binaryPath:  options.BinaryPath,
-		cachePath:   options.CachePath,
-		logger:      options.Logger,
-		tracer:      options.Tracer,
-		exitTimeout: options.ExitTimeout,
+		execMut:      &sync.Mutex{},
+		binaryPath:   options.BinaryPath,
+		cachePath:    options.CachePath,
+		cacheMaxSize: options.CacheMaxSize,
+		cacheMetrics: options.CacheMetrics,
+		cliConfig:    cliConfigPath,
+		logger:       options.Logger,
+		tracer:       options.Tracer,
+		exitTimeout:  options.ExitTimeout,
 	}, options.ServeOptions)
 }
 
 type server struct {
-	execMut     *sync.Mutex
-	binaryPath  string
-	cachePath   string
-	logger      slog.Logger
-	tracer      trace.Tracer
-	exitTimeout time.Duration
+	execMut      *sync.Mutex
+	binaryPath   string
+	cachePath    string
+	cacheMaxSize int64
+	cacheMetrics *prometheus.CounterVec
+	cliConfig    string
+	logger       slog.Logger
+	tracer       trace.Tracer
+	exitTimeout  time.Duration
 }
 
 func (s *server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
@@ -0,0 +1,399 @@
+package tfparse
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/v2/codersdk"
+	"github.com/coder/coder/v2/provisioner"
+)
+
+// Severity is the severity of a lint finding.
+type Severity string
+
+const (
+	// SeverityError findings fail the template import.
+	SeverityError Severity = "error"
+	// SeverityWarning findings are best-practice violations.
+	SeverityWarning Severity = "warning"
+)
+
+// LintRule describes a check performed by Lint.
+type LintRule struct {
+	ID          string
+	Severity    Severity
+	Description string
+}
+
+// LintRules are all of the checks performed by Lint.
+var LintRules = []LintRule{
+	{
+		ID:          "duplicate-parameter-name",
+		Severity:    SeverityError,
+		Description: "coder_parameter names must be unique.",
+	},
+	{
+		ID:          "invalid-app-slug",
+		Severity:    SeverityError,
+		Description: "coder_app slugs must be valid hostnames.",
+	},
+	{
+		ID:          "duplicate-app-slug",
+		Severity:    SeverityError,
+		Description: "coder_app slugs must be unique per template.",
+	},
+	{
+		ID:          "invalid-app-url",
+		Severity:    SeverityError,
+		Description: "coder_app URLs must be valid for proxying.",
+	},
+	{
+		ID:          "missing-agent",
+		Severity:    SeverityWarning,
+		Description: "Templates should define at least one coder_agent.",
+	},
+	{
+		ID:          "agent-startup-script-behavior",
+		Severity:    SeverityWarning,
+		Description: "coder_agent resources with a startup script should set startup_script_behavior.",
+	},
+	{
+		ID:          "app-healthcheck",
+		Severity:    SeverityWarning,
+		Description: "coder_app resources with a URL should define a healthcheck.",
+	},
+	{
+		ID:          "parameter-validation",
+		Severity:    SeverityWarning,
+		Description: "String and number coder_parameter data sources should define a validation or options.",
+	},
+	{
+		ID:          "mutable-parameter-persistent-resource",
+		Severity:    SeverityWarning,
+		Description: "Mutable coder_parameter values should not be used by resources that persist across workspace restarts.",
+	},
+}
+
+// LintFinding is a problem found in a template.
+type LintFinding struct {
+	Rule     string
+	Severity Severity
+	Message  string
+	// Filename is relative to the template directory.
+	Filename string
+	Line     int
+	Column   int
+}
+
+// Lint statically checks the Terraform template in dir for problems that
+// would otherwise only be reported by a template import, and for violations
+// of best practices. Terraform is not run, so only literal attribute values
+// are checked.
+func Lint(dir string) ([]LintFinding, error) {
+	module, diags := LoadModule(dir)
+	if diags.HasErrors() {
+		return nil, xerrors.Errorf("load module: %s", FormatDiagnostics(dir, diags))
+	}
+
+	l := &linter{dir: dir}
+
+	agents := 0
+	for _, resource := range module.ManagedResources {
+		if resource.Type == "coder_agent" {
+			agents++
+		}
+	}
+	if agents == 0 {
+		l.add("missing-agent", hcl.Range{}, "The template does not define any coder_agent, so workspaces won't be accessible.")
+	}
+
+	// Attributes are only inspected in native syntax files, like
+	// coder_workspace_tags.
+	var filenames []string
+	for _, resource := range module.ManagedResources {
+		filenames = append(filenames, resource.Pos.Filename)
+	}
+	for _, resource := range module.DataResources {
+		filenames = append(filenames, resource.Pos.Filename)
+	}
+	sort.Strings(filenames)
+	parser := hclparse.NewParser()
+	var blocks []*hclsyntax.Block
+	for i, filename := range filenames {
+		if (i > 0 && filenames[i-1] == filename) || !isNativeSyntaxFile(filename) {
+			continue
+		}
+		file, diags := parser.ParseHCLFile(filename)
+		if diags.HasErrors() {
+			return nil, xerrors.Errorf("can't parse the resource file: %s", diags.Error())
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			continue
+		}
+		blocks = append(blocks, body.Blocks...)
+	}
+
+	l.lintAgents(blocks)
+	l.lintApps(blocks)
+	l.lintParameters(blocks)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+type linter struct {
+	dir      string
+	findings []LintFinding
+}
+
+func (l *linter) add(rule string, rng hcl.Range, format string, args ...any) {
+	severity := SeverityWarning
+	for _, r := range LintRules {
+		if r.ID == rule {
+			severity = r.Severity
+			break
+		}
+	}
+	filename := rng.Filename
+	if filename != "" {
+		if rel, err := filepath.Rel(l.dir, filename); err == nil {
+			filename = rel
+		}
+	}
+	l.findings = append(l.findings, LintFinding{
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		Filename: filepath.ToSlash(filename),
+		Line:     rng.Start.Line,
+		Column:   rng.Start.Column,
+	})
+}
+
+func (l *linter) lintAgents(blocks []*hclsyntax.Block) {
+	for _, block := range filterBlocks(blocks, "resource", "coder_agent") {
+		_, hasScript := block.Body.Attributes["startup_script"]
+		_, hasBehavior := block.Body.Attributes["startup_script_behavior"]
+		if hasScript && !hasBehavior {
+			l.add("agent-startup-script-behavior", block.DefRange(),
+				"coder_agent.%s has a startup_script but doesn't set startup_script_behavior, so users may connect before it completes.", block.Labels[1])
+		}
+	}
+}
+
+func (l *linter) lintApps(blocks []*hclsyntax.Block) {
+	appSlugs := make(map[string]struct{})
+	for _, block := range filterBlocks(blocks, "resource", "coder_app") {
+		name := block.Labels[1]
+
+		// Default to the resource name if none is set! Slugs that aren't
+		// literals are only known once the template is imported.
+		slug := name
+		if _, exists := block.Body.Attributes["slug"]; exists {
+			slug, _ = stringAttribute(block.Body, "slug")
+		}
+		if slug != "" {
+			if !provisioner.AppSlugRegex.MatchString(slug) {
+				l.add("invalid-app-slug", block.DefRange(), "coder_app.%s has an invalid slug %q.", name, slug)
+			}
+			if _, exists := appSlugs[slug]; exists {
+				l.add("duplicate-app-slug", block.DefRange(), "coder_app.%s has a duplicate app slug, they must be unique per template: %q.", name, slug)
+			}
+			appSlugs[slug] = struct{}{}
+		}
+
+		external, _ := boolAttribute(block.Body, "external")
+		_, hasURL := block.Body.Attributes["url"]
+		if url, ok := stringAttribute(block.Body, "url"); ok && !external {
+			if err := codersdk.ValidateWorkspaceAppURL(url); err != nil {
+				l.add("invalid-app-url", block.Body.Attributes["url"].SrcRange, "coder_app.%s has an invalid url: %s.", name, err)
+			}
+		}
+		if hasURL && !external && len(filterBlocks(block.Body.Blocks, "healthcheck")) == 0 {
+			l.add("app-healthcheck", block.DefRange(),
+				"coder_app.%s has no healthcheck, so users won't know whether the app is ready.", name)
+		}
+	}
+}
+
+func (l *linter) lintParameters(blocks []*hclsyntax.Block) {
+	paramNames := make(map[string]struct{})
+	mutableParams := make(map[string]string)
+	for _, block := range filterBlocks(blocks, "data", "coder_parameter") {
+		name := block.Labels[1]
+
+		if paramName, ok := stringAttribute(block.Body, "name"); ok {
+			if _, exists := paramNames[paramName]; exists {
+				l.add("duplicate-parameter-name", block.DefRange(), "coder_parameter names must be unique but %q appears multiple times.", paramName)
+			}
+			paramNames[paramName] = struct{}{}
+		}
+
+		paramType := "string"
+		if _, exists := block.Body.Attributes["type"]; exists {
+			paramType, _ = stringAttribute(block.Body, "type")
+		}
+		if (paramType == "string" || paramType == "number") &&
+			len(filterBlocks(block.Body.Blocks, "validation")) == 0 &&
+			len(filterBlocks(block.Body.Blocks, "option")) == 0 {
+			l.add("parameter-validation", block.DefRange(),
+				"data.coder_parameter.%s has neither a validation nor options, so any %s value is accepted.", name, paramType)
+		}
+
+		if mutable, _ := boolAttribute(block.Body, "mutable"); mutable {
+			paramName, ok := stringAttribute(block.Body, "name")
+			if !ok {
+				paramName = name
+			}
+			mutableParams[name] = paramName
+		}
+	}
+
+	if len(mutableParams) == 0 {
+		return
+	}
+	for _, block := range filterBlocks(blocks, "resource") {
+		if len(block.Labels) != 2 || strings.HasPrefix(block.Labels[0], "coder_") {
+			continue
+		}
+		// Resources counted by the workspace transition are recreated on
+		// every start, so changing a parameter is harmless.
+		if count, ok := block.Body.Attributes["count"]; ok && referencesWorkspaceTransition(count.Expr) {
+			continue
+		}
+		reported := make(map[string]struct{})
+		_ = hclsyntax.VisitAll(block.Body, func(node hclsyntax.Node) hcl.Diagnostics {
+			expr, ok := node.(*hclsyntax.ScopeTraversalExpr)
+			if !ok {
+				return nil
+			}
+			name, ok := parameterReference(expr.Traversal)
+			if !ok {
+				return nil
+			}
+			paramName, ok := mutableParams[name]
+			if !ok {
+				return nil
+			}
+			if _, ok := reported[name]; ok {
+				return nil
+			}
+			reported[name] = struct{}{}
+			l.add("mutable-parameter-persistent-resource", expr.SrcRange,
+				"%s.%s persists across workspace restarts and depends on the mutable parameter %q. Changing it may replace the resource and break existing workspaces.",
+				block.Labels[0], block.Labels[1], paramName)
+			return nil
+		})
+	}
+}
+
+// filterBlocks returns the blocks of the given type whose labels start with
+// the given labels.
+func filterBlocks(blocks []*hclsyntax.Block, typ string, labels ...string) []*hclsyntax.Block {
+	var filtered []*hclsyntax.Block
+	for _, block := range blocks {
+		if block.Type != typ || len(block.Labels) < len(labels) {
+			continue
+		}
+		match := true
+		for i, label := range labels {
+			if block.Labels[i] != label {
+				match = false
+				break
+			}
+		}
+		if match {
+			filtered = append(filtered, block)
+		}
+	}
+	return filtered
+}
+
+// stringAttribute returns the value of the attribute if it's a literal
+// string.
+func stringAttribute(body *hclsyntax.Body, name string) (string, bool) {
+	value, ok := literalAttribute(body, name)
+	if !ok || value.Type() != cty.String {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// boolAttribute returns the value of the attribute if it's a literal bool.
+func boolAttribute(body *hclsyntax.Body, name string) (bool, bool) {
+	value, ok := literalAttribute(body, name)
+	if !ok || value.Type() != cty.Bool {
+		return false, false
+	}
+	return value.True(), true
+}
+
+func literalAttribute(body *hclsyntax.Body, name string) (cty.Value, bool) {
+	attr, ok := body.Attributes[name]
+	if !ok {
+		return cty.NilVal, false
+	}
+	// Expressions that reference variables can't be evaluated statically.
+	if len(attr.Expr.Variables()) > 0 {
+		return cty.NilVal, false
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || !value.IsKnown() || value.IsNull() {
+		return cty.NilVal, false
+	}
+	return value, true
+}
+
+// parameterReference returns the name of the coder_parameter data source
+// referenced by the traversal.
+func parameterReference(traversal hcl.Traversal) (string, bool) {
+	if len(traversal) < 3 || traversal.RootName() != "data" {
+		return "", false
+	}
+	typ, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok || typ.Name != "coder_parameter" {
+		return "", false
+	}
+	name, ok := traversal[2].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	return name.Name, true
+}
+
+func referencesWorkspaceTransition(expr hcl.Expression) bool {
+	for _, traversal := range expr.Variables() {
+		if len(traversal) < 3 || traversal.RootName() != "data" {
+			continue
+		}
+		typ, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok || typ.Name != "coder_workspace" {
+			continue
+		}
+		for _, step := range traversal[2:] {
+			attr, ok := step.(hcl.TraverseAttr)
+			if ok && (attr.Name == "start_count" || attr.Name == "transition") {
+				return true
+			}
+		}
+	}
+	return false
+}
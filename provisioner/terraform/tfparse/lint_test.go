@@ -0,0 +1,260 @@
+package tfparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/v2/provisioner/terraform/tfparse"
+)
+
+const lintAgent = `
+resource "coder_agent" "main" {
+  os   = "linux"
+  arch = "amd64"
+}
+`
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name          string
+		Files         map[string]string
+		Findings      []tfparse.LintFinding
+		ErrorContains string
+	}{
+		{
+			Name: "Clean",
+			Files: map[string]string{
+				"main.tf": lintAgent + `
+data "coder_workspace" "me" {}
+
+data "coder_parameter" "region" {
+  name    = "region"
+  default = "us"
+  mutable = true
+  option {
+    name  = "US"
+    value = "us"
+  }
+}
+
+resource "coder_app" "code-server" {
+  agent_id = coder_agent.main.id
+  url      = "http://localhost:8080"
+  healthcheck {
+    url       = "http://localhost:8080/healthz"
+    interval  = 5
+    threshold = 6
+  }
+}
+
+resource "docker_container" "workspace" {
+  count = data.coder_workspace.me.start_count
+  env   = [data.coder_parameter.region.value]
+}
+`,
+			},
+		},
+		{
+			Name: "InvalidModule",
+			Files: map[string]string{
+				"main.tf": `resource "coder_agent" {`,
+			},
+			ErrorContains: "load module",
+		},
+		{
+			Name: "MissingAgent",
+			Files: map[string]string{
+				"main.tf": `resource "null_resource" "example" {}`,
+			},
+			Findings: []tfparse.LintFinding{{
+				Rule:     "missing-agent",
+				Severity: tfparse.SeverityWarning,
+				Message:  "The template does not define any coder_agent, so workspaces won't be accessible.",
+			}},
+		},
+		{
+			Name: "DuplicateParameterName",
+			Files: map[string]string{
+				"main.tf": lintAgent,
+				"params.tf": `data "coder_parameter" "a" {
+  name = "example"
+  type = "bool"
+}
+
+data "coder_parameter" "b" {
+  name = "example"
+  type = "bool"
+}
+`,
+			},
+			Findings: []tfparse.LintFinding{{
+				Rule:     "duplicate-parameter-name",
+				Severity: tfparse.SeverityError,
+				Message:  `coder_parameter names must be unique but "example" appears multiple times.`,
+				Filename: "params.tf",
+				Line:     6,
+				Column:   1,
+			}},
+		},
+		{
+			Name: "Apps",
+			Files: map[string]string{
+				"main.tf": lintAgent,
+				"apps.tf": `resource "coder_app" "Invalid_Slug" {
+  agent_id = coder_agent.main.id
+  command  = "htop"
+}
+
+resource "coder_app" "first" {
+  agent_id = coder_agent.main.id
+  slug     = "app"
+  command  = "htop"
+}
+
+resource "coder_app" "second" {
+  agent_id = coder_agent.main.id
+  slug     = "app"
+  command  = "htop"
+}
+
+resource "coder_app" "web" {
+  agent_id = coder_agent.main.id
+  url      = "http://localhost:8080"
+}
+
+resource "coder_app" "docs" {
+  agent_id = coder_agent.main.id
+  url      = "https://coder.com/docs"
+  external = true
+}
+`,
+			},
+			Findings: []tfparse.LintFinding{{
+				Rule:     "invalid-app-slug",
+				Severity: tfparse.SeverityError,
+				Message:  `coder_app.Invalid_Slug has an invalid slug "Invalid_Slug".`,
+				Filename: "apps.tf",
+				Line:     1,
+				Column:   1,
+			}, {
+				Rule:     "duplicate-app-slug",
+				Severity: tfparse.SeverityError,
+				Message:  `coder_app.second has a duplicate app slug, they must be unique per template: "app".`,
+				Filename: "apps.tf",
+				Line:     12,
+				Column:   1,
+			}, {
+				Rule:     "app-healthcheck",
+				Severity: tfparse.SeverityWarning,
+				Message:  "coder_app.web has no healthcheck, so users won't know whether the app is ready.",
+				Filename: "apps.tf",
+				Line:     18,
+				Column:   1,
+			}},
+		},
+		{
+			Name: "AgentStartupScript",
+			Files: map[string]string{
+				"main.tf": `resource "coder_agent" "main" {
+  os             = "linux"
+  arch           = "amd64"
+  startup_script = "echo hello"
+}
+
+resource "coder_agent" "blocking" {
+  os                      = "linux"
+  arch                    = "amd64"
+  startup_script          = "echo hello"
+  startup_script_behavior = "blocking"
+}
+`,
+			},
+			Findings: []tfparse.LintFinding{{
+				Rule:     "agent-startup-script-behavior",
+				Severity: tfparse.SeverityWarning,
+				Message:  "coder_agent.main has a startup_script but doesn't set startup_script_behavior, so users may connect before it completes.",
+				Filename: "main.tf",
+				Line:     1,
+				Column:   1,
+			}},
+		},
+		{
+			Name: "Parameters",
+			Files: map[string]string{
+				"main.tf": lintAgent + `
+data "coder_parameter" "name" {
+  name    = "name"
+  default = "dev"
+}
+
+data "coder_parameter" "cpu" {
+  name    = "cpu"
+  type    = "number"
+  default = 2
+  validation {
+    min = 1
+    max = 8
+  }
+}
+
+data "coder_parameter" "disk" {
+  name    = "Disk size"
+  type    = "number"
+  default = 10
+  mutable = true
+  validation {
+    min = 10
+  }
+}
+
+resource "docker_volume" "home" {
+  name = "home"
+  labels {
+    label = "size"
+    value = data.coder_parameter.disk.value
+  }
+}
+`,
+			},
+			Findings: []tfparse.LintFinding{{
+				Rule:     "parameter-validation",
+				Severity: tfparse.SeverityWarning,
+				Message:  "data.coder_parameter.name has neither a validation nor options, so any string value is accepted.",
+				Filename: "main.tf",
+				Line:     7,
+				Column:   1,
+			}, {
+				Rule:     "mutable-parameter-persistent-resource",
+				Severity: tfparse.SeverityWarning,
+				Message:  `docker_volume.home persists across workspace restarts and depends on the mutable parameter "Disk size". Changing it may replace the resource and break existing workspaces.`,
+				Filename: "main.tf",
+				Line:     36,
+				Column:   13,
+			}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for name, content := range tc.Files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600)
+				require.NoError(t, err)
+			}
+
+			findings, err := tfparse.Lint(dir)
+			if tc.ErrorContains != "" {
+				require.ErrorContains(t, err, tc.ErrorContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Findings, findings)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package tfparse
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// LoadModule reads the Terraform module in dir like tfconfig.LoadModule, but
+// also reads the OpenTofu-specific .tofu and .tofu.json files, which
+// tfconfig ignores. Like OpenTofu, a .tofu file replaces the .tf file of the
+// same name, and a .tofu.json file the .tf.json file.
+func LoadModule(dir string) (*tfconfig.Module, tfconfig.Diagnostics) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// tfconfig reports unreadable directories.
+		return tfconfig.LoadModule(dir)
+	}
+	names := make(map[string]bool, len(entries))
+	hasTofu := false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		names[entry.Name()] = true
+		if ext := configFileExt(entry.Name()); ext == ".tofu" || ext == ".tofu.json" {
+			hasTofu = true
+		}
+	}
+	if !hasTofu {
+		return tfconfig.LoadModule(dir)
+	}
+
+	var primary, override []string
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := configFileExt(name)
+		if entry.IsDir() || ext == "" || isIgnoredFile(name) {
+			continue
+		}
+		base := strings.TrimSuffix(name, ext)
+		if (ext == ".tf" && names[base+".tofu"]) || (ext == ".tf.json" && names[base+".tofu.json"]) {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if base == "override" || strings.HasSuffix(base, "_override") {
+			override = append(override, path)
+		} else {
+			primary = append(primary, path)
+		}
+	}
+
+	// Overrides are loaded last, as tfconfig does.
+	module := tfconfig.NewModule(dir)
+	parser := hclparse.NewParser()
+	var diags hcl.Diagnostics
+	for _, path := range append(primary, override...) {
+		var file *hcl.File
+		var fileDiags hcl.Diagnostics
+		if strings.HasSuffix(path, ".json") {
+			file, fileDiags = parser.ParseJSONFile(path)
+		} else {
+			file, fileDiags = parser.ParseHCLFile(path)
+		}
+		diags = append(diags, fileDiags...)
+		if file == nil {
+			continue
+		}
+		diags = append(diags, tfconfig.LoadModuleFromFile(file, module)...)
+	}
+	return module, convertDiagnostics(diags)
+}
+
+// isNativeSyntaxFile returns whether filename is a configuration file in
+// native HCL syntax rather than JSON.
+func isNativeSyntaxFile(filename string) bool {
+	ext := configFileExt(filename)
+	return ext == ".tf" || ext == ".tofu"
+}
+
+func configFileExt(name string) string {
+	for _, ext := range []string{".tf.json", ".tofu.json", ".tf", ".tofu"} {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return ""
+}
+
+// isIgnoredFile matches the editor and hidden files skipped by tfconfig.
+func isIgnoredFile(name string) bool {
+	return strings.HasPrefix(name, ".") ||
+		strings.HasSuffix(name, "~") ||
+		strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#")
+}
+
+func convertDiagnostics(diags hcl.Diagnostics) tfconfig.Diagnostics {
+	if len(diags) == 0 {
+		return nil
+	}
+	converted := make(tfconfig.Diagnostics, 0, len(diags))
+	for _, diag := range diags {
+		d := tfconfig.Diagnostic{
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+			Severity: tfconfig.DiagWarning,
+		}
+		if diag.Severity == hcl.DiagError {
+			d.Severity = tfconfig.DiagError
+		}
+		if diag.Subject != nil {
+			d.Pos = &tfconfig.SourcePos{
+				Filename: diag.Subject.Filename,
+				Line:     diag.Subject.Start.Line,
+			}
+		}
+		converted = append(converted, d)
+	}
+	return converted
+}
@@ -0,0 +1,103 @@
+package tfparse_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+
+	"github.com/coder/coder/v2/provisioner/terraform/tfparse"
+)
+
+func TestLoadModule(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name      string
+		Files     map[string]string
+		Variables []string
+	}{
+		{
+			Name: "Terraform",
+			Files: map[string]string{
+				"main.tf":          `variable "a" {}`,
+				"extra.tf.json":    `{"variable": {"b": {}}}`,
+				"ignored.tofu~":    `variable "c" {}`,
+				"README.md":        `variable "d" {}`,
+				".hidden.tf":       `variable "e" {}`,
+				"sub/nested.tf":    `variable "f" {}`,
+				"main_override.tf": `variable "a" { default = "override" }`,
+			},
+			Variables: []string{"a", "b"},
+		},
+		{
+			Name: "TofuReplacesTerraform",
+			Files: map[string]string{
+				"main.tf":         `variable "a" {}`,
+				"main.tofu":       `variable "b" {}`,
+				"extra.tf.json":   `{"variable": {"c": {}}}`,
+				"extra.tofu.json": `{"variable": {"d": {}}}`,
+				"other.tf":        `variable "e" {}`,
+			},
+			Variables: []string{"b", "d", "e"},
+		},
+		{
+			Name: "TofuOnly",
+			Files: map[string]string{
+				"main.tofu": `
+variable "a" {}
+
+data "coder_workspace_tags" "tags" {
+  tags = {
+    "cluster" = "a"
+  }
+}
+`,
+			},
+			Variables: []string{"a"},
+		},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for name, content := range tc.Files {
+				require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+			}
+
+			module, diags := tfparse.LoadModule(dir)
+			require.False(t, diags.HasErrors(), tfparse.FormatDiagnostics(dir, diags))
+			require.ElementsMatch(t, tc.Variables, maps.Keys(module.Variables))
+		})
+	}
+
+	t.Run("DataResourcePosition", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`variable "a" {}`), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "tags.tofu"), []byte(`data "coder_workspace_tags" "tags" {}`), 0o600))
+
+		module, diags := tfparse.LoadModule(dir)
+		require.False(t, diags.HasErrors())
+		resource, ok := module.DataResources["data.coder_workspace_tags.tags"]
+		require.True(t, ok)
+		// The real filename is kept, so the file can be parsed again.
+		require.Equal(t, filepath.Join(dir, "tags.tofu"), resource.Pos.Filename)
+	})
+
+	t.Run("SyntaxError", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tofu"), []byte(`variable "a" {`), 0o600))
+
+		_, diags := tfparse.LoadModule(dir)
+		require.True(t, diags.HasErrors())
+		require.Contains(t, tfparse.FormatDiagnostics(dir, diags), "main.tofu:1")
+	})
+}
@@ -0,0 +1,56 @@
+// Package tfparse statically inspects Terraform templates without running
+// Terraform.
+package tfparse
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/mitchellh/go-wordwrap"
+)
+
+// FormatDiagnostics returns a nicely formatted string containing all of the
+// error details within the tfconfig.Diagnostics. We need to use this because
+// the default format doesn't provide much useful information.
+func FormatDiagnostics(baseDir string, diags tfconfig.Diagnostics) string {
+	var msgs strings.Builder
+	for _, d := range diags {
+		// Convert severity.
+		severity := "UNKNOWN SEVERITY"
+		switch {
+		case d.Severity == tfconfig.DiagError:
+			severity = "ERROR"
+		case d.Severity == tfconfig.DiagWarning:
+			severity = "WARN"
+		}
+
+		// Determine filepath and line
+		location := "unknown location"
+		if d.Pos != nil {
+			filename, err := filepath.Rel(baseDir, d.Pos.Filename)
+			if err != nil {
+				filename = d.Pos.Filename
+			}
+			location = fmt.Sprintf("%s:%d", filename, d.Pos.Line)
+		}
+
+		_, _ = msgs.WriteString(fmt.Sprintf("\n%s: %s (%s)\n", severity, d.Summary, location))
+
+		// Wrap the details to 80 characters and indent them.
+		if d.Detail != "" {
+			wrapped := wordwrap.WrapString(d.Detail, 78)
+			for _, line := range strings.Split(wrapped, "\n") {
+				_, _ = msgs.WriteString(fmt.Sprintf("> %s\n", line))
+			}
+		}
+	}
+
+	spacer := " "
+	if len(diags) > 1 {
+		spacer = "\n\n"
+	}
+
+	return spacer + strings.TrimSpace(msgs.String())
+}
@@ -2,9 +2,28 @@ package proto
 
 import "github.com/coder/coder/v2/apiversion"
 
+// Version history:
+//
+// API v1.2:
+//   - Add plan_only to AcquiredJob.WorkspaceBuild and resource_changes to
+//     CompletedJob.WorkspaceBuild. Daemons older than v1.2 ignore plan_only
+//     and would apply the build, so coderd must not hand them plan jobs.
+//
+// API v1.3:
+//   - Add refresh_only to AcquiredJob.WorkspaceBuild and PlanRequest for
+//     drift detection. Daemons older than v1.3 would run a regular plan.
+//
+// API v1.4:
+//   - Add build_timeout_ms and cancel_grace_period_ms to
+//     AcquiredJob.WorkspaceBuild. Older daemons ignore them and rely on the
+//     hang detector to terminate builds that exceed the timeout.
+//
+// API v1.5:
+//   - Add timings to CompletedJob.TemplateImport. Template versions imported
+//     by older daemons have no provisioner timings.
 const (
 	CurrentMajor = 1
-	CurrentMinor = 1
+	CurrentMinor = 5
 )
 
 // CurrentVersion is the current provisionerd API version.
@@ -137,6 +137,9 @@ type Server struct {
 
 type Metrics struct {
 	Runner runner.Metrics
+	// TerraformCache counts lookups in the Terraform provider and module
+	// cache of the provisioners.
+	TerraformCache *prometheus.CounterVec
 }
 
 func NewMetrics(reg prometheus.Registerer) Metrics {
@@ -179,6 +182,12 @@ func NewMetrics(reg prometheus.Registerer) Metrics {
 				Help:      "The number of workspaces started, updated, or deleted.",
 			}, []string{"workspace_owner", "workspace_name", "template_name", "template_version", "workspace_transition", "status"}),
 		},
+		TerraformCache: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "provisionerd",
+			Name:      "terraform_cache_lookups_total",
+			Help:      "The number of Terraform provider and module cache lookups.",
+		}, []string{"cache", "result"}),
 	}
 }
 
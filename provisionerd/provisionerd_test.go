@@ -538,6 +538,83 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didFail.Load(), "should fail the job")
 	})
 
+	t.Run("WorkspaceBuildPlanOnly", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			completed atomic.Pointer[proto.CompletedJob]
+			acq       = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: createTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				Type: &proto.AcquiredJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+						Metadata: &sdkproto.Metadata{
+							WorkspaceTransition: sdkproto.WorkspaceTransition_START,
+						},
+						State:    []byte("state"),
+						PlanOnly: true,
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					completed.Store(job)
+					return &proto.Empty{}, nil
+				},
+				commitQuota: func(ctx context.Context, com *proto.CommitQuotaRequest) (*proto.CommitQuotaResponse, error) {
+					t.Error("should not commit quota for a plan")
+					return &proto.CommitQuotaResponse{Ok: true}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.PlanRequest,
+					_ <-chan struct{},
+				) *sdkproto.PlanComplete {
+					return &sdkproto.PlanComplete{
+						Resources: []*sdkproto.Resource{{Name: "home", DailyCost: 10}},
+						ResourceChanges: []*sdkproto.ResourceChange{{
+							Address: "docker_volume.home",
+							Type:    "docker_volume",
+							Name:    "home",
+							Action:  "replace",
+						}},
+					}
+				},
+				apply: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					t.Error("should not apply a plan-only build")
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		job := completed.Load()
+		require.NotNil(t, job, "should complete the job")
+		build := job.GetWorkspaceBuild()
+		require.NotNil(t, build)
+		require.Empty(t, build.State, "state must not be replaced by a plan")
+		require.Len(t, build.ResourceChanges, 1)
+		require.Equal(t, "replace", build.ResourceChanges[0].Action)
+	})
+
 	t.Run("WorkspaceBuildFailComplete", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
@@ -597,6 +674,69 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didFail.Load(), "should fail the job")
 	})
 
+	t.Run("WorkspaceBuildTimeout", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			failedErr atomic.Pointer[string]
+			acq       = newAcquireOne(t, &proto.AcquiredJob{
+				JobId:       "test",
+				Provisioner: "someprovisioner",
+				TemplateSourceArchive: createTar(t, map[string]string{
+					"test.txt": "content",
+				}),
+				Type: &proto.AcquiredJob_WorkspaceBuild_{
+					WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+						Metadata:            &sdkproto.Metadata{},
+						BuildTimeoutMs:      100,
+						CancelGracePeriodMs: testutil.WaitShort.Milliseconds(),
+					},
+				},
+			})
+		)
+
+		closer := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJobWithCancel: acq.acquireWithCancel,
+				updateJob:            noopUpdateJob,
+				failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+					failedErr.Store(&job.Error)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.LocalProvisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				plan: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.PlanRequest,
+					canceledOrComplete <-chan struct{},
+				) *sdkproto.PlanComplete {
+					// Exit gracefully once the build is canceled.
+					<-canceledOrComplete
+					return &sdkproto.PlanComplete{
+						Error: "canceled",
+					}
+				},
+				apply: func(
+					_ *provisionersdk.Session,
+					_ *sdkproto.ApplyRequest,
+					_ <-chan struct{},
+				) *sdkproto.ApplyComplete {
+					t.Error("should not apply when the build timed out")
+					return &sdkproto.ApplyComplete{}
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(acq.complete, testutil.WaitShort))
+		require.NoError(t, closer.Close())
+		jobErr := failedErr.Load()
+		require.NotNil(t, jobErr, "should fail the job")
+		require.Equal(t, "Build exceeded the template's build timeout of 100ms", *jobErr)
+	})
+
 	// Simulates when there is no coderd to connect to. So the client connection
 	// will never be established.
 	t.Run("ShutdownNoCoderd", func(t *testing.T) {
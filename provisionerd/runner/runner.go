@@ -53,6 +53,9 @@ type Runner struct {
 	updateInterval      time.Duration
 	forceCancelInterval time.Duration
 	logBufferInterval   time.Duration
+	// buildTimeout is the template's build timeout for workspace builds.
+	// Zero means no limit.
+	buildTimeout time.Duration
 
 	// session is the provisioning session with the (possibly remote) provisioner
 	session sdkproto.DRPCProvisioner_SessionClient
@@ -73,6 +76,9 @@ type Runner struct {
 	queuedLogs     []*proto.Log
 	failedJob      *proto.FailedJob
 	completedJob   *proto.CompletedJob
+	// timeoutReason is set when the job was canceled because it exceeded
+	// its build timeout, and replaces the error the job fails with.
+	timeoutReason string
 	// setting this false signals that no more messages about this job should be sent.  Usually this
 	// means that a terminal message like FailedJob or CompletedJob has been sent, even in the case
 	// of a Cancel().  However, when someone calls Fail() or ForceStop(), we might not send the
@@ -122,7 +128,14 @@ func New(
 	gracefulContext, cancelFunc := context.WithCancel(forceStopContext)
 
 	logger := opts.Logger.With(slog.F("job_id", job.JobId))
+	forceCancelInterval := opts.ForceCancelInterval
+	var buildTimeout time.Duration
 	if build := job.GetWorkspaceBuild(); build != nil {
+		// The template's settings take precedence over the daemon's.
+		if build.CancelGracePeriodMs > 0 {
+			forceCancelInterval = time.Duration(build.CancelGracePeriodMs) * time.Millisecond
+		}
+		buildTimeout = time.Duration(build.BuildTimeoutMs) * time.Millisecond
 		logger = logger.With(
 			slog.F("template_name", build.Metadata.TemplateName),
 			slog.F("template_version", build.Metadata.TemplateVersion),
@@ -143,8 +156,9 @@ func New(
 		logger:              logger,
 		provisioner:         opts.Provisioner,
 		updateInterval:      opts.UpdateInterval,
-		forceCancelInterval: opts.ForceCancelInterval,
+		forceCancelInterval: forceCancelInterval,
 		logBufferInterval:   opts.LogDebounceInterval,
+		buildTimeout:        buildTimeout,
 		queuedLogs:          make([]*proto.Log, 0),
 		mutex:               m,
 		cond:                sync.NewCond(m),
@@ -285,6 +299,12 @@ func (r *Runner) setFail(f *proto.FailedJob) {
 	defer r.mutex.Unlock()
 	if r.failedJob == nil {
 		f.JobId = r.job.JobId
+		if r.timeoutReason != "" {
+			// The failure is a consequence of canceling the job, so the
+			// timeout is the more useful error to report.
+			f.Error = r.timeoutReason
+			f.ErrorCode = ""
+		}
 		r.failedJob = f
 		r.cond.Signal()
 	}
@@ -458,10 +478,35 @@ func (r *Runner) heartbeatRoutine(ctx context.Context) {
 	ticker := time.NewTicker(r.updateInterval)
 	defer ticker.Stop()
 
+	// A build that exceeds its timeout is canceled gracefully, the same as
+	// a build canceled by a user.
+	var timeout <-chan time.Time
+	if r.buildTimeout > 0 {
+		timeoutTimer := time.NewTimer(r.buildTimeout)
+		defer timeoutTimer.Stop()
+		timeout = timeoutTimer.C
+	}
+
 	for {
 		select {
 		case <-r.notCanceled.Done():
 			return
+		case <-timeout:
+			reason := fmt.Sprintf("Build exceeded the template's build timeout of %s", r.buildTimeout)
+			r.logger.Info(ctx, "build timed out, attempting graceful cancellation", slog.F("timeout", r.buildTimeout))
+			r.mutex.Lock()
+			r.timeoutReason = reason
+			r.mutex.Unlock()
+			r.queueLog(ctx, &proto.Log{
+				Source:    proto.LogSource_PROVISIONER_DAEMON,
+				Level:     sdkproto.LogLevel_ERROR,
+				Stage:     "Canceling build",
+				Output:    reason + ", canceling.",
+				CreatedAt: time.Now().UnixMilli(),
+			})
+			r.Cancel()
+			r.waitForCancel(ctx, reason)
+			return
 		case <-ticker.C:
 		}
 
@@ -480,23 +525,25 @@ func (r *Runner) heartbeatRoutine(ctx context.Context) {
 		}
 		r.logger.Info(ctx, "attempting graceful cancellation")
 		r.Cancel()
-		// Mark the job as failed after a minute of pending cancellation.
-		timer := time.NewTimer(r.forceCancelInterval)
-		select {
-		case <-timer.C:
-			r.logger.Debug(ctx, "cancel timed out")
-			err := r.Fail(ctx, r.failedJobf("Cancel timed out"))
-			if err != nil {
-				r.logger.Warn(ctx, "failed to call FailJob", slog.Error(err))
-			}
-			return
-		case <-r.Done():
-			timer.Stop()
-			return
-		case <-r.notStopped.Done():
-			timer.Stop()
-			return
+		r.waitForCancel(ctx, "Cancel timed out")
+		return
+	}
+}
+
+// waitForCancel marks the job as failed with reason if it hasn't exited
+// within the force cancel interval after being canceled.
+func (r *Runner) waitForCancel(ctx context.Context, reason string) {
+	timer := time.NewTimer(r.forceCancelInterval)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		r.logger.Debug(ctx, "cancel timed out")
+		err := r.Fail(ctx, r.failedJobf("%s", reason))
+		if err != nil {
+			r.logger.Warn(ctx, "failed to call FailJob", slog.Error(err))
 		}
+	case <-r.Done():
+	case <-r.notStopped.Done():
 	}
 }
 
@@ -581,6 +628,7 @@ func (r *Runner) runTemplateImport(ctx context.Context) (*proto.CompletedJob, *p
 				RichParameters:             startProvision.Parameters,
 				ExternalAuthProvidersNames: externalAuthProviderNames,
 				ExternalAuthProviders:      startProvision.ExternalAuthProviders,
+				Timings:                    append(startProvision.Timings, stopProvision.Timings...),
 			},
 		},
 	}, nil
@@ -640,6 +688,7 @@ type templateImportProvision struct {
 	Resources             []*sdkproto.Resource
 	Parameters            []*sdkproto.RichParameter
 	ExternalAuthProviders []*sdkproto.ExternalAuthProviderResource
+	Timings               []*sdkproto.Timing
 }
 
 // Performs a dry-run provision when importing a template.
@@ -731,6 +780,7 @@ func (r *Runner) runTemplateImportProvisionWithRichParameters(
 				Resources:             c.Resources,
 				Parameters:            c.Parameters,
 				ExternalAuthProviders: c.ExternalAuthProviders,
+				Timings:               c.Timings,
 			}, nil
 		default:
 			return nil, xerrors.Errorf("invalid message type %q received from provisioner",
@@ -939,6 +989,7 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 				RichParameterValues:   r.job.GetWorkspaceBuild().RichParameterValues,
 				VariableValues:        r.job.GetWorkspaceBuild().VariableValues,
 				ExternalAuthProviders: r.job.GetWorkspaceBuild().ExternalAuthProviders,
+				RefreshOnly:           r.job.GetWorkspaceBuild().RefreshOnly,
 			},
 		},
 	})
@@ -968,6 +1019,20 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		slog.F("resources", planComplete.Resources),
 	)
 	r.flushQueuedLogs(ctx)
+	if r.job.GetWorkspaceBuild().PlanOnly {
+		// Plan-only builds stop here. Nothing is applied, so no quota is
+		// consumed and the state is left untouched.
+		return &proto.CompletedJob{
+			JobId: r.job.JobId,
+			Type: &proto.CompletedJob_WorkspaceBuild_{
+				WorkspaceBuild: &proto.CompletedJob_WorkspaceBuild{
+					Resources:       planComplete.Resources,
+					Timings:         planComplete.Timings,
+					ResourceChanges: planComplete.ResourceChanges,
+				},
+			},
+		}, nil
+	}
 	if commitQuota {
 		failed = r.commitQuota(ctx, planComplete.Resources)
 		r.flushQueuedLogs(ctx)
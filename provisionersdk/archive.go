@@ -41,13 +41,19 @@ func DirHasLockfile(dir string) (bool, error) {
 	return dirHasExt(dir, ".terraform.lock.hcl")
 }
 
+// DirHasTofuFiles returns whether the directory contains OpenTofu-specific
+// configuration files, which Terraform ignores.
+func DirHasTofuFiles(dir string) (bool, error) {
+	return dirHasExt(dir, ".tofu", ".tofu.json")
+}
+
 // Tar archives a Terraform directory.
 func Tar(w io.Writer, logger slog.Logger, directory string, limit int64) error {
 	// The total bytes written must be under the limit, so use -1
 	w = xio.NewLimitWriter(w, limit-1)
 	tarWriter := tar.NewWriter(w)
 
-	tfExts := []string{".tf", ".tf.json"}
+	tfExts := []string{".tf", ".tf.json", ".tofu", ".tofu.json"}
 	hasTf, err := dirHasExt(directory, tfExts...)
 	if err != nil {
 		return err
@@ -89,6 +89,18 @@ func TestTar(t *testing.T) {
 		err = provisionersdk.Tar(io.Discard, log, dir, 1024)
 		require.NoError(t, err)
 	})
+	t.Run("ValidTofu", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		file, err := os.CreateTemp(dir, "*.tofu")
+		require.NoError(t, err)
+		_ = file.Close()
+		hasTofu, err := provisionersdk.DirHasTofuFiles(dir)
+		require.NoError(t, err)
+		require.True(t, hasTofu)
+		err = provisionersdk.Tar(io.Discard, log, dir, 1024)
+		require.NoError(t, err)
+	})
 	t.Run("HiddenFiles", func(t *testing.T) {
 		t.Parallel()
 		dir := t.TempDir()
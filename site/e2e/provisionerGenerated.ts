@@ -275,6 +275,23 @@ export interface PlanRequest {
 	richParameterValues: RichParameterValue[];
 	variableValues: VariableValue[];
 	externalAuthProviders: ExternalAuthProvider[];
+	/**
+	 * refresh_only runs a refresh-only plan. Its resource changes describe
+	 * how the live infrastructure drifted from the state.
+	 */
+	refreshOnly: boolean;
+}
+
+/**
+ * ResourceChange is a single managed resource the plan would create, update,
+ * replace or delete. Data sources and no-op changes are omitted.
+ */
+export interface ResourceChange {
+	address: string;
+	type: string;
+	name: string;
+	/** action is one of "create", "update", "replace" or "delete". */
+	action: string;
 }
 
 /** PlanComplete indicates a request to plan completed. */
@@ -284,6 +301,7 @@ export interface PlanComplete {
 	parameters: RichParameter[];
 	externalAuthProviders: ExternalAuthProviderResource[];
 	timings: Timing[];
+	resourceChanges: ResourceChange[];
 }
 
 /**
@@ -961,6 +979,30 @@ export const PlanRequest = {
 		for (const v of message.externalAuthProviders) {
 			ExternalAuthProvider.encode(v!, writer.uint32(34).fork()).ldelim();
 		}
+		if (message.refreshOnly === true) {
+			writer.uint32(40).bool(message.refreshOnly);
+		}
+		return writer;
+	},
+};
+
+export const ResourceChange = {
+	encode(
+		message: ResourceChange,
+		writer: _m0.Writer = _m0.Writer.create(),
+	): _m0.Writer {
+		if (message.address !== "") {
+			writer.uint32(10).string(message.address);
+		}
+		if (message.type !== "") {
+			writer.uint32(18).string(message.type);
+		}
+		if (message.name !== "") {
+			writer.uint32(26).string(message.name);
+		}
+		if (message.action !== "") {
+			writer.uint32(34).string(message.action);
+		}
 		return writer;
 	},
 };
@@ -988,6 +1030,9 @@ export const PlanComplete = {
 		for (const v of message.timings) {
 			Timing.encode(v!, writer.uint32(50).fork()).ldelim();
 		}
+		for (const v of message.resourceChanges) {
+			ResourceChange.encode(v!, writer.uint32(58).fork()).ldelim();
+		}
 		return writer;
 	},
 };
@@ -932,6 +932,12 @@ type RenderOAuthAllowData struct {
 	CancelURI   string
 	RedirectURI string
 	Username    string
+	// UserCode is shown when authorizing a device so the user can check it
+	// matches the code on their device.
+	UserCode string
+	// EnterCodeURI replaces the allow prompt with a form asking for a device
+	// user code, which is submitted to this URI.
+	EnterCodeURI string
 }
 
 // RenderOAuthAllowPage renders the static page for a user to "Allow" an create
@@ -406,8 +406,12 @@ class ApiMethods {
 	login = async (
 		email: string,
 		password: string,
+		secondFactor?: Pick<
+			TypesGen.LoginWithPasswordRequest,
+			"totp_code" | "recovery_code"
+		>,
 	): Promise<TypesGen.LoginWithPasswordResponse> => {
-		const payload = JSON.stringify({ email, password });
+		const payload = JSON.stringify({ email, password, ...secondFactor });
 		const response = await this.axios.post<TypesGen.LoginWithPasswordResponse>(
 			"/api/v2/users/login",
 			payload,
@@ -1286,6 +1290,33 @@ class ApiMethods {
 		await this.axios.put(`/api/v2/users/${userId}/password`, updatePassword);
 	};
 
+	getUserMFAStatus = async (
+		userId: TypesGen.User["id"],
+	): Promise<TypesGen.UserMFAStatus> => {
+		const response = await this.axios.get<TypesGen.UserMFAStatus>(
+			`/api/v2/users/${userId}/mfa`,
+		);
+
+		return response.data;
+	};
+
+	enrollTOTP = async (
+		userId: TypesGen.User["id"],
+	): Promise<TypesGen.TOTPEnrollment> => {
+		const response = await this.axios.post<TypesGen.TOTPEnrollment>(
+			`/api/v2/users/${userId}/mfa/totp`,
+		);
+
+		return response.data;
+	};
+
+	confirmTOTP = async (
+		userId: TypesGen.User["id"],
+		req: TypesGen.ConfirmTOTPRequest,
+	): Promise<void> => {
+		await this.axios.post(`/api/v2/users/${userId}/mfa/totp/confirm`, req);
+	};
+
 	getRoles = async (): Promise<Array<TypesGen.AssignableRoles>> => {
 		const response = await this.axios.get<TypesGen.AssignableRoles[]>(
 			"/api/v2/users/roles",
@@ -1,8 +1,10 @@
 import { API } from "api/api";
 import type {
 	AuthorizationRequest,
+	ConfirmTOTPRequest,
 	GenerateAPIKeyResponse,
 	GetUsersResponse,
+	LoginWithPasswordRequest,
 	UpdateUserAppearanceSettingsRequest,
 	UpdateUserPasswordRequest,
 	UpdateUserProfileRequest,
@@ -63,6 +65,30 @@ export const updatePassword = () => {
 	};
 };
 
+const userMFAStatusKey = (userId: string) => ["users", userId, "mfa"];
+
+export const userMFAStatus = (userId: string) => {
+	return {
+		queryKey: userMFAStatusKey(userId),
+		queryFn: () => API.getUserMFAStatus(userId),
+	};
+};
+
+export const enrollTOTP = () => {
+	return {
+		mutationFn: API.enrollTOTP,
+	};
+};
+
+export const confirmTOTP = (userId: string, queryClient: QueryClient) => {
+	return {
+		mutationFn: (req: ConfirmTOTPRequest) => API.confirmTOTP(userId, req),
+		onSuccess: async () => {
+			await queryClient.invalidateQueries(userMFAStatusKey(userId));
+		},
+	};
+};
+
 export const createUser = (queryClient: QueryClient) => {
 	return {
 		mutationFn: API.createUser,
@@ -156,7 +182,7 @@ export const login = (
 	queryClient: QueryClient,
 ) => {
 	return {
-		mutationFn: async (credentials: { email: string; password: string }) =>
+		mutationFn: async (credentials: LoginWithPasswordRequest) =>
 			loginFn({ ...credentials, authorization }),
 		onSuccess: async (data: Awaited<ReturnType<typeof loginFn>>) => {
 			queryClient.setQueryData(["me"], data.user);
@@ -171,13 +197,13 @@ export const login = (
 const loginFn = async ({
 	email,
 	password,
+	totp_code,
+	recovery_code,
 	authorization,
-}: {
-	email: string;
-	password: string;
+}: LoginWithPasswordRequest & {
 	authorization: AuthorizationRequest;
 }) => {
-	await API.login(email, password);
+	await API.login(email, password, { totp_code, recovery_code });
 	const [user, permissions] = await Promise.all([
 		API.getAuthenticatedUser(),
 		API.checkAuthorization(authorization),
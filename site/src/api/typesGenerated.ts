@@ -8,6 +8,12 @@ export interface ACLAvailable {
 	readonly groups: Readonly<Array<Group>>;
 }
 
+// From codersdk/apikey.go
+export interface APIAllowListTarget {
+	readonly type: RBACResource;
+	readonly id: string;
+}
+
 // From codersdk/apikey.go
 export interface APIKey {
 	readonly id: string;
@@ -20,6 +26,8 @@ export interface APIKey {
 	readonly scope: APIKeyScope;
 	readonly token_name: string;
 	readonly lifetime_seconds: number;
+	readonly scopes: Readonly<Array<APIKeyScope>>;
+	readonly allow_list: Readonly<Array<APIAllowListTarget>>;
 }
 
 // From codersdk/apikey.go
@@ -39,6 +47,16 @@ export interface AgentStatsReportResponse {
 	readonly tx_bytes: number;
 }
 
+// From codersdk/workspacebuilds.go
+export interface AgentTiming {
+	readonly agent_id: string;
+	readonly agent_name: string;
+	readonly stage: AgentTimingStage;
+	readonly started_at: string;
+	readonly ended_at: string;
+	readonly status: WorkspaceAgentLifecycle;
+}
+
 // From codersdk/deployment.go
 export interface AppHostResponse {
 	readonly host: string;
@@ -127,6 +145,7 @@ export interface AuthMethods {
 	readonly password: AuthMethod;
 	readonly github: AuthMethod;
 	readonly oidc: OIDCAuthMethod;
+	readonly ldap: AuthMethod;
 }
 
 // From codersdk/authorization.go
@@ -176,6 +195,11 @@ export interface BuildInfoResponse {
 	readonly deployment_id: string;
 }
 
+// From codersdk/users.go
+export interface ConfirmTOTPRequest {
+	readonly code: string;
+}
+
 // From codersdk/insights.go
 export interface ConnectionLatency {
 	readonly p50: number;
@@ -242,6 +266,14 @@ export interface CreateProvisionerKeyResponse {
 	readonly key: string;
 }
 
+// From codersdk/rolegrants.go
+export interface CreateRoleGrantRequest {
+	readonly role_name: string;
+	readonly organization_id?: string;
+	readonly reason: string;
+	readonly lifetime: number;
+}
+
 // From codersdk/organizations.go
 export interface CreateTemplateRequest {
 	readonly name: string;
@@ -264,6 +296,15 @@ export interface CreateTemplateRequest {
 	readonly max_port_share_level?: WorkspaceAgentPortShareLevel;
 }
 
+// From codersdk/templateversioncanaries.go
+export interface CreateTemplateVersionCanaryRequest {
+	readonly template_version_id: string;
+	readonly percent: number;
+	readonly group_id?: string;
+	readonly min_builds: number;
+	readonly success_threshold: number;
+}
+
 // From codersdk/templateversions.go
 export interface CreateTemplateVersionDryRunRequest {
 	readonly workspace_name: string;
@@ -300,6 +341,8 @@ export interface CreateTokenRequest {
 	readonly lifetime: number;
 	readonly scope: APIKeyScope;
 	readonly token_name: string;
+	readonly scopes?: Readonly<Array<APIKeyScope>>;
+	readonly allow_list?: Readonly<Array<APIAllowListTarget>>;
 }
 
 // From codersdk/users.go
@@ -311,6 +354,7 @@ export interface CreateUserRequest {
 	readonly login_type: LoginType;
 	readonly disable_login: boolean;
 	readonly organization_id: string;
+	readonly service_account?: boolean;
 }
 
 // From codersdk/workspaces.go
@@ -324,6 +368,14 @@ export interface CreateWorkspaceBuildRequest {
 	readonly log_level?: ProvisionerLogLevel;
 }
 
+// From codersdk/workspaceplans.go
+export interface CreateWorkspacePlanRequest {
+	readonly template_version_id?: string;
+	readonly transition: WorkspaceTransition;
+	readonly rich_parameter_values?: Readonly<Array<WorkspaceBuildParameter>>;
+	readonly log_level?: ProvisionerLogLevel;
+}
+
 // From codersdk/workspaceproxy.go
 export interface CreateWorkspaceProxyRequest {
 	readonly name: string;
@@ -447,6 +499,7 @@ export interface DeploymentValues {
 	readonly pg_auth?: string;
 	readonly oauth2?: OAuth2Config;
 	readonly oidc?: OIDCConfig;
+	readonly ldap?: LDAPConfig;
 	readonly telemetry?: TelemetryConfig;
 	readonly tls?: TLSConfig;
 	readonly trace?: TraceConfig;
@@ -470,6 +523,7 @@ export interface DeploymentValues {
 	readonly disable_path_apps?: boolean;
 	readonly session_lifetime?: SessionLifetime;
 	readonly disable_password_auth?: boolean;
+	readonly require_owner_mfa?: boolean;
 	readonly support?: SupportConfig;
 	readonly external_auth?: Readonly<Array<ExternalAuthConfig>>;
 	readonly config_ssh?: SSHConfig;
@@ -489,6 +543,27 @@ export interface DeploymentValues {
 	readonly address?: string;
 }
 
+// From codersdk/users.go
+export interface DeviceLoginResponse {
+	readonly device_code: string;
+	readonly user_code: string;
+	readonly verification_url: string;
+	readonly verification_url_complete: string;
+	readonly expires_at: string;
+	readonly interval: number;
+}
+
+// From codersdk/users.go
+export interface DeviceLoginTokenRequest {
+	readonly device_code: string;
+}
+
+// From codersdk/users.go
+export interface DeviceLoginTokenResponse {
+	readonly status: DeviceLoginStatus;
+	readonly session_token?: string;
+}
+
 // From codersdk/deployment.go
 export interface Entitlements {
 	readonly features: Record<FeatureName, Feature>;
@@ -642,6 +717,7 @@ export interface Healthcheck {
 export interface HealthcheckConfig {
 	readonly refresh: number;
 	readonly threshold_database: number;
+	readonly derp_history_interval: number;
 }
 
 // From codersdk/workspaceagents.go
@@ -665,6 +741,26 @@ export interface JFrogXrayScan {
 	readonly results_url: string;
 }
 
+// From codersdk/deployment.go
+export interface LDAPConfig {
+	readonly url: string;
+	readonly start_tls: boolean;
+	readonly insecure_skip_verify: boolean;
+	readonly ca_file: string;
+	readonly bind_dn: string;
+	readonly bind_password: string;
+	readonly search_base_dn: string;
+	readonly search_filter: string;
+	readonly username_attribute: string;
+	readonly email_attribute: string;
+	readonly name_attribute: string;
+	readonly allow_signups: boolean;
+	readonly group_attribute: string;
+	readonly group_mapping: Record<string, string>;
+	readonly group_regex_filter: string;
+	readonly group_auto_create: boolean;
+}
+
 // From codersdk/licenses.go
 export interface License {
 	readonly id: number;
@@ -695,10 +791,18 @@ export interface LoggingConfig {
 	readonly stackdriver: string;
 }
 
+// From codersdk/users.go
+export interface LoginWithLDAPRequest {
+	readonly username: string;
+	readonly password: string;
+}
+
 // From codersdk/users.go
 export interface LoginWithPasswordRequest {
 	readonly email: string;
 	readonly password: string;
+	readonly totp_code?: string;
+	readonly recovery_code?: string;
 }
 
 // From codersdk/users.go
@@ -828,6 +932,8 @@ export interface OAuth2ProviderApp {
 	readonly name: string;
 	readonly callback_url: string;
 	readonly icon: string;
+	readonly public: boolean;
+	readonly client_credentials_user_id?: string;
 	readonly endpoints: OAuth2AppEndpoints;
 }
 
@@ -887,18 +993,45 @@ export interface OIDCConfig {
 	readonly user_role_field: string;
 	readonly user_role_mapping: Record<string, Readonly<Array<string>>>;
 	readonly user_roles_default: string[];
+	readonly organization_field: string;
+	readonly organization_mapping: Record<string, Readonly<Array<string>>>;
 	readonly sign_in_text: string;
 	readonly icon_url: string;
 	readonly signups_disabled_text: string;
 	readonly skip_issuer_checks: boolean;
 }
 
+// From codersdk/users.go
+export interface OIDCSyncDryRunOrganization {
+	readonly id: string;
+	readonly name: string;
+	readonly action: OIDCSyncAction;
+	readonly groups: Readonly<Array<string>>;
+}
+
+// From codersdk/users.go
+export interface OIDCSyncDryRunRequest {
+	// empty interface{} type, falling back to unknown
+	readonly claims: Record<string, unknown>;
+	readonly user_id?: string;
+}
+
+// From codersdk/users.go
+export interface OIDCSyncDryRunResponse {
+	readonly organization_sync_enabled: boolean;
+	readonly group_sync_enabled: boolean;
+	readonly organizations: Readonly<Array<OIDCSyncDryRunOrganization>>;
+	readonly unmatched_organizations: Readonly<Array<string>>;
+}
+
 // From codersdk/organizations.go
 export interface Organization extends MinimalOrganization {
 	readonly description: string;
 	readonly created_at: string;
 	readonly updated_at: string;
 	readonly is_default: boolean;
+	readonly allowed_workspace_proxy_ids: Readonly<Array<string>>;
+	readonly allowed_derp_region_ids: Readonly<Array<number>>;
 }
 
 // From codersdk/organizations.go
@@ -963,6 +1096,8 @@ export interface PostOAuth2ProviderAppRequest {
 	readonly name: string;
 	readonly callback_url: string;
 	readonly icon: string;
+	readonly public?: boolean;
+	readonly client_credentials_user_id?: string;
 }
 
 // From codersdk/workspaces.go
@@ -994,6 +1129,8 @@ export interface ProvisionerConfig {
 	readonly daemon_poll_jitter: number;
 	readonly force_cancel_interval: number;
 	readonly daemon_psk: string;
+	readonly cache_max_size: number;
+	readonly provider_mirror: string;
 }
 
 // From codersdk/provisionerdaemons.go
@@ -1045,6 +1182,17 @@ export interface ProvisionerKey {
 	readonly tags: Record<string, string>;
 }
 
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerTiming {
+	readonly job_id: string;
+	readonly started_at: string;
+	readonly ended_at: string;
+	readonly stage: ProvisionerTimingStage;
+	readonly source: string;
+	readonly action: string;
+	readonly resource: string;
+}
+
 // From codersdk/workspaceproxy.go
 export interface ProxyHealthReport {
 	readonly errors: Readonly<Array<string>>;
@@ -1061,12 +1209,16 @@ export interface PutOAuth2ProviderAppRequest {
 	readonly name: string;
 	readonly callback_url: string;
 	readonly icon: string;
+	readonly public?: boolean;
+	readonly client_credentials_user_id?: string;
 }
 
 // From codersdk/deployment.go
 export interface RateLimitConfig {
 	readonly disable_all: boolean;
 	readonly api: number;
+	readonly workspace_app_max_connections_per_user: number;
+	readonly workspace_app_max_bytes_per_second: number;
 }
 
 // From codersdk/users.go
@@ -1130,6 +1282,26 @@ export interface Role {
 	readonly user_permissions: Readonly<Array<Permission>>;
 }
 
+// From codersdk/rolegrants.go
+export interface RoleGrantRequest {
+	readonly id: string;
+	readonly user_id: string;
+	readonly role: SlimRole;
+	readonly reason: string;
+	readonly lifetime_seconds: number;
+	readonly status: RoleGrantStatus;
+	readonly created_at: string;
+	readonly reviewed_by?: string;
+	readonly reviewed_at?: string;
+	readonly expires_at?: string;
+}
+
+// From codersdk/rolegrants.go
+export interface RoleGrantRequestsFilter {
+	readonly organization_id?: string;
+	readonly status?: RoleGrantStatus;
+}
+
 // From codersdk/deployment.go
 export interface SSHConfig {
 	readonly DeploymentName: string;
@@ -1156,6 +1328,18 @@ export interface ServiceBannerConfig {
 	readonly background_color?: string;
 }
 
+// From codersdk/sessions.go
+export interface Session {
+	readonly id: string;
+	readonly login_type: LoginType;
+	readonly ip_address: string;
+	readonly user_agent: string;
+	readonly created_at: string;
+	readonly last_used: string;
+	readonly expires_at: string;
+	readonly current: boolean;
+}
+
 // From codersdk/deployment.go
 export interface SessionCountDeploymentStats {
 	readonly vscode: number;
@@ -1204,6 +1388,14 @@ export interface TLSConfig {
 	readonly allow_insecure_ciphers: boolean;
 }
 
+// From codersdk/users.go
+export interface TOTPEnrollment {
+	readonly secret: string;
+	readonly url: string;
+	readonly qr_code: string;
+	readonly recovery_codes: Readonly<Array<string>>;
+}
+
 // From codersdk/deployment.go
 export interface TelemetryConfig {
 	readonly enable: boolean;
@@ -1244,6 +1436,13 @@ export interface Template {
 	readonly time_til_dormant_autodelete_ms: number;
 	readonly require_active_version: boolean;
 	readonly max_port_share_level: WorkspaceAgentPortShareLevel;
+	readonly allowed_workspace_proxy_ids: Readonly<Array<string>>;
+	readonly allowed_derp_region_ids: Readonly<Array<number>>;
+	readonly max_app_connections_per_user: number;
+	readonly max_app_bytes_per_second: number;
+	readonly drift_detection_interval_ms: number;
+	readonly build_timeout_ms: number;
+	readonly build_cancel_grace_period_ms: number;
 }
 
 // From codersdk/templates.go
@@ -1370,6 +1569,25 @@ export interface TemplateVersion {
 	readonly warnings?: Readonly<Array<TemplateVersionWarning>>;
 }
 
+// From codersdk/templateversioncanaries.go
+export interface TemplateVersionCanary {
+	readonly id: string;
+	readonly template_id: string;
+	readonly template_version_id: string;
+	readonly percent: number;
+	readonly group_id?: string;
+	readonly min_builds: number;
+	readonly success_threshold: number;
+	readonly status: TemplateVersionCanaryStatus;
+	readonly status_reason: string;
+	readonly succeeded_builds: number;
+	readonly failed_builds: number;
+	readonly created_by: string;
+	readonly created_at: string;
+	readonly updated_at: string;
+	readonly completed_at?: string;
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionExternalAuth {
 	readonly id: string;
@@ -1481,6 +1699,8 @@ export interface UpdateOrganizationRequest {
 	readonly display_name?: string;
 	readonly description?: string;
 	readonly icon?: string;
+	readonly allowed_workspace_proxy_ids?: Readonly<Array<string>>;
+	readonly allowed_derp_region_ids?: Readonly<Array<number>>;
 }
 
 // From codersdk/users.go
@@ -1516,6 +1736,18 @@ export interface UpdateTemplateMeta {
 	readonly deprecation_message?: string;
 	readonly disable_everyone_group_access: boolean;
 	readonly max_port_share_level?: WorkspaceAgentPortShareLevel;
+	readonly allowed_workspace_proxy_ids?: Readonly<Array<string>>;
+	readonly allowed_derp_region_ids?: Readonly<Array<number>>;
+	readonly max_app_connections_per_user?: number;
+	readonly max_app_bytes_per_second?: number;
+	readonly drift_detection_interval_ms?: number;
+	readonly build_timeout_ms?: number;
+	readonly build_cancel_grace_period_ms?: number;
+}
+
+// From codersdk/templateversioncanaries.go
+export interface UpdateTemplateVersionCanaryRequest {
+	readonly status: TemplateVersionCanaryStatus;
 }
 
 // From codersdk/users.go
@@ -1593,6 +1825,7 @@ export interface UpsertWorkspaceAgentPortShareRequest {
 export interface User extends ReducedUser {
 	readonly organization_ids: Readonly<Array<string>>;
 	readonly roles: Readonly<Array<SlimRole>>;
+	readonly is_service_account: boolean;
 }
 
 // From codersdk/insights.go
@@ -1658,6 +1891,13 @@ export interface UserLoginType {
 	readonly login_type: LoginType;
 }
 
+// From codersdk/users.go
+export interface UserMFAStatus {
+	readonly totp_enabled: boolean;
+	readonly recovery_codes_remaining: number;
+	readonly required: boolean;
+}
+
 // From codersdk/users.go
 export interface UserParameter {
 	readonly name: string;
@@ -1860,6 +2100,7 @@ export interface WorkspaceApp {
 	readonly id: string;
 	readonly url: string;
 	readonly external: boolean;
+	readonly protocol?: WorkspaceAppProtocol;
 	readonly slug: string;
 	readonly display_name: string;
 	readonly command?: string;
@@ -1902,6 +2143,12 @@ export interface WorkspaceBuildParameter {
 	readonly value: string;
 }
 
+// From codersdk/workspacebuilds.go
+export interface WorkspaceBuildTimings {
+	readonly provisioner_timings: Readonly<Array<ProvisionerTiming>>;
+	readonly agent_timings: Readonly<Array<AgentTiming>>;
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceBuildsRequest extends Pagination {
 	readonly since?: string;
@@ -1925,6 +2172,13 @@ export interface WorkspaceDeploymentStats {
 	readonly tx_bytes: number;
 }
 
+// From codersdk/workspaceplans.go
+export interface WorkspaceDrift {
+	readonly checked_at?: string;
+	readonly latest_check?: ProvisionerJob;
+	readonly resources: Readonly<Array<WorkspaceResourceChange>>;
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceFilter {
 	readonly q?: string;
@@ -1941,6 +2195,12 @@ export interface WorkspaceOptions {
 	readonly include_deleted?: boolean;
 }
 
+// From codersdk/workspaceplans.go
+export interface WorkspacePlan {
+	readonly job: ProvisionerJob;
+	readonly resource_changes: Readonly<Array<WorkspaceResourceChange>>;
+}
+
 // From codersdk/workspaceproxy.go
 export interface WorkspaceProxy extends Region {
 	readonly derp_enabled: boolean;
@@ -1986,6 +2246,15 @@ export interface WorkspaceResource {
 	readonly daily_cost: number;
 }
 
+// From codersdk/workspaceplans.go
+export interface WorkspaceResourceChange {
+	readonly address: string;
+	readonly type: string;
+	readonly name: string;
+	readonly action: ResourceChangeAction;
+	readonly destructive: boolean;
+}
+
 // From codersdk/workspacebuilds.go
 export interface WorkspaceResourceMetadata {
 	readonly key: string;
@@ -2005,16 +2274,20 @@ export interface WorkspacesResponse {
 }
 
 // From codersdk/apikey.go
-export type APIKeyScope = "all" | "application_connect"
-export const APIKeyScopes: APIKeyScope[] = ["all", "application_connect"]
+export type APIKeyScope = "all" | "application_connect" | "template:read" | "template:update" | "user:read" | "workspace:create" | "workspace:delete" | "workspace:read" | "workspace:ssh" | "workspace:start" | "workspace:stop"
+export const APIKeyScopes: APIKeyScope[] = ["all", "application_connect", "template:read", "template:update", "user:read", "workspace:create", "workspace:delete", "workspace:read", "workspace:ssh", "workspace:start", "workspace:stop"]
 
 // From codersdk/workspaceagents.go
 export type AgentSubsystem = "envbox" | "envbuilder" | "exectrace"
 export const AgentSubsystems: AgentSubsystem[] = ["envbox", "envbuilder", "exectrace"]
 
+// From codersdk/workspacebuilds.go
+export type AgentTimingStage = "connect" | "start"
+export const AgentTimingStages: AgentTimingStage[] = ["connect", "start"]
+
 // From codersdk/audit.go
-export type AuditAction = "create" | "delete" | "login" | "logout" | "register" | "start" | "stop" | "write"
-export const AuditActions: AuditAction[] = ["create", "delete", "login", "logout", "register", "start", "stop", "write"]
+export type AuditAction = "create" | "delete" | "login" | "logout" | "open" | "register" | "start" | "stop" | "write"
+export const AuditActions: AuditAction[] = ["create", "delete", "login", "logout", "open", "register", "start", "stop", "write"]
 
 // From codersdk/workspaces.go
 export type AutomaticUpdates = "always" | "never"
@@ -2024,6 +2297,10 @@ export const AutomaticUpdateses: AutomaticUpdates[] = ["always", "never"]
 export type BuildReason = "autostart" | "autostop" | "initiator"
 export const BuildReasons: BuildReason[] = ["autostart", "autostop", "initiator"]
 
+// From codersdk/users.go
+export type DeviceLoginStatus = "approved" | "denied" | "expired" | "pending"
+export const DeviceLoginStatuses: DeviceLoginStatus[] = ["approved", "denied", "expired", "pending"]
+
 // From codersdk/workspaceagents.go
 export type DisplayApp = "port_forwarding_helper" | "ssh_helper" | "vscode" | "vscode_insiders" | "web_terminal"
 export const DisplayApps: DisplayApp[] = ["port_forwarding_helper", "ssh_helper", "vscode", "vscode_insiders", "web_terminal"]
@@ -2041,16 +2318,16 @@ export type Experiment = "auto-fill-parameters" | "custom-roles" | "example" | "
 export const Experiments: Experiment[] = ["auto-fill-parameters", "custom-roles", "example", "multi-organization", "notifications", "workspace-usage"]
 
 // From codersdk/deployment.go
-export type FeatureName = "access_control" | "advanced_template_scheduling" | "appearance" | "audit_log" | "browser_only" | "control_shared_ports" | "custom_roles" | "external_provisioner_daemons" | "external_token_encryption" | "high_availability" | "multiple_external_auth" | "multiple_organizations" | "scim" | "template_rbac" | "user_limit" | "user_role_management" | "workspace_batch_actions" | "workspace_proxy"
-export const FeatureNames: FeatureName[] = ["access_control", "advanced_template_scheduling", "appearance", "audit_log", "browser_only", "control_shared_ports", "custom_roles", "external_provisioner_daemons", "external_token_encryption", "high_availability", "multiple_external_auth", "multiple_organizations", "scim", "template_rbac", "user_limit", "user_role_management", "workspace_batch_actions", "workspace_proxy"]
+export type FeatureName = "access_control" | "advanced_template_scheduling" | "appearance" | "audit_log" | "browser_only" | "control_shared_ports" | "custom_roles" | "external_provisioner_daemons" | "external_token_encryption" | "high_availability" | "multiple_external_auth" | "multiple_organizations" | "scim" | "service_account_limit" | "template_rbac" | "user_limit" | "user_role_management" | "workspace_batch_actions" | "workspace_proxy"
+export const FeatureNames: FeatureName[] = ["access_control", "advanced_template_scheduling", "appearance", "audit_log", "browser_only", "control_shared_ports", "custom_roles", "external_provisioner_daemons", "external_token_encryption", "high_availability", "multiple_external_auth", "multiple_organizations", "scim", "service_account_limit", "template_rbac", "user_limit", "user_role_management", "workspace_batch_actions", "workspace_proxy"]
 
 // From codersdk/deployment.go
 export type FeatureSet = "" | "enterprise" | "premium"
 export const FeatureSets: FeatureSet[] = ["", "enterprise", "premium"]
 
 // From codersdk/groups.go
-export type GroupSource = "oidc" | "user"
-export const GroupSources: GroupSource[] = ["oidc", "user"]
+export type GroupSource = "oidc" | "scim" | "user"
+export const GroupSources: GroupSource[] = ["oidc", "scim", "user"]
 
 // From codersdk/insights.go
 export type InsightsReportInterval = "day" | "week"
@@ -2069,17 +2346,25 @@ export type LogSource = "provisioner" | "provisioner_daemon"
 export const LogSources: LogSource[] = ["provisioner", "provisioner_daemon"]
 
 // From codersdk/apikey.go
-export type LoginType = "" | "github" | "none" | "oidc" | "password" | "token"
-export const LoginTypes: LoginType[] = ["", "github", "none", "oidc", "password", "token"]
+export type LoginType = "" | "github" | "ldap" | "none" | "oidc" | "password" | "token"
+export const LoginTypes: LoginType[] = ["", "github", "ldap", "none", "oidc", "password", "token"]
+
+// From codersdk/oauth2.go
+export type OAuth2ProviderCodeChallengeMethod = "S256"
+export const OAuth2ProviderCodeChallengeMethods: OAuth2ProviderCodeChallengeMethod[] = ["S256"]
 
 // From codersdk/oauth2.go
-export type OAuth2ProviderGrantType = "authorization_code" | "refresh_token"
-export const OAuth2ProviderGrantTypes: OAuth2ProviderGrantType[] = ["authorization_code", "refresh_token"]
+export type OAuth2ProviderGrantType = "authorization_code" | "client_credentials" | "refresh_token" | "urn:ietf:params:oauth:grant-type:device_code"
+export const OAuth2ProviderGrantTypes: OAuth2ProviderGrantType[] = ["authorization_code", "client_credentials", "refresh_token", "urn:ietf:params:oauth:grant-type:device_code"]
 
 // From codersdk/oauth2.go
 export type OAuth2ProviderResponseType = "code"
 export const OAuth2ProviderResponseTypes: OAuth2ProviderResponseType[] = ["code"]
 
+// From codersdk/users.go
+export type OIDCSyncAction = "add" | "keep" | "remove"
+export const OIDCSyncActions: OIDCSyncAction[] = ["add", "keep", "remove"]
+
 // From codersdk/deployment.go
 export type PostgresAuth = "awsiamrds" | "password"
 export const PostgresAuths: PostgresAuth[] = ["awsiamrds", "password"]
@@ -2096,9 +2381,13 @@ export const ProvisionerLogLevels: ProvisionerLogLevel[] = ["debug"]
 export type ProvisionerStorageMethod = "file"
 export const ProvisionerStorageMethods: ProvisionerStorageMethod[] = ["file"]
 
+// From codersdk/provisionerdaemons.go
+export type ProvisionerTimingStage = "apply" | "graph" | "init" | "plan"
+export const ProvisionerTimingStages: ProvisionerTimingStage[] = ["apply", "graph", "init", "plan"]
+
 // From codersdk/organizations.go
-export type ProvisionerType = "echo" | "terraform"
-export const ProvisionerTypes: ProvisionerType[] = ["echo", "terraform"]
+export type ProvisionerType = "echo" | "terraform" | "tofu"
+export const ProvisionerTypes: ProvisionerType[] = ["echo", "terraform", "tofu"]
 
 // From codersdk/workspaceproxy.go
 export type ProxyHealthStatus = "ok" | "unhealthy" | "unreachable" | "unregistered"
@@ -2112,9 +2401,17 @@ export const RBACActions: RBACAction[] = ["application_connect", "assign", "crea
 export type RBACResource = "*" | "api_key" | "assign_org_role" | "assign_role" | "audit_log" | "debug_info" | "deployment_config" | "deployment_stats" | "file" | "group" | "group_member" | "license" | "notification_preference" | "notification_template" | "oauth2_app" | "oauth2_app_code_token" | "oauth2_app_secret" | "organization" | "organization_member" | "provisioner_daemon" | "provisioner_keys" | "replicas" | "system" | "tailnet_coordinator" | "template" | "user" | "workspace" | "workspace_dormant" | "workspace_proxy"
 export const RBACResources: RBACResource[] = ["*", "api_key", "assign_org_role", "assign_role", "audit_log", "debug_info", "deployment_config", "deployment_stats", "file", "group", "group_member", "license", "notification_preference", "notification_template", "oauth2_app", "oauth2_app_code_token", "oauth2_app_secret", "organization", "organization_member", "provisioner_daemon", "provisioner_keys", "replicas", "system", "tailnet_coordinator", "template", "user", "workspace", "workspace_dormant", "workspace_proxy"]
 
+// From codersdk/workspaceplans.go
+export type ResourceChangeAction = "create" | "delete" | "replace" | "update"
+export const ResourceChangeActions: ResourceChangeAction[] = ["create", "delete", "replace", "update"]
+
 // From codersdk/audit.go
-export type ResourceType = "api_key" | "convert_login" | "custom_role" | "git_ssh_key" | "group" | "health_settings" | "license" | "notifications_settings" | "oauth2_provider_app" | "oauth2_provider_app_secret" | "organization" | "template" | "template_version" | "user" | "workspace" | "workspace_build" | "workspace_proxy"
-export const ResourceTypes: ResourceType[] = ["api_key", "convert_login", "custom_role", "git_ssh_key", "group", "health_settings", "license", "notifications_settings", "oauth2_provider_app", "oauth2_provider_app_secret", "organization", "template", "template_version", "user", "workspace", "workspace_build", "workspace_proxy"]
+export type ResourceType = "api_key" | "convert_login" | "custom_role" | "git_ssh_key" | "group" | "health_settings" | "license" | "notifications_settings" | "oauth2_provider_app" | "oauth2_provider_app_secret" | "organization" | "role_grant_request" | "template" | "template_version" | "template_version_canary" | "user" | "workspace" | "workspace_build" | "workspace_proxy"
+export const ResourceTypes: ResourceType[] = ["api_key", "convert_login", "custom_role", "git_ssh_key", "group", "health_settings", "license", "notifications_settings", "oauth2_provider_app", "oauth2_provider_app_secret", "organization", "role_grant_request", "template", "template_version", "template_version_canary", "user", "workspace", "workspace_build", "workspace_proxy"]
+
+// From codersdk/rolegrants.go
+export type RoleGrantStatus = "approved" | "denied" | "expired" | "pending" | "revoked"
+export const RoleGrantStatuses: RoleGrantStatus[] = ["approved", "denied", "expired", "pending", "revoked"]
 
 // From codersdk/serversentevents.go
 export type ServerSentEventType = "data" | "error" | "ping"
@@ -2132,6 +2429,10 @@ export const TemplateInsightsSections: TemplateInsightsSection[] = ["interval_re
 export type TemplateRole = "" | "admin" | "use"
 export const TemplateRoles: TemplateRole[] = ["", "admin", "use"]
 
+// From codersdk/templateversioncanaries.go
+export type TemplateVersionCanaryStatus = "active" | "promoted" | "rolled_back"
+export const TemplateVersionCanaryStatuses: TemplateVersionCanaryStatus[] = ["active", "promoted", "rolled_back"]
+
 // From codersdk/templateversions.go
 export type TemplateVersionWarning = "UNSUPPORTED_WORKSPACES"
 export const TemplateVersionWarnings: TemplateVersionWarning[] = ["UNSUPPORTED_WORKSPACES"]
@@ -2172,6 +2473,10 @@ export const WorkspaceAgentStatuses: WorkspaceAgentStatus[] = ["connected", "con
 export type WorkspaceAppHealth = "disabled" | "healthy" | "initializing" | "unhealthy"
 export const WorkspaceAppHealths: WorkspaceAppHealth[] = ["disabled", "healthy", "initializing", "unhealthy"]
 
+// From codersdk/workspaceapps.go
+export type WorkspaceAppProtocol = "http" | "https" | "tcp" | "udp" | "unix"
+export const WorkspaceAppProtocols: WorkspaceAppProtocol[] = ["http", "https", "tcp", "udp", "unix"]
+
 // From codersdk/workspaceapps.go
 export type WorkspaceAppSharingLevel = "authenticated" | "owner" | "public"
 export const WorkspaceAppSharingLevels: WorkspaceAppSharingLevel[] = ["authenticated", "owner", "public"]
@@ -2206,6 +2511,17 @@ export interface BaseReport {
 	readonly dismissed: boolean;
 }
 
+// From healthsdk/healthsdk.go
+export interface DERPHealthHistory {
+	readonly nodes: Readonly<Array<DERPNodeHealthHistory>>;
+}
+
+// From healthsdk/healthsdk.go
+export interface DERPHealthHistoryRequest {
+	readonly Since: string;
+	readonly RegionID: number;
+}
+
 // From healthsdk/healthsdk.go
 export interface DERPHealthReport extends BaseReport {
 	readonly healthy: boolean;
@@ -2216,6 +2532,27 @@ export interface DERPHealthReport extends BaseReport {
 	readonly netcheck_logs: Readonly<Array<string>>;
 }
 
+// From healthsdk/healthsdk.go
+export interface DERPNodeHealthHistory {
+	readonly replica_id: string;
+	readonly region_id: number;
+	readonly region_code: string;
+	readonly node_name: string;
+	readonly results: Readonly<Array<DERPNodeHealthResult>>;
+}
+
+// From healthsdk/healthsdk.go
+export interface DERPNodeHealthResult {
+	readonly created_at: string;
+	readonly healthy: boolean;
+	readonly can_exchange_messages: boolean;
+	readonly round_trip_ping_ms: number;
+	readonly packet_loss: number;
+	readonly stun_enabled: boolean;
+	readonly can_stun: boolean;
+	readonly error?: string;
+}
+
 // From healthsdk/healthsdk.go
 export interface DERPNodeReport {
 	readonly healthy: boolean;
@@ -2229,6 +2566,7 @@ export interface DERPNodeReport {
 	readonly can_exchange_messages: boolean;
 	readonly round_trip_ping: string;
 	readonly round_trip_ping_ms: number;
+	readonly packet_loss: number;
 	readonly uses_websocket: boolean;
 	readonly client_logs: Readonly<Array<Readonly<Array<string>>>>;
 	readonly client_errs: Readonly<Array<Readonly<Array<string>>>>;
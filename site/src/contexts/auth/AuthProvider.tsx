@@ -7,7 +7,11 @@ import {
 	me,
 	updateProfile as updateProfileOptions,
 } from "api/queries/users";
-import type { UpdateUserProfileRequest, User } from "api/typesGenerated";
+import type {
+	LoginWithPasswordRequest,
+	UpdateUserProfileRequest,
+	User,
+} from "api/typesGenerated";
 import { displaySuccess } from "components/GlobalSnackbar/utils";
 import { useEmbeddedMetadata } from "hooks/useEmbeddedMetadata";
 import {
@@ -20,6 +24,11 @@ import {
 import { useMutation, useQuery, useQueryClient } from "react-query";
 import { type Permissions, permissionsToCheck } from "./permissions";
 
+type SecondFactor = Pick<
+	LoginWithPasswordRequest,
+	"totp_code" | "recovery_code"
+>;
+
 export type AuthContextValue = {
 	isLoading: boolean;
 	isSignedOut: boolean;
@@ -33,7 +42,11 @@ export type AuthContextValue = {
 	signInError: unknown;
 	updateProfileError: unknown;
 	signOut: () => void;
-	signIn: (email: string, password: string) => Promise<void>;
+	signIn: (
+		email: string,
+		password: string,
+		secondFactor?: SecondFactor,
+	) => Promise<void>;
 	updateProfile: (data: UpdateUserProfileRequest) => void;
 };
 
@@ -88,8 +101,8 @@ export const AuthProvider: FC<PropsWithChildren> = ({ children }) => {
 	}, [logoutMutation]);
 
 	const signIn = useCallback(
-		async (email: string, password: string) => {
-			await loginMutation.mutateAsync({ email, password });
+		async (email: string, password: string, secondFactor?: SecondFactor) => {
+			await loginMutation.mutateAsync({ email, password, ...secondFactor });
 		},
 		[loginMutation],
 	);
@@ -139,6 +139,18 @@ export const HealthUnhealthy: Story = {
 	},
 };
 
+export const TCPApp: Story = {
+	args: {
+		workspace: MockWorkspace,
+		app: {
+			...MockWorkspaceApp,
+			url: "tcp://localhost:5432",
+			protocol: "tcp",
+		},
+		agent: MockWorkspaceAgent,
+	},
+};
+
 export const InternalApp: Story = {
 	args: {
 		workspace: MockWorkspace,
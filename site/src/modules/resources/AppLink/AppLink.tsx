@@ -6,6 +6,7 @@ import Tooltip from "@mui/material/Tooltip";
 import { API } from "api/api";
 import type * as TypesGen from "api/typesGenerated";
 import { useProxy } from "contexts/ProxyContext";
+import { useClipboard } from "hooks/useClipboard";
 import { type FC, type MouseEvent, useState } from "react";
 import { createAppLinkHref } from "utils/apps";
 import { generateRandomString } from "utils/random";
@@ -50,6 +51,12 @@ export const AppLink: FC<AppLinkProps> = ({ app, workspace, agent }) => {
 		appDisplayName = appSlug;
 	}
 
+	// TCP and UDP apps can't be opened in the browser, so the button copies
+	// the command to port-forward them to the user's machine instead.
+	const isPortForwardApp = app.protocol === "tcp" || app.protocol === "udp";
+	const portForwardCommand = `coder port-forward ${username}/${workspace.name}.${agent.name} --app ${appSlug}`;
+	const clipboard = useClipboard({ textToCopy: portForwardCommand });
+
 	const href = createAppLinkHref(
 		window.location.protocol,
 		preferredPathBase,
@@ -101,6 +108,11 @@ export const AppLink: FC<AppLinkProps> = ({ app, workspace, agent }) => {
 	if (fetchingSessionToken) {
 		canClick = false;
 	}
+	if (isPortForwardApp) {
+		primaryTooltip = clipboard.showCopiedSuccess
+			? "Copied! Run the command to forward this app to your machine"
+			: `Copy "${portForwardCommand}"`;
+	}
 	if (
 		agent.lifecycle_state === "starting" &&
 		agent.startup_script_behavior === "blocking"
@@ -129,6 +141,10 @@ export const AppLink: FC<AppLinkProps> = ({ app, workspace, agent }) => {
 					}
 
 					event.preventDefault();
+					if (isPortForwardApp) {
+						await clipboard.copyToClipboard();
+						return;
+					}
 					// This is an external URI like "vscode://", so
 					// it needs to be opened with the browser protocol handler.
 					if (app.external && !app.url.startsWith("http")) {
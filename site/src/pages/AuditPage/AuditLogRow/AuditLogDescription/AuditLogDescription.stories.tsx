@@ -4,6 +4,7 @@ import {
 	MockAuditLogSuccessfulLogin,
 	MockAuditLogUnsuccessfulLoginKnownUser,
 	MockAuditLogWithWorkspaceBuild,
+	MockUser,
 	MockWorkspaceCreateAuditLogForDifferentOwner,
 } from "testHelpers/entities";
 import { AuditLogDescription } from "./AuditLogDescription";
@@ -98,3 +99,16 @@ export const SCIMUpdateUser: Story = {
 		},
 	},
 };
+
+export const ServiceAccount: Story = {
+	args: {
+		auditLog: {
+			...MockAuditLog,
+			user: {
+				...MockUser,
+				username: "ci-bot",
+				is_service_account: true,
+			},
+		},
+	},
+};
@@ -31,6 +31,10 @@ export const AuditLogDescription: FC<AuditLogDescriptionProps> = ({
 		user = "Coder automatically";
 	}
 
+	if (auditLog.user?.is_service_account) {
+		user = `${user} (service account)`;
+	}
+
 	const truncatedDescription = auditLog.description
 		.replace("{user}", `${user}`)
 		.replace("{target}", "");
@@ -42,6 +42,10 @@ export const authMethodLanguage = {
 		displayName: "Github",
 		description: "Use Github OAuth for authentication",
 	},
+	ldap: {
+		displayName: "LDAP",
+		description: "Use the credentials of an LDAP directory account to login",
+	},
 	none: {
 		displayName: "None",
 		description: (
@@ -109,6 +113,7 @@ export const CreateUserForm: FC<
 		authMethods?.password.enabled && "password",
 		authMethods?.oidc.enabled && "oidc",
 		authMethods?.github.enabled && "github",
+		authMethods?.ldap.enabled && "ldap",
 		"none",
 	].filter(Boolean) as Array<keyof typeof authMethodLanguage>;
 
@@ -94,8 +94,8 @@ export const LoginPage: FC = () => {
 				isLoading={isLoading || authMethodsQuery.isLoading}
 				buildInfo={buildInfoQuery.data}
 				isSigningIn={isSigningIn}
-				onSignIn={async ({ email, password }) => {
-					await signIn(email, password);
+				onSignIn={async ({ email, password, totp_code, recovery_code }) => {
+					await signIn(email, password, { totp_code, recovery_code });
 					navigate("/");
 				}}
 			/>
@@ -1,6 +1,10 @@
 import type { Interpolation, Theme } from "@emotion/react";
 import Button from "@mui/material/Button";
-import type { AuthMethods, BuildInfoResponse } from "api/typesGenerated";
+import type {
+	AuthMethods,
+	BuildInfoResponse,
+	LoginWithPasswordRequest,
+} from "api/typesGenerated";
 import { CoderIcon } from "components/Icons/CoderIcon";
 import { Loader } from "components/Loader/Loader";
 import { type FC, useState } from "react";
@@ -16,7 +20,7 @@ export interface LoginPageViewProps {
 	isLoading: boolean;
 	buildInfo?: BuildInfoResponse;
 	isSigningIn: boolean;
-	onSignIn: (credentials: { email: string; password: string }) => void;
+	onSignIn: (credentials: LoginWithPasswordRequest) => void;
 }
 
 export const LoginPageView: FC<LoginPageViewProps> = ({
@@ -1,5 +1,7 @@
 import LoadingButton from "@mui/lab/LoadingButton";
 import TextField from "@mui/material/TextField";
+import { isApiValidationError } from "api/errors";
+import type { LoginWithPasswordRequest } from "api/typesGenerated";
 import { Stack } from "components/Stack/Stack";
 import { useFormik } from "formik";
 import type { FC } from "react";
@@ -8,15 +10,17 @@ import * as Yup from "yup";
 import { Language } from "./SignInForm";
 
 type PasswordSignInFormProps = {
-	onSubmit: (credentials: { email: string; password: string }) => void;
+	onSubmit: (credentials: LoginWithPasswordRequest) => void;
 	isSigningIn: boolean;
 	autoFocus: boolean;
+	error?: unknown;
 };
 
 export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
 	onSubmit,
 	isSigningIn,
 	autoFocus,
+	error,
 }) => {
 	const validationSchema = Yup.object({
 		email: Yup.string()
@@ -24,18 +28,23 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
 			.email(Language.emailInvalid)
 			.required(Language.emailRequired),
 		password: Yup.string(),
+		code: Yup.string(),
 	});
 
 	const form = useFormik({
 		initialValues: {
 			email: "",
 			password: "",
+			code: "",
 		},
 		validationSchema,
-		onSubmit,
+		onSubmit: ({ email, password, code }) => {
+			onSubmit({ email, password, ...secondFactor(code) });
+		},
 		validateOnBlur: false,
 	});
 	const getFieldHelpers = getFormHelpers(form);
+	const secondFactorRequired = isSecondFactorRequiredError(error);
 
 	return (
 		<form onSubmit={form.handleSubmit}>
@@ -57,6 +66,18 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
 					label={Language.passwordLabel}
 					type="password"
 				/>
+				{secondFactorRequired && (
+					<TextField
+						{...getFieldHelpers("code")}
+						onChange={onChangeTrimmed(form)}
+						autoFocus
+						autoComplete="one-time-code"
+						fullWidth
+						id="code"
+						label={Language.secondFactorLabel}
+						helperText={Language.secondFactorHelperText}
+					/>
+				)}
 				<LoadingButton
 					size="xlarge"
 					loading={isSigningIn}
@@ -69,3 +90,25 @@ export const PasswordSignInForm: FC<PasswordSignInFormProps> = ({
 		</form>
 	);
 };
+
+const isSecondFactorRequiredError = (error: unknown): boolean => {
+	return (
+		isApiValidationError(error) &&
+		Boolean(
+			error.response.data.validations?.some(
+				(v) => v.field === "totp_code" || v.field === "recovery_code",
+			),
+		)
+	);
+};
+
+const secondFactor = (
+	code: string,
+): Pick<LoginWithPasswordRequest, "totp_code" | "recovery_code"> => {
+	if (!code) {
+		return {};
+	}
+	// Authenticator apps generate six digit codes, anything else is treated as
+	// a recovery code.
+	return /^\d{6}$/.test(code) ? { totp_code: code } : { recovery_code: code };
+};
@@ -22,6 +22,7 @@ export const SigningIn: Story = {
 			password: { enabled: true },
 			github: { enabled: true },
 			oidc: { enabled: false, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -46,6 +47,7 @@ export const WithGithub: Story = {
 			password: { enabled: true },
 			github: { enabled: true },
 			oidc: { enabled: false, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -56,6 +58,7 @@ export const WithOIDC: Story = {
 			password: { enabled: true },
 			github: { enabled: false },
 			oidc: { enabled: true, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -66,6 +69,7 @@ export const WithOIDCWithoutPassword: Story = {
 			password: { enabled: false },
 			github: { enabled: false },
 			oidc: { enabled: true, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -76,6 +80,7 @@ export const WithoutAny: Story = {
 			password: { enabled: false },
 			github: { enabled: false },
 			oidc: { enabled: false, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -86,6 +91,7 @@ export const WithGithubAndOIDC: Story = {
 			password: { enabled: true },
 			github: { enabled: true },
 			oidc: { enabled: true, signInText: "", iconUrl: "" },
+			ldap: { enabled: false },
 		},
 	},
 };
@@ -1,5 +1,8 @@
 import type { Interpolation, Theme } from "@emotion/react";
-import type { AuthMethods } from "api/typesGenerated";
+import type {
+	AuthMethods,
+	LoginWithPasswordRequest,
+} from "api/typesGenerated";
 import { Alert } from "components/Alert/Alert";
 import { ErrorAlert } from "components/Alert/ErrorAlert";
 import type { FC, ReactNode } from "react";
@@ -13,6 +16,9 @@ export const Language = {
 	emailInvalid: "Please enter a valid email address.",
 	emailRequired: "Please enter an email address.",
 	passwordSignIn: "Sign In",
+	secondFactorLabel: "Authentication code",
+	secondFactorHelperText:
+		"Enter the code from your authenticator app, or one of your recovery codes.",
 	githubSignIn: "GitHub",
 	oidcSignIn: "OpenID Connect",
 };
@@ -66,7 +72,7 @@ export interface SignInFormProps {
 	error?: unknown;
 	message?: ReactNode;
 	authMethods?: AuthMethods;
-	onSubmit: (credentials: { email: string; password: string }) => void;
+	onSubmit: (credentials: LoginWithPasswordRequest) => void;
 }
 
 export const SignInForm: FC<SignInFormProps> = ({
@@ -120,6 +126,7 @@ export const SignInForm: FC<SignInFormProps> = ({
 					onSubmit={onSubmit}
 					autoFocus={!oAuthEnabled}
 					isSigningIn={isSigningIn}
+					error={error}
 				/>
 			)}
 
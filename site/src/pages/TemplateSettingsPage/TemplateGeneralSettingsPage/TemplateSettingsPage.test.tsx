@@ -51,6 +51,14 @@ const validFormValues: FormValues = {
 	require_active_version: false,
 	disable_everyone_group_access: false,
 	max_port_share_level: "owner",
+	allowed_workspace_proxy_ids: [],
+	allowed_derp_region_ids: [],
+
+	max_app_connections_per_user: 0,
+	max_app_bytes_per_second: 0,
+	drift_detection_interval_ms: 0,
+	build_timeout_ms: 0,
+	build_cancel_grace_period_ms: 0,
 };
 
 const renderTemplateSettingsPage = async () => {
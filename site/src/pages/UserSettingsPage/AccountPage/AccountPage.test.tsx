@@ -34,6 +34,7 @@ describe("AccountPage", () => {
 					status: "active",
 					organization_ids: ["123"],
 					roles: [],
+					is_service_account: false,
 					avatar_url: "",
 					last_seen_at: new Date().toISOString(),
 					login_type: "password",
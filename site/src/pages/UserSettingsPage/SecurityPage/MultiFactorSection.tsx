@@ -0,0 +1,121 @@
+import LoadingButton from "@mui/lab/LoadingButton";
+import TextField from "@mui/material/TextField";
+import type {
+	TOTPEnrollment,
+	UserLoginType,
+	UserMFAStatus,
+} from "api/typesGenerated";
+import { Alert } from "components/Alert/Alert";
+import { ErrorAlert } from "components/Alert/ErrorAlert";
+import { Stack } from "components/Stack/Stack";
+import { type FC, useState } from "react";
+import { Section } from "../Section";
+
+export interface MultiFactorSectionProps {
+	userLoginType: UserLoginType;
+	status: UserMFAStatus;
+	enrollment?: TOTPEnrollment;
+	isEnrolling: boolean;
+	isConfirming: boolean;
+	error?: unknown;
+	onEnroll: () => void;
+	onConfirm: (code: string) => void;
+}
+
+export const MultiFactorSection: FC<MultiFactorSectionProps> = ({
+	userLoginType,
+	status,
+	enrollment,
+	isEnrolling,
+	isConfirming,
+	error,
+	onEnroll,
+	onConfirm,
+}) => {
+	const [code, setCode] = useState("");
+
+	return (
+		<Section
+			id="mfa-section"
+			title="Multi-factor Authentication"
+			description="Require a code from an authenticator app when you sign in with your password"
+		>
+			<Stack spacing={2.5}>
+				{Boolean(error) && <ErrorAlert error={error} />}
+
+				{userLoginType.login_type !== "password" ? (
+					<Alert severity="info">
+						Multi-factor authentication is managed by your identity provider.
+					</Alert>
+				) : status.totp_enabled ? (
+					<Alert severity="success">
+						Multi-factor authentication is enabled. You have{" "}
+						{status.recovery_codes_remaining} recovery codes remaining.
+					</Alert>
+				) : enrollment ? (
+					<form
+						onSubmit={(event) => {
+							event.preventDefault();
+							onConfirm(code);
+						}}
+					>
+						<Stack spacing={2.5}>
+							<img
+								src={`data:image/png;base64,${enrollment.qr_code}`}
+								alt="QR code for your authenticator app"
+								css={{ width: 200, height: 200 }}
+							/>
+							<span>
+								Scan the QR code with your authenticator app, or enter this
+								secret: <code>{enrollment.secret}</code>
+							</span>
+							<span>
+								Store these recovery codes somewhere safe. Each of them can be
+								used once to sign in if you lose access to your authenticator
+								app.
+							</span>
+							<pre css={{ margin: 0 }}>
+								{enrollment.recovery_codes.join("\n")}
+							</pre>
+							<TextField
+								fullWidth
+								id="totp-code"
+								label="Authentication code"
+								autoComplete="one-time-code"
+								value={code}
+								onChange={(event) => setCode(event.target.value.trim())}
+							/>
+							<div>
+								<LoadingButton
+									loading={isConfirming}
+									type="submit"
+									variant="contained"
+								>
+									Enable
+								</LoadingButton>
+							</div>
+						</Stack>
+					</form>
+				) : (
+					<>
+						{status.required && (
+							<Alert severity="warning">
+								Your deployment requires multi-factor authentication for your
+								account.
+							</Alert>
+						)}
+						<div>
+							<LoadingButton
+								loading={isEnrolling}
+								variant="contained"
+								onClick={onEnroll}
+							>
+								Set up authenticator app
+							</LoadingButton>
+						</div>
+					</>
+				)}
+			</Stack>
+		</Section>
+	);
+};
@@ -1,12 +1,19 @@
 import { API } from "api/api";
-import { authMethods, updatePassword } from "api/queries/users";
+import {
+	authMethods,
+	confirmTOTP,
+	enrollTOTP,
+	updatePassword,
+	userMFAStatus,
+} from "api/queries/users";
 import { displaySuccess } from "components/GlobalSnackbar/utils";
 import { Loader } from "components/Loader/Loader";
 import { Stack } from "components/Stack/Stack";
 import { useAuthenticated } from "contexts/auth/RequireAuth";
 import type { ComponentProps, FC } from "react";
-import { useMutation, useQuery } from "react-query";
+import { useMutation, useQuery, useQueryClient } from "react-query";
 import { Section } from "../Section";
+import { MultiFactorSection } from "./MultiFactorSection";
 import { SecurityForm } from "./SecurityForm";
 import {
 	SingleSignOnSection,
@@ -22,6 +29,10 @@ export const SecurityPage: FC = () => {
 		queryFn: API.getUserLoginType,
 	});
 	const singleSignOnSection = useSingleSignOnSection();
+	const queryClient = useQueryClient();
+	const mfaStatusQuery = useQuery(userMFAStatus(me.id));
+	const enrollTOTPMutation = useMutation(enrollTOTP());
+	const confirmTOTPMutation = useMutation(confirmTOTP(me.id, queryClient));
 
 	if (!authMethodsQuery.data || !userLoginType) {
 		return <Loader />;
@@ -53,6 +64,23 @@ export const SecurityPage: FC = () => {
 					...singleSignOnSection,
 				},
 			}}
+			mfa={
+				mfaStatusQuery.data && {
+					section: {
+						userLoginType,
+						status: mfaStatusQuery.data,
+						enrollment: enrollTOTPMutation.data,
+						isEnrolling: enrollTOTPMutation.isLoading,
+						isConfirming: confirmTOTPMutation.isLoading,
+						error: enrollTOTPMutation.error ?? confirmTOTPMutation.error,
+						onEnroll: () => enrollTOTPMutation.mutate(me.id),
+						onConfirm: async (code) => {
+							await confirmTOTPMutation.mutateAsync({ code });
+							displaySuccess("Multi-factor authentication enabled.");
+						},
+					},
+				}
+			}
 		/>
 	);
 };
@@ -64,17 +92,22 @@ interface SecurityPageViewProps {
 	oidc?: {
 		section: ComponentProps<typeof SingleSignOnSection>;
 	};
+	mfa?: {
+		section: ComponentProps<typeof MultiFactorSection>;
+	};
 }
 
 export const SecurityPageView: FC<SecurityPageViewProps> = ({
 	security,
 	oidc,
+	mfa,
 }) => {
 	return (
 		<Stack spacing={6}>
 			<Section title="Security" description="Update your account password">
 				<SecurityForm {...security.form} />
 			</Section>
+			{mfa && <MultiFactorSection {...mfa.section} />}
 			{oidc && <SingleSignOnSection {...oidc.section} />}
 		</Stack>
 	);
@@ -5,6 +5,8 @@ import type { ComponentProps } from "react";
 import {
 	MockAuthMethodsAll,
 	MockAuthMethodsPasswordOnly,
+	MockTOTPEnrollment,
+	MockUserMFAStatus,
 } from "testHelpers/entities";
 import { SecurityPageView } from "./SecurityPage";
 
@@ -31,6 +33,18 @@ const defaultArgs: ComponentProps<typeof SecurityPageView> = {
 			openConfirmation: action("openConfirmation"),
 		},
 	},
+	mfa: {
+		section: {
+			userLoginType: {
+				login_type: "password",
+			},
+			status: MockUserMFAStatus,
+			isEnrolling: false,
+			isConfirming: false,
+			onEnroll: action("onEnroll"),
+			onConfirm: action("onConfirm"),
+		},
+	},
 };
 
 const meta: Meta<typeof SecurityPageView> = {
@@ -66,3 +80,15 @@ export const ConfirmingOIDCConversion: Story = {
 		defaultArgs,
 	),
 };
+
+export const EnrollingTOTP: Story = {
+	args: set("mfa.section.enrollment", MockTOTPEnrollment, defaultArgs),
+};
+
+export const TOTPEnabled: Story = {
+	args: set(
+		"mfa.section.status",
+		{ ...MockUserMFAStatus, totp_enabled: true, recovery_codes_remaining: 10 },
+		defaultArgs,
+	),
+};
@@ -256,6 +256,9 @@ const LoginType: FC<LoginTypeProps> = ({ authMethods, value }) => {
 	} else if (value === "token") {
 		displayName = "Token";
 		icon = <KeyOutlined css={styles.icon} />;
+	} else if (value === "ldap") {
+		displayName = "LDAP";
+		icon = <ShieldOutlined css={styles.icon} />;
 	} else if (value === "oidc") {
 		displayName =
 			authMethods.oidc.signInText === "" ? "OIDC" : authMethods.oidc.signInText;
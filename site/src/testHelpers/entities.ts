@@ -20,6 +20,8 @@ export const MockOrganization: TypesGen.Organization = {
 	created_at: "",
 	updated_at: "",
 	is_default: false,
+	allowed_workspace_proxy_ids: [],
+	allowed_derp_region_ids: [],
 };
 
 export const MockDefaultOrganization: TypesGen.Organization = {
@@ -36,6 +38,8 @@ export const MockOrganization2: TypesGen.Organization = {
 	created_at: "",
 	updated_at: "",
 	is_default: false,
+	allowed_workspace_proxy_ids: [],
+	allowed_derp_region_ids: [],
 };
 
 export const MockTemplateDAUResponse: TypesGen.DAUsResponse = {
@@ -73,6 +77,8 @@ export const MockToken: TypesGen.APIKeyWithOwner = {
 	scope: "all",
 	lifetime_seconds: 2592000,
 	token_name: "token-one",
+	scopes: [],
+	allow_list: [],
 	username: "admin",
 };
 
@@ -89,6 +95,8 @@ export const MockTokens: TypesGen.APIKeyWithOwner[] = [
 		scope: "all",
 		lifetime_seconds: 2592000,
 		token_name: "token-two",
+		scopes: [],
+		allow_list: [],
 		username: "admin",
 	},
 ];
@@ -423,6 +431,7 @@ export const MockUser: TypesGen.User = {
 	status: "active",
 	organization_ids: [MockOrganization.id],
 	roles: [MockOwnerRole],
+	is_service_account: false,
 	avatar_url: "https://avatars.githubusercontent.com/u/95932066?s=200&v=4",
 	last_seen_at: "",
 	login_type: "password",
@@ -444,6 +453,7 @@ export const MockUser2: TypesGen.User = {
 	status: "active",
 	organization_ids: [MockOrganization.id],
 	roles: [],
+	is_service_account: false,
 	avatar_url: "",
 	last_seen_at: "2022-09-14T19:12:21Z",
 	login_type: "oidc",
@@ -460,6 +470,7 @@ export const SuspendedMockUser: TypesGen.User = {
 	status: "suspended",
 	organization_ids: [MockOrganization.id],
 	roles: [],
+	is_service_account: false,
 	avatar_url: "",
 	last_seen_at: "",
 	login_type: "password",
@@ -667,6 +678,14 @@ export const MockTemplate: TypesGen.Template = {
 	deprecated: false,
 	deprecation_message: "",
 	max_port_share_level: "public",
+	allowed_workspace_proxy_ids: [],
+	allowed_derp_region_ids: [],
+
+	max_app_connections_per_user: 0,
+	max_app_bytes_per_second: 0,
+	drift_detection_interval_ms: 0,
+	build_timeout_ms: 0,
+	build_cancel_grace_period_ms: 0,
 };
 
 export const MockTemplateVersionFiles: TemplateVersionFiles = {
@@ -1528,10 +1547,24 @@ export const MockUserAgent = {
 	os: "Windows 10",
 };
 
+export const MockUserMFAStatus: TypesGen.UserMFAStatus = {
+	totp_enabled: false,
+	recovery_codes_remaining: 0,
+	required: false,
+};
+
+export const MockTOTPEnrollment: TypesGen.TOTPEnrollment = {
+	secret: "JBSWY3DPEHPK3PXP",
+	url: "otpauth://totp/Coder:TestUser@coder.com?issuer=Coder&secret=JBSWY3DPEHPK3PXP",
+	qr_code: "",
+	recovery_codes: ["a1b2c-d3e4f", "g5h6i-j7k8l"],
+};
+
 export const MockAuthMethodsPasswordOnly: TypesGen.AuthMethods = {
 	password: { enabled: true },
 	github: { enabled: false },
 	oidc: { enabled: false, signInText: "", iconUrl: "" },
+	ldap: { enabled: false },
 };
 
 export const MockAuthMethodsPasswordTermsOfService: TypesGen.AuthMethods = {
@@ -1539,6 +1572,7 @@ export const MockAuthMethodsPasswordTermsOfService: TypesGen.AuthMethods = {
 	password: { enabled: true },
 	github: { enabled: false },
 	oidc: { enabled: false, signInText: "", iconUrl: "" },
+	ldap: { enabled: false },
 };
 
 export const MockAuthMethodsExternal: TypesGen.AuthMethods = {
@@ -1549,6 +1583,7 @@ export const MockAuthMethodsExternal: TypesGen.AuthMethods = {
 		signInText: "Google",
 		iconUrl: "/icon/google.svg",
 	},
+	ldap: { enabled: false },
 };
 
 export const MockAuthMethodsAll: TypesGen.AuthMethods = {
@@ -1559,6 +1594,7 @@ export const MockAuthMethodsAll: TypesGen.AuthMethods = {
 		signInText: "Google",
 		iconUrl: "/icon/google.svg",
 	},
+	ldap: { enabled: false },
 };
 
 export const MockGitSSHKey: TypesGen.GitSSHKey = {
@@ -2818,6 +2854,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: false,
 						round_trip_ping: "0",
 						round_trip_ping_ms: 0,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [],
 						client_errs: [],
@@ -2844,6 +2881,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: true,
 						round_trip_ping: "7674330",
 						round_trip_ping_ms: 7674330,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [
 							[
@@ -2916,6 +2954,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: false,
 						round_trip_ping: "0",
 						round_trip_ping_ms: 0,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [],
 						client_errs: [],
@@ -2942,6 +2981,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: true,
 						round_trip_ping: "170527034",
 						round_trip_ping_ms: 170527034,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [
 							[
@@ -3004,6 +3044,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: false,
 						round_trip_ping: "0",
 						round_trip_ping_ms: 0,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [],
 						client_errs: [],
@@ -3030,6 +3071,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: true,
 						round_trip_ping: "111329690",
 						round_trip_ping_ms: 111329690,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [
 							[
@@ -3092,6 +3134,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: false,
 						round_trip_ping: "0",
 						round_trip_ping_ms: 0,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [],
 						client_errs: [],
@@ -3118,6 +3161,7 @@ export const MockHealth: TypesGen.HealthcheckReport = {
 						can_exchange_messages: true,
 						round_trip_ping: "138185506",
 						round_trip_ping_ms: 138185506,
+						packet_loss: 0,
 						uses_websocket: false,
 						client_logs: [
 							[
@@ -3702,10 +3746,11 @@ export const MockOAuth2ProviderApps: TypesGen.OAuth2ProviderApp[] = [
 		name: "foo",
 		callback_url: "http://localhost:3001",
 		icon: "/icon/github.svg",
+		public: false,
 		endpoints: {
 			authorization: "http://localhost:3001/oauth2/authorize",
 			token: "http://localhost:3001/oauth2/token",
-			device_authorization: "",
+			device_authorization: "http://localhost:3001/oauth2/device",
 		},
 	},
 ];
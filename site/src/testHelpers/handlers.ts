@@ -147,6 +147,9 @@ export const handlers = [
 			login_type: "password",
 		});
 	}),
+	http.get("/api/v2/users/:userid/mfa", () => {
+		return HttpResponse.json(M.MockUserMFAStatus);
+	}),
 	http.get("/api/v2/users/me/organizations", () => {
 		return HttpResponse.json([M.MockOrganization]);
 	}),
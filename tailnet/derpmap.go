@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 
 	"golang.org/x/xerrors"
 	"tailscale.com/tailcfg"
@@ -152,6 +153,63 @@ regionLoop:
 	return derpMap, nil
 }
 
+// PrimaryDERPRegionName is the name of the region served by coderd itself in
+// the regions API. Its relay is the DERP region marked as EmbeddedRelay.
+const PrimaryDERPRegionName = "primary"
+
+// preferredDERPRegionScore scales the measured latency of a preferred DERP
+// region when netcheck picks the home region. A score below 1 makes the region
+// proportionally more attractive without forcing it when it's unreachable.
+const preferredDERPRegionScore = 0.1
+
+// WorkspaceProxyDERPRegionCode returns the DERP region code assigned to the
+// relay embedded in the workspace proxy with the given name.
+func WorkspaceProxyDERPRegionCode(proxyName string) string {
+	return fmt.Sprintf("coder_%s", strings.ToLower(proxyName))
+}
+
+// DERPRegionIDByName returns the ID of the DERP region serving the named
+// region from the regions API, if one exists in the DERP map.
+func DERPRegionIDByName(derpMap *tailcfg.DERPMap, name string) (int, bool) {
+	if derpMap == nil || name == "" {
+		return 0, false
+	}
+	code := WorkspaceProxyDERPRegionCode(name)
+	for id, region := range derpMap.Regions {
+		if region == nil {
+			continue
+		}
+		if name == PrimaryDERPRegionName && region.EmbeddedRelay {
+			return id, true
+		}
+		if region.RegionCode == code {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// PreferDERPRegion returns a copy of the DERP map whose home parameters favor
+// the given region when selecting a home DERP. The original map is not
+// modified. If the region is not in the map, the map is returned unchanged.
+func PreferDERPRegion(derpMap *tailcfg.DERPMap, regionID int) *tailcfg.DERPMap {
+	if derpMap == nil {
+		return nil
+	}
+	if _, ok := derpMap.Regions[regionID]; !ok {
+		return derpMap
+	}
+	derpMap = derpMap.Clone()
+	if derpMap.HomeParams == nil {
+		derpMap.HomeParams = &tailcfg.DERPHomeParams{}
+	}
+	if derpMap.HomeParams.RegionScore == nil {
+		derpMap.HomeParams.RegionScore = map[int]float64{}
+	}
+	derpMap.HomeParams.RegionScore[regionID] = preferredDERPRegionScore
+	return derpMap
+}
+
 // CompareDERPMaps returns true if the given DERPMaps are equivalent. Ordering
 // of slices is ignored.
 //
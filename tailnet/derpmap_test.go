@@ -162,3 +162,60 @@ func TestNewDERPMap(t *testing.T) {
 		require.ErrorContains(t, err, "DERP map has no DERP nodes")
 	})
 }
+
+func TestPreferDERPRegion(t *testing.T) {
+	t.Parallel()
+
+	newMap := func() *tailcfg.DERPMap {
+		return &tailcfg.DERPMap{
+			Regions: map[int]*tailcfg.DERPRegion{
+				1: {
+					RegionID:      1,
+					RegionCode:    "coder",
+					EmbeddedRelay: true,
+				},
+				10001: {
+					RegionID:   10001,
+					RegionCode: tailnet.WorkspaceProxyDERPRegionCode("Sydney"),
+				},
+			},
+		}
+	}
+
+	t.Run("LookupByName", func(t *testing.T) {
+		t.Parallel()
+		derpMap := newMap()
+
+		id, ok := tailnet.DERPRegionIDByName(derpMap, tailnet.PrimaryDERPRegionName)
+		require.True(t, ok)
+		require.Equal(t, 1, id)
+
+		id, ok = tailnet.DERPRegionIDByName(derpMap, "sydney")
+		require.True(t, ok)
+		require.Equal(t, 10001, id)
+
+		_, ok = tailnet.DERPRegionIDByName(derpMap, "london")
+		require.False(t, ok)
+	})
+
+	t.Run("Prefer", func(t *testing.T) {
+		t.Parallel()
+		derpMap := newMap()
+
+		preferred := tailnet.PreferDERPRegion(derpMap, 10001)
+		require.NotNil(t, preferred.HomeParams)
+		require.Less(t, preferred.HomeParams.RegionScore[10001], 1.0)
+		require.NotContains(t, preferred.HomeParams.RegionScore, 1)
+		// The original map must not be modified.
+		require.Nil(t, derpMap.HomeParams)
+	})
+
+	t.Run("UnknownRegion", func(t *testing.T) {
+		t.Parallel()
+		derpMap := newMap()
+
+		preferred := tailnet.PreferDERPRegion(derpMap, 42)
+		require.Same(t, derpMap, preferred)
+		require.Nil(t, preferred.HomeParams)
+	})
+}
@@ -37,6 +37,15 @@ func WithStreamID(ctx context.Context, streamID StreamID) context.Context {
 	return context.WithValue(ctx, streamIDContextKey{}, streamID)
 }
 
+type derpMapFilterContextKey struct{}
+
+// WithDERPMapFilter causes DERP maps streamed to the caller to be passed
+// through filter first. This lets the HTTP layer restrict the DERP regions a
+// single client may use without a separate DRPCService per connection.
+func WithDERPMapFilter(ctx context.Context, filter func(*tailcfg.DERPMap) *tailcfg.DERPMap) context.Context {
+	return context.WithValue(ctx, derpMapFilterContextKey{}, filter)
+}
+
 type ClientServiceOptions struct {
 	Logger                  slog.Logger
 	CoordPtr                *atomic.Pointer[Coordinator]
@@ -142,6 +151,8 @@ func (s *DRPCService) PostTelemetry(_ context.Context, req *proto.TelemetryReque
 func (s *DRPCService) StreamDERPMaps(_ *proto.StreamDERPMapsRequest, stream proto.DRPCTailnet_StreamDERPMapsStream) error {
 	defer stream.Close()
 
+	filter, _ := stream.Context().Value(derpMapFilterContextKey{}).(func(*tailcfg.DERPMap) *tailcfg.DERPMap)
+
 	ticker := time.NewTicker(s.DerpMapUpdateFrequency)
 	defer ticker.Stop()
 
@@ -152,6 +163,9 @@ func (s *DRPCService) StreamDERPMaps(_ *proto.StreamDERPMapsRequest, stream prot
 			// in testing, we send nil to close the stream.
 			return io.EOF
 		}
+		if filter != nil {
+			derpMap = filter(derpMap)
+		}
 		if lastDERPMap == nil || !CompareDERPMaps(lastDERPMap, derpMap) {
 			protoDERPMap := DERPMapToProto(derpMap)
 			err := stream.Send(protoDERPMap)
@@ -132,6 +132,62 @@ func TestClientService_ServeClient_V2(t *testing.T) {
 	require.True(t, xerrors.Is(err, io.EOF) || xerrors.Is(err, io.ErrClosedPipe))
 }
 
+func TestClientService_ServeClient_DERPMapFilter(t *testing.T) {
+	t.Parallel()
+	fCoord := tailnettest.NewFakeCoordinator()
+	var coord tailnet.Coordinator = fCoord
+	coordPtr := atomic.Pointer[tailnet.Coordinator]{}
+	coordPtr.Store(&coord)
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	derpMap := &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+		999:  {RegionCode: "allowed"},
+		1000: {RegionCode: "denied"},
+	}}
+
+	uut, err := tailnet.NewClientService(tailnet.ClientServiceOptions{
+		Logger:                  logger,
+		CoordPtr:                &coordPtr,
+		DERPMapUpdateFrequency:  time.Millisecond,
+		DERPMapFn:               func() *tailcfg.DERPMap { return derpMap },
+		NetworkTelemetryHandler: func(batch []*proto.TelemetryEvent) {},
+		ResumeTokenProvider:     tailnet.NewInsecureTestResumeTokenProvider(),
+	})
+	require.NoError(t, err)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	serveCtx := tailnet.WithDERPMapFilter(ctx, func(dm *tailcfg.DERPMap) *tailcfg.DERPMap {
+		filtered := dm.Clone()
+		delete(filtered.Regions, 1000)
+		return filtered
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- uut.ServeClient(serveCtx, "2.0", s, uuid.New(), uuid.New())
+	}()
+
+	client, err := tailnet.NewDRPCClient(c, logger)
+	require.NoError(t, err)
+
+	dms, err := client.StreamDERPMaps(ctx, &proto.StreamDERPMapsRequest{})
+	require.NoError(t, err)
+	gotDERPMap, err := dms.Recv()
+	require.NoError(t, err)
+	require.Len(t, gotDERPMap.GetRegions(), 1)
+	require.Equal(t, "allowed", gotDERPMap.GetRegions()[999].GetRegionCode())
+	// The filter must not modify the shared map.
+	require.Len(t, derpMap.Regions, 2)
+	err = dms.Close()
+	require.NoError(t, err)
+
+	err = c.Close()
+	require.NoError(t, err)
+	err = testutil.RequireRecvCtx(ctx, t, errCh)
+	require.True(t, xerrors.Is(err, io.EOF) || xerrors.Is(err, io.ErrClosedPipe))
+}
+
 func TestClientService_ServeClient_V1(t *testing.T) {
 	t.Parallel()
 	fCoord := tailnettest.NewFakeCoordinator()